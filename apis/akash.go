@@ -21,6 +21,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 
 	resourcev1alpha1 "github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	resourcev1beta1 "github.com/overlock-network/provider-akash/apis/resource/v1beta1"
 	akashv1alpha1 "github.com/overlock-network/provider-akash/apis/v1alpha1"
 )
 
@@ -29,6 +30,7 @@ func init() {
 	AddToSchemes = append(AddToSchemes,
 		akashv1alpha1.SchemeBuilder.AddToScheme,
 		resourcev1alpha1.SchemeBuilder.AddToScheme,
+		resourcev1beta1.SchemeBuilder.AddToScheme,
 	)
 }
 
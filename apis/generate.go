@@ -26,6 +26,15 @@ limitations under the License.
 // Generate deepcopy methodsets and CRD manifests
 //go:generate go run -tags generate sigs.k8s.io/controller-tools/cmd/controller-gen object:headerFile=../hack/boilerplate.go.txt paths=./... crd:crdVersions=v1 output:artifacts:config=../package/crds
 
+// Convert the versions of Deployments with the conversion webhook
+//go:generate ../hack/helpers/conversion.sh ../package/crds/resource.akash.overlock.network_deployments.yaml
+
+// Generate the ClusterRole of the controllers from their RBAC markers
+//go:generate go run -tags generate sigs.k8s.io/controller-tools/cmd/controller-gen rbac:roleName=provider-akash paths=../internal/controller/... output:rbac:artifacts:config=../cluster/rbac
+
+// Generate the webhook configurations of the package from the webhook markers
+//go:generate go run -tags generate sigs.k8s.io/controller-tools/cmd/controller-gen webhook paths=../internal/webhook/... output:webhook:artifacts:config=../package/webhookconfigurations
+
 // Generate crossplane-runtime methodsets (resource.Claim, etc)
 //go:generate go run -tags generate github.com/crossplane/crossplane-tools/cmd/angryjet generate-methodsets --header-file=../hack/boilerplate.go.txt ./...
 
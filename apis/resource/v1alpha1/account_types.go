@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// AccountParameters are the configurable fields of an Account.
+type AccountParameters struct {
+	// Address of the observed account. Defaults to the account of the
+	// ProviderConfig.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^akash1[02-9ac-hj-np-z]+$`
+	Address *string `json:"address,omitempty"`
+
+	// LowBalance is the spendable balance below which the LowBalance
+	// condition of the Account is true.
+	// +optional
+	LowBalance *BalanceThreshold `json:"lowBalance,omitempty"`
+}
+
+// A BalanceThreshold is an amount of a denomination an account should hold.
+type BalanceThreshold struct {
+	// Amount in the smallest unit of Denom.
+	// +kubebuilder:validation:Pattern=`^[0-9]+$`
+	Amount string `json:"amount"`
+
+	// Denom of the amount.
+	// +optional
+	// +kubebuilder:default=uakt
+	Denom string `json:"denom,omitempty"`
+}
+
+// A Coin is an amount of a denomination.
+type Coin struct {
+	Denom  string `json:"denom"`
+	Amount string `json:"amount"`
+}
+
+// A Delegation is stake an account delegated to a validator.
+type Delegation struct {
+	// Validator is the operator address of the validator.
+	Validator string `json:"validator"`
+
+	// Balance is the stake delegated to the validator.
+	Balance Coin `json:"balance"`
+}
+
+// AccountObservation are the observable fields of an Account.
+type AccountObservation struct {
+	// Address of the observed account.
+	Address string `json:"address,omitempty"`
+
+	// Balances the account can spend.
+	// +optional
+	Balances []Coin `json:"balances,omitempty"`
+
+	// Delegations of the account to validators.
+	// +optional
+	Delegations []Delegation `json:"delegations,omitempty"`
+
+	// EscrowObligations is the balance left in the escrow of the active
+	// deployments of the account, by denomination.
+	// +optional
+	EscrowObligations []Coin `json:"escrowObligations,omitempty"`
+
+	// ActiveDeployments is the number of active deployments of the account.
+	// +optional
+	ActiveDeployments int `json:"activeDeployments,omitempty"`
+}
+
+// An AccountSpec defines the desired state of an Account.
+type AccountSpec struct {
+	xpv1.ResourceSpec            `json:",inline"`
+	NamespacedProviderConfigSpec `json:",inline"`
+	ForProvider                  AccountParameters `json:"forProvider,omitempty"`
+}
+
+// An AccountStatus represents the observed state of an Account.
+type AccountStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          AccountObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// An Account observes the balances of an Akash account. Accounts are never
+// created or deleted on chain.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="ADDRESS",type="string",JSONPath=".status.atProvider.address"
+// +kubebuilder:printcolumn:name="UAKT",type="string",JSONPath=".status.atProvider.balances[?(@.denom=='uakt')].amount"
+// +kubebuilder:printcolumn:name="LOW",type="string",JSONPath=".status.conditions[?(@.type=='LowBalance')].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,akash}
+type Account struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AccountSpec   `json:"spec"`
+	Status AccountStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AccountList contains a list of Account
+type AccountList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Account `json:"items"`
+}
+
+// Account type metadata.
+var (
+	AccountKind             = reflect.TypeOf(Account{}).Name()
+	AccountGroupKind        = schema.GroupKind{Group: Group, Kind: AccountKind}.String()
+	AccountKindAPIVersion   = AccountKind + "." + SchemeGroupVersion.String()
+	AccountGroupVersionKind = SchemeGroupVersion.WithKind(AccountKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&Account{}, &AccountList{})
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// LabelKeyProvider is set on an Audit to the address of the audited provider.
+const LabelKeyProvider = "akash.web7.md/provider"
+
+// LabelKeyPrefixAuditor prefixes the address of every auditor that signed
+// attributes of the provider of an Audit, forming a label set to "true", so
+// that Audits can be selected by auditor.
+const LabelKeyPrefixAuditor = "auditor.akash.web7.md/"
+
+// AuditParameters are the configurable fields of an Audit.
+type AuditParameters struct {
+	// Provider is the address of the audited provider.
+	// +kubebuilder:validation:Pattern=`^akash1[02-9ac-hj-np-z]+$`
+	Provider string `json:"provider"`
+
+	// Auditor restricts the Audit to the attributes signed by the auditor at
+	// this address. The attributes signed by any auditor are observed when
+	// unset.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^akash1[02-9ac-hj-np-z]+$`
+	Auditor *string `json:"auditor,omitempty"`
+}
+
+// An AuditRecord are the attributes of a provider signed by an auditor.
+type AuditRecord struct {
+	// Auditor is the address of the auditor.
+	Auditor string `json:"auditor"`
+
+	// Attributes signed by the auditor.
+	// +optional
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// AuditObservation are the observable fields of an Audit.
+type AuditObservation struct {
+	// Records of the auditors that signed attributes of the provider, by
+	// auditor address.
+	// +optional
+	Records []AuditRecord `json:"records,omitempty"`
+}
+
+// An AuditSpec defines the desired state of an Audit.
+type AuditSpec struct {
+	xpv1.ResourceSpec            `json:",inline"`
+	NamespacedProviderConfigSpec `json:",inline"`
+	ForProvider                  AuditParameters `json:"forProvider"`
+}
+
+// An AuditStatus represents the observed state of an Audit.
+type AuditStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          AuditObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// An Audit observes the attributes of a provider signed by auditors on chain.
+// Every auditor is also recorded as a label of the Audit, see
+// LabelKeyPrefixAuditor.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="PROVIDER",type="string",JSONPath=".spec.forProvider.provider"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,akash}
+type Audit struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AuditSpec   `json:"spec"`
+	Status AuditStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AuditList contains a list of Audit
+type AuditList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Audit `json:"items"`
+}
+
+// Audit type metadata.
+var (
+	AuditKind             = reflect.TypeOf(Audit{}).Name()
+	AuditGroupKind        = schema.GroupKind{Group: Group, Kind: AuditKind}.String()
+	AuditKindAPIVersion   = AuditKind + "." + SchemeGroupVersion.String()
+	AuditGroupVersionKind = SchemeGroupVersion.WithKind(AuditKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&Audit{}, &AuditList{})
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// AuthzGrantParameters are the configurable fields of an AuthzGrant.
+type AuthzGrantParameters struct {
+	// Grantee is the address of the account that may deposit into its
+	// deployments from the account of the ProviderConfig.
+	// +kubebuilder:validation:Pattern=`^akash1[02-9ac-hj-np-z]+$`
+	Grantee string `json:"grantee"`
+
+	// SpendLimit is the amount the grantee may deposit in total.
+	SpendLimit Deposit `json:"spendLimit"`
+
+	// Validity is how long the authorization is valid once granted.
+	// +optional
+	// +kubebuilder:default="8760h"
+	Validity *metav1.Duration `json:"validity,omitempty"`
+
+	// RenewBefore is how long before its expiration the authorization is
+	// renewed, granting it again for Validity with the full SpendLimit.
+	// +optional
+	// +kubebuilder:default="168h"
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+}
+
+// AuthzGrantObservation are the observable fields of an AuthzGrant.
+type AuthzGrantObservation struct {
+	// SpendLimit is the amount the grantee may still deposit.
+	// +optional
+	SpendLimit *Coin `json:"spendLimit,omitempty"`
+
+	// Expiration is when the authorization expires.
+	// +optional
+	Expiration *metav1.Time `json:"expiration,omitempty"`
+
+	// GrantedSpendLimit is the spend limit the authorization was granted
+	// with, which the remaining SpendLimit cannot be compared to.
+	// +optional
+	GrantedSpendLimit *Coin `json:"grantedSpendLimit,omitempty"`
+}
+
+// An AuthzGrantSpec defines the desired state of an AuthzGrant.
+type AuthzGrantSpec struct {
+	xpv1.ResourceSpec            `json:",inline"`
+	NamespacedProviderConfigSpec `json:",inline"`
+	ForProvider                  AuthzGrantParameters `json:"forProvider"`
+}
+
+// An AuthzGrantStatus represents the observed state of an AuthzGrant.
+type AuthzGrantStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          AuthzGrantObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// An AuthzGrant is a deposit authorization the account of its
+// ProviderConfig, e.g. a treasury, grants another account, letting it fund
+// its deployments from the deposit of the granter. The authorization is
+// renewed before it expires, and granted again when the AuthzGrant changes.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="GRANTEE",type="string",JSONPath=".spec.forProvider.grantee"
+// +kubebuilder:printcolumn:name="EXPIRATION",type="string",JSONPath=".status.atProvider.expiration"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,akash}
+type AuthzGrant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AuthzGrantSpec   `json:"spec"`
+	Status AuthzGrantStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AuthzGrantList contains a list of AuthzGrant
+type AuthzGrantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AuthzGrant `json:"items"`
+}
+
+// AuthzGrant type metadata.
+var (
+	AuthzGrantKind             = reflect.TypeOf(AuthzGrant{}).Name()
+	AuthzGrantGroupKind        = schema.GroupKind{Group: Group, Kind: AuthzGrantKind}.String()
+	AuthzGrantKindAPIVersion   = AuthzGrantKind + "." + SchemeGroupVersion.String()
+	AuthzGrantGroupVersionKind = SchemeGroupVersion.WithKind(AuthzGrantKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&AuthzGrant{}, &AuthzGrantList{})
+}
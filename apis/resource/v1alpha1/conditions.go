@@ -0,0 +1,351 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// Condition types specific to Akash resources.
+const (
+	// TypeAccount indicates whether the account signing transactions for a
+	// resource is usable: it matches the configured key and exists on chain.
+	TypeAccount xpv1.ConditionType = "Account"
+
+	// TypeClosed indicates the deployment was closed on chain by the
+	// controller while the resource still exists.
+	TypeClosed xpv1.ConditionType = "Closed"
+
+	// TypeProviderGateway indicates whether the gateway of the leasing
+	// provider serves an API version the controller can talk to.
+	TypeProviderGateway xpv1.ConditionType = "ProviderGateway"
+
+	// TypeObservedViaFallback indicates whether the state in status was read
+	// from an indexer API because the node was unavailable.
+	TypeObservedViaFallback xpv1.ConditionType = "ObservedViaFallback"
+
+	// TypeSimulation indicates whether the last transaction broadcast for a
+	// resource passed its simulation.
+	TypeSimulation xpv1.ConditionType = "Simulation"
+
+	// TypeMainnetSpend indicates whether transactions depositing funds for a
+	// resource may be broadcast on mainnet.
+	TypeMainnetSpend xpv1.ConditionType = "MainnetSpend"
+
+	// TypePriceExceeded indicates whether a deployment waits for a lease
+	// because every bid asks more than its maximum price.
+	TypePriceExceeded xpv1.ConditionType = "PriceExceeded"
+
+	// TypeLowBalance indicates whether the spendable balance of an account
+	// is below its threshold.
+	TypeLowBalance xpv1.ConditionType = "LowBalance"
+)
+
+// Reasons an account is or is not usable.
+const (
+	ReasonAccountInitialized   xpv1.ConditionReason = "AccountInitialized"
+	ReasonAccountUninitialized xpv1.ConditionReason = "AccountUninitialized"
+	ReasonAccountMismatch      xpv1.ConditionReason = "AccountMismatch"
+	ReasonNotOwner             xpv1.ConditionReason = "NotOwner"
+)
+
+// Reasons a deployment was closed.
+const (
+	ReasonClosedUnleased xpv1.ConditionReason = "Unleased"
+	ReasonClosedExpired  xpv1.ConditionReason = "Expired"
+)
+
+// Reasons state was or was not observed via fallback.
+const (
+	ReasonObservedViaNode    xpv1.ConditionReason = "Node"
+	ReasonObservedViaIndexer xpv1.ConditionReason = "IndexerFallback"
+)
+
+// Reasons a provider gateway is or is not usable.
+const (
+	ReasonGatewaySupported          xpv1.ConditionReason = "Supported"
+	ReasonGatewayUnsupportedVersion xpv1.ConditionReason = "UnsupportedAPIVersion"
+	ReasonGatewayReachable          xpv1.ConditionReason = "Reachable"
+	ReasonGatewayUnreachable        xpv1.ConditionReason = "Unreachable"
+)
+
+// Reasons a transaction did or did not pass its simulation.
+const (
+	ReasonSimulationSucceeded xpv1.ConditionReason = "Succeeded"
+	ReasonSimulationFailed    xpv1.ConditionReason = "Failed"
+)
+
+// Reasons spending on mainnet is or is not allowed.
+const (
+	ReasonMainnetSpendConfirmed    xpv1.ConditionReason = "MainnetSpendConfirmed"
+	ReasonMainnetSpendNotConfirmed xpv1.ConditionReason = "MainnetSpendNotConfirmed"
+)
+
+// Reasons a deployment did or did not exceed its maximum price.
+const (
+	ReasonBidWithinMaxPrice   xpv1.ConditionReason = "BidWithinMaxPrice"
+	ReasonNoBidWithinMaxPrice xpv1.ConditionReason = "NoBidWithinMaxPrice"
+)
+
+// Reasons the balance of an account is or is not low.
+const (
+	ReasonBalanceSufficient xpv1.ConditionReason = "BalanceSufficient"
+	ReasonBalanceLow        xpv1.ConditionReason = "BalanceLow"
+)
+
+// AccountInitialized returns a condition indicating the signing account exists
+// on chain.
+func AccountInitialized() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeAccount,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonAccountInitialized,
+	}
+}
+
+// AccountUninitialized returns a condition indicating the signing account does
+// not exist on chain yet, which is the case of freshly created wallets until
+// they first receive funds.
+func AccountUninitialized(address string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeAccount,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonAccountUninitialized,
+		Message:            fmt.Sprintf("account %s does not exist on chain yet: fund it, or send any transaction from it, to initialize it", address),
+	}
+}
+
+// AccountMismatch returns a condition indicating the configured account address
+// is not the address of the configured key, so the controller refuses to act
+// rather than query or sign for the wrong owner.
+func AccountMismatch(message string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeAccount,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonAccountMismatch,
+		Message:            message,
+	}
+}
+
+// NotOwner returns a condition indicating the deployment is owned by another
+// account than the one signing for the resource, so the controller only
+// observes it and refuses to update or lease it.
+func NotOwner(dseq, owner, account string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeAccount,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonNotOwner,
+		Message:            fmt.Sprintf("deployment %s is owned by %s, not by the account %s of the ProviderConfig: it is only observed", dseq, owner, account),
+	}
+}
+
+// ClosedUnleased returns a condition indicating the deployment was closed
+// because it had no active lease for longer than the given period.
+func ClosedUnleased(period metav1.Duration) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeClosed,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonClosedUnleased,
+		Message:            fmt.Sprintf("deployment had no active lease for %s and was closed, refunding its escrow", period.Duration),
+	}
+}
+
+// ClosedExpired returns a condition indicating the controller closed the
+// deployment because it expired at the given deadline.
+func ClosedExpired(deadline metav1.Time) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeClosed,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonClosedExpired,
+		Message:            fmt.Sprintf("deployment expired at %s and was closed, refunding its escrow", deadline.Format(time.RFC3339)),
+	}
+}
+
+// GatewaySupported returns a condition indicating the gateway of the leasing
+// provider serves the given supported API version.
+func GatewaySupported(apiVersion string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeProviderGateway,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonGatewaySupported,
+		Message:            fmt.Sprintf("provider gateway serves API version %s", apiVersion),
+	}
+}
+
+// GatewayUnsupported returns a condition indicating the gateway of the leasing
+// provider serves no supported API version.
+func GatewayUnsupported(message string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeProviderGateway,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonGatewayUnsupportedVersion,
+		Message:            message,
+	}
+}
+
+// GatewayReachable returns a condition indicating the gateway of the leasing
+// provider answered the last probe.
+func GatewayReachable() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeProviderGateway,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonGatewayReachable,
+	}
+}
+
+// GatewayUnreachable returns a condition indicating the gateway of the leasing
+// provider failed the given number of consecutive probes.
+func GatewayUnreachable(failures int, message string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeProviderGateway,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonGatewayUnreachable,
+		Message:            fmt.Sprintf("provider gateway failed %d consecutive probes: %s", failures, message),
+	}
+}
+
+// ObservedViaNode returns a condition indicating state was read from the node.
+func ObservedViaNode() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeObservedViaFallback,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonObservedViaNode,
+	}
+}
+
+// ObservedViaIndexer returns a condition indicating state was read from the
+// given indexer API because the node was unavailable.
+func ObservedViaIndexer(indexer string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeObservedViaFallback,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonObservedViaIndexer,
+		Message:            fmt.Sprintf("node was unavailable, state was observed via the indexer at %s", indexer),
+	}
+}
+
+// SimulationSucceeded returns a condition indicating the last transaction
+// passed its simulation and was broadcast.
+func SimulationSucceeded() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeSimulation,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonSimulationSucceeded,
+	}
+}
+
+// SimulationFailed returns a condition indicating the last transaction failed
+// its simulation, so it was not broadcast and no fees were spent.
+func SimulationFailed(message string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeSimulation,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonSimulationFailed,
+		Message:            message,
+	}
+}
+
+// MainnetSpendConfirmed returns a condition indicating the ProviderConfig
+// confirms spending on mainnet.
+func MainnetSpendConfirmed() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeMainnetSpend,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonMainnetSpendConfirmed,
+	}
+}
+
+// MainnetSpendNotConfirmed returns a condition indicating transactions
+// depositing funds were not broadcast on the given mainnet chain because the
+// ProviderConfig does not confirm spending on mainnet.
+func MainnetSpendNotConfirmed(chainId string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeMainnetSpend,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonMainnetSpendNotConfirmed,
+		Message:            fmt.Sprintf("%s is a mainnet chain: set confirmMainnetSpend in the ProviderConfig to deposit real funds", chainId),
+	}
+}
+
+// BidWithinMaxPrice returns a condition indicating a bid asking at most the
+// maximum price of the deployment was leased.
+func BidWithinMaxPrice() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypePriceExceeded,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonBidWithinMaxPrice,
+	}
+}
+
+// NoBidWithinMaxPrice returns a condition indicating no bid asking at most
+// the given maximum price arrived within window, the cheapest bid asking the
+// given price instead.
+func NoBidWithinMaxPrice(maxPrice, cheapest string, window metav1.Duration) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypePriceExceeded,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonNoBidWithinMaxPrice,
+		Message:            fmt.Sprintf("no bid asked at most %s within %s: the cheapest bid asks %s", maxPrice, window.Duration, cheapest),
+	}
+}
+
+// BalanceSufficient returns a condition indicating the spendable balance of an
+// account is at least its threshold.
+func BalanceSufficient() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeLowBalance,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonBalanceSufficient,
+	}
+}
+
+// BalanceLow returns a condition indicating the spendable balance of an
+// account is below the given threshold.
+func BalanceLow(balance, threshold string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeLowBalance,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonBalanceLow,
+		Message:            fmt.Sprintf("spendable balance %s is below %s", balance, threshold),
+	}
+}
@@ -25,20 +25,975 @@ import (
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 )
 
+// AnnotationKeyApprovedBid is set by a human approver to the address of the
+// provider whose bid should be accepted when bid approval is required.
+const AnnotationKeyApprovedBid = "akash.web7.md/approved-bid"
+
+// AnnotationKeyPromoteTo requests the promotion of a Deployment to the network
+// of the named ProviderConfig, e.g. from sandbox to mainnet.
+const AnnotationKeyPromoteTo = "akash.web7.md/promote-to"
+
+// AnnotationKeyInspectAtHeight requests a one-off diagnostic query of the
+// on-chain deployment and its leases at the given block height. The result is
+// recorded as an event and the annotation is removed afterwards.
+const AnnotationKeyInspectAtHeight = "akash.web7.md/inspect-at-height"
+
+// AnnotationKeySupportBundle requests a redacted support bundle for a
+// Deployment. The bundle is written to a ConfigMap in the namespace named by
+// the annotation value and the annotation is removed afterwards.
+const AnnotationKeySupportBundle = "akash.web7.md/support-bundle"
+
+// AnnotationKeySDLFreeze stops edits of the ConfigMap or Secret referenced by
+// sdlRef from being rolled out as soon as they are made, e.g. during a change
+// freeze window. Edits are still picked up at the next poll.
+const AnnotationKeySDLFreeze = "akash.web7.md/sdl-freeze"
+
+// LabelKeyPromotedFrom is set on a promoted Deployment to the name of the
+// Deployment it was promoted from.
+const LabelKeyPromotedFrom = "akash.web7.md/promoted-from"
+
 // DeploymentParameters are the configurable fields of a Deployment.
 type DeploymentParameters struct {
+	// Deployment is the SDL of the deployment.
+	// Deprecated: Use SDL or SDLRef instead.
+	// +optional
 	Deployment string `json:"deployment,omitempty"`
+
+	// SDL is the inline SDL of the deployment. It takes precedence over
+	// Deployment.
+	// +optional
+	SDL *string `json:"sdl,omitempty"`
+
+	// SDLRef references a ConfigMap or Secret key holding the SDL of the
+	// deployment. It takes precedence over SDL and Deployment.
+	// +optional
+	SDLRef *SDLReference `json:"sdlRef,omitempty"`
+
+	// SDLChecksum is the lowercase hex encoded SHA-256 digest the SDL must
+	// match before it is deployed. Create and Update are refused when the
+	// SDL does not match, so only an approved SDL can reach the network.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[a-f0-9]{64}$`
+	SDLChecksum *string `json:"sdlChecksum,omitempty"`
+
+	// ManifestDelivery tunes how the manifest is submitted to the leasing
+	// provider before the controller gives up on it.
+	// +optional
+	ManifestDelivery *ManifestDelivery `json:"manifestDelivery,omitempty"`
+
+	// RequireApproval holds lease creation until a human approves one of the
+	// received bids by setting the akash.web7.md/approved-bid annotation to
+	// the address of the chosen provider. A report comparing the cheapest
+	// bids is published in status.atProvider.bidReport meanwhile.
+	// +optional
+	RequireApproval *bool `json:"requireApproval,omitempty"`
+
+	// BidReportSize is the number of cheapest bids compared in the bid
+	// report published while waiting for approval.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=5
+	BidReportSize *int `json:"bidReportSize,omitempty"`
+
+	// MinBids is the number of bids collected before one is selected,
+	// rather than accepting the first bid, e.g. to get better prices in
+	// regions with many providers.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	MinBids *int `json:"minBids,omitempty"`
+
+	// BidCollectionWindow bounds how long bids are collected while fewer
+	// than MinBids arrived. Once it elapses, a bid is selected among those
+	// received so far.
+	// +optional
+	// +kubebuilder:default="1m"
+	BidCollectionWindow *metav1.Duration `json:"bidCollectionWindow,omitempty"`
+
+	// BidSelection controls which of the collected bids is leased. The
+	// lowest priced bid is leased by default.
+	// +optional
+	BidSelection *BidSelection `json:"bidSelection,omitempty"`
+
+	// ProviderFilter restricts the providers whose bids may be leased. Bids
+	// of other providers are rejected and recorded in status.atProvider.bids
+	// once a bid is leased.
+	// +optional
+	ProviderFilter *ProviderFilter `json:"providerFilter,omitempty"`
+
+	// MaxPrice is the highest price per block a bid may ask to be leased.
+	// Bids asking more are rejected, and the PriceExceeded condition is set
+	// when no bid within it arrived in time.
+	// +optional
+	MaxPrice *MaxPrice `json:"maxPrice,omitempty"`
+
+	// CloseIfUnleasedFor closes the deployment, refunding its escrow, once it
+	// has had no active lease for this long, e.g. because no bid was ever
+	// matched or its lease was closed by the provider. Unset disables it.
+	// +optional
+	CloseIfUnleasedFor *metav1.Duration `json:"closeIfUnleasedFor,omitempty"`
+
+	// TTL closes the deployment, refunding its escrow, once it was created
+	// on chain this long ago, e.g. for preview environments. Unset disables
+	// it.
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+
+	// CloseAt closes the deployment, refunding its escrow, at this time. The
+	// deployment is closed at the earlier deadline when TTL is set as well.
+	// +optional
+	CloseAt *metav1.Time `json:"closeAt,omitempty"`
+
+	// RecreateOnFailure closes the deployment and creates it again, under a
+	// new dseq, once its lease or the deployment itself was closed on chain
+	// other than by this controller, e.g. by the provider. Deployments closed
+	// because of CloseIfUnleasedFor, TTL or CloseAt are not recreated.
+	// +optional
+	RecreateOnFailure *bool `json:"recreateOnFailure,omitempty"`
+
+	// AllowClosingUnowned allows closing a deployment imported with an
+	// external name of the form owner/dseq whose owner is not the account of
+	// the ProviderConfig, e.g. when the resource is deleted. Closing it
+	// requires an authorization of the owner on chain.
+	// +optional
+	AllowClosingUnowned *bool `json:"allowClosingUnowned,omitempty"`
+
+	// Failover moves the deployment to another provider once the gateway of
+	// its provider was unreachable for a number of consecutive probes. The
+	// deployment is recreated under a new dseq, and the bid of the provider
+	// it leaves is rejected.
+	// +optional
+	Failover *Failover `json:"failover,omitempty"`
+
+	// HealthChecks are probes of the published endpoints of SDL services.
+	// The Deployment is only reported Ready while all of them pass.
+	// +optional
+	HealthChecks []HealthCheck `json:"healthChecks,omitempty"`
+
+	// ServiceDependencies declare which SDL services depend on others, e.g.
+	// an application on its database. A service is only considered healthy
+	// once all the services it depends on are.
+	// +optional
+	ServiceDependencies []ServiceDependency `json:"serviceDependencies,omitempty"`
+
+	// LogForwarding forwards the logs of the SDL services from the gateway
+	// of the leasing provider into the logging pipeline of the cluster, so
+	// they are collected like the logs of in-cluster workloads.
+	// +optional
+	LogForwarding *LogForwarding `json:"logForwarding,omitempty"`
+
+	// SpendTolerancePercent is how much faster than the prices of its leases
+	// the escrow of the deployment may drain, or the leases may be charged,
+	// before a spend anomaly is reported.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=20
+	SpendTolerancePercent *int `json:"spendTolerancePercent,omitempty"`
+
+	// Deposit funds the escrow of the deployment when it is created. The
+	// deposit defaults to 5000000uakt, like the Akash CLI.
+	// +optional
+	Deposit *Deposit `json:"deposit,omitempty"`
+
+	// Depositor is the address of the account the deposit is taken from,
+	// which must have authorized the owner to spend it. The deposit is taken
+	// from the owner when unset.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^akash1[02-9ac-hj-np-z]+$`
+	Depositor *string `json:"depositor,omitempty"`
+
+	// Owner is the address of the account owning the deployment. It is
+	// late-initialized from the chain and informational only: deployments
+	// are created by the account of the ProviderConfig, and the owner of an
+	// imported deployment is given by its external name.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^akash1[02-9ac-hj-np-z]+$`
+	Owner *string `json:"owner,omitempty"`
+
+	// AutoTopUp deposits funds into the escrow of the deployment before it
+	// runs out, which would close its leases.
+	// +optional
+	AutoTopUp *AutoTopUp `json:"autoTopUp,omitempty"`
+
+	// MetadataPassthrough propagates labels and annotations of the Deployment
+	// into the environment of every SDL service, so workloads on Akash carry
+	// the same organizational metadata, e.g. team or cost center, as
+	// in-cluster workloads.
+	// +optional
+	MetadataPassthrough []MetadataPassthrough `json:"metadataPassthrough,omitempty"`
+
+	// ColocateWith is the name of another Deployment whose provider is
+	// preferred when selecting a bid, e.g. to place an application next to
+	// its database. The Deployment is only created once the referenced one
+	// was placed with a provider. Bids of other providers are only selected
+	// when that provider does not bid.
+	// +optional
+	ColocateWith *string `json:"colocateWith,omitempty"`
+
+	// Transaction overrides the memo and fees of the transactions made for
+	// the deployment, e.g. to get a high priority workload included faster
+	// during congestion without raising the fees of the whole fleet.
+	// +optional
+	Transaction *TransactionOverrides `json:"transaction,omitempty"`
+}
+
+// A BidSelectionStrategy decides which of the collected bids is leased.
+// +kubebuilder:validation:Enum=lowestPrice;random;weighted
+type BidSelectionStrategy string
+
+// Bid selection strategies.
+const (
+	// BidSelectionLowestPrice leases the cheapest bid.
+	BidSelectionLowestPrice BidSelectionStrategy = "lowestPrice"
+	// BidSelectionRandom leases a bid at random, e.g. to spread the
+	// deployments of a fleet across providers.
+	BidSelectionRandom BidSelectionStrategy = "random"
+	// BidSelectionWeighted leases the bid with the highest score, weighing
+	// its price against the attributes of its provider.
+	BidSelectionWeighted BidSelectionStrategy = "weighted"
+)
+
+// BidSelection controls which of the collected bids is leased. Bids that
+// cannot be leased, e.g. because they were withdrawn, are skipped in favor
+// of the next one in the order of the strategy.
+type BidSelection struct {
+	// Strategy deciding which bid is leased.
+	// +optional
+	// +kubebuilder:default=lowestPrice
+	Strategy BidSelectionStrategy `json:"strategy,omitempty"`
+
+	// Weights of the weighted strategy.
+	// +optional
+	Weights *BidSelectionWeights `json:"weights,omitempty"`
+}
+
+// BidSelectionWeights weigh the criteria bids are scored by with the weighted
+// strategy. The score of a bid is the sum of its criteria multiplied by their
+// weights.
+type BidSelectionWeights struct {
+	// Price weighs how cheap a bid is compared to the others, from 1 for the
+	// cheapest to 0 for the most expensive.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=1
+	Price *int `json:"price,omitempty"`
+
+	// Audited weighs whether the provider was audited, 1 if it was.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Audited *int `json:"audited,omitempty"`
+
+	// Uptime weighs the uptime of the provider, from 0 to 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Uptime *int `json:"uptime,omitempty"`
+
+	// Attributes weigh the attributes of the provider by key=value, e.g.
+	// region=us-west. A provider advertising the attribute scores its weight.
+	// +optional
+	Attributes map[string]int `json:"attributes,omitempty"`
+}
+
+// ProviderFilter restricts the providers whose bids may be leased. A provider
+// must pass every check that is set.
+type ProviderFilter struct {
+	// Attributes the provider must advertise, by key and value.
+	// +optional
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	// Regions the provider must advertise one of in its region attribute.
+	// +optional
+	Regions []string `json:"regions,omitempty"`
+
+	// SignedBy requires the provider to be audited by the given auditors,
+	// like the signedBy requirement of an SDL placement.
+	// +optional
+	SignedBy *SignedBy `json:"signedBy,omitempty"`
+
+	// Allow are the addresses of the only providers that may be leased from.
+	// +optional
+	Allow []string `json:"allow,omitempty"`
+
+	// Deny are the addresses of providers that are never leased from.
+	// +optional
+	Deny []string `json:"deny,omitempty"`
+}
+
+// A Deposit is an amount funding the escrow of a deployment.
+type Deposit struct {
+	// Amount of Denom deposited.
+	// +kubebuilder:validation:Pattern=`^[0-9]+$`
+	Amount string `json:"amount"`
+
+	// Denom of the deposit: uakt, or the IBC denomination of USDC, e.g.
+	// ibc/170C677610AC31DF0904FFE09CD3B5C657492170E7E52372E48756B71E56F2F1
+	// on mainnet.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(uakt|ibc/[0-9A-F]{64})$`
+	// +kubebuilder:default=uakt
+	Denom string `json:"denom,omitempty"`
+}
+
+// AutoTopUp configures the deposits that refund the escrow of a deployment.
+// Amounts are in Denom.
+type AutoTopUp struct {
+	// Threshold is the escrow balance below which Amount is deposited.
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	Threshold string `json:"threshold"`
+
+	// Amount deposited by each top-up.
+	// +kubebuilder:validation:Pattern=`^[0-9]+$`
+	Amount string `json:"amount"`
+
+	// Denom of the amounts, which must match the denomination of the
+	// escrow.
+	// +optional
+	// +kubebuilder:default=uakt
+	Denom string `json:"denom,omitempty"`
+
+	// MaxTotal bounds the total amount deposited by top-ups. A top-up that
+	// would exceed it deposits what is left. Unset deposits without bound.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+$`
+	MaxTotal *string `json:"maxTotal,omitempty"`
+}
+
+// Failover configures when a deployment leaves its provider.
+type Failover struct {
+	// UnreachableProbes is the number of consecutive probes the gateway of
+	// the provider must fail before the deployment fails over. The gateway
+	// is probed whenever the deployment is observed.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=3
+	UnreachableProbes *int `json:"unreachableProbes,omitempty"`
+}
+
+// MaxPrice is the highest price per block a bid may ask.
+type MaxPrice struct {
+	// Amount is the decimal price per block, e.g. "1000" or "0.5".
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	Amount string `json:"amount"`
+
+	// Denom is the denomination of Amount. Bids in other denominations are
+	// rejected.
+	// +optional
+	// +kubebuilder:default=uakt
+	Denom string `json:"denom,omitempty"`
+
+	// Window bounds how long bids are awaited after the deployment was
+	// created before the PriceExceeded condition is set because none asks
+	// at most Amount. Bids within it are still leased once they arrive.
+	// +optional
+	// +kubebuilder:default="5m"
+	Window *metav1.Duration `json:"window,omitempty"`
+}
+
+// SignedBy names the auditors a provider must be audited by.
+type SignedBy struct {
+	// AnyOf are auditors at least one of which must have audited the
+	// provider.
+	// +optional
+	AnyOf []string `json:"anyOf,omitempty"`
+
+	// AllOf are auditors all of which must have audited the provider.
+	// +optional
+	AllOf []string `json:"allOf,omitempty"`
+}
+
+// TransactionOverrides take precedence over the transaction settings of the
+// ProviderConfig for the transactions of a single Deployment.
+type TransactionOverrides struct {
+	// Memo set on the transactions.
+	// +optional
+	// +kubebuilder:validation:MaxLength=256
+	Memo *string `json:"memo,omitempty"`
+
+	// GasAdjustment multiplies the gas estimated for the transactions, e.g.
+	// 1.8 to leave more headroom than the default of 1.5.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	GasAdjustment *string `json:"gasAdjustment,omitempty"`
+
+	// FeeCap is the fee paid for each transaction, e.g. 50000uakt, instead of
+	// a fee at the default gas price. Transactions whose gas would cost more
+	// than the cap at the minimum gas price of the node are rejected rather
+	// than paid for.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+[a-zA-Z][a-zA-Z0-9/]*$`
+	FeeCap *string `json:"feeCap,omitempty"`
+}
+
+// SDLSourceKind is the kind of object an SDL is read from.
+// +kubebuilder:validation:Enum=ConfigMap;Secret
+type SDLSourceKind string
+
+// SDL source kinds.
+const (
+	SDLSourceConfigMap SDLSourceKind = "ConfigMap"
+	SDLSourceSecret    SDLSourceKind = "Secret"
+)
+
+// An SDLReference selects a key of a ConfigMap or Secret holding an SDL.
+type SDLReference struct {
+	// Kind of the referenced object.
+	// +optional
+	// +kubebuilder:default=ConfigMap
+	Kind SDLSourceKind `json:"kind,omitempty"`
+
+	// Name of the referenced object.
+	Name string `json:"name"`
+
+	// Namespace of the referenced object.
+	Namespace string `json:"namespace"`
+
+	// Key of the SDL in the data of the referenced object.
+	// +optional
+	// +kubebuilder:default="deploy.yaml"
+	Key string `json:"key,omitempty"`
+}
+
+// A MetadataPassthrough sets an environment variable of every SDL service to
+// the value of a label or annotation of the Deployment. Nothing is set while
+// the Deployment lacks the label or annotation.
+type MetadataPassthrough struct {
+	// Label is the key of the label whose value is passed through.
+	// +optional
+	Label *string `json:"label,omitempty"`
+
+	// Annotation is the key of the annotation whose value is passed through.
+	// It is ignored when Label is set.
+	// +optional
+	Annotation *string `json:"annotation,omitempty"`
+
+	// Env is the name of the environment variable set to the value. Services
+	// that already set the variable in the SDL keep their own value.
+	// +kubebuilder:validation:Pattern=`^[A-Za-z_][A-Za-z0-9_]*$`
+	Env string `json:"env"`
+}
+
+// HealthCheckType is the kind of probe of a health check.
+// +kubebuilder:validation:Enum=HTTP;TCP
+type HealthCheckType string
+
+// Health check types.
+const (
+	HealthCheckHTTP HealthCheckType = "HTTP"
+	HealthCheckTCP  HealthCheckType = "TCP"
+)
+
+// A ServiceDependency declares the services an SDL service depends on.
+type ServiceDependency struct {
+	// Service is the name of the dependent SDL service.
+	Service string `json:"service"`
+
+	// DependsOn are the names of the SDL services it depends on.
+	DependsOn []string `json:"dependsOn"`
+}
+
+// A HealthCheck probes an endpoint published by the provider for a service.
+type HealthCheck struct {
+	// Service is the name of the SDL service to probe.
+	Service string `json:"service"`
+
+	// Type of the probe. HTTP probes GET the path on the first URI of the
+	// service and expect a 2xx or 3xx response. TCP probes connect to the
+	// port forwarded for Port.
+	// +optional
+	// +kubebuilder:default=HTTP
+	Type HealthCheckType `json:"type,omitempty"`
+
+	// Path requested by HTTP probes.
+	// +optional
+	// +kubebuilder:default="/"
+	Path string `json:"path,omitempty"`
+
+	// Port of the service whose forwarded port is probed by TCP probes.
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// Interval between probes.
+	// +optional
+	// +kubebuilder:default="30s"
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// Timeout of a single probe.
+	// +optional
+	// +kubebuilder:default="5s"
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// LogForwarding configures the forwarding of the logs of SDL services.
+type LogForwarding struct {
+	// Services whose logs are forwarded. The logs of all services are
+	// forwarded when empty.
+	// +optional
+	Services []string `json:"services,omitempty"`
+
+	// Tail is the number of past lines of each service forwarded when the
+	// log stream starts, e.g. after the controller restarted.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=0
+	Tail *int `json:"tail,omitempty"`
+
+	// Sink the logs are forwarded to. They are written to the log of the
+	// controller, labeled with the Deployment and service, when unset.
+	// +optional
+	Sink *LogSink `json:"sink,omitempty"`
+}
+
+// A LogSinkType is a kind of log sink.
+// +kubebuilder:validation:Enum=Loki;HTTP
+type LogSinkType string
+
+// Log sink types.
+const (
+	LogSinkLoki LogSinkType = "Loki"
+	LogSinkHTTP LogSinkType = "HTTP"
+)
+
+// A LogSink receives forwarded logs.
+type LogSink struct {
+	// Type of the sink. Loki sinks receive the logs through the push API of
+	// Loki, with a stream per service. HTTP sinks receive batches of lines as
+	// a JSON array in a POST request.
+	Type LogSinkType `json:"type"`
+
+	// URL of the sink. The push API path is appended to the URL of Loki
+	// sinks.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// Labels added to every line forwarded to the sink.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// ManifestDelivery configures retries of manifest submission to a provider.
+type ManifestDelivery struct {
+	// MaxRetries is the number of additional submissions attempted after the
+	// first one fails.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=3
+	MaxRetries *int `json:"maxRetries,omitempty"`
+
+	// Backoff is the delay before the first retry. It doubles after every
+	// failed attempt.
+	// +optional
+	// +kubebuilder:default="5s"
+	Backoff *metav1.Duration `json:"backoff,omitempty"`
+
+	// Timeout bounds the total time spent delivering the manifest, including
+	// all retries.
+	// +optional
+	// +kubebuilder:default="2m"
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
 }
 
 // DeploymentObservation are the observable fields of a Deployment.
 type DeploymentObservation struct {
+	// Dseq is the sequence number of the deployment on chain. It is used to
+	// restore the external name if the annotation is accidentally removed.
+	// +optional
+	Dseq string `json:"dseq,omitempty"`
+
+	// Owner of the deployment when it was imported with an external name of
+	// the form owner/dseq. It is used to restore the external name too.
+	// +optional
+	Owner string `json:"owner,omitempty"`
+
+	// State of the deployment on chain.
+	// +optional
+	State DeploymentState `json:"state,omitempty"`
+
+	// Phase is how far the deployment got in being provisioned.
+	// +optional
+	Phase DeploymentPhase `json:"phase,omitempty"`
+
 	ObservableField string `json:"observableField,omitempty"`
+
+	// ManifestDelivery reports the outcome of the latest manifest submission.
+	// +optional
+	ManifestDelivery *ManifestDeliveryStatus `json:"manifestDelivery,omitempty"`
+
+	// BidReport compares the cheapest bids received for the deployment. It is
+	// only published when bid approval is required.
+	// +optional
+	BidReport []BidReportEntry `json:"bidReport,omitempty"`
+
+	// Services are the services of the deployment as reported by the
+	// gateway of the leasing provider.
+	// +optional
+	Services []ServiceStatus `json:"services,omitempty"`
+
+	// GatewayFailures is the number of consecutive probes the gateway of the
+	// leasing provider failed.
+	// +optional
+	GatewayFailures int `json:"gatewayFailures,omitempty"`
+
+	// ForwardedPorts are the external ports the provider assigned to raw
+	// TCP/UDP service ports.
+	// +optional
+	ForwardedPorts []ForwardedPort `json:"forwardedPorts,omitempty"`
+
+	// IPs are the leased IP endpoints the provider assigned to service ports.
+	// +optional
+	IPs []LeasedIP `json:"ips,omitempty"`
+
+	// Bids records the bids accepted or rejected for the deployment, together
+	// with what was known about their providers when the decision was made.
+	// +optional
+	Bids []BidRecord `json:"bids,omitempty"`
+
+	// Promotion reports the promotion of the deployment to another network.
+	// +optional
+	Promotion *PromotionStatus `json:"promotion,omitempty"`
+
+	// UnleasedSince is when the deployment was first observed without an
+	// active lease. It is cleared as soon as a lease is active again.
+	// +optional
+	UnleasedSince *metav1.Time `json:"unleasedSince,omitempty"`
+
+	// ExpiresAt is when the deployment is closed because of its TTL or
+	// CloseAt.
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// ServiceHealth is the result of the last probe of each health check.
+	// +optional
+	ServiceHealth []ServiceHealth `json:"serviceHealth,omitempty"`
+
+	// Spend tracks how fast the escrow of the deployment drains.
+	// +optional
+	Spend *SpendStatus `json:"spend,omitempty"`
+
+	// Recreation reports the last time the deployment was recreated because
+	// it failed on chain.
+	// +optional
+	Recreation *RecreationStatus `json:"recreation,omitempty"`
+
+	// TopUp reports the deposits made by AutoTopUp.
+	// +optional
+	TopUp *TopUpStatus `json:"topUp,omitempty"`
+}
+
+// A DeploymentPhase is the provisioning phase of a deployment.
+// +kubebuilder:validation:Enum=Pending;BidsOpen;LeaseActive;Closed
+type DeploymentPhase string
+
+// Deployment phases.
+const (
+	// DeploymentPhasePending deployments were created on chain and wait for
+	// bids.
+	DeploymentPhasePending DeploymentPhase = "Pending"
+	// DeploymentPhaseBidsOpen deployments received bids, and wait for more
+	// bids, the approval of one, or a lease to be created.
+	DeploymentPhaseBidsOpen DeploymentPhase = "BidsOpen"
+	// DeploymentPhaseLeaseActive deployments have an active lease.
+	DeploymentPhaseLeaseActive DeploymentPhase = "LeaseActive"
+	// DeploymentPhaseClosed deployments were closed on chain.
+	DeploymentPhaseClosed DeploymentPhase = "Closed"
+)
+
+// A PromotionPhase is the phase of a promotion.
+// +kubebuilder:validation:Enum=Refused;PreflightFailed;Completed
+type PromotionPhase string
+
+// Promotion phases.
+const (
+	PromotionPhaseRefused         PromotionPhase = "Refused"
+	PromotionPhasePreflightFailed PromotionPhase = "PreflightFailed"
+	PromotionPhaseCompleted       PromotionPhase = "Completed"
+)
+
+// A PromotionStatus reports the promotion of a Deployment to another network.
+type PromotionStatus struct {
+	// ProviderConfig is the ProviderConfig of the target network.
+	ProviderConfig string `json:"providerConfig"`
+
+	// Deployment is the name of the Deployment created on the target network.
+	// +optional
+	Deployment string `json:"deployment,omitempty"`
+
+	// Phase is the phase of the promotion.
+	Phase PromotionPhase `json:"phase"`
+
+	// Message details the phase, e.g. why the promotion was refused or the
+	// preflight check that failed.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// A BidDecision is the decision made on a bid.
+// +kubebuilder:validation:Enum=Accepted;Rejected
+type BidDecision string
+
+// Bid decisions.
+const (
+	BidDecisionAccepted BidDecision = "Accepted"
+	BidDecisionRejected BidDecision = "Rejected"
+)
+
+// A BidRecord records the decision made on a bid.
+type BidRecord struct {
+	// Provider is the address of the bidding provider.
+	Provider string `json:"provider"`
+
+	// Price is the amount per block asked by the provider.
+	Price string `json:"price"`
+
+	// Denom is the denomination of the price.
+	// +optional
+	Denom string `json:"denom,omitempty"`
+
+	// DisplayPrice is the price in the display unit of the denomination,
+	// e.g. AKT rather than uakt. It is only set for known denominations.
+	// +optional
+	DisplayPrice string `json:"displayPrice,omitempty"`
+
+	// DisplayDenom is the display unit of DisplayPrice, e.g. AKT or USDC.
+	// +optional
+	DisplayDenom string `json:"displayDenom,omitempty"`
+
+	// Decision is whether the bid was accepted or rejected.
+	Decision BidDecision `json:"decision"`
+
+	// Reason explains the decision.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// DecisionTime is when the decision was made.
+	DecisionTime metav1.Time `json:"decisionTime"`
+
+	// ProviderSnapshot is what was known about the provider at decision time.
+	ProviderSnapshot ProviderSnapshot `json:"providerSnapshot"`
+}
+
+// A ProviderSnapshot captures the details of a provider at a point in time.
+type ProviderSnapshot struct {
+	// HostURI is the gateway endpoint of the provider.
+	// +optional
+	HostURI string `json:"hostUri,omitempty"`
+
+	// Audited reports whether the provider was audited.
+	Audited bool `json:"audited"`
+
+	// Attributes are the attributes advertised by the provider.
+	// +optional
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// A ServiceStatus is the status of a service of a deployment.
+type ServiceStatus struct {
+	// Name is the name of the SDL service.
+	Name string `json:"name"`
+
+	// Available is the number of replicas of the service that are ready.
+	Available int32 `json:"available"`
+
+	// Total is the number of replicas of the service.
+	Total int32 `json:"total"`
+
+	// URIs are the hostnames the service is reachable at over HTTP.
+	// +optional
+	URIs []string `json:"uris,omitempty"`
+}
+
+// A ForwardedPort is a service port the provider exposes on one of its nodes.
+type ForwardedPort struct {
+	// Service is the name of the SDL service.
+	Service string `json:"service"`
+
+	// Host is the provider host the port is exposed on.
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// Port is the port of the service.
+	Port int32 `json:"port"`
+
+	// ExternalPort is the port assigned by the provider.
+	ExternalPort int32 `json:"externalPort"`
+
+	// Protocol is the protocol of the port, TCP or UDP.
+	// +optional
+	Protocol string `json:"protocol,omitempty"`
+}
+
+// TopUpStatus reports the deposits made into the escrow of a deployment.
+type TopUpStatus struct {
+	// Deposited is the total amount deposited, in Denom.
+	Deposited string `json:"deposited"`
+
+	// Denom of the deposits.
+	Denom string `json:"denom"`
+
+	// Count is the number of deposits made.
+	Count int `json:"count"`
+
+	// LastTopUpTime is when the last deposit was made.
+	// +optional
+	LastTopUpTime *metav1.Time `json:"lastTopUpTime,omitempty"`
+}
+
+// RecreationStatus reports the recreation of a deployment that failed on
+// chain.
+type RecreationStatus struct {
+	// Count is how many times the deployment was recreated.
+	Count int `json:"count"`
+
+	// PreviousDseq is the dseq of the deployment that failed last.
+	PreviousDseq string `json:"previousDseq"`
+
+	// Reason the deployment failed.
+	Reason string `json:"reason"`
+
+	// LastRecreateTime is when the deployment was last recreated.
+	LastRecreateTime metav1.Time `json:"lastRecreateTime"`
+}
+
+// SpendStatus compares the rate the escrow of a deployment drains at with the
+// prices agreed for its leases. Rates are amounts of Denom per block.
+type SpendStatus struct {
+	// Denom of the escrow balance and of the rates.
+	Denom string `json:"denom"`
+
+	// Balance of the escrow when it was last settled.
+	Balance string `json:"balance"`
+
+	// EscrowState is the state of the escrow account.
+	// +optional
+	EscrowState EscrowState `json:"escrowState,omitempty"`
+
+	// SettledAt is the height the escrow was last settled at.
+	SettledAt int64 `json:"settledAt"`
+
+	// AgreedRate is the sum of the prices of the active leases.
+	AgreedRate string `json:"agreedRate"`
+
+	// ObservedRate is the rate the escrow drained at between its last two
+	// settlements.
+	// +optional
+	ObservedRate string `json:"observedRate,omitempty"`
+
+	// DisplayDenom is the display unit of the denomination, e.g. AKT rather
+	// than uakt, that the display amounts are in. The display amounts are
+	// only set for known denominations.
+	// +optional
+	DisplayDenom string `json:"displayDenom,omitempty"`
+
+	// DisplayBalance is the balance in the display unit.
+	// +optional
+	DisplayBalance string `json:"displayBalance,omitempty"`
+
+	// DisplayAgreedRate is the agreed rate in the display unit.
+	// +optional
+	DisplayAgreedRate string `json:"displayAgreedRate,omitempty"`
+
+	// DisplayObservedRate is the observed rate in the display unit.
+	// +optional
+	DisplayObservedRate string `json:"displayObservedRate,omitempty"`
+
+	// Anomaly describes the last detected spend anomaly, if any.
+	// +optional
+	Anomaly string `json:"anomaly,omitempty"`
+}
+
+// ServiceHealth is the result of the last probe of a health check.
+type ServiceHealth struct {
+	// Service is the name of the probed SDL service.
+	Service string `json:"service"`
+
+	// Type of the probe.
+	Type HealthCheckType `json:"type"`
+
+	// Healthy is whether the last probe passed.
+	Healthy bool `json:"healthy"`
+
+	// Message explains why the last probe failed.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// LastProbeTime is when the service was last probed.
+	LastProbeTime metav1.Time `json:"lastProbeTime"`
+}
+
+// A LeasedIP is a service port exposed on a leased IP endpoint.
+type LeasedIP struct {
+	// Service is the name of the SDL service.
+	Service string `json:"service"`
+
+	// IP is the leased IP address.
+	IP string `json:"ip"`
+
+	// Port is the port of the service.
+	Port int32 `json:"port"`
+
+	// ExternalPort is the port exposed on the leased IP.
+	ExternalPort int32 `json:"externalPort"`
+
+	// Protocol is the protocol of the port, TCP or UDP.
+	// +optional
+	Protocol string `json:"protocol,omitempty"`
+}
+
+// A BidReportEntry describes a bid received for a Deployment.
+type BidReportEntry struct {
+	// Provider is the address of the bidding provider.
+	Provider string `json:"provider"`
+
+	// Price is the amount per block asked by the provider.
+	Price string `json:"price"`
+
+	// Denom is the denomination of the price.
+	// +optional
+	Denom string `json:"denom,omitempty"`
+
+	// DisplayPrice is the price in the display unit of the denomination,
+	// e.g. AKT rather than uakt. It is only set for known denominations.
+	// +optional
+	DisplayPrice string `json:"displayPrice,omitempty"`
+
+	// DisplayDenom is the display unit of DisplayPrice, e.g. AKT or USDC.
+	// +optional
+	DisplayDenom string `json:"displayDenom,omitempty"`
+
+	// Region is the region advertised by the provider, if any.
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// Audited reports whether the provider has been audited.
+	Audited bool `json:"audited"`
+}
+
+// ManifestDeliveryStatus records the latest manifest submission to a provider.
+type ManifestDeliveryStatus struct {
+	// Attempts is the number of submissions made during the latest delivery.
+	Attempts int `json:"attempts,omitempty"`
+
+	// LastAttemptTime is when the latest submission was made.
+	// +optional
+	LastAttemptTime *metav1.Time `json:"lastAttemptTime,omitempty"`
+
+	// LastError is the error returned by the latest failed submission, if any.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// Provider is the provider the manifest was submitted to.
+	// +optional
+	Provider string `json:"provider,omitempty"`
+
+	// Version is the base64 encoded version of the submitted manifest.
+	// +optional
+	Version string `json:"version,omitempty"`
 }
 
 // A DeploymentSpec defines the desired state of a Deployment.
 type DeploymentSpec struct {
-	xpv1.ResourceSpec `json:",inline"`
-	ForProvider       DeploymentParameters `json:"forProvider"`
+	xpv1.ResourceSpec            `json:",inline"`
+	NamespacedProviderConfigSpec `json:",inline"`
+	ForProvider                  DeploymentParameters `json:"forProvider"`
 }
 
 // A DeploymentStatus represents the observed state of a Deployment.
@@ -56,6 +1011,8 @@ type DeploymentStatus struct {
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,akash}
+// +kubebuilder:storageversion
+// +kubebuilder:deprecatedversion:warning="resource.akash.overlock.network/v1alpha1 Deployment is deprecated, use resource.akash.overlock.network/v1beta1"
 type Deployment struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -0,0 +1,154 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// LabelKeyDeployment is set on objects recorded for a Deployment to its name.
+const LabelKeyDeployment = "akash.web7.md/deployment"
+
+// LeaseStatusEvidence is the last lease status a provider returned, or the
+// error its gateway returned instead.
+type LeaseStatusEvidence struct {
+	// Provider is the address of the provider.
+	Provider string `json:"provider"`
+
+	// Gseq and Oseq identify the lease within the deployment.
+	Gseq int `json:"gseq"`
+	Oseq int `json:"oseq"`
+
+	// Response is the JSON lease status returned by the gateway.
+	// +optional
+	Response string `json:"response,omitempty"`
+
+	// GatewayError is the error returned by the gateway instead of a status.
+	// +optional
+	GatewayError string `json:"gatewayError,omitempty"`
+}
+
+// LeaseEvidence is a lease of a deployment as recorded on chain.
+type LeaseEvidence struct {
+	// Provider is the address of the provider.
+	Provider string `json:"provider"`
+
+	// Gseq and Oseq identify the lease within the deployment.
+	Gseq int `json:"gseq"`
+	Oseq int `json:"oseq"`
+
+	// State is the state of the lease on chain, e.g. active or closed.
+	State string `json:"state"`
+
+	// Price is the amount per block paid for the lease.
+	// +optional
+	Price string `json:"price,omitempty"`
+
+	// Denom is the denomination of the price.
+	// +optional
+	Denom string `json:"denom,omitempty"`
+
+	// CreatedAt is the height the lease was created at.
+	// +optional
+	CreatedAt string `json:"createdAt,omitempty"`
+
+	// ClosedOn is the height the lease was closed at.
+	// +optional
+	ClosedOn string `json:"closedOn,omitempty"`
+}
+
+// FailoverRecordSpec is the evidence captured before a Deployment was moved
+// away from a provider or torn down, kept so users can pursue provider
+// accountability or refunds afterwards.
+type FailoverRecordSpec struct {
+	// Deployment is the name of the Deployment.
+	Deployment string `json:"deployment"`
+
+	// Dseq is the sequence of the deployment on chain.
+	Dseq string `json:"dseq"`
+
+	// Provider is the address of the provider the Deployment left, if any.
+	// +optional
+	Provider string `json:"provider,omitempty"`
+
+	// Reason explains why the Deployment left the provider.
+	Reason string `json:"reason"`
+
+	// CapturedAt is when the evidence was captured.
+	CapturedAt metav1.Time `json:"capturedAt"`
+
+	// LeaseStatuses are the last lease statuses returned by the gateways of
+	// the providers of active leases.
+	// +optional
+	LeaseStatuses []LeaseStatusEvidence `json:"leaseStatuses,omitempty"`
+
+	// Leases are the leases of the deployment as recorded on chain.
+	// +optional
+	Leases []LeaseEvidence `json:"leases,omitempty"`
+
+	// Bids is the bid history of the Deployment.
+	// +optional
+	Bids []BidRecord `json:"bids,omitempty"`
+
+	// Events are the most recent events of the Deployment, one per line.
+	// +optional
+	Events []string `json:"events,omitempty"`
+
+	// Errors describe the evidence that could not be captured.
+	// +optional
+	Errors []string `json:"errors,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A FailoverRecord holds the evidence captured when a Deployment left a
+// provider. It is not reconciled and outlives the Deployment.
+// +kubebuilder:printcolumn:name="DEPLOYMENT",type="string",JSONPath=".spec.deployment"
+// +kubebuilder:printcolumn:name="PROVIDER",type="string",JSONPath=".spec.provider"
+// +kubebuilder:printcolumn:name="REASON",type="string",JSONPath=".spec.reason"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster,categories={akash}
+type FailoverRecord struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec FailoverRecordSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// FailoverRecordList contains a list of FailoverRecord
+type FailoverRecordList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FailoverRecord `json:"items"`
+}
+
+// FailoverRecord type metadata.
+var (
+	FailoverRecordKind             = reflect.TypeOf(FailoverRecord{}).Name()
+	FailoverRecordGroupKind        = schema.GroupKind{Group: Group, Kind: FailoverRecordKind}.String()
+	FailoverRecordKindAPIVersion   = FailoverRecordKind + "." + SchemeGroupVersion.String()
+	FailoverRecordGroupVersionKind = SchemeGroupVersion.WithKind(FailoverRecordKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&FailoverRecord{}, &FailoverRecordList{})
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// FeeGrantParameters are the configurable fields of a FeeGrant.
+type FeeGrantParameters struct {
+	// Grantee is the address of the account whose transaction fees the
+	// account of the ProviderConfig pays.
+	// +kubebuilder:validation:Pattern=`^akash1[02-9ac-hj-np-z]+$`
+	Grantee string `json:"grantee"`
+
+	// SpendLimit are the coins the grantee may spend on fees. Unlimited
+	// when unset.
+	// +optional
+	SpendLimit []Coin `json:"spendLimit,omitempty"`
+
+	// Expiration is when the allowance expires. Never when unset.
+	// +optional
+	Expiration *metav1.Time `json:"expiration,omitempty"`
+
+	// AllowedMessages restricts the allowance to transactions of the given
+	// message types, e.g. /akash.deployment.v1beta3.MsgCreateDeployment.
+	// +optional
+	AllowedMessages []string `json:"allowedMessages,omitempty"`
+}
+
+// FeeGrantObservation are the observable fields of a FeeGrant.
+type FeeGrantObservation struct {
+	// Granter is the address of the account paying the fees.
+	Granter string `json:"granter,omitempty"`
+
+	// SpendLimit are the coins the grantee may still spend on fees.
+	// +optional
+	SpendLimit []Coin `json:"spendLimit,omitempty"`
+
+	// Expiration is when the allowance expires.
+	// +optional
+	Expiration *metav1.Time `json:"expiration,omitempty"`
+
+	// AllowedMessages are the message types the allowance is restricted to.
+	// +optional
+	AllowedMessages []string `json:"allowedMessages,omitempty"`
+
+	// GrantedSpendLimit is the spend limit the allowance was granted with,
+	// which the remaining SpendLimit cannot be compared to.
+	// +optional
+	GrantedSpendLimit []Coin `json:"grantedSpendLimit,omitempty"`
+}
+
+// A FeeGrantSpec defines the desired state of a FeeGrant.
+type FeeGrantSpec struct {
+	xpv1.ResourceSpec            `json:",inline"`
+	NamespacedProviderConfigSpec `json:",inline"`
+	ForProvider                  FeeGrantParameters `json:"forProvider"`
+}
+
+// A FeeGrantStatus represents the observed state of a FeeGrant.
+type FeeGrantStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          FeeGrantObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A FeeGrant is a fee allowance the account of its ProviderConfig, e.g. a
+// treasury, grants another account, e.g. the owner of deployments whose
+// ProviderConfig sets it as feeGranter. Allowances cannot be changed on
+// chain, so they are revoked and granted again when the FeeGrant changes.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="GRANTEE",type="string",JSONPath=".spec.forProvider.grantee"
+// +kubebuilder:printcolumn:name="EXPIRATION",type="string",JSONPath=".status.atProvider.expiration"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,akash}
+type FeeGrant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   FeeGrantSpec   `json:"spec"`
+	Status FeeGrantStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// FeeGrantList contains a list of FeeGrant
+type FeeGrantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FeeGrant `json:"items"`
+}
+
+// FeeGrant type metadata.
+var (
+	FeeGrantKind             = reflect.TypeOf(FeeGrant{}).Name()
+	FeeGrantGroupKind        = schema.GroupKind{Group: Group, Kind: FeeGrantKind}.String()
+	FeeGrantKindAPIVersion   = FeeGrantKind + "." + SchemeGroupVersion.String()
+	FeeGrantGroupVersionKind = SchemeGroupVersion.WithKind(FeeGrantKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&FeeGrant{}, &FeeGrantList{})
+}
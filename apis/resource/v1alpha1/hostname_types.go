@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// HostnameParameters are the configurable fields of a Hostname.
+type HostnameParameters struct {
+	// Hostname is the custom domain, which has to resolve to the ingress of
+	// the provider leasing the deployment.
+	// +kubebuilder:validation:Pattern=`^([a-z0-9]([-a-z0-9]*[a-z0-9])?\.)+[a-z]{2,}$`
+	Hostname string `json:"hostname"`
+
+	// Deployment is the name of the Deployment whose lease serves the
+	// hostname. Its SDL has to accept the hostname.
+	Deployment string `json:"deployment"`
+
+	// Gseq is the sequence of the group of the deployment whose lease serves
+	// the hostname.
+	// +optional
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	Gseq int `json:"gseq,omitempty"`
+}
+
+// HostnameObservation are the observable fields of a Hostname.
+type HostnameObservation struct {
+	// Dseq of the deployment serving the hostname.
+	Dseq string `json:"dseq,omitempty"`
+
+	// Provider of the lease serving the hostname.
+	Provider string `json:"provider,omitempty"`
+
+	// Service is the service of the deployment the hostname routes to.
+	Service string `json:"service,omitempty"`
+}
+
+// A HostnameSpec defines the desired state of a Hostname.
+type HostnameSpec struct {
+	xpv1.ResourceSpec            `json:",inline"`
+	NamespacedProviderConfigSpec `json:",inline"`
+	ForProvider                  HostnameParameters `json:"forProvider"`
+}
+
+// A HostnameStatus represents the observed state of a Hostname.
+type HostnameStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          HostnameObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Hostname binds a custom domain to the lease of a Deployment, migrating
+// it from another deployment of the same owner on the same provider if
+// needed. Providers release a hostname once no lease serves it, so deleting
+// a Hostname leaves it with its deployment.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="HOSTNAME",type="string",JSONPath=".spec.forProvider.hostname"
+// +kubebuilder:printcolumn:name="DEPLOYMENT",type="string",JSONPath=".spec.forProvider.deployment"
+// +kubebuilder:printcolumn:name="SERVICE",type="string",JSONPath=".status.atProvider.service"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,akash}
+type Hostname struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HostnameSpec   `json:"spec"`
+	Status HostnameStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// HostnameList contains a list of Hostname
+type HostnameList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Hostname `json:"items"`
+}
+
+// Hostname type metadata.
+var (
+	HostnameKind             = reflect.TypeOf(Hostname{}).Name()
+	HostnameGroupKind        = schema.GroupKind{Group: Group, Kind: HostnameKind}.String()
+	HostnameKindAPIVersion   = HostnameKind + "." + SchemeGroupVersion.String()
+	HostnameGroupVersionKind = SchemeGroupVersion.WithKind(HostnameKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&Hostname{}, &HostnameList{})
+}
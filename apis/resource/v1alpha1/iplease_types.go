@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// IPLeaseParameters are the configurable fields of an IPLease.
+type IPLeaseParameters struct {
+	// Deployment is the name of the Deployment whose SDL declares the IP
+	// endpoints.
+	Deployment string `json:"deployment"`
+
+	// Service restricts the IPLease to the IP endpoints of the named SDL
+	// service. The IP endpoints of every service are observed when unset.
+	// +optional
+	Service *string `json:"service,omitempty"`
+}
+
+// IPLeaseObservation are the observable fields of an IPLease.
+type IPLeaseObservation struct {
+	// Dseq of the deployment the IP endpoints are leased for.
+	Dseq string `json:"dseq,omitempty"`
+
+	// IPs are the service ports exposed on the leased IP endpoints.
+	// +optional
+	IPs []LeasedIP `json:"ips,omitempty"`
+}
+
+// An IPLeaseSpec defines the desired state of an IPLease.
+type IPLeaseSpec struct {
+	xpv1.ResourceSpec            `json:",inline"`
+	NamespacedProviderConfigSpec `json:",inline"`
+	ForProvider                  IPLeaseParameters `json:"forProvider"`
+}
+
+// An IPLeaseStatus represents the observed state of an IPLease.
+type IPLeaseStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          IPLeaseObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// An IPLease observes the IP endpoints providers lease to a Deployment whose
+// SDL declares endpoints of kind ip, and publishes the leased addresses as
+// connection details: the first IP keyed by ip, the IP of every service keyed
+// by <service>.ip and the address of every port keyed by
+// <service>.<port>.<protocol>.ip. The IP endpoints are leased and released
+// together with the deployment.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="DEPLOYMENT",type="string",JSONPath=".spec.forProvider.deployment"
+// +kubebuilder:printcolumn:name="IP",type="string",JSONPath=".status.atProvider.ips[0].ip"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,akash}
+type IPLease struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IPLeaseSpec   `json:"spec"`
+	Status IPLeaseStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// IPLeaseList contains a list of IPLease
+type IPLeaseList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IPLease `json:"items"`
+}
+
+// IPLease type metadata.
+var (
+	IPLeaseKind             = reflect.TypeOf(IPLease{}).Name()
+	IPLeaseGroupKind        = schema.GroupKind{Group: Group, Kind: IPLeaseKind}.String()
+	IPLeaseKindAPIVersion   = IPLeaseKind + "." + SchemeGroupVersion.String()
+	IPLeaseGroupVersionKind = SchemeGroupVersion.WithKind(IPLeaseKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&IPLease{}, &IPLeaseList{})
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// ManifestParameters are the configurable fields of a Manifest.
+type ManifestParameters struct {
+	// Dseq is the sequence number of the deployment the manifest is
+	// submitted for.
+	// +kubebuilder:validation:Pattern=`^[0-9]+$`
+	Dseq string `json:"dseq"`
+
+	// Provider is the address of the provider leasing the deployment.
+	// +kubebuilder:validation:Pattern=`^akash1[02-9ac-hj-np-z]+$`
+	Provider string `json:"provider"`
+
+	// SDL the manifest is built from. Its groups have to match the groups
+	// of the deployment on chain.
+	SDL string `json:"sdl"`
+}
+
+// ManifestObservation are the observable fields of a Manifest.
+type ManifestObservation struct {
+	// Version is the base64 encoded version of the manifest stored by the
+	// provider, comparable to the version of the deployment on chain.
+	Version string `json:"version,omitempty"`
+}
+
+// A ManifestSpec defines the desired state of a Manifest.
+type ManifestSpec struct {
+	xpv1.ResourceSpec            `json:",inline"`
+	NamespacedProviderConfigSpec `json:",inline"`
+	ForProvider                  ManifestParameters `json:"forProvider"`
+}
+
+// A ManifestStatus represents the observed state of a Manifest.
+type ManifestStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          ManifestObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Manifest is the manifest of a deployment submitted to the provider
+// leasing it, decoupled from the Deployment creating the deployment, e.g.
+// for deployments created outside of Crossplane. The manifest stored by the
+// provider is compared to the manifest of the SDL and submitted again when
+// they differ. Providers drop the manifest when the lease is closed, so
+// deleting a Manifest leaves it in place.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="DSEQ",type="string",JSONPath=".spec.forProvider.dseq"
+// +kubebuilder:printcolumn:name="PROVIDER",type="string",JSONPath=".spec.forProvider.provider"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,akash}
+type Manifest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ManifestSpec   `json:"spec"`
+	Status ManifestStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ManifestList contains a list of Manifest
+type ManifestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Manifest `json:"items"`
+}
+
+// Manifest type metadata.
+var (
+	ManifestKind             = reflect.TypeOf(Manifest{}).Name()
+	ManifestGroupKind        = schema.GroupKind{Group: Group, Kind: ManifestKind}.String()
+	ManifestKindAPIVersion   = ManifestKind + "." + SchemeGroupVersion.String()
+	ManifestGroupVersionKind = SchemeGroupVersion.WithKind(ManifestKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&Manifest{}, &ManifestList{})
+}
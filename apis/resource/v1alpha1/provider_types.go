@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// ProviderParameters are the configurable fields of a Provider.
+type ProviderParameters struct {
+	// Address of the observed provider.
+	// +kubebuilder:validation:Pattern=`^akash1[02-9ac-hj-np-z]+$`
+	Address string `json:"address"`
+}
+
+// ProviderObservation are the observable fields of a Provider.
+type ProviderObservation struct {
+	// HostURI is the address of the gateway of the provider.
+	HostURI string `json:"hostURI,omitempty"`
+
+	// Attributes the provider advertises on chain.
+	// +optional
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	// Email of the operator of the provider.
+	// +optional
+	Email string `json:"email,omitempty"`
+
+	// Website of the operator of the provider.
+	// +optional
+	Website string `json:"website,omitempty"`
+
+	// Auditors that signed attributes of the provider.
+	// +optional
+	Auditors []string `json:"auditors,omitempty"`
+
+	// Audited is whether the providers API considers the provider audited.
+	// +optional
+	Audited bool `json:"audited,omitempty"`
+
+	// Active is whether the providers API considers the provider online.
+	// +optional
+	Active bool `json:"active,omitempty"`
+
+	// Uptime of the provider as reported by the providers API, between 0
+	// and 1.
+	// +optional
+	Uptime string `json:"uptime,omitempty"`
+
+	// Reachable is whether the gateway of the provider answered the last
+	// probe.
+	// +optional
+	Reachable bool `json:"reachable,omitempty"`
+
+	// GatewayFailures is the number of consecutive probes of the gateway of
+	// the provider that failed.
+	// +optional
+	GatewayFailures int `json:"gatewayFailures,omitempty"`
+
+	// Version is the provider-services release served by the gateway.
+	// +optional
+	Version string `json:"version,omitempty"`
+}
+
+// A ProviderSpec defines the desired state of a Provider.
+type ProviderSpec struct {
+	xpv1.ResourceSpec            `json:",inline"`
+	NamespacedProviderConfigSpec `json:",inline"`
+	ForProvider                  ProviderParameters `json:"forProvider"`
+}
+
+// A ProviderStatus represents the observed state of a Provider.
+type ProviderStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          ProviderObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Provider observes a provider of the on-chain provider directory.
+// Providers are never created or deleted on chain.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="ADDRESS",type="string",JSONPath=".spec.forProvider.address"
+// +kubebuilder:printcolumn:name="HOST",type="string",JSONPath=".status.atProvider.hostURI"
+// +kubebuilder:printcolumn:name="AUDITED",type="boolean",JSONPath=".status.atProvider.audited"
+// +kubebuilder:printcolumn:name="REACHABLE",type="boolean",JSONPath=".status.atProvider.reachable"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,akash}
+type Provider struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProviderSpec   `json:"spec"`
+	Status ProviderStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ProviderList contains a list of Provider
+type ProviderList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Provider `json:"items"`
+}
+
+// Provider type metadata.
+var (
+	ProviderKind             = reflect.TypeOf(Provider{}).Name()
+	ProviderGroupKind        = schema.GroupKind{Group: Group, Kind: ProviderKind}.String()
+	ProviderKindAPIVersion   = ProviderKind + "." + SchemeGroupVersion.String()
+	ProviderGroupVersionKind = SchemeGroupVersion.WithKind(ProviderKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&Provider{}, &ProviderList{})
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// A NamespacedProviderConfigSpec selects the NamespacedProviderConfig a
+// resource composed for a claim is configured by instead of its
+// ProviderConfig.
+type NamespacedProviderConfigSpec struct {
+	// NamespacedProviderConfigReference names the NamespacedProviderConfig,
+	// in the namespace of the claim the resource was composed for, that
+	// configures the resource instead of its ProviderConfig. Resources not
+	// composed for a claim cannot reference one.
+	// +optional
+	NamespacedProviderConfigReference *xpv1.Reference `json:"namespacedProviderConfigRef,omitempty"`
+}
+
+// GetNamespacedProviderConfigReference of this Account.
+func (mg *Account) GetNamespacedProviderConfigReference() *xpv1.Reference {
+	return mg.Spec.NamespacedProviderConfigReference
+}
+
+// GetNamespacedProviderConfigReference of this Audit.
+func (mg *Audit) GetNamespacedProviderConfigReference() *xpv1.Reference {
+	return mg.Spec.NamespacedProviderConfigReference
+}
+
+// GetNamespacedProviderConfigReference of this AuthzGrant.
+func (mg *AuthzGrant) GetNamespacedProviderConfigReference() *xpv1.Reference {
+	return mg.Spec.NamespacedProviderConfigReference
+}
+
+// GetNamespacedProviderConfigReference of this Deployment.
+func (mg *Deployment) GetNamespacedProviderConfigReference() *xpv1.Reference {
+	return mg.Spec.NamespacedProviderConfigReference
+}
+
+// GetNamespacedProviderConfigReference of this FeeGrant.
+func (mg *FeeGrant) GetNamespacedProviderConfigReference() *xpv1.Reference {
+	return mg.Spec.NamespacedProviderConfigReference
+}
+
+// GetNamespacedProviderConfigReference of this Hostname.
+func (mg *Hostname) GetNamespacedProviderConfigReference() *xpv1.Reference {
+	return mg.Spec.NamespacedProviderConfigReference
+}
+
+// GetNamespacedProviderConfigReference of this IPLease.
+func (mg *IPLease) GetNamespacedProviderConfigReference() *xpv1.Reference {
+	return mg.Spec.NamespacedProviderConfigReference
+}
+
+// GetNamespacedProviderConfigReference of this Manifest.
+func (mg *Manifest) GetNamespacedProviderConfigReference() *xpv1.Reference {
+	return mg.Spec.NamespacedProviderConfigReference
+}
+
+// GetNamespacedProviderConfigReference of this Provider.
+func (mg *Provider) GetNamespacedProviderConfigReference() *xpv1.Reference {
+	return mg.Spec.NamespacedProviderConfigReference
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import "strings"
+
+// A DeploymentState is the state of a deployment on chain.
+// +kubebuilder:validation:Enum=Active;Closed;Invalid
+type DeploymentState string
+
+// Deployment states.
+const (
+	// DeploymentStateActive deployments can be leased and are charged.
+	DeploymentStateActive DeploymentState = "Active"
+	// DeploymentStateClosed deployments were closed and refunded.
+	DeploymentStateClosed DeploymentState = "Closed"
+	// DeploymentStateInvalid is reported for states this API does not know.
+	DeploymentStateInvalid DeploymentState = "Invalid"
+)
+
+// A LeaseState is the state of a lease on chain.
+// +kubebuilder:validation:Enum=Active;InsufficientFunds;Closed;Invalid
+type LeaseState string
+
+// Lease states.
+const (
+	// LeaseStateActive leases are running and paid for.
+	LeaseStateActive LeaseState = "Active"
+	// LeaseStateInsufficientFunds leases were closed because the escrow of
+	// the deployment ran out of funds.
+	LeaseStateInsufficientFunds LeaseState = "InsufficientFunds"
+	// LeaseStateClosed leases were closed by the tenant or the provider.
+	LeaseStateClosed LeaseState = "Closed"
+	// LeaseStateInvalid is reported for states this API does not know.
+	LeaseStateInvalid LeaseState = "Invalid"
+)
+
+// A BidState is the state of a bid on chain.
+// +kubebuilder:validation:Enum=Open;Active;Lost;Closed;Invalid
+type BidState string
+
+// Bid states.
+const (
+	// BidStateOpen bids await the decision of the tenant.
+	BidStateOpen BidState = "Open"
+	// BidStateActive bids were accepted and have an active lease.
+	BidStateActive BidState = "Active"
+	// BidStateLost bids lost to the bid of another provider.
+	BidStateLost BidState = "Lost"
+	// BidStateClosed bids were closed.
+	BidStateClosed BidState = "Closed"
+	// BidStateInvalid is reported for states this API does not know.
+	BidStateInvalid BidState = "Invalid"
+)
+
+// An EscrowState is the state of an escrow account or payment on chain.
+// +kubebuilder:validation:Enum=Open;Closed;Overdrawn;Invalid
+type EscrowState string
+
+// Escrow states.
+const (
+	// EscrowStateOpen escrows are funded and paying out.
+	EscrowStateOpen EscrowState = "Open"
+	// EscrowStateClosed escrows were closed and their balance returned.
+	EscrowStateClosed EscrowState = "Closed"
+	// EscrowStateOverdrawn escrows ran out of funds.
+	EscrowStateOverdrawn EscrowState = "Overdrawn"
+	// EscrowStateInvalid is reported for states this API does not know.
+	EscrowStateInvalid EscrowState = "Invalid"
+)
+
+// chainState normalizes a state reported by the chain, which depending on the
+// client is the name of the enum value, e.g. active, or its prefixed
+// constant, e.g. STATE_ACTIVE or LeaseInsufficientFunds.
+func chainState(s string) string {
+	s = strings.ToLower(s)
+	s = strings.TrimPrefix(s, "state_")
+	s = strings.ReplaceAll(s, "_", "")
+	for _, prefix := range []string{"deployment", "lease", "bid", "account", "payment"} {
+		s = strings.TrimPrefix(s, prefix)
+	}
+	return s
+}
+
+// DeploymentStateFromChain converts a deployment state reported by the chain.
+func DeploymentStateFromChain(s string) DeploymentState {
+	switch chainState(s) {
+	case "active":
+		return DeploymentStateActive
+	case "closed":
+		return DeploymentStateClosed
+	}
+	return DeploymentStateInvalid
+}
+
+// LeaseStateFromChain converts a lease state reported by the chain.
+func LeaseStateFromChain(s string) LeaseState {
+	switch chainState(s) {
+	case "active":
+		return LeaseStateActive
+	case "insufficientfunds":
+		return LeaseStateInsufficientFunds
+	case "closed":
+		return LeaseStateClosed
+	}
+	return LeaseStateInvalid
+}
+
+// BidStateFromChain converts a bid state reported by the chain.
+func BidStateFromChain(s string) BidState {
+	switch chainState(s) {
+	case "open":
+		return BidStateOpen
+	case "active", "matched":
+		return BidStateActive
+	case "lost":
+		return BidStateLost
+	case "closed":
+		return BidStateClosed
+	}
+	return BidStateInvalid
+}
+
+// EscrowStateFromChain converts an escrow account or payment state reported
+// by the chain.
+func EscrowStateFromChain(s string) EscrowState {
+	switch chainState(s) {
+	case "open":
+		return EscrowStateOpen
+	case "closed":
+		return EscrowStateClosed
+	case "overdrawn":
+		return EscrowStateOverdrawn
+	}
+	return EscrowStateInvalid
+}
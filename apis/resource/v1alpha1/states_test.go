@@ -0,0 +1,185 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDeploymentStateFromChain(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		state  string
+		want   DeploymentState
+	}{
+		"EnumName": {
+			reason: "The name of the enum value should be converted.",
+			state:  "active",
+			want:   DeploymentStateActive,
+		},
+		"PrefixedConstant": {
+			reason: "The prefixed constant of the enum value should be converted.",
+			state:  "DeploymentClosed",
+			want:   DeploymentStateClosed,
+		},
+		"ProtoConstant": {
+			reason: "The protobuf constant of the enum value should be converted.",
+			state:  "STATE_ACTIVE",
+			want:   DeploymentStateActive,
+		},
+		"Unknown": {
+			reason: "States this API does not know should be invalid.",
+			state:  "invalid",
+			want:   DeploymentStateInvalid,
+		},
+		"Empty": {
+			reason: "A missing state should be invalid.",
+			want:   DeploymentStateInvalid,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := DeploymentStateFromChain(tc.state)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nDeploymentStateFromChain(%q): -want, +got:\n%s", tc.reason, tc.state, diff)
+			}
+		})
+	}
+}
+
+func TestLeaseStateFromChain(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		state  string
+		want   LeaseState
+	}{
+		"EnumName": {
+			reason: "The name of the enum value should be converted.",
+			state:  "insufficient_funds",
+			want:   LeaseStateInsufficientFunds,
+		},
+		"PrefixedConstant": {
+			reason: "The prefixed constant of the enum value should be converted.",
+			state:  "LeaseInsufficientFunds",
+			want:   LeaseStateInsufficientFunds,
+		},
+		"ProtoConstant": {
+			reason: "The protobuf constant of the enum value should be converted.",
+			state:  "STATE_CLOSED",
+			want:   LeaseStateClosed,
+		},
+		"Active": {
+			reason: "Active leases should be active.",
+			state:  "active",
+			want:   LeaseStateActive,
+		},
+		"Unknown": {
+			reason: "States this API does not know should be invalid.",
+			state:  "paused",
+			want:   LeaseStateInvalid,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := LeaseStateFromChain(tc.state)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nLeaseStateFromChain(%q): -want, +got:\n%s", tc.reason, tc.state, diff)
+			}
+		})
+	}
+}
+
+func TestBidStateFromChain(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		state  string
+		want   BidState
+	}{
+		"Open": {
+			reason: "Open bids should be open.",
+			state:  "open",
+			want:   BidStateOpen,
+		},
+		"Matched": {
+			reason: "Matched bids, as older chains call them, should be active.",
+			state:  "matched",
+			want:   BidStateActive,
+		},
+		"PrefixedConstant": {
+			reason: "The prefixed constant of the enum value should be converted.",
+			state:  "BidLost",
+			want:   BidStateLost,
+		},
+		"ProtoConstant": {
+			reason: "The protobuf constant of the enum value should be converted.",
+			state:  "STATE_CLOSED",
+			want:   BidStateClosed,
+		},
+		"Unknown": {
+			reason: "States this API does not know should be invalid.",
+			state:  "pending",
+			want:   BidStateInvalid,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := BidStateFromChain(tc.state)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nBidStateFromChain(%q): -want, +got:\n%s", tc.reason, tc.state, diff)
+			}
+		})
+	}
+}
+
+func TestEscrowStateFromChain(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		state  string
+		want   EscrowState
+	}{
+		"EnumName": {
+			reason: "The name of the enum value should be converted.",
+			state:  "overdrawn",
+			want:   EscrowStateOverdrawn,
+		},
+		"AccountConstant": {
+			reason: "The prefixed constant of an account state should be converted.",
+			state:  "AccountOpen",
+			want:   EscrowStateOpen,
+		},
+		"PaymentConstant": {
+			reason: "The prefixed constant of a payment state should be converted.",
+			state:  "PaymentClosed",
+			want:   EscrowStateClosed,
+		},
+		"Unknown": {
+			reason: "States this API does not know should be invalid.",
+			state:  "frozen",
+			want:   EscrowStateInvalid,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := EscrowStateFromChain(tc.state)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nEscrowStateFromChain(%q): -want, +got:\n%s", tc.reason, tc.state, diff)
+			}
+		})
+	}
+}
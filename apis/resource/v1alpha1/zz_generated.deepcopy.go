@@ -21,9 +21,622 @@ limitations under the License.
 package v1alpha1
 
 import (
+	commonv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Account) DeepCopyInto(out *Account) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Account.
+func (in *Account) DeepCopy() *Account {
+	if in == nil {
+		return nil
+	}
+	out := new(Account)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Account) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccountList) DeepCopyInto(out *AccountList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Account, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccountList.
+func (in *AccountList) DeepCopy() *AccountList {
+	if in == nil {
+		return nil
+	}
+	out := new(AccountList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AccountList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccountObservation) DeepCopyInto(out *AccountObservation) {
+	*out = *in
+	if in.Balances != nil {
+		in, out := &in.Balances, &out.Balances
+		*out = make([]Coin, len(*in))
+		copy(*out, *in)
+	}
+	if in.Delegations != nil {
+		in, out := &in.Delegations, &out.Delegations
+		*out = make([]Delegation, len(*in))
+		copy(*out, *in)
+	}
+	if in.EscrowObligations != nil {
+		in, out := &in.EscrowObligations, &out.EscrowObligations
+		*out = make([]Coin, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccountObservation.
+func (in *AccountObservation) DeepCopy() *AccountObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(AccountObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccountParameters) DeepCopyInto(out *AccountParameters) {
+	*out = *in
+	if in.Address != nil {
+		in, out := &in.Address, &out.Address
+		*out = new(string)
+		**out = **in
+	}
+	if in.LowBalance != nil {
+		in, out := &in.LowBalance, &out.LowBalance
+		*out = new(BalanceThreshold)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccountParameters.
+func (in *AccountParameters) DeepCopy() *AccountParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(AccountParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccountSpec) DeepCopyInto(out *AccountSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.NamespacedProviderConfigSpec.DeepCopyInto(&out.NamespacedProviderConfigSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccountSpec.
+func (in *AccountSpec) DeepCopy() *AccountSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AccountSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccountStatus) DeepCopyInto(out *AccountStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccountStatus.
+func (in *AccountStatus) DeepCopy() *AccountStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AccountStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Audit) DeepCopyInto(out *Audit) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Audit.
+func (in *Audit) DeepCopy() *Audit {
+	if in == nil {
+		return nil
+	}
+	out := new(Audit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Audit) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditList) DeepCopyInto(out *AuditList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Audit, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditList.
+func (in *AuditList) DeepCopy() *AuditList {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AuditList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditObservation) DeepCopyInto(out *AuditObservation) {
+	*out = *in
+	if in.Records != nil {
+		in, out := &in.Records, &out.Records
+		*out = make([]AuditRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditObservation.
+func (in *AuditObservation) DeepCopy() *AuditObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditParameters) DeepCopyInto(out *AuditParameters) {
+	*out = *in
+	if in.Auditor != nil {
+		in, out := &in.Auditor, &out.Auditor
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditParameters.
+func (in *AuditParameters) DeepCopy() *AuditParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditRecord) DeepCopyInto(out *AuditRecord) {
+	*out = *in
+	if in.Attributes != nil {
+		in, out := &in.Attributes, &out.Attributes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditRecord.
+func (in *AuditRecord) DeepCopy() *AuditRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditSpec) DeepCopyInto(out *AuditSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.NamespacedProviderConfigSpec.DeepCopyInto(&out.NamespacedProviderConfigSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditSpec.
+func (in *AuditSpec) DeepCopy() *AuditSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditStatus) DeepCopyInto(out *AuditStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditStatus.
+func (in *AuditStatus) DeepCopy() *AuditStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthzGrant) DeepCopyInto(out *AuthzGrant) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthzGrant.
+func (in *AuthzGrant) DeepCopy() *AuthzGrant {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthzGrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AuthzGrant) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthzGrantList) DeepCopyInto(out *AuthzGrantList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AuthzGrant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthzGrantList.
+func (in *AuthzGrantList) DeepCopy() *AuthzGrantList {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthzGrantList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AuthzGrantList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthzGrantObservation) DeepCopyInto(out *AuthzGrantObservation) {
+	*out = *in
+	if in.SpendLimit != nil {
+		in, out := &in.SpendLimit, &out.SpendLimit
+		*out = new(Coin)
+		**out = **in
+	}
+	if in.Expiration != nil {
+		in, out := &in.Expiration, &out.Expiration
+		*out = (*in).DeepCopy()
+	}
+	if in.GrantedSpendLimit != nil {
+		in, out := &in.GrantedSpendLimit, &out.GrantedSpendLimit
+		*out = new(Coin)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthzGrantObservation.
+func (in *AuthzGrantObservation) DeepCopy() *AuthzGrantObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthzGrantObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthzGrantParameters) DeepCopyInto(out *AuthzGrantParameters) {
+	*out = *in
+	out.SpendLimit = in.SpendLimit
+	if in.Validity != nil {
+		in, out := &in.Validity, &out.Validity
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthzGrantParameters.
+func (in *AuthzGrantParameters) DeepCopy() *AuthzGrantParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthzGrantParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthzGrantSpec) DeepCopyInto(out *AuthzGrantSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.NamespacedProviderConfigSpec.DeepCopyInto(&out.NamespacedProviderConfigSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthzGrantSpec.
+func (in *AuthzGrantSpec) DeepCopy() *AuthzGrantSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthzGrantSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthzGrantStatus) DeepCopyInto(out *AuthzGrantStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthzGrantStatus.
+func (in *AuthzGrantStatus) DeepCopy() *AuthzGrantStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthzGrantStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoTopUp) DeepCopyInto(out *AutoTopUp) {
+	*out = *in
+	if in.MaxTotal != nil {
+		in, out := &in.MaxTotal, &out.MaxTotal
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoTopUp.
+func (in *AutoTopUp) DeepCopy() *AutoTopUp {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoTopUp)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BalanceThreshold) DeepCopyInto(out *BalanceThreshold) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BalanceThreshold.
+func (in *BalanceThreshold) DeepCopy() *BalanceThreshold {
+	if in == nil {
+		return nil
+	}
+	out := new(BalanceThreshold)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BidRecord) DeepCopyInto(out *BidRecord) {
+	*out = *in
+	in.DecisionTime.DeepCopyInto(&out.DecisionTime)
+	in.ProviderSnapshot.DeepCopyInto(&out.ProviderSnapshot)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BidRecord.
+func (in *BidRecord) DeepCopy() *BidRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(BidRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BidReportEntry) DeepCopyInto(out *BidReportEntry) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BidReportEntry.
+func (in *BidReportEntry) DeepCopy() *BidReportEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(BidReportEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BidSelection) DeepCopyInto(out *BidSelection) {
+	*out = *in
+	if in.Weights != nil {
+		in, out := &in.Weights, &out.Weights
+		*out = new(BidSelectionWeights)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BidSelection.
+func (in *BidSelection) DeepCopy() *BidSelection {
+	if in == nil {
+		return nil
+	}
+	out := new(BidSelection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BidSelectionWeights) DeepCopyInto(out *BidSelectionWeights) {
+	*out = *in
+	if in.Price != nil {
+		in, out := &in.Price, &out.Price
+		*out = new(int)
+		**out = **in
+	}
+	if in.Audited != nil {
+		in, out := &in.Audited, &out.Audited
+		*out = new(int)
+		**out = **in
+	}
+	if in.Uptime != nil {
+		in, out := &in.Uptime, &out.Uptime
+		*out = new(int)
+		**out = **in
+	}
+	if in.Attributes != nil {
+		in, out := &in.Attributes, &out.Attributes
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BidSelectionWeights.
+func (in *BidSelectionWeights) DeepCopy() *BidSelectionWeights {
+	if in == nil {
+		return nil
+	}
+	out := new(BidSelectionWeights)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Coin) DeepCopyInto(out *Coin) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Coin.
+func (in *Coin) DeepCopy() *Coin {
+	if in == nil {
+		return nil
+	}
+	out := new(Coin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Delegation) DeepCopyInto(out *Delegation) {
+	*out = *in
+	out.Balance = in.Balance
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Delegation.
+func (in *Delegation) DeepCopy() *Delegation {
+	if in == nil {
+		return nil
+	}
+	out := new(Delegation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Deployment) DeepCopyInto(out *Deployment) {
 	*out = *in
@@ -33,18 +646,1297 @@ func (in *Deployment) DeepCopyInto(out *Deployment) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Deployment.
-func (in *Deployment) DeepCopy() *Deployment {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Deployment.
+func (in *Deployment) DeepCopy() *Deployment {
+	if in == nil {
+		return nil
+	}
+	out := new(Deployment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Deployment) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentList) DeepCopyInto(out *DeploymentList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Deployment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentList.
+func (in *DeploymentList) DeepCopy() *DeploymentList {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeploymentList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentObservation) DeepCopyInto(out *DeploymentObservation) {
+	*out = *in
+	if in.ManifestDelivery != nil {
+		in, out := &in.ManifestDelivery, &out.ManifestDelivery
+		*out = new(ManifestDeliveryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BidReport != nil {
+		in, out := &in.BidReport, &out.BidReport
+		*out = make([]BidReportEntry, len(*in))
+		copy(*out, *in)
+	}
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = make([]ServiceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ForwardedPorts != nil {
+		in, out := &in.ForwardedPorts, &out.ForwardedPorts
+		*out = make([]ForwardedPort, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPs != nil {
+		in, out := &in.IPs, &out.IPs
+		*out = make([]LeasedIP, len(*in))
+		copy(*out, *in)
+	}
+	if in.Bids != nil {
+		in, out := &in.Bids, &out.Bids
+		*out = make([]BidRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Promotion != nil {
+		in, out := &in.Promotion, &out.Promotion
+		*out = new(PromotionStatus)
+		**out = **in
+	}
+	if in.UnleasedSince != nil {
+		in, out := &in.UnleasedSince, &out.UnleasedSince
+		*out = (*in).DeepCopy()
+	}
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+	if in.ServiceHealth != nil {
+		in, out := &in.ServiceHealth, &out.ServiceHealth
+		*out = make([]ServiceHealth, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Spend != nil {
+		in, out := &in.Spend, &out.Spend
+		*out = new(SpendStatus)
+		**out = **in
+	}
+	if in.Recreation != nil {
+		in, out := &in.Recreation, &out.Recreation
+		*out = new(RecreationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopUp != nil {
+		in, out := &in.TopUp, &out.TopUp
+		*out = new(TopUpStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentObservation.
+func (in *DeploymentObservation) DeepCopy() *DeploymentObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentParameters) DeepCopyInto(out *DeploymentParameters) {
+	*out = *in
+	if in.SDL != nil {
+		in, out := &in.SDL, &out.SDL
+		*out = new(string)
+		**out = **in
+	}
+	if in.SDLRef != nil {
+		in, out := &in.SDLRef, &out.SDLRef
+		*out = new(SDLReference)
+		**out = **in
+	}
+	if in.SDLChecksum != nil {
+		in, out := &in.SDLChecksum, &out.SDLChecksum
+		*out = new(string)
+		**out = **in
+	}
+	if in.ManifestDelivery != nil {
+		in, out := &in.ManifestDelivery, &out.ManifestDelivery
+		*out = new(ManifestDelivery)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RequireApproval != nil {
+		in, out := &in.RequireApproval, &out.RequireApproval
+		*out = new(bool)
+		**out = **in
+	}
+	if in.BidReportSize != nil {
+		in, out := &in.BidReportSize, &out.BidReportSize
+		*out = new(int)
+		**out = **in
+	}
+	if in.MinBids != nil {
+		in, out := &in.MinBids, &out.MinBids
+		*out = new(int)
+		**out = **in
+	}
+	if in.BidCollectionWindow != nil {
+		in, out := &in.BidCollectionWindow, &out.BidCollectionWindow
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.BidSelection != nil {
+		in, out := &in.BidSelection, &out.BidSelection
+		*out = new(BidSelection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProviderFilter != nil {
+		in, out := &in.ProviderFilter, &out.ProviderFilter
+		*out = new(ProviderFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxPrice != nil {
+		in, out := &in.MaxPrice, &out.MaxPrice
+		*out = new(MaxPrice)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CloseIfUnleasedFor != nil {
+		in, out := &in.CloseIfUnleasedFor, &out.CloseIfUnleasedFor
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.CloseAt != nil {
+		in, out := &in.CloseAt, &out.CloseAt
+		*out = (*in).DeepCopy()
+	}
+	if in.RecreateOnFailure != nil {
+		in, out := &in.RecreateOnFailure, &out.RecreateOnFailure
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowClosingUnowned != nil {
+		in, out := &in.AllowClosingUnowned, &out.AllowClosingUnowned
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = new(Failover)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HealthChecks != nil {
+		in, out := &in.HealthChecks, &out.HealthChecks
+		*out = make([]HealthCheck, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ServiceDependencies != nil {
+		in, out := &in.ServiceDependencies, &out.ServiceDependencies
+		*out = make([]ServiceDependency, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LogForwarding != nil {
+		in, out := &in.LogForwarding, &out.LogForwarding
+		*out = new(LogForwarding)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SpendTolerancePercent != nil {
+		in, out := &in.SpendTolerancePercent, &out.SpendTolerancePercent
+		*out = new(int)
+		**out = **in
+	}
+	if in.Deposit != nil {
+		in, out := &in.Deposit, &out.Deposit
+		*out = new(Deposit)
+		**out = **in
+	}
+	if in.Depositor != nil {
+		in, out := &in.Depositor, &out.Depositor
+		*out = new(string)
+		**out = **in
+	}
+	if in.Owner != nil {
+		in, out := &in.Owner, &out.Owner
+		*out = new(string)
+		**out = **in
+	}
+	if in.AutoTopUp != nil {
+		in, out := &in.AutoTopUp, &out.AutoTopUp
+		*out = new(AutoTopUp)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MetadataPassthrough != nil {
+		in, out := &in.MetadataPassthrough, &out.MetadataPassthrough
+		*out = make([]MetadataPassthrough, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ColocateWith != nil {
+		in, out := &in.ColocateWith, &out.ColocateWith
+		*out = new(string)
+		**out = **in
+	}
+	if in.Transaction != nil {
+		in, out := &in.Transaction, &out.Transaction
+		*out = new(TransactionOverrides)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentParameters.
+func (in *DeploymentParameters) DeepCopy() *DeploymentParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentSpec) DeepCopyInto(out *DeploymentSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.NamespacedProviderConfigSpec.DeepCopyInto(&out.NamespacedProviderConfigSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentSpec.
+func (in *DeploymentSpec) DeepCopy() *DeploymentSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentStatus) DeepCopyInto(out *DeploymentStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentStatus.
+func (in *DeploymentStatus) DeepCopy() *DeploymentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Deposit) DeepCopyInto(out *Deposit) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Deposit.
+func (in *Deposit) DeepCopy() *Deposit {
+	if in == nil {
+		return nil
+	}
+	out := new(Deposit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Failover) DeepCopyInto(out *Failover) {
+	*out = *in
+	if in.UnreachableProbes != nil {
+		in, out := &in.UnreachableProbes, &out.UnreachableProbes
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Failover.
+func (in *Failover) DeepCopy() *Failover {
+	if in == nil {
+		return nil
+	}
+	out := new(Failover)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailoverRecord) DeepCopyInto(out *FailoverRecord) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailoverRecord.
+func (in *FailoverRecord) DeepCopy() *FailoverRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(FailoverRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FailoverRecord) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailoverRecordList) DeepCopyInto(out *FailoverRecordList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FailoverRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailoverRecordList.
+func (in *FailoverRecordList) DeepCopy() *FailoverRecordList {
+	if in == nil {
+		return nil
+	}
+	out := new(FailoverRecordList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FailoverRecordList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailoverRecordSpec) DeepCopyInto(out *FailoverRecordSpec) {
+	*out = *in
+	in.CapturedAt.DeepCopyInto(&out.CapturedAt)
+	if in.LeaseStatuses != nil {
+		in, out := &in.LeaseStatuses, &out.LeaseStatuses
+		*out = make([]LeaseStatusEvidence, len(*in))
+		copy(*out, *in)
+	}
+	if in.Leases != nil {
+		in, out := &in.Leases, &out.Leases
+		*out = make([]LeaseEvidence, len(*in))
+		copy(*out, *in)
+	}
+	if in.Bids != nil {
+		in, out := &in.Bids, &out.Bids
+		*out = make([]BidRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailoverRecordSpec.
+func (in *FailoverRecordSpec) DeepCopy() *FailoverRecordSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FailoverRecordSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FeeGrant) DeepCopyInto(out *FeeGrant) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeeGrant.
+func (in *FeeGrant) DeepCopy() *FeeGrant {
+	if in == nil {
+		return nil
+	}
+	out := new(FeeGrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FeeGrant) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FeeGrantList) DeepCopyInto(out *FeeGrantList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FeeGrant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeeGrantList.
+func (in *FeeGrantList) DeepCopy() *FeeGrantList {
+	if in == nil {
+		return nil
+	}
+	out := new(FeeGrantList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FeeGrantList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FeeGrantObservation) DeepCopyInto(out *FeeGrantObservation) {
+	*out = *in
+	if in.SpendLimit != nil {
+		in, out := &in.SpendLimit, &out.SpendLimit
+		*out = make([]Coin, len(*in))
+		copy(*out, *in)
+	}
+	if in.Expiration != nil {
+		in, out := &in.Expiration, &out.Expiration
+		*out = (*in).DeepCopy()
+	}
+	if in.AllowedMessages != nil {
+		in, out := &in.AllowedMessages, &out.AllowedMessages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.GrantedSpendLimit != nil {
+		in, out := &in.GrantedSpendLimit, &out.GrantedSpendLimit
+		*out = make([]Coin, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeeGrantObservation.
+func (in *FeeGrantObservation) DeepCopy() *FeeGrantObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(FeeGrantObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FeeGrantParameters) DeepCopyInto(out *FeeGrantParameters) {
+	*out = *in
+	if in.SpendLimit != nil {
+		in, out := &in.SpendLimit, &out.SpendLimit
+		*out = make([]Coin, len(*in))
+		copy(*out, *in)
+	}
+	if in.Expiration != nil {
+		in, out := &in.Expiration, &out.Expiration
+		*out = (*in).DeepCopy()
+	}
+	if in.AllowedMessages != nil {
+		in, out := &in.AllowedMessages, &out.AllowedMessages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeeGrantParameters.
+func (in *FeeGrantParameters) DeepCopy() *FeeGrantParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(FeeGrantParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FeeGrantSpec) DeepCopyInto(out *FeeGrantSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.NamespacedProviderConfigSpec.DeepCopyInto(&out.NamespacedProviderConfigSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeeGrantSpec.
+func (in *FeeGrantSpec) DeepCopy() *FeeGrantSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FeeGrantSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FeeGrantStatus) DeepCopyInto(out *FeeGrantStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeeGrantStatus.
+func (in *FeeGrantStatus) DeepCopy() *FeeGrantStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FeeGrantStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ForwardedPort) DeepCopyInto(out *ForwardedPort) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ForwardedPort.
+func (in *ForwardedPort) DeepCopy() *ForwardedPort {
+	if in == nil {
+		return nil
+	}
+	out := new(ForwardedPort)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthCheck) DeepCopyInto(out *HealthCheck) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCheck.
+func (in *HealthCheck) DeepCopy() *HealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(HealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Hostname) DeepCopyInto(out *Hostname) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Hostname.
+func (in *Hostname) DeepCopy() *Hostname {
+	if in == nil {
+		return nil
+	}
+	out := new(Hostname)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Hostname) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostnameList) DeepCopyInto(out *HostnameList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Hostname, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostnameList.
+func (in *HostnameList) DeepCopy() *HostnameList {
+	if in == nil {
+		return nil
+	}
+	out := new(HostnameList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HostnameList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostnameObservation) DeepCopyInto(out *HostnameObservation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostnameObservation.
+func (in *HostnameObservation) DeepCopy() *HostnameObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(HostnameObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostnameParameters) DeepCopyInto(out *HostnameParameters) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostnameParameters.
+func (in *HostnameParameters) DeepCopy() *HostnameParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(HostnameParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostnameSpec) DeepCopyInto(out *HostnameSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.NamespacedProviderConfigSpec.DeepCopyInto(&out.NamespacedProviderConfigSpec)
+	out.ForProvider = in.ForProvider
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostnameSpec.
+func (in *HostnameSpec) DeepCopy() *HostnameSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HostnameSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostnameStatus) DeepCopyInto(out *HostnameStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	out.AtProvider = in.AtProvider
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostnameStatus.
+func (in *HostnameStatus) DeepCopy() *HostnameStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HostnameStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPLease) DeepCopyInto(out *IPLease) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPLease.
+func (in *IPLease) DeepCopy() *IPLease {
+	if in == nil {
+		return nil
+	}
+	out := new(IPLease)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IPLease) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPLeaseList) DeepCopyInto(out *IPLeaseList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IPLease, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPLeaseList.
+func (in *IPLeaseList) DeepCopy() *IPLeaseList {
+	if in == nil {
+		return nil
+	}
+	out := new(IPLeaseList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IPLeaseList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPLeaseObservation) DeepCopyInto(out *IPLeaseObservation) {
+	*out = *in
+	if in.IPs != nil {
+		in, out := &in.IPs, &out.IPs
+		*out = make([]LeasedIP, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPLeaseObservation.
+func (in *IPLeaseObservation) DeepCopy() *IPLeaseObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(IPLeaseObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPLeaseParameters) DeepCopyInto(out *IPLeaseParameters) {
+	*out = *in
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPLeaseParameters.
+func (in *IPLeaseParameters) DeepCopy() *IPLeaseParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(IPLeaseParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPLeaseSpec) DeepCopyInto(out *IPLeaseSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.NamespacedProviderConfigSpec.DeepCopyInto(&out.NamespacedProviderConfigSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPLeaseSpec.
+func (in *IPLeaseSpec) DeepCopy() *IPLeaseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IPLeaseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPLeaseStatus) DeepCopyInto(out *IPLeaseStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPLeaseStatus.
+func (in *IPLeaseStatus) DeepCopy() *IPLeaseStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IPLeaseStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LeaseEvidence) DeepCopyInto(out *LeaseEvidence) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LeaseEvidence.
+func (in *LeaseEvidence) DeepCopy() *LeaseEvidence {
+	if in == nil {
+		return nil
+	}
+	out := new(LeaseEvidence)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LeaseStatusEvidence) DeepCopyInto(out *LeaseStatusEvidence) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LeaseStatusEvidence.
+func (in *LeaseStatusEvidence) DeepCopy() *LeaseStatusEvidence {
+	if in == nil {
+		return nil
+	}
+	out := new(LeaseStatusEvidence)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LeasedIP) DeepCopyInto(out *LeasedIP) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LeasedIP.
+func (in *LeasedIP) DeepCopy() *LeasedIP {
+	if in == nil {
+		return nil
+	}
+	out := new(LeasedIP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogForwarding) DeepCopyInto(out *LogForwarding) {
+	*out = *in
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tail != nil {
+		in, out := &in.Tail, &out.Tail
+		*out = new(int)
+		**out = **in
+	}
+	if in.Sink != nil {
+		in, out := &in.Sink, &out.Sink
+		*out = new(LogSink)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogForwarding.
+func (in *LogForwarding) DeepCopy() *LogForwarding {
+	if in == nil {
+		return nil
+	}
+	out := new(LogForwarding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogSink) DeepCopyInto(out *LogSink) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogSink.
+func (in *LogSink) DeepCopy() *LogSink {
+	if in == nil {
+		return nil
+	}
+	out := new(LogSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Manifest) DeepCopyInto(out *Manifest) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Manifest.
+func (in *Manifest) DeepCopy() *Manifest {
+	if in == nil {
+		return nil
+	}
+	out := new(Manifest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Manifest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManifestDelivery) DeepCopyInto(out *ManifestDelivery) {
+	*out = *in
+	if in.MaxRetries != nil {
+		in, out := &in.MaxRetries, &out.MaxRetries
+		*out = new(int)
+		**out = **in
+	}
+	if in.Backoff != nil {
+		in, out := &in.Backoff, &out.Backoff
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManifestDelivery.
+func (in *ManifestDelivery) DeepCopy() *ManifestDelivery {
+	if in == nil {
+		return nil
+	}
+	out := new(ManifestDelivery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManifestDeliveryStatus) DeepCopyInto(out *ManifestDeliveryStatus) {
+	*out = *in
+	if in.LastAttemptTime != nil {
+		in, out := &in.LastAttemptTime, &out.LastAttemptTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManifestDeliveryStatus.
+func (in *ManifestDeliveryStatus) DeepCopy() *ManifestDeliveryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ManifestDeliveryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManifestList) DeepCopyInto(out *ManifestList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Manifest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManifestList.
+func (in *ManifestList) DeepCopy() *ManifestList {
+	if in == nil {
+		return nil
+	}
+	out := new(ManifestList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ManifestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManifestObservation) DeepCopyInto(out *ManifestObservation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManifestObservation.
+func (in *ManifestObservation) DeepCopy() *ManifestObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(ManifestObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManifestParameters) DeepCopyInto(out *ManifestParameters) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManifestParameters.
+func (in *ManifestParameters) DeepCopy() *ManifestParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(ManifestParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManifestSpec) DeepCopyInto(out *ManifestSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.NamespacedProviderConfigSpec.DeepCopyInto(&out.NamespacedProviderConfigSpec)
+	out.ForProvider = in.ForProvider
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManifestSpec.
+func (in *ManifestSpec) DeepCopy() *ManifestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ManifestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManifestStatus) DeepCopyInto(out *ManifestStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	out.AtProvider = in.AtProvider
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManifestStatus.
+func (in *ManifestStatus) DeepCopy() *ManifestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ManifestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaxPrice) DeepCopyInto(out *MaxPrice) {
+	*out = *in
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaxPrice.
+func (in *MaxPrice) DeepCopy() *MaxPrice {
+	if in == nil {
+		return nil
+	}
+	out := new(MaxPrice)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetadataPassthrough) DeepCopyInto(out *MetadataPassthrough) {
+	*out = *in
+	if in.Label != nil {
+		in, out := &in.Label, &out.Label
+		*out = new(string)
+		**out = **in
+	}
+	if in.Annotation != nil {
+		in, out := &in.Annotation, &out.Annotation
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetadataPassthrough.
+func (in *MetadataPassthrough) DeepCopy() *MetadataPassthrough {
+	if in == nil {
+		return nil
+	}
+	out := new(MetadataPassthrough)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacedProviderConfigSpec) DeepCopyInto(out *NamespacedProviderConfigSpec) {
+	*out = *in
+	if in.NamespacedProviderConfigReference != nil {
+		in, out := &in.NamespacedProviderConfigReference, &out.NamespacedProviderConfigReference
+		*out = new(commonv1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedProviderConfigSpec.
+func (in *NamespacedProviderConfigSpec) DeepCopy() *NamespacedProviderConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacedProviderConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromotionStatus) DeepCopyInto(out *PromotionStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromotionStatus.
+func (in *PromotionStatus) DeepCopy() *PromotionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PromotionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Provider) DeepCopyInto(out *Provider) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Provider.
+func (in *Provider) DeepCopy() *Provider {
 	if in == nil {
 		return nil
 	}
-	out := new(Deployment)
+	out := new(Provider)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Deployment) DeepCopyObject() runtime.Object {
+func (in *Provider) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -52,31 +1944,73 @@ func (in *Deployment) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DeploymentList) DeepCopyInto(out *DeploymentList) {
+func (in *ProviderFilter) DeepCopyInto(out *ProviderFilter) {
+	*out = *in
+	if in.Attributes != nil {
+		in, out := &in.Attributes, &out.Attributes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Regions != nil {
+		in, out := &in.Regions, &out.Regions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SignedBy != nil {
+		in, out := &in.SignedBy, &out.SignedBy
+		*out = new(SignedBy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Allow != nil {
+		in, out := &in.Allow, &out.Allow
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Deny != nil {
+		in, out := &in.Deny, &out.Deny
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderFilter.
+func (in *ProviderFilter) DeepCopy() *ProviderFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderList) DeepCopyInto(out *ProviderList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Deployment, len(*in))
+		*out = make([]Provider, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentList.
-func (in *DeploymentList) DeepCopy() *DeploymentList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderList.
+func (in *ProviderList) DeepCopy() *ProviderList {
 	if in == nil {
 		return nil
 	}
-	out := new(DeploymentList)
+	out := new(ProviderList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DeploymentList) DeepCopyObject() runtime.Object {
+func (in *ProviderList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -84,65 +2018,276 @@ func (in *DeploymentList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DeploymentObservation) DeepCopyInto(out *DeploymentObservation) {
+func (in *ProviderObservation) DeepCopyInto(out *ProviderObservation) {
 	*out = *in
+	if in.Attributes != nil {
+		in, out := &in.Attributes, &out.Attributes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Auditors != nil {
+		in, out := &in.Auditors, &out.Auditors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentObservation.
-func (in *DeploymentObservation) DeepCopy() *DeploymentObservation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderObservation.
+func (in *ProviderObservation) DeepCopy() *ProviderObservation {
 	if in == nil {
 		return nil
 	}
-	out := new(DeploymentObservation)
+	out := new(ProviderObservation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DeploymentParameters) DeepCopyInto(out *DeploymentParameters) {
+func (in *ProviderParameters) DeepCopyInto(out *ProviderParameters) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentParameters.
-func (in *DeploymentParameters) DeepCopy() *DeploymentParameters {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderParameters.
+func (in *ProviderParameters) DeepCopy() *ProviderParameters {
 	if in == nil {
 		return nil
 	}
-	out := new(DeploymentParameters)
+	out := new(ProviderParameters)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DeploymentSpec) DeepCopyInto(out *DeploymentSpec) {
+func (in *ProviderSnapshot) DeepCopyInto(out *ProviderSnapshot) {
+	*out = *in
+	if in.Attributes != nil {
+		in, out := &in.Attributes, &out.Attributes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderSnapshot.
+func (in *ProviderSnapshot) DeepCopy() *ProviderSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderSpec) DeepCopyInto(out *ProviderSpec) {
 	*out = *in
 	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.NamespacedProviderConfigSpec.DeepCopyInto(&out.NamespacedProviderConfigSpec)
 	out.ForProvider = in.ForProvider
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentSpec.
-func (in *DeploymentSpec) DeepCopy() *DeploymentSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderSpec.
+func (in *ProviderSpec) DeepCopy() *ProviderSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DeploymentSpec)
+	out := new(ProviderSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DeploymentStatus) DeepCopyInto(out *DeploymentStatus) {
+func (in *ProviderStatus) DeepCopyInto(out *ProviderStatus) {
 	*out = *in
 	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
-	out.AtProvider = in.AtProvider
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentStatus.
-func (in *DeploymentStatus) DeepCopy() *DeploymentStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderStatus.
+func (in *ProviderStatus) DeepCopy() *ProviderStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(DeploymentStatus)
+	out := new(ProviderStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecreationStatus) DeepCopyInto(out *RecreationStatus) {
+	*out = *in
+	in.LastRecreateTime.DeepCopyInto(&out.LastRecreateTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecreationStatus.
+func (in *RecreationStatus) DeepCopy() *RecreationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RecreationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDLReference) DeepCopyInto(out *SDLReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDLReference.
+func (in *SDLReference) DeepCopy() *SDLReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SDLReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceDependency) DeepCopyInto(out *ServiceDependency) {
+	*out = *in
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceDependency.
+func (in *ServiceDependency) DeepCopy() *ServiceDependency {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceDependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceHealth) DeepCopyInto(out *ServiceHealth) {
+	*out = *in
+	in.LastProbeTime.DeepCopyInto(&out.LastProbeTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceHealth.
+func (in *ServiceHealth) DeepCopy() *ServiceHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceStatus) DeepCopyInto(out *ServiceStatus) {
+	*out = *in
+	if in.URIs != nil {
+		in, out := &in.URIs, &out.URIs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceStatus.
+func (in *ServiceStatus) DeepCopy() *ServiceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SignedBy) DeepCopyInto(out *SignedBy) {
+	*out = *in
+	if in.AnyOf != nil {
+		in, out := &in.AnyOf, &out.AnyOf
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllOf != nil {
+		in, out := &in.AllOf, &out.AllOf
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SignedBy.
+func (in *SignedBy) DeepCopy() *SignedBy {
+	if in == nil {
+		return nil
+	}
+	out := new(SignedBy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpendStatus) DeepCopyInto(out *SpendStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpendStatus.
+func (in *SpendStatus) DeepCopy() *SpendStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SpendStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopUpStatus) DeepCopyInto(out *TopUpStatus) {
+	*out = *in
+	if in.LastTopUpTime != nil {
+		in, out := &in.LastTopUpTime, &out.LastTopUpTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopUpStatus.
+func (in *TopUpStatus) DeepCopy() *TopUpStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TopUpStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransactionOverrides) DeepCopyInto(out *TransactionOverrides) {
+	*out = *in
+	if in.Memo != nil {
+		in, out := &in.Memo, &out.Memo
+		*out = new(string)
+		**out = **in
+	}
+	if in.GasAdjustment != nil {
+		in, out := &in.GasAdjustment, &out.GasAdjustment
+		*out = new(string)
+		**out = **in
+	}
+	if in.FeeCap != nil {
+		in, out := &in.FeeCap, &out.FeeCap
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransactionOverrides.
+func (in *TransactionOverrides) DeepCopy() *TransactionOverrides {
+	if in == nil {
+		return nil
+	}
+	out := new(TransactionOverrides)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -0,0 +1,187 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+)
+
+const errNotHub = "hub is not a v1alpha1 Deployment"
+
+// AnnotationKeyDeprecatedDeployment holds the deprecated deployment field of
+// a v1alpha1 Deployment, which v1beta1 has no field for, so that it survives
+// a round trip through v1beta1. It is only set on v1beta1 Deployments.
+const AnnotationKeyDeprecatedDeployment = "akash.web7.md/deprecated-deployment"
+
+// ConvertTo converts the Deployment to the v1alpha1 Deployment hub.
+func (d *Deployment) ConvertTo(hub conversion.Hub) error {
+	dst, ok := hub.(*v1alpha1.Deployment)
+	if !ok {
+		return errors.New(errNotHub)
+	}
+	dst.ObjectMeta = d.ObjectMeta
+	deprecated, hasDeprecated := d.GetAnnotations()[AnnotationKeyDeprecatedDeployment]
+	if hasDeprecated {
+		dst.SetAnnotations(withoutAnnotation(d.GetAnnotations(), AnnotationKeyDeprecatedDeployment))
+	}
+	dst.Spec.ResourceSpec = d.Spec.ResourceSpec
+	dst.Spec.NamespacedProviderConfigSpec = d.Spec.NamespacedProviderConfigSpec
+	dst.Status.ResourceStatus = d.Status.ResourceStatus
+	dst.Status.AtProvider = d.Status.AtProvider
+
+	in := d.Spec.ForProvider
+	out := v1alpha1.DeploymentParameters{
+		ManifestDelivery:      in.ManifestDelivery,
+		CloseIfUnleasedFor:    in.CloseIfUnleasedFor,
+		TTL:                   in.TTL,
+		CloseAt:               in.CloseAt,
+		RecreateOnFailure:     in.RecreateOnFailure,
+		AllowClosingUnowned:   in.AllowClosingUnowned,
+		Failover:              in.Failover,
+		HealthChecks:          in.HealthChecks,
+		ServiceDependencies:   in.ServiceDependencies,
+		LogForwarding:         in.LogForwarding,
+		SpendTolerancePercent: in.SpendTolerancePercent,
+		Owner:                 in.Owner,
+		MetadataPassthrough:   in.MetadataPassthrough,
+		ColocateWith:          in.ColocateWith,
+		Transaction:           in.Transaction,
+	}
+	if s := in.SDL; s != nil {
+		out.SDL, out.SDLRef, out.SDLChecksum = s.Inline, s.Ref, s.Checksum
+	}
+	if hasDeprecated {
+		// The inline SDL is the deprecated field unless it was changed.
+		out.Deployment = deprecated
+		if out.SDL != nil && *out.SDL == deprecated {
+			out.SDL = nil
+		}
+	}
+	if dep := in.Deposit; dep != nil {
+		if dep.Amount != "" || dep.Denom != "" {
+			out.Deposit = &v1alpha1.Deposit{Amount: dep.Amount, Denom: dep.Denom}
+		}
+		out.Depositor, out.AutoTopUp = dep.Depositor, dep.AutoTopUp
+	}
+	if b := in.BidPolicy; b != nil {
+		out.MinBids = b.MinBids
+		out.BidCollectionWindow = b.CollectionWindow
+		out.BidSelection = b.Selection
+		out.ProviderFilter = b.ProviderFilter
+		out.MaxPrice = b.MaxPrice
+		out.RequireApproval = b.RequireApproval
+		out.BidReportSize = b.ReportSize
+	}
+	dst.Spec.ForProvider = out
+	return nil
+}
+
+// ConvertFrom converts the v1alpha1 Deployment hub to the Deployment. The
+// deprecated deployment field of v1alpha1 becomes the inline SDL unless an
+// SDL is set, which takes precedence over it. It is kept in an annotation
+// either way, from which ConvertTo restores it.
+func (d *Deployment) ConvertFrom(hub conversion.Hub) error {
+	src, ok := hub.(*v1alpha1.Deployment)
+	if !ok {
+		return errors.New(errNotHub)
+	}
+	d.ObjectMeta = src.ObjectMeta
+	d.Spec.ResourceSpec = src.Spec.ResourceSpec
+	d.Spec.NamespacedProviderConfigSpec = src.Spec.NamespacedProviderConfigSpec
+	d.Status.ResourceStatus = src.Status.ResourceStatus
+	d.Status.AtProvider = src.Status.AtProvider
+
+	in := src.Spec.ForProvider
+	out := DeploymentParameters{
+		ManifestDelivery:      in.ManifestDelivery,
+		CloseIfUnleasedFor:    in.CloseIfUnleasedFor,
+		TTL:                   in.TTL,
+		CloseAt:               in.CloseAt,
+		RecreateOnFailure:     in.RecreateOnFailure,
+		AllowClosingUnowned:   in.AllowClosingUnowned,
+		Failover:              in.Failover,
+		HealthChecks:          in.HealthChecks,
+		ServiceDependencies:   in.ServiceDependencies,
+		LogForwarding:         in.LogForwarding,
+		SpendTolerancePercent: in.SpendTolerancePercent,
+		Owner:                 in.Owner,
+		MetadataPassthrough:   in.MetadataPassthrough,
+		ColocateWith:          in.ColocateWith,
+		Transaction:           in.Transaction,
+	}
+
+	inline := in.SDL
+	if in.Deployment != "" {
+		if inline == nil {
+			inline = &in.Deployment
+		}
+		d.SetAnnotations(withAnnotation(src.GetAnnotations(), AnnotationKeyDeprecatedDeployment, in.Deployment))
+	}
+	if inline != nil || in.SDLRef != nil || in.SDLChecksum != nil {
+		out.SDL = &SDL{Inline: inline, Ref: in.SDLRef, Checksum: in.SDLChecksum}
+	}
+
+	if in.Deposit != nil || in.Depositor != nil || in.AutoTopUp != nil {
+		out.Deposit = &Deposit{Depositor: in.Depositor, AutoTopUp: in.AutoTopUp}
+		if in.Deposit != nil {
+			out.Deposit.Amount, out.Deposit.Denom = in.Deposit.Amount, in.Deposit.Denom
+		}
+	}
+
+	b := BidPolicy{
+		MinBids:          in.MinBids,
+		CollectionWindow: in.BidCollectionWindow,
+		Selection:        in.BidSelection,
+		ProviderFilter:   in.ProviderFilter,
+		MaxPrice:         in.MaxPrice,
+		RequireApproval:  in.RequireApproval,
+		ReportSize:       in.BidReportSize,
+	}
+	if b != (BidPolicy{}) {
+		out.BidPolicy = &b
+	}
+	d.Spec.ForProvider = out
+	return nil
+}
+
+// withAnnotation returns a copy of annotations with key set to value.
+func withAnnotation(annotations map[string]string, key, value string) map[string]string {
+	out := make(map[string]string, len(annotations)+1)
+	for k, v := range annotations {
+		out[k] = v
+	}
+	out[key] = value
+	return out
+}
+
+// withoutAnnotation returns a copy of annotations without key, or nil when no
+// other annotation is left.
+func withoutAnnotation(annotations map[string]string, key string) map[string]string {
+	out := make(map[string]string, len(annotations))
+	for k, v := range annotations {
+		if k != key {
+			out[k] = v
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+)
+
+func TestConvert(t *testing.T) {
+	sdl := "version: \"2.0\""
+	checksum := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	depositor := "akash1depositor"
+	minBids := 3
+	reportSize := 5
+	approval := true
+	window := &metav1.Duration{Duration: time.Minute}
+	meta := metav1.ObjectMeta{Name: "web", Annotations: map[string]string{"crossplane.io/external-name": "42"}}
+	resourceSpec := xpv1.ResourceSpec{ProviderConfigReference: &xpv1.Reference{Name: "default"}}
+	namespaced := v1alpha1.NamespacedProviderConfigSpec{NamespacedProviderConfigReference: &xpv1.Reference{Name: "team"}}
+	status := v1alpha1.DeploymentStatus{AtProvider: v1alpha1.DeploymentObservation{Dseq: "42"}}
+
+	cases := map[string]struct {
+		hub  v1alpha1.DeploymentParameters
+		want DeploymentParameters
+	}{
+		"Empty": {},
+		"Grouped": {
+			hub: v1alpha1.DeploymentParameters{
+				SDL:                 &sdl,
+				SDLRef:              &v1alpha1.SDLReference{Kind: v1alpha1.SDLSourceConfigMap, Name: "web", Namespace: "default", Key: "deploy.yaml"},
+				SDLChecksum:         &checksum,
+				Deposit:             &v1alpha1.Deposit{Amount: "100", Denom: "uakt"},
+				Depositor:           &depositor,
+				AutoTopUp:           &v1alpha1.AutoTopUp{Threshold: "10", Amount: "100"},
+				MinBids:             &minBids,
+				BidCollectionWindow: window,
+				BidSelection:        &v1alpha1.BidSelection{Strategy: v1alpha1.BidSelectionRandom},
+				ProviderFilter:      &v1alpha1.ProviderFilter{Regions: []string{"us-west"}},
+				MaxPrice:            &v1alpha1.MaxPrice{Amount: "1000", Denom: "uakt"},
+				RequireApproval:     &approval,
+				BidReportSize:       &reportSize,
+				ColocateWith:        &depositor,
+			},
+			want: DeploymentParameters{
+				SDL: &SDL{
+					Inline:   &sdl,
+					Ref:      &v1alpha1.SDLReference{Kind: v1alpha1.SDLSourceConfigMap, Name: "web", Namespace: "default", Key: "deploy.yaml"},
+					Checksum: &checksum,
+				},
+				Deposit: &Deposit{Amount: "100", Denom: "uakt", Depositor: &depositor, AutoTopUp: &v1alpha1.AutoTopUp{Threshold: "10", Amount: "100"}},
+				BidPolicy: &BidPolicy{
+					MinBids:          &minBids,
+					CollectionWindow: window,
+					Selection:        &v1alpha1.BidSelection{Strategy: v1alpha1.BidSelectionRandom},
+					ProviderFilter:   &v1alpha1.ProviderFilter{Regions: []string{"us-west"}},
+					MaxPrice:         &v1alpha1.MaxPrice{Amount: "1000", Denom: "uakt"},
+					RequireApproval:  &approval,
+					ReportSize:       &reportSize,
+				},
+				ColocateWith: &depositor,
+			},
+		},
+		"DepositorOnly": {
+			hub:  v1alpha1.DeploymentParameters{Depositor: &depositor},
+			want: DeploymentParameters{Deposit: &Deposit{Depositor: &depositor}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			hub := &v1alpha1.Deployment{ObjectMeta: meta, Spec: v1alpha1.DeploymentSpec{ResourceSpec: resourceSpec, NamespacedProviderConfigSpec: namespaced, ForProvider: tc.hub}, Status: status}
+
+			got := &Deployment{}
+			if err := got.ConvertFrom(hub); err != nil {
+				t.Fatalf("ConvertFrom(...): %v", err)
+			}
+			want := &Deployment{ObjectMeta: meta, Spec: DeploymentSpec{ResourceSpec: resourceSpec, NamespacedProviderConfigSpec: namespaced, ForProvider: tc.want}, Status: DeploymentStatus{AtProvider: status.AtProvider}}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("ConvertFrom(...): -want, +got:\n%s", diff)
+			}
+
+			back := &v1alpha1.Deployment{}
+			if err := got.ConvertTo(back); err != nil {
+				t.Fatalf("ConvertTo(...): %v", err)
+			}
+			if diff := cmp.Diff(hub, back); diff != "" {
+				t.Errorf("ConvertTo(ConvertFrom(...)): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConvertDeprecatedDeployment(t *testing.T) {
+	sdl := "version: \"2.0\""
+	other := "version: \"2.1\""
+
+	cases := map[string]struct {
+		reason      string
+		hub         v1alpha1.DeploymentParameters
+		annotations map[string]string
+		want        DeploymentParameters
+	}{
+		"DeploymentOnly": {
+			reason: "The deprecated deployment field becomes the inline SDL.",
+			hub:    v1alpha1.DeploymentParameters{Deployment: sdl},
+			want:   DeploymentParameters{SDL: &SDL{Inline: &sdl}},
+		},
+		"SDLTakesPrecedence": {
+			reason: "The SDL takes precedence over the deprecated deployment field, which is still kept.",
+			hub:    v1alpha1.DeploymentParameters{Deployment: sdl, SDL: &other},
+			want:   DeploymentParameters{SDL: &SDL{Inline: &other}},
+		},
+		"OtherAnnotations": {
+			reason:      "Other annotations are kept.",
+			hub:         v1alpha1.DeploymentParameters{Deployment: sdl},
+			annotations: map[string]string{"team": "web"},
+			want:        DeploymentParameters{SDL: &SDL{Inline: &sdl}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			hub := &v1alpha1.Deployment{Spec: v1alpha1.DeploymentSpec{ForProvider: tc.hub}}
+			hub.SetAnnotations(tc.annotations)
+
+			got := &Deployment{}
+			if err := got.ConvertFrom(hub); err != nil {
+				t.Fatalf("ConvertFrom(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got.Spec.ForProvider); diff != "" {
+				t.Errorf("\n%s\nConvertFrom(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if a := got.GetAnnotations()[AnnotationKeyDeprecatedDeployment]; a != sdl {
+				t.Errorf("\n%s\nConvertFrom(...): want the deprecated deployment field annotated, got %q", tc.reason, a)
+			}
+			if _, ok := hub.GetAnnotations()[AnnotationKeyDeprecatedDeployment]; ok {
+				t.Errorf("\n%s\nConvertFrom(...): the annotations of the hub were modified", tc.reason)
+			}
+
+			back := &v1alpha1.Deployment{}
+			if err := got.ConvertTo(back); err != nil {
+				t.Fatalf("ConvertTo(...): %v", err)
+			}
+			if diff := cmp.Diff(hub, back); diff != "" {
+				t.Errorf("\n%s\nConvertTo(ConvertFrom(...)): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConvertToChangedSDL(t *testing.T) {
+	sdl := "version: \"2.0\""
+	changed := "version: \"2.1\""
+
+	d := &Deployment{Spec: DeploymentSpec{ForProvider: DeploymentParameters{SDL: &SDL{Inline: &changed}}}}
+	d.SetAnnotations(map[string]string{AnnotationKeyDeprecatedDeployment: sdl})
+
+	got := &v1alpha1.Deployment{}
+	if err := d.ConvertTo(got); err != nil {
+		t.Fatalf("ConvertTo(...): %v", err)
+	}
+	want := v1alpha1.DeploymentParameters{Deployment: sdl, SDL: &changed}
+	if diff := cmp.Diff(want, got.Spec.ForProvider); diff != "" {
+		t.Errorf("ConvertTo(...): an inline SDL changed in v1beta1 must take precedence over the deprecated field: -want, +got:\n%s", diff)
+	}
+	if got.GetAnnotations() != nil {
+		t.Errorf("ConvertTo(...): want no annotations, got %v", got.GetAnnotations())
+	}
+}
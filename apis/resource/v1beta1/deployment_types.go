@@ -0,0 +1,260 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+)
+
+// DeploymentParameters are the configurable fields of a Deployment. The SDL,
+// the deposit and the bid policy are grouped, the other fields are those of
+// v1alpha1.
+type DeploymentParameters struct {
+	// SDL of the deployment. Deployments that are only observed need none.
+	// +optional
+	SDL *SDL `json:"sdl,omitempty"`
+
+	// Deposit funding the escrow of the deployment.
+	// +optional
+	Deposit *Deposit `json:"deposit,omitempty"`
+
+	// BidPolicy controls which bid is leased and when.
+	// +optional
+	BidPolicy *BidPolicy `json:"bidPolicy,omitempty"`
+
+	// ManifestDelivery tunes how the manifest is submitted to the leasing
+	// provider before the controller gives up on it.
+	// +optional
+	ManifestDelivery *v1alpha1.ManifestDelivery `json:"manifestDelivery,omitempty"`
+
+	// CloseIfUnleasedFor closes the deployment, refunding its escrow, once it
+	// has had no active lease for this long. Unset disables it.
+	// +optional
+	CloseIfUnleasedFor *metav1.Duration `json:"closeIfUnleasedFor,omitempty"`
+
+	// TTL closes the deployment, refunding its escrow, once it was created
+	// on chain this long ago. Unset disables it.
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+
+	// CloseAt closes the deployment, refunding its escrow, at this time.
+	// +optional
+	CloseAt *metav1.Time `json:"closeAt,omitempty"`
+
+	// RecreateOnFailure closes the deployment and creates it again, under a
+	// new dseq, once its lease or the deployment itself was closed on chain
+	// other than by this controller.
+	// +optional
+	RecreateOnFailure *bool `json:"recreateOnFailure,omitempty"`
+
+	// AllowClosingUnowned allows closing a deployment imported with an
+	// external name of the form owner/dseq whose owner is not the account of
+	// the ProviderConfig.
+	// +optional
+	AllowClosingUnowned *bool `json:"allowClosingUnowned,omitempty"`
+
+	// Failover moves the deployment to another provider once the gateway of
+	// its provider was unreachable for a number of consecutive probes.
+	// +optional
+	Failover *v1alpha1.Failover `json:"failover,omitempty"`
+
+	// HealthChecks are probes of the published endpoints of SDL services.
+	// +optional
+	HealthChecks []v1alpha1.HealthCheck `json:"healthChecks,omitempty"`
+
+	// ServiceDependencies declare which SDL services depend on others.
+	// +optional
+	ServiceDependencies []v1alpha1.ServiceDependency `json:"serviceDependencies,omitempty"`
+
+	// LogForwarding forwards the logs of the SDL services into the logging
+	// pipeline of the cluster.
+	// +optional
+	LogForwarding *v1alpha1.LogForwarding `json:"logForwarding,omitempty"`
+
+	// SpendTolerancePercent is how much faster than the prices of its leases
+	// the escrow of the deployment may drain before a spend anomaly is
+	// reported.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=20
+	SpendTolerancePercent *int `json:"spendTolerancePercent,omitempty"`
+
+	// Owner is the address of the account owning the deployment. It is
+	// late-initialized from the chain and informational only.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^akash1[02-9ac-hj-np-z]+$`
+	Owner *string `json:"owner,omitempty"`
+
+	// MetadataPassthrough propagates labels and annotations of the Deployment
+	// into the environment of every SDL service.
+	// +optional
+	MetadataPassthrough []v1alpha1.MetadataPassthrough `json:"metadataPassthrough,omitempty"`
+
+	// ColocateWith is the name of another Deployment whose provider is
+	// preferred when selecting a bid.
+	// +optional
+	ColocateWith *string `json:"colocateWith,omitempty"`
+
+	// Transaction overrides the memo and fees of the transactions made for
+	// the deployment.
+	// +optional
+	Transaction *v1alpha1.TransactionOverrides `json:"transaction,omitempty"`
+}
+
+// An SDL is the source of the SDL of a deployment, either inline or in a
+// ConfigMap or Secret.
+type SDL struct {
+	// Inline SDL of the deployment.
+	// +optional
+	Inline *string `json:"inline,omitempty"`
+
+	// Ref references a ConfigMap or Secret key holding the SDL. It takes
+	// precedence over Inline.
+	// +optional
+	Ref *v1alpha1.SDLReference `json:"ref,omitempty"`
+
+	// Checksum is the lowercase hex encoded SHA-256 digest the SDL must
+	// match before it is deployed.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[a-f0-9]{64}$`
+	Checksum *string `json:"checksum,omitempty"`
+}
+
+// A Deposit funds the escrow of a deployment.
+type Deposit struct {
+	// Amount of Denom deposited when the deployment is created. It defaults
+	// to 5000000, like the Akash CLI.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+$`
+	Amount string `json:"amount,omitempty"`
+
+	// Denom of the deposit: uakt, or the IBC denomination of USDC. It
+	// defaults to uakt.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(uakt|ibc/[0-9A-F]{64})$`
+	Denom string `json:"denom,omitempty"`
+
+	// Depositor is the address of the account the deposit is taken from,
+	// which must have authorized the owner to spend it. The deposit is taken
+	// from the owner when unset.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^akash1[02-9ac-hj-np-z]+$`
+	Depositor *string `json:"depositor,omitempty"`
+
+	// AutoTopUp deposits funds into the escrow of the deployment before it
+	// runs out.
+	// +optional
+	AutoTopUp *v1alpha1.AutoTopUp `json:"autoTopUp,omitempty"`
+}
+
+// A BidPolicy controls which bid of a deployment is leased and when.
+type BidPolicy struct {
+	// MinBids is the number of bids collected before one is selected.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	MinBids *int `json:"minBids,omitempty"`
+
+	// CollectionWindow bounds how long bids are collected while fewer than
+	// MinBids arrived.
+	// +optional
+	// +kubebuilder:default="1m"
+	CollectionWindow *metav1.Duration `json:"collectionWindow,omitempty"`
+
+	// Selection controls which of the collected bids is leased. The lowest
+	// priced bid is leased by default.
+	// +optional
+	Selection *v1alpha1.BidSelection `json:"selection,omitempty"`
+
+	// ProviderFilter restricts the providers whose bids may be leased.
+	// +optional
+	ProviderFilter *v1alpha1.ProviderFilter `json:"providerFilter,omitempty"`
+
+	// MaxPrice is the highest price per block a bid may ask to be leased.
+	// +optional
+	MaxPrice *v1alpha1.MaxPrice `json:"maxPrice,omitempty"`
+
+	// RequireApproval holds lease creation until a human approves one of the
+	// received bids with the akash.web7.md/approved-bid annotation.
+	// +optional
+	RequireApproval *bool `json:"requireApproval,omitempty"`
+
+	// ReportSize is the number of cheapest bids compared in the bid report
+	// published while waiting for approval.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=5
+	ReportSize *int `json:"reportSize,omitempty"`
+}
+
+// A DeploymentSpec defines the desired state of a Deployment.
+type DeploymentSpec struct {
+	xpv1.ResourceSpec                     `json:",inline"`
+	v1alpha1.NamespacedProviderConfigSpec `json:",inline"`
+	ForProvider                           DeploymentParameters `json:"forProvider"`
+}
+
+// A DeploymentStatus represents the observed state of a Deployment.
+type DeploymentStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          v1alpha1.DeploymentObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Deployment is a deployment on the Akash network.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="EXTERNAL-NAME",type="string",JSONPath=".metadata.annotations.crossplane\\.io/external-name"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,akash}
+type Deployment struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DeploymentSpec   `json:"spec"`
+	Status DeploymentStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DeploymentList contains a list of Deployment
+type DeploymentList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Deployment `json:"items"`
+}
+
+// Deployment type metadata.
+var (
+	DeploymentKind             = reflect.TypeOf(Deployment{}).Name()
+	DeploymentGroupKind        = schema.GroupKind{Group: Group, Kind: DeploymentKind}.String()
+	DeploymentKindAPIVersion   = DeploymentKind + "." + SchemeGroupVersion.String()
+	DeploymentGroupVersionKind = SchemeGroupVersion.WithKind(DeploymentKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&Deployment{}, &DeploymentList{})
+}
@@ -0,0 +1,336 @@
+//go:build !ignore_autogenerated
+
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BidPolicy) DeepCopyInto(out *BidPolicy) {
+	*out = *in
+	if in.MinBids != nil {
+		in, out := &in.MinBids, &out.MinBids
+		*out = new(int)
+		**out = **in
+	}
+	if in.CollectionWindow != nil {
+		in, out := &in.CollectionWindow, &out.CollectionWindow
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Selection != nil {
+		in, out := &in.Selection, &out.Selection
+		*out = new(v1alpha1.BidSelection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProviderFilter != nil {
+		in, out := &in.ProviderFilter, &out.ProviderFilter
+		*out = new(v1alpha1.ProviderFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxPrice != nil {
+		in, out := &in.MaxPrice, &out.MaxPrice
+		*out = new(v1alpha1.MaxPrice)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RequireApproval != nil {
+		in, out := &in.RequireApproval, &out.RequireApproval
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ReportSize != nil {
+		in, out := &in.ReportSize, &out.ReportSize
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BidPolicy.
+func (in *BidPolicy) DeepCopy() *BidPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(BidPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Deployment) DeepCopyInto(out *Deployment) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Deployment.
+func (in *Deployment) DeepCopy() *Deployment {
+	if in == nil {
+		return nil
+	}
+	out := new(Deployment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Deployment) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentList) DeepCopyInto(out *DeploymentList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Deployment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentList.
+func (in *DeploymentList) DeepCopy() *DeploymentList {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeploymentList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentParameters) DeepCopyInto(out *DeploymentParameters) {
+	*out = *in
+	if in.SDL != nil {
+		in, out := &in.SDL, &out.SDL
+		*out = new(SDL)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Deposit != nil {
+		in, out := &in.Deposit, &out.Deposit
+		*out = new(Deposit)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BidPolicy != nil {
+		in, out := &in.BidPolicy, &out.BidPolicy
+		*out = new(BidPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ManifestDelivery != nil {
+		in, out := &in.ManifestDelivery, &out.ManifestDelivery
+		*out = new(v1alpha1.ManifestDelivery)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CloseIfUnleasedFor != nil {
+		in, out := &in.CloseIfUnleasedFor, &out.CloseIfUnleasedFor
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.CloseAt != nil {
+		in, out := &in.CloseAt, &out.CloseAt
+		*out = (*in).DeepCopy()
+	}
+	if in.RecreateOnFailure != nil {
+		in, out := &in.RecreateOnFailure, &out.RecreateOnFailure
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowClosingUnowned != nil {
+		in, out := &in.AllowClosingUnowned, &out.AllowClosingUnowned
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = new(v1alpha1.Failover)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HealthChecks != nil {
+		in, out := &in.HealthChecks, &out.HealthChecks
+		*out = make([]v1alpha1.HealthCheck, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ServiceDependencies != nil {
+		in, out := &in.ServiceDependencies, &out.ServiceDependencies
+		*out = make([]v1alpha1.ServiceDependency, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LogForwarding != nil {
+		in, out := &in.LogForwarding, &out.LogForwarding
+		*out = new(v1alpha1.LogForwarding)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SpendTolerancePercent != nil {
+		in, out := &in.SpendTolerancePercent, &out.SpendTolerancePercent
+		*out = new(int)
+		**out = **in
+	}
+	if in.Owner != nil {
+		in, out := &in.Owner, &out.Owner
+		*out = new(string)
+		**out = **in
+	}
+	if in.MetadataPassthrough != nil {
+		in, out := &in.MetadataPassthrough, &out.MetadataPassthrough
+		*out = make([]v1alpha1.MetadataPassthrough, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ColocateWith != nil {
+		in, out := &in.ColocateWith, &out.ColocateWith
+		*out = new(string)
+		**out = **in
+	}
+	if in.Transaction != nil {
+		in, out := &in.Transaction, &out.Transaction
+		*out = new(v1alpha1.TransactionOverrides)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentParameters.
+func (in *DeploymentParameters) DeepCopy() *DeploymentParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentSpec) DeepCopyInto(out *DeploymentSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.NamespacedProviderConfigSpec.DeepCopyInto(&out.NamespacedProviderConfigSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentSpec.
+func (in *DeploymentSpec) DeepCopy() *DeploymentSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentStatus) DeepCopyInto(out *DeploymentStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentStatus.
+func (in *DeploymentStatus) DeepCopy() *DeploymentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Deposit) DeepCopyInto(out *Deposit) {
+	*out = *in
+	if in.Depositor != nil {
+		in, out := &in.Depositor, &out.Depositor
+		*out = new(string)
+		**out = **in
+	}
+	if in.AutoTopUp != nil {
+		in, out := &in.AutoTopUp, &out.AutoTopUp
+		*out = new(v1alpha1.AutoTopUp)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Deposit.
+func (in *Deposit) DeepCopy() *Deposit {
+	if in == nil {
+		return nil
+	}
+	out := new(Deposit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDL) DeepCopyInto(out *SDL) {
+	*out = *in
+	if in.Inline != nil {
+		in, out := &in.Inline, &out.Inline
+		*out = new(string)
+		**out = **in
+	}
+	if in.Ref != nil {
+		in, out := &in.Ref, &out.Ref
+		*out = new(v1alpha1.SDLReference)
+		**out = **in
+	}
+	if in.Checksum != nil {
+		in, out := &in.Checksum, &out.Checksum
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDL.
+func (in *SDL) DeepCopy() *SDL {
+	if in == nil {
+		return nil
+	}
+	out := new(SDL)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// +kubebuilder:object:root=true
+
+// A NamespacedProviderConfig configures the Akash provider for the resources
+// composed for claims in its namespace, e.g. with the wallet of a team, that
+// reference it with their namespacedProviderConfigRef instead of using their
+// ProviderConfig.
+// Its credentials must be a Secret in its namespace holding a mnemonic.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="SECRET-NAME",type="string",JSONPath=".spec.credentials.secretRef.name",priority=1
+// +kubebuilder:resource:scope=Namespaced
+type NamespacedProviderConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProviderConfigSpec   `json:"spec"`
+	Status ProviderConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NamespacedProviderConfigList contains a list of NamespacedProviderConfig.
+type NamespacedProviderConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NamespacedProviderConfig `json:"items"`
+}
+
+// NamespacedProviderConfig type metadata.
+var (
+	NamespacedProviderConfigKind             = reflect.TypeOf(NamespacedProviderConfig{}).Name()
+	NamespacedProviderConfigGroupKind        = schema.GroupKind{Group: Group, Kind: NamespacedProviderConfigKind}.String()
+	NamespacedProviderConfigKindAPIVersion   = NamespacedProviderConfigKind + "." + SchemeGroupVersion.String()
+	NamespacedProviderConfigGroupVersionKind = SchemeGroupVersion.WithKind(NamespacedProviderConfigKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&NamespacedProviderConfig{}, &NamespacedProviderConfigList{})
+}
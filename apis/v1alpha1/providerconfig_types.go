@@ -23,6 +23,8 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	resourcev1alpha1 "github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
 )
 
 // A ProviderConfigSpec defines the desired state of a ProviderConfig.
@@ -46,15 +48,15 @@ type ProviderCredentials struct {
 
 // AkashConfiguration contains Akash-specific configuration settings.
 type AkashConfiguration struct {
-	// KeyName is the name of the key to use for signing transactions.
+	// KeyName is deprecated and ignored. Transactions are signed with the key
+	// held by the credentials.
 	// +optional
-	// +kubebuilder:default="default"
 	KeyName *string `json:"keyName,omitempty"`
 
-	// KeyringBackend specifies the keyring backend to use.
+	// KeyringBackend is deprecated and ignored. No keyring is used, the key
+	// is loaded from the credentials in memory.
 	// +optional
 	// +kubebuilder:validation:Enum=os;file;test;memory
-	// +kubebuilder:default="test"
 	KeyringBackend *string `json:"keyringBackend,omitempty"`
 
 	// AccountAddress is the Akash account address to use.
@@ -67,9 +69,8 @@ type AkashConfiguration struct {
 	// +kubebuilder:default="mainnet"
 	Net *string `json:"net,omitempty"`
 
-	// Version specifies the Akash version to use.
+	// Version is deprecated and ignored. The Akash CLI is no longer run.
 	// +optional
-	// +kubebuilder:default="0.18.0"
 	Version *string `json:"version,omitempty"`
 
 	// ChainId is the chain ID of the Akash network.
@@ -77,30 +78,281 @@ type AkashConfiguration struct {
 	// +kubebuilder:default="akashnet-2"
 	ChainId *string `json:"chainId,omitempty"`
 
-	// Node is the RPC endpoint of the Akash node.
+	// ConfirmMainnetSpend must be true for transactions depositing funds,
+	// such as creating a deployment, to be broadcast on mainnet chain IDs.
+	// It keeps configurations copied from sandbox examples from spending
+	// real funds by accident.
+	// +optional
+	ConfirmMainnetSpend *bool `json:"confirmMainnetSpend,omitempty"`
+
+	// Node is the RPC endpoint of the Akash node. When unset, a healthy public
+	// endpoint of the selected network is discovered from the chain registry
+	// and recorded in status.endpoints. A configured node requires a
+	// GRPCEndpoint.
 	// +optional
-	// +kubebuilder:default="https://rpc.akashnet.io:443"
 	Node *string `json:"node,omitempty"`
 
-	// Home is the home directory for Akash configuration.
+	// Transport selects how the chain is queried. grpc queries it through
+	// GRPCEndpoint. cli is a deprecated alias of grpc, the Akash CLI is no
+	// longer run.
+	// +optional
+	// +kubebuilder:validation:Enum=cli;grpc
+	// +kubebuilder:default="grpc"
+	Transport *string `json:"transport,omitempty"`
+
+	// Home is the directory the client certificate is kept in.
 	// +optional
 	// +kubebuilder:default="/tmp/.akash"
 	Home *string `json:"home,omitempty"`
 
-	// Path is the path to the Akash binary.
+	// Path is deprecated and ignored. The Akash CLI is no longer run.
 	// +optional
-	// +kubebuilder:default="/usr/local/bin/akash"
 	Path *string `json:"path,omitempty"`
 
 	// ProvidersApi is the URL of the Akash providers API.
 	// +optional
 	// +kubebuilder:default="https://akash-api.polkachu.com"
 	ProvidersApi *string `json:"providersApi,omitempty"`
+
+	// IndexerApi is the URL of an indexer API, e.g. the Akash Console API,
+	// that deployment and lease state is read from when the node is
+	// unavailable. State read from it is marked as observed via fallback. It
+	// is never used for transactions. Unset disables the fallback.
+	// +optional
+	IndexerApi *string `json:"indexerApi,omitempty"`
+
+	// RestApi is the URL of the REST API of a node, which bids are queried
+	// from, with pagination, when no GRPCEndpoint is configured.
+	// +optional
+	RestApi *string `json:"restApi,omitempty"`
+
+	// GRPCEndpoint is the gRPC endpoint of a node, e.g.
+	// grpc.akashnet.net:443, which the chain is queried through over one
+	// connection shared by every resource using the endpoint. Endpoints are
+	// dialed with TLS when prefixed with https:// or on port 443. When unset,
+	// the endpoint discovered from the chain registry is used, unless a Node
+	// is configured.
+	// +optional
+	GRPCEndpoint *string `json:"grpcEndpoint,omitempty"`
+
+	// GasPrices are the prices paid per unit of gas of transactions, e.g.
+	// 0.025uakt. An amount without a denomination is in FeeDenom. Raise them
+	// when transactions are not included in congested blocks.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?([a-zA-Z][a-zA-Z0-9/]*)?$`
+	// +kubebuilder:default="0.025"
+	GasPrices *string `json:"gasPrices,omitempty"`
+
+	// GasAdjustment multiplies the gas estimated for transactions, e.g. 1.8
+	// to leave more headroom than the default of 1.5. Deployments may
+	// override it.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	GasAdjustment *string `json:"gasAdjustment,omitempty"`
+
+	// Fees are paid for each transaction, e.g. 50000uakt, instead of fees at
+	// the gas prices. An amount without a denomination is in FeeDenom.
+	// Deployments may override them with a fee cap.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+([a-zA-Z][a-zA-Z0-9/]*)?$`
+	Fees *string `json:"fees,omitempty"`
+
+	// FeeGranter is the address of an account, e.g. a treasury, that granted
+	// the account of this ProviderConfig a fee allowance, and pays the fees
+	// of its transactions.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^akash1[02-9ac-hj-np-z]+$`
+	FeeGranter *string `json:"feeGranter,omitempty"`
+
+	// FeeDenom is the denomination of gas prices and fees given without
+	// one, e.g. an IBC denomination of USDC.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z][a-zA-Z0-9/]*$`
+	// +kubebuilder:default="uakt"
+	FeeDenom *string `json:"feeDenom,omitempty"`
+
+	// BroadcastMode is how transactions are broadcast: sync waits for them
+	// to pass the checks of the node, async does not wait at all and block
+	// waits for them to be included in a block, which newer nodes no longer
+	// support. Transactions broadcast in sync or async mode are queried
+	// until they are included in a block.
+	// +optional
+	// +kubebuilder:validation:Enum=sync;async;block
+	// +kubebuilder:default=sync
+	BroadcastMode *string `json:"broadcastMode,omitempty"`
+
+	// TxConfirmationTimeout bounds how long a broadcast transaction is
+	// waited for to be included in a block.
+	// +optional
+	// +kubebuilder:default="1m"
+	TxConfirmationTimeout *metav1.Duration `json:"txConfirmationTimeout,omitempty"`
+
+	// Retry controls how queries and broadcasts failing with transient
+	// errors, e.g. timeouts, rate limits or a full mempool, are retried.
+	// Broadcasts are only retried when the transaction was not accepted.
+	// +optional
+	Retry *RetryPolicy `json:"retry,omitempty"`
+
+	// Timeouts bound the operations of clients, so that an unresponsive
+	// node or provider cannot block a reconcile indefinitely.
+	// +optional
+	Timeouts *OperationTimeouts `json:"timeouts,omitempty"`
+
+	// ChainRegistry is the base URL of the Cosmos chain registry, or of a
+	// mirror of it, used to discover endpoints when Node is unset.
+	// +optional
+	// +kubebuilder:default="https://raw.githubusercontent.com/cosmos/chain-registry/master"
+	ChainRegistry *string `json:"chainRegistry,omitempty"`
+
+	// EndpointRefreshInterval is how often discovered endpoints are checked
+	// for health and replaced.
+	// +optional
+	// +kubebuilder:default="1h"
+	EndpointRefreshInterval *metav1.Duration `json:"endpointRefreshInterval,omitempty"`
+
+	// GovernanceWatchInterval is how often governance proposals affecting
+	// deployments and the market, such as parameter changes and software
+	// upgrades, are polled and reported. Unset disables the watch.
+	// +optional
+	GovernanceWatchInterval *metav1.Duration `json:"governanceWatchInterval,omitempty"`
+
+	// DeploymentDefaults are filled into the unset fields of Deployments
+	// using this ProviderConfig when they are applied, so that their
+	// manifests stay short while the stored objects show the effective
+	// values.
+	// +optional
+	DeploymentDefaults *DeploymentDefaults `json:"deploymentDefaults,omitempty"`
+
+	// PromotionTargets are the ProviderConfigs Deployments using this
+	// ProviderConfig may be promoted to with the akash.web7.md/promote-to
+	// annotation. Promotion to any other ProviderConfig is refused, so that
+	// annotating a Deployment cannot create and fund one from another
+	// account.
+	// +optional
+	// +listType=set
+	PromotionTargets []string `json:"promotionTargets,omitempty"`
+}
+
+// DeploymentDefaults are the defaults of the spec of Deployments.
+type DeploymentDefaults struct {
+	// Deposit funding the escrow of Deployments. The Akash CLI default of
+	// 5000000uakt is filled in when unset.
+	// +optional
+	Deposit *resourcev1alpha1.Deposit `json:"deposit,omitempty"`
+
+	// BidSelection of Deployments. The lowestPrice strategy is filled in
+	// when unset.
+	// +optional
+	BidSelection *resourcev1alpha1.BidSelection `json:"bidSelection,omitempty"`
+
+	// MaxPrice of the bids of Deployments. Deployments accept bids at any
+	// price when unset.
+	// +optional
+	MaxPrice *resourcev1alpha1.MaxPrice `json:"maxPrice,omitempty"`
 }
 
 // A ProviderConfigStatus reflects the observed state of a ProviderConfig.
 type ProviderConfigStatus struct {
 	xpv1.ProviderConfigStatus `json:",inline"`
+
+	// Endpoints are the endpoints discovered from the chain registry.
+	// +optional
+	Endpoints *DiscoveredEndpoints `json:"endpoints,omitempty"`
+
+	// PendingProposals are the governance proposals in voting period that
+	// affect deployments or the market.
+	// +optional
+	PendingProposals []PendingProposal `json:"pendingProposals,omitempty"`
+}
+
+// ProposalKind is the kind of a governance proposal relevant to deployments.
+type ProposalKind string
+
+// Proposal kinds.
+const (
+	ProposalKindParamChange     ProposalKind = "ParamChange"
+	ProposalKindSoftwareUpgrade ProposalKind = "SoftwareUpgrade"
+)
+
+// A PendingProposal is a governance proposal that will change how deployments
+// behave on chain if it passes.
+type PendingProposal struct {
+	// ID of the proposal.
+	ID string `json:"id"`
+
+	// Kind of the proposal.
+	Kind ProposalKind `json:"kind"`
+
+	// Title of the proposal.
+	// +optional
+	Title string `json:"title,omitempty"`
+
+	// VotingEndTime is when voting on the proposal ends.
+	// +optional
+	VotingEndTime *metav1.Time `json:"votingEndTime,omitempty"`
+
+	// UpgradeHeight is the block height a software upgrade is planned at.
+	// +optional
+	UpgradeHeight int64 `json:"upgradeHeight,omitempty"`
+}
+
+// A RetryPolicy controls how chain calls failing with transient errors are
+// retried.
+type RetryPolicy struct {
+	// MaxAttempts bounds the attempts of a call, the first included. One
+	// disables retries.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=4
+	MaxAttempts *int `json:"maxAttempts,omitempty"`
+
+	// Backoff is waited before the first retry and doubled, with jitter,
+	// before every further one.
+	// +optional
+	// +kubebuilder:default="1s"
+	Backoff *metav1.Duration `json:"backoff,omitempty"`
+
+	// MaxBackoff caps the wait between retries.
+	// +optional
+	// +kubebuilder:default="15s"
+	MaxBackoff *metav1.Duration `json:"maxBackoff,omitempty"`
+}
+
+// OperationTimeouts bound the operations of clients.
+type OperationTimeouts struct {
+	// Query bounds a query of the chain, a provider or an API, retries
+	// included.
+	// +optional
+	// +kubebuilder:default="1m"
+	Query *metav1.Duration `json:"query,omitempty"`
+
+	// Transaction bounds a transaction from signing to its inclusion in a
+	// block. Transactions are not cancelled with the reconcile broadcasting
+	// them, as they may be included anyway.
+	// +optional
+	// +kubebuilder:default="3m"
+	Transaction *metav1.Duration `json:"transaction,omitempty"`
+
+	// Manifest bounds sending a manifest to a provider once.
+	// +optional
+	// +kubebuilder:default="2m"
+	Manifest *metav1.Duration `json:"manifest,omitempty"`
+}
+
+// DiscoveredEndpoints are the public endpoints chosen for a ProviderConfig
+// that does not configure its own node.
+type DiscoveredEndpoints struct {
+	// RPC is the chosen Tendermint RPC endpoint.
+	// +optional
+	RPC string `json:"rpc,omitempty"`
+
+	// GRPC is the chosen gRPC endpoint.
+	// +optional
+	GRPC string `json:"grpc,omitempty"`
+
+	// LastRefreshTime is when the endpoints were last discovered.
+	// +optional
+	LastRefreshTime *metav1.Time `json:"lastRefreshTime,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -21,6 +21,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	resourcev1alpha1 "github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -57,11 +59,21 @@ func (in *AkashConfiguration) DeepCopyInto(out *AkashConfiguration) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.ConfirmMainnetSpend != nil {
+		in, out := &in.ConfirmMainnetSpend, &out.ConfirmMainnetSpend
+		*out = new(bool)
+		**out = **in
+	}
 	if in.Node != nil {
 		in, out := &in.Node, &out.Node
 		*out = new(string)
 		**out = **in
 	}
+	if in.Transport != nil {
+		in, out := &in.Transport, &out.Transport
+		*out = new(string)
+		**out = **in
+	}
 	if in.Home != nil {
 		in, out := &in.Home, &out.Home
 		*out = new(string)
@@ -77,6 +89,91 @@ func (in *AkashConfiguration) DeepCopyInto(out *AkashConfiguration) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.IndexerApi != nil {
+		in, out := &in.IndexerApi, &out.IndexerApi
+		*out = new(string)
+		**out = **in
+	}
+	if in.RestApi != nil {
+		in, out := &in.RestApi, &out.RestApi
+		*out = new(string)
+		**out = **in
+	}
+	if in.GRPCEndpoint != nil {
+		in, out := &in.GRPCEndpoint, &out.GRPCEndpoint
+		*out = new(string)
+		**out = **in
+	}
+	if in.GasPrices != nil {
+		in, out := &in.GasPrices, &out.GasPrices
+		*out = new(string)
+		**out = **in
+	}
+	if in.GasAdjustment != nil {
+		in, out := &in.GasAdjustment, &out.GasAdjustment
+		*out = new(string)
+		**out = **in
+	}
+	if in.Fees != nil {
+		in, out := &in.Fees, &out.Fees
+		*out = new(string)
+		**out = **in
+	}
+	if in.FeeGranter != nil {
+		in, out := &in.FeeGranter, &out.FeeGranter
+		*out = new(string)
+		**out = **in
+	}
+	if in.FeeDenom != nil {
+		in, out := &in.FeeDenom, &out.FeeDenom
+		*out = new(string)
+		**out = **in
+	}
+	if in.BroadcastMode != nil {
+		in, out := &in.BroadcastMode, &out.BroadcastMode
+		*out = new(string)
+		**out = **in
+	}
+	if in.TxConfirmationTimeout != nil {
+		in, out := &in.TxConfirmationTimeout, &out.TxConfirmationTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(RetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timeouts != nil {
+		in, out := &in.Timeouts, &out.Timeouts
+		*out = new(OperationTimeouts)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ChainRegistry != nil {
+		in, out := &in.ChainRegistry, &out.ChainRegistry
+		*out = new(string)
+		**out = **in
+	}
+	if in.EndpointRefreshInterval != nil {
+		in, out := &in.EndpointRefreshInterval, &out.EndpointRefreshInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.GovernanceWatchInterval != nil {
+		in, out := &in.GovernanceWatchInterval, &out.GovernanceWatchInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.DeploymentDefaults != nil {
+		in, out := &in.DeploymentDefaults, &out.DeploymentDefaults
+		*out = new(DeploymentDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PromotionTargets != nil {
+		in, out := &in.PromotionTargets, &out.PromotionTargets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AkashConfiguration.
@@ -89,6 +186,163 @@ func (in *AkashConfiguration) DeepCopy() *AkashConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentDefaults) DeepCopyInto(out *DeploymentDefaults) {
+	*out = *in
+	if in.Deposit != nil {
+		in, out := &in.Deposit, &out.Deposit
+		*out = new(resourcev1alpha1.Deposit)
+		**out = **in
+	}
+	if in.BidSelection != nil {
+		in, out := &in.BidSelection, &out.BidSelection
+		*out = new(resourcev1alpha1.BidSelection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxPrice != nil {
+		in, out := &in.MaxPrice, &out.MaxPrice
+		*out = new(resourcev1alpha1.MaxPrice)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentDefaults.
+func (in *DeploymentDefaults) DeepCopy() *DeploymentDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiscoveredEndpoints) DeepCopyInto(out *DiscoveredEndpoints) {
+	*out = *in
+	if in.LastRefreshTime != nil {
+		in, out := &in.LastRefreshTime, &out.LastRefreshTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoveredEndpoints.
+func (in *DiscoveredEndpoints) DeepCopy() *DiscoveredEndpoints {
+	if in == nil {
+		return nil
+	}
+	out := new(DiscoveredEndpoints)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacedProviderConfig) DeepCopyInto(out *NamespacedProviderConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedProviderConfig.
+func (in *NamespacedProviderConfig) DeepCopy() *NamespacedProviderConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacedProviderConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespacedProviderConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacedProviderConfigList) DeepCopyInto(out *NamespacedProviderConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NamespacedProviderConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedProviderConfigList.
+func (in *NamespacedProviderConfigList) DeepCopy() *NamespacedProviderConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacedProviderConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespacedProviderConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationTimeouts) DeepCopyInto(out *OperationTimeouts) {
+	*out = *in
+	if in.Query != nil {
+		in, out := &in.Query, &out.Query
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Transaction != nil {
+		in, out := &in.Transaction, &out.Transaction
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Manifest != nil {
+		in, out := &in.Manifest, &out.Manifest
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationTimeouts.
+func (in *OperationTimeouts) DeepCopy() *OperationTimeouts {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationTimeouts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingProposal) DeepCopyInto(out *PendingProposal) {
+	*out = *in
+	if in.VotingEndTime != nil {
+		in, out := &in.VotingEndTime, &out.VotingEndTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingProposal.
+func (in *PendingProposal) DeepCopy() *PendingProposal {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingProposal)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProviderConfig) DeepCopyInto(out *ProviderConfig) {
 	*out = *in
@@ -173,6 +427,18 @@ func (in *ProviderConfigSpec) DeepCopy() *ProviderConfigSpec {
 func (in *ProviderConfigStatus) DeepCopyInto(out *ProviderConfigStatus) {
 	*out = *in
 	in.ProviderConfigStatus.DeepCopyInto(&out.ProviderConfigStatus)
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = new(DiscoveredEndpoints)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PendingProposals != nil {
+		in, out := &in.PendingProposals, &out.PendingProposals
+		*out = make([]PendingProposal, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderConfigStatus.
@@ -259,6 +525,36 @@ func (in *ProviderCredentials) DeepCopy() *ProviderCredentials {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryPolicy) DeepCopyInto(out *RetryPolicy) {
+	*out = *in
+	if in.MaxAttempts != nil {
+		in, out := &in.MaxAttempts, &out.MaxAttempts
+		*out = new(int)
+		**out = **in
+	}
+	if in.Backoff != nil {
+		in, out := &in.Backoff, &out.Backoff
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxBackoff != nil {
+		in, out := &in.MaxBackoff, &out.MaxBackoff
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryPolicy.
+func (in *RetryPolicy) DeepCopy() *RetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StoreConfig) DeepCopyInto(out *StoreConfig) {
 	*out = *in
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/overlock-network/provider-akash/apis"
+	"github.com/overlock-network/provider-akash/apis/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+)
+
+// check validates the named ProviderConfig end-to-end and writes a JSON report
+// to out. It returns whether every check passed.
+func check(ctx context.Context, cfg *rest.Config, providerConfig string, sdl string, out io.Writer) (bool, error) {
+	s := runtime.NewScheme()
+	if err := apis.AddToScheme(s); err != nil {
+		return false, errors.Wrap(err, "cannot add Akash APIs to scheme")
+	}
+	if err := corev1.AddToScheme(s); err != nil {
+		return false, errors.Wrap(err, "cannot add core APIs to scheme")
+	}
+
+	kube, err := kubeclient.New(cfg, kubeclient.Options{Scheme: s})
+	if err != nil {
+		return false, errors.Wrap(err, "cannot create Kubernetes client")
+	}
+
+	pc := &v1alpha1.ProviderConfig{}
+	if err := kube.Get(ctx, types.NamespacedName{Name: providerConfig}, pc); err != nil {
+		return false, errors.Wrap(err, "cannot get ProviderConfig")
+	}
+
+	// No managed resource uses the client, so there is no usage to track.
+	ak, err := client.NewFromManagedResource(ctx, kube, nil, nil, client.ProviderConfigInfo{
+		Source:              pc.Spec.Credentials.Source,
+		CredentialSelectors: pc.Spec.Credentials.CommonCredentialSelectors,
+		Configuration:       pc.Spec.Configuration,
+		Endpoints:           pc.Status.Endpoints,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	report := ak.Check(ctx, sdl)
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return false, errors.Wrap(err, "cannot write report")
+	}
+
+	return report.Passed, nil
+}
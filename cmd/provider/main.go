@@ -22,13 +22,17 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
 	"gopkg.in/alecthomas/kingpin.v2"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	ctrlwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/controller"
@@ -39,28 +43,80 @@ import (
 
 	"github.com/overlock-network/provider-akash/apis"
 	"github.com/overlock-network/provider-akash/apis/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/billing"
+	akashclient "github.com/overlock-network/provider-akash/internal/client"
 	akash "github.com/overlock-network/provider-akash/internal/controller"
+	"github.com/overlock-network/provider-akash/internal/controller/deployment"
 	"github.com/overlock-network/provider-akash/internal/features"
+	"github.com/overlock-network/provider-akash/internal/statestore"
+	"github.com/overlock-network/provider-akash/internal/tracing"
+	"github.com/overlock-network/provider-akash/internal/webhook"
 )
 
 func main() {
 	var (
 		app            = kingpin.New(filepath.Base(os.Args[0]), "Akash support for Crossplane.").DefaultEnvars()
 		debug          = app.Flag("debug", "Run with debug logging.").Short('d').Bool()
+		verbosity      = app.Flag("verbosity", "Log verbosity: 0 logs what the provider does, 1 adds debug messages, e.g. the bids collected for every deployment. --debug implies 1.").Default("0").Envar("VERBOSITY").Int()
 		leaderElection = app.Flag("leader-election", "Use leader election for the controller manager.").Short('l').Default("false").OverrideDefaultFromEnvar("LEADER_ELECTION").Bool()
 
 		syncInterval     = app.Flag("sync", "How often all resources will be double-checked for drift from the desired state.").Short('s').Default("1h").Duration()
 		pollInterval     = app.Flag("poll", "How often individual resources will be checked for drift from the desired state").Default("1m").Duration()
 		maxReconcileRate = app.Flag("max-reconcile-rate", "The global maximum rate per second at which resources may checked for drift from the desired state.").Default("10").Int()
 
+		watchLabelSelector  = app.Flag("watch-label-selector", "Only cache and reconcile managed resources matching this label selector.").Default("").Envar("WATCH_LABEL_SELECTOR").String()
+		stripCachePayloads  = app.Flag("strip-cache-payloads", "Drop managed fields from cached objects to reduce memory usage.").Default("true").Envar("STRIP_CACHE_PAYLOADS").Bool()
+		secretLabelSelector = app.Flag("secret-label-selector", "Only watch Secrets matching this label selector, e.g. those holding SDLs.").Default("").Envar("SECRET_LABEL_SELECTOR").String()
+		clientPoolIdle      = app.Flag("client-pool-idle-timeout", "How long the credentials shared by the clients of a ProviderConfig are kept unused. Zero extracts credentials on every reconcile.").Default(akashclient.DefaultPoolIdleTimeout.String()).Envar("CLIENT_POOL_IDLE_TIMEOUT").Duration()
+		cacheSecrets        = app.Flag("cache-secrets", "Cache Secrets in memory instead of reading credentials directly from the API server.").Default("false").Envar("CACHE_SECRETS").Bool()
+
+		stateStore          = app.Flag("state-store", "Where operational state such as sequence hints, provider blacklists and observed prices is kept.").Default("memory").Envar("STATE_STORE").Enum("memory", "configmap")
+		stateStoreConfigMap = app.Flag("state-store-configmap", "Name of the ConfigMap in the provider namespace holding operational state when --state-store=configmap.").Default("provider-akash-state").Envar("STATE_STORE_CONFIGMAP").String()
+
+		billingExportInterval = app.Flag("billing-export-interval", "How often escrow payments are exported per deployment. Zero disables the export.").Default("0").Envar("BILLING_EXPORT_INTERVAL").Duration()
+		billingSink           = app.Flag("billing-sink", "Where exported escrow payments are written.").Default("prometheus").Envar("BILLING_SINK").Enum("prometheus", "csv", "http")
+		billingCSVPath        = app.Flag("billing-csv-path", "CSV file, e.g. on a persistent volume, escrow payments are appended to when --billing-sink=csv.").Default("/var/lib/provider-akash/payments.csv").Envar("BILLING_CSV_PATH").String()
+		billingHTTPURL        = app.Flag("billing-http-url", "Endpoint escrow payments are posted to as JSON when --billing-sink=http.").Envar("BILLING_HTTP_URL").String()
+
+		chainEvents  = app.Flag("chain-events", "Subscribe to deployment, lease and bid events over the WebSocket of the node of every ProviderConfig and reconcile the Deployments they concern right away instead of at the next poll.").Default("false").Envar("CHAIN_EVENTS").Bool()
+		fleetMetrics = app.Flag("fleet-metrics", "Export metrics summarizing Deployments per ProviderConfig for fleet dashboards.").Default("false").Envar("FLEET_METRICS").Bool()
+
+		traceExporter    = app.Flag("trace-exporter", "Where spans of reconciles and the chain calls they make are exported.").Default(tracing.ExporterNone).Envar("TRACE_EXPORTER").Enum(tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout)
+		traceEndpoint    = app.Flag("trace-endpoint", "Host and port of the OTLP gRPC collector spans are exported to when --trace-exporter=otlp. Defaults to OTEL_EXPORTER_OTLP_ENDPOINT.").Default("").Envar("TRACE_ENDPOINT").String()
+		traceInsecure    = app.Flag("trace-insecure", "Export spans to the OTLP collector without TLS.").Default("false").Envar("TRACE_INSECURE").Bool()
+		traceSampleRatio = app.Flag("trace-sample-ratio", "Fraction of reconciles traced, between 0 and 1.").Default("1").Envar("TRACE_SAMPLE_RATIO").Float64()
+
 		namespace                  = app.Flag("namespace", "Namespace used to set as default scope in default secret store config.").Default("crossplane-system").Envar("POD_NAMESPACE").String()
 		enableExternalSecretStores = app.Flag("enable-external-secret-stores", "Enable support for ExternalSecretStores.").Default("false").Envar("ENABLE_EXTERNAL_SECRET_STORES").Bool()
+		certsDir                   = app.Flag("certs-dir", "Directory holding the tls.crt and tls.key the admission webhooks are served with. Webhooks are only served when it holds a certificate.").Default("/tls/server").Envar("TLS_SERVER_CERTS_DIR").String()
 		enableManagementPolicies   = app.Flag("enable-management-policies", "Enable support for Management Policies.").Default("false").Envar("ENABLE_MANAGEMENT_POLICIES").Bool()
+
+		_ = app.Command("start", "Start the provider controllers.").Default()
+
+		checkCmd            = app.Command("check", "Validate a ProviderConfig end-to-end and print a JSON report.")
+		checkProviderConfig = checkCmd.Flag("provider-config", "Name of the ProviderConfig to check.").Required().String()
+		checkSDL            = checkCmd.Flag("sdl", "Path of an SDL file used to simulate a deployment creation.").String()
 	)
-	kingpin.MustParse(app.Parse(os.Args[1:]))
+	cmd := kingpin.MustParse(app.Parse(os.Args[1:]))
+
+	if cmd == checkCmd.FullCommand() {
+		cfg, err := ctrl.GetConfig()
+		kingpin.FatalIfError(err, "Cannot get API server rest config")
 
-	zl := zap.New(zap.UseDevMode(*debug))
+		passed, err := check(context.Background(), cfg, *checkProviderConfig, *checkSDL, os.Stdout)
+		kingpin.FatalIfError(err, "Cannot check ProviderConfig")
+		if !passed {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *debug && *verbosity < 1 {
+		*verbosity = 1
+	}
+	zl := zap.New(zap.UseDevMode(*debug), zap.Level(zapcore.Level(-*verbosity)))
 	log := logging.NewLogrLogger(zl.WithName("provider-akash"))
+	akashclient.SetLogger(log.WithValues("component", "client"))
 	if *debug {
 		// The controller-runtime runs with a no-op logger by default. It is
 		// *very* verbose even at info level, so we only provide it a real
@@ -68,15 +124,30 @@ func main() {
 		ctrl.SetLogger(zl)
 	}
 
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter:    *traceExporter,
+		Endpoint:    *traceEndpoint,
+		Insecure:    *traceInsecure,
+		SampleRatio: *traceSampleRatio,
+	})
+	kingpin.FatalIfError(err, "Cannot setup tracing")
+
 	cfg, err := ctrl.GetConfig()
 	kingpin.FatalIfError(err, "Cannot get API server rest config")
 
+	selector, err := labels.Parse(*watchLabelSelector)
+	kingpin.FatalIfError(err, "Cannot parse watch label selector")
+
+	secretSelector, err := labels.Parse(*secretLabelSelector)
+	kingpin.FatalIfError(err, "Cannot parse secret label selector")
+
 	mgr, err := ctrl.NewManager(ratelimiter.LimitRESTConfig(cfg, *maxReconcileRate), ctrl.Options{
 		// SyncPeriod in ctrl.Options has been removed since controller-runtime v0.16.0
 		// The recommended way is to move it to cache.Options instead
-		Cache: cache.Options{
-			SyncPeriod: syncInterval,
-		},
+		Cache:  akash.CacheOptions(syncInterval, selector, secretSelector, *stripCachePayloads),
+		Client: akash.ClientOptions(*cacheSecrets),
+
+		WebhookServer: ctrlwebhook.NewServer(ctrlwebhook.Options{CertDir: *certsDir}),
 
 		// controller-runtime uses both ConfigMaps and Leases for leader
 		// election by default. Leases expire after 15 seconds, with a
@@ -88,8 +159,11 @@ func main() {
 		LeaderElection:             *leaderElection,
 		LeaderElectionID:           "crossplane-leader-election-provider-akash",
 		LeaderElectionResourceLock: resourcelock.LeasesResourceLock,
-		LeaseDuration:              func() *time.Duration { d := 60 * time.Second; return &d }(),
-		RenewDeadline:              func() *time.Duration { d := 50 * time.Second; return &d }(),
+		// Release the lease on shutdown so a standby replica takes over
+		// without waiting for it to expire.
+		LeaderElectionReleaseOnCancel: true,
+		LeaseDuration:                 func() *time.Duration { d := 60 * time.Second; return &d }(),
+		RenewDeadline:                 func() *time.Duration { d := 50 * time.Second; return &d }(),
 	})
 	kingpin.FatalIfError(err, "Cannot create controller manager")
 	kingpin.FatalIfError(apis.AddToScheme(mgr.GetScheme()), "Cannot add Akash APIs to scheme")
@@ -126,6 +200,67 @@ func main() {
 		log.Info("Alpha feature enabled", "flag", features.EnableAlphaManagementPolicies)
 	}
 
+	if *leaderElection {
+		// Controllers only run on the elected replica, but guard transactions
+		// as well so a standby replica can never broadcast them.
+		akashclient.SetBroadcastGate(func() error {
+			select {
+			case <-mgr.Elected():
+				return nil
+			default:
+				return errors.New("this replica is not the elected leader")
+			}
+		})
+	}
+
+	akashclient.SetPoolIdleTimeout(*clientPoolIdle)
+
+	if *stateStore == "configmap" {
+		akashclient.SetStateStore(statestore.NewConfigMap(mgr.GetClient(), mgr.GetAPIReader(), *namespace, *stateStoreConfigMap))
+		log.Info("Persisting operational state", "configmap", *namespace+"/"+*stateStoreConfigMap)
+	}
+
 	kingpin.FatalIfError(akash.Setup(mgr, o), "Cannot setup Akash controllers")
-	kingpin.FatalIfError(mgr.Start(ctrl.SetupSignalHandler()), "Cannot start controller manager")
+
+	// Crossplane mounts the certificate of the webhooks of the package into
+	// the provider. Without it, e.g. when running out of cluster, there is
+	// nothing to serve them with.
+	if _, err := os.Stat(filepath.Join(*certsDir, "tls.crt")); err == nil {
+		kingpin.FatalIfError(webhook.SetupProviderConfig(mgr), "Cannot setup ProviderConfig webhook")
+		kingpin.FatalIfError(webhook.SetupDeployment(mgr), "Cannot setup Deployment webhook")
+	} else {
+		log.Info("Admission webhooks disabled, no certificate found", "dir", *certsDir)
+	}
+
+	if *billingExportInterval > 0 {
+		var sink billing.Sink
+		switch *billingSink {
+		case "csv":
+			sink = billing.NewCSVSink(*billingCSVPath)
+		case "http":
+			if *billingHTTPURL == "" {
+				kingpin.Fatalf("--billing-http-url is required when --billing-sink=http")
+			}
+			sink = billing.NewHTTPSink(*billingHTTPURL)
+		default:
+			sink, err = billing.NewPrometheusSink(metrics.Registry)
+			kingpin.FatalIfError(err, "Cannot register billing metrics")
+		}
+		kingpin.FatalIfError(deployment.SetupBillingExport(mgr, log, sink, *billingExportInterval), "Cannot setup billing export")
+	}
+	if *chainEvents {
+		kingpin.FatalIfError(deployment.SetupChainEvents(mgr, log), "Cannot setup chain event watcher")
+	}
+	if *fleetMetrics {
+		kingpin.FatalIfError(metrics.Registry.Register(deployment.NewFleetCollector(mgr.GetClient(), log)), "Cannot register fleet metrics")
+	}
+	err = mgr.Start(ctrl.SetupSignalHandler())
+
+	// Flush the spans of the last reconciles before exiting.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if serr := shutdownTracing(ctx); serr != nil {
+		log.Info("Cannot flush spans", "error", serr)
+	}
+	cancel()
+	kingpin.FatalIfError(err, "Cannot start controller manager")
 }
@@ -0,0 +1,26 @@
+#!/usr/bin/env bash
+
+# Copyright 2024 The Akash Provider Authors.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Makes the API server convert the versions of the given CRDs with the
+# conversion webhook of the provider, which controller-gen has no marker for.
+# Crossplane fills in the client config of the webhook when it installs the
+# package.
+set -euo pipefail
+
+for crd in "$@"; do
+  awk '{ print } /^spec:$/ && !done { print "  conversion:\n    strategy: Webhook\n    webhook:\n      conversionReviewVersions:\n      - v1"; done = 1 }' "${crd}" > "${crd}.tmp"
+  mv "${crd}.tmp" "${crd}"
+done
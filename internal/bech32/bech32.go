@@ -0,0 +1,131 @@
+// Package bech32 encodes and validates bech32 addresses, e.g. akash1..., as
+// specified by BIP 173.
+package bech32
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// EncodeAddress returns the bech32 string of the address bytes with the
+// human-readable part hrp, e.g. akash.
+func EncodeAddress(hrp string, address []byte) string {
+	data := convertBits(address, 8, 5)
+	values := append(expand(hrp), data...)
+	chk := polymod(append(values, 0, 0, 0, 0, 0, 0)) ^ 1
+
+	var b strings.Builder
+	b.WriteString(hrp)
+	b.WriteByte('1')
+	for _, v := range data {
+		b.WriteByte(charset[v])
+	}
+	for i := 0; i < 6; i++ {
+		b.WriteByte(charset[(chk>>(5*(5-i)))&31])
+	}
+	return b.String()
+}
+
+// convertBits regroups data of from bits per value into values of to bits,
+// padding the last one with zeros.
+func convertBits(data []byte, from, to uint) []byte {
+	out := make([]byte, 0, len(data)*int(from)/int(to)+1)
+	acc, bits := uint32(0), uint(0)
+	for _, v := range data {
+		acc = acc<<from | uint32(v)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits)&(1<<to-1))
+		}
+	}
+	if bits > 0 {
+		out = append(out, byte(acc<<(to-bits))&(1<<to-1))
+	}
+	return out
+}
+
+// maxLength is the maximum length of a bech32 string.
+const maxLength = 90
+
+// Decode returns the human-readable part and the 5-bit data of s, without
+// its checksum. It fails when s is not a valid bech32 string.
+func Decode(s string) (string, []byte, error) {
+	if len(s) > maxLength {
+		return "", nil, errors.Errorf("%d characters exceed the maximum of %d", len(s), maxLength)
+	}
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, errors.New("mixed case")
+	}
+	s = strings.ToLower(s)
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 1 || sep+7 > len(s) {
+		return "", nil, errors.New("missing separator, human-readable part or checksum")
+	}
+	hrp := s[:sep]
+	for _, c := range hrp {
+		if c < 33 || c > 126 {
+			return "", nil, errors.Errorf("invalid character %q in human-readable part", c)
+		}
+	}
+	data := make([]byte, 0, len(s)-sep-1)
+	for _, c := range s[sep+1:] {
+		i := strings.IndexRune(charset, c)
+		if i < 0 {
+			return "", nil, errors.Errorf("invalid character %q", c)
+		}
+		data = append(data, byte(i))
+	}
+	if polymod(append(expand(hrp), data...)) != 1 {
+		return "", nil, errors.New("invalid checksum")
+	}
+	return hrp, data[:len(data)-6], nil
+}
+
+// ValidateAddress returns an error unless address is a valid bech32 address
+// with the human-readable part hrp, e.g. akash.
+func ValidateAddress(address, hrp string) error {
+	got, data, err := Decode(address)
+	if err != nil {
+		return err
+	}
+	if got != hrp {
+		return errors.Errorf("prefix is %s, want %s", got, hrp)
+	}
+	if len(data) == 0 {
+		return errors.New("empty address")
+	}
+	return nil
+}
+
+// expand returns the values of the human-readable part that the checksum
+// covers.
+func expand(hrp string) []byte {
+	out := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+func polymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
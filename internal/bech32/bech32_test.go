@@ -0,0 +1,63 @@
+package bech32
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestEncodeAddress(t *testing.T) {
+	cases := map[string]struct {
+		hrp     string
+		address string
+		want    string
+	}{
+		"Akash": {
+			hrp:     "akash",
+			address: "4c1029697ee358715d3a14a2add817c4b0165144",
+			want:    "akash1fsgzj6t7udv8zhf6zj32mkqhcjcpv52y9trpyw",
+		},
+		"Cosmos": {
+			hrp:     "cosmos",
+			address: "4c1029697ee358715d3a14a2add817c4b0165144",
+			want:    "cosmos1fsgzj6t7udv8zhf6zj32mkqhcjcpv52ygswxa5",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			address, err := hex.DecodeString(tc.address)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := EncodeAddress(tc.hrp, address); got != tc.want {
+				t.Errorf("EncodeAddress(%q, %s): want %s, got %s", tc.hrp, tc.address, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateAddress(t *testing.T) {
+	cases := map[string]struct {
+		address string
+		err     bool
+	}{
+		"Valid":           {address: "akash1fsgzj6t7udv8zhf6zj32mkqhcjcpv52y9trpyw"},
+		"UpperCase":       {address: "AKASH1FSGZJ6T7UDV8ZHF6ZJ32MKQHCJCPV52Y9TRPYW"},
+		"MixedCase":       {address: "akash1FSGZJ6T7UDV8ZHF6ZJ32MKQHCJCPV52Y9TRPYW", err: true},
+		"InvalidChecksum": {address: "akash1fsgzj6t7udv8zhf6zj32mkqhcjcpv52y9trpyx", err: true},
+		"InvalidChar":     {address: "akash1bsgzj6t7udv8zhf6zj32mkqhcjcpv52y9trpyw", err: true},
+		"OtherPrefix":     {address: "cosmos1fsgzj6t7udv8zhf6zj32mkqhcjcpv52ygswxa5", err: true},
+		"NoSeparator":     {address: "akashfsgzj6t7udv8zhf6zj32mkqhcjcpv52y9trpyw", err: true},
+		"Truncated":       {address: "akash1", err: true},
+		"Empty":           {address: "", err: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateAddress(tc.address, "akash")
+			if (err != nil) != tc.err {
+				t.Errorf("ValidateAddress(%q): want error %t, got %v", tc.address, tc.err, err)
+			}
+		})
+	}
+}
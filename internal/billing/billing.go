@@ -0,0 +1,169 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package billing exports the escrow payments made to providers for each
+// Deployment to billing systems.
+package billing
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// A Record is an amount paid to a provider for a Deployment over a period.
+type Record struct {
+	Deployment string `json:"deployment"`
+	Dseq       string `json:"dseq"`
+	Provider   string `json:"provider"`
+
+	// PeriodStart is zero for the first record of a lease, which covers all
+	// payments since it was created.
+	PeriodStart time.Time `json:"periodStart,omitempty"`
+	PeriodEnd   time.Time `json:"periodEnd"`
+
+	// Amount is the exact decimal amount paid.
+	Amount string `json:"amount"`
+	Denom  string `json:"denom"`
+}
+
+// A Sink receives payment records.
+type Sink interface {
+	Write(ctx context.Context, records []Record) error
+}
+
+var csvHeader = []string{"period_start", "period_end", "deployment", "dseq", "provider", "amount", "denom"}
+
+// CSVSink appends records to a CSV file, e.g. on a persistent volume.
+type CSVSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewCSVSink returns a Sink appending records to the CSV file at path, which
+// is created with a header row if it does not exist.
+func NewCSVSink(path string) *CSVSink {
+	return &CSVSink{path: path}
+}
+
+// Write appends records to the CSV file.
+func (s *CSVSink) Write(_ context.Context, records []Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := os.Stat(s.path)
+	create := os.IsNotExist(err)
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "cannot open billing CSV file")
+	}
+
+	w := csv.NewWriter(f)
+	if create {
+		_ = w.Write(csvHeader)
+	}
+	for _, r := range records {
+		start := ""
+		if !r.PeriodStart.IsZero() {
+			start = r.PeriodStart.UTC().Format(time.RFC3339)
+		}
+		_ = w.Write([]string{start, r.PeriodEnd.UTC().Format(time.RFC3339), r.Deployment, r.Dseq, r.Provider, r.Amount, r.Denom})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		_ = f.Close()
+		return errors.Wrap(err, "cannot write billing CSV file")
+	}
+	return errors.Wrap(f.Close(), "cannot close billing CSV file")
+}
+
+// HTTPSink posts records as a JSON array to an HTTP endpoint.
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPSink returns a Sink posting records to url.
+func NewHTTPSink(url string) *HTTPSink {
+	return &HTTPSink{url: url, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Write posts records to the endpoint, which must answer with a 2xx status.
+func (s *HTTPSink) Write(ctx context.Context, records []Record) error {
+	body, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "cannot post billing records")
+	}
+	defer resp.Body.Close() //nolint:errcheck // Nothing is read from the body.
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("billing endpoint response status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// PrometheusSink adds records to a counter of payments per deployment,
+// provider and denom, to be scraped, or remote-written, by Prometheus.
+type PrometheusSink struct {
+	payments *prometheus.CounterVec
+}
+
+// NewPrometheusSink returns a Sink counting payments in a metric registered
+// with the given registerer.
+func NewPrometheusSink(r prometheus.Registerer) (*PrometheusSink, error) {
+	payments := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "akash_escrow_payments_total",
+		Help: "Amount paid to providers from the escrow of deployments.",
+	}, []string{"deployment", "provider", "denom"})
+	if err := r.Register(payments); err != nil {
+		return nil, err
+	}
+	return &PrometheusSink{payments: payments}, nil
+}
+
+// Write adds the amount of each record to the counter.
+func (s *PrometheusSink) Write(_ context.Context, records []Record) error {
+	for _, r := range records {
+		amount, err := strconv.ParseFloat(r.Amount, 64)
+		if err != nil {
+			return errors.Wrapf(err, "invalid amount %q", r.Amount)
+		}
+		s.payments.WithLabelValues(r.Deployment, r.Provider, r.Denom).Add(amount)
+	}
+	return nil
+}
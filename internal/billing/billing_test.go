@@ -0,0 +1,55 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package billing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCSVSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "payments.csv")
+	s := NewCSVSink(path)
+
+	end := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	writes := [][]Record{
+		{{Deployment: "web", Dseq: "1", Provider: "akash1p", PeriodEnd: end, Amount: "1200.5", Denom: "uakt"}},
+		{{Deployment: "web", Dseq: "1", Provider: "akash1p", PeriodStart: end, PeriodEnd: end.Add(time.Hour), Amount: "99.5", Denom: "uakt"}},
+	}
+	for _, records := range writes {
+		if err := s.Write(context.Background(), records); err != nil {
+			t.Fatalf("Write(...): %v", err)
+		}
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `period_start,period_end,deployment,dseq,provider,amount,denom
+,2024-06-01T00:00:00Z,web,1,akash1p,1200.5,uakt
+2024-06-01T00:00:00Z,2024-06-01T01:00:00Z,web,1,akash1p,99.5,uakt
+`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("Write(...): -want, +got:\n%s", diff)
+	}
+}
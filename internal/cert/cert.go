@@ -0,0 +1,204 @@
+// Package cert manages the client certificate an owner authenticates to
+// provider gateways with. The certificate is generated locally, published on
+// chain, kept in the home directory of the Akash tools, cached in memory and
+// rotated before it expires.
+package cert
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+const (
+	errGenerate     = "cannot generate client certificate"
+	errLoad         = "cannot load client certificate"
+	errWrite        = "cannot write client certificate"
+	errPublish      = "cannot publish client certificate"
+	errPublished    = "cannot get published client certificates"
+	errEncryptedKey = "client certificate key in %s is encrypted"
+)
+
+const (
+	// Validity of generated certificates.
+	Validity = 365 * 24 * time.Hour
+
+	// RenewBefore is how long before it expires a certificate is replaced.
+	RenewBefore = 30 * 24 * time.Hour
+)
+
+// A Chain publishes client certificates and lists the valid ones.
+type Chain interface {
+	// PublishClientCertificate publishes the certificate of the file at
+	// path on chain.
+	PublishClientCertificate(ctx context.Context, path string) error
+
+	// GetCertificates returns the valid certificates published by owner.
+	GetCertificates(ctx context.Context, owner string) (types.Certificates, error)
+}
+
+// cache holds the certificates known to be valid on chain by the path of
+// their file, shared by every client of the process.
+var cache sync.Map
+
+// Path returns the path of the certificate file of owner in home, where the
+// Akash tools keep it too.
+func Path(home string, owner string) string {
+	return filepath.Join(home, owner+".pem")
+}
+
+// Ensure returns a client certificate of owner that is valid on chain. The
+// certificate in home is used while it is published and not about to expire.
+// Otherwise a new one is generated, written to home and published.
+func Ensure(ctx context.Context, chain Chain, home string, owner string, now time.Time) (tls.Certificate, error) {
+	path := Path(home, owner)
+	if c, ok := cache.Load(path); ok && !expiring(c.(tls.Certificate), now) {
+		return c.(tls.Certificate), nil
+	}
+
+	c, err := Load(path)
+	if err == nil && !expiring(c, now) {
+		published, perr := chain.GetCertificates(ctx, owner)
+		if perr != nil {
+			return tls.Certificate{}, errors.Wrap(perr, errPublished)
+		}
+		if isPublished(c, published) {
+			cache.Store(path, c)
+			return c, nil
+		}
+	}
+
+	certPEM, keyPEM, err := Generate(owner, now)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	if err := Write(path, certPEM, keyPEM); err != nil {
+		return tls.Certificate{}, err
+	}
+	if err := chain.PublishClientCertificate(ctx, path); err != nil {
+		return tls.Certificate{}, errors.Wrap(err, errPublish)
+	}
+
+	c, err = keyPair(certPEM, keyPEM)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	cache.Store(path, c)
+	return c, nil
+}
+
+// expiring reports whether c expires within RenewBefore of now.
+func expiring(c tls.Certificate, now time.Time) bool {
+	return c.Leaf == nil || now.Add(RenewBefore).After(c.Leaf.NotAfter)
+}
+
+// isPublished reports whether c is among the published certificates.
+func isPublished(c tls.Certificate, published types.Certificates) bool {
+	serial := c.Leaf.SerialNumber.String()
+	for _, p := range published.Certificates {
+		if p.Serial == serial {
+			return true
+		}
+	}
+	return false
+}
+
+// Generate returns a new PEM encoded client certificate of owner, valid from
+// now for Validity, and its PEM encoded private key.
+func Generate(owner string, now time.Time) ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errGenerate)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errGenerate)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: owner},
+		Issuer:                pkix.Name{CommonName: owner},
+		NotBefore:             now,
+		NotAfter:              now.Add(Validity),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDataEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errGenerate)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errGenerate)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), nil
+}
+
+// Write writes a certificate and its private key to the file at path,
+// replacing the previous certificate.
+func Write(path string, certPEM, keyPEM []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return errors.Wrap(err, errWrite)
+	}
+	return errors.Wrap(os.WriteFile(path, append(append([]byte{}, certPEM...), keyPEM...), 0o600), errWrite)
+}
+
+// Load loads the certificate and private key of the file at path. Its leaf is
+// parsed.
+func Load(path string) (tls.Certificate, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // The path is derived from configuration.
+	if err != nil {
+		return tls.Certificate{}, errors.Wrap(err, errLoad)
+	}
+
+	var certPEM, keyPEM []byte
+	for rest := data; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		switch block.Type {
+		case "CERTIFICATE":
+			certPEM = append(certPEM, pem.EncodeToMemory(block)...)
+		case "EC PRIVATE KEY", "PRIVATE KEY":
+			keyPEM = pem.EncodeToMemory(block)
+		case "ENCRYPTED PRIVATE KEY":
+			return tls.Certificate{}, errors.Errorf(errEncryptedKey, path)
+		}
+	}
+
+	return keyPair(certPEM, keyPEM)
+}
+
+// keyPair parses a certificate and its private key, including its leaf.
+func keyPair(certPEM, keyPEM []byte) (tls.Certificate, error) {
+	c, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return tls.Certificate{}, errors.Wrap(err, errLoad)
+	}
+	if c.Leaf == nil {
+		if c.Leaf, err = x509.ParseCertificate(c.Certificate[0]); err != nil {
+			return tls.Certificate{}, errors.Wrap(err, errLoad)
+		}
+	}
+	return c, nil
+}
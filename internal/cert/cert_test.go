@@ -0,0 +1,272 @@
+package cert
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+// fakeChain publishes certificates by remembering their serial.
+type fakeChain struct {
+	published types.Certificates
+	publishes int
+}
+
+func (f *fakeChain) PublishClientCertificate(_ context.Context, path string) error {
+	c, err := Load(path)
+	if err != nil {
+		return err
+	}
+	f.publishes++
+	f.published.Certificates = append(f.published.Certificates, types.CertificateResponse{Serial: c.Leaf.SerialNumber.String()})
+	return nil
+}
+
+func (f *fakeChain) GetCertificates(_ context.Context, _ string) (types.Certificates, error) {
+	return f.published, nil
+}
+
+func TestEnsure(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		// setup prepares the home directory and the chain, and returns the
+		// time Ensure is called at.
+		setup         func(t *testing.T, home string, chain *fakeChain) time.Time
+		wantPublishes int
+	}{
+		{
+			name:          "no certificate",
+			setup:         func(*testing.T, string, *fakeChain) time.Time { return now },
+			wantPublishes: 1,
+		},
+		{
+			name: "published certificate",
+			setup: func(t *testing.T, home string, chain *fakeChain) time.Time {
+				writeCert(t, home, now)
+				if err := chain.PublishClientCertificate(context.Background(), Path(home, "akash1owner")); err != nil {
+					t.Fatal(err)
+				}
+				chain.publishes = 0
+				return now
+			},
+			wantPublishes: 0,
+		},
+		{
+			name: "unpublished certificate",
+			setup: func(t *testing.T, home string, _ *fakeChain) time.Time {
+				writeCert(t, home, now)
+				return now
+			},
+			wantPublishes: 1,
+		},
+		{
+			name: "expiring certificate",
+			setup: func(t *testing.T, home string, chain *fakeChain) time.Time {
+				writeCert(t, home, now)
+				if err := chain.PublishClientCertificate(context.Background(), Path(home, "akash1owner")); err != nil {
+					t.Fatal(err)
+				}
+				chain.publishes = 0
+				return now.Add(Validity - RenewBefore + time.Hour)
+			},
+			wantPublishes: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			chain := &fakeChain{}
+			at := tt.setup(t, home, chain)
+
+			c, err := Ensure(context.Background(), chain, home, "akash1owner", at)
+			if err != nil {
+				t.Fatalf("Ensure(...): %v", err)
+			}
+			if expiring(c, at) {
+				t.Errorf("Ensure(...): certificate expires at %s", c.Leaf.NotAfter)
+			}
+			if chain.publishes != tt.wantPublishes {
+				t.Errorf("Ensure(...): published %d certificates, want %d", chain.publishes, tt.wantPublishes)
+			}
+
+			// The certificate is cached once ensured.
+			if _, err := Ensure(context.Background(), chain, home, "akash1owner", at); err != nil {
+				t.Fatalf("Ensure(...): %v", err)
+			}
+			if chain.publishes != tt.wantPublishes {
+				t.Errorf("Ensure(...) again: published %d certificates, want %d", chain.publishes, tt.wantPublishes)
+			}
+		})
+	}
+}
+
+func writeCert(t *testing.T, home string, now time.Time) {
+	t.Helper()
+	certPEM, keyPEM, err := Generate("akash1owner", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(Path(home, "akash1owner"), certPEM, keyPEM); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// providerCert returns a self-signed DER encoded gateway certificate issued
+// to cn for host, valid from now, and its published form.
+func providerCert(t *testing.T, cn string, host string, now time.Time) ([]byte, types.CertificateResponse) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: cn},
+		Issuer:                pkix.Name{CommonName: cn},
+		DNSNames:              []string{host},
+		NotBefore:             now,
+		NotAfter:              now.Add(Validity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return der, types.CertificateResponse{
+		Serial:      serial.String(),
+		Certificate: types.Certificate{State: "valid", Cert: base64.StdEncoding.EncodeToString(certPEM)},
+	}
+}
+
+// certificatesFunc lists certificates by calling itself.
+type certificatesFunc func(owner string) (types.Certificates, error)
+
+func (f certificatesFunc) GetCertificates(_ context.Context, owner string) (types.Certificates, error) {
+	return f(owner)
+}
+
+func TestVerifyProvider(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	const provider = "akash1provider"
+	const host = "provider.example.com"
+
+	tests := map[string]struct {
+		// setup returns the certificates presented by the gateway and the
+		// certificates published on chain.
+		setup   func(t *testing.T) ([][]byte, certificatesFunc)
+		at      time.Time
+		wantErr bool
+	}{
+		"Published": {
+			setup: func(t *testing.T) ([][]byte, certificatesFunc) {
+				der, published := providerCert(t, provider, host, now)
+				return [][]byte{der}, func(owner string) (types.Certificates, error) {
+					if owner != provider {
+						return types.Certificates{}, nil
+					}
+					return types.Certificates{Certificates: []types.CertificateResponse{published}}, nil
+				}
+			},
+		},
+		"NotPublished": {
+			setup: func(t *testing.T) ([][]byte, certificatesFunc) {
+				der, _ := providerCert(t, provider, host, now)
+				_, other := providerCert(t, provider, host, now)
+				return [][]byte{der}, func(string) (types.Certificates, error) {
+					return types.Certificates{Certificates: []types.CertificateResponse{other}}, nil
+				}
+			},
+			wantErr: true,
+		},
+		"SameSerialOtherCertificate": {
+			setup: func(t *testing.T) ([][]byte, certificatesFunc) {
+				der, published := providerCert(t, provider, host, now)
+				_, other := providerCert(t, provider, host, now)
+				other.Serial = published.Serial
+				return [][]byte{der}, func(string) (types.Certificates, error) {
+					return types.Certificates{Certificates: []types.CertificateResponse{other}}, nil
+				}
+			},
+			wantErr: true,
+		},
+		"OtherProvider": {
+			setup: func(t *testing.T) ([][]byte, certificatesFunc) {
+				der, published := providerCert(t, "akash1other", host, now)
+				return [][]byte{der}, func(string) (types.Certificates, error) {
+					return types.Certificates{Certificates: []types.CertificateResponse{published}}, nil
+				}
+			},
+			wantErr: true,
+		},
+		"OtherHost": {
+			setup: func(t *testing.T) ([][]byte, certificatesFunc) {
+				der, published := providerCert(t, provider, "other.example.com", now)
+				return [][]byte{der}, func(string) (types.Certificates, error) {
+					return types.Certificates{Certificates: []types.CertificateResponse{published}}, nil
+				}
+			},
+			wantErr: true,
+		},
+		"Expired": {
+			setup: func(t *testing.T) ([][]byte, certificatesFunc) {
+				der, published := providerCert(t, provider, host, now)
+				return [][]byte{der}, func(string) (types.Certificates, error) {
+					return types.Certificates{Certificates: []types.CertificateResponse{published}}, nil
+				}
+			},
+			at:      now.Add(Validity + time.Hour),
+			wantErr: true,
+		},
+		"ChainUnavailable": {
+			setup: func(t *testing.T) ([][]byte, certificatesFunc) {
+				der, _ := providerCert(t, provider, host, now)
+				return [][]byte{der}, func(string) (types.Certificates, error) {
+					return types.Certificates{}, errors.New("connection refused")
+				}
+			},
+			wantErr: true,
+		},
+		"NoCertificate": {
+			setup: func(*testing.T) ([][]byte, certificatesFunc) {
+				return nil, func(string) (types.Certificates, error) { return types.Certificates{}, nil }
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			raw, chain := tt.setup(t)
+			at := tt.at
+			if at.IsZero() {
+				at = now.Add(time.Hour)
+			}
+			verify := VerifyProvider(context.Background(), chain, provider, host, func() time.Time { return at })
+			if err := verify(raw, nil); (err != nil) != tt.wantErr {
+				t.Errorf("VerifyProvider(...): want error %t, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
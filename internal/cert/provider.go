@@ -0,0 +1,105 @@
+package cert
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+// VerifiedTTL is how long a provider certificate found on chain is trusted
+// before it is looked up again, e.g. in case it was revoked.
+const VerifiedTTL = 10 * time.Minute
+
+// ErrUntrustedProvider is returned when a provider gateway presents a
+// certificate that is not the one its provider published on chain.
+var ErrUntrustedProvider = errors.New("provider gateway certificate is not published on chain")
+
+// A Certificates lists the valid certificates published by an owner.
+type Certificates interface {
+	GetCertificates(ctx context.Context, owner string) (types.Certificates, error)
+}
+
+// verified holds the expiry of the provider certificates recently found on
+// chain, by provider and fingerprint, shared by every client of the process.
+var verified sync.Map
+
+// VerifyProvider returns a tls.Config VerifyPeerCertificate function that
+// only accepts the certificate provider published on chain for its gateway
+// at host. Providers serve self-signed certificates, which cannot be
+// verified against the system roots, so the presented certificate must be
+// issued by and to the provider, valid for host at now, and found among the
+// valid certificates of the provider on chain. Anything else, including
+// failing to query the chain, is rejected.
+func VerifyProvider(ctx context.Context, chain Certificates, provider string, host string, now func() time.Time) func([][]byte, [][]*x509.Certificate) error {
+	return func(raw [][]byte, _ [][]*x509.Certificate) error {
+		if len(raw) != 1 {
+			return errors.Wrapf(ErrUntrustedProvider, "%s presented %d certificates, want 1", provider, len(raw))
+		}
+		c, err := x509.ParseCertificate(raw[0])
+		if err != nil {
+			return errors.Wrap(err, "cannot parse provider gateway certificate")
+		}
+		if c.Subject.CommonName != provider || c.Issuer.CommonName != provider {
+			return errors.Wrapf(ErrUntrustedProvider, "certificate of %s presented by the gateway of %s", c.Subject.CommonName, provider)
+		}
+
+		roots := x509.NewCertPool()
+		roots.AddCert(c)
+		if _, err := c.Verify(x509.VerifyOptions{
+			DNSName:     host,
+			Roots:       roots,
+			CurrentTime: now(),
+			KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		}); err != nil {
+			return errors.Wrapf(err, "invalid certificate of provider %s", provider)
+		}
+
+		key := provider + "/" + string(fingerprint(raw[0]))
+		if expiry, ok := verified.Load(key); ok && now().Before(expiry.(time.Time)) {
+			return nil
+		}
+		published, err := chain.GetCertificates(ctx, provider)
+		if err != nil {
+			return errors.Wrapf(err, "cannot get certificates of provider %s", provider)
+		}
+		if !isPublishedCert(raw[0], c.SerialNumber.String(), published) {
+			return errors.Wrapf(ErrUntrustedProvider, "serial %s of provider %s", c.SerialNumber, provider)
+		}
+		verified.Store(key, now().Add(VerifiedTTL))
+		return nil
+	}
+}
+
+// fingerprint returns the SHA-256 digest of a DER encoded certificate.
+func fingerprint(der []byte) []byte {
+	sum := sha256.Sum256(der)
+	return sum[:]
+}
+
+// isPublishedCert reports whether the DER encoded certificate with the given
+// serial is among the published certificates, which hold the base64 encoded
+// PEM of every certificate.
+func isPublishedCert(der []byte, serial string, published types.Certificates) bool {
+	for _, p := range published.Certificates {
+		if p.Serial != serial {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(p.Certificate.Cert)
+		if err != nil {
+			continue
+		}
+		if block, _ := pem.Decode(data); block != nil && bytes.Equal(block.Bytes, der) {
+			return true
+		}
+	}
+	return false
+}
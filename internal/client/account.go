@@ -0,0 +1,226 @@
+package client
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/overlock-network/provider-akash/internal/cert"
+	"github.com/overlock-network/provider-akash/internal/client/node"
+	"github.com/overlock-network/provider-akash/internal/client/tx"
+	"github.com/overlock-network/provider-akash/internal/client/types"
+	"github.com/overlock-network/provider-akash/internal/tracing"
+)
+
+// nodeStatusTimeout bounds the node connectivity probe.
+const nodeStatusTimeout = 10 * time.Second
+
+// ErrAccountUninitialized is returned instead of broadcasting a transaction
+// when the signing account does not exist on chain yet.
+var ErrAccountUninitialized = errors.New("account is not initialized on chain")
+
+// ErrAccountMismatch is returned instead of operating when the configured
+// account address is not the address of the configured key.
+var ErrAccountMismatch = errors.New("configured account address does not match the key")
+
+// IsAccountNotFound reports whether err is the chain reporting that an
+// account does not exist.
+func IsAccountNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrAccountUninitialized) {
+		return true
+	}
+
+	msg := err.Error()
+	return strings.Contains(msg, "account") && strings.Contains(msg, "not found")
+}
+
+// AccountExists reports whether the given address has an account on chain.
+// Freshly generated wallets only get one once they first receive funds.
+func (ak *AkashClient) AccountExists(ctx context.Context, address string) (bool, error) {
+	defer ak.begin(ctx, opQuery)()
+	if _, err := node.New(ak.Config.Node).Account(ak.ctx, address); err != nil {
+		if IsAccountNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// AccountSequence returns the sequence of the next transaction signed by the
+// given address, as of the last block.
+func (ak *AkashClient) AccountSequence(ctx context.Context, address string) (uint64, error) {
+	defer ak.begin(ctx, opQuery)()
+	account, err := node.New(ak.Config.Node).Account(ak.ctx, address)
+	if err != nil {
+		return 0, err
+	}
+	return account.Sequence, nil
+}
+
+// requireAccount returns ErrAccountUninitialized unless the configured account
+// exists on chain. Accounts are never removed, so a positive answer is cached.
+func (ak *AkashClient) requireAccount() error {
+	if ak.accountVerified {
+		return nil
+	}
+	if _, err := ak.AccountAddress(); err != nil {
+		return err
+	}
+
+	exists, err := ak.AccountExists(ak.ctx, ak.Config.AccountAddress)
+	if err != nil {
+		return errors.Wrap(err, "cannot query account")
+	}
+	if !exists {
+		return errors.Wrap(ErrAccountUninitialized, ak.Config.AccountAddress)
+	}
+
+	ak.accountVerified = true
+	ak.pooled.verifyAccount()
+	return nil
+}
+
+// AccountAddress returns the address of the configured key, which owns the
+// deployments and signs the transactions of the client. When an address is
+// configured as well, it must match the one derived from the key so that a
+// stale configuration never queries the wrong owner.
+func (ak *AkashClient) AccountAddress() (string, error) {
+	if ak.addressVerified {
+		return ak.Config.AccountAddress, nil
+	}
+
+	derived, err := ak.KeyAddress()
+	if err != nil {
+		return "", errors.Wrap(err, "cannot derive account address from key")
+	}
+	if ak.Config.AccountAddress != "" && ak.Config.AccountAddress != derived {
+		return "", errors.Wrapf(ErrAccountMismatch, "configured %s, credentials hold %s", ak.Config.AccountAddress, derived)
+	}
+
+	ak.Config.AccountAddress = derived
+	ak.addressVerified = true
+	ak.pooled.verifyAddress(derived)
+	return derived, nil
+}
+
+// KeyAddress returns the account address derived from the key held by the
+// credentials.
+func (ak *AkashClient) KeyAddress() (string, error) {
+	key, err := ak.signingKey()
+	if err != nil {
+		return "", err
+	}
+	return key.Address(), nil
+}
+
+// GetBalances queries the bank balances of the given address.
+func (ak *AkashClient) GetBalances(ctx context.Context, address string) (types.Balances, error) {
+	defer ak.begin(ctx, opQuery)()
+	q, err := ak.grpcQuery()
+	if err != nil {
+		return types.Balances{}, err
+	}
+	return q.Balances(ak.ctx, address)
+}
+
+// GetSpendableBalances queries the bank balances of the given address that
+// can be spent, i.e. are neither vesting nor otherwise locked.
+func (ak *AkashClient) GetSpendableBalances(ctx context.Context, address string) (types.Balances, error) {
+	defer ak.begin(ctx, opQuery)()
+	q, err := ak.grpcQuery()
+	if err != nil {
+		return types.Balances{}, err
+	}
+	return q.SpendableBalances(ak.ctx, address)
+}
+
+// GetDelegations queries the stake the given address delegated to validators.
+func (ak *AkashClient) GetDelegations(ctx context.Context, address string) ([]types.DelegationResponse, error) {
+	defer ak.begin(ctx, opQuery)()
+	q, err := ak.grpcQuery()
+	if err != nil {
+		return nil, err
+	}
+	return q.Delegations(ak.ctx, address)
+}
+
+// GetCertificates queries the valid client certificates published by owner.
+func (ak *AkashClient) GetCertificates(ctx context.Context, owner string) (types.Certificates, error) {
+	defer ak.begin(ctx, opQuery)()
+	q, err := ak.grpcQuery()
+	if err != nil {
+		return types.Certificates{}, err
+	}
+	return q.Certificates(ak.ctx, owner, "valid")
+}
+
+// PublishClientCertificate publishes the client certificate of the file at
+// path on chain, sending a MsgCreateCertificate.
+func (ak *AkashClient) PublishClientCertificate(ctx context.Context, path string) error {
+	defer ak.begin(ctx, opTransaction)()
+	if err := ak.requireAccount(); err != nil {
+		return err
+	}
+
+	c, err := cert.Load(path)
+	if err != nil {
+		return err
+	}
+	pubKey, err := x509.MarshalPKIXPublicKey(c.Leaf.PublicKey)
+	if err != nil {
+		return errors.Wrap(err, "cannot encode client certificate public key")
+	}
+	key, owner, err := ak.signer()
+	if err != nil {
+		return err
+	}
+
+	msg := tx.MsgCreateCertificate{
+		Owner:  owner,
+		Cert:   pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.Leaf.Raw}),
+		PubKey: pem.EncodeToMemory(&pem.Block{Type: "EC PUBLIC KEY", Bytes: pubKey}),
+	}
+	_, err = ak.signAndBroadcast(ak.ctx, key, owner, msg)
+	return err
+}
+
+// GetNodeStatus queries the status endpoint of the configured node.
+func (ak *AkashClient) GetNodeStatus(ctx context.Context) (types.NodeStatus, error) {
+	defer ak.begin(ctx, opQuery)()
+	ctx, cancel := context.WithTimeout(ak.ctx, nodeStatusTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(ak.Config.Node, "/")+"/status", nil)
+	if err != nil {
+		return types.NodeStatus{}, err
+	}
+
+	resp, err := tracing.DefaultClient.Do(req)
+	if err != nil {
+		return types.NodeStatus{}, err
+	}
+	defer resp.Body.Close() //nolint:errcheck // Nothing is lost when closing a body fails.
+
+	if resp.StatusCode != http.StatusOK {
+		return types.NodeStatus{}, fmt.Errorf("response status code %d", resp.StatusCode)
+	}
+
+	status := types.NodeStatus{}
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return types.NodeStatus{}, err
+	}
+
+	return status, nil
+}
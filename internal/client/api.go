@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gateway "github.com/overlock-network/provider-akash/internal/client/provider-gateway"
+	"github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+// A DeploymentAPI manages the deployments of an account.
+type DeploymentAPI interface {
+	Preflight(ctx context.Context, deposit types.Coin) error
+	CreateDeployment(ctx context.Context, manifestLocation string, deposit types.Coin, depositor string) (Seqs, error)
+	UpdateDeployment(ctx context.Context, dseq string, manifestLocation string) error
+	DepositDeployment(ctx context.Context, dseq string, owner string, amount types.Coin) error
+	DeleteDeployment(ctx context.Context, dseq string, owner string) error
+	GetDeployment(ctx context.Context, dseq string, owner string) (types.Deployment, error)
+	ReadDeployment(ctx context.Context, dseq string, owner string) (types.Deployment, error)
+	GetDeploymentAtHeight(ctx context.Context, dseq string, owner string, height int64) (types.Deployment, error)
+	ListDeployments(ctx context.Context, o ListOptions) ([]types.Deployment, uint64, error)
+}
+
+// A BidAPI queries the bids on the orders of deployments and leases them.
+type BidAPI interface {
+	QueryBids(ctx context.Context, seqs Seqs) (types.Bids, error)
+	LeaseFirstOpenBid(ctx context.Context, seqs Seqs, ranked types.Bids) (types.Bid, error)
+	RecordBidPrices(bids types.Bids, at time.Time) error
+	BlacklistProvider(provider string, reason string) error
+	BlacklistedProviders() (map[string]string, error)
+}
+
+// A LeaseAPI reads leases and talks to the providers serving them.
+type LeaseAPI interface {
+	GetLeases(ctx context.Context, dseq string, owner string) ([]types.Lease, error)
+	GetActiveLeases(ctx context.Context, dseq string, owner string) ([]types.Lease, error)
+	ReadActiveLeases(ctx context.Context, dseq string, owner string) ([]types.Lease, error)
+	GetLeasesAtHeight(ctx context.Context, dseq string, owner string, height int64) ([]types.Lease, error)
+	GetLeaseStatus(ctx context.Context, id types.LeaseId) (types.LeaseStatus, error)
+	StreamLeaseLogs(ctx context.Context, seqs Seqs, provider string, opts gateway.LogOptions, fn func(gateway.LogLine) error) error
+	MigrateHostname(ctx context.Context, hostname string, id types.LeaseId) error
+}
+
+// A ManifestAPI delivers manifests to providers and reads them back.
+type ManifestAPI interface {
+	GetManifest(ctx context.Context, dseq string, provider string) ([]byte, error)
+	SubmitManifest(ctx context.Context, dseq string, provider string, content []byte) error
+	SendManifestWithRetry(ctx context.Context, dseq string, provider string, manifestLocation string, policy ManifestDeliveryPolicy) (ManifestDeliveryResult, error)
+}
+
+// A CertificateAPI reads the certificates of accounts.
+type CertificateAPI interface {
+	GetCertificates(ctx context.Context, owner string) (types.Certificates, error)
+}
+
+// A ProviderAPI reads providers, their audits and versions.
+type ProviderAPI interface {
+	GetProvider(ctx context.Context, address string) (types.ProviderRecord, error)
+	GetProviders(ctx context.Context) ([]types.Provider, error)
+	GetProviderAuditors(ctx context.Context, provider string) ([]string, error)
+	GetProviderAudits(ctx context.Context, provider string) ([]types.AuditedAttributes, error)
+	ProviderVersion(ctx context.Context, provider string, hostURI string) (string, error)
+}
+
+// An AccountAPI reads the balances of accounts and manages the fee and
+// deposit grants of the signing account.
+type AccountAPI interface {
+	AccountAddress() (string, error)
+	GetSpendableBalances(ctx context.Context, address string) (types.Balances, error)
+	GetDelegations(ctx context.Context, address string) ([]types.DelegationResponse, error)
+	GetFeeGrant(ctx context.Context, granter string, grantee string) (types.FeeGrant, error)
+	GrantFeeAllowance(ctx context.Context, grantee string, allowance FeeAllowance) error
+	RevokeFeeAllowance(ctx context.Context, grantee string) error
+	GetDepositAuthorization(ctx context.Context, granter string, grantee string) (types.AuthzGrant, error)
+	GrantDepositAuthorization(ctx context.Context, grantee string, spendLimit types.Coin, expiration time.Time) error
+	RevokeDepositAuthorization(ctx context.Context, grantee string) error
+}
+
+// An AkashAPI is what controllers need of the Akash network. AkashClient
+// implements it on chain; package fake implements it with programmable
+// responses for tests.
+type AkashAPI interface {
+	DeploymentAPI
+	BidAPI
+	LeaseAPI
+	ManifestAPI
+	CertificateAPI
+	ProviderAPI
+	AccountAPI
+
+	// Configuration returns the configuration the client was prepared with.
+	Configuration() AkashProviderConfiguration
+	// SetTransactionOptions overrides the settings of subsequent
+	// transactions.
+	SetTransactionOptions(o TransactionOptions)
+	// ObservedViaFallback reports whether a read of the client was served by
+	// the indexer because the node was unavailable.
+	ObservedViaFallback() bool
+}
+
+var _ AkashAPI = &AkashClient{}
+
+// Configuration returns the configuration the client was prepared with.
+func (ak *AkashClient) Configuration() AkashProviderConfiguration {
+	return ak.Config
+}
+
+// NewAPIFromManagedResource is NewFromManagedResource returning an AkashAPI,
+// the constructor controllers use unless they are given another.
+func NewAPIFromManagedResource(ctx context.Context, kubeClient client.Client, usage resource.Tracker, mg resource.Managed, pcInfo ProviderConfigInfo) (AkashAPI, error) {
+	ak, err := NewFromManagedResource(ctx, kubeClient, usage, mg, pcInfo)
+	if err != nil {
+		// A nil *AkashClient would be a non-nil AkashAPI.
+		return nil, err
+	}
+	return ak, nil
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/overlock-network/provider-akash/internal/client/tx"
+	"github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+// IsDepositAuthorizationNotFound reports whether err is the chain reporting
+// that a deposit authorization does not exist.
+func IsDepositAuthorizationNotFound(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "not found")
+}
+
+// GetDepositAuthorization queries the authorization granter granted grantee
+// to deposit into deployments from its account.
+func (ak *AkashClient) GetDepositAuthorization(ctx context.Context, granter string, grantee string) (types.AuthzGrant, error) {
+	defer ak.begin(ctx, opQuery)()
+	q, err := ak.grpcQuery()
+	if err != nil {
+		return types.AuthzGrant{}, err
+	}
+	grants, err := q.AuthzGrants(ak.ctx, granter, grantee)
+	if err != nil {
+		return types.AuthzGrant{}, err
+	}
+
+	for _, g := range grants {
+		if g.Authorization.IsDepositDeployment() {
+			return g, nil
+		}
+	}
+	return types.AuthzGrant{}, errors.Errorf("deposit authorization of %s to %s not found", granter, grantee)
+}
+
+// GrantDepositAuthorization authorizes grantee to deposit up to spendLimit
+// into deployments from the configured account until expiration, sending a
+// MsgGrant. An existing authorization is replaced.
+func (ak *AkashClient) GrantDepositAuthorization(ctx context.Context, grantee string, spendLimit types.Coin, expiration time.Time) error {
+	defer ak.begin(ctx, opTransaction)()
+	if err := ak.requireAccount(); err != nil {
+		return err
+	}
+	if err := ak.requireSpendConfirmed(); err != nil {
+		return err
+	}
+
+	key, granter, err := ak.signer()
+	if err != nil {
+		return err
+	}
+	msg := tx.MsgGrant{
+		Granter:    granter,
+		Grantee:    grantee,
+		SpendLimit: tx.Coin{Denom: spendLimit.Denom, Amount: spendLimit.Amount},
+		Expiration: expiration,
+	}
+	_, err = ak.signAndBroadcast(ak.ctx, key, granter, msg)
+	return err
+}
+
+// RevokeDepositAuthorization revokes the authorization of grantee to deposit
+// into deployments from the configured account, sending a MsgRevoke.
+func (ak *AkashClient) RevokeDepositAuthorization(ctx context.Context, grantee string) error {
+	defer ak.begin(ctx, opTransaction)()
+	if err := ak.requireAccount(); err != nil {
+		return err
+	}
+
+	key, granter, err := ak.signer()
+	if err != nil {
+		return err
+	}
+	_, err = ak.signAndBroadcast(ak.ctx, key, granter, tx.MsgRevoke{Granter: granter, Grantee: grantee, MsgTypeURL: tx.TypeURLMsgDepositDeployment})
+	return err
+}
@@ -1,45 +1,431 @@
 package client
 
 import (
-	"fmt"
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/overlock-network/provider-akash/internal/client/cli"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	resourcev1alpha1 "github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	grpcquery "github.com/overlock-network/provider-akash/internal/client/grpc-query"
+	"github.com/overlock-network/provider-akash/internal/client/market"
+	providersapi "github.com/overlock-network/provider-akash/internal/client/providers-api"
 	"github.com/overlock-network/provider-akash/internal/client/types"
+	"github.com/overlock-network/provider-akash/internal/denom"
 )
 
-func (ak *AkashClient) GetBids(seqs Seqs, timeout time.Duration) (types.Bids, error) {
-	bids := types.Bids{}
-	for timeout > 0 && len(bids) == 0 {
-		startTime := time.Now()
-		currentBids, err := queryBidList(ak, seqs)
+// bidPollInterval is how often bids are queried while waiting for them.
+const bidPollInterval = 3 * time.Second
+
+// ErrNoOpenBids is returned when every ranked bid was withdrawn or failed to
+// be leased.
+var ErrNoOpenBids = errors.New("no open bids left to lease")
+
+// ErrNotDeploymentOwner is returned instead of leasing bids on a deployment
+// whose owner is not the signing account.
+var ErrNotDeploymentOwner = errors.New("deployment is not owned by the signing account")
+
+// LeaseFirstOpenBid creates a lease with the first of the ranked bids that is
+// still open, and returns that bid. Bids are queried again before every
+// attempt, so a bid its provider closed since it was ranked is skipped right
+// away, and a lease refused because the bid was closed meanwhile falls back
+// to the next bid, instead of failing until the next reconcile.
+func (ak *AkashClient) LeaseFirstOpenBid(ctx context.Context, seqs Seqs, ranked types.Bids) (types.Bid, error) {
+	defer ak.begin(ctx, opTransaction)()
+	remaining := ranked
+	for len(remaining) > 0 {
+		current, err := ak.queryBids(ak.ctx, seqs)
 		if err != nil {
-			fmt.Print(ak.ctx, "Failed to query bid list")
+			return types.Bid{}, err
+		}
+		remaining = openBids(remaining, current)
+		if len(remaining) == 0 {
+			break
+		}
+
+		bid := remaining[0]
+		_, err = ak.CreateLease(ak.ctx, seqs, bid.Id.Provider)
+		if err == nil {
+			return bid, nil
+		}
+		if !isBidClosed(err) {
+			return types.Bid{}, err
+		}
+		ak.log().Info("Bid was withdrawn, trying the next bid", "dseq", seqs.Dseq, "provider", bid.Id.Provider)
+		remaining = remaining[1:]
+	}
+
+	return types.Bid{}, ErrNoOpenBids
+}
+
+// openBids returns the ranked bids, in order, that are still open among the
+// current bids on the order.
+func openBids(ranked, current types.Bids) types.Bids {
+	open := make(map[string]bool, len(current))
+	for _, b := range current {
+		// Older chain versions do not report the state of bids.
+		open[b.Id.Provider] = b.State == "" || resourcev1alpha1.BidStateFromChain(b.State) == resourcev1alpha1.BidStateOpen
+	}
+
+	bids := make(types.Bids, 0, len(ranked))
+	for _, b := range ranked {
+		if open[b.Id.Provider] {
+			bids = append(bids, b)
+		}
+	}
+	return bids
+}
+
+// isBidClosed reports whether a lease was refused because its bid is no
+// longer open, e.g. because the provider withdrew it.
+func isBidClosed(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "bid not open") || strings.Contains(msg, "unknown bid")
+}
+
+// BidCollectionPolicy controls how many bids CollectBids waits for before
+// they are scored.
+type BidCollectionPolicy struct {
+	MinBids int
+	Window  time.Duration
+}
+
+// NewBidCollectionPolicy converts the bid collection settings of a Deployment
+// into a BidCollectionPolicy, using constants for defaults.
+func NewBidCollectionPolicy(minBids *int, window *metav1.Duration) BidCollectionPolicy {
+	policy := BidCollectionPolicy{
+		MinBids: DefaultMinBids,
+		Window:  DefaultBidCollectionWindow,
+	}
+	if minBids != nil {
+		policy.MinBids = *minBids
+	}
+	if window != nil {
+		policy.Window = window.Duration
+	}
+	return policy
+}
+
+// Collected reports whether the received bids are enough to score them after
+// collecting bids for elapsed: either MinBids arrived, or the window elapsed
+// with at least one bid.
+func (p BidCollectionPolicy) Collected(received int, elapsed time.Duration) bool {
+	return received > 0 && (received >= p.MinBids || elapsed >= p.Window)
+}
+
+// MaxPricePolicy bounds the price per block of the bids that may be leased.
+type MaxPricePolicy struct {
+	Amount float32
+	Denom  string
+	// Window is how long bids are awaited before no bid within the maximum
+	// price is reported.
+	Window time.Duration
+}
+
+// NewMaxPricePolicy converts the maximum price of a Deployment into a
+// MaxPricePolicy, using constants for defaults. No policy is returned when
+// the Deployment sets no maximum price.
+func NewMaxPricePolicy(max *resourcev1alpha1.MaxPrice) (*MaxPricePolicy, error) {
+	if max == nil {
+		return nil, nil
+	}
+	// Bid prices are decoded with the same precision, so a bid asking
+	// exactly the maximum price compares equal to it.
+	amount, err := strconv.ParseFloat(max.Amount, 32)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid maximum price %q", max.Amount)
+	}
+	policy := &MaxPricePolicy{
+		Amount: float32(amount),
+		Denom:  DefaultMaxPriceDenom,
+		Window: DefaultMaxPriceWindow,
+	}
+	if max.Denom != "" {
+		policy.Denom = max.Denom
+	}
+	if max.Window != nil {
+		policy.Window = max.Window.Duration
+	}
+	return policy, nil
+}
+
+// Exceeds reports whether price is above the maximum price, or in another
+// denomination. No price exceeds a nil policy.
+func (p *MaxPricePolicy) Exceeds(price types.BidPrice) bool {
+	return p != nil && (price.Denom != p.Denom || price.Amount > p.Amount)
+}
+
+// String returns the maximum price with its denomination, e.g. 1.5uakt.
+func (p *MaxPricePolicy) String() string {
+	return FormatBidPrice(types.BidPrice{Amount: p.Amount}) + p.Denom
+}
 
+// BidSelectionPolicy controls the order RankBids ranks bids in.
+type BidSelectionPolicy struct {
+	Strategy resourcev1alpha1.BidSelectionStrategy
+	// Weights of the criteria of the weighted strategy.
+	PriceWeight      int
+	AuditedWeight    int
+	UptimeWeight     int
+	AttributeWeights map[string]int
+}
+
+// NewBidSelectionPolicy converts the bid selection settings of a Deployment
+// into a BidSelectionPolicy, using constants for defaults.
+func NewBidSelectionPolicy(s *resourcev1alpha1.BidSelection) BidSelectionPolicy {
+	policy := BidSelectionPolicy{
+		Strategy:    DefaultBidSelectionStrategy,
+		PriceWeight: DefaultBidPriceWeight,
+	}
+	if s == nil {
+		return policy
+	}
+
+	if s.Strategy != "" {
+		policy.Strategy = s.Strategy
+	}
+	if w := s.Weights; w != nil {
+		if w.Price != nil {
+			policy.PriceWeight = *w.Price
+		}
+		if w.Audited != nil {
+			policy.AuditedWeight = *w.Audited
+		}
+		if w.Uptime != nil {
+			policy.UptimeWeight = *w.Uptime
+		}
+		policy.AttributeWeights = w.Attributes
+	}
+
+	return policy
+}
+
+// RankBids returns a copy of bids in the order policy prefers them, using the
+// given providers to score them and r to shuffle them.
+func RankBids(bids types.Bids, providers []types.Provider, policy BidSelectionPolicy, r *rand.Rand) types.Bids {
+	switch policy.Strategy {
+	case resourcev1alpha1.BidSelectionRandom:
+		shuffled := make(types.Bids, len(bids))
+		copy(shuffled, bids)
+		r.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		return shuffled
+	case resourcev1alpha1.BidSelectionWeighted:
+		byAddress := IndexProviders(providers)
+		scores := make(map[string]float64, len(bids))
+		for _, b := range bids {
+			scores[b.Id.Provider] = policy.score(b, bids, byAddress[b.Id.Provider])
+		}
+		// Ties go to the cheapest bid.
+		sorted := bids.SortByPrice()
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return scores[sorted[i].Id.Provider] > scores[sorted[j].Id.Provider]
+		})
+		return sorted
+	default:
+		return bids.SortByPrice()
+	}
+}
+
+// score returns the weighted score of bid among bids, placed by provider.
+func (p BidSelectionPolicy) score(bid types.Bid, bids types.Bids, provider types.Provider) float64 {
+	lowest, highest := bid.Price.Amount, bid.Price.Amount
+	for _, b := range bids {
+		lowest = min(lowest, b.Price.Amount)
+		highest = max(highest, b.Price.Amount)
+	}
+	price := 1.0
+	if highest > lowest {
+		price = float64(highest-bid.Price.Amount) / float64(highest-lowest)
+	}
+
+	score := float64(p.PriceWeight) * price
+	if provider.Audited {
+		score += float64(p.AuditedWeight)
+	}
+	// The providers API reports the uptime as a percentage.
+	score += float64(p.UptimeWeight) * math.Min(math.Max(float64(provider.Uptime)/100, 0), 1)
+	for attribute, weight := range p.AttributeWeights {
+		key, value, _ := strings.Cut(attribute, "=")
+		if v, ok := provider.Attributes[key]; ok && v == value {
+			score += float64(weight)
+		}
+	}
+	return score
+}
+
+// GetBids waits up to timeout for bids on the order of seqs and returns them,
+// polling every bidPollInterval. No bids are returned when none arrived in
+// time.
+func (ak *AkashClient) GetBids(ctx context.Context, seqs Seqs, timeout time.Duration) (types.Bids, error) {
+	defer ak.begin(ctx, opUnbounded)()
+	return ak.CollectBids(ak.ctx, seqs, BidCollectionPolicy{MinBids: 1}, timeout)
+}
+
+// CollectBids waits up to timeout for bids on the order of seqs until policy
+// considers them collected, polling every bidPollInterval. The bids received
+// when the timeout elapses are returned, if any.
+func (ak *AkashClient) CollectBids(ctx context.Context, seqs Seqs, policy BidCollectionPolicy, timeout time.Duration) (types.Bids, error) {
+	defer ak.begin(ctx, opUnbounded)()
+	ctx, cancel := context.WithTimeout(ak.ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	received := types.Bids{}
+	for {
+		bids, err := ak.queryBids(ctx, seqs)
+		switch {
+		case ctx.Err() != nil:
+			return received, nil
+		case err != nil:
 			return nil, err
+		case policy.Collected(len(bids), time.Since(start)):
+			ak.log().Debug("Collected bids", "dseq", seqs.Dseq, "bids", len(bids))
+			return bids, nil
+		}
+		received = bids
+
+		select {
+		case <-ctx.Done():
+			return received, nil
+		case <-time.After(bidPollInterval):
 		}
-		fmt.Printf("Received %d bids", len(bids))
-		bids = currentBids
-		timeout -= time.Since(startTime)
 	}
+}
 
-	return bids, nil
+// QueryBids queries the bids currently on the order of seqs.
+func (ak *AkashClient) QueryBids(ctx context.Context, seqs Seqs) (types.Bids, error) {
+	defer ak.begin(ctx, opQuery)()
+	return ak.queryBids(ak.ctx, seqs)
 }
 
-func queryBidList(ak *AkashClient, seqs Seqs) (types.Bids, error) {
-	cmd := cli.AkashCli(ak).Query().Market().Bid().List().
-		SetDseq(seqs.Dseq).SetGseq(seqs.Gseq).SetOseq(seqs.Oseq).
-		SetOwner(ak.Config.AccountAddress).SetChainId(ak.Config.ChainId).SetNode(ak.Config.Node).OutputJson()
+// seqsOwner returns the owner of the deployment of seqs.
+func (ak *AkashClient) seqsOwner(seqs Seqs) (string, error) {
+	if seqs.Owner != "" {
+		return seqs.Owner, nil
+	}
+	return ak.AccountAddress()
+}
 
-	bidsSliceWrapper := types.BidsSliceWrapper{}
-	if err := cmd.DecodeJson(&bidsSliceWrapper); err != nil {
+// queryBids queries the bids on the order of seqs through the gRPC endpoint,
+// or through the market query client of the REST API when only that is
+// configured.
+func (ak *AkashClient) queryBids(ctx context.Context, seqs Seqs) (types.Bids, error) {
+	owner, err := ak.seqsOwner(seqs)
+	if err != nil {
+		return nil, err
+	}
+	if ak.Config.GRPCEndpoint == "" && ak.Config.RestApi != "" {
+		return market.New(ak.Config.RestApi).Bids(ctx, market.BidFilters{
+			Owner: owner,
+			Dseq:  seqs.Dseq,
+			Gseq:  seqs.Gseq,
+			Oseq:  seqs.Oseq,
+		})
+	}
+	q, err := ak.grpcQuery()
+	if err != nil {
 		return nil, err
 	}
+	return q.Bids(ctx, grpcquery.Filters{Owner: owner, Dseq: seqs.Dseq, Gseq: seqs.Gseq, Oseq: seqs.Oseq})
+}
+
+// GetBidReport waits for bids on the deployment and compares the cheapest
+// size of them using the provider details known to the providers API.
+func (ak *AkashClient) GetBidReport(ctx context.Context, seqs Seqs, timeout time.Duration, size int) ([]resourcev1alpha1.BidReportEntry, error) {
+	defer ak.begin(ctx, opUnbounded)()
+	bids, err := ak.GetBids(ak.ctx, seqs, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ak.RecordBidPrices(bids, time.Now()); err != nil {
+		return nil, errors.Wrap(err, "cannot record bid prices")
+	}
+
+	providers, err := providersapi.New(ak.Config.ProvidersApi).GetAllProviders(ak.ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get providers")
+	}
+
+	return BuildBidReport(bids, providers, size), nil
+}
+
+// BuildBidReport returns the cheapest size bids, enriched with the region and
+// audit status of the bidding providers, ordered by ascending price.
+func BuildBidReport(bids types.Bids, providers []types.Provider, size int) []resourcev1alpha1.BidReportEntry {
+	byAddress := IndexProviders(providers)
+
+	sorted := bids.SortByPrice()
+	if size >= 0 && len(sorted) > size {
+		sorted = sorted[:size]
+	}
 
-	bids := types.Bids{}
-	for _, bidWrapper := range bidsSliceWrapper.BidWrappers {
-		bids = append(bids, bidWrapper.Bid)
+	report := make([]resourcev1alpha1.BidReportEntry, 0, len(sorted))
+	for _, bid := range sorted {
+		p := byAddress[bid.Id.Provider]
+		price := FormatBidPrice(bid.Price)
+		displayPrice, displayDenom, _ := denom.ToDisplay(price, bid.Price.Denom)
+		report = append(report, resourcev1alpha1.BidReportEntry{
+			Provider:     bid.Id.Provider,
+			Price:        price,
+			Denom:        bid.Price.Denom,
+			DisplayPrice: displayPrice,
+			DisplayDenom: displayDenom,
+			Region:       p.Region(),
+			Audited:      p.Audited,
+		})
 	}
 
-	return bids, nil
+	return report
+}
+
+// NewBidRecord records the decision made on a bid together with a snapshot of
+// what was known about its provider at that time.
+func NewBidRecord(bid types.Bid, provider types.Provider, decision resourcev1alpha1.BidDecision, reason string, at metav1.Time) resourcev1alpha1.BidRecord {
+	var attributes map[string]string
+	if len(provider.Attributes) > 0 {
+		attributes = make(map[string]string, len(provider.Attributes))
+		for k, v := range provider.Attributes {
+			attributes[k] = v
+		}
+	}
+
+	price := FormatBidPrice(bid.Price)
+	displayPrice, displayDenom, _ := denom.ToDisplay(price, bid.Price.Denom)
+	return resourcev1alpha1.BidRecord{
+		Provider:     bid.Id.Provider,
+		Price:        price,
+		Denom:        bid.Price.Denom,
+		DisplayPrice: displayPrice,
+		DisplayDenom: displayDenom,
+		Decision:     decision,
+		Reason:       reason,
+		DecisionTime: at,
+		ProviderSnapshot: resourcev1alpha1.ProviderSnapshot{
+			HostURI:    provider.HostURI,
+			Audited:    provider.Audited,
+			Attributes: attributes,
+		},
+	}
+}
+
+// IndexProviders indexes the given providers by address.
+func IndexProviders(providers []types.Provider) map[string]types.Provider {
+	byAddress := make(map[string]types.Provider, len(providers))
+	for _, p := range providers {
+		byAddress[p.Address] = p
+	}
+
+	return byAddress
+}
+
+// FormatBidPrice formats the amount per block of a price.
+func FormatBidPrice(price types.BidPrice) string {
+	return strconv.FormatFloat(float64(price.Amount), 'f', -1, 32)
 }
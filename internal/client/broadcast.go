@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/overlock-network/provider-akash/internal/client/node"
+	"github.com/overlock-network/provider-akash/internal/client/retry"
+	"github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+const errTxNotConfirmed = "transaction %s was not included in a block within %s"
+
+// Broadcast modes of transactions.
+const (
+	BroadcastModeSync  = "sync"
+	BroadcastModeAsync = "async"
+	BroadcastModeBlock = "block"
+)
+
+// maxSequenceRetries bounds how often a transaction rejected because of an
+// account sequence mismatch is signed again and rebroadcast.
+const maxSequenceRetries = 3
+
+// txPollInterval is how often a broadcast transaction is queried while
+// waiting for it to be included in a block.
+const txPollInterval = 2 * time.Second
+
+// ErrTxNotConfirmed is returned when a broadcast transaction was not included
+// in a block before the confirmation timeout. It may still be included later.
+var ErrTxNotConfirmed = errors.New("transaction not confirmed")
+
+// sequenceMismatch matches the sequence expected by the chain when it rejects
+// a transaction signed with another one, e.g. "account sequence mismatch,
+// expected 42, got 41: incorrect account sequence".
+var sequenceMismatch = regexp.MustCompile(`account sequence mismatch, expected (\d+)`)
+
+// nodeSequence returns the account sequence the chain expected when it
+// rejected a transaction with err, or else the current sequence of the
+// account of address.
+func nodeSequence(ctx context.Context, n *node.Client, address string, err error) (uint64, error) {
+	if m := sequenceMismatch.FindStringSubmatch(err.Error()); m != nil {
+		return strconv.ParseUint(m[1], 10, 64)
+	}
+	account, err := n.Account(ctx, address)
+	return account.Sequence, err
+}
+
+// isSequenceMismatch reports whether a transaction was rejected because its
+// account sequence did not match the one expected by the chain.
+func isSequenceMismatch(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "account sequence mismatch") || strings.Contains(msg, "incorrect account sequence")
+}
+
+// broadcastSigned broadcasts the signed transaction raw through the node in
+// the configured broadcast mode and returns it once it was included in a
+// block. Under sync and async mode a successful broadcast only means the
+// transaction entered the mempool, so it is queried by hash until it is
+// included or the confirmation timeout elapses. An error is returned when it
+// failed, with its code and log.
+func (ak *AkashClient) broadcastSigned(ctx context.Context, n *node.Client, raw []byte) (types.Transaction, error) {
+	mode := ak.Config.BroadcastMode
+	if mode == "" {
+		mode = DefaultBroadcastMode
+	}
+	// Transactions the node did not accept, e.g. because its mempool is
+	// full, are broadcast again.
+	var res node.Result
+	transaction, submitted := types.Transaction{}, false
+	err := retry.Do(ctx, retry.Broadcast, func() error {
+		var err error
+		transaction, submitted = types.Transaction{}, false
+		if res, err = n.BroadcastTx(ctx, mode, raw); err != nil {
+			return errors.Wrap(err, "cannot broadcast transaction")
+		}
+		transaction, submitted = nodeTransaction(res), true
+		return txResult(transaction)
+	})
+	if !submitted {
+		return types.Transaction{}, err
+	}
+	if err != nil || mode == BroadcastModeBlock {
+		return transaction, err
+	}
+
+	timeout := ak.Config.TxConfirmationTimeout
+	if timeout <= 0 {
+		timeout = DefaultTxConfirmationTimeout
+	}
+	committed, err := waitForTx(ctx, n, res.Hash, timeout)
+	if err != nil {
+		return transaction, err
+	}
+	return committed, txResult(committed)
+}
+
+// waitForTx queries the transaction with the given hash from the node every
+// txPollInterval until it was included in a block, or timeout elapses.
+func waitForTx(ctx context.Context, n *node.Client, hash string, timeout time.Duration) (types.Transaction, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		res, err := n.Tx(ctx, hash)
+		switch {
+		case err == nil:
+			return nodeTransaction(res), nil
+		case !errors.Is(err, node.ErrTxNotFound):
+			return types.Transaction{}, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return types.Transaction{}, errors.Wrapf(ErrTxNotConfirmed, errTxNotConfirmed, hash, timeout)
+		case <-time.After(txPollInterval):
+		}
+	}
+}
+
+// nodeTransaction returns the outcome of a transaction reported by the node.
+func nodeTransaction(res node.Result) types.Transaction {
+	transaction := types.Transaction{TxHash: res.Hash, Code: res.Code, RawLog: res.Log}
+	if res.Height > 0 {
+		transaction.Height = strconv.FormatInt(res.Height, 10)
+	}
+	for _, e := range res.Events {
+		event := types.TransactionEvent{Type: e.Type}
+		for _, a := range e.Attributes {
+			event.Attributes = append(event.Attributes, types.TransactionEventAttribute{Key: a.Key, Value: a.Value})
+		}
+		transaction.Events = append(transaction.Events, event)
+	}
+	return transaction
+}
+
+// txResult returns an error carrying the code and log of transaction unless
+// it succeeded.
+func txResult(transaction types.Transaction) error {
+	if transaction.Code == 0 {
+		return nil
+	}
+	return errors.Errorf(errTransactionFailed, transaction.TxHash, transaction.Code, transaction.RawLog)
+}
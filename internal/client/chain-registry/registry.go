@@ -0,0 +1,153 @@
+package chain_registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/overlock-network/provider-akash/internal/tracing"
+)
+
+// DefaultHost is the upstream Cosmos chain registry.
+const DefaultHost = "https://raw.githubusercontent.com/cosmos/chain-registry/master"
+
+// probeTimeout bounds every health probe made against a public endpoint.
+const probeTimeout = 5 * time.Second
+
+// chainPaths maps the Akash networks to their directory in the chain registry.
+var chainPaths = map[string]string{
+	"mainnet": "akash",
+	"testnet": "testnets/akashtestnet",
+	"sandbox": "testnets/akashsandbox",
+}
+
+type Endpoint struct {
+	Address  string `json:"address"`
+	Provider string `json:"provider"`
+}
+
+type Apis struct {
+	Rpc  []Endpoint `json:"rpc"`
+	Grpc []Endpoint `json:"grpc"`
+}
+
+type Chain struct {
+	ChainId string `json:"chain_id"`
+	Apis    Apis   `json:"apis"`
+}
+
+// Endpoints are the healthy endpoints selected for a network.
+type Endpoints struct {
+	Rpc  string
+	Grpc string
+}
+
+type rpcStatus struct {
+	Result struct {
+		NodeInfo struct {
+			Network string `json:"network"`
+		} `json:"node_info"`
+		SyncInfo struct {
+			CatchingUp bool `json:"catching_up"`
+		} `json:"sync_info"`
+	} `json:"result"`
+}
+
+type RegistryClient struct {
+	host string
+	http *http.Client
+}
+
+// New creates a new RegistryClient based on the given host. The upstream
+// chain registry is used when host is empty.
+func New(host string) *RegistryClient {
+	if host == "" {
+		host = DefaultHost
+	}
+
+	return &RegistryClient{
+		host: strings.TrimSuffix(host, "/"),
+		http: &http.Client{Timeout: probeTimeout, Transport: tracing.NewTransport(nil)},
+	}
+}
+
+// GetChain gets the chain registry entry of the given Akash network.
+func (c *RegistryClient) GetChain(ctx context.Context, network string) (Chain, error) {
+	path, ok := chainPaths[network]
+	if !ok {
+		return Chain{}, fmt.Errorf("network %q is not in the chain registry", network)
+	}
+
+	chain := Chain{}
+	if err := c.getJson(ctx, c.host+"/"+path+"/chain.json", &chain); err != nil {
+		return Chain{}, err
+	}
+
+	return chain, nil
+}
+
+// DiscoverEndpoints returns the first RPC endpoint of the given network that
+// reports the expected chain ID and is not catching up, together with the
+// first gRPC endpoint accepting connections.
+func (c *RegistryClient) DiscoverEndpoints(ctx context.Context, network string) (Endpoints, error) {
+	chain, err := c.GetChain(ctx, network)
+	if err != nil {
+		return Endpoints{}, err
+	}
+
+	endpoints := Endpoints{}
+	for _, rpc := range chain.Apis.Rpc {
+		if c.rpcHealthy(ctx, rpc.Address, chain.ChainId) {
+			endpoints.Rpc = rpc.Address
+			break
+		}
+	}
+	if endpoints.Rpc == "" {
+		return Endpoints{}, fmt.Errorf("no healthy RPC endpoint found for chain %s", chain.ChainId)
+	}
+
+	dialer := net.Dialer{Timeout: probeTimeout}
+	for _, grpc := range chain.Apis.Grpc {
+		conn, err := dialer.DialContext(ctx, "tcp", grpc.Address)
+		if err != nil {
+			continue
+		}
+		_ = conn.Close()
+		endpoints.Grpc = grpc.Address
+		break
+	}
+
+	return endpoints, nil
+}
+
+func (c *RegistryClient) rpcHealthy(ctx context.Context, address string, chainId string) bool {
+	status := rpcStatus{}
+	if err := c.getJson(ctx, strings.TrimSuffix(address, "/")+"/status", &status); err != nil {
+		return false
+	}
+
+	return status.Result.NodeInfo.Network == chainId && !status.Result.SyncInfo.CatchingUp
+}
+
+func (c *RegistryClient) getJson(ctx context.Context, addr string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, addr, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck // Nothing is lost when closing a body fails.
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("response status code %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
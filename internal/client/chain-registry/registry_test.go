@@ -0,0 +1,57 @@
+package chain_registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiscoverEndpoints(t *testing.T) {
+	status := func(network string, catchingUp bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			s := rpcStatus{}
+			s.Result.NodeInfo.Network = network
+			s.Result.SyncInfo.CatchingUp = catchingUp
+			_ = json.NewEncoder(w).Encode(s)
+		}
+	}
+
+	wrongChain := httptest.NewServer(status("other-1", false))
+	defer wrongChain.Close()
+	syncing := httptest.NewServer(status("akashnet-2", true))
+	defer syncing.Close()
+	healthy := httptest.NewServer(status("akashnet-2", false))
+	defer healthy.Close()
+
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/akash/chain.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Chain{
+			ChainId: "akashnet-2",
+			Apis: Apis{Rpc: []Endpoint{
+				{Address: wrongChain.URL},
+				{Address: syncing.URL},
+				{Address: healthy.URL},
+			}},
+		})
+	}))
+	defer registry.Close()
+
+	got, err := New(registry.URL).DiscoverEndpoints(context.Background(), "mainnet")
+	if err != nil {
+		t.Fatalf("DiscoverEndpoints() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(Endpoints{Rpc: healthy.URL}, got); diff != "" {
+		t.Errorf("DiscoverEndpoints() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := New(registry.URL).DiscoverEndpoints(context.Background(), "unknown"); err == nil {
+		t.Errorf("DiscoverEndpoints() expected error for unknown network")
+	}
+}
@@ -0,0 +1,251 @@
+// Package chaintest serves a mock Akash chain for hermetic client and
+// controller tests. A Server answers the gRPC queries of deployments, bids,
+// leases, providers, audits, balances, delegations, certificates, grants and
+// proposals from state the test programs, and the Tendermint RPC of a node:
+// its status, the ABCI queries of accounts, deployments and simulations that
+// sign transactions, the broadcast and lookup of transactions with canned
+// results, and WebSocket subscriptions to the events of broadcast
+// transactions.
+package chaintest
+
+import (
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+// DefaultChainID is the chain ID the node reports unless SetChainID is called.
+const DefaultChainID = "akashnet-test"
+
+// A TxResult is the outcome of a broadcast transaction.
+type TxResult struct {
+	// Code is zero when the transaction succeeded.
+	Code uint32
+	// Log explains why the transaction failed.
+	Log string
+	// Events are emitted by the transaction once it is included in a block.
+	Events []types.TransactionEvent
+}
+
+// A Tx is a transaction broadcast to the Server.
+type Tx struct {
+	// Hash of the transaction, upper case hex as reported by the node.
+	Hash string
+	// Height of the block including the transaction.
+	Height int64
+	// Bytes of the transaction as broadcast.
+	Bytes []byte
+	// Result of the transaction.
+	Result TxResult
+}
+
+// A bid is a bid on the order of a deployment, which types.Bid only
+// identifies by provider.
+type bid struct {
+	owner, dseq, gseq, oseq string
+	bid                     types.Bid
+}
+
+// A Server is a mock Akash chain. Its state is empty until the test adds
+// deployments, bids, leases, providers, audits, balances, delegations,
+// certificates, grants and proposals. Queries are
+// answered from the current state, whatever the block height they ask for.
+type Server struct {
+	grpc *grpc.Server
+	lis  net.Listener
+	rpc  *httptest.Server
+
+	mu          sync.Mutex
+	chainID     string
+	height      int64
+	deployments []types.Deployment
+	bids        []bid
+	leases      []types.Lease
+	providers   map[string]types.ProviderRecord
+	audits      map[string][]types.AuditedAttributes
+	balances    map[string][]types.Coin
+	delegations []types.DelegationResponse
+	// certificates are keyed by owner, grants by granter and grantee.
+	certificates map[string][]types.CertificateResponse
+	grants       map[[2]string][]types.AuthzGrant
+	feeGrants    []types.FeeGrant
+	proposals    []types.Proposal
+	result       TxResult
+	txs          []Tx
+	subscribers  map[*subscriber]bool
+}
+
+// New starts a Server, which is stopped when the test t finishes.
+func New(t testing.TB) *Server {
+	t.Helper()
+
+	s := &Server{
+		chainID:      DefaultChainID,
+		height:       1,
+		providers:    map[string]types.ProviderRecord{},
+		audits:       map[string][]types.AuditedAttributes{},
+		balances:     map[string][]types.Coin{},
+		certificates: map[string][]types.CertificateResponse{},
+		grants:       map[[2]string][]types.AuthzGrant{},
+		subscribers:  map[*subscriber]bool{},
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("cannot listen for gRPC: %v", err)
+	}
+	s.lis = lis
+	s.grpc, err = s.newGRPCServer()
+	if err != nil {
+		t.Fatalf("cannot serve gRPC: %v", err)
+	}
+	go s.grpc.Serve(lis) //nolint:errcheck // Serve returns once the server stops.
+
+	s.rpc = httptest.NewServer(s.rpcHandler())
+
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Close stops the Server.
+func (s *Server) Close() {
+	s.grpc.Stop()
+	s.rpc.Close()
+}
+
+// GRPCEndpoint returns the gRPC endpoint of the node, served without TLS.
+func (s *Server) GRPCEndpoint() string {
+	return "http://" + s.lis.Addr().String()
+}
+
+// Node returns the Tendermint RPC endpoint of the node.
+func (s *Server) Node() string {
+	return s.rpc.URL
+}
+
+// SetChainID sets the chain ID the node reports.
+func (s *Server) SetChainID(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chainID = id
+}
+
+// Height returns the height of the latest block.
+func (s *Server) Height() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.height
+}
+
+// AddDeployment adds d, or replaces the deployment with the same owner and
+// dseq.
+func (s *Server) AddDeployment(d types.Deployment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := d.DeploymentInfo.DeploymentId
+	for i, existing := range s.deployments {
+		if existing.DeploymentInfo.DeploymentId == id {
+			s.deployments[i] = d
+			return
+		}
+	}
+	s.deployments = append(s.deployments, d)
+}
+
+// AddBid adds bid b on the order gseq/oseq of the deployment dseq of owner.
+func (s *Server) AddBid(owner, dseq string, gseq, oseq int, b types.Bid) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bids = append(s.bids, bid{owner: owner, dseq: dseq, gseq: strconv.Itoa(gseq), oseq: strconv.Itoa(oseq), bid: b})
+}
+
+// AddLease adds l, or replaces the lease with the same id.
+func (s *Server) AddLease(l types.Lease) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.leases {
+		if existing.Lease.LeaseId == l.Lease.LeaseId {
+			s.leases[i] = l
+			return
+		}
+	}
+	s.leases = append(s.leases, l)
+}
+
+// AddProvider adds the on-chain record of the provider p.Owner.
+func (s *Server) AddProvider(p types.ProviderRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.providers[p.Owner] = p
+}
+
+// AddAudit adds the attributes of the provider a.Owner signed by a.Auditor.
+func (s *Server) AddAudit(a types.AuditedAttributes) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.audits[a.Owner] = append(s.audits[a.Owner], a)
+}
+
+// SetBalances sets the balances of address, all of which are spendable.
+func (s *Server) SetBalances(address string, coins ...types.Coin) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.balances[address] = coins
+}
+
+// AddDelegation adds the stake d.Delegation.DelegatorAddress delegated.
+func (s *Server) AddDelegation(d types.DelegationResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delegations = append(s.delegations, d)
+}
+
+// AddCertificate adds a certificate published by owner.
+func (s *Server) AddCertificate(owner string, c types.CertificateResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.certificates[owner] = append(s.certificates[owner], c)
+}
+
+// AddGrant adds an authorization granter granted grantee.
+func (s *Server) AddGrant(granter, grantee string, g types.AuthzGrant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := [2]string{granter, grantee}
+	s.grants[key] = append(s.grants[key], g)
+}
+
+// AddFeeGrant adds the fee allowance g.Granter granted g.Grantee.
+func (s *Server) AddFeeGrant(g types.FeeGrant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.feeGrants = append(s.feeGrants, g)
+}
+
+// AddProposal adds a governance proposal, served as a v1 proposal.
+func (s *Server) AddProposal(p types.Proposal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.proposals = append(s.proposals, p)
+}
+
+// SetTxResult sets the result of the transactions broadcast from now on.
+// Transactions succeed without events until it is called.
+func (s *Server) SetTxResult(r TxResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.result = r
+}
+
+// Txs returns the transactions broadcast so far.
+func (s *Server) Txs() []Tx {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Tx(nil), s.txs...)
+}
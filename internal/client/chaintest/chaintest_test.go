@@ -0,0 +1,294 @@
+package chaintest
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/overlock-network/provider-akash/internal/client/events"
+	grpcquery "github.com/overlock-network/provider-akash/internal/client/grpc-query"
+	"github.com/overlock-network/provider-akash/internal/client/node"
+	"github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+func deployment(owner, dseq, state string) types.Deployment {
+	return types.Deployment{DeploymentInfo: types.DeploymentInfo{
+		State:        state,
+		DeploymentId: types.DeploymentId{Owner: owner, Dseq: dseq},
+	}}
+}
+
+func TestQueries(t *testing.T) {
+	s := New(t)
+	s.AddDeployment(deployment("akash1owner", "1", "active"))
+	s.AddDeployment(deployment("akash1owner", "2", "closed"))
+	s.AddDeployment(deployment("akash1other", "3", "active"))
+	s.AddBid("akash1owner", "1", 1, 1, types.Bid{Id: types.BidId{Provider: "akash1a"}, State: "open", Price: types.BidPrice{Denom: "uakt", Amount: 1.5}})
+	s.AddBid("akash1owner", "2", 1, 1, types.Bid{Id: types.BidId{Provider: "akash1b"}, State: "open"})
+	lease := types.Lease{Lease: types.LeaseInfo{
+		LeaseId: types.LeaseId{Owner: "akash1owner", Dseq: "1", Gseq: 1, Oseq: 1, Provider: "akash1a"},
+		State:   "active",
+	}}
+	s.AddLease(lease)
+	s.AddProvider(types.ProviderRecord{Owner: "akash1a", HostURI: "https://provider.example.com:8443"})
+	s.SetBalances("akash1owner", types.Coin{Denom: "uakt", Amount: "1000"})
+	valid := types.CertificateResponse{Certificate: types.Certificate{State: "valid", Cert: "Y2VydA=="}, Serial: "1"}
+	s.AddCertificate("akash1owner", valid)
+	s.AddCertificate("akash1owner", types.CertificateResponse{Certificate: types.Certificate{State: "revoked"}, Serial: "2"})
+	voting := types.Proposal{Id: "7", Status: grpcquery.ProposalStatusVotingPeriod}
+	s.AddProposal(voting)
+	s.AddProposal(types.Proposal{Id: "6", Status: "PROPOSAL_STATUS_PASSED"})
+
+	q, err := grpcquery.New(s.GRPCEndpoint())
+	if err != nil {
+		t.Fatalf("grpcquery.New(...): %v", err)
+	}
+	ctx := context.Background()
+
+	got, err := q.Deployment(ctx, "akash1owner", "1", 0)
+	if err != nil {
+		t.Fatalf("Deployment(...): %v", err)
+	}
+	if diff := cmp.Diff(deployment("akash1owner", "1", "active"), got); diff != "" {
+		t.Errorf("Deployment(...): -want, +got:\n%s", diff)
+	}
+	if _, err := q.Deployment(ctx, "akash1owner", "42", 0); err == nil {
+		t.Errorf("Deployment(...): want error for an unknown deployment, got nil")
+	}
+
+	page, err := q.DeploymentsPage(ctx, grpcquery.Filters{Owner: "akash1owner"}, "", 1, true)
+	if err != nil {
+		t.Fatalf("DeploymentsPage(...): %v", err)
+	}
+	wantPage := types.DeploymentResponse{
+		Deployments: []types.Deployment{deployment("akash1owner", "1", "active")},
+		Pagination:  types.Pagination{NextKey: "1", Total: "2"},
+	}
+	if diff := cmp.Diff(wantPage, page); diff != "" {
+		t.Errorf("DeploymentsPage(...): -want, +got:\n%s", diff)
+	}
+
+	active, err := q.Deployments(ctx, grpcquery.Filters{State: "active"})
+	if err != nil {
+		t.Fatalf("Deployments(...): %v", err)
+	}
+	if len(active) != 2 {
+		t.Errorf("Deployments(...): want 2 active deployments, got %d", len(active))
+	}
+
+	bids, err := q.Bids(ctx, grpcquery.Filters{Owner: "akash1owner", Dseq: "1"})
+	if err != nil {
+		t.Fatalf("Bids(...): %v", err)
+	}
+	wantBids := types.Bids{{Id: types.BidId{Provider: "akash1a"}, State: "open", Price: types.BidPrice{Denom: "uakt", Amount: 1.5}}}
+	if diff := cmp.Diff(wantBids, bids); diff != "" {
+		t.Errorf("Bids(...): -want, +got:\n%s", diff)
+	}
+
+	leases, err := q.Leases(ctx, grpcquery.Filters{Owner: "akash1owner", State: "active"}, 0)
+	if err != nil {
+		t.Fatalf("Leases(...): %v", err)
+	}
+	if diff := cmp.Diff([]types.Lease{lease}, leases); diff != "" {
+		t.Errorf("Leases(...): -want, +got:\n%s", diff)
+	}
+	if _, err := q.Lease(ctx, grpcquery.Filters{Owner: "akash1owner", Dseq: "1", Gseq: "1", Oseq: "1", Provider: "akash1b"}, 0); err == nil {
+		t.Errorf("Lease(...): want error for an unknown lease, got nil")
+	}
+
+	provider, err := q.Provider(ctx, "akash1a")
+	if err != nil {
+		t.Fatalf("Provider(...): %v", err)
+	}
+	if provider.HostURI != "https://provider.example.com:8443" {
+		t.Errorf("Provider(...): want host URI https://provider.example.com:8443, got %s", provider.HostURI)
+	}
+
+	balances, err := q.SpendableBalances(ctx, "akash1owner")
+	if err != nil {
+		t.Fatalf("SpendableBalances(...): %v", err)
+	}
+	if diff := cmp.Diff(types.Balances{Balances: []types.Coin{{Denom: "uakt", Amount: "1000"}}}, balances); diff != "" {
+		t.Errorf("SpendableBalances(...): -want, +got:\n%s", diff)
+	}
+
+	certs, err := q.Certificates(ctx, "akash1owner", "valid")
+	if err != nil {
+		t.Fatalf("Certificates(...): %v", err)
+	}
+	if diff := cmp.Diff(types.Certificates{Certificates: []types.CertificateResponse{valid}}, certs); diff != "" {
+		t.Errorf("Certificates(...): -want, +got:\n%s", diff)
+	}
+
+	proposals, err := q.Proposals(ctx, grpcquery.ProposalStatusVotingPeriod)
+	if err != nil {
+		t.Fatalf("Proposals(...): %v", err)
+	}
+	if diff := cmp.Diff([]types.Proposal{voting}, proposals); diff != "" {
+		t.Errorf("Proposals(...): -want, +got:\n%s", diff)
+	}
+}
+
+// call calls method of the Tendermint RPC of s and decodes its result into
+// out.
+func call(t *testing.T, s *Server, method string, params map[string]any, out any) *rpcError {
+	t.Helper()
+	body, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	resp, err := http.Post(s.Node(), "application/json", bytes.NewReader(body)) //nolint:noctx // The server is local.
+	if err != nil {
+		t.Fatalf("%s: %v", method, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // Nothing is lost when closing a body fails.
+
+	res := struct {
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		t.Fatalf("%s: %v", method, err)
+	}
+	if res.Error == nil && out != nil {
+		if err := json.Unmarshal(res.Result, out); err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+	}
+	return res.Error
+}
+
+func TestBroadcast(t *testing.T) {
+	s := New(t)
+	created := types.TransactionEvent{
+		Type:       events.TypeDeploymentCreated,
+		Attributes: types.TransactionEventAttributes{{Key: "id", Value: `{"owner":"akash1owner","dseq":"42"}`}},
+	}
+	s.SetTxResult(TxResult{Events: []types.TransactionEvent{created}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	received := make(chan events.Event, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- events.Subscribe(ctx, s.Node(), func(e events.Event) {
+			received <- e
+			cancel()
+		})
+	}()
+
+	// Transactions are only published to subscriptions made before they are
+	// broadcast.
+	broadcast := struct {
+		Code uint32 `json:"code"`
+		Hash string `json:"hash"`
+	}{}
+	tx := base64.StdEncoding.EncodeToString([]byte("tx"))
+	for {
+		if ctx.Err() != nil {
+			t.Fatal("no event received")
+		}
+		if e := call(t, s, "broadcast_tx_sync", map[string]any{"tx": tx}, &broadcast); e != nil {
+			t.Fatalf("broadcast_tx_sync: %s %s", e.Message, e.Data)
+		}
+		select {
+		case e := <-received:
+			want := events.Event{Type: events.TypeDeploymentCreated, Owner: "akash1owner", Dseq: "42", Height: e.Height}
+			if diff := cmp.Diff(want, e); diff != "" {
+				t.Errorf("Subscribe(...): -want, +got:\n%s", diff)
+			}
+		case <-time.After(50 * time.Millisecond):
+			continue
+		}
+		break
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Subscribe(...): %v", err)
+	}
+
+	result := struct {
+		Height   string `json:"height"`
+		TxResult struct {
+			Code   uint32                   `json:"code"`
+			Events []types.TransactionEvent `json:"events"`
+		} `json:"tx_result"`
+	}{}
+	if e := call(t, s, "tx", map[string]any{"hash": broadcast.Hash}, &result); e != nil {
+		t.Fatalf("tx: %s %s", e.Message, e.Data)
+	}
+	if diff := cmp.Diff([]types.TransactionEvent{created}, result.TxResult.Events); diff != "" {
+		t.Errorf("tx: events: -want, +got:\n%s", diff)
+	}
+	if e := call(t, s, "tx", map[string]any{"hash": "ABCDEF"}, nil); e == nil {
+		t.Errorf("tx: want error for an unknown transaction, got nil")
+	}
+
+	s.SetTxResult(TxResult{Code: 5, Log: "insufficient funds"})
+	if e := call(t, s, "broadcast_tx_sync", map[string]any{"tx": tx}, &broadcast); e != nil {
+		t.Fatalf("broadcast_tx_sync: %s %s", e.Message, e.Data)
+	}
+	if broadcast.Code != 5 {
+		t.Errorf("broadcast_tx_sync: want code 5, got %d", broadcast.Code)
+	}
+	if got := s.Txs(); len(got) < 2 {
+		t.Errorf("Txs(): want at least 2 transactions, got %d", len(got))
+	}
+}
+
+func TestABCIQueries(t *testing.T) {
+	s := New(t)
+	s.AddDeployment(deployment("akash1owner", "42", types.DeploymentStateActive))
+	n := node.New(s.Node())
+	ctx := context.Background()
+
+	account, err := n.Account(ctx, "akash1owner")
+	if err != nil {
+		t.Fatalf("Account(...): %v", err)
+	}
+	if diff := cmp.Diff(node.Account{Number: 1}, account); diff != "" {
+		t.Errorf("Account(...): -want, +got:\n%s", diff)
+	}
+
+	if _, err := n.BroadcastTx(ctx, "sync", []byte("tx")); err != nil {
+		t.Fatalf("BroadcastTx(...): %v", err)
+	}
+	if account, err = n.Account(ctx, "akash1owner"); err != nil || account.Sequence != 1 {
+		t.Errorf("Account(...) after a broadcast: want sequence 1, got %d, %v", account.Sequence, err)
+	}
+
+	if gas, err := n.Simulate(ctx, []byte("tx")); err != nil || gas != SimulatedGas {
+		t.Errorf("Simulate(...): want %d, got %d, %v", SimulatedGas, gas, err)
+	}
+
+	if state, err := n.DeploymentState(ctx, "akash1owner", 42); err != nil || state != types.DeploymentStateActive {
+		t.Errorf("DeploymentState(...): want %s, got %q, %v", types.DeploymentStateActive, state, err)
+	}
+	if _, err := n.DeploymentState(ctx, "akash1owner", 43); err == nil {
+		t.Errorf("DeploymentState(...) of a missing deployment: want error, got nil")
+	}
+}
+
+func TestMatchesQuery(t *testing.T) {
+	attrs := map[string][]string{"tm.event": {"Tx"}, "akash.v1.EventA.id": {"1"}}
+	cases := map[string]struct {
+		query string
+		want  bool
+	}{
+		"Exists":        {query: "tm.event='Tx' AND akash.v1.EventA.id EXISTS", want: true},
+		"Missing":       {query: "tm.event='Tx' AND akash.v1.EventB.id EXISTS"},
+		"Equals":        {query: "akash.v1.EventA.id='1'", want: true},
+		"DiffersValue":  {query: "tm.event='NewBlock'"},
+		"NotCondition":  {query: "tm.event"},
+		"EqualsMissing": {query: "akash.v1.EventB.id='1'"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := matchesQuery(tc.query, attrs); got != tc.want {
+				t.Errorf("matchesQuery(%q): want %t, got %t", tc.query, tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,406 @@
+package chaintest
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+// A method answers a request decoded from JSON with a response encoded as
+// JSON.
+type method func(req request) (any, error)
+
+// A request holds the fields of the requests of every query served.
+type request struct {
+	ID             filters    `json:"id"`
+	Filters        filters    `json:"filters"`
+	Filter         filters    `json:"filter"`
+	Owner          string     `json:"owner"`
+	Address        string     `json:"address"`
+	DelegatorAddr  string     `json:"delegator_addr"`
+	Granter        string     `json:"granter"`
+	Grantee        string     `json:"grantee"`
+	ProposalStatus string     `json:"proposal_status"`
+	Pagination     pagination `json:"pagination"`
+}
+
+type filters struct {
+	Owner    string `json:"owner"`
+	Dseq     string `json:"dseq"`
+	Gseq     any    `json:"gseq"`
+	Oseq     any    `json:"oseq"`
+	Provider string `json:"provider"`
+	State    string `json:"state"`
+}
+
+type pagination struct {
+	Key        string `json:"key"`
+	Limit      string `json:"limit"`
+	CountTotal bool   `json:"count_total"`
+}
+
+// services returns the methods served, keyed by service.
+func (s *Server) services() map[string]map[string]method {
+	return map[string]map[string]method{
+		"akash.deployment.v1beta3.Query": {
+			"Deployment":  s.deployment,
+			"Deployments": s.listDeployments,
+		},
+		"akash.market.v1beta4.Query": {
+			"Bids":   s.listBids,
+			"Lease":  s.lease,
+			"Leases": s.listLeases,
+		},
+		"akash.provider.v1beta3.Query": {
+			"Provider": s.provider,
+		},
+		"akash.audit.v1beta3.Query": {
+			"ProviderAttributes": s.listAudits,
+		},
+		"cosmos.bank.v1beta1.Query": {
+			"AllBalances":       s.listBalances,
+			"SpendableBalances": s.listBalances,
+		},
+		"cosmos.staking.v1beta1.Query": {
+			"DelegatorDelegations": s.listDelegations,
+		},
+		"akash.cert.v1beta3.Query": {
+			"Certificates": s.listCertificates,
+		},
+		"cosmos.authz.v1beta1.Query": {
+			"Grants": s.listGrants,
+		},
+		"cosmos.feegrant.v1beta1.Query": {
+			"Allowance": s.allowance,
+		},
+		"cosmos.gov.v1.Query": {
+			"Proposals": s.listProposals,
+		},
+	}
+}
+
+// newGRPCServer returns a gRPC server of the query services. Their requests
+// and responses are google.protobuf.Struct messages, which encode to the
+// same JSON as the messages of the chain, and are advertised through server
+// reflection like those of a node.
+func (s *Server) newGRPCServer() (*grpc.Server, error) {
+	files := &protoregistry.Files{}
+	if err := files.RegisterFile(structpb.File_google_protobuf_struct_proto); err != nil {
+		return nil, err
+	}
+
+	srv := grpc.NewServer()
+	for service, methods := range s.services() {
+		fd, err := serviceFile(service, methods, files)
+		if err != nil {
+			return nil, err
+		}
+		if err := files.RegisterFile(fd); err != nil {
+			return nil, err
+		}
+
+		desc := &grpc.ServiceDesc{ServiceName: service, HandlerType: (*any)(nil), Metadata: fd.Path()}
+		for name, m := range methods {
+			desc.Methods = append(desc.Methods, grpc.MethodDesc{MethodName: name, Handler: handler(m)})
+		}
+		srv.RegisterService(desc, s)
+	}
+	rpb.RegisterServerReflectionServer(srv, reflection.NewServer(reflection.ServerOptions{Services: srv, DescriptorResolver: files}))
+	return srv, nil
+}
+
+// serviceFile returns the descriptor of a file declaring service with the
+// given methods, each taking and returning a google.protobuf.Struct.
+func serviceFile(service string, methods map[string]method, files *protoregistry.Files) (protoreflect.FileDescriptor, error) {
+	pkg, name := path.Split(strings.ReplaceAll(service, ".", "/"))
+	sd := &descriptorpb.ServiceDescriptorProto{Name: proto.String(name)}
+	for m := range methods {
+		sd.Method = append(sd.Method, &descriptorpb.MethodDescriptorProto{
+			Name:       proto.String(m),
+			InputType:  proto.String(".google.protobuf.Struct"),
+			OutputType: proto.String(".google.protobuf.Struct"),
+		})
+	}
+	return protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String(pkg + "query.proto"),
+		Package:    proto.String(strings.ReplaceAll(strings.TrimSuffix(pkg, "/"), "/", ".")),
+		Dependency: []string{structpb.File_google_protobuf_struct_proto.Path()},
+		Service:    []*descriptorpb.ServiceDescriptorProto{sd},
+		Syntax:     proto.String("proto3"),
+	}, files)
+}
+
+// handler returns the gRPC handler of m.
+func handler(m method) func(any, context.Context, func(any) error, grpc.UnaryServerInterceptor) (any, error) {
+	return func(_ any, _ context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+		in := &structpb.Struct{}
+		if err := dec(in); err != nil {
+			return nil, err
+		}
+		raw, err := protojson.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		req := request{}
+		if err := json.Unmarshal(raw, &req); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		resp, err := m(req)
+		if err != nil {
+			return nil, err
+		}
+		raw, err = json.Marshal(resp)
+		if err != nil {
+			return nil, err
+		}
+		out := &structpb.Struct{}
+		return out, protojson.Unmarshal(raw, out)
+	}
+}
+
+func (s *Server) deployment(req request) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d, ok := s.findDeployment(req.ID.Owner, req.ID.Dseq); ok {
+		return d, nil
+	}
+	return nil, status.Error(codes.NotFound, "deployment not found")
+}
+
+// findDeployment returns the deployment dseq of owner, if any. s.mu is held.
+func (s *Server) findDeployment(owner, dseq string) (types.Deployment, bool) {
+	for _, d := range s.deployments {
+		if id := d.DeploymentInfo.DeploymentId; id.Owner == owner && id.Dseq == dseq {
+			return d, true
+		}
+	}
+	return types.Deployment{}, false
+}
+
+func (s *Server) listDeployments(req request) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var matching []types.Deployment
+	for _, d := range s.deployments {
+		id := d.DeploymentInfo.DeploymentId
+		if req.Filters.matches(id.Owner, id.Dseq, "", "", "", d.DeploymentInfo.State) {
+			matching = append(matching, d)
+		}
+	}
+	page, p, err := paginate(matching, req.Pagination)
+	return map[string]any{"deployments": page, "pagination": p}, err
+}
+
+func (s *Server) listBids(req request) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var matching []types.BidWrapper
+	for _, b := range s.bids {
+		if req.Filters.matches(b.owner, b.dseq, b.gseq, b.oseq, b.bid.Id.Provider, b.bid.State) {
+			matching = append(matching, types.BidWrapper{Bid: b.bid})
+		}
+	}
+	page, p, err := paginate(matching, req.Pagination)
+	return map[string]any{"bids": page, "pagination": p}, err
+}
+
+func (s *Server) lease(req request) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, l := range s.leases {
+		if matchesLease(req.ID, l) {
+			return l, nil
+		}
+	}
+	return nil, status.Error(codes.NotFound, "lease not found")
+}
+
+func (s *Server) listLeases(req request) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var matching []types.Lease
+	for _, l := range s.leases {
+		if matchesLease(req.Filters, l) {
+			matching = append(matching, l)
+		}
+	}
+	page, p, err := paginate(matching, req.Pagination)
+	return map[string]any{"leases": page, "pagination": p}, err
+}
+
+func (s *Server) provider(req request) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.providers[req.Owner]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "invalid provider: address not found")
+	}
+	return map[string]any{"provider": p}, nil
+}
+
+func (s *Server) listAudits(req request) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	audits, ok := s.audits[req.Owner]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "provider not found")
+	}
+	page, p, err := paginate(audits, req.Pagination)
+	return map[string]any{"providers": page, "pagination": p}, err
+}
+
+func (s *Server) listBalances(req request) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	page, p, err := paginate(s.balances[req.Address], req.Pagination)
+	return map[string]any{"balances": page, "pagination": p}, err
+}
+
+func (s *Server) listDelegations(req request) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var matching []types.DelegationResponse
+	for _, d := range s.delegations {
+		if d.Delegation.DelegatorAddress == req.DelegatorAddr {
+			matching = append(matching, d)
+		}
+	}
+	page, p, err := paginate(matching, req.Pagination)
+	return map[string]any{"delegation_responses": page, "pagination": p}, err
+}
+
+func (s *Server) listCertificates(req request) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var matching []types.CertificateResponse
+	for _, c := range s.certificates[req.Filter.Owner] {
+		if req.Filter.State == "" || req.Filter.State == c.Certificate.State {
+			matching = append(matching, c)
+		}
+	}
+	page, p, err := paginate(matching, req.Pagination)
+	return map[string]any{"certificates": page, "pagination": p}, err
+}
+
+func (s *Server) listGrants(req request) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	page, p, err := paginate(s.grants[[2]string{req.Granter, req.Grantee}], req.Pagination)
+	return map[string]any{"grants": page, "pagination": p}, err
+}
+
+func (s *Server) allowance(req request) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, g := range s.feeGrants {
+		if g.Granter == req.Granter && g.Grantee == req.Grantee {
+			return map[string]any{"allowance": g}, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "fee-grant not found")
+}
+
+func (s *Server) listProposals(req request) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var matching []types.Proposal
+	for _, pr := range s.proposals {
+		if req.ProposalStatus == "" || req.ProposalStatus == pr.Status {
+			matching = append(matching, pr)
+		}
+	}
+	page, p, err := paginate(matching, req.Pagination)
+	return map[string]any{"proposals": page, "pagination": p}, err
+}
+
+// matchesLease reports whether l matches f.
+func matchesLease(f filters, l types.Lease) bool {
+	id := l.Lease.LeaseId
+	return f.matches(id.Owner, id.Dseq, strconv.Itoa(id.Gseq), strconv.Itoa(id.Oseq), id.Provider, l.Lease.State)
+}
+
+// matches reports whether the fields of a deployment, bid or lease match f.
+// Empty fields of f and fields the deployment, bid or lease lacks match any.
+func (f filters) matches(owner, dseq, gseq, oseq, provider, state string) bool {
+	for _, c := range [][2]string{
+		{f.Owner, owner},
+		{f.Dseq, dseq},
+		{sequence(f.Gseq), gseq},
+		{sequence(f.Oseq), oseq},
+		{f.Provider, provider},
+		{f.State, state},
+	} {
+		if c[0] != "" && c[1] != "" && c[0] != c[1] {
+			return false
+		}
+	}
+	return true
+}
+
+// sequence returns a sequence of a filter, which is encoded as a string or
+// a number. Zero matches any.
+func sequence(v any) string {
+	switch s := v.(type) {
+	case string:
+		if s == "0" {
+			return ""
+		}
+		return s
+	case float64:
+		if s == 0 {
+			return ""
+		}
+		return strconv.FormatFloat(s, 'f', -1, 64)
+	}
+	return ""
+}
+
+// paginate returns the page of items p requests, and the pagination of the
+// response. Keys are the offset of the first item of the page.
+func paginate[T any](items []T, p pagination) ([]T, types.Pagination, error) {
+	offset := 0
+	if p.Key != "" {
+		o, err := strconv.Atoi(p.Key)
+		if err != nil || o < 0 || o > len(items) {
+			return nil, types.Pagination{}, status.Errorf(codes.InvalidArgument, "invalid pagination key %q", p.Key)
+		}
+		offset = o
+	}
+	limit := len(items) - offset
+	if p.Limit != "" {
+		l, err := strconv.Atoi(p.Limit)
+		if err != nil || l < 0 {
+			return nil, types.Pagination{}, status.Errorf(codes.InvalidArgument, "invalid pagination limit %q", p.Limit)
+		}
+		if l > 0 && l < limit {
+			limit = l
+		}
+	}
+
+	out := types.Pagination{}
+	if end := offset + limit; end < len(items) {
+		out.NextKey = strconv.Itoa(end)
+	}
+	if p.CountTotal {
+		out.Total = strconv.Itoa(len(items))
+	}
+	return items[offset : offset+limit], out, nil
+}
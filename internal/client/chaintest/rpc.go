@@ -0,0 +1,414 @@
+package chaintest
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/websocket"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+// SimulatedGas is the gas every simulated transaction uses.
+const SimulatedGas = 100000
+
+// Paths of the ABCI queries the node answers.
+const (
+	pathAccount    = "/cosmos.auth.v1beta1.Query/Account"
+	pathSimulate   = "/cosmos.tx.v1beta1.Service/Simulate"
+	pathDeployment = "/akash.deployment.v1beta3.Query/Deployment"
+)
+
+// codeNotFound is the ABCI code of queries of missing state.
+const codeNotFound = 22
+
+// deploymentStates numbers the states of deployments as the chain does.
+var deploymentStates = map[string]uint64{types.DeploymentStateActive: 1, types.DeploymentStateClosed: 2}
+
+// JSON-RPC error codes of Tendermint.
+const (
+	codeInvalidParams  = -32602
+	codeMethodNotFound = -32601
+	codeInternal       = -32603
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// A subscriber receives the events of the transactions matching its queries
+// over a WebSocket.
+type subscriber struct {
+	mu      sync.Mutex
+	conn    *websocket.Conn
+	queries map[string]json.RawMessage
+}
+
+// rpcHandler returns the handler of the Tendermint RPC of the node.
+func (s *Server) rpcHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/websocket", websocket.Handler(s.serveWebsocket))
+	mux.HandleFunc("/status", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("-1"), Result: s.status().Result})
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		req := rpcRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, s.call(req))
+	})
+	return mux
+}
+
+// call answers the JSON-RPC request req.
+func (s *Server) call(req rpcRequest) rpcResponse {
+	resp := rpcResponse{JSONRPC: "2.0", ID: req.ID}
+	params := struct {
+		Tx   string `json:"tx"`
+		Hash string `json:"hash"`
+		Path string `json:"path"`
+		Data string `json:"data"`
+	}{}
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			resp.Error = &rpcError{Code: codeInvalidParams, Message: "Invalid params", Data: err.Error()}
+			return resp
+		}
+	}
+
+	switch req.Method {
+	case "status":
+		resp.Result = s.status().Result
+	case "abci_query":
+		data, err := hex.DecodeString(params.Data)
+		if err != nil {
+			resp.Error = &rpcError{Code: codeInvalidParams, Message: "Invalid params", Data: err.Error()}
+			return resp
+		}
+		resp.Result = map[string]any{"response": s.abciQuery(params.Path, data)}
+	case "broadcast_tx_sync", "broadcast_tx_async", "broadcast_tx_commit":
+		raw, err := base64.StdEncoding.DecodeString(params.Tx)
+		if err != nil {
+			resp.Error = &rpcError{Code: codeInvalidParams, Message: "Invalid params", Data: err.Error()}
+			return resp
+		}
+		tx := s.broadcast(raw)
+		result := map[string]any{"code": tx.Result.Code, "log": tx.Result.Log, "hash": tx.Hash}
+		if req.Method == "broadcast_tx_commit" {
+			result = map[string]any{"check_tx": map[string]any{"code": 0}, "tx_result": txResult(tx.Result), "hash": tx.Hash, "height": strconv.FormatInt(tx.Height, 10)}
+		}
+		resp.Result = result
+	case "tx":
+		// Nodes take the hash base64 encoded over JSON-RPC, tests may give it
+		// hex encoded as well.
+		hash := strings.TrimPrefix(params.Hash, "0x")
+		if _, err := hex.DecodeString(hash); err != nil {
+			b, _ := base64.StdEncoding.DecodeString(hash)
+			hash = hex.EncodeToString(b)
+		}
+		tx, ok := s.tx(hash)
+		if !ok {
+			resp.Error = &rpcError{Code: codeInternal, Message: "Internal error", Data: "tx (" + params.Hash + ") not found"}
+			return resp
+		}
+		resp.Result = map[string]any{
+			"hash":      tx.Hash,
+			"height":    strconv.FormatInt(tx.Height, 10),
+			"index":     0,
+			"tx_result": txResult(tx.Result),
+			"tx":        base64.StdEncoding.EncodeToString(tx.Bytes),
+		}
+	default:
+		resp.Error = &rpcError{Code: codeMethodNotFound, Message: "Method not found"}
+	}
+	return resp
+}
+
+// abciQuery answers the ABCI query of the gRPC method at path with the
+// protobuf encoded request data. Every address has an account, numbered 1,
+// whose sequence is the number of transactions broadcast so far.
+func (s *Server) abciQuery(path string, data []byte) map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var value []byte
+	switch path {
+	case pathAccount:
+		address, _ := field(data, 1)
+		account := appendString(nil, 1, string(address))
+		account = appendVarint(account, 3, 1)
+		account = appendVarint(account, 4, uint64(len(s.txs)))
+		packed := appendBytes(appendString(nil, 1, "/cosmos.auth.v1beta1.BaseAccount"), 2, account)
+		value = appendBytes(nil, 1, packed)
+	case pathSimulate:
+		value = appendBytes(nil, 1, appendVarint(nil, 2, SimulatedGas))
+	case pathDeployment:
+		id, _ := field(data, 1)
+		owner, _ := field(id, 1)
+		dseq, _ := varint(id, 2)
+		d, ok := s.findDeployment(string(owner), strconv.FormatUint(dseq, 10))
+		if !ok {
+			return map[string]any{"code": codeNotFound, "log": "deployment not found"}
+		}
+		deployment := appendBytes(nil, 1, id)
+		deployment = appendVarint(deployment, 2, deploymentStates[d.DeploymentInfo.State])
+		value = appendBytes(nil, 1, deployment)
+	default:
+		return map[string]any{"code": 6, "log": "unknown query path " + path}
+	}
+	return map[string]any{"code": 0, "value": value}
+}
+
+// status returns the status of the node.
+func (s *Server) status() types.NodeStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := types.NodeStatus{}
+	status.Result.NodeInfo.Network = s.chainID
+	status.Result.NodeInfo.Version = "0.34.27"
+	status.Result.SyncInfo.LatestBlockHeight = strconv.FormatInt(s.height, 10)
+	return status
+}
+
+// broadcast includes the transaction raw in a new block with the configured
+// result, and publishes its events to the subscribers.
+func (s *Server) broadcast(raw []byte) Tx {
+	sum := sha256.Sum256(raw)
+	s.mu.Lock()
+	s.height++
+	tx := Tx{Hash: strings.ToUpper(hex.EncodeToString(sum[:])), Height: s.height, Bytes: raw, Result: s.result}
+	s.txs = append(s.txs, tx)
+	subscribers := make([]*subscriber, 0, len(s.subscribers))
+	for sub := range s.subscribers {
+		subscribers = append(subscribers, sub)
+	}
+	s.mu.Unlock()
+
+	if tx.Result.Code == 0 {
+		attrs := eventAttributes(tx)
+		for _, sub := range subscribers {
+			sub.publish(attrs)
+		}
+	}
+	return tx
+}
+
+// tx returns the transaction with the given hash, if any.
+func (s *Server) tx(hash string) (Tx, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tx := range s.txs {
+		if strings.EqualFold(tx.Hash, strings.TrimPrefix(hash, "0x")) {
+			return tx, true
+		}
+	}
+	return Tx{}, false
+}
+
+// txResult returns the result of a transaction as the node reports it.
+func txResult(r TxResult) map[string]any {
+	events := make([]map[string]any, 0, len(r.Events))
+	for _, e := range r.Events {
+		attrs := make([]map[string]any, 0, len(e.Attributes))
+		for _, a := range e.Attributes {
+			attrs = append(attrs, map[string]any{"key": a.Key, "value": a.Value, "index": true})
+		}
+		events = append(events, map[string]any{"type": e.Type, "attributes": attrs})
+	}
+	return map[string]any{"code": r.Code, "log": r.Log, "events": events}
+}
+
+// eventAttributes returns the events of tx keyed by <type>.<attribute>, as
+// the node sends them to subscribers.
+func eventAttributes(tx Tx) map[string][]string {
+	attrs := map[string][]string{
+		"tm.event":  {"Tx"},
+		"tx.hash":   {tx.Hash},
+		"tx.height": {strconv.FormatInt(tx.Height, 10)},
+	}
+	for _, e := range tx.Result.Events {
+		for _, a := range e.Attributes {
+			key := e.Type + "." + a.Key
+			attrs[key] = append(attrs[key], a.Value)
+		}
+	}
+	return attrs
+}
+
+// serveWebsocket serves the subscriptions of a client until it goes away.
+func (s *Server) serveWebsocket(conn *websocket.Conn) {
+	sub := &subscriber{conn: conn, queries: map[string]json.RawMessage{}}
+	s.mu.Lock()
+	s.subscribers[sub] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.subscribers, sub)
+		s.mu.Unlock()
+	}()
+
+	for {
+		req := rpcRequest{}
+		if err := websocket.JSON.Receive(conn, &req); err != nil {
+			return
+		}
+		params := struct {
+			Query string `json:"query"`
+		}{}
+		_ = json.Unmarshal(req.Params, &params)
+
+		resp := rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: map[string]any{}}
+		sub.mu.Lock()
+		switch req.Method {
+		case "subscribe":
+			sub.queries[params.Query] = req.ID
+		case "unsubscribe":
+			delete(sub.queries, params.Query)
+		case "unsubscribe_all":
+			sub.queries = map[string]json.RawMessage{}
+		default:
+			resp.Result, resp.Error = nil, &rpcError{Code: codeMethodNotFound, Message: "Method not found"}
+		}
+		err := websocket.JSON.Send(conn, resp)
+		sub.mu.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+// publish sends the events attrs of a transaction to sub, once per query
+// they match.
+func (sub *subscriber) publish(attrs map[string][]string) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	for query, id := range sub.queries {
+		if !matchesQuery(query, attrs) {
+			continue
+		}
+		_ = websocket.JSON.Send(sub.conn, rpcResponse{
+			JSONRPC: "2.0",
+			ID:      id,
+			Result:  map[string]any{"query": query, "events": attrs},
+		})
+	}
+}
+
+// matchesQuery reports whether attrs match a query of conditions joined by
+// AND, each either <key> EXISTS or <key>='<value>'.
+func matchesQuery(query string, attrs map[string][]string) bool {
+	for _, cond := range strings.Split(query, " AND ") {
+		cond = strings.TrimSpace(cond)
+		if key, ok := strings.CutSuffix(cond, " EXISTS"); ok {
+			if len(attrs[strings.TrimSpace(key)]) == 0 {
+				return false
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(cond, "=")
+		if !ok {
+			return false
+		}
+		value = strings.Trim(strings.TrimSpace(value), "'")
+		found := false
+		for _, v := range attrs[strings.TrimSpace(key)] {
+			found = found || v == value
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	return protowire.AppendString(protowire.AppendTag(b, num, protowire.BytesType), v)
+}
+
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	return protowire.AppendBytes(protowire.AppendTag(b, num, protowire.BytesType), v)
+}
+
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	return protowire.AppendVarint(protowire.AppendTag(b, num, protowire.VarintType), v)
+}
+
+// field returns the last value of the length-delimited field num of the
+// protobuf message b.
+func field(b []byte, num protowire.Number) ([]byte, bool) {
+	var out []byte
+	found := false
+	for len(b) > 0 {
+		n, typ, l := protowire.ConsumeTag(b)
+		if l < 0 {
+			return nil, false
+		}
+		b = b[l:]
+		if n == num && typ == protowire.BytesType {
+			out, l = protowire.ConsumeBytes(b)
+			found = true
+		} else {
+			l = protowire.ConsumeFieldValue(n, typ, b)
+		}
+		if l < 0 {
+			return nil, false
+		}
+		b = b[l:]
+	}
+	return out, found
+}
+
+// varint returns the last value of the varint field num of the protobuf
+// message b.
+func varint(b []byte, num protowire.Number) (uint64, bool) {
+	var out uint64
+	found := false
+	for len(b) > 0 {
+		n, typ, l := protowire.ConsumeTag(b)
+		if l < 0 {
+			return 0, false
+		}
+		b = b[l:]
+		if n == num && typ == protowire.VarintType {
+			out, l = protowire.ConsumeVarint(b)
+			found = true
+		} else {
+			l = protowire.ConsumeFieldValue(n, typ, b)
+		}
+		if l < 0 {
+			return 0, false
+		}
+		b = b[l:]
+	}
+	return out, found
+}
@@ -0,0 +1,160 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/pkg/errors"
+
+	providersapi "github.com/overlock-network/provider-akash/internal/client/providers-api"
+	"github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+// Names of the checks run by Check.
+const (
+	CheckTransport      = "transport"
+	CheckKey            = "key"
+	CheckAddress        = "address"
+	CheckNode           = "node"
+	CheckBalance        = "balance"
+	CheckCertificate    = "certificate"
+	CheckSimulateCreate = "simulate-create"
+)
+
+// A CheckResult is the outcome of a single conformance check.
+type CheckResult struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Skipped bool   `json:"skipped,omitempty"`
+	Message string `json:"message"`
+}
+
+// A CheckReport is the outcome of every conformance check of a configuration.
+type CheckReport struct {
+	Passed bool          `json:"passed"`
+	Checks []CheckResult `json:"checks"`
+}
+
+func (r *CheckReport) add(name string, err error, message string) {
+	result := CheckResult{Name: name, Passed: err == nil, Message: message}
+	if err != nil {
+		result.Message = err.Error()
+	}
+	r.Checks = append(r.Checks, result)
+}
+
+func (r *CheckReport) skip(name string, reason string) {
+	r.Checks = append(r.Checks, CheckResult{Name: name, Passed: true, Skipped: true, Message: reason})
+}
+
+// Check validates the configuration of the client end-to-end: the signing key
+// can be loaded, its address matches the configured one, the node is
+// reachable, the account is funded, a client certificate is published and,
+// when a manifest is given, a deployment creation can be simulated.
+func (ak *AkashClient) Check(ctx context.Context, manifestLocation string) CheckReport {
+	defer ak.begin(ctx, opUnbounded)()
+	report := CheckReport{}
+
+	report.add(CheckTransport, checkTransport(ak.Config.Transport, ak.Config.GRPCEndpoint), ak.Config.GRPCEndpoint)
+
+	address, err := ak.KeyAddress()
+	report.add(CheckKey, err, "key loaded from the credentials")
+	if err != nil {
+		address = ak.Config.AccountAddress
+	}
+
+	switch {
+	case address == "":
+		report.add(CheckAddress, fmt.Errorf("no address could be derived from the key nor is one configured"), "")
+	case ak.Config.AccountAddress != "" && ak.Config.AccountAddress != address:
+		report.add(CheckAddress, fmt.Errorf("configured address %s does not match key address %s", ak.Config.AccountAddress, address), "")
+	default:
+		report.add(CheckAddress, nil, address)
+	}
+
+	status, err := ak.GetNodeStatus(ak.ctx)
+	switch {
+	case err != nil:
+		report.add(CheckNode, err, "")
+	case status.Result.NodeInfo.Network != ak.Config.ChainId:
+		report.add(CheckNode, fmt.Errorf("node %s serves chain %s, expected %s", ak.Config.Node, status.Result.NodeInfo.Network, ak.Config.ChainId), "")
+	case status.Result.SyncInfo.CatchingUp:
+		report.add(CheckNode, fmt.Errorf("node %s is catching up", ak.Config.Node), "")
+	default:
+		report.add(CheckNode, nil, fmt.Sprintf("node %s at height %s", ak.Config.Node, status.Result.SyncInfo.LatestBlockHeight))
+	}
+
+	if address == "" {
+		report.skip(CheckBalance, "no address")
+		report.skip(CheckCertificate, "no address")
+	} else {
+		balances, err := ak.GetBalances(ak.ctx, address)
+		switch {
+		case err != nil:
+			report.add(CheckBalance, err, "")
+		case len(balances.Balances) == 0:
+			report.add(CheckBalance, fmt.Errorf("account %s has no funds", address), "")
+		default:
+			report.add(CheckBalance, nil, fmt.Sprintf("%v", balances.Balances))
+		}
+
+		certs, err := ak.GetCertificates(ak.ctx, address)
+		switch {
+		case err != nil:
+			report.add(CheckCertificate, err, "")
+		case len(certs.Certificates) == 0:
+			report.add(CheckCertificate, fmt.Errorf("account %s has no valid client certificate", address), "")
+		default:
+			report.add(CheckCertificate, nil, fmt.Sprintf("%d valid certificates", len(certs.Certificates)))
+		}
+	}
+
+	if manifestLocation == "" {
+		report.skip(CheckSimulateCreate, "no manifest given")
+	} else {
+		report.add(CheckSimulateCreate, ak.SimulateCreateDeployment(ak.ctx, manifestLocation), "deployment creation simulated")
+	}
+
+	report.Passed = true
+	for _, c := range report.Checks {
+		report.Passed = report.Passed && c.Passed
+	}
+
+	return report
+}
+
+// Preflight verifies that a deployment can be funded and leased with this
+// client: the account holds at least the given deposit and active providers
+// are available to bid on it.
+func (ak *AkashClient) Preflight(ctx context.Context, deposit types.Coin) error {
+	defer ak.begin(ctx, opQuery)()
+	address, err := ak.AccountAddress()
+	if err != nil {
+		return err
+	}
+
+	balances, err := ak.GetBalances(ak.ctx, address)
+	if err != nil {
+		return errors.Wrap(err, "cannot query balance")
+	}
+
+	have, _ := new(big.Int).SetString(balances.AmountOf(deposit.Denom), 10)
+	want, ok := new(big.Int).SetString(deposit.Amount, 10)
+	if !ok {
+		return errors.Errorf("invalid deposit amount %q", deposit.Amount)
+	}
+	if have == nil || have.Cmp(want) < 0 {
+		return errors.Errorf("account %s holds less than the %s%s deposit", address, deposit.Amount, deposit.Denom)
+	}
+
+	providers, err := providersapi.New(ak.Config.ProvidersApi).GetActiveProviders(ak.ctx)
+	if err != nil {
+		return errors.Wrap(err, "cannot get providers")
+	}
+	if len(providers) == 0 {
+		return errors.New("no active provider is available to bid")
+	}
+
+	return nil
+}
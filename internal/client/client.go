@@ -2,22 +2,39 @@ package client
 
 import (
 	"context"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+	"unicode"
 
 	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	apisv1alpha1 "github.com/overlock-network/provider-akash/apis/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client/retry"
 )
 
 type AkashClient struct {
 	ctx             context.Context
 	Config          AkashProviderConfiguration
 	transactionNote string
+	// gasAdjustment and fees override the gas settings of the configuration
+	// for transactions when set.
+	gasAdjustment float32
+	fees          string
+
+	// accountVerified is set once the signing account is known to exist.
+	accountVerified bool
+	// addressVerified is set once the account address was derived from the
+	// key and checked against the configured one.
+	addressVerified bool
+	// observedViaFallback is set once a read was served by the indexer.
+	observedViaFallback bool
 
 	// Kubernetes-based credential loading
 	kubeClient      client.Client
@@ -25,6 +42,10 @@ type AkashClient struct {
 	secretRef       *SecretReference
 	managedResource resource.Managed // Managed resource with ProviderConfigReference
 	usage           resource.Tracker // For tracking ProviderConfig usage
+
+	// pooled is the state shared with the other clients of the
+	// ProviderConfig, if any.
+	pooled *pooledClient
 }
 
 type SecretReference struct {
@@ -42,30 +63,92 @@ type credentialCache struct {
 
 type AkashProviderConfiguration struct {
 	Creds          []byte
-	KeyName        string
-	KeyringBackend string
 	AccountAddress string
 	Net            string
-	Version        string
 	ChainId        string
 	Node           string
+	Transport      string
 	Home           string
-	Path           string
 	ProvidersApi   string
+	IndexerApi     string
+	RestApi        string
+	GRPCEndpoint   string
+	// GasPrices are paid per unit of gas unless Fees are set.
+	GasPrices     string
+	GasAdjustment float32
+	Fees          string
+	FeeDenom      string
+	// FeeGranter pays the fees of transactions out of a fee allowance.
+	FeeGranter string
+	// BroadcastMode and TxConfirmationTimeout control how long transactions
+	// are waited for.
+	BroadcastMode         string
+	TxConfirmationTimeout time.Duration
+	// ConfirmMainnetSpend allows transactions depositing funds on mainnet.
+	ConfirmMainnetSpend bool
+	// Retry controls how chain calls failing with transient errors are
+	// retried.
+	Retry retry.Policy
+	// Timeouts bound the operations of the client.
+	Timeouts Timeouts
 }
 
 func (ak *AkashClient) GetContext() context.Context {
 	return ak.ctx
 }
 
-func (ak *AkashClient) GetPath() string {
-	return ak.Config.Path
-}
-
 func (ak *AkashClient) SetGlobalTransactionNote(note string) {
 	ak.transactionNote = note
 }
 
+// TransactionOptions override the settings of the transactions of a client.
+// Zero values keep the defaults.
+type TransactionOptions struct {
+	// Memo set on transactions, replacing the global transaction note.
+	Memo string
+	// GasAdjustment multiplies the estimated gas of transactions.
+	GasAdjustment float32
+	// Fees paid for each transaction, e.g. 50000uakt, instead of fees at the
+	// default gas price.
+	Fees string
+}
+
+// SetTransactionOptions overrides the settings of subsequent transactions.
+func (ak *AkashClient) SetTransactionOptions(o TransactionOptions) {
+	if o.Memo != "" {
+		ak.transactionNote = o.Memo
+	}
+	ak.gasAdjustment = o.GasAdjustment
+	ak.fees = o.Fees
+}
+
+// txGasAdjustment returns the gas adjustment of transactions.
+func (ak *AkashClient) txGasAdjustment() float32 {
+	switch {
+	case ak.gasAdjustment > 0:
+		return ak.gasAdjustment
+	case ak.Config.GasAdjustment > 0:
+		return ak.Config.GasAdjustment
+	}
+	return DefaultGasAdjustment
+}
+
+// txFees returns the fees paid for each transaction, if fixed.
+func (ak *AkashClient) txFees() string {
+	if ak.fees != "" {
+		return ak.fees
+	}
+	return ak.Config.Fees
+}
+
+// txGasPrices returns the gas prices of transactions paying no fixed fees.
+func (ak *AkashClient) txGasPrices() string {
+	if ak.Config.GasPrices != "" {
+		return ak.Config.GasPrices
+	}
+	return DefaultGasPrice + DefaultFeeDenom
+}
+
 // New creates a new AkashClient with direct credential configuration (legacy)
 func New(ctx context.Context, configuration AkashProviderConfiguration) *AkashClient {
 	return &AkashClient{ctx: ctx, Config: configuration}
@@ -86,9 +169,79 @@ func NewWithSecretRef(ctx context.Context, kubeClient client.Client, secretRef S
 
 // ProviderConfigInfo contains the credentials and configuration information from a ProviderConfig
 type ProviderConfigInfo struct {
+	// Name of the ProviderConfig. Clients of unnamed ProviderConfigs share
+	// no state.
+	Name string
+	// Namespace of the NamespacedProviderConfig, empty for a ProviderConfig.
+	Namespace           string
 	Source              xpv1.CredentialsSource
 	CredentialSelectors xpv1.CommonCredentialSelectors
 	Configuration       *apisv1alpha1.AkashConfiguration
+	// Endpoints are the endpoints discovered for the ProviderConfig, used
+	// when Configuration does not set a node.
+	Endpoints *apisv1alpha1.DiscoveredEndpoints
+}
+
+// NewProviderConfigInfo extracts the credentials and configuration of a
+// ProviderConfig.
+func NewProviderConfigInfo(pc *apisv1alpha1.ProviderConfig) ProviderConfigInfo {
+	return ProviderConfigInfo{
+		Name:                pc.GetName(),
+		Source:              pc.Spec.Credentials.Source,
+		CredentialSelectors: pc.Spec.Credentials.CommonCredentialSelectors,
+		Configuration:       pc.Spec.Configuration,
+		Endpoints:           pc.Status.Endpoints,
+	}
+}
+
+// key returns the key the state of the clients of the ProviderConfig is
+// pooled by.
+func (i ProviderConfigInfo) key() string {
+	if i.Namespace == "" || i.Name == "" {
+		return i.Name
+	}
+	return i.Namespace + "/" + i.Name
+}
+
+// secret returns the namespace/name of the Secret holding the credentials of
+// the ProviderConfig, or nothing when they come from another source.
+func (i ProviderConfigInfo) secret() string {
+	ref := i.CredentialSelectors.SecretRef
+	if i.Source != xpv1.CredentialsSourceSecret || ref == nil {
+		return ""
+	}
+	return ref.Namespace + "/" + ref.Name
+}
+
+// Node returns the RPC endpoint of the node a ProviderConfig talks to: the
+// configured node, else the endpoint discovered from the chain registry, else
+// the default node.
+func Node(pcInfo ProviderConfigInfo) string {
+	if pcInfo.Configuration != nil && pcInfo.Configuration.Node != nil {
+		return *pcInfo.Configuration.Node
+	}
+	if pcInfo.Endpoints != nil && pcInfo.Endpoints.RPC != "" {
+		return pcInfo.Endpoints.RPC
+	}
+	return DefaultNode
+}
+
+// GRPCEndpoint returns the gRPC endpoint the chain of a ProviderConfig is
+// queried through: the configured endpoint, else the endpoint discovered from
+// the chain registry, else the default endpoint. There is none when only a
+// node is configured, as the default endpoint may serve another chain.
+func GRPCEndpoint(pcInfo ProviderConfigInfo) string {
+	c := pcInfo.Configuration
+	if c != nil && c.GRPCEndpoint != nil {
+		return *c.GRPCEndpoint
+	}
+	if c != nil && c.Node != nil {
+		return ""
+	}
+	if pcInfo.Endpoints != nil && pcInfo.Endpoints.GRPC != "" {
+		return pcInfo.Endpoints.GRPC
+	}
+	return DefaultGRPCEndpoint
 }
 
 // Helper function to get string value with default fallback
@@ -99,35 +252,106 @@ func getStringValue(ptr *string, defaultValue string) string {
 	return defaultValue
 }
 
+// getDurationValue safely extracts a duration from a pointer, returning the
+// default if nil.
+func getDurationValue(ptr *metav1.Duration, defaultValue time.Duration) time.Duration {
+	if ptr != nil {
+		return ptr.Duration
+	}
+	return defaultValue
+}
+
+// withDenom returns amount in denom unless it names a denomination already.
+func withDenom(amount string, denom string) string {
+	if amount == "" || strings.IndexFunc(amount, unicode.IsLetter) >= 0 {
+		return amount
+	}
+	return amount + denom
+}
+
+// parseGasAdjustment returns the configured gas adjustment, or zero to keep
+// the default.
+func parseGasAdjustment(adjustment *string) float32 {
+	if adjustment == nil {
+		return 0
+	}
+	// The pattern of the field guarantees a valid number.
+	a, _ := strconv.ParseFloat(*adjustment, 32)
+	return float32(a)
+}
+
+// buildRetryPolicy converts a RetryPolicy into a retry.Policy, whose zero
+// fields take their defaults.
+func buildRetryPolicy(p *apisv1alpha1.RetryPolicy) retry.Policy {
+	if p == nil {
+		return retry.Policy{}
+	}
+	policy := retry.Policy{
+		Backoff:    getDurationValue(p.Backoff, 0),
+		MaxBackoff: getDurationValue(p.MaxBackoff, 0),
+	}
+	if p.MaxAttempts != nil {
+		policy.MaxAttempts = *p.MaxAttempts
+	}
+	return policy
+}
+
+// buildTimeouts converts OperationTimeouts into Timeouts, whose zero fields
+// take their defaults.
+func buildTimeouts(t *apisv1alpha1.OperationTimeouts) Timeouts {
+	if t == nil {
+		return Timeouts{}
+	}
+	return Timeouts{
+		Query:       getDurationValue(t.Query, 0),
+		Transaction: getDurationValue(t.Transaction, 0),
+		Manifest:    getDurationValue(t.Manifest, 0),
+	}
+}
+
 // buildAkashProviderConfiguration converts AkashConfiguration to AkashProviderConfiguration with constants for defaults
 func buildAkashProviderConfiguration(config *apisv1alpha1.AkashConfiguration) AkashProviderConfiguration {
 	// Set defaults if config is nil
 	if config == nil {
 		return AkashProviderConfiguration{
-			KeyName:        DefaultKeyName,
-			KeyringBackend: DefaultKeyringBackend,
-			Net:            DefaultNet,
-			Version:        DefaultVersion,
-			ChainId:        DefaultChainId,
-			Node:           DefaultNode,
-			Home:           DefaultHome,
-			Path:           DefaultPath,
-			ProvidersApi:   DefaultProvidersApi,
+			Net:                   DefaultNet,
+			ChainId:               DefaultChainId,
+			Node:                  DefaultNode,
+			GRPCEndpoint:          DefaultGRPCEndpoint,
+			Transport:             DefaultTransport,
+			Home:                  DefaultHome,
+			ProvidersApi:          DefaultProvidersApi,
+			GasPrices:             DefaultGasPrice + DefaultFeeDenom,
+			FeeDenom:              DefaultFeeDenom,
+			BroadcastMode:         DefaultBroadcastMode,
+			TxConfirmationTimeout: DefaultTxConfirmationTimeout,
 		}
 	}
 
+	feeDenom := getStringValue(config.FeeDenom, DefaultFeeDenom)
+
 	// Build configuration with values from ProviderConfig, using constants for defaults
 	return AkashProviderConfiguration{
-		KeyName:        getStringValue(config.KeyName, DefaultKeyName),
-		KeyringBackend: getStringValue(config.KeyringBackend, DefaultKeyringBackend),
-		AccountAddress: getStringValue(config.AccountAddress, ""),
-		Net:            getStringValue(config.Net, DefaultNet),
-		Version:        getStringValue(config.Version, DefaultVersion),
-		ChainId:        getStringValue(config.ChainId, DefaultChainId),
-		Node:           getStringValue(config.Node, DefaultNode),
-		Home:           getStringValue(config.Home, DefaultHome),
-		Path:           getStringValue(config.Path, DefaultPath),
-		ProvidersApi:   getStringValue(config.ProvidersApi, DefaultProvidersApi),
+		AccountAddress:        getStringValue(config.AccountAddress, ""),
+		Net:                   getStringValue(config.Net, DefaultNet),
+		ChainId:               getStringValue(config.ChainId, DefaultChainId),
+		Node:                  getStringValue(config.Node, DefaultNode),
+		Transport:             getStringValue(config.Transport, DefaultTransport),
+		Home:                  getStringValue(config.Home, DefaultHome),
+		ProvidersApi:          getStringValue(config.ProvidersApi, DefaultProvidersApi),
+		IndexerApi:            getStringValue(config.IndexerApi, ""),
+		RestApi:               getStringValue(config.RestApi, ""),
+		GRPCEndpoint:          GRPCEndpoint(ProviderConfigInfo{Configuration: config}),
+		GasPrices:             withDenom(getStringValue(config.GasPrices, DefaultGasPrice), feeDenom),
+		GasAdjustment:         parseGasAdjustment(config.GasAdjustment),
+		Fees:                  withDenom(getStringValue(config.Fees, ""), feeDenom),
+		FeeDenom:              feeDenom,
+		FeeGranter:            getStringValue(config.FeeGranter, ""),
+		BroadcastMode:         getStringValue(config.BroadcastMode, DefaultBroadcastMode),
+		TxConfirmationTimeout: getDurationValue(config.TxConfirmationTimeout, DefaultTxConfirmationTimeout),
+		ConfirmMainnetSpend:   config.ConfirmMainnetSpend != nil && *config.ConfirmMainnetSpend,
+		Retry:                 buildRetryPolicy(config.Retry),
+		Timeouts:              buildTimeouts(config.Timeouts),
 		// Creds will be set later when loaded
 	}
 }
@@ -137,6 +361,13 @@ func buildAkashProviderConfiguration(config *apisv1alpha1.AkashConfiguration) Ak
 func NewFromManagedResource(ctx context.Context, kubeClient client.Client, usage resource.Tracker, mg resource.Managed, pcInfo ProviderConfigInfo) (*AkashClient, error) {
 	// Build AkashProviderConfiguration from ProviderConfigInfo
 	config := buildAkashProviderConfiguration(pcInfo.Configuration)
+	config.Node = Node(pcInfo)
+	config.GRPCEndpoint = GRPCEndpoint(pcInfo)
+	ctx = retry.WithPolicy(ctx, config.Retry)
+
+	if err := checkTransport(config.Transport, config.GRPCEndpoint); err != nil {
+		return nil, err
+	}
 
 	client := &AkashClient{
 		ctx:             ctx,
@@ -144,9 +375,6 @@ func NewFromManagedResource(ctx context.Context, kubeClient client.Client, usage
 		kubeClient:      kubeClient,
 		managedResource: mg,
 		usage:           usage,
-		credentialCache: &credentialCache{
-			ttl: 5 * time.Minute, // Default TTL for credential cache
-		},
 	}
 
 	// Set up secret reference if using secrets
@@ -158,26 +386,38 @@ func NewFromManagedResource(ctx context.Context, kubeClient client.Client, usage
 		}
 	}
 
-	// Load credentials immediately using the provided ProviderConfig info
-	creds, err := resource.CommonCredentialExtractor(ctx, pcInfo.Source, kubeClient, pcInfo.CredentialSelectors)
+	// Credentials are extracted and parsed only when no client of the
+	// ProviderConfig did so recently.
+	pooled, err := pool.acquire(pcInfo.key(), pcInfo.secret(), config, func() ([]byte, error) {
+		start := time.Now()
+		creds, err := resource.CommonCredentialExtractor(ctx, pcInfo.Source, kubeClient, pcInfo.CredentialSelectors)
+		observeCredentialFetch(pcInfo.Source, start)
+		return creds, errors.Wrap(err, "failed to load credentials from ProviderConfig")
+	}, func(creds []byte) (AkashProviderConfiguration, error) {
+		prepared := config
+		prepared.Creds = creds
+		_, _, err := parseMnemonicCredentials(creds)
+		return prepared, err
+	})
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to load credentials from ProviderConfig")
+		return nil, err
 	}
 
-	// Track ProviderConfig usage
-	if usage != nil {
+	// Track ProviderConfig usage. Usages only count towards cluster-scoped
+	// ProviderConfigs.
+	if usage != nil && pcInfo.Namespace == "" {
 		if err := usage.Track(ctx, mg); err != nil {
 			return nil, errors.Wrap(err, "cannot track ProviderConfig usage")
 		}
 	}
 
-	// Set the credentials in config and cache
-	client.Config.Creds = creds
-	if client.credentialCache != nil {
-		client.credentialCache.mu.Lock()
-		client.credentialCache.credentials = creds
-		client.credentialCache.lastUpdated = time.Now()
-		client.credentialCache.mu.Unlock()
+	client.Config = pooled.prepared
+	client.credentialCache = pooled.cache
+	client.pooled = pooled
+	if address, exists := pooled.verifiedAccount(); address != "" {
+		client.Config.AccountAddress = address
+		client.addressVerified = true
+		client.accountVerified = exists
 	}
 
 	return client, nil
@@ -234,10 +474,16 @@ func (ak *AkashClient) getCachedCredentials() []byte {
 	ak.credentialCache.mu.RLock()
 	defer ak.credentialCache.mu.RUnlock()
 
-	if time.Since(ak.credentialCache.lastUpdated) > ak.credentialCache.ttl {
+	switch {
+	case ak.credentialCache.credentials == nil:
+		credentialCacheLookups.WithLabelValues(cacheMiss).Inc()
+		return nil
+	case time.Since(ak.credentialCache.lastUpdated) > ak.credentialCache.ttl:
+		credentialCacheLookups.WithLabelValues(cacheExpired).Inc()
 		return nil
 	}
 
+	credentialCacheLookups.WithLabelValues(cacheHit).Inc()
 	return ak.credentialCache.credentials
 }
 
@@ -259,7 +505,9 @@ func (ak *AkashClient) loadAndCacheCredentials() ([]byte, error) {
 	}
 
 	// Load credentials from secret
+	start := time.Now()
 	creds, err := resource.CommonCredentialExtractor(ak.ctx, xpv1.CredentialsSourceSecret, ak.kubeClient, credSelectors)
+	observeCredentialFetch(xpv1.CredentialsSourceSecret, start)
 	if err != nil {
 		return nil, err
 	}
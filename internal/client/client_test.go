@@ -1,11 +1,42 @@
 package client
 
 import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/protobuf/encoding/protowire"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	resourcev1alpha1 "github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
 	apisv1alpha1 "github.com/overlock-network/provider-akash/apis/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/cert"
+	"github.com/overlock-network/provider-akash/internal/client/chaintest"
+	"github.com/overlock-network/provider-akash/internal/client/node"
+	"github.com/overlock-network/provider-akash/internal/client/retry"
+	"github.com/overlock-network/provider-akash/internal/client/tx"
+	"github.com/overlock-network/provider-akash/internal/client/types"
+	"github.com/overlock-network/provider-akash/internal/sdl"
 )
 
 func TestBuildAkashProviderConfiguration(t *testing.T) {
@@ -18,62 +49,109 @@ func TestBuildAkashProviderConfiguration(t *testing.T) {
 			name:   "nil config uses constants for defaults",
 			config: nil,
 			expected: AkashProviderConfiguration{
-				KeyName:        DefaultKeyName,
-				KeyringBackend: DefaultKeyringBackend,
-				Net:            DefaultNet,
-				Version:        DefaultVersion,
-				ChainId:        DefaultChainId,
-				Node:           DefaultNode,
-				Home:           DefaultHome,
-				Path:           DefaultPath,
-				ProvidersApi:   DefaultProvidersApi,
+				Net:                   DefaultNet,
+				ChainId:               DefaultChainId,
+				Node:                  DefaultNode,
+				GRPCEndpoint:          DefaultGRPCEndpoint,
+				Transport:             DefaultTransport,
+				Home:                  DefaultHome,
+				ProvidersApi:          DefaultProvidersApi,
+				GasPrices:             DefaultGasPrice + DefaultFeeDenom,
+				FeeDenom:              DefaultFeeDenom,
+				BroadcastMode:         DefaultBroadcastMode,
+				TxConfirmationTimeout: DefaultTxConfirmationTimeout,
 			},
 		},
 		{
 			name: "partial config with custom values",
 			config: &apisv1alpha1.AkashConfiguration{
-				KeyName: stringPtr("custom-key"),
 				Net:     stringPtr("testnet"),
 				ChainId: stringPtr("testnet-1"),
 				// Other fields nil - should use constants for defaults
 			},
 			expected: AkashProviderConfiguration{
-				KeyName:        "custom-key",
-				KeyringBackend: DefaultKeyringBackend,
-				Net:            "testnet",
-				Version:        DefaultVersion,
-				ChainId:        "testnet-1",
-				Node:           DefaultNode,
-				Home:           DefaultHome,
-				Path:           DefaultPath,
-				ProvidersApi:   DefaultProvidersApi,
+				Net:                   "testnet",
+				ChainId:               "testnet-1",
+				Node:                  DefaultNode,
+				GRPCEndpoint:          DefaultGRPCEndpoint,
+				Transport:             DefaultTransport,
+				Home:                  DefaultHome,
+				ProvidersApi:          DefaultProvidersApi,
+				GasPrices:             DefaultGasPrice + DefaultFeeDenom,
+				FeeDenom:              DefaultFeeDenom,
+				BroadcastMode:         DefaultBroadcastMode,
+				TxConfirmationTimeout: DefaultTxConfirmationTimeout,
 			},
 		},
 		{
 			name: "all custom values",
 			config: &apisv1alpha1.AkashConfiguration{
-				KeyName:        stringPtr("my-key"),
-				KeyringBackend: stringPtr("os"),
 				AccountAddress: stringPtr("akash1234567890"),
 				Net:            stringPtr("testnet"),
-				Version:        stringPtr("0.20.0"),
 				ChainId:        stringPtr("testnet-2"),
 				Node:           stringPtr("https://custom-rpc.example.com:443"),
+				Transport:      stringPtr("cli"),
 				Home:           stringPtr("/custom/.akash"),
-				Path:           stringPtr("/custom/bin/akash"),
 				ProvidersApi:   stringPtr("https://custom-api.example.com"),
 			},
 			expected: AkashProviderConfiguration{
-				KeyName:        "my-key",
-				KeyringBackend: "os",
-				AccountAddress: "akash1234567890",
-				Net:            "testnet",
-				Version:        "0.20.0",
-				ChainId:        "testnet-2",
-				Node:           "https://custom-rpc.example.com:443",
-				Home:           "/custom/.akash",
-				Path:           "/custom/bin/akash",
-				ProvidersApi:   "https://custom-api.example.com",
+				AccountAddress:        "akash1234567890",
+				Net:                   "testnet",
+				ChainId:               "testnet-2",
+				Node:                  "https://custom-rpc.example.com:443",
+				Transport:             "cli",
+				Home:                  "/custom/.akash",
+				ProvidersApi:          "https://custom-api.example.com",
+				GasPrices:             DefaultGasPrice + DefaultFeeDenom,
+				FeeDenom:              DefaultFeeDenom,
+				BroadcastMode:         DefaultBroadcastMode,
+				TxConfirmationTimeout: DefaultTxConfirmationTimeout,
+			},
+		},
+		{
+			name: "gas settings in a custom fee denom",
+			config: &apisv1alpha1.AkashConfiguration{
+				GasPrices:     stringPtr("0.04"),
+				GasAdjustment: stringPtr("1.8"),
+				Fees:          stringPtr("20000"),
+				FeeDenom:      stringPtr("ibc/usdc"),
+				FeeGranter:    stringPtr("akash1treasury"),
+			},
+			expected: AkashProviderConfiguration{
+				Net:                   DefaultNet,
+				ChainId:               DefaultChainId,
+				Node:                  DefaultNode,
+				GRPCEndpoint:          DefaultGRPCEndpoint,
+				Transport:             DefaultTransport,
+				Home:                  DefaultHome,
+				ProvidersApi:          DefaultProvidersApi,
+				GasPrices:             "0.04ibc/usdc",
+				GasAdjustment:         1.8,
+				Fees:                  "20000ibc/usdc",
+				FeeDenom:              "ibc/usdc",
+				FeeGranter:            "akash1treasury",
+				BroadcastMode:         DefaultBroadcastMode,
+				TxConfirmationTimeout: DefaultTxConfirmationTimeout,
+			},
+		},
+		{
+			name: "gas prices naming a denom keep it",
+			config: &apisv1alpha1.AkashConfiguration{
+				GasPrices: stringPtr("0.1uakt"),
+				FeeDenom:  stringPtr("ibc/usdc"),
+			},
+			expected: AkashProviderConfiguration{
+				Net:                   DefaultNet,
+				ChainId:               DefaultChainId,
+				Node:                  DefaultNode,
+				GRPCEndpoint:          DefaultGRPCEndpoint,
+				Transport:             DefaultTransport,
+				Home:                  DefaultHome,
+				ProvidersApi:          DefaultProvidersApi,
+				GasPrices:             "0.1uakt",
+				FeeDenom:              "ibc/usdc",
+				BroadcastMode:         DefaultBroadcastMode,
+				TxConfirmationTimeout: DefaultTxConfirmationTimeout,
 			},
 		},
 	}
@@ -125,7 +203,1778 @@ func TestGetStringValue(t *testing.T) {
 	}
 }
 
+func TestNewManifestDeliveryPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		delivery *resourcev1alpha1.ManifestDelivery
+		expected ManifestDeliveryPolicy
+	}{
+		{
+			name:     "nil delivery uses constants for defaults",
+			delivery: nil,
+			expected: ManifestDeliveryPolicy{
+				MaxRetries: DefaultManifestMaxRetries,
+				Backoff:    DefaultManifestBackoff,
+				Timeout:    DefaultManifestTimeout,
+			},
+		},
+		{
+			name: "partial delivery with custom retries",
+			delivery: &resourcev1alpha1.ManifestDelivery{
+				MaxRetries: intPtr(0),
+			},
+			expected: ManifestDeliveryPolicy{
+				MaxRetries: 0,
+				Backoff:    DefaultManifestBackoff,
+				Timeout:    DefaultManifestTimeout,
+			},
+		},
+		{
+			name: "all custom values",
+			delivery: &resourcev1alpha1.ManifestDelivery{
+				MaxRetries: intPtr(10),
+				Backoff:    &metav1.Duration{Duration: time.Second},
+				Timeout:    &metav1.Duration{Duration: 10 * time.Minute},
+			},
+			expected: ManifestDeliveryPolicy{
+				MaxRetries: 10,
+				Backoff:    time.Second,
+				Timeout:    10 * time.Minute,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewManifestDeliveryPolicy(tt.delivery)
+			if diff := cmp.Diff(tt.expected, result); diff != "" {
+				t.Errorf("NewManifestDeliveryPolicy() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewDeposit(t *testing.T) {
+	usdc := "ibc/170C677610AC31DF0904FFE09CD3B5C657492170E7E52372E48756B71E56F2F1"
+	tests := []struct {
+		name     string
+		deposit  *resourcev1alpha1.Deposit
+		expected types.Coin
+	}{
+		{
+			name:     "nil deposit uses constants for defaults",
+			expected: types.Coin{Denom: DefaultDepositDenom, Amount: "5000000"},
+		},
+		{
+			name:     "amount only",
+			deposit:  &resourcev1alpha1.Deposit{Amount: "10000000"},
+			expected: types.Coin{Denom: DefaultDepositDenom, Amount: "10000000"},
+		},
+		{
+			name:     "IBC USDC",
+			deposit:  &resourcev1alpha1.Deposit{Amount: "5000000", Denom: usdc},
+			expected: types.Coin{Denom: usdc, Amount: "5000000"},
+		},
+		{
+			name:     "denom only",
+			deposit:  &resourcev1alpha1.Deposit{Denom: usdc},
+			expected: types.Coin{Denom: usdc, Amount: "5000000"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.expected, NewDeposit(tt.deposit)); diff != "" {
+				t.Errorf("NewDeposit() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewMaxPricePolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxPrice *resourcev1alpha1.MaxPrice
+		expected *MaxPricePolicy
+		wantErr  bool
+	}{
+		{
+			name: "nil max price sets no policy",
+		},
+		{
+			name:     "amount only uses constants for defaults",
+			maxPrice: &resourcev1alpha1.MaxPrice{Amount: "0.5"},
+			expected: &MaxPricePolicy{Amount: 0.5, Denom: DefaultMaxPriceDenom, Window: DefaultMaxPriceWindow},
+		},
+		{
+			name: "all custom values",
+			maxPrice: &resourcev1alpha1.MaxPrice{
+				Amount: "1000",
+				Denom:  "uusdc",
+				Window: &metav1.Duration{Duration: time.Hour},
+			},
+			expected: &MaxPricePolicy{Amount: 1000, Denom: "uusdc", Window: time.Hour},
+		},
+		{
+			name:     "invalid amount",
+			maxPrice: &resourcev1alpha1.MaxPrice{Amount: "cheap"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewMaxPricePolicy(tt.maxPrice)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewMaxPricePolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.expected, result); diff != "" {
+				t.Errorf("NewMaxPricePolicy() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRankBids(t *testing.T) {
+	bid := func(provider string, amount float32) types.Bid {
+		return types.Bid{Id: types.BidId{Provider: provider}, Price: types.BidPrice{Denom: "uakt", Amount: amount}}
+	}
+	bids := types.Bids{bid("a", 3), bid("b", 1), bid("c", 2)}
+	providers := []types.Provider{
+		{Address: "a", Audited: true, Uptime: 99, Attributes: map[string]string{"region": "us-west"}},
+		{Address: "b", Uptime: 50},
+		{Address: "c", Audited: true, Uptime: 90},
+	}
+
+	tests := []struct {
+		name      string
+		selection *resourcev1alpha1.BidSelection
+		expected  []string
+	}{
+		{
+			name:     "nil selection ranks the cheapest first",
+			expected: []string{"b", "c", "a"},
+		},
+		{
+			name: "audited providers outweigh a cheaper price",
+			selection: &resourcev1alpha1.BidSelection{
+				Strategy: resourcev1alpha1.BidSelectionWeighted,
+				Weights:  &resourcev1alpha1.BidSelectionWeights{Audited: intPtr(2)},
+			},
+			expected: []string{"c", "a", "b"},
+		},
+		{
+			name: "attributes outweigh a cheaper price",
+			selection: &resourcev1alpha1.BidSelection{
+				Strategy: resourcev1alpha1.BidSelectionWeighted,
+				Weights:  &resourcev1alpha1.BidSelectionWeights{Attributes: map[string]int{"region=us-west": 2}},
+			},
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name: "price only ties go to the cheapest",
+			selection: &resourcev1alpha1.BidSelection{
+				Strategy: resourcev1alpha1.BidSelectionWeighted,
+				Weights:  &resourcev1alpha1.BidSelectionWeights{Price: intPtr(0), Uptime: intPtr(0)},
+			},
+			expected: []string{"b", "c", "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := RankBids(bids, providers, NewBidSelectionPolicy(tt.selection), nil)
+			if diff := cmp.Diff(tt.expected, result.GetProviderAddresses()); diff != "" {
+				t.Errorf("RankBids() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("random ranks every bid", func(t *testing.T) {
+		policy := NewBidSelectionPolicy(&resourcev1alpha1.BidSelection{Strategy: resourcev1alpha1.BidSelectionRandom})
+		result := RankBids(bids, providers, policy, rand.New(rand.NewSource(1)))
+		if diff := cmp.Diff(bids.SortByPrice(), result.SortByPrice()); diff != "" {
+			t.Errorf("RankBids() mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestNewBidRecord(t *testing.T) {
+	at := metav1.NewTime(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	provider := types.Provider{
+		Address:    "akash1provider",
+		HostURI:    "https://provider.example.com:8443",
+		Audited:    true,
+		Attributes: map[string]string{"region": "us-west"},
+	}
+
+	tests := []struct {
+		name     string
+		bid      types.Bid
+		provider types.Provider
+		decision resourcev1alpha1.BidDecision
+		reason   string
+		expected resourcev1alpha1.BidRecord
+	}{
+		{
+			name:     "accepted bid of a known provider",
+			bid:      types.Bid{Id: types.BidId{Provider: "akash1provider"}, Price: types.BidPrice{Denom: "uakt", Amount: 1.5}},
+			provider: provider,
+			decision: resourcev1alpha1.BidDecisionAccepted,
+			reason:   "lowest price",
+			expected: resourcev1alpha1.BidRecord{
+				Provider:     "akash1provider",
+				Price:        "1.5",
+				Denom:        "uakt",
+				DisplayPrice: "0.0000015",
+				DisplayDenom: "AKT",
+				Decision:     resourcev1alpha1.BidDecisionAccepted,
+				Reason:       "lowest price",
+				DecisionTime: at,
+				ProviderSnapshot: resourcev1alpha1.ProviderSnapshot{
+					HostURI:    "https://provider.example.com:8443",
+					Audited:    true,
+					Attributes: map[string]string{"region": "us-west"},
+				},
+			},
+		},
+		{
+			name:     "rejected bid of an unknown provider in an unknown denom",
+			bid:      types.Bid{Id: types.BidId{Provider: "akash1unknown"}, Price: types.BidPrice{Denom: "ibc/unknown", Amount: 2}},
+			decision: resourcev1alpha1.BidDecisionRejected,
+			reason:   "price exceeds maximum",
+			expected: resourcev1alpha1.BidRecord{
+				Provider:     "akash1unknown",
+				Price:        "2",
+				Denom:        "ibc/unknown",
+				Decision:     resourcev1alpha1.BidDecisionRejected,
+				Reason:       "price exceeds maximum",
+				DecisionTime: at,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewBidRecord(tt.bid, tt.provider, tt.decision, tt.reason, at)
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("NewBidRecord() -want, +got:\n%s", diff)
+			}
+		})
+	}
+
+	// The snapshot must not change with the provider it was taken of.
+	record := NewBidRecord(types.Bid{Id: types.BidId{Provider: "akash1provider"}}, provider, resourcev1alpha1.BidDecisionAccepted, "", at)
+	provider.Attributes["region"] = "eu-central"
+	if got := record.ProviderSnapshot.Attributes["region"]; got != "us-west" {
+		t.Errorf("NewBidRecord() snapshot region = %q after the provider changed, want %q", got, "us-west")
+	}
+}
+
+func TestBidCollectionPolicy(t *testing.T) {
+	policy := NewBidCollectionPolicy(intPtr(3), &metav1.Duration{Duration: time.Minute})
+	if diff := cmp.Diff(BidCollectionPolicy{MinBids: 3, Window: time.Minute}, policy); diff != "" {
+		t.Errorf("NewBidCollectionPolicy() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(BidCollectionPolicy{MinBids: DefaultMinBids, Window: DefaultBidCollectionWindow}, NewBidCollectionPolicy(nil, nil)); diff != "" {
+		t.Errorf("NewBidCollectionPolicy(nil, nil) mismatch (-want +got):\n%s", diff)
+	}
+
+	tests := []struct {
+		name     string
+		received int
+		elapsed  time.Duration
+		expected bool
+	}{
+		{
+			name:     "no bids within the window",
+			received: 0,
+			elapsed:  time.Second,
+			expected: false,
+		},
+		{
+			name:     "no bids after the window",
+			received: 0,
+			elapsed:  2 * time.Minute,
+			expected: false,
+		},
+		{
+			name:     "too few bids within the window",
+			received: 2,
+			elapsed:  30 * time.Second,
+			expected: false,
+		},
+		{
+			name:     "too few bids after the window",
+			received: 1,
+			elapsed:  time.Minute,
+			expected: true,
+		},
+		{
+			name:     "enough bids within the window",
+			received: 3,
+			elapsed:  time.Second,
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Collected(tt.received, tt.elapsed); got != tt.expected {
+				t.Errorf("Collected(%d, %s) = %v, want %v", tt.received, tt.elapsed, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBuildBidReport(t *testing.T) {
+	bids := types.Bids{
+		{Id: types.BidId{Provider: "akash1expensive"}, Price: types.BidPrice{Denom: "uakt", Amount: 12.5}},
+		{Id: types.BidId{Provider: "akash1cheap"}, Price: types.BidPrice{Denom: "uakt", Amount: 1.25}},
+		{Id: types.BidId{Provider: "akash1unknown"}, Price: types.BidPrice{Denom: "uakt", Amount: 3}},
+	}
+	providers := []types.Provider{
+		{Address: "akash1cheap", Audited: true, Attributes: map[string]string{"region": "us-west"}},
+		{Address: "akash1expensive", Attributes: map[string]string{"region": "eu-central"}},
+	}
+
+	tests := []struct {
+		name     string
+		size     int
+		expected []resourcev1alpha1.BidReportEntry
+	}{
+		{
+			name: "bids are ordered by price and enriched with provider details",
+			size: 5,
+			expected: []resourcev1alpha1.BidReportEntry{
+				{Provider: "akash1cheap", Price: "1.25", Denom: "uakt", DisplayPrice: "0.00000125", DisplayDenom: "AKT", Region: "us-west", Audited: true},
+				{Provider: "akash1unknown", Price: "3", Denom: "uakt", DisplayPrice: "0.000003", DisplayDenom: "AKT"},
+				{Provider: "akash1expensive", Price: "12.5", Denom: "uakt", DisplayPrice: "0.0000125", DisplayDenom: "AKT", Region: "eu-central"},
+			},
+		},
+		{
+			name: "report is truncated to the requested size",
+			size: 1,
+			expected: []resourcev1alpha1.BidReportEntry{
+				{Provider: "akash1cheap", Price: "1.25", Denom: "uakt", DisplayPrice: "0.00000125", DisplayDenom: "AKT", Region: "us-west", Audited: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := BuildBidReport(bids, providers, tt.size)
+			if diff := cmp.Diff(tt.expected, result); diff != "" {
+				t.Errorf("BuildBidReport() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWithDeploymentLock(t *testing.T) {
+	var running, overlaps int32
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = withDeploymentLock("akash1owner", "1234", func() error {
+				if atomic.AddInt32(&running, 1) > 1 {
+					atomic.AddInt32(&overlaps, 1)
+				}
+				time.Sleep(time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+
+	if overlaps != 0 {
+		t.Errorf("withDeploymentLock() ran %d actions concurrently on the same deployment", overlaps)
+	}
+	// Locks are dropped once nobody holds or waits for them.
+	if n := deploymentLocks.len(); n != 0 {
+		t.Errorf("withDeploymentLock() kept %d locks once released, want 0", n)
+	}
+}
+
 // Helper function to create string pointers
 func stringPtr(s string) *string {
 	return &s
 }
+
+// Helper function to create int pointers
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestPendingProposals(t *testing.T) {
+	end := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	proposals := []types.Proposal{
+		{
+			ProposalId: "10",
+			Content: &types.ProposalContent{
+				Type:  "/cosmos.upgrade.v1beta1.SoftwareUpgradeProposal",
+				Title: "Upgrade to v0.36",
+				Plan:  &types.UpgradePlan{Name: "v0.36.0", Height: 16000000},
+			},
+			VotingEndTime: end,
+		},
+		{
+			ProposalId: "11",
+			Content: &types.ProposalContent{
+				Type:    "/cosmos.params.v1beta1.ParameterChangeProposal",
+				Title:   "Raise staking rewards",
+				Changes: []types.ParamChange{{Subspace: "staking", Key: "MaxValidators"}},
+			},
+		},
+		{
+			Id:    "12",
+			Title: "Raise minimum deposit",
+			Messages: []types.ProposalContent{
+				{Type: "/akash.deployment.v1beta3.MsgUpdateParams"},
+			},
+		},
+		{
+			Id: "13",
+			Messages: []types.ProposalContent{{
+				Type: "/cosmos.gov.v1.MsgExecLegacyContent",
+				Content: &types.ProposalContent{
+					Type:    "/cosmos.params.v1beta1.ParameterChangeProposal",
+					Title:   "Raise bid deposit",
+					Changes: []types.ParamChange{{Subspace: "market", Key: "BidMinDeposit"}},
+				},
+			}},
+		},
+	}
+
+	want := []apisv1alpha1.PendingProposal{
+		{
+			ID:            "10",
+			Kind:          apisv1alpha1.ProposalKindSoftwareUpgrade,
+			Title:         "Upgrade to v0.36",
+			VotingEndTime: &metav1.Time{Time: end},
+			UpgradeHeight: 16000000,
+		},
+		{
+			ID:    "12",
+			Kind:  apisv1alpha1.ProposalKindParamChange,
+			Title: "Raise minimum deposit",
+		},
+		{
+			ID:    "13",
+			Kind:  apisv1alpha1.ProposalKindParamChange,
+			Title: "Raise bid deposit",
+		},
+	}
+
+	if diff := cmp.Diff(want, PendingProposals(proposals)); diff != "" {
+		t.Errorf("PendingProposals() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBroadcastGate(t *testing.T) {
+	elected := make(chan struct{})
+	SetBroadcastGate(func() error {
+		select {
+		case <-elected:
+			return nil
+		default:
+			return errors.New("not the elected leader")
+		}
+	})
+	defer SetBroadcastGate(func() error { return nil })
+
+	var broadcast [][]byte
+	srv := testNode(t, &broadcast, 0)
+	defer srv.Close()
+	ak := New(context.Background(), AkashProviderConfiguration{
+		Creds: []byte("c4a48e2fce1481cd3294b4490f6678090ea98d3d0e5cd984558ab0968741b104"), ChainId: "akashnet-2", ConfirmMainnetSpend: true, Node: srv.URL,
+	})
+	ak.accountVerified = true
+
+	transactions := map[string]func() error{
+		"CreateLease": func() error {
+			_, err := ak.CreateLease(context.Background(), Seqs{Dseq: "12", Gseq: "1", Oseq: "1"}, "akash1provider")
+			return err
+		},
+		"DepositDeployment": func() error {
+			return ak.DepositDeployment(context.Background(), "12", "akash19rl4cm2hmr8afy4kldpxz3fka4jguq0a3mq6x0", types.Coin{Amount: "5000000", Denom: "uakt"})
+		},
+		"GrantFeeAllowance": func() error {
+			return ak.GrantFeeAllowance(context.Background(), "akash1grantee", FeeAllowance{SpendLimit: "5000000uakt"})
+		},
+		"RevokeFeeAllowance": func() error {
+			return ak.RevokeFeeAllowance(context.Background(), "akash1grantee")
+		},
+		"GrantDepositAuthorization": func() error {
+			return ak.GrantDepositAuthorization(context.Background(), "akash1grantee", types.Coin{Amount: "5000000", Denom: "uakt"}, time.Now().Add(time.Hour))
+		},
+		"RevokeDepositAuthorization": func() error {
+			return ak.RevokeDepositAuthorization(context.Background(), "akash1grantee")
+		},
+	}
+
+	// A standby replica must not broadcast.
+	for name, tx := range transactions {
+		if err := tx(); err == nil {
+			t.Errorf("%s() on a standby replica: want error, got nil", name)
+		}
+	}
+	if got := len(broadcast); got != 0 {
+		t.Errorf("standby replica broadcast %d transactions, want 0", got)
+	}
+
+	// Once it takes over, it broadcasts.
+	close(elected)
+	for name, tx := range transactions {
+		if err := tx(); err != nil {
+			t.Errorf("%s() on the elected replica: %v", name, err)
+		}
+	}
+	if got := len(broadcast); got != len(transactions) {
+		t.Errorf("elected replica broadcast %d transactions, want %d", got, len(transactions))
+	}
+}
+
+// testSDL is the SDL of a deployment of a single service.
+const testSDL = `version: "2.0"
+services:
+  web:
+    image: nginx
+    expose:
+      - port: 80
+        to:
+          - global: true
+profiles:
+  compute:
+    web:
+      resources:
+        cpu: {units: 0.5}
+        memory: {size: 512Mi}
+        storage: {size: 1Gi}
+  placement:
+    dcloud:
+      pricing:
+        web: {denom: uakt, amount: 1000}
+deployment:
+  web:
+    dcloud: {profile: web}
+`
+
+func TestCheck(t *testing.T) {
+	// The key of the mnemonic "abandon abandon ... about".
+	const key = "c4a48e2fce1481cd3294b4490f6678090ea98d3d0e5cd984558ab0968741b104"
+	const owner = "akash19rl4cm2hmr8afy4kldpxz3fka4jguq0a3mq6x0"
+
+	manifest := filepath.Join(t.TempDir(), "deployment.yaml")
+	if err := os.WriteFile(manifest, []byte(testSDL), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	type want struct {
+		passed  bool
+		failed  []string
+		skipped []string
+	}
+
+	cases := map[string]struct {
+		creds    string
+		account  string
+		chainID  string
+		funded   bool
+		manifest string
+		want     want
+	}{
+		"Passing": {
+			creds: key, account: owner, funded: true, manifest: manifest,
+			want: want{passed: true},
+		},
+		"NoManifest": {
+			creds: key, funded: true,
+			want: want{passed: true, skipped: []string{CheckSimulateCreate}},
+		},
+		"OtherAddress": {
+			creds: key, account: "akash1fsgzj6t7udv8zhf6zj32mkqhcjcpv52y9trpyw", funded: true, manifest: manifest,
+			want: want{failed: []string{CheckAddress, CheckSimulateCreate}},
+		},
+		"InvalidKey": {
+			creds: "EXAMPLE", account: owner, funded: true,
+			want: want{failed: []string{CheckKey}, skipped: []string{CheckSimulateCreate}},
+		},
+		"NoKeyNorAddress": {
+			creds: "EXAMPLE",
+			want:  want{failed: []string{CheckKey, CheckAddress}, skipped: []string{CheckBalance, CheckCertificate, CheckSimulateCreate}},
+		},
+		"OtherChain": {
+			creds: key, chainID: "sandbox-01", funded: true,
+			want: want{failed: []string{CheckNode}, skipped: []string{CheckSimulateCreate}},
+		},
+		"Unfunded": {
+			creds: key,
+			want:  want{failed: []string{CheckBalance, CheckCertificate}, skipped: []string{CheckSimulateCreate}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			chain := chaintest.New(t)
+			if tc.funded {
+				chain.SetBalances(owner, types.Coin{Amount: "5000000", Denom: "uakt"})
+				chain.AddCertificate(owner, types.CertificateResponse{Certificate: types.Certificate{State: "valid"}, Serial: "1"})
+			}
+			chainID := tc.chainID
+			if chainID == "" {
+				chainID = chaintest.DefaultChainID
+			}
+
+			ak := New(context.Background(), AkashProviderConfiguration{
+				Creds: []byte(tc.creds), AccountAddress: tc.account, ChainId: chainID, ConfirmMainnetSpend: true,
+				Node: chain.Node(), GRPCEndpoint: chain.GRPCEndpoint(),
+			})
+			report := ak.Check(context.Background(), tc.manifest)
+
+			got := want{passed: report.Passed}
+			for _, c := range report.Checks {
+				switch {
+				case c.Skipped:
+					got.skipped = append(got.skipped, c.Name)
+				case !c.Passed:
+					got.failed = append(got.failed, c.Name)
+				}
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("Check(...): -want, +got:\n%s\n%+v", diff, report)
+			}
+			if len(chain.Txs()) != 0 {
+				t.Errorf("Check(...) broadcast %d transactions, want 0", len(chain.Txs()))
+			}
+		})
+	}
+}
+
+func TestCheckTransport(t *testing.T) {
+	tests := []struct {
+		name         string
+		transport    string
+		grpcEndpoint string
+		wantErr      bool
+	}{
+		{name: "grpc", transport: TransportGRPC, grpcEndpoint: "grpc.akashnet.net:443"},
+		{name: "cli is an alias of grpc", transport: TransportCLI, grpcEndpoint: "grpc.akashnet.net:443"},
+		{name: "unset defaults to grpc", transport: "", grpcEndpoint: "grpc.akashnet.net:443"},
+		{name: "without endpoint", transport: TransportGRPC, wantErr: true},
+		{name: "unknown", transport: "rest", grpcEndpoint: "grpc.akashnet.net:443", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkTransport(tt.transport, tt.grpcEndpoint); (err != nil) != tt.wantErr {
+				t.Fatalf("checkTransport(%q, %q): want error %t, got %v", tt.transport, tt.grpcEndpoint, tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGRPCEndpoint(t *testing.T) {
+	discovered := &apisv1alpha1.DiscoveredEndpoints{RPC: "https://rpc.example.com:443", GRPC: "grpc.example.com:443"}
+	tests := []struct {
+		name   string
+		pcInfo ProviderConfigInfo
+		want   string
+	}{
+		{name: "default", want: DefaultGRPCEndpoint},
+		{name: "discovered", pcInfo: ProviderConfigInfo{Endpoints: discovered}, want: "grpc.example.com:443"},
+		{
+			name:   "configured",
+			pcInfo: ProviderConfigInfo{Configuration: &apisv1alpha1.AkashConfiguration{GRPCEndpoint: stringPtr("localhost:9090")}, Endpoints: discovered},
+			want:   "localhost:9090",
+		},
+		{
+			name:   "configured node",
+			pcInfo: ProviderConfigInfo{Configuration: &apisv1alpha1.AkashConfiguration{Node: stringPtr("http://localhost:26657")}, Endpoints: discovered},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GRPCEndpoint(tt.pcInfo); got != tt.want {
+				t.Errorf("GRPCEndpoint(): want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCheckMainnetSpend(t *testing.T) {
+	tests := []struct {
+		name      string
+		chainId   string
+		confirmed bool
+		wantErr   bool
+	}{
+		{name: "mainnet unconfirmed", chainId: DefaultChainId, wantErr: true},
+		{name: "mainnet confirmed", chainId: DefaultChainId, confirmed: true},
+		{name: "sandbox", chainId: "sandbox-01"},
+		{name: "testnet", chainId: "testnet-02"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkMainnetSpend(tt.chainId, tt.confirmed)
+			if got := errors.Is(err, ErrMainnetSpendNotConfirmed); got != tt.wantErr {
+				t.Errorf("checkMainnetSpend(%q, %t): want ErrMainnetSpendNotConfirmed %t, got %v", tt.chainId, tt.confirmed, tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSeqsFromTransaction(t *testing.T) {
+	attrs := func(kv ...string) types.TransactionEventAttributes {
+		a := types.TransactionEventAttributes{}
+		for i := 0; i+1 < len(kv); i += 2 {
+			a = append(a, types.TransactionEventAttribute{Key: kv[i], Value: kv[i+1]})
+		}
+		return a
+	}
+
+	tests := []struct {
+		name        string
+		transaction types.Transaction
+		want        Seqs
+		wantErr     bool
+	}{
+		{
+			name: "typed events",
+			transaction: types.Transaction{Events: []types.TransactionEvent{
+				{Type: "message", Attributes: attrs("action", "/akash.deployment.v1beta4.MsgCreateDeployment")},
+				{Type: eventDeploymentCreated, Attributes: attrs("id", `{"owner":"akash1owner","dseq":"19245"}`)},
+				{Type: eventOrderCreated, Attributes: attrs("id", `{"owner":"akash1owner","dseq":19245,"gseq":2,"oseq":3}`)},
+			}},
+			want: Seqs{Dseq: "19245", Gseq: "2", Oseq: "3"},
+		},
+		{
+			name: "legacy events in logs",
+			transaction: types.Transaction{Logs: []types.TransactionLog{{Events: []types.TransactionEvent{
+				{Type: "message", Attributes: attrs("action", "create-deployment")},
+				{Type: eventLegacy, Attributes: attrs("module", "deployment", "action", actionDeploymentCreated, "dseq", "812")},
+			}}}},
+			want: Seqs{Dseq: "812", Gseq: "1", Oseq: "1"},
+		},
+		{
+			name:        "failed",
+			transaction: types.Transaction{Code: 5, RawLog: "insufficient funds"},
+			wantErr:     true,
+		},
+		{
+			name: "no deployment created",
+			transaction: types.Transaction{Events: []types.TransactionEvent{
+				{Type: "message", Attributes: attrs("action", "send")},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := seqsFromTransaction(tt.transaction)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("seqsFromTransaction(...): want error %t, got %v", tt.wantErr, err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("seqsFromTransaction(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFeeCoins(t *testing.T) {
+	cases := map[string]struct {
+		fees      string
+		gasPrices string
+		gas       uint64
+		want      []tx.Coin
+		wantErr   bool
+	}{
+		"DefaultGasPrices": {gas: 200001, want: []tx.Coin{{Denom: "uakt", Amount: "5001"}}},
+		"GasPrices":        {gasPrices: "0.1uact", gas: 200001, want: []tx.Coin{{Denom: "uact", Amount: "20001"}}},
+		"Fees":             {fees: "5000uakt", gas: 200001, want: []tx.Coin{{Denom: "uakt", Amount: "5000"}}},
+		"Several":          {fees: "5000uakt, 10ibc/27394FB", want: []tx.Coin{{Denom: "uakt", Amount: "5000"}, {Denom: "ibc/27394FB", Amount: "10"}}},
+		"Invalid":          {fees: "uakt", wantErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ak := &AkashClient{Config: AkashProviderConfiguration{Fees: tc.fees, GasPrices: tc.gasPrices}}
+			got, err := ak.feeCoins(tc.gas)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("feeCoins() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("feeCoins() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// testNode serves the Tendermint RPC of a node holding an active deployment
+// and records the transactions broadcast to it. The first mismatches of them
+// are rejected with an account sequence mismatch.
+func testNode(t *testing.T, broadcast *[][]byte, mismatches int) *httptest.Server {
+	t.Helper()
+	message := func(num protowire.Number, b []byte) []byte {
+		return protowire.AppendBytes(protowire.AppendTag(nil, num, protowire.BytesType), b)
+	}
+	varint := func(num protowire.Number, v uint64) []byte {
+		return protowire.AppendVarint(protowire.AppendTag(nil, num, protowire.VarintType), v)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := struct {
+			Method string         `json:"method"`
+			Params map[string]any `json:"params"`
+		}{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		var value []byte
+		switch path, _ := req.Params["path"].(string); path {
+		case "/cosmos.auth.v1beta1.Query/Account":
+			account := append(varint(3, 7), varint(4, 3)...)
+			value = message(1, append(message(1, []byte("/cosmos.auth.v1beta1.BaseAccount")), message(2, account)...))
+		case "/akash.deployment.v1beta3.Query/Deployment":
+			value = message(1, varint(2, 1))
+		case "/cosmos.tx.v1beta1.Service/Simulate":
+			value = message(1, varint(2, 100000))
+		}
+
+		result := map[string]any{"response": map[string]any{"value": value}}
+		if req.Method == "broadcast_tx_sync" {
+			raw, _ := base64.StdEncoding.DecodeString(req.Params["tx"].(string))
+			*broadcast = append(*broadcast, raw)
+			result = map[string]any{"code": 0, "hash": "CAFE"}
+			if len(*broadcast) <= mismatches {
+				result = map[string]any{"code": 32, "hash": "CAFE", "log": "account sequence mismatch, expected 4, got 3: incorrect account sequence"}
+			}
+		}
+		if req.Method == "tx" {
+			result = map[string]any{"hash": "CAFE", "height": "7", "tx_result": map[string]any{"code": 0}}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": 1, "result": result})
+	}))
+}
+
+func TestAccountAddress(t *testing.T) {
+	// The key of the mnemonic "abandon abandon ... about".
+	const key = "c4a48e2fce1481cd3294b4490f6678090ea98d3d0e5cd984558ab0968741b104"
+	const owner = "akash19rl4cm2hmr8afy4kldpxz3fka4jguq0a3mq6x0"
+
+	const mnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+	cases := map[string]struct {
+		creds   string
+		account string
+		want    string
+		wantErr error
+	}{
+		"Unset":      {creds: key, want: owner},
+		"Matching":   {creds: key, account: owner, want: owner},
+		"Mnemonic":   {creds: mnemonic, account: owner, want: owner},
+		"Differing":  {creds: key, account: "akash1fsgzj6t7udv8zhf6zj32mkqhcjcpv52y9trpyw", wantErr: ErrAccountMismatch},
+		"OtherIndex": {creds: `{"mnemonic": "` + mnemonic + `", "index": 1}`, account: owner, wantErr: ErrAccountMismatch},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ak := New(context.Background(), AkashProviderConfiguration{Creds: []byte(tc.creds), AccountAddress: tc.account})
+			got, err := ak.AccountAddress()
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("AccountAddress() error = %v, want %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("AccountAddress(): want %q, got %q", tc.want, got)
+			}
+			if tc.wantErr != nil {
+				if ak.Config.AccountAddress != tc.account {
+					t.Errorf("AccountAddress() replaced the configured address %q with %q", tc.account, ak.Config.AccountAddress)
+				}
+				return
+			}
+
+			// The verified address is kept, without deriving it again.
+			ak.Config.Creds = nil
+			if again, err := ak.AccountAddress(); err != nil || again != tc.want {
+				t.Errorf("AccountAddress() once verified: want %q, got %q, %v", tc.want, again, err)
+			}
+		})
+	}
+}
+
+func TestCreateLease(t *testing.T) {
+	// The key of the mnemonic "abandon abandon ... about".
+	const key = "c4a48e2fce1481cd3294b4490f6678090ea98d3d0e5cd984558ab0968741b104"
+	const owner = "akash19rl4cm2hmr8afy4kldpxz3fka4jguq0a3mq6x0"
+
+	const mnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+	cases := map[string]struct {
+		creds         string
+		account       string
+		feeGranter    string
+		seqsOwner     string
+		mismatches    int
+		wantErr       error
+		wantBroadcast int
+	}{
+		"FeeGranted":       {creds: key, account: owner, feeGranter: "akash1treasury", wantBroadcast: 1},
+		"Signed":           {creds: key + "\n", account: owner, wantBroadcast: 1},
+		"SequenceMismatch": {creds: key, account: owner, mismatches: 1, wantBroadcast: 2},
+		"Unset":            {creds: key, wantBroadcast: 1},
+		"Mnemonic":         {creds: `{"mnemonic": "` + mnemonic + `"}`, account: owner, wantBroadcast: 1},
+		"OtherIndex":       {creds: `{"mnemonic": "` + mnemonic + `", "index": 1}`, account: owner, wantErr: ErrAccountMismatch},
+		"OtherOwner":       {creds: key, account: "akash1fsgzj6t7udv8zhf6zj32mkqhcjcpv52y9trpyw", wantErr: ErrAccountMismatch},
+		"Owned":            {creds: key, seqsOwner: owner, wantBroadcast: 1},
+		"NotOwned":         {creds: key, seqsOwner: "akash1fsgzj6t7udv8zhf6zj32mkqhcjcpv52y9trpyw", wantErr: ErrNotDeploymentOwner},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var broadcast [][]byte
+			srv := testNode(t, &broadcast, tc.mismatches)
+			defer srv.Close()
+
+			ak := New(context.Background(), AkashProviderConfiguration{
+				Creds: []byte(tc.creds), AccountAddress: tc.account, FeeGranter: tc.feeGranter, ChainId: "akashnet-2", Node: srv.URL,
+			})
+			_, err := ak.CreateLease(context.Background(), Seqs{Owner: tc.seqsOwner, Dseq: "12", Gseq: "1", Oseq: "1"}, "akash1provider")
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("CreateLease() error = %v, want %v", err, tc.wantErr)
+			}
+			if len(broadcast) != tc.wantBroadcast {
+				t.Fatalf("CreateLease() broadcast %d transactions, want %d", len(broadcast), tc.wantBroadcast)
+			}
+			if tc.wantBroadcast == 0 {
+				return
+			}
+
+			want := tx.MsgCreateLease{Owner: owner, Dseq: 12, Gseq: 1, Oseq: 1, Provider: "akash1provider"}
+			if !bytes.Contains(broadcast[0], want.Marshal()) {
+				t.Errorf("CreateLease() broadcast %x, want it to hold %x", broadcast[0], want.Marshal())
+			}
+			if granted := bytes.Contains(broadcast[0], []byte("akash1treasury")); granted != (tc.feeGranter != "") {
+				t.Errorf("CreateLease() broadcast a transaction granted %t, want %t", granted, tc.feeGranter != "")
+			}
+		})
+	}
+}
+
+func TestDeploymentTransactions(t *testing.T) {
+	// The key of the mnemonic "abandon abandon ... about".
+	const key = "c4a48e2fce1481cd3294b4490f6678090ea98d3d0e5cd984558ab0968741b104"
+	const owner = "akash19rl4cm2hmr8afy4kldpxz3fka4jguq0a3mq6x0"
+
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "deployment.yaml")
+	if err := os.WriteFile(manifest, []byte(testSDL), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	groups, manifests, err := sdl.ParseSDL([]byte(testSDL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	version, err := sdl.ManifestVersion(manifests)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPath := cert.Path(dir, owner)
+	certPEM, keyPEM, err := cert.Generate(owner, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cert.Write(certPath, certPEM, keyPEM); err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(certPEM)
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey, err := x509.MarshalPKIXPublicKey(leaf.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deposit := types.Coin{Denom: "uakt", Amount: "5000000"}
+	cases := map[string]struct {
+		run  func(ak *AkashClient) error
+		want tx.Msg
+	}{
+		"CreateDeployment": {
+			run: func(ak *AkashClient) error {
+				seqs, err := ak.CreateDeployment(context.Background(), manifest, deposit, "")
+				if want := (Seqs{Dseq: "1", Gseq: "1", Oseq: "1"}); err == nil && seqs != want {
+					return fmt.Errorf("created %+v, want %+v", seqs, want)
+				}
+				return err
+			},
+			// The deployment is numbered after the latest block height.
+			want: tx.MsgCreateDeployment{Owner: owner, Dseq: 1, Groups: groups, Version: version, Deposit: tx.Coin{Denom: "uakt", Amount: "5000000"}, Depositor: owner},
+		},
+		"UpdateDeployment": {
+			run: func(ak *AkashClient) error {
+				return ak.UpdateDeployment(context.Background(), "42", manifest)
+			},
+			want: tx.MsgUpdateDeployment{Owner: owner, Dseq: 42, Version: version},
+		},
+		"DepositDeployment": {
+			run: func(ak *AkashClient) error {
+				return ak.DepositDeployment(context.Background(), "42", owner, deposit)
+			},
+			want: tx.MsgDepositDeployment{Owner: owner, Dseq: 42, Amount: tx.Coin{Denom: "uakt", Amount: "5000000"}, Depositor: owner},
+		},
+		"DeleteDeployment": {
+			run: func(ak *AkashClient) error {
+				return ak.DeleteDeployment(context.Background(), "42", owner)
+			},
+			want: tx.MsgCloseDeployment{Owner: owner, Dseq: 42},
+		},
+		"PublishClientCertificate": {
+			run: func(ak *AkashClient) error {
+				return ak.PublishClientCertificate(context.Background(), certPath)
+			},
+			want: tx.MsgCreateCertificate{Owner: owner, Cert: certPEM, PubKey: pem.EncodeToMemory(&pem.Block{Type: "EC PUBLIC KEY", Bytes: pubKey})},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			chain := chaintest.New(t)
+			chain.AddDeployment(types.Deployment{DeploymentInfo: types.DeploymentInfo{
+				State:        types.DeploymentStateActive,
+				DeploymentId: types.DeploymentId{Owner: owner, Dseq: "42"},
+			}})
+			chain.SetTxResult(chaintest.TxResult{Events: []types.TransactionEvent{
+				{Type: eventDeploymentCreated, Attributes: types.TransactionEventAttributes{{Key: "id", Value: `{"owner":"` + owner + `","dseq":"1"}`}}},
+				{Type: eventOrderCreated, Attributes: types.TransactionEventAttributes{{Key: "id", Value: `{"owner":"` + owner + `","dseq":"1","gseq":1,"oseq":1}`}}},
+			}})
+
+			ak := New(context.Background(), AkashProviderConfiguration{
+				Creds: []byte(key), AccountAddress: owner, ChainId: chaintest.DefaultChainID, ConfirmMainnetSpend: true,
+				Node: chain.Node(), GRPCEndpoint: chain.GRPCEndpoint(),
+			})
+			ak.accountVerified = true
+
+			if err := tc.run(ak); err != nil {
+				t.Fatalf("%s(): %v", name, err)
+			}
+			txs := chain.Txs()
+			if len(txs) != 1 {
+				t.Fatalf("%s() broadcast %d transactions, want 1", name, len(txs))
+			}
+			if !bytes.Contains(txs[0].Bytes, tc.want.Marshal()) {
+				t.Errorf("%s() broadcast %x, want it to hold %x", name, txs[0].Bytes, tc.want.Marshal())
+			}
+		})
+	}
+}
+
+func TestLeaseEndpoints(t *testing.T) {
+	// A lease status as reported by the provider, whose leased IPs lack JSON
+	// tags.
+	raw := `{
+		"services": {"web": {"name": "web", "available": 1, "total": 1, "uris": ["web.example.com", "www.example.com"]}},
+		"forwarded_ports": {
+			"web": [{"host": "node1.provider.com", "port": 22, "externalPort": 31022, "proto": "TCP", "name": "web"}],
+			"db": [{"host": "node2.provider.com", "port": 5432, "externalPort": 32432, "proto": "TCP", "name": "db"}]
+		},
+		"ips": {"web": [{"IP": "203.0.113.7", "Port": 53, "ExternalPort": 53, "Protocol": "UDP"}]}
+	}`
+	status := types.LeaseStatus{}
+	if err := json.Unmarshal([]byte(raw), &status); err != nil {
+		t.Fatal(err)
+	}
+
+	ports, ips := LeaseEndpoints(status)
+	wantPorts := []resourcev1alpha1.ForwardedPort{
+		{Service: "db", Host: "node2.provider.com", Port: 5432, ExternalPort: 32432, Protocol: "TCP"},
+		{Service: "web", Host: "node1.provider.com", Port: 22, ExternalPort: 31022, Protocol: "TCP"},
+	}
+	if diff := cmp.Diff(wantPorts, ports); diff != "" {
+		t.Errorf("LeaseEndpoints() ports: -want, +got:\n%s", diff)
+	}
+	wantIPs := []resourcev1alpha1.LeasedIP{
+		{Service: "web", IP: "203.0.113.7", Port: 53, ExternalPort: 53, Protocol: "UDP"},
+	}
+	if diff := cmp.Diff(wantIPs, ips); diff != "" {
+		t.Errorf("LeaseEndpoints() ips: -want, +got:\n%s", diff)
+	}
+
+	wantDetails := map[string][]byte{
+		"web.uri":       []byte("web.example.com"),
+		"web.uris":      []byte("web.example.com,www.example.com"),
+		"web.22.tcp":    []byte("node1.provider.com:31022"),
+		"db.5432.tcp":   []byte("node2.provider.com:32432"),
+		"web.53.udp.ip": []byte("203.0.113.7:53"),
+	}
+	if diff := cmp.Diff(wantDetails, status.ConnectionDetails()); diff != "" {
+		t.Errorf("ConnectionDetails(): -want, +got:\n%s", diff)
+	}
+}
+
+func TestOpenBids(t *testing.T) {
+	bid := func(provider, state string) types.Bid {
+		return types.Bid{Id: types.BidId{Provider: provider}, State: state}
+	}
+	ranked := types.Bids{bid("akash1first", "open"), bid("akash1second", "open"), bid("akash1third", "open")}
+
+	tests := []struct {
+		name    string
+		current types.Bids
+		want    []string
+	}{
+		{
+			name:    "all open",
+			current: types.Bids{bid("akash1third", "open"), bid("akash1first", "open"), bid("akash1second", "open")},
+			want:    []string{"akash1first", "akash1second", "akash1third"},
+		},
+		{
+			name:    "first withdrawn",
+			current: types.Bids{bid("akash1first", "closed"), bid("akash1second", "open"), bid("akash1third", "open")},
+			want:    []string{"akash1second", "akash1third"},
+		},
+		{
+			name:    "gone or lost",
+			current: types.Bids{bid("akash1second", "lost"), bid("akash1third", "")},
+			want:    []string{"akash1third"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := openBids(ranked, tt.current).GetProviderAddresses()
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("openBids(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseMnemonicCredentials(t *testing.T) {
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+	tests := []struct {
+		name    string
+		creds   string
+		want    MnemonicCredentials
+		wantOK  bool
+		wantErr bool
+	}{
+		{
+			name:   "plain mnemonic",
+			creds:  "  abandon abandon abandon abandon abandon abandon\nabandon abandon abandon abandon abandon about\n",
+			want:   MnemonicCredentials{Mnemonic: mnemonic},
+			wantOK: true,
+		},
+		{
+			name:   "json with account and index",
+			creds:  `{"mnemonic":"` + mnemonic + `","account":1,"index":2}`,
+			want:   MnemonicCredentials{Mnemonic: mnemonic, Account: 1, Index: 2},
+			wantOK: true,
+		},
+		{
+			name:   "json with hd path",
+			creds:  `{"mnemonic":"` + mnemonic + `","hdPath":"m/44'/118'/0'/0/7"}`,
+			want:   MnemonicCredentials{Mnemonic: mnemonic, HDPath: "m/44'/118'/0'/0/7"},
+			wantOK: true,
+		},
+		{
+			name:  "hex key",
+			creds: "c4a48e2fce1481cd3294b4490f6678090ea98d3d0e5cd984558ab0968741b104\n",
+		},
+		{
+			name:  "json without mnemonic",
+			creds: `{"token":"secret"}`,
+		},
+		{
+			name:    "truncated mnemonic",
+			creds:   `{"mnemonic":"abandon abandon about"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			creds:   `{"mnemonic":`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := parseMnemonicCredentials([]byte(tt.creds))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseMnemonicCredentials(...): want error %t, got %v", tt.wantErr, err)
+			}
+			if ok != tt.wantOK {
+				t.Errorf("parseMnemonicCredentials(...): want ok %t, got %t", tt.wantOK, ok)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("parseMnemonicCredentials(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTxResult(t *testing.T) {
+	tests := []struct {
+		name        string
+		transaction types.Transaction
+		expected    string
+	}{
+		{
+			name:        "succeeded",
+			transaction: types.Transaction{TxHash: "ABC", Code: 0},
+		},
+		{
+			name:        "failed with code and log",
+			transaction: types.Transaction{TxHash: "ABC", Code: 5, RawLog: "insufficient funds"},
+			expected:    "transaction ABC failed with code 5: insufficient funds",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := txResult(tt.transaction)
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tt.expected {
+				t.Errorf("txResult() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsAccountNotFound(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "queried by the CLI",
+			err:      errors.New("rpc error: code = NotFound desc = account akash1tenant not found: key not found"),
+			expected: true,
+		},
+		{
+			name:     "queried from the node",
+			err:      fmt.Errorf("%w: akash1tenant", node.ErrAccountNotFound),
+			expected: true,
+		},
+		{
+			name:     "checked before broadcasting",
+			err:      fmt.Errorf("akash1tenant: %w", ErrAccountUninitialized),
+			expected: true,
+		},
+		{
+			name:     "sequence mismatch",
+			err:      errors.New("account sequence mismatch, expected 4, got 3: incorrect account sequence"),
+			expected: false,
+		},
+		{
+			name:     "node unreachable",
+			err:      errors.New("post failed: connection refused"),
+			expected: false,
+		},
+		{
+			name:     "no error",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsAccountNotFound(tt.err); got != tt.expected {
+				t.Errorf("IsAccountNotFound() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNodeSequence(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		mismatch bool
+		expected uint64
+	}{
+		{
+			name:     "rejected by the chain",
+			err:      errors.New("transaction ABC failed with code 32: account sequence mismatch, expected 42, got 41: incorrect account sequence"),
+			mismatch: true,
+			expected: 42,
+		},
+		{
+			name:     "rejected when checked",
+			err:      errors.New("account sequence mismatch, expected 7, got 5: incorrect account sequence"),
+			mismatch: true,
+			expected: 7,
+		},
+		{
+			name: "other failure",
+			err:  errors.New("transaction ABC failed with code 5: insufficient funds"),
+		},
+		{
+			name: "no failure",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSequenceMismatch(tt.err); got != tt.mismatch {
+				t.Fatalf("isSequenceMismatch() = %v, want %v", got, tt.mismatch)
+			}
+			if !tt.mismatch {
+				return
+			}
+			got, err := nodeSequence(context.Background(), nil, "akash1owner", tt.err)
+			if err != nil {
+				t.Fatalf("nodeSequence() unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("nodeSequence() = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGasLimit(t *testing.T) {
+	tests := []struct {
+		name       string
+		estimate   uint64
+		adjustment float32
+		want       uint64
+	}{
+		{
+			name:       "estimate is adjusted",
+			estimate:   100000,
+			adjustment: 1.5,
+			want:       150000,
+		},
+		{
+			name:       "adjusted estimate is rounded up",
+			estimate:   3,
+			adjustment: 1.5,
+			want:       5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gasLimit(tt.estimate, tt.adjustment); got != tt.want {
+				t.Errorf("gasLimit(%d, %v) = %d, want %d", tt.estimate, tt.adjustment, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCredentialCacheLookups(t *testing.T) {
+	tests := []struct {
+		name     string
+		cache    *credentialCache
+		want     []byte
+		expected string
+	}{
+		{
+			name:     "nothing cached",
+			cache:    &credentialCache{ttl: time.Minute},
+			expected: cacheMiss,
+		},
+		{
+			name:     "cached",
+			cache:    &credentialCache{credentials: []byte("creds"), lastUpdated: time.Now(), ttl: time.Minute},
+			want:     []byte("creds"),
+			expected: cacheHit,
+		},
+		{
+			name:     "cached too long ago",
+			cache:    &credentialCache{credentials: []byte("creds"), lastUpdated: time.Now().Add(-time.Hour), ttl: time.Minute},
+			expected: cacheExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := map[string]float64{}
+			for _, result := range []string{cacheHit, cacheMiss, cacheExpired} {
+				before[result] = testutil.ToFloat64(credentialCacheLookups.WithLabelValues(result))
+			}
+
+			ak := &AkashClient{credentialCache: tt.cache}
+			if got := ak.getCachedCredentials(); !bytes.Equal(got, tt.want) {
+				t.Errorf("getCachedCredentials() = %q, want %q", got, tt.want)
+			}
+
+			for result, n := range before {
+				want := n
+				if result == tt.expected {
+					want++
+				}
+				if got := testutil.ToFloat64(credentialCacheLookups.WithLabelValues(result)); got != want {
+					t.Errorf("akash_credential_cache_lookups_total{result=%q} = %v, want %v", result, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestClientPool(t *testing.T) {
+	tests := []struct {
+		name string
+		idle time.Duration
+		pc   string
+		// second is the configuration and credentials the second client is
+		// created with.
+		second      AkashProviderConfiguration
+		secondCreds string
+		// fetched and used age the pooled state before the second client.
+		fetched      time.Duration
+		used         time.Duration
+		wantExtracts int
+		wantPrepares int
+		wantShared   bool
+	}{
+		{name: "reused", idle: time.Minute, pc: "default", secondCreds: "a", wantExtracts: 1, wantPrepares: 1, wantShared: true},
+		{name: "configuration changed", idle: time.Minute, pc: "default", second: AkashProviderConfiguration{Node: "https://rpc.example.com:443"}, secondCreds: "a", wantExtracts: 2, wantPrepares: 2},
+		{name: "credentials checked and unchanged", idle: time.Hour, pc: "default", secondCreds: "a", fetched: poolCredentialTTL, wantExtracts: 2, wantPrepares: 1, wantShared: true},
+		{name: "credentials checked and changed", idle: time.Hour, pc: "default", secondCreds: "b", fetched: poolCredentialTTL, wantExtracts: 2, wantPrepares: 2},
+		{name: "evicted when idle", idle: time.Minute, pc: "default", secondCreds: "a", used: 2 * time.Minute, wantExtracts: 2, wantPrepares: 2},
+		{name: "disabled", pc: "default", secondCreds: "a", wantExtracts: 2, wantPrepares: 2},
+		{name: "unnamed ProviderConfig", idle: time.Minute, secondCreds: "a", wantExtracts: 2, wantPrepares: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newClientPool(tt.idle)
+			extracts, prepares := 0, 0
+			creds := "a"
+			extract := func() ([]byte, error) {
+				extracts++
+				return []byte(creds), nil
+			}
+			prepare := func(c []byte) (AkashProviderConfiguration, error) {
+				prepares++
+				return AkashProviderConfiguration{Creds: c}, nil
+			}
+
+			first, err := p.acquire(tt.pc, "", AkashProviderConfiguration{}, extract, prepare)
+			if err != nil {
+				t.Fatalf("acquire(): %v", err)
+			}
+			first.fetched = first.fetched.Add(-tt.fetched)
+			first.lastUsed = first.lastUsed.Add(-tt.used)
+
+			creds = tt.secondCreds
+			second, err := p.acquire(tt.pc, "", tt.second, extract, prepare)
+			if err != nil {
+				t.Fatalf("acquire(): %v", err)
+			}
+
+			if extracts != tt.wantExtracts {
+				t.Errorf("acquire() extracted credentials %d times, want %d", extracts, tt.wantExtracts)
+			}
+			if prepares != tt.wantPrepares {
+				t.Errorf("acquire() prepared %d times, want %d", prepares, tt.wantPrepares)
+			}
+			if shared := first == second; shared != tt.wantShared {
+				t.Errorf("acquire(): want shared state %t, got %t", tt.wantShared, shared)
+			}
+			if diff := cmp.Diff(tt.secondCreds, string(second.prepared.Creds)); diff != "" {
+				t.Errorf("acquire(): credentials -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestClientPoolInvalidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		secret       string
+		invalidated  string
+		wantN        int
+		wantPrepares int
+	}{
+		{name: "secret changed", secret: "crossplane-system/akash-creds", invalidated: "crossplane-system/akash-creds", wantN: 1, wantPrepares: 2},
+		{name: "other secret changed", secret: "crossplane-system/akash-creds", invalidated: "crossplane-system/other", wantPrepares: 1},
+		{name: "credentials not in a secret", invalidated: "/", wantPrepares: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newClientPool(time.Hour)
+			prepares := 0
+			extract := func() ([]byte, error) { return []byte("a"), nil }
+			prepare := func(c []byte) (AkashProviderConfiguration, error) {
+				prepares++
+				return AkashProviderConfiguration{Creds: c}, nil
+			}
+
+			first, err := p.acquire("default", tt.secret, AkashProviderConfiguration{}, extract, prepare)
+			if err != nil {
+				t.Fatalf("acquire(): %v", err)
+			}
+			if n := p.invalidate(tt.invalidated); n != tt.wantN {
+				t.Errorf("invalidate(%q): want %d, got %d", tt.invalidated, tt.wantN, n)
+			}
+			// Clients created before the invalidation reload the credentials.
+			c := &AkashClient{credentialCache: first.cache}
+			if got := c.getCachedCredentials(); (got == nil) != (tt.wantN > 0) {
+				t.Errorf("getCachedCredentials(): want reload %t, got %q", tt.wantN > 0, got)
+			}
+
+			if _, err := p.acquire("default", tt.secret, AkashProviderConfiguration{}, extract, prepare); err != nil {
+				t.Fatalf("acquire(): %v", err)
+			}
+			if prepares != tt.wantPrepares {
+				t.Errorf("acquire() prepared %d times, want %d", prepares, tt.wantPrepares)
+			}
+		})
+	}
+}
+
+func TestPooledClientAccount(t *testing.T) {
+	var e *pooledClient
+	// Clients without pooled state verify their account on their own.
+	e.verifyAddress("akash1owner")
+	e.verifyAccount()
+	if address, exists := e.verifiedAccount(); address != "" || exists {
+		t.Errorf("verifiedAccount() of no state: want nothing, got %q %t", address, exists)
+	}
+
+	e = &pooledClient{}
+	e.verifyAddress("akash1owner")
+	e.verifyAccount()
+	if address, exists := e.verifiedAccount(); address != "akash1owner" || !exists {
+		t.Errorf("verifiedAccount(): want akash1owner true, got %q %t", address, exists)
+	}
+}
+
+func TestListDeployments(t *testing.T) {
+	// The chain serves five deployments of the owner, and one of another.
+	chain := chaintest.New(t)
+	for dseq := 1; dseq <= 5; dseq++ {
+		chain.AddDeployment(types.Deployment{DeploymentInfo: types.DeploymentInfo{
+			State:        types.DeploymentStateActive,
+			DeploymentId: types.DeploymentId{Owner: "akash1owner", Dseq: strconv.Itoa(dseq)},
+		}})
+	}
+	chain.AddDeployment(types.Deployment{DeploymentInfo: types.DeploymentInfo{
+		State:        types.DeploymentStateActive,
+		DeploymentId: types.DeploymentId{Owner: "akash1other", Dseq: "6"},
+	}})
+
+	owner := DeploymentFilters{Owner: "akash1owner"}
+	tests := []struct {
+		name      string
+		opts      ListOptions
+		wantDseqs []string
+		wantTotal uint64
+		wantErr   bool
+	}{
+		{name: "every page", opts: ListOptions{Filters: owner, PageSize: 2}, wantDseqs: []string{"1", "2", "3", "4", "5"}, wantTotal: 5},
+		{name: "limited", opts: ListOptions{Filters: owner, PageSize: 2, Limit: 3}, wantDseqs: []string{"1", "2", "3"}, wantTotal: 5},
+		{name: "limited to the first page", opts: ListOptions{Filters: owner, Limit: 1}, wantDseqs: []string{"1"}, wantTotal: 5},
+		{name: "dseq range", opts: ListOptions{Filters: DeploymentFilters{Owner: "akash1owner", MinDseq: 2, MaxDseq: 4}, PageSize: 2}, wantDseqs: []string{"2", "3", "4"}, wantTotal: 5},
+		{name: "open dseq range", opts: ListOptions{Filters: DeploymentFilters{Owner: "akash1owner", MinDseq: 4}, PageSize: 2}, wantDseqs: []string{"4", "5"}, wantTotal: 5},
+		{name: "limited dseq range", opts: ListOptions{Filters: DeploymentFilters{Owner: "akash1owner", MinDseq: 2}, PageSize: 2, Limit: 2}, wantDseqs: []string{"2", "3"}, wantTotal: 5},
+		{name: "exact dseq", opts: ListOptions{Filters: DeploymentFilters{Owner: "akash1owner", MinDseq: 3, MaxDseq: 3}, PageSize: 2}, wantDseqs: []string{"3"}, wantTotal: 1},
+		{name: "closed", opts: ListOptions{Filters: DeploymentFilters{Owner: "akash1owner", State: types.DeploymentStateClosed}}},
+		{name: "invalid state", opts: ListOptions{Filters: DeploymentFilters{State: "open"}}, wantErr: true},
+		{name: "invalid dseq range", opts: ListOptions{Filters: DeploymentFilters{MinDseq: 4, MaxDseq: 2}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ak := New(context.Background(), AkashProviderConfiguration{GRPCEndpoint: chain.GRPCEndpoint()})
+
+			ids, total, err := ak.GetDeployments(context.Background(), tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetDeployments(): want error %t, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			var dseqs []string
+			for _, id := range ids {
+				dseqs = append(dseqs, id.Dseq)
+			}
+			if diff := cmp.Diff(tt.wantDseqs, dseqs); diff != "" {
+				t.Errorf("GetDeployments(): -want, +got:\n%s", diff)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("GetDeployments(): want total %d, got %d", tt.wantTotal, total)
+			}
+		})
+	}
+}
+
+// recordingLogger records the messages logged through it, with the key
+// value pairs of the logger they were logged by.
+type recordingLogger struct {
+	values  []any
+	entries *[][]any
+}
+
+func (l recordingLogger) Info(msg string, keysAndValues ...any) {
+	*l.entries = append(*l.entries, append(append([]any{msg}, l.values...), keysAndValues...))
+}
+
+func (l recordingLogger) Debug(msg string, keysAndValues ...any) {
+	l.Info(msg, keysAndValues...)
+}
+
+func (l recordingLogger) WithValues(keysAndValues ...any) logging.Logger {
+	return recordingLogger{values: append(append([]any{}, l.values...), keysAndValues...), entries: l.entries}
+}
+
+func TestLog(t *testing.T) {
+	var entries [][]any
+	SetLogger(recordingLogger{entries: &entries})
+	defer SetLogger(logging.NewNopLogger())
+
+	ak := New(context.Background(), AkashProviderConfiguration{ChainId: "akashnet-2", AccountAddress: "akash1owner"})
+	ak.log().Info("Broadcast transaction", "txhash", "ABC")
+
+	want := [][]any{{"Broadcast transaction", "chain", "akashnet-2", "owner", "akash1owner", "txhash", "ABC"}}
+	if diff := cmp.Diff(want, entries); diff != "" {
+		t.Errorf("log(): -want, +got:\n%s", diff)
+	}
+}
+
+func TestBuildRetryPolicy(t *testing.T) {
+	attempts := 1
+	tests := []struct {
+		name   string
+		policy *apisv1alpha1.RetryPolicy
+		want   retry.Policy
+	}{
+		{name: "unset takes the defaults"},
+		{
+			name:   "retries disabled",
+			policy: &apisv1alpha1.RetryPolicy{MaxAttempts: &attempts},
+			want:   retry.Policy{MaxAttempts: 1},
+		},
+		{
+			name:   "backoff",
+			policy: &apisv1alpha1.RetryPolicy{Backoff: &metav1.Duration{Duration: 2 * time.Second}, MaxBackoff: &metav1.Duration{Duration: time.Minute}},
+			want:   retry.Policy{Backoff: 2 * time.Second, MaxBackoff: time.Minute},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, buildRetryPolicy(tt.policy)); diff != "" {
+				t.Errorf("buildRetryPolicy(): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOperationTimeouts(t *testing.T) {
+	// The endpoint accepts connections but never answers, like an
+	// unresponsive node.
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close() //nolint:errcheck // Nothing is lost when closing a listener fails.
+	held := make(chan net.Conn, 16)
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				close(held)
+				return
+			}
+			held <- conn
+		}
+	}()
+	defer func() {
+		_ = lis.Close()
+		for conn := range held {
+			_ = conn.Close()
+		}
+	}()
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		timeouts Timeouts
+	}{
+		{name: "query timeout", ctx: context.Background(), timeouts: Timeouts{Query: 100 * time.Millisecond}},
+		{name: "canceled reconcile", ctx: canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := context.Background()
+			ak := New(parent, AkashProviderConfiguration{GRPCEndpoint: lis.Addr().String(), Timeouts: tt.timeouts, Retry: retry.Policy{MaxAttempts: 1}})
+
+			start := time.Now()
+			if _, err := ak.GetDeployment(tt.ctx, "1", "akash1owner"); err == nil {
+				t.Fatal("GetDeployment(): want error, got nil")
+			}
+			if elapsed := time.Since(start); elapsed > 10*time.Second {
+				t.Errorf("GetDeployment(): returned after %s, want it cut short", elapsed)
+			}
+			if ak.GetContext() != parent {
+				t.Error("GetDeployment(): the context of the client was not restored")
+			}
+		})
+	}
+}
+
+func TestTimeouts(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeouts Timeouts
+		op       operation
+		want     time.Duration
+	}{
+		{name: "default query", op: opQuery, want: DefaultQueryTimeout},
+		{name: "default transaction", op: opTransaction, want: DefaultTransactionTimeout},
+		{name: "default manifest", op: opManifest, want: DefaultManifestTimeout},
+		{name: "configured query", timeouts: Timeouts{Query: time.Second}, op: opQuery, want: time.Second},
+		{name: "unbounded", timeouts: Timeouts{Query: time.Second}, op: opUnbounded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.timeouts.timeout(tt.op); got != tt.want {
+				t.Errorf("timeout(): want %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBeginTransaction(t *testing.T) {
+	ak := New(context.Background(), AkashProviderConfiguration{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := ak.begin(ctx, opTransaction)
+	cancel()
+	if err := ak.GetContext().Err(); err != nil {
+		t.Errorf("begin(): a transaction was cancelled with its reconcile: %v", err)
+	}
+	if _, ok := ak.GetContext().Deadline(); !ok {
+		t.Error("begin(): a transaction has no deadline")
+	}
+	done()
+	if ak.GetContext() != context.Background() {
+		t.Error("begin(): the context of the client was not restored")
+	}
+}
@@ -1,29 +1,80 @@
 package client
 
+import "time"
+
 // Default configuration constants for Akash provider
 const (
-	// Default key and keyring settings
-	DefaultKeyName        = "default"
-	DefaultKeyringBackend = "test"
-
 	// Default network settings
 	DefaultNet     = "mainnet"
 	DefaultChainId = "akashnet-2"
 	DefaultNode    = "https://rpc.akashnet.io:443"
+	// DefaultGRPCEndpoint is the gRPC endpoint of DefaultNode.
+	DefaultGRPCEndpoint = "grpc.akashnet.net:443"
 
-	// Default version and paths
-	DefaultVersion      = "0.18.0"
+	// Default transport
+	DefaultTransport = TransportGRPC
+
+	// Default paths and APIs
 	DefaultHome         = "/tmp/.akash"
-	DefaultPath         = "/usr/local/bin/akash"
 	DefaultProvidersApi = "https://akash-api.polkachu.com"
 
-	// Validation constants
-	KeyringBackendOS     = "os"
-	KeyringBackendFile   = "file"
-	KeyringBackendTest   = "test"
-	KeyringBackendMemory = "memory"
+	// Default gas price, in the default fee denomination
+	DefaultGasPrice = "0.025"
+	DefaultFeeDenom = "uakt"
+
+	// DefaultGasAdjustment multiplies the estimated gas of transactions.
+	DefaultGasAdjustment = 1.5
+
+	// Default deployment deposit, matching the Akash CLI default
+	DefaultDepositAmount = 5000000
+	DefaultDepositDenom  = "uakt"
+
+	// Default manifest delivery settings
+	DefaultManifestMaxRetries = 3
+	DefaultManifestBackoff    = 5 * time.Second
+	DefaultManifestTimeout    = 2 * time.Minute
+
+	// Default broadcast settings
+	DefaultBroadcastMode         = BroadcastModeSync
+	DefaultTxConfirmationTimeout = time.Minute
+
+	// Default bid collection settings
+	DefaultMinBids             = 1
+	DefaultBidCollectionWindow = time.Minute
+
+	// Default bid selection settings
+	DefaultBidSelectionStrategy = "lowestPrice"
+	DefaultBidPriceWeight       = 1
+
+	// Default maximum price settings
+	DefaultMaxPriceDenom  = "uakt"
+	DefaultMaxPriceWindow = 5 * time.Minute
+
+	// Default denomination of account balance thresholds
+	DefaultBalanceDenom = "uakt"
+
+	// Default deposit authorization settings, the validity matching the
+	// Akash CLI default
+	DefaultAuthzValidity    = 365 * 24 * time.Hour
+	DefaultAuthzRenewBefore = 7 * 24 * time.Hour
+
+	// Default operation timeouts
+	DefaultQueryTimeout       = time.Minute
+	DefaultTransactionTimeout = 3 * time.Minute
+
+	// Default page size of list queries, matching the Cosmos SDK default
+	DefaultPageSize = 100
+
+	// TransportCLI is a deprecated alias of TransportGRPC, kept for
+	// configurations written when queries ran the Akash CLI.
+	TransportCLI  = "cli"
+	TransportGRPC = "grpc"
 
 	NetworkMainnet = "mainnet"
 	NetworkTestnet = "testnet"
 	NetworkSandbox = "sandbox"
+
+	// MainnetChainIdPrefix prefixes the chain IDs of the Akash mainnet,
+	// e.g. akashnet-2.
+	MainnetChainIdPrefix = "akashnet-"
 )
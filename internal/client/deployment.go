@@ -1,100 +1,461 @@
 package client
 
 import (
-	"fmt"
+	"context"
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
 
-	"github.com/overlock-network/provider-akash/internal/client/cli"
+	"github.com/pkg/errors"
+
+	resourcev1alpha1 "github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	grpcquery "github.com/overlock-network/provider-akash/internal/client/grpc-query"
+	"github.com/overlock-network/provider-akash/internal/client/node"
+	"github.com/overlock-network/provider-akash/internal/client/tx"
 	"github.com/overlock-network/provider-akash/internal/client/types"
+	"github.com/overlock-network/provider-akash/internal/sdl"
+)
+
+const (
+	errTransactionFailed   = "transaction %s failed with code %d: %s"
+	errNoDeploymentCreated = "transaction %s created no deployment: %s"
+	errDeploymentState     = "invalid deployment state %q, want active or closed"
+	errDseqRange           = "invalid dseq range %d to %d"
+
+	eventDeploymentCreated  = "akash.deployment.v1.EventDeploymentCreated"
+	eventOrderCreated       = "akash.market.v1.EventOrderCreated"
+	eventLegacy             = "akash.v1"
+	actionDeploymentCreated = "deployment-created"
 )
 
 type Seqs struct {
+	// Owner is the owner of the deployment, the signing account when empty.
+	Owner string
+
 	Dseq string
 	Gseq string
 	Oseq string
 }
 
-func (ak *AkashClient) GetDeployments(owner string) ([]types.DeploymentId, error) {
-	panic("Not implemented")
+// DeploymentFilters select the deployments of list queries. The chain filters
+// by owner, state and an exact dseq, dseq ranges are applied to the queried
+// pages.
+type DeploymentFilters struct {
+	// Owner of the deployments.
+	Owner string
+	// State of the deployments, DeploymentStateActive or
+	// DeploymentStateClosed. Any state when empty.
+	State string
+	// MinDseq and MaxDseq bound the dseqs of the deployments, inclusively.
+	// Zero leaves a bound open.
+	MinDseq uint64
+	MaxDseq uint64
 }
 
-func (ak *AkashClient) GetDeployment(dseq string, owner string) (types.Deployment, error) {
-	cmd := cli.AkashCli(ak).Query().Deployment().Get().SetOwner(owner).SetDseq(dseq).SetChainId(ak.Config.ChainId).
-		SetNode(ak.Config.Node).OutputJson()
+// ListOptions page through the results of list queries.
+type ListOptions struct {
+	Filters DeploymentFilters
+	// PageSize is the number of results queried at once, DefaultPageSize
+	// when zero.
+	PageSize uint64
+	// Limit is the maximum number of results returned, every result when
+	// zero.
+	Limit uint64
+}
 
-	deployment := types.Deployment{}
-	err := cmd.DecodeJson(&deployment)
+// GetDeployments queries the ids of the deployments matching o, see
+// ListDeployments.
+func (ak *AkashClient) GetDeployments(ctx context.Context, o ListOptions) ([]types.DeploymentId, uint64, error) {
+	defer ak.begin(ctx, opQuery)()
+	deployments, total, err := ak.ListDeployments(ak.ctx, o)
+	if err != nil {
+		return nil, 0, err
+	}
+	ids := make([]types.DeploymentId, 0, len(deployments))
+	for _, d := range deployments {
+		ids = append(ids, d.DeploymentInfo.DeploymentId)
+	}
+	return ids, total, nil
+}
+
+// ListDeployments queries the deployments matching o page by page until the
+// last page or the limit. It returns them with the total number of
+// deployments matching the filters the chain applies, which exceeds the
+// number returned when o limits them or selects a dseq range.
+func (ak *AkashClient) ListDeployments(ctx context.Context, o ListOptions) ([]types.Deployment, uint64, error) {
+	defer ak.begin(ctx, opQuery)()
+	f := o.Filters
+	switch f.State {
+	case "", types.DeploymentStateActive, types.DeploymentStateClosed:
+	default:
+		return nil, 0, errors.Errorf(errDeploymentState, f.State)
+	}
+	if f.MaxDseq > 0 && f.MinDseq > f.MaxDseq {
+		return nil, 0, errors.Errorf(errDseqRange, f.MinDseq, f.MaxDseq)
+	}
+	exact := grpcquery.Filters{Owner: f.Owner, State: f.State}
+	if f.MinDseq > 0 && f.MinDseq == f.MaxDseq {
+		exact.Dseq = strconv.FormatUint(f.MinDseq, 10)
+	}
+
+	size := o.PageSize
+	if size == 0 {
+		size = DefaultPageSize
+	}
+	if o.Limit > 0 && o.Limit < size {
+		size = o.Limit
+	}
+
+	q, err := ak.grpcQuery()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// The first page counts the total.
+	var deployments []types.Deployment
+	var total uint64
+	key := ""
+	for page := 1; ; page++ {
+		res, err := q.DeploymentsPage(ak.ctx, exact, key, size, page == 1)
+		if err != nil {
+			return nil, 0, err
+		}
+		if page == 1 {
+			total, _ = strconv.ParseUint(res.Pagination.Total, 10, 64)
+		}
+		for _, d := range res.Deployments {
+			if f.inDseqRange(d.DeploymentInfo.DeploymentId.Dseq) {
+				deployments = append(deployments, d)
+			}
+		}
+
+		if o.Limit > 0 && uint64(len(deployments)) >= o.Limit {
+			return deployments[:o.Limit], total, nil
+		}
+		key = res.Pagination.NextKey
+		if key == "" || uint64(len(res.Deployments)) < size {
+			return deployments, total, nil
+		}
+	}
+}
+
+// inDseqRange reports whether dseq is within the dseq range of f.
+func (f DeploymentFilters) inDseqRange(dseq string) bool {
+	if f.MinDseq == 0 && f.MaxDseq == 0 {
+		return true
+	}
+	n, err := strconv.ParseUint(dseq, 10, 64)
+	if err != nil {
+		return false
+	}
+	return n >= f.MinDseq && (f.MaxDseq == 0 || n <= f.MaxDseq)
+}
+
+// IsDeploymentNotFound reports whether err is the chain reporting that a
+// deployment does not exist.
+func IsDeploymentNotFound(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "deployment not found")
+}
+
+func (ak *AkashClient) GetDeployment(ctx context.Context, dseq string, owner string) (types.Deployment, error) {
+	defer ak.begin(ctx, opQuery)()
+	q, err := ak.grpcQuery()
 	if err != nil {
 		return types.Deployment{}, err
 	}
+	return q.Deployment(ak.ctx, owner, dseq, 0)
+}
 
-	return deployment, nil
+// GetDeploymentAtHeight queries a deployment as it was at the given block
+// height, provided the node has not pruned that state yet.
+func (ak *AkashClient) GetDeploymentAtHeight(ctx context.Context, dseq string, owner string, height int64) (types.Deployment, error) {
+	defer ak.begin(ctx, opQuery)()
+	q, err := ak.grpcQuery()
+	if err != nil {
+		return types.Deployment{}, err
+	}
+	return q.Deployment(ak.ctx, owner, dseq, height)
+}
+
+// NewDeposit converts the deposit of a Deployment into a coin, using
+// constants for defaults.
+func NewDeposit(d *resourcev1alpha1.Deposit) types.Coin {
+	deposit := types.Coin{Denom: DefaultDepositDenom, Amount: strconv.Itoa(DefaultDepositAmount)}
+	if d == nil {
+		return deposit
+	}
+	if d.Amount != "" {
+		deposit.Amount = d.Amount
+	}
+	if d.Denom != "" {
+		deposit.Denom = d.Denom
+	}
+	return deposit
 }
 
-func (ak *AkashClient) CreateDeployment(manifestLocation string) (Seqs, error) {
+// CreateDeployment creates a deployment from the SDL at manifestLocation,
+// funding its escrow with deposit. The deposit is taken from the account of
+// depositor when one is given, which must have authorized the owner to spend
+// it, and from the owner otherwise.
+func (ak *AkashClient) CreateDeployment(ctx context.Context, manifestLocation string, deposit types.Coin, depositor string) (Seqs, error) {
+	defer ak.begin(ctx, opTransaction)()
 
-	fmt.Println("Creating deployment")
+	if err := ak.requireAccount(); err != nil {
+		return Seqs{}, err
+	}
+	if err := ak.requireSpendConfirmed(); err != nil {
+		return Seqs{}, err
+	}
+
+	ak.log().Debug("Creating deployment", "deposit", deposit.Amount+deposit.Denom)
 	// Create deployment using the file created with the SDL
-	attributes, err := transactionCreateDeployment(ak, manifestLocation)
+	transaction, err := transactionCreateDeployment(ak, manifestLocation, deposit, depositor)
 	if err != nil {
-		fmt.Print(ak.ctx, "Failed creating deployment")
 		return Seqs{}, err
 	}
 
-	dseq, _ := attributes.Get("dseq")
-	gseq, _ := attributes.Get("gseq")
-	oseq, _ := attributes.Get("oseq")
+	seqs, err := seqsFromTransaction(transaction)
+	if err != nil {
+		return Seqs{}, err
+	}
 
-	fmt.Printf("Deployment created with DSEQ=%s GSEQ=%s OSEQ=%s\n", dseq, gseq, oseq)
+	ak.log().Info("Created deployment", "dseq", seqs.Dseq, "gseq", seqs.Gseq, "oseq", seqs.Oseq, "txhash", transaction.TxHash)
 
-	return Seqs{dseq, gseq, oseq}, nil
+	return seqs, nil
 }
 
-// Perform the transaction to create the deployment and return either the DSEQ or an error.
-func transactionCreateDeployment(ak *AkashClient, manifestLocation string) (types.TransactionEventAttributes, error) {
-	cmd := cli.AkashCli(ak).Tx().Deployment().Create().Manifest(manifestLocation).
-		DefaultGas().AutoAccept().SetFrom(ak.Config.KeyName).SetKeyringBackend(ak.Config.KeyringBackend).
-		SetNote(ak.transactionNote).SetChainId(ak.Config.ChainId).SetNode(ak.Config.Node).OutputJson()
+// Perform the transaction to create the deployment.
+func transactionCreateDeployment(ak *AkashClient, manifestLocation string, deposit types.Coin, depositor string) (types.Transaction, error) {
+	key, owner, err := ak.signer()
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	msg, err := ak.newMsgCreateDeployment(owner, manifestLocation, deposit, depositor)
+	if err != nil {
+		return types.Transaction{}, err
+	}
 
-	transaction := types.Transaction{}
-	if err := cmd.DecodeJson(&transaction); err != nil {
-		return nil, err
+	return ak.signAndBroadcast(ak.ctx, key, owner, msg)
+}
+
+// newMsgCreateDeployment returns the message of owner creating a deployment
+// from the SDL at manifestLocation, funded with deposit taken from depositor,
+// the owner itself when empty. As the Akash CLI does, the deployment is
+// numbered after the latest block height.
+func (ak *AkashClient) newMsgCreateDeployment(owner string, manifestLocation string, deposit types.Coin, depositor string) (tx.MsgCreateDeployment, error) {
+	groups, version, err := readSDL(manifestLocation)
+	if err != nil {
+		return tx.MsgCreateDeployment{}, err
+	}
+	height, err := node.New(ak.Config.Node).LatestHeight(ak.ctx)
+	if err != nil {
+		return tx.MsgCreateDeployment{}, err
+	}
+	if depositor == "" {
+		depositor = owner
+	}
+
+	return tx.MsgCreateDeployment{
+		Owner:     owner,
+		Dseq:      uint64(height),
+		Groups:    groups,
+		Version:   version,
+		Deposit:   tx.Coin{Denom: deposit.Denom, Amount: deposit.Amount},
+		Depositor: depositor,
+	}, nil
+}
+
+// readSDL returns the groups of the SDL at path and the version of their
+// manifest.
+func readSDL(path string) ([]sdl.GroupSpec, []byte, error) {
+	content, err := os.ReadFile(path) //nolint:gosec // The SDL is written by the controller.
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errReadManifest)
+	}
+	groups, manifests, err := sdl.ParseSDL(content)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errBuildManifest)
 	}
+	version, err := sdl.ManifestVersion(manifests)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errBuildManifest)
+	}
+	return groups, version, nil
+}
 
-	if len(transaction.Logs) == 0 {
-		return nil, fmt.Errorf("something went wrong: %s", transaction.RawLog)
+// parseDseq parses the dseq of a deployment.
+func parseDseq(dseq string) (uint64, error) {
+	id, err := strconv.ParseUint(dseq, 10, 64)
+	return id, errors.Wrapf(err, "invalid dseq %q", dseq)
+}
+
+// seqsFromTransaction returns the sequences of the deployment, group and
+// order created by a create deployment transaction, taken from its typed
+// events, or from the legacy events of older chain versions.
+func seqsFromTransaction(transaction types.Transaction) (Seqs, error) {
+	if err := txResult(transaction); err != nil {
+		return Seqs{}, err
 	}
 
-	return transaction.Logs[0].Events[0].Attributes, nil
+	seqs := Seqs{Gseq: "1", Oseq: "1"}
+	for _, e := range transaction.FindEvents(eventDeploymentCreated) {
+		seqs.Dseq = eventID(e, "dseq")
+	}
+	for _, e := range transaction.FindEvents(eventOrderCreated) {
+		if gseq, oseq := eventID(e, "gseq"), eventID(e, "oseq"); gseq != "" && oseq != "" {
+			seqs.Gseq, seqs.Oseq = gseq, oseq
+			break
+		}
+	}
+	if seqs.Dseq != "" {
+		return seqs, nil
+	}
+
+	for _, e := range transaction.FindEvents(eventLegacy) {
+		if action, _ := e.Attributes.Get("action"); action != actionDeploymentCreated {
+			continue
+		}
+		if dseq, err := e.Attributes.Get("dseq"); err == nil {
+			seqs.Dseq = dseq
+			return seqs, nil
+		}
+	}
+	return Seqs{}, errors.Errorf(errNoDeploymentCreated, transaction.TxHash, transaction.RawLog)
 }
 
-func (ak *AkashClient) DeleteDeployment(dseq string, owner string) error {
-	cmd := cli.AkashCli(ak).Tx().Deployment().Close().
-		SetDseq(dseq).SetOwner(owner).SetFrom(ak.Config.KeyName).
-		DefaultGas().SetChainId(ak.Config.ChainId).SetKeyringBackend(ak.Config.KeyringBackend).
-		SetNote(ak.transactionNote).SetNode(ak.Config.Node).AutoAccept().OutputJson()
+// eventID returns a field of the id attribute of a typed event, which holds
+// the JSON encoded id of the created object, e.g. {"owner":"akash1...",
+// "dseq":"42"}. Sequences are encoded as strings or numbers.
+func eventID(e types.TransactionEvent, field string) string {
+	raw, err := e.Attributes.Get("id")
+	if err != nil {
+		return ""
+	}
+	id := map[string]json.RawMessage{}
+	if err := json.Unmarshal([]byte(raw), &id); err != nil {
+		return ""
+	}
+	return strings.Trim(string(id[field]), `"`)
+}
 
-	out, err := cmd.Raw()
+// SimulateCreateDeployment simulates the creation of a deployment from the
+// given manifest without broadcasting it.
+func (ak *AkashClient) SimulateCreateDeployment(ctx context.Context, manifestLocation string) error {
+	defer ak.begin(ctx, opTransaction)()
+	key, owner, err := ak.signer()
 	if err != nil {
 		return err
 	}
+	msg, err := ak.newMsgCreateDeployment(owner, manifestLocation, NewDeposit(nil), "")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = ak.simulated(ak.ctx, node.New(ak.Config.Node), key, owner, msg)
+	return err
+}
+
+func (ak *AkashClient) DeleteDeployment(ctx context.Context, dseq string, owner string) error {
+	defer ak.begin(ctx, opTransaction)()
+	if err := ak.requireAccount(); err != nil {
+		return err
+	}
+
+	return withDeploymentLock(owner, dseq, func() error {
+		state, err := ak.deploymentState(dseq, owner)
+		if err != nil {
+			return err
+		}
+		if state == types.DeploymentStateClosed {
+			// Already closed, e.g. by a concurrent Delete. Nothing to pay for.
+			return nil
+		}
+
+		id, err := parseDseq(dseq)
+		if err != nil {
+			return err
+		}
+		key, signer, err := ak.signer()
+		if err != nil {
+			return err
+		}
+
+		transaction, err := ak.signAndBroadcast(ak.ctx, key, signer, tx.MsgCloseDeployment{Owner: owner, Dseq: id})
+		if err != nil {
+			return err
+		}
 
-	fmt.Printf("Response: %s\n", out)
+		ak.log().Info("Closed deployment", "dseq", dseq, "txhash", transaction.TxHash, "height", transaction.Height)
 
-	return nil
+		return nil
+	})
 }
 
-func (ak *AkashClient) UpdateDeployment(dseq string, manifestLocation string) error {
-	cmd := cli.AkashCli(ak).Tx().Deployment().Update().Manifest(manifestLocation).
-		SetDseq(dseq).SetFrom(ak.Config.KeyName).SetNode(ak.Config.Node).
-		SetNote(ak.transactionNote).SetKeyringBackend(ak.Config.KeyringBackend).SetChainId(ak.Config.ChainId).
-		GasAuto().SetGasAdjustment(1.5).SetGasPrices().SetSignMode("amino-json").AutoAccept().OutputJson()
+// DepositDeployment deposits amount into the escrow of the deployment dseq,
+// sending a MsgDepositDeployment.
+func (ak *AkashClient) DepositDeployment(ctx context.Context, dseq string, owner string, amount types.Coin) error {
+	defer ak.begin(ctx, opTransaction)()
+	if err := ak.requireAccount(); err != nil {
+		return err
+	}
+	if err := ak.requireSpendConfirmed(); err != nil {
+		return err
+	}
 
-	out, err := cmd.Raw()
-	if err != nil {
+	return withDeploymentLock(owner, dseq, func() error {
+		id, err := parseDseq(dseq)
+		if err != nil {
+			return err
+		}
+		key, depositor, err := ak.signer()
+		if err != nil {
+			return err
+		}
+
+		msg := tx.MsgDepositDeployment{Owner: owner, Dseq: id, Amount: tx.Coin{Denom: amount.Denom, Amount: amount.Amount}, Depositor: depositor}
+		transaction, err := ak.signAndBroadcast(ak.ctx, key, depositor, msg)
+		if err != nil {
+			return err
+		}
+
+		ak.log().Info("Deposited into deployment", "dseq", dseq, "amount", amount.Amount+amount.Denom, "txhash", transaction.TxHash)
+
+		return nil
+	})
+}
+
+func (ak *AkashClient) UpdateDeployment(ctx context.Context, dseq string, manifestLocation string) error {
+	defer ak.begin(ctx, opTransaction)()
+	if err := ak.requireAccount(); err != nil {
 		return err
 	}
 
-	fmt.Printf("Response: %s\n", out)
+	return withDeploymentLock(ak.Config.AccountAddress, dseq, func() error {
+		if err := ak.requireActiveDeployment(dseq, ak.Config.AccountAddress); err != nil {
+			return err
+		}
+
+		id, err := parseDseq(dseq)
+		if err != nil {
+			return err
+		}
+		_, version, err := readSDL(manifestLocation)
+		if err != nil {
+			return err
+		}
+		key, owner, err := ak.signer()
+		if err != nil {
+			return err
+		}
+
+		transaction, err := ak.signAndBroadcast(ak.ctx, key, owner, tx.MsgUpdateDeployment{Owner: owner, Dseq: id, Version: version})
+		if err != nil {
+			return err
+		}
+
+		ak.log().Info("Updated deployment", "dseq", dseq, "txhash", transaction.TxHash, "height", transaction.Height)
 
-	return nil
+		return nil
+	})
 }
@@ -0,0 +1,183 @@
+// Package events subscribes to the typed events Akash transactions emit over
+// the Tendermint WebSocket of a node.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/websocket"
+)
+
+// Types of the events subscribed to. Their id attribute holds the JSON
+// encoded id of the deployment, lease or bid, e.g. {"owner":"akash1...",
+// "dseq":"42"}.
+const (
+	TypeDeploymentCreated = "akash.deployment.v1.EventDeploymentCreated"
+	TypeDeploymentClosed  = "akash.deployment.v1.EventDeploymentClosed"
+	TypeLeaseCreated      = "akash.market.v1.EventLeaseCreated"
+	TypeLeaseClosed       = "akash.market.v1.EventLeaseClosed"
+	TypeBidCreated        = "akash.market.v1.EventBidCreated"
+)
+
+// Types are the types of every event subscribed to. Nodes accept five
+// subscriptions per client by default, one per type.
+var Types = []string{TypeDeploymentCreated, TypeDeploymentClosed, TypeLeaseCreated, TypeLeaseClosed, TypeBidCreated}
+
+// IdleTimeout is how long a connection may go without events before it is
+// considered dead. Pings of the node are answered without surfacing, so a
+// quiet chain merely causes a reconnect.
+var IdleTimeout = 10 * time.Minute
+
+// An Event is a typed event of a transaction concerning a deployment.
+type Event struct {
+	// Type of the event, e.g. TypeLeaseCreated.
+	Type string
+
+	// Owner and Dseq of the deployment.
+	Owner string
+	Dseq  string
+
+	// Provider of the lease or bid, empty for deployment events.
+	Provider string
+
+	// Height of the block including the transaction.
+	Height string
+}
+
+type request struct {
+	JSONRPC string         `json:"jsonrpc"`
+	ID      int            `json:"id"`
+	Method  string         `json:"method"`
+	Params  map[string]any `json:"params"`
+}
+
+type response struct {
+	ID     int `json:"id"`
+	Result struct {
+		Query  string              `json:"query"`
+		Events map[string][]string `json:"events"`
+	} `json:"result"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    string `json:"data"`
+	} `json:"error"`
+}
+
+// Subscribe subscribes to the events of Types at the Tendermint RPC endpoint
+// node, e.g. https://rpc.akashnet.net:443, and passes every event received to
+// handle. It blocks until ctx is done, in which case it returns nil, or the
+// connection fails.
+func Subscribe(ctx context.Context, node string, handle func(Event)) error {
+	addr, origin, err := websocketURL(node)
+	if err != nil {
+		return err
+	}
+	cfg, err := websocket.NewConfig(addr, origin)
+	if err != nil {
+		return errors.Wrapf(err, "invalid node %s", node)
+	}
+	conn, err := cfg.DialContext(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "cannot connect to %s", addr)
+	}
+	defer conn.Close() //nolint:errcheck // Nothing is left to flush.
+
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	for i, typ := range Types {
+		req := request{
+			JSONRPC: "2.0",
+			ID:      i,
+			Method:  "subscribe",
+			Params:  map[string]any{"query": Query(typ)},
+		}
+		if err := websocket.JSON.Send(conn, req); err != nil {
+			return errors.Wrapf(err, "cannot subscribe to %s", typ)
+		}
+	}
+
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(IdleTimeout)); err != nil {
+			return err
+		}
+		res := response{}
+		if err := websocket.JSON.Receive(conn, &res); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return errors.Wrapf(err, "cannot receive events from %s", addr)
+		}
+		if res.Error != nil {
+			return errors.Errorf("cannot subscribe to %s: %s %s", Types[res.ID%len(Types)], res.Error.Message, res.Error.Data)
+		}
+		for _, e := range Parse(res.Result.Events) {
+			handle(e)
+		}
+	}
+}
+
+// Query returns the query of transactions emitting events of typ.
+func Query(typ string) string {
+	return "tm.event='Tx' AND " + typ + ".id EXISTS"
+}
+
+// Parse returns the events of Types among the events of a transaction, keyed
+// by <type>.<attribute> as the node sends them.
+func Parse(attrs map[string][]string) []Event {
+	var height string
+	if h := attrs["tx.height"]; len(h) > 0 {
+		height = h[0]
+	}
+
+	var out []Event
+	for _, typ := range Types {
+		for _, raw := range attrs[typ+".id"] {
+			id := map[string]json.RawMessage{}
+			if err := json.Unmarshal([]byte(raw), &id); err != nil {
+				continue
+			}
+			e := Event{
+				Type:     typ,
+				Owner:    field(id, "owner"),
+				Dseq:     field(id, "dseq"),
+				Provider: field(id, "provider"),
+				Height:   height,
+			}
+			if e.Dseq != "" {
+				out = append(out, e)
+			}
+		}
+	}
+	return out
+}
+
+// field returns a field of an id. Sequences are encoded as strings or
+// numbers.
+func field(id map[string]json.RawMessage, name string) string {
+	return strings.Trim(string(id[name]), `"`)
+}
+
+// websocketURL returns the WebSocket endpoint of the Tendermint RPC endpoint
+// node and the origin to connect with.
+func websocketURL(node string) (string, string, error) {
+	u, err := url.Parse(node)
+	if err != nil || u.Host == "" {
+		return "", "", errors.Errorf("invalid node %q", node)
+	}
+	switch u.Scheme {
+	case "https", "wss":
+		u.Scheme = "wss"
+	default:
+		u.Scheme = "ws"
+	}
+	origin := "http://" + u.Host
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/websocket"
+	return u.String(), origin, nil
+}
@@ -0,0 +1,172 @@
+package events
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/net/websocket"
+)
+
+func TestParse(t *testing.T) {
+	cases := map[string]struct {
+		attrs map[string][]string
+		want  []Event
+	}{
+		"DeploymentCreated": {
+			attrs: map[string][]string{
+				"tm.event":                    {"Tx"},
+				"tx.height":                   {"100"},
+				TypeDeploymentCreated + ".id": {`{"owner":"akash1owner","dseq":"42"}`},
+			},
+			want: []Event{{Type: TypeDeploymentCreated, Owner: "akash1owner", Dseq: "42", Height: "100"}},
+		},
+		"Leases": {
+			attrs: map[string][]string{
+				TypeLeaseCreated + ".id": {
+					`{"owner":"akash1owner","dseq":42,"gseq":1,"oseq":1,"provider":"akash1a"}`,
+					`{"owner":"akash1owner","dseq":43,"gseq":1,"oseq":1,"provider":"akash1b"}`,
+				},
+				TypeLeaseClosed + ".id": {`{"owner":"akash1owner","dseq":"41","gseq":1,"oseq":1,"provider":"akash1a"}`},
+			},
+			want: []Event{
+				{Type: TypeLeaseCreated, Owner: "akash1owner", Dseq: "42", Provider: "akash1a"},
+				{Type: TypeLeaseCreated, Owner: "akash1owner", Dseq: "43", Provider: "akash1b"},
+				{Type: TypeLeaseClosed, Owner: "akash1owner", Dseq: "41", Provider: "akash1a"},
+			},
+		},
+		"Malformed": {
+			attrs: map[string][]string{
+				TypeBidCreated + ".id":       {`not json`},
+				TypeDeploymentClosed + ".id": {`{"owner":"akash1owner"}`},
+			},
+		},
+		"Unrelated": {
+			attrs: map[string][]string{
+				"akash.cert.v1.EventCertificateCreated.id": {`{"owner":"akash1owner","serial":"1"}`},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Parse(tc.attrs)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Parse(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWebsocketURL(t *testing.T) {
+	cases := map[string]struct {
+		node   string
+		want   string
+		origin string
+		err    bool
+	}{
+		"HTTPS":   {node: "https://rpc.akashnet.net:443", want: "wss://rpc.akashnet.net:443/websocket", origin: "http://rpc.akashnet.net:443"},
+		"HTTP":    {node: "http://localhost:26657/", want: "ws://localhost:26657/websocket", origin: "http://localhost:26657"},
+		"TCP":     {node: "tcp://localhost:26657", want: "ws://localhost:26657/websocket", origin: "http://localhost:26657"},
+		"Path":    {node: "https://example.com/rpc", want: "wss://example.com/rpc/websocket", origin: "http://example.com"},
+		"NoHost":  {node: "localhost", err: true},
+		"Invalid": {node: "://", err: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, origin, err := websocketURL(tc.node)
+			if (err != nil) != tc.err {
+				t.Fatalf("websocketURL(%q): unexpected error %v", tc.node, err)
+			}
+			if got != tc.want || origin != tc.origin {
+				t.Errorf("websocketURL(%q): want %s %s, got %s %s", tc.node, tc.want, tc.origin, got, origin)
+			}
+		})
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	queries := make(chan string, len(Types))
+	srv := httptest.NewServer(websocket.Handler(func(conn *websocket.Conn) {
+		for range Types {
+			req := request{}
+			if err := websocket.JSON.Receive(conn, &req); err != nil {
+				return
+			}
+			queries <- req.Params["query"].(string)
+			_ = websocket.JSON.Send(conn, map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": map[string]any{}})
+		}
+		_ = websocket.JSON.Send(conn, map[string]any{
+			"jsonrpc": "2.0",
+			"id":      0,
+			"result": map[string]any{
+				"query": Query(TypeDeploymentCreated),
+				"events": map[string][]string{
+					"tx.height":                   {"7"},
+					TypeDeploymentCreated + ".id": {`{"owner":"akash1owner","dseq":"42"}`},
+				},
+			},
+		})
+		// Keep the connection open until the client goes away.
+		_ = websocket.JSON.Receive(conn, &request{})
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var got []Event
+	err := Subscribe(ctx, srv.URL, func(e Event) {
+		got = append(got, e)
+		cancel()
+	})
+	if err != nil {
+		t.Fatalf("Subscribe(...): %v", err)
+	}
+
+	want := []Event{{Type: TypeDeploymentCreated, Owner: "akash1owner", Dseq: "42", Height: "7"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Subscribe(...): -want, +got:\n%s", diff)
+	}
+	close(queries)
+	var subscribed []string
+	for q := range queries {
+		subscribed = append(subscribed, q)
+	}
+	wantQueries := []string{
+		"tm.event='Tx' AND akash.deployment.v1.EventDeploymentCreated.id EXISTS",
+		"tm.event='Tx' AND akash.deployment.v1.EventDeploymentClosed.id EXISTS",
+		"tm.event='Tx' AND akash.market.v1.EventLeaseCreated.id EXISTS",
+		"tm.event='Tx' AND akash.market.v1.EventLeaseClosed.id EXISTS",
+		"tm.event='Tx' AND akash.market.v1.EventBidCreated.id EXISTS",
+	}
+	if diff := cmp.Diff(wantQueries, subscribed); diff != "" {
+		t.Errorf("Subscribe(...): queries: -want, +got:\n%s", diff)
+	}
+}
+
+func TestSubscribeError(t *testing.T) {
+	srv := httptest.NewServer(websocket.Handler(func(conn *websocket.Conn) {
+		req := request{}
+		if err := websocket.JSON.Receive(conn, &req); err != nil {
+			return
+		}
+		_ = websocket.JSON.Send(conn, map[string]any{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"error":   map[string]any{"code": -32603, "message": "Internal error", "data": "max_subscriptions_per_client 5 reached"},
+		})
+		_ = websocket.JSON.Receive(conn, &request{})
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := Subscribe(ctx, srv.URL, func(Event) {}); err == nil {
+		t.Errorf("Subscribe(...): want error, got nil")
+	}
+}
@@ -0,0 +1,392 @@
+// Package fake implements the AkashAPI with programmable responses, so that
+// controllers can be tested without a chain.
+package fake
+
+import (
+	"context"
+	"time"
+
+	"github.com/overlock-network/provider-akash/internal/client"
+	gateway "github.com/overlock-network/provider-akash/internal/client/provider-gateway"
+	"github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+var _ client.AkashAPI = &Client{}
+
+// A Client is a fake AkashAPI. Each method calls the function of the same
+// name prefixed with Mock, and returns zero values when it is not set.
+type Client struct {
+	MockPreflight                  func(context.Context, types.Coin) error
+	MockCreateDeployment           func(context.Context, string, types.Coin, string) (client.Seqs, error)
+	MockUpdateDeployment           func(context.Context, string, string) error
+	MockDepositDeployment          func(context.Context, string, string, types.Coin) error
+	MockDeleteDeployment           func(context.Context, string, string) error
+	MockGetDeployment              func(context.Context, string, string) (types.Deployment, error)
+	MockReadDeployment             func(context.Context, string, string) (types.Deployment, error)
+	MockGetDeploymentAtHeight      func(context.Context, string, string, int64) (types.Deployment, error)
+	MockListDeployments            func(context.Context, client.ListOptions) ([]types.Deployment, uint64, error)
+	MockQueryBids                  func(context.Context, client.Seqs) (types.Bids, error)
+	MockLeaseFirstOpenBid          func(context.Context, client.Seqs, types.Bids) (types.Bid, error)
+	MockRecordBidPrices            func(types.Bids, time.Time) error
+	MockBlacklistProvider          func(string, string) error
+	MockBlacklistedProviders       func() (map[string]string, error)
+	MockGetLeases                  func(context.Context, string, string) ([]types.Lease, error)
+	MockGetActiveLeases            func(context.Context, string, string) ([]types.Lease, error)
+	MockReadActiveLeases           func(context.Context, string, string) ([]types.Lease, error)
+	MockGetLeasesAtHeight          func(context.Context, string, string, int64) ([]types.Lease, error)
+	MockGetLeaseStatus             func(context.Context, types.LeaseId) (types.LeaseStatus, error)
+	MockStreamLeaseLogs            func(context.Context, client.Seqs, string, gateway.LogOptions, func(gateway.LogLine) error) error
+	MockMigrateHostname            func(context.Context, string, types.LeaseId) error
+	MockGetManifest                func(context.Context, string, string) ([]byte, error)
+	MockSubmitManifest             func(context.Context, string, string, []byte) error
+	MockSendManifestWithRetry      func(context.Context, string, string, string, client.ManifestDeliveryPolicy) (client.ManifestDeliveryResult, error)
+	MockGetCertificates            func(context.Context, string) (types.Certificates, error)
+	MockGetProvider                func(context.Context, string) (types.ProviderRecord, error)
+	MockGetProviders               func(context.Context) ([]types.Provider, error)
+	MockGetProviderAuditors        func(context.Context, string) ([]string, error)
+	MockGetProviderAudits          func(context.Context, string) ([]types.AuditedAttributes, error)
+	MockProviderVersion            func(context.Context, string, string) (string, error)
+	MockAccountAddress             func() (string, error)
+	MockGetSpendableBalances       func(context.Context, string) (types.Balances, error)
+	MockGetDelegations             func(context.Context, string) ([]types.DelegationResponse, error)
+	MockGetFeeGrant                func(context.Context, string, string) (types.FeeGrant, error)
+	MockGrantFeeAllowance          func(context.Context, string, client.FeeAllowance) error
+	MockRevokeFeeAllowance         func(context.Context, string) error
+	MockGetDepositAuthorization    func(context.Context, string, string) (types.AuthzGrant, error)
+	MockGrantDepositAuthorization  func(context.Context, string, types.Coin, time.Time) error
+	MockRevokeDepositAuthorization func(context.Context, string) error
+
+	// Config is returned by Configuration.
+	Config client.AkashProviderConfiguration
+	// TransactionOptions are the options last set by SetTransactionOptions.
+	TransactionOptions client.TransactionOptions
+	// Fallback is returned by ObservedViaFallback.
+	Fallback bool
+}
+
+// Preflight calls MockPreflight.
+func (c *Client) Preflight(ctx context.Context, deposit types.Coin) error {
+	if c.MockPreflight == nil {
+		return nil
+	}
+	return c.MockPreflight(ctx, deposit)
+}
+
+// CreateDeployment calls MockCreateDeployment.
+func (c *Client) CreateDeployment(ctx context.Context, manifestLocation string, deposit types.Coin, depositor string) (client.Seqs, error) {
+	if c.MockCreateDeployment == nil {
+		return client.Seqs{}, nil
+	}
+	return c.MockCreateDeployment(ctx, manifestLocation, deposit, depositor)
+}
+
+// UpdateDeployment calls MockUpdateDeployment.
+func (c *Client) UpdateDeployment(ctx context.Context, dseq string, manifestLocation string) error {
+	if c.MockUpdateDeployment == nil {
+		return nil
+	}
+	return c.MockUpdateDeployment(ctx, dseq, manifestLocation)
+}
+
+// DepositDeployment calls MockDepositDeployment.
+func (c *Client) DepositDeployment(ctx context.Context, dseq string, owner string, amount types.Coin) error {
+	if c.MockDepositDeployment == nil {
+		return nil
+	}
+	return c.MockDepositDeployment(ctx, dseq, owner, amount)
+}
+
+// DeleteDeployment calls MockDeleteDeployment.
+func (c *Client) DeleteDeployment(ctx context.Context, dseq string, owner string) error {
+	if c.MockDeleteDeployment == nil {
+		return nil
+	}
+	return c.MockDeleteDeployment(ctx, dseq, owner)
+}
+
+// GetDeployment calls MockGetDeployment.
+func (c *Client) GetDeployment(ctx context.Context, dseq string, owner string) (types.Deployment, error) {
+	if c.MockGetDeployment == nil {
+		return types.Deployment{}, nil
+	}
+	return c.MockGetDeployment(ctx, dseq, owner)
+}
+
+// ReadDeployment calls MockReadDeployment.
+func (c *Client) ReadDeployment(ctx context.Context, dseq string, owner string) (types.Deployment, error) {
+	if c.MockReadDeployment == nil {
+		return types.Deployment{}, nil
+	}
+	return c.MockReadDeployment(ctx, dseq, owner)
+}
+
+// GetDeploymentAtHeight calls MockGetDeploymentAtHeight.
+func (c *Client) GetDeploymentAtHeight(ctx context.Context, dseq string, owner string, height int64) (types.Deployment, error) {
+	if c.MockGetDeploymentAtHeight == nil {
+		return types.Deployment{}, nil
+	}
+	return c.MockGetDeploymentAtHeight(ctx, dseq, owner, height)
+}
+
+// ListDeployments calls MockListDeployments.
+func (c *Client) ListDeployments(ctx context.Context, o client.ListOptions) ([]types.Deployment, uint64, error) {
+	if c.MockListDeployments == nil {
+		return nil, 0, nil
+	}
+	return c.MockListDeployments(ctx, o)
+}
+
+// QueryBids calls MockQueryBids.
+func (c *Client) QueryBids(ctx context.Context, seqs client.Seqs) (types.Bids, error) {
+	if c.MockQueryBids == nil {
+		return nil, nil
+	}
+	return c.MockQueryBids(ctx, seqs)
+}
+
+// LeaseFirstOpenBid calls MockLeaseFirstOpenBid.
+func (c *Client) LeaseFirstOpenBid(ctx context.Context, seqs client.Seqs, ranked types.Bids) (types.Bid, error) {
+	if c.MockLeaseFirstOpenBid == nil {
+		return types.Bid{}, nil
+	}
+	return c.MockLeaseFirstOpenBid(ctx, seqs, ranked)
+}
+
+// RecordBidPrices calls MockRecordBidPrices.
+func (c *Client) RecordBidPrices(bids types.Bids, at time.Time) error {
+	if c.MockRecordBidPrices == nil {
+		return nil
+	}
+	return c.MockRecordBidPrices(bids, at)
+}
+
+// BlacklistProvider calls MockBlacklistProvider.
+func (c *Client) BlacklistProvider(provider string, reason string) error {
+	if c.MockBlacklistProvider == nil {
+		return nil
+	}
+	return c.MockBlacklistProvider(provider, reason)
+}
+
+// BlacklistedProviders calls MockBlacklistedProviders.
+func (c *Client) BlacklistedProviders() (map[string]string, error) {
+	if c.MockBlacklistedProviders == nil {
+		return nil, nil
+	}
+	return c.MockBlacklistedProviders()
+}
+
+// GetLeases calls MockGetLeases.
+func (c *Client) GetLeases(ctx context.Context, dseq string, owner string) ([]types.Lease, error) {
+	if c.MockGetLeases == nil {
+		return nil, nil
+	}
+	return c.MockGetLeases(ctx, dseq, owner)
+}
+
+// GetActiveLeases calls MockGetActiveLeases.
+func (c *Client) GetActiveLeases(ctx context.Context, dseq string, owner string) ([]types.Lease, error) {
+	if c.MockGetActiveLeases == nil {
+		return nil, nil
+	}
+	return c.MockGetActiveLeases(ctx, dseq, owner)
+}
+
+// ReadActiveLeases calls MockReadActiveLeases.
+func (c *Client) ReadActiveLeases(ctx context.Context, dseq string, owner string) ([]types.Lease, error) {
+	if c.MockReadActiveLeases == nil {
+		return nil, nil
+	}
+	return c.MockReadActiveLeases(ctx, dseq, owner)
+}
+
+// GetLeasesAtHeight calls MockGetLeasesAtHeight.
+func (c *Client) GetLeasesAtHeight(ctx context.Context, dseq string, owner string, height int64) ([]types.Lease, error) {
+	if c.MockGetLeasesAtHeight == nil {
+		return nil, nil
+	}
+	return c.MockGetLeasesAtHeight(ctx, dseq, owner, height)
+}
+
+// GetLeaseStatus calls MockGetLeaseStatus.
+func (c *Client) GetLeaseStatus(ctx context.Context, id types.LeaseId) (types.LeaseStatus, error) {
+	if c.MockGetLeaseStatus == nil {
+		return types.LeaseStatus{}, nil
+	}
+	return c.MockGetLeaseStatus(ctx, id)
+}
+
+// StreamLeaseLogs calls MockStreamLeaseLogs.
+func (c *Client) StreamLeaseLogs(ctx context.Context, seqs client.Seqs, provider string, opts gateway.LogOptions, fn func(gateway.LogLine) error) error {
+	if c.MockStreamLeaseLogs == nil {
+		return nil
+	}
+	return c.MockStreamLeaseLogs(ctx, seqs, provider, opts, fn)
+}
+
+// MigrateHostname calls MockMigrateHostname.
+func (c *Client) MigrateHostname(ctx context.Context, hostname string, id types.LeaseId) error {
+	if c.MockMigrateHostname == nil {
+		return nil
+	}
+	return c.MockMigrateHostname(ctx, hostname, id)
+}
+
+// GetManifest calls MockGetManifest.
+func (c *Client) GetManifest(ctx context.Context, dseq string, provider string) ([]byte, error) {
+	if c.MockGetManifest == nil {
+		return nil, nil
+	}
+	return c.MockGetManifest(ctx, dseq, provider)
+}
+
+// SubmitManifest calls MockSubmitManifest.
+func (c *Client) SubmitManifest(ctx context.Context, dseq string, provider string, content []byte) error {
+	if c.MockSubmitManifest == nil {
+		return nil
+	}
+	return c.MockSubmitManifest(ctx, dseq, provider, content)
+}
+
+// SendManifestWithRetry calls MockSendManifestWithRetry.
+func (c *Client) SendManifestWithRetry(ctx context.Context, dseq string, provider string, manifestLocation string, policy client.ManifestDeliveryPolicy) (client.ManifestDeliveryResult, error) {
+	if c.MockSendManifestWithRetry == nil {
+		return client.ManifestDeliveryResult{}, nil
+	}
+	return c.MockSendManifestWithRetry(ctx, dseq, provider, manifestLocation, policy)
+}
+
+// GetCertificates calls MockGetCertificates.
+func (c *Client) GetCertificates(ctx context.Context, owner string) (types.Certificates, error) {
+	if c.MockGetCertificates == nil {
+		return types.Certificates{}, nil
+	}
+	return c.MockGetCertificates(ctx, owner)
+}
+
+// GetProvider calls MockGetProvider.
+func (c *Client) GetProvider(ctx context.Context, address string) (types.ProviderRecord, error) {
+	if c.MockGetProvider == nil {
+		return types.ProviderRecord{}, nil
+	}
+	return c.MockGetProvider(ctx, address)
+}
+
+// GetProviders calls MockGetProviders.
+func (c *Client) GetProviders(ctx context.Context) ([]types.Provider, error) {
+	if c.MockGetProviders == nil {
+		return nil, nil
+	}
+	return c.MockGetProviders(ctx)
+}
+
+// GetProviderAuditors calls MockGetProviderAuditors.
+func (c *Client) GetProviderAuditors(ctx context.Context, provider string) ([]string, error) {
+	if c.MockGetProviderAuditors == nil {
+		return nil, nil
+	}
+	return c.MockGetProviderAuditors(ctx, provider)
+}
+
+// GetProviderAudits calls MockGetProviderAudits.
+func (c *Client) GetProviderAudits(ctx context.Context, provider string) ([]types.AuditedAttributes, error) {
+	if c.MockGetProviderAudits == nil {
+		return nil, nil
+	}
+	return c.MockGetProviderAudits(ctx, provider)
+}
+
+// ProviderVersion calls MockProviderVersion.
+func (c *Client) ProviderVersion(ctx context.Context, provider string, hostURI string) (string, error) {
+	if c.MockProviderVersion == nil {
+		return "", nil
+	}
+	return c.MockProviderVersion(ctx, provider, hostURI)
+}
+
+// AccountAddress calls MockAccountAddress.
+func (c *Client) AccountAddress() (string, error) {
+	if c.MockAccountAddress == nil {
+		return "", nil
+	}
+	return c.MockAccountAddress()
+}
+
+// GetSpendableBalances calls MockGetSpendableBalances.
+func (c *Client) GetSpendableBalances(ctx context.Context, address string) (types.Balances, error) {
+	if c.MockGetSpendableBalances == nil {
+		return types.Balances{}, nil
+	}
+	return c.MockGetSpendableBalances(ctx, address)
+}
+
+// GetDelegations calls MockGetDelegations.
+func (c *Client) GetDelegations(ctx context.Context, address string) ([]types.DelegationResponse, error) {
+	if c.MockGetDelegations == nil {
+		return nil, nil
+	}
+	return c.MockGetDelegations(ctx, address)
+}
+
+// GetFeeGrant calls MockGetFeeGrant.
+func (c *Client) GetFeeGrant(ctx context.Context, granter string, grantee string) (types.FeeGrant, error) {
+	if c.MockGetFeeGrant == nil {
+		return types.FeeGrant{}, nil
+	}
+	return c.MockGetFeeGrant(ctx, granter, grantee)
+}
+
+// GrantFeeAllowance calls MockGrantFeeAllowance.
+func (c *Client) GrantFeeAllowance(ctx context.Context, grantee string, allowance client.FeeAllowance) error {
+	if c.MockGrantFeeAllowance == nil {
+		return nil
+	}
+	return c.MockGrantFeeAllowance(ctx, grantee, allowance)
+}
+
+// RevokeFeeAllowance calls MockRevokeFeeAllowance.
+func (c *Client) RevokeFeeAllowance(ctx context.Context, grantee string) error {
+	if c.MockRevokeFeeAllowance == nil {
+		return nil
+	}
+	return c.MockRevokeFeeAllowance(ctx, grantee)
+}
+
+// GetDepositAuthorization calls MockGetDepositAuthorization.
+func (c *Client) GetDepositAuthorization(ctx context.Context, granter string, grantee string) (types.AuthzGrant, error) {
+	if c.MockGetDepositAuthorization == nil {
+		return types.AuthzGrant{}, nil
+	}
+	return c.MockGetDepositAuthorization(ctx, granter, grantee)
+}
+
+// GrantDepositAuthorization calls MockGrantDepositAuthorization.
+func (c *Client) GrantDepositAuthorization(ctx context.Context, grantee string, spendLimit types.Coin, expiration time.Time) error {
+	if c.MockGrantDepositAuthorization == nil {
+		return nil
+	}
+	return c.MockGrantDepositAuthorization(ctx, grantee, spendLimit, expiration)
+}
+
+// RevokeDepositAuthorization calls MockRevokeDepositAuthorization.
+func (c *Client) RevokeDepositAuthorization(ctx context.Context, grantee string) error {
+	if c.MockRevokeDepositAuthorization == nil {
+		return nil
+	}
+	return c.MockRevokeDepositAuthorization(ctx, grantee)
+}
+
+// Configuration returns Config.
+func (c *Client) Configuration() client.AkashProviderConfiguration {
+	return c.Config
+}
+
+// SetTransactionOptions records o in TransactionOptions.
+func (c *Client) SetTransactionOptions(o client.TransactionOptions) {
+	c.TransactionOptions = o
+}
+
+// ObservedViaFallback returns Fallback.
+func (c *Client) ObservedViaFallback() bool {
+	return c.Fallback
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/overlock-network/provider-akash/internal/client/indexer"
+	"github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+// nodeUnavailable are fragments of the errors reported when the node cannot
+// be reached.
+var nodeUnavailable = []string{
+	"connection refused",
+	"no such host",
+	"i/o timeout",
+	"context deadline exceeded",
+	"connection reset by peer",
+	"post failed",
+	"503 service unavailable",
+}
+
+// IsNodeUnavailable reports whether err was caused by the node being
+// unreachable, rather than by the node answering the query with an error.
+func IsNodeUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, s := range nodeUnavailable {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// ObservedViaFallback reports whether a read of the client was served by the
+// indexer because the node was unavailable.
+func (ak *AkashClient) ObservedViaFallback() bool {
+	return ak.observedViaFallback
+}
+
+// ReadDeployment queries a deployment like GetDeployment, but falls back to
+// the indexer API when the node is unavailable and one is configured. The
+// result must only be used for observation, never to decide on transactions.
+func (ak *AkashClient) ReadDeployment(ctx context.Context, dseq string, owner string) (types.Deployment, error) {
+	defer ak.begin(ctx, opQuery)()
+	deployment, err := ak.GetDeployment(ak.ctx, dseq, owner)
+	if !ak.useFallback(err) {
+		return deployment, err
+	}
+
+	deployment, _, ferr := indexer.New(ak.Config.IndexerApi).GetDeployment(ak.ctx, owner, dseq)
+	if ferr != nil {
+		return types.Deployment{}, errors.Wrapf(err, "cannot fall back to indexer: %v", ferr)
+	}
+	ak.observedViaFallback = true
+	return deployment, nil
+}
+
+// ReadActiveLeases queries the active leases of a deployment like
+// GetActiveLeases, but falls back to the indexer API when the node is
+// unavailable and one is configured. The result must only be used for
+// observation, never to decide on transactions.
+func (ak *AkashClient) ReadActiveLeases(ctx context.Context, dseq string, owner string) ([]types.Lease, error) {
+	defer ak.begin(ctx, opQuery)()
+	leases, err := ak.GetActiveLeases(ak.ctx, dseq, owner)
+	if !ak.useFallback(err) {
+		return leases, err
+	}
+
+	_, all, ferr := indexer.New(ak.Config.IndexerApi).GetDeployment(ak.ctx, owner, dseq)
+	if ferr != nil {
+		return nil, errors.Wrapf(err, "cannot fall back to indexer: %v", ferr)
+	}
+	ak.observedViaFallback = true
+
+	active := make([]types.Lease, 0, len(all))
+	for _, l := range all {
+		if l.Lease.State == types.LeaseStateActive {
+			active = append(active, l)
+		}
+	}
+	return active, nil
+}
+
+func (ak *AkashClient) useFallback(err error) bool {
+	return ak.Config.IndexerApi != "" && IsNodeUnavailable(err)
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/overlock-network/provider-akash/internal/client/tx"
+	"github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+// A FeeAllowance is what a fee grant allows its grantee to spend on fees.
+type FeeAllowance struct {
+	// SpendLimit are the coins the grantee may spend, e.g. 5000000uakt.
+	// Unlimited when empty.
+	SpendLimit string
+	// Expiration is when the grant expires. Never when nil.
+	Expiration *time.Time
+	// AllowedMessages restricts the grant to the given message types.
+	AllowedMessages []string
+}
+
+// IsFeeGrantNotFound reports whether err is the chain reporting that a fee
+// grant does not exist.
+func IsFeeGrantNotFound(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "not found")
+}
+
+// GetFeeGrant queries the fee allowance granter granted grantee.
+func (ak *AkashClient) GetFeeGrant(ctx context.Context, granter string, grantee string) (types.FeeGrant, error) {
+	defer ak.begin(ctx, opQuery)()
+	q, err := ak.grpcQuery()
+	if err != nil {
+		return types.FeeGrant{}, err
+	}
+	return q.FeeAllowance(ak.ctx, granter, grantee)
+}
+
+// GrantFeeAllowance grants grantee an allowance to pay the fees of its
+// transactions from the configured account, sending a MsgGrantAllowance.
+func (ak *AkashClient) GrantFeeAllowance(ctx context.Context, grantee string, allowance FeeAllowance) error {
+	defer ak.begin(ctx, opTransaction)()
+	if err := ak.requireAccount(); err != nil {
+		return err
+	}
+	if err := ak.requireSpendConfirmed(); err != nil {
+		return err
+	}
+
+	key, granter, err := ak.signer()
+	if err != nil {
+		return err
+	}
+	msg := tx.MsgGrantAllowance{Granter: granter, Grantee: grantee, AllowedMessages: allowance.AllowedMessages}
+	if allowance.SpendLimit != "" {
+		if msg.SpendLimit, err = parseCoins(allowance.SpendLimit); err != nil {
+			return err
+		}
+	}
+	if allowance.Expiration != nil {
+		msg.Expiration = *allowance.Expiration
+	}
+
+	_, err = ak.signAndBroadcast(ak.ctx, key, granter, msg)
+	return err
+}
+
+// RevokeFeeAllowance revokes the fee allowance the configured account granted
+// grantee, sending a MsgRevokeAllowance.
+func (ak *AkashClient) RevokeFeeAllowance(ctx context.Context, grantee string) error {
+	defer ak.begin(ctx, opTransaction)()
+	if err := ak.requireAccount(); err != nil {
+		return err
+	}
+
+	key, granter, err := ak.signer()
+	if err != nil {
+		return err
+	}
+	_, err = ak.signAndBroadcast(ak.ctx, key, granter, tx.MsgRevokeAllowance{Granter: granter, Grantee: grantee})
+	return err
+}
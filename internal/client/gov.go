@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apisv1alpha1 "github.com/overlock-network/provider-akash/apis/v1alpha1"
+	grpcquery "github.com/overlock-network/provider-akash/internal/client/grpc-query"
+	"github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+// paramSubspaces are the legacy parameter subspaces whose changes affect
+// deployments.
+var paramSubspaces = map[string]bool{
+	"deployment": true,
+	"market":     true,
+	"escrow":     true,
+}
+
+// GetProposalsInVotingPeriod queries the governance proposals currently being
+// voted on.
+func (ak *AkashClient) GetProposalsInVotingPeriod(ctx context.Context) ([]types.Proposal, error) {
+	defer ak.begin(ctx, opQuery)()
+	q, err := ak.grpcQuery()
+	if err != nil {
+		return nil, err
+	}
+	return q.Proposals(ak.ctx, grpcquery.ProposalStatusVotingPeriod)
+}
+
+// PendingProposals returns the proposals that change deployment or market
+// parameters, or upgrade the chain software.
+func PendingProposals(proposals []types.Proposal) []apisv1alpha1.PendingProposal {
+	pending := make([]apisv1alpha1.PendingProposal, 0)
+
+	for _, p := range proposals {
+		contents := p.Messages
+		if p.Content != nil {
+			contents = append([]types.ProposalContent{*p.Content}, contents...)
+		}
+
+		for _, c := range contents {
+			if c.Content != nil {
+				c = *c.Content
+			}
+			kind, ok := proposalKind(c)
+			if !ok {
+				continue
+			}
+
+			pp := apisv1alpha1.PendingProposal{
+				ID:    p.Id,
+				Kind:  kind,
+				Title: p.Title,
+			}
+			if pp.ID == "" {
+				pp.ID = p.ProposalId
+			}
+			if pp.Title == "" {
+				pp.Title = c.Title
+			}
+			if !p.VotingEndTime.IsZero() {
+				pp.VotingEndTime = &metav1.Time{Time: p.VotingEndTime}
+			}
+			if c.Plan != nil {
+				pp.UpgradeHeight = c.Plan.Height
+			}
+			pending = append(pending, pp)
+			break
+		}
+	}
+
+	return pending
+}
+
+func proposalKind(c types.ProposalContent) (apisv1alpha1.ProposalKind, bool) {
+	switch {
+	case strings.Contains(c.Type, "SoftwareUpgrade"):
+		return apisv1alpha1.ProposalKindSoftwareUpgrade, true
+	case strings.HasSuffix(c.Type, "ParameterChangeProposal"):
+		for _, change := range c.Changes {
+			if paramSubspaces[change.Subspace] {
+				return apisv1alpha1.ProposalKindParamChange, true
+			}
+		}
+	case strings.HasSuffix(c.Type, ".MsgUpdateParams"):
+		if strings.HasPrefix(c.Type, "/akash.deployment.") || strings.HasPrefix(c.Type, "/akash.market.") || strings.HasPrefix(c.Type, "/akash.escrow.") {
+			return apisv1alpha1.ProposalKindParamChange, true
+		}
+	}
+	return "", false
+}
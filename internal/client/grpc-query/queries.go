@@ -0,0 +1,292 @@
+package grpc_query
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+// Methods of the query services of the node.
+const (
+	methodDeployment        = "/akash.deployment.v1beta3.Query/Deployment"
+	methodDeployments       = "/akash.deployment.v1beta3.Query/Deployments"
+	methodBids              = "/akash.market.v1beta4.Query/Bids"
+	methodLease             = "/akash.market.v1beta4.Query/Lease"
+	methodLeases            = "/akash.market.v1beta4.Query/Leases"
+	methodProvider          = "/akash.provider.v1beta3.Query/Provider"
+	methodProviderAudits    = "/akash.audit.v1beta3.Query/ProviderAttributes"
+	methodAllBalances       = "/cosmos.bank.v1beta1.Query/AllBalances"
+	methodSpendableBalances = "/cosmos.bank.v1beta1.Query/SpendableBalances"
+	methodDelegations       = "/cosmos.staking.v1beta1.Query/DelegatorDelegations"
+	methodCertificates      = "/akash.cert.v1beta3.Query/Certificates"
+	methodAuthzGrants       = "/cosmos.authz.v1beta1.Query/Grants"
+	methodFeeAllowance      = "/cosmos.feegrant.v1beta1.Query/Allowance"
+	methodProposals         = "/cosmos.gov.v1.Query/Proposals"
+	methodLegacyProposals   = "/cosmos.gov.v1beta1.Query/Proposals"
+)
+
+// ProposalStatusVotingPeriod selects the governance proposals being voted on.
+const ProposalStatusVotingPeriod = "PROPOSAL_STATUS_VOTING_PERIOD"
+
+// Filters select the deployments, bids or leases returned by a query, or
+// identify a lease. Empty fields match any.
+type Filters struct {
+	Owner    string `json:"owner,omitempty"`
+	Dseq     string `json:"dseq,omitempty"`
+	Gseq     string `json:"gseq,omitempty"`
+	Oseq     string `json:"oseq,omitempty"`
+	Provider string `json:"provider,omitempty"`
+	State    string `json:"state,omitempty"`
+}
+
+type pageRequest struct {
+	Key        string `json:"key,omitempty"`
+	Limit      string `json:"limit"`
+	CountTotal bool   `json:"count_total,omitempty"`
+}
+
+type paginated struct {
+	Pagination types.Pagination `json:"pagination"`
+}
+
+func (p paginated) nextKey() string {
+	return p.Pagination.NextKey
+}
+
+type deploymentsPage struct {
+	paginated
+	Deployments []types.Deployment `json:"deployments"`
+}
+
+type bidsPage struct {
+	paginated
+	Bids []types.BidWrapper `json:"bids"`
+}
+
+type leasesPage struct {
+	paginated
+	Leases []types.Lease `json:"leases"`
+}
+
+type auditsPage struct {
+	paginated
+	Providers []types.AuditedAttributes `json:"providers"`
+}
+
+type balancesPage struct {
+	paginated
+	Balances []types.Coin `json:"balances"`
+}
+
+type delegationsPage struct {
+	paginated
+	DelegationResponses []types.DelegationResponse `json:"delegation_responses"`
+}
+
+type certificatesPage struct {
+	paginated
+	Certificates []types.CertificateResponse `json:"certificates"`
+}
+
+type grantsPage struct {
+	paginated
+	Grants []types.AuthzGrant `json:"grants"`
+}
+
+type proposalsPage struct {
+	paginated
+	Proposals []types.Proposal `json:"proposals"`
+}
+
+// list calls the paginated method with req until the last page, passing
+// every page to add.
+func list[P interface{ nextKey() string }](ctx context.Context, c *QueryClient, method string, height int64, req map[string]any, add func(P)) error {
+	key := ""
+	for {
+		req["pagination"] = pageRequest{Key: key, Limit: strconv.Itoa(c.pageLimit)}
+		var page P
+		if err := c.Invoke(ctx, method, height, req, &page); err != nil {
+			return err
+		}
+		add(page)
+		if key = page.nextKey(); key == "" {
+			return nil
+		}
+	}
+}
+
+// Deployment returns the deployment dseq of owner at the given block height,
+// or at the latest one when zero.
+func (c *QueryClient) Deployment(ctx context.Context, owner string, dseq string, height int64) (types.Deployment, error) {
+	deployment := types.Deployment{}
+	req := map[string]any{"id": Filters{Owner: owner, Dseq: dseq}}
+	if err := c.Invoke(ctx, methodDeployment, height, req, &deployment); err != nil {
+		return types.Deployment{}, err
+	}
+	return deployment, nil
+}
+
+// Deployments returns every deployment matching the filters.
+func (c *QueryClient) Deployments(ctx context.Context, filters Filters) ([]types.Deployment, error) {
+	var deployments []types.Deployment
+	err := list(ctx, c, methodDeployments, 0, map[string]any{"filters": filters}, func(p deploymentsPage) {
+		deployments = append(deployments, p.Deployments...)
+	})
+	return deployments, err
+}
+
+// DeploymentsPage returns the page of at most limit deployments matching the
+// filters that starts at key, the first page when empty. The total number of
+// matching deployments is counted when total is set.
+func (c *QueryClient) DeploymentsPage(ctx context.Context, filters Filters, key string, limit uint64, total bool) (types.DeploymentResponse, error) {
+	page := deploymentsPage{}
+	req := map[string]any{
+		"filters":    filters,
+		"pagination": pageRequest{Key: key, Limit: strconv.FormatUint(limit, 10), CountTotal: total},
+	}
+	if err := c.Invoke(ctx, methodDeployments, 0, req, &page); err != nil {
+		return types.DeploymentResponse{}, err
+	}
+	return types.DeploymentResponse{Deployments: page.Deployments, Pagination: page.Pagination}, nil
+}
+
+// Bids returns every bid matching the filters.
+func (c *QueryClient) Bids(ctx context.Context, filters Filters) (types.Bids, error) {
+	bids := types.Bids{}
+	err := list(ctx, c, methodBids, 0, map[string]any{"filters": filters}, func(p bidsPage) {
+		for _, b := range p.Bids {
+			bids = append(bids, b.Bid)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bids, nil
+}
+
+// Lease returns the lease identified by id at the given block height, or at
+// the latest one when zero. The state of id is ignored.
+func (c *QueryClient) Lease(ctx context.Context, id Filters, height int64) (types.Lease, error) {
+	id.State = ""
+	lease := types.Lease{}
+	if err := c.Invoke(ctx, methodLease, height, map[string]any{"id": id}, &lease); err != nil {
+		return types.Lease{}, err
+	}
+	return lease, nil
+}
+
+// Leases returns every lease matching the filters at the given block height,
+// or at the latest one when zero.
+func (c *QueryClient) Leases(ctx context.Context, filters Filters, height int64) ([]types.Lease, error) {
+	var leases []types.Lease
+	err := list(ctx, c, methodLeases, height, map[string]any{"filters": filters}, func(p leasesPage) {
+		leases = append(leases, p.Leases...)
+	})
+	return leases, err
+}
+
+// Provider returns the on-chain record of the provider at address.
+func (c *QueryClient) Provider(ctx context.Context, address string) (types.ProviderRecord, error) {
+	resp := struct {
+		Provider types.ProviderRecord `json:"provider"`
+	}{}
+	if err := c.Invoke(ctx, methodProvider, 0, map[string]any{"owner": address}, &resp); err != nil {
+		return types.ProviderRecord{}, err
+	}
+	return resp.Provider, nil
+}
+
+// ProviderAudits returns the attributes of the provider at address signed by
+// auditors, by auditor.
+func (c *QueryClient) ProviderAudits(ctx context.Context, address string) ([]types.AuditedAttributes, error) {
+	var audits []types.AuditedAttributes
+	err := list(ctx, c, methodProviderAudits, 0, map[string]any{"owner": address}, func(p auditsPage) {
+		audits = append(audits, p.Providers...)
+	})
+	return audits, err
+}
+
+// Balances returns the bank balances of address.
+func (c *QueryClient) Balances(ctx context.Context, address string) (types.Balances, error) {
+	return c.balances(ctx, methodAllBalances, address)
+}
+
+// SpendableBalances returns the bank balances of address that can be spent.
+func (c *QueryClient) SpendableBalances(ctx context.Context, address string) (types.Balances, error) {
+	return c.balances(ctx, methodSpendableBalances, address)
+}
+
+func (c *QueryClient) balances(ctx context.Context, method string, address string) (types.Balances, error) {
+	balances := types.Balances{}
+	err := list(ctx, c, method, 0, map[string]any{"address": address}, func(p balancesPage) {
+		balances.Balances = append(balances.Balances, p.Balances...)
+	})
+	if err != nil {
+		return types.Balances{}, err
+	}
+	return balances, nil
+}
+
+// Delegations returns the stake address delegated to validators.
+func (c *QueryClient) Delegations(ctx context.Context, address string) ([]types.DelegationResponse, error) {
+	var delegations []types.DelegationResponse
+	err := list(ctx, c, methodDelegations, 0, map[string]any{"delegator_addr": address}, func(p delegationsPage) {
+		delegations = append(delegations, p.DelegationResponses...)
+	})
+	return delegations, err
+}
+
+// Certificates returns the certificates published by owner in the given
+// state, e.g. valid, or in any state when empty.
+func (c *QueryClient) Certificates(ctx context.Context, owner string, state string) (types.Certificates, error) {
+	certs := types.Certificates{}
+	req := map[string]any{"filter": map[string]string{"owner": owner, "state": state}}
+	err := list(ctx, c, methodCertificates, 0, req, func(p certificatesPage) {
+		certs.Certificates = append(certs.Certificates, p.Certificates...)
+	})
+	if err != nil {
+		return types.Certificates{}, err
+	}
+	return certs, nil
+}
+
+// AuthzGrants returns the authorizations granter granted grantee.
+func (c *QueryClient) AuthzGrants(ctx context.Context, granter string, grantee string) ([]types.AuthzGrant, error) {
+	var grants []types.AuthzGrant
+	err := list(ctx, c, methodAuthzGrants, 0, map[string]any{"granter": granter, "grantee": grantee}, func(p grantsPage) {
+		grants = append(grants, p.Grants...)
+	})
+	return grants, err
+}
+
+// FeeAllowance returns the fee allowance granter granted grantee.
+func (c *QueryClient) FeeAllowance(ctx context.Context, granter string, grantee string) (types.FeeGrant, error) {
+	resp := types.FeeGrantResponse{}
+	if err := c.Invoke(ctx, methodFeeAllowance, 0, map[string]any{"granter": granter, "grantee": grantee}, &resp); err != nil {
+		return types.FeeGrant{}, err
+	}
+	return resp.Allowance, nil
+}
+
+// Proposals returns the governance proposals in the given status, e.g.
+// ProposalStatusVotingPeriod. Nodes without the v1 governance queries are
+// queried for v1beta1 proposals.
+func (c *QueryClient) Proposals(ctx context.Context, status string) ([]types.Proposal, error) {
+	proposals, err := c.proposals(ctx, methodProposals, status)
+	if err == nil {
+		return proposals, nil
+	}
+	if legacy, lerr := c.proposals(ctx, methodLegacyProposals, status); lerr == nil {
+		return legacy, nil
+	}
+	return nil, err
+}
+
+func (c *QueryClient) proposals(ctx context.Context, method string, status string) ([]types.Proposal, error) {
+	var proposals []types.Proposal
+	err := list(ctx, c, method, 0, map[string]any{"proposal_status": status}, func(p proposalsPage) {
+		proposals = append(proposals, p.Proposals...)
+	})
+	return proposals, err
+}
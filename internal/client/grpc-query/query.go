@@ -0,0 +1,315 @@
+package grpc_query
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/overlock-network/provider-akash/internal/client/retry"
+	"github.com/overlock-network/provider-akash/internal/tracing"
+)
+
+// heightHeader selects the block height a query is answered at.
+const heightHeader = "x-cosmos-block-height"
+
+// DefaultPageLimit is the number of results requested per page.
+const DefaultPageLimit = 100
+
+var (
+	clientsMu sync.Mutex
+	clients   = map[string]*QueryClient{}
+)
+
+// QueryClient queries a node through its gRPC query services. The request and
+// response types of methods are resolved through gRPC server reflection and
+// cached, so that no generated code is needed and responses decode into the
+// same types as the output of the CLI.
+type QueryClient struct {
+	endpoint  string
+	conn      *grpc.ClientConn
+	pageLimit int
+
+	// resolveMu serializes resolving services, each extending the files
+	// resolved before.
+	resolveMu sync.Mutex
+
+	mu      sync.Mutex
+	files   *protoregistry.Files
+	types   *dynamicpb.Types
+	methods map[string]protoreflect.MethodDescriptor
+}
+
+// New returns the QueryClient of the gRPC endpoint of a node, e.g.
+// grpc.akashnet.net:443. Clients, and their connection, are shared by every
+// caller of the same endpoint. Endpoints prefixed with https:// or on port
+// 443 are dialed with TLS, others, e.g. http://localhost:9090, without.
+func New(endpoint string) (*QueryClient, error) {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+
+	if c, ok := clients[endpoint]; ok {
+		return c, nil
+	}
+
+	target, creds := dialTarget(endpoint)
+	conn, err := grpc.Dial(target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot dial gRPC endpoint %s", endpoint)
+	}
+	c := newQueryClient(endpoint, conn)
+	clients[endpoint] = c
+	return c, nil
+}
+
+func newQueryClient(endpoint string, conn *grpc.ClientConn) *QueryClient {
+	return &QueryClient{
+		endpoint:  endpoint,
+		conn:      conn,
+		pageLimit: DefaultPageLimit,
+		files:     &protoregistry.Files{},
+		types:     dynamicpb.NewTypes(&protoregistry.Files{}),
+		methods:   map[string]protoreflect.MethodDescriptor{},
+	}
+}
+
+// dialTarget returns the target and transport credentials endpoint is dialed
+// with.
+func dialTarget(endpoint string) (string, credentials.TransportCredentials) {
+	switch {
+	case strings.HasPrefix(endpoint, "https://"):
+		return strings.TrimPrefix(endpoint, "https://"), credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	case strings.HasPrefix(endpoint, "http://"):
+		return strings.TrimPrefix(endpoint, "http://"), insecure.NewCredentials()
+	}
+	if _, port, err := net.SplitHostPort(endpoint); err == nil && port == "443" {
+		return endpoint, credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+	return endpoint, insecure.NewCredentials()
+}
+
+// Invoke calls the unary method, e.g. /akash.deployment.v1beta3.Query/Deployment,
+// with req encoded as JSON and decodes its response into out, using the
+// original field names of the protobuf messages as the CLI does. Messages
+// packed into Any fields of the response are resolved as they are met. The
+// query is answered at the given block height, or at the latest one when
+// zero, and retried according to the retry policy of ctx.
+func (c *QueryClient) Invoke(ctx context.Context, method string, height int64, req any, out any) (err error) {
+	ctx, span := tracing.Start(ctx, "grpc "+method, tracing.AttrNode.String(c.endpoint))
+	defer func() { tracing.End(span, err) }()
+
+	md, err := c.method(ctx, method)
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	in := dynamicpb.NewMessage(md.Input())
+	if err := (protojson.UnmarshalOptions{Resolver: c.resolver()}).Unmarshal(raw, in); err != nil {
+		return errors.Wrapf(err, "cannot encode request of %s", method)
+	}
+
+	if height > 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, heightHeader, strconv.FormatInt(height, 10))
+	}
+	resp := dynamicpb.NewMessage(md.Output())
+	err = retry.Do(ctx, retry.Query, func() error {
+		resp.Reset()
+		return c.conn.Invoke(ctx, method, in, resp)
+	})
+	if err != nil {
+		return err
+	}
+
+	raw, err = protojson.MarshalOptions{UseProtoNames: true, Resolver: anyResolver{Types: c.resolver(), ctx: ctx, c: c}}.Marshal(resp)
+	if err != nil {
+		return errors.Wrapf(err, "cannot decode response of %s", method)
+	}
+	return json.Unmarshal(raw, out)
+}
+
+func (c *QueryClient) resolver() *dynamicpb.Types {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.types
+}
+
+// method returns the descriptor of method, resolving the service it belongs to
+// through server reflection on first use.
+func (c *QueryClient) method(ctx context.Context, method string) (protoreflect.MethodDescriptor, error) {
+	c.mu.Lock()
+	md, ok := c.methods[method]
+	c.mu.Unlock()
+	if ok {
+		return md, nil
+	}
+
+	service, name, ok := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	if !ok {
+		return nil, errors.Errorf("invalid gRPC method %q", method)
+	}
+	files, err := c.resolveSymbol(ctx, service)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot resolve gRPC service %s", service)
+	}
+	d, err := files.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot resolve gRPC service %s", service)
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, errors.Errorf("%s is not a gRPC service", service)
+	}
+	md = sd.Methods().ByName(protoreflect.Name(name))
+	if md == nil {
+		return nil, errors.Errorf("gRPC service %s has no method %s", service, name)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.methods[method] = md
+	return md, nil
+}
+
+// resolveSymbol returns the files resolved so far, extended with the file
+// declaring symbol unless it was resolved before.
+func (c *QueryClient) resolveSymbol(ctx context.Context, symbol string) (*protoregistry.Files, error) {
+	c.resolveMu.Lock()
+	defer c.resolveMu.Unlock()
+
+	c.mu.Lock()
+	files := c.files
+	c.mu.Unlock()
+	if _, err := files.FindDescriptorByName(protoreflect.FullName(symbol)); err == nil {
+		return files, nil
+	}
+
+	files, err := c.resolve(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.files = files
+	c.types = dynamicpb.NewTypes(files)
+	return files, nil
+}
+
+// An anyResolver resolves the messages packed into Any fields of responses,
+// e.g. the messages of governance proposals, through server reflection when
+// they were not resolved before.
+type anyResolver struct {
+	*dynamicpb.Types
+	ctx context.Context
+	c   *QueryClient
+}
+
+func (r anyResolver) FindMessageByURL(url string) (protoreflect.MessageType, error) {
+	mt, err := r.c.resolver().FindMessageByURL(url)
+	if !errors.Is(err, protoregistry.NotFound) {
+		return mt, err
+	}
+	if _, err := r.c.resolveSymbol(r.ctx, url[strings.LastIndex(url, "/")+1:]); err != nil {
+		return nil, errors.Wrapf(err, "cannot resolve %s", url)
+	}
+	return r.c.resolver().FindMessageByURL(url)
+}
+
+// resolve returns the files known so far, extended with the file declaring
+// symbol and its transitive dependencies as served by the reflection service
+// of the node.
+func (c *QueryClient) resolve(ctx context.Context, symbol string) (*protoregistry.Files, error) {
+	stream, err := reflectionpb.NewServerReflectionClient(c.conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.CloseSend() //nolint:errcheck // The stream is done once every file was received.
+
+	fetched := map[string]*descriptorpb.FileDescriptorProto{}
+	requested := map[string]bool{}
+	request := &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+	}
+	for request != nil {
+		if err := stream.Send(request); err != nil {
+			return nil, err
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		// Dependencies the node does not serve are left unresolved.
+		if e := resp.GetErrorResponse(); e != nil && len(requested) == 0 {
+			return nil, errors.New(e.GetErrorMessage())
+		}
+		for _, b := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fd := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(b, fd); err != nil {
+				return nil, err
+			}
+			fetched[fd.GetName()] = fd
+		}
+
+		request = nil
+		if dep := c.missingDependency(fetched, requested); dep != "" {
+			requested[dep] = true
+			request = &reflectionpb.ServerReflectionRequest{
+				MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: dep},
+			}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	set := &descriptorpb.FileDescriptorSet{}
+	c.files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		if _, ok := fetched[fd.Path()]; !ok {
+			set.File = append(set.File, protodesc.ToFileDescriptorProto(fd))
+		}
+		return true
+	})
+	for _, fd := range fetched {
+		set.File = append(set.File, fd)
+	}
+	// Nodes built with gogoproto may reference options they do not serve.
+	return protodesc.FileOptions{AllowUnresolvable: true}.NewFiles(set)
+}
+
+// missingDependency returns a dependency of the fetched files that was
+// neither fetched, requested nor resolved before, if any.
+func (c *QueryClient) missingDependency(fetched map[string]*descriptorpb.FileDescriptorProto, requested map[string]bool) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, fd := range fetched {
+		for _, dep := range fd.GetDependency() {
+			if _, ok := fetched[dep]; ok || requested[dep] {
+				continue
+			}
+			if _, err := c.files.FindFileByPath(dep); err == nil {
+				continue
+			}
+			return dep
+		}
+	}
+	return ""
+}
@@ -0,0 +1,207 @@
+package grpc_query
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// newTestClient returns a QueryClient of a server serving the health service
+// and server reflection.
+func newTestClient(t *testing.T) *QueryClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	hs := health.NewServer()
+	hs.SetServingStatus("akash", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(srv, hs)
+	reflection.Register(srv)
+	go srv.Serve(lis) //nolint:errcheck // Serve returns once the server stops.
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc.Dial(...): %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return newQueryClient("bufnet", conn)
+}
+
+func TestInvoke(t *testing.T) {
+	type response struct {
+		Status string `json:"status"`
+	}
+
+	cases := map[string]struct {
+		method  string
+		req     any
+		want    response
+		wantErr bool
+	}{
+		"Resolved": {
+			method: "/grpc.health.v1.Health/Check",
+			req:    map[string]any{"service": "akash"},
+			want:   response{Status: "NOT_SERVING"},
+		},
+		"DefaultService": {
+			method: "/grpc.health.v1.Health/Check",
+			req:    map[string]any{},
+			want:   response{Status: "SERVING"},
+		},
+		"UnknownMethod": {
+			method:  "/grpc.health.v1.Health/Probe",
+			req:     map[string]any{},
+			wantErr: true,
+		},
+		"UnknownService": {
+			method:  "/akash.deployment.v1beta3.Query/Deployment",
+			req:     map[string]any{},
+			wantErr: true,
+		},
+		"UnknownField": {
+			method:  "/grpc.health.v1.Health/Check",
+			req:     map[string]any{"dseq": "42"},
+			wantErr: true,
+		},
+	}
+
+	c := newTestClient(t)
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := response{}
+			err := c.Invoke(context.Background(), tc.method, 0, tc.req, &got)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Invoke(...): want error %t, got %v", tc.wantErr, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Invoke(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+// newPackingClient returns a QueryClient of a server whose
+// /test.Packer/Pack method returns a google.protobuf.Duration of a second
+// packed into an Any. The file declaring Duration is served through server
+// reflection, but not a dependency of the service.
+func newPackingClient(t *testing.T) *QueryClient {
+	t.Helper()
+
+	files := &protoregistry.Files{}
+	for _, fd := range []protoreflect.FileDescriptor{anypb.File_google_protobuf_any_proto, durationpb.File_google_protobuf_duration_proto} {
+		if err := files.RegisterFile(fd); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("test/packer.proto"),
+		Package:    proto.String("test"),
+		Dependency: []string{anypb.File_google_protobuf_any_proto.Path()},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Packer"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("Pack"),
+				InputType:  proto.String(".google.protobuf.Any"),
+				OutputType: proto.String(".google.protobuf.Any"),
+			}},
+		}},
+		Syntax: proto.String("proto3"),
+	}, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := files.RegisterFile(fd); err != nil {
+		t.Fatal(err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	srv.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "test.Packer",
+		HandlerType: (*any)(nil),
+		Metadata:    fd.Path(),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Pack",
+			Handler: func(_ any, _ context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+				if err := dec(&anypb.Any{}); err != nil {
+					return nil, err
+				}
+				return anypb.New(durationpb.New(time.Second))
+			},
+		}},
+	}, struct{}{})
+	rpb.RegisterServerReflectionServer(srv, reflection.NewServer(reflection.ServerOptions{Services: srv, DescriptorResolver: files}))
+	go srv.Serve(lis) //nolint:errcheck // Serve returns once the server stops.
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc.Dial(...): %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return newQueryClient("bufnet", conn)
+}
+
+func TestInvokePacked(t *testing.T) {
+	type response struct {
+		Type  string `json:"@type"`
+		Value string `json:"value"`
+	}
+
+	c := newPackingClient(t)
+	got := response{}
+	if err := c.Invoke(context.Background(), "/test.Packer/Pack", 0, map[string]any{}, &got); err != nil {
+		t.Fatalf("Invoke(...): %v", err)
+	}
+	want := response{Type: "type.googleapis.com/google.protobuf.Duration", Value: "1s"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Invoke(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestDialTarget(t *testing.T) {
+	cases := map[string]struct {
+		endpoint   string
+		wantTarget string
+		wantTLS    bool
+	}{
+		"TLSPort":     {endpoint: "grpc.akashnet.net:443", wantTarget: "grpc.akashnet.net:443", wantTLS: true},
+		"HTTPS":       {endpoint: "https://grpc.example.com:9090", wantTarget: "grpc.example.com:9090", wantTLS: true},
+		"HTTP":        {endpoint: "http://localhost:443", wantTarget: "localhost:443"},
+		"Plaintext":   {endpoint: "localhost:9090", wantTarget: "localhost:9090"},
+		"MissingPort": {endpoint: "grpc.example.com", wantTarget: "grpc.example.com"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			target, creds := dialTarget(tc.endpoint)
+			if target != tc.wantTarget {
+				t.Errorf("dialTarget(%q): want target %q, got %q", tc.endpoint, tc.wantTarget, target)
+			}
+			if gotTLS := creds.Info().SecurityProtocol == "tls"; gotTLS != tc.wantTLS {
+				t.Errorf("dialTarget(%q): want TLS %t, got %t", tc.endpoint, tc.wantTLS, gotTLS)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package indexer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/overlock-network/provider-akash/internal/client/types"
+	"github.com/overlock-network/provider-akash/internal/tracing"
+)
+
+// DefaultTimeout bounds a single request to an indexer.
+const DefaultTimeout = 30 * time.Second
+
+type deployment struct {
+	Owner   string  `json:"owner"`
+	Dseq    string  `json:"dseq"`
+	Status  string  `json:"status"`
+	Balance float64 `json:"balance"`
+	Denom   string  `json:"denom"`
+	Leases  []lease `json:"leases"`
+}
+
+type lease struct {
+	Gseq     int    `json:"gseq"`
+	Oseq     int    `json:"oseq"`
+	Status   string `json:"status"`
+	Provider struct {
+		Address string `json:"address"`
+	} `json:"provider"`
+}
+
+// IndexerClient reads deployment and lease state from an indexer API such as
+// the Akash Console API. Indexers lag behind the chain, so their state is
+// only suitable for observation, never to decide on transactions.
+type IndexerClient struct {
+	host string
+	http *http.Client
+}
+
+// New creates a new IndexerClient for the indexer API at host.
+func New(host string) *IndexerClient {
+	return &IndexerClient{
+		host: strings.TrimSuffix(host, "/"),
+		http: &http.Client{Timeout: DefaultTimeout, Transport: tracing.NewTransport(nil)},
+	}
+}
+
+// GetDeployment gets a deployment of owner and its leases from the indexer.
+// Only the fields the indexer knows about are set.
+func (c *IndexerClient) GetDeployment(ctx context.Context, owner string, dseq string) (types.Deployment, []types.Lease, error) {
+	addr := c.host + "/v1/deployment/" + owner + "/" + dseq
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, addr, nil)
+	if err != nil {
+		return types.Deployment{}, nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return types.Deployment{}, nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck // Nothing is lost when closing a body fails.
+
+	if resp.StatusCode != http.StatusOK {
+		return types.Deployment{}, nil, fmt.Errorf("response status code %d", resp.StatusCode)
+	}
+
+	result := deployment{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return types.Deployment{}, nil, err
+	}
+
+	d := types.Deployment{
+		DeploymentInfo: types.DeploymentInfo{
+			State:        result.Status,
+			DeploymentId: types.DeploymentId{Owner: result.Owner, Dseq: result.Dseq},
+		},
+		EscrowAccount: types.EscrowAccount{
+			Owner: result.Owner,
+			State: result.Status,
+			Balance: types.EscrowAccountBalance{
+				Denom:  result.Denom,
+				Amount: strconv.FormatFloat(result.Balance, 'f', -1, 64),
+			},
+		},
+	}
+
+	leases := make([]types.Lease, 0, len(result.Leases))
+	for _, l := range result.Leases {
+		leases = append(leases, types.Lease{
+			Lease: types.LeaseInfo{
+				LeaseId: types.LeaseId{
+					Owner:    result.Owner,
+					Dseq:     result.Dseq,
+					Gseq:     l.Gseq,
+					Oseq:     l.Oseq,
+					Provider: l.Provider.Address,
+				},
+				State: l.Status,
+			},
+		})
+	}
+
+	return d, leases, nil
+}
@@ -0,0 +1,53 @@
+package indexer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+func TestGetDeployment(t *testing.T) {
+	indexer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/deployment/akash1owner/42" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"owner":"akash1owner","dseq":"42","status":"active","balance":4500000.5,"denom":"uakt",
+			"leases":[{"gseq":1,"oseq":1,"status":"active","provider":{"address":"akash1provider"}}]}`))
+	}))
+	defer indexer.Close()
+
+	wantDeployment := types.Deployment{
+		DeploymentInfo: types.DeploymentInfo{
+			State:        types.DeploymentStateActive,
+			DeploymentId: types.DeploymentId{Owner: "akash1owner", Dseq: "42"},
+		},
+		EscrowAccount: types.EscrowAccount{
+			Owner:   "akash1owner",
+			State:   "active",
+			Balance: types.EscrowAccountBalance{Denom: "uakt", Amount: "4500000.5"},
+		},
+	}
+	wantLeases := []types.Lease{{
+		Lease: types.LeaseInfo{
+			LeaseId: types.LeaseId{Owner: "akash1owner", Dseq: "42", Gseq: 1, Oseq: 1, Provider: "akash1provider"},
+			State:   types.LeaseStateActive,
+		},
+	}}
+
+	d, leases, err := New(indexer.URL+"/").GetDeployment(context.Background(), "akash1owner", "42")
+	if err != nil {
+		t.Fatalf("GetDeployment() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(wantDeployment, d); diff != "" {
+		t.Errorf("GetDeployment() deployment mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(wantLeases, leases); diff != "" {
+		t.Errorf("GetDeployment() leases mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,162 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/overlock-network/provider-akash/internal/client/chaintest"
+	"github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+// TestChainQueries runs the queries of a client against a mock chain.
+func TestChainQueries(t *testing.T) {
+	chain := chaintest.New(t)
+	d := types.Deployment{DeploymentInfo: types.DeploymentInfo{
+		State:        "active",
+		DeploymentId: types.DeploymentId{Owner: "akash1owner", Dseq: "42"},
+	}}
+	chain.AddDeployment(d)
+	chain.AddBid("akash1owner", "42", 1, 1, types.Bid{Id: types.BidId{Provider: "akash1a"}, State: "open"})
+	active := types.Lease{Lease: types.LeaseInfo{
+		LeaseId: types.LeaseId{Owner: "akash1owner", Dseq: "42", Gseq: 1, Oseq: 1, Provider: "akash1a"},
+		State:   types.LeaseStateActive,
+	}}
+	closed := types.Lease{Lease: types.LeaseInfo{
+		LeaseId: types.LeaseId{Owner: "akash1owner", Dseq: "42", Gseq: 1, Oseq: 1, Provider: "akash1b"},
+		State:   "closed",
+	}}
+	chain.AddLease(active)
+	chain.AddLease(closed)
+	chain.SetBalances("akash1owner", types.Coin{Denom: "uakt", Amount: "5000000"})
+	audit := types.AuditedAttributes{Owner: "akash1a", Auditor: "akash1auditor", Attributes: []types.Attribute{{Key: "region", Value: "us-west"}}}
+	chain.AddAudit(audit)
+	delegation := types.DelegationResponse{
+		Delegation: types.Delegation{DelegatorAddress: "akash1owner", ValidatorAddress: "akashvaloper1a", Shares: "1000.000000000000000000"},
+		Balance:    types.Coin{Denom: "uakt", Amount: "1000"},
+	}
+	chain.AddDelegation(delegation)
+	chain.AddGrant("akash1owner", "akash1grantee", types.AuthzGrant{Authorization: types.Authorization{Type: "/cosmos.authz.v1beta1.GenericAuthorization"}})
+	deposit := types.AuthzGrant{
+		Authorization: types.Authorization{Type: "/akash.deployment.v1beta3.DepositDeploymentAuthorization", SpendLimit: types.Coin{Denom: "uakt", Amount: "5000000"}},
+		Expiration:    "2030-01-01T00:00:00Z",
+	}
+	chain.AddGrant("akash1owner", "akash1grantee", deposit)
+	feeGrant := types.FeeGrant{Granter: "akash1treasury", Grantee: "akash1owner", Allowance: types.Allowance{Type: types.AllowanceTypeBasic, SpendLimit: []types.Coin{{Denom: "uakt", Amount: "100000"}}}}
+	chain.AddFeeGrant(feeGrant)
+	upgrade := types.Proposal{Id: "7", Title: "Upgrade", Status: "PROPOSAL_STATUS_VOTING_PERIOD", Messages: []types.ProposalContent{
+		{Type: "/cosmos.upgrade.v1beta1.MsgSoftwareUpgrade", Plan: &types.UpgradePlan{Name: "v1.0.0", Height: 100}},
+	}}
+	chain.AddProposal(upgrade)
+
+	ctx := context.Background()
+	// The address is taken as verified, so no key is needed to query bids.
+	ak := New(ctx, AkashProviderConfiguration{
+		AccountAddress: "akash1owner",
+		GRPCEndpoint:   chain.GRPCEndpoint(),
+		Node:           chain.Node(),
+	})
+	ak.addressVerified = true
+
+	got, err := ak.GetDeployment(ctx, "42", "akash1owner")
+	if err != nil {
+		t.Fatalf("GetDeployment(...): %v", err)
+	}
+	if diff := cmp.Diff(d, got); diff != "" {
+		t.Errorf("GetDeployment(...): -want, +got:\n%s", diff)
+	}
+	if _, err := ak.GetDeployment(ctx, "43", "akash1owner"); !IsDeploymentNotFound(err) {
+		t.Errorf("GetDeployment(...): want deployment not found, got %v", err)
+	}
+
+	bids, err := ak.QueryBids(ctx, Seqs{Dseq: "42", Gseq: "1", Oseq: "1"})
+	if err != nil {
+		t.Fatalf("QueryBids(...): %v", err)
+	}
+	if diff := cmp.Diff(types.Bids{{Id: types.BidId{Provider: "akash1a"}, State: "open"}}, bids); diff != "" {
+		t.Errorf("QueryBids(...): -want, +got:\n%s", diff)
+	}
+
+	leases, err := ak.GetLeases(ctx, "42", "akash1owner")
+	if err != nil {
+		t.Fatalf("GetLeases(...): %v", err)
+	}
+	if diff := cmp.Diff([]types.Lease{active, closed}, leases); diff != "" {
+		t.Errorf("GetLeases(...): -want, +got:\n%s", diff)
+	}
+	leases, err = ak.GetActiveLeases(ctx, "42", "akash1owner")
+	if err != nil {
+		t.Fatalf("GetActiveLeases(...): %v", err)
+	}
+	if diff := cmp.Diff([]types.Lease{active}, leases); diff != "" {
+		t.Errorf("GetActiveLeases(...): -want, +got:\n%s", diff)
+	}
+
+	balances, err := ak.GetSpendableBalances(ctx, "akash1owner")
+	if err != nil {
+		t.Fatalf("GetSpendableBalances(...): %v", err)
+	}
+	if got := balances.AmountOf("uakt"); got != "5000000" {
+		t.Errorf("GetSpendableBalances(...): want 5000000uakt, got %suakt", got)
+	}
+
+	audits, err := ak.GetProviderAudits(ctx, "akash1a")
+	if err != nil {
+		t.Fatalf("GetProviderAudits(...): %v", err)
+	}
+	if diff := cmp.Diff([]types.AuditedAttributes{audit}, audits); diff != "" {
+		t.Errorf("GetProviderAudits(...): -want, +got:\n%s", diff)
+	}
+	// Providers nobody audited are reported as not found by the chain.
+	audits, err = ak.GetProviderAudits(ctx, "akash1b")
+	if err != nil || audits != nil {
+		t.Errorf("GetProviderAudits(...): want no audits, got %v, %v", audits, err)
+	}
+
+	delegations, err := ak.GetDelegations(ctx, "akash1owner")
+	if err != nil {
+		t.Fatalf("GetDelegations(...): %v", err)
+	}
+	if diff := cmp.Diff([]types.DelegationResponse{delegation}, delegations); diff != "" {
+		t.Errorf("GetDelegations(...): -want, +got:\n%s", diff)
+	}
+
+	grant, err := ak.GetDepositAuthorization(ctx, "akash1owner", "akash1grantee")
+	if err != nil {
+		t.Fatalf("GetDepositAuthorization(...): %v", err)
+	}
+	if diff := cmp.Diff(deposit, grant); diff != "" {
+		t.Errorf("GetDepositAuthorization(...): -want, +got:\n%s", diff)
+	}
+	if _, err := ak.GetDepositAuthorization(ctx, "akash1owner", "akash1other"); !IsDepositAuthorizationNotFound(err) {
+		t.Errorf("GetDepositAuthorization(...): want authorization not found, got %v", err)
+	}
+
+	allowance, err := ak.GetFeeGrant(ctx, "akash1treasury", "akash1owner")
+	if err != nil {
+		t.Fatalf("GetFeeGrant(...): %v", err)
+	}
+	if diff := cmp.Diff(feeGrant, allowance); diff != "" {
+		t.Errorf("GetFeeGrant(...): -want, +got:\n%s", diff)
+	}
+	if _, err := ak.GetFeeGrant(ctx, "akash1treasury", "akash1other"); !IsFeeGrantNotFound(err) {
+		t.Errorf("GetFeeGrant(...): want fee grant not found, got %v", err)
+	}
+
+	proposals, err := ak.GetProposalsInVotingPeriod(ctx)
+	if err != nil {
+		t.Fatalf("GetProposalsInVotingPeriod(...): %v", err)
+	}
+	if diff := cmp.Diff([]types.Proposal{upgrade}, proposals); diff != "" {
+		t.Errorf("GetProposalsInVotingPeriod(...): -want, +got:\n%s", diff)
+	}
+
+	status, err := ak.GetNodeStatus(ctx)
+	if err != nil {
+		t.Fatalf("GetNodeStatus(...): %v", err)
+	}
+	if status.Result.NodeInfo.Network != chaintest.DefaultChainID {
+		t.Errorf("GetNodeStatus(...): want network %s, got %s", chaintest.DefaultChainID, status.Result.NodeInfo.Network)
+	}
+}
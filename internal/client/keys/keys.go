@@ -0,0 +1,58 @@
+// Package keys holds the secp256k1 keys of Akash accounts in memory, so that
+// transactions are signed without a keyring or the Akash CLI.
+package keys
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ripemd160" //nolint:staticcheck // Cosmos SDK addresses are RIPEMD-160 hashes.
+
+	"github.com/overlock-network/provider-akash/internal/bech32"
+)
+
+// AddressPrefix is the human-readable part of Akash account addresses.
+const AddressPrefix = "akash"
+
+const errInvalidHexKey = "private key must be 32 hex encoded bytes"
+
+// A PrivKey is the secp256k1 private key of an account.
+type PrivKey struct {
+	key *secp256k1.PrivateKey
+}
+
+// FromHex returns the private key of its hex encoding, as exported by akash
+// keys export --unarmored-hex --unsafe.
+func FromHex(s string) (*PrivKey, error) {
+	b, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(b) != secp256k1.PrivKeyBytesLen {
+		return nil, errors.New(errInvalidHexKey)
+	}
+	return &PrivKey{key: secp256k1.PrivKeyFromBytes(b)}, nil
+}
+
+// PubKey returns the compressed public key of k.
+func (k *PrivKey) PubKey() []byte {
+	return k.key.PubKey().SerializeCompressed()
+}
+
+// Address returns the account address of k, e.g. akash1....
+func (k *PrivKey) Address() string {
+	sum := sha256.Sum256(k.PubKey())
+	h := ripemd160.New()
+	h.Write(sum[:])
+	return bech32.EncodeAddress(AddressPrefix, h.Sum(nil))
+}
+
+// Sign returns the signature of the SHA-256 hash of msg, as the 32 byte R and
+// S values of a canonical low-S ECDSA signature.
+func (k *PrivKey) Sign(msg []byte) []byte {
+	sum := sha256.Sum256(msg)
+	compact := ecdsa.SignCompact(k.key, sum[:], true)
+	// The first byte of a compact signature is its recovery code.
+	return compact[1:]
+}
@@ -0,0 +1,74 @@
+package keys
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+// The key of the mnemonic "abandon ... about" at m/44'/118'/0'/0/0.
+const (
+	testKey     = "c4a48e2fce1481cd3294b4490f6678090ea98d3d0e5cd984558ab0968741b104"
+	testPubKey  = "024f4e2ad99c34d60b9ba6283c9431a8418af8673212961f97a77b6377fcd05b62"
+	testAddress = "akash19rl4cm2hmr8afy4kldpxz3fka4jguq0a3mq6x0"
+)
+
+func TestFromHex(t *testing.T) {
+	cases := map[string]struct {
+		key     string
+		wantErr bool
+	}{
+		"Valid":           {key: testKey},
+		"TrailingNewline": {key: testKey + "\n"},
+		"NotHex":          {key: "not a key", wantErr: true},
+		"TooShort":        {key: testKey[:62], wantErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			k, err := FromHex(tc.key)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("FromHex(...): want error %t, got %v", tc.wantErr, err)
+			}
+			if tc.wantErr {
+				return
+			}
+			if got := hex.EncodeToString(k.PubKey()); got != testPubKey {
+				t.Errorf("PubKey(): want %s, got %s", testPubKey, got)
+			}
+			if got := k.Address(); got != testAddress {
+				t.Errorf("Address(): want %s, got %s", testAddress, got)
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	k, err := FromHex(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("sign doc")
+	sig := k.Sign(msg)
+	if len(sig) != 64 {
+		t.Fatalf("Sign(...): want 64 bytes, got %d", len(sig))
+	}
+
+	var r, s secp256k1.ModNScalar
+	r.SetByteSlice(sig[:32])
+	s.SetByteSlice(sig[32:])
+	if s.IsOverHalfOrder() {
+		t.Errorf("Sign(...): S is not canonical")
+	}
+	pub, err := secp256k1.ParsePubKey(k.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(msg)
+	if !ecdsa.NewSignature(&r, &s).Verify(sum[:], pub) {
+		t.Errorf("Sign(...): signature does not verify")
+	}
+}
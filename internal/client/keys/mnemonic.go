@@ -0,0 +1,102 @@
+package keys
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	errInvalidHDPath = "invalid HD path %q"
+	errDeriveKey     = "cannot derive key at %s"
+
+	// hardened is the offset of hardened child indexes.
+	hardened = 1 << 31
+)
+
+// HDPath returns the HD path of the key of account and index along the
+// default Cosmos coin type, m/44'/118'/account'/0/index.
+func HDPath(account uint32, index uint32) string {
+	return fmt.Sprintf("m/44'/118'/%d'/0/%d", account, index)
+}
+
+// FromMnemonic returns the private key derived from the BIP39 mnemonic along
+// the BIP32 HD path, e.g. m/44'/118'/0'/0/0. The mnemonic has no passphrase,
+// as keys recovered by the Akash CLI.
+func FromMnemonic(mnemonic string, hdPath string) (*PrivKey, error) {
+	indexes, err := parseHDPath(hdPath)
+	if err != nil {
+		return nil, err
+	}
+
+	seed := pbkdf2.Key([]byte(strings.Join(strings.Fields(mnemonic), " ")), []byte("mnemonic"), 2048, 64, sha512.New)
+	key, chainCode := split(hmacSHA512([]byte("Bitcoin seed"), seed))
+	k := new(secp256k1.ModNScalar)
+	if overflow := k.SetByteSlice(key); overflow || k.IsZero() {
+		return nil, errors.Errorf(errDeriveKey, hdPath)
+	}
+
+	for _, i := range indexes {
+		data := make([]byte, 0, 37)
+		if i >= hardened {
+			b := k.Bytes()
+			data = append(append(data, 0), b[:]...)
+		} else {
+			data = append(data, secp256k1.NewPrivateKey(k).PubKey().SerializeCompressed()...)
+		}
+		data = binary.BigEndian.AppendUint32(data, i)
+
+		var tweak []byte
+		tweak, chainCode = split(hmacSHA512(chainCode, data))
+		t := new(secp256k1.ModNScalar)
+		if overflow := t.SetByteSlice(tweak); overflow {
+			return nil, errors.Errorf(errDeriveKey, hdPath)
+		}
+		if k.Add(t); k.IsZero() {
+			return nil, errors.Errorf(errDeriveKey, hdPath)
+		}
+	}
+	return &PrivKey{key: secp256k1.NewPrivateKey(k)}, nil
+}
+
+// parseHDPath returns the child indexes of the HD path, hardened ones marked
+// with an apostrophe.
+func parseHDPath(hdPath string) ([]uint32, error) {
+	parts := strings.Split(hdPath, "/")
+	if len(parts) < 2 || parts[0] != "m" {
+		return nil, errors.Errorf(errInvalidHDPath, hdPath)
+	}
+
+	indexes := make([]uint32, 0, len(parts)-1)
+	for _, p := range parts[1:] {
+		offset := uint64(0)
+		if strings.HasSuffix(p, "'") {
+			p, offset = strings.TrimSuffix(p, "'"), hardened
+		}
+		i, err := strconv.ParseUint(p, 10, 31)
+		if err != nil {
+			return nil, errors.Errorf(errInvalidHDPath, hdPath)
+		}
+		indexes = append(indexes, uint32(i+offset))
+	}
+	return indexes, nil
+}
+
+func hmacSHA512(key []byte, data []byte) []byte {
+	h := hmac.New(sha512.New, key)
+	h.Write(data) //nolint:errcheck // Writing to a hash never fails.
+	return h.Sum(nil)
+}
+
+// split returns the left and right halves of the output of HMAC-SHA512, the
+// key and the chain code.
+func split(b []byte) ([]byte, []byte) {
+	return b[:32], b[32:]
+}
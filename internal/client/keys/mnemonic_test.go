@@ -0,0 +1,60 @@
+package keys
+
+import "testing"
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestFromMnemonic(t *testing.T) {
+	cases := map[string]struct {
+		mnemonic string
+		hdPath   string
+		want     string
+		wantErr  bool
+	}{
+		"Default": {
+			mnemonic: testMnemonic,
+			hdPath:   HDPath(0, 0),
+			want:     testAddress,
+		},
+		"Index": {
+			mnemonic: testMnemonic,
+			hdPath:   HDPath(0, 1),
+			want:     "akash1jrkmdcwgq94uaamx6zax2luewlhf7u4k3rtk0c",
+		},
+		"Account": {
+			mnemonic: testMnemonic,
+			hdPath:   HDPath(1, 0),
+			want:     "akash1tehv5km5e9y706rc2gzk9yyun9dljjjnplxk94",
+		},
+		"ExtraWhitespace": {
+			mnemonic: " abandon  abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about\n",
+			hdPath:   HDPath(0, 0),
+			want:     testAddress,
+		},
+		"InvalidPath": {
+			mnemonic: testMnemonic,
+			hdPath:   "44'/118'/0'/0/0",
+			wantErr:  true,
+		},
+		"InvalidIndex": {
+			mnemonic: testMnemonic,
+			hdPath:   "m/44'/118'/x/0/0",
+			wantErr:  true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			k, err := FromMnemonic(tc.mnemonic, tc.hdPath)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("FromMnemonic(...): want error %t, got %v", tc.wantErr, err)
+			}
+			if tc.wantErr {
+				return
+			}
+			if got := k.Address(); got != tc.want {
+				t.Errorf("FromMnemonic(...).Address(): want %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
@@ -1,20 +1,166 @@
 package client
 
 import (
-	"github.com/overlock-network/provider-akash/internal/client/cli"
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	resourcev1alpha1 "github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	grpcquery "github.com/overlock-network/provider-akash/internal/client/grpc-query"
+	"github.com/overlock-network/provider-akash/internal/client/node"
+	"github.com/overlock-network/provider-akash/internal/client/tx"
+	"github.com/overlock-network/provider-akash/internal/client/types"
 )
 
-func (ak *AkashClient) CreateLease(seqs Seqs, provider string) (string, error) {
-	cmd := cli.AkashCli(ak).Tx().Market().Lease().Create().
-		SetDseq(seqs.Dseq).SetGseq(seqs.Gseq).SetOseq(seqs.Oseq).
-		SetProvider(provider).SetOwner(ak.Config.AccountAddress).SetFrom(ak.Config.KeyName).
-		DefaultGas().SetChainId(ak.Config.ChainId).SetKeyringBackend(ak.Config.KeyringBackend).
-		SetNote(ak.transactionNote).AutoAccept().SetNode(ak.Config.Node).OutputJson()
+// CreateLease accepts the bid of provider on the order of seqs and returns the
+// JSON encoded transaction once it was included in a block. The
+// MsgCreateLease is signed with the key of the credentials and broadcast
+// through the node, so no Akash CLI is involved.
+func (ak *AkashClient) CreateLease(ctx context.Context, seqs Seqs, provider string) (string, error) {
+	defer ak.begin(ctx, opTransaction)()
+	key, owner, err := ak.signer()
+	if err != nil {
+		return "", err
+	}
+	if seqs.Owner != "" && seqs.Owner != owner {
+		return "", errors.Wrapf(ErrNotDeploymentOwner, "deployment %s is owned by %s, not %s", seqs.Dseq, seqs.Owner, owner)
+	}
+	msg, err := newMsgCreateLease(seqs, owner, provider)
+	if err != nil {
+		return "", err
+	}
 
-	out, err := cmd.Raw()
+	var out []byte
+	err = withDeploymentLock(owner, seqs.Dseq, func() error {
+		state, err := node.New(ak.Config.Node).DeploymentState(ak.ctx, owner, msg.Dseq)
+		if err != nil {
+			return err
+		}
+		if state != types.DeploymentStateActive {
+			return errors.Errorf("deployment %s is %s, refusing to broadcast", seqs.Dseq, state)
+		}
+
+		transaction, err := ak.signAndBroadcast(ak.ctx, key, owner, msg)
+		if err != nil {
+			return err
+		}
+		out, err = json.Marshal(transaction)
+		return err
+	})
 	if err != nil {
 		return "", err
 	}
 
 	return string(out), nil
 }
+
+// newMsgCreateLease returns the message of owner accepting the bid of
+// provider on the order of seqs.
+func newMsgCreateLease(seqs Seqs, owner string, provider string) (tx.MsgCreateLease, error) {
+	dseq, err := parseDseq(seqs.Dseq)
+	if err != nil {
+		return tx.MsgCreateLease{}, err
+	}
+	gseq, err := strconv.ParseUint(seqs.Gseq, 10, 32)
+	if err != nil {
+		return tx.MsgCreateLease{}, errors.Wrapf(err, "invalid gseq %q", seqs.Gseq)
+	}
+	oseq, err := strconv.ParseUint(seqs.Oseq, 10, 32)
+	if err != nil {
+		return tx.MsgCreateLease{}, errors.Wrapf(err, "invalid oseq %q", seqs.Oseq)
+	}
+	return tx.MsgCreateLease{Owner: owner, Dseq: dseq, Gseq: uint32(gseq), Oseq: uint32(oseq), Provider: provider}, nil
+}
+
+// GetLeaseAtHeight queries a lease as it was at the given block height,
+// provided the node has not pruned that state yet.
+func (ak *AkashClient) GetLeaseAtHeight(ctx context.Context, seqs Seqs, owner string, provider string, height int64) (types.Lease, error) {
+	defer ak.begin(ctx, opQuery)()
+	q, err := ak.grpcQuery()
+	if err != nil {
+		return types.Lease{}, err
+	}
+	return q.Lease(ak.ctx, grpcquery.Filters{Owner: owner, Dseq: seqs.Dseq, Gseq: seqs.Gseq, Oseq: seqs.Oseq, Provider: provider}, height)
+}
+
+// GetLeasesAtHeight queries every lease of a deployment as it was at the given
+// block height.
+func (ak *AkashClient) GetLeasesAtHeight(ctx context.Context, dseq string, owner string, height int64) ([]types.Lease, error) {
+	defer ak.begin(ctx, opQuery)()
+	q, err := ak.grpcQuery()
+	if err != nil {
+		return nil, err
+	}
+	return q.Leases(ak.ctx, grpcquery.Filters{Owner: owner, Dseq: dseq}, height)
+}
+
+// GetLeases queries the leases of a deployment, whatever their state.
+func (ak *AkashClient) GetLeases(ctx context.Context, dseq string, owner string) ([]types.Lease, error) {
+	defer ak.begin(ctx, opQuery)()
+	q, err := ak.grpcQuery()
+	if err != nil {
+		return nil, err
+	}
+	return q.Leases(ak.ctx, grpcquery.Filters{Owner: owner, Dseq: dseq}, 0)
+}
+
+// GetActiveLeases queries the active leases of a deployment.
+func (ak *AkashClient) GetActiveLeases(ctx context.Context, dseq string, owner string) ([]types.Lease, error) {
+	defer ak.begin(ctx, opQuery)()
+	q, err := ak.grpcQuery()
+	if err != nil {
+		return nil, err
+	}
+	return q.Leases(ak.ctx, grpcquery.Filters{Owner: owner, Dseq: dseq, State: types.LeaseStateActive}, 0)
+}
+
+// LeaseServices converts the services of a lease status into their Deployment
+// status representation, ordered by name.
+func LeaseServices(status types.LeaseStatus) []resourcev1alpha1.ServiceStatus {
+	var services []resourcev1alpha1.ServiceStatus
+	for _, name := range types.SortedServiceNames(status.Services) {
+		s := status.Services[name]
+		services = append(services, resourcev1alpha1.ServiceStatus{
+			Name:      name,
+			Available: s.Available,
+			Total:     s.Total,
+			URIs:      s.URIs,
+		})
+	}
+
+	return services
+}
+
+// LeaseEndpoints converts the forwarded ports and leased IPs of a lease status
+// into their Deployment status representation, ordered by service name.
+func LeaseEndpoints(status types.LeaseStatus) ([]resourcev1alpha1.ForwardedPort, []resourcev1alpha1.LeasedIP) {
+	var ports []resourcev1alpha1.ForwardedPort
+	for _, service := range types.SortedServiceNames(status.ForwardedPorts) {
+		for _, p := range status.ForwardedPorts[service] {
+			ports = append(ports, resourcev1alpha1.ForwardedPort{
+				Service:      service,
+				Host:         p.Host,
+				Port:         p.Port,
+				ExternalPort: p.ExternalPort,
+				Protocol:     p.Proto,
+			})
+		}
+	}
+
+	var ips []resourcev1alpha1.LeasedIP
+	for _, service := range types.SortedServiceNames(status.IPs) {
+		for _, ip := range status.IPs[service] {
+			ips = append(ips, resourcev1alpha1.LeasedIP{
+				Service:      service,
+				IP:           ip.IP,
+				Port:         ip.Port,
+				ExternalPort: ip.ExternalPort,
+				Protocol:     ip.Protocol,
+			})
+		}
+	}
+
+	return ports, ips
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+// deploymentLocks serializes transactions touching the same deployment across
+// every AkashClient of the process, e.g. an Update racing a Delete issued by a
+// fast sequence of kubectl commands.
+var deploymentLocks = &lockMap{locks: map[string]*refLock{}}
+
+// A lockMap holds a lock per key for as long as it is held or waited for, so
+// that it does not grow with every deployment the process ever touched.
+type lockMap struct {
+	mu    sync.Mutex
+	locks map[string]*refLock
+}
+
+// A refLock is a lock counting the callers holding or waiting for it.
+type refLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lock locks key and returns the function unlocking it.
+func (m *lockMap) lock(key string) func() {
+	m.mu.Lock()
+	l, ok := m.locks[key]
+	if !ok {
+		l = &refLock{}
+		m.locks[key] = l
+	}
+	l.refs++
+	m.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if l.refs--; l.refs == 0 {
+			delete(m.locks, key)
+		}
+	}
+}
+
+// len returns the number of keys held or waited for.
+func (m *lockMap) len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.locks)
+}
+
+var (
+	gateMu        sync.RWMutex
+	broadcastGate = func() error { return nil }
+)
+
+// SetBroadcastGate sets the check every transaction must pass right before it
+// is broadcast. When several replicas of the provider run with leader
+// election, the gate ensures only the elected one broadcasts, even if a
+// standby replica happens to run client code.
+func SetBroadcastGate(gate func() error) {
+	gateMu.Lock()
+	defer gateMu.Unlock()
+	broadcastGate = gate
+}
+
+// requireBroadcast returns an error if this replica may not broadcast
+// transactions.
+func requireBroadcast() error {
+	gateMu.RLock()
+	defer gateMu.RUnlock()
+	return errors.Wrap(broadcastGate(), "refusing to broadcast")
+}
+
+// withDeploymentLock runs fn while holding the lock of the deployment
+// identified by owner and dseq.
+func withDeploymentLock(owner string, dseq string, fn func() error) error {
+	defer deploymentLocks.lock(owner + "/" + dseq)()
+	return fn()
+}
+
+// deploymentState queries the chain for the current state of a deployment, so
+// callers can recheck it right before broadcasting a transaction.
+func (ak *AkashClient) deploymentState(dseq string, owner string) (string, error) {
+	deployment, err := ak.GetDeployment(ak.ctx, dseq, owner)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot recheck deployment state")
+	}
+
+	return deployment.DeploymentInfo.State, nil
+}
+
+// requireActiveDeployment returns an error unless the deployment is active on
+// chain, avoiding paying gas for transactions that are bound to fail.
+func (ak *AkashClient) requireActiveDeployment(dseq string, owner string) error {
+	state, err := ak.deploymentState(dseq, owner)
+	if err != nil {
+		return err
+	}
+	if state != types.DeploymentStateActive {
+		return errors.Errorf("deployment %s is %s, refusing to broadcast", dseq, state)
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package client
+
+import (
+	"sync"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+)
+
+var (
+	logMu  sync.RWMutex
+	logger = logging.NewNopLogger()
+)
+
+// SetLogger sets the logger of all clients. Nothing is logged by default.
+func SetLogger(l logging.Logger) {
+	logMu.Lock()
+	defer logMu.Unlock()
+	logger = l
+}
+
+// log returns the logger of ak, which records the chain and the owner of
+// everything it logs.
+func (ak *AkashClient) log() logging.Logger {
+	logMu.RLock()
+	defer logMu.RUnlock()
+	return logger.WithValues("chain", ak.Config.ChainId, "owner", ak.Config.AccountAddress)
+}
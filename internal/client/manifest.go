@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	resourcev1alpha1 "github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+)
+
+// ManifestDeliveryPolicy controls how SendManifestWithRetry retries failed
+// manifest submissions.
+type ManifestDeliveryPolicy struct {
+	MaxRetries int
+	Backoff    time.Duration
+	Timeout    time.Duration
+}
+
+// ManifestDeliveryResult is the outcome of SendManifestWithRetry.
+type ManifestDeliveryResult struct {
+	Response string
+	Attempts int
+}
+
+// NewManifestDeliveryPolicy converts the ManifestDelivery settings of a
+// Deployment into a ManifestDeliveryPolicy, using constants for defaults.
+func NewManifestDeliveryPolicy(md *resourcev1alpha1.ManifestDelivery) ManifestDeliveryPolicy {
+	policy := ManifestDeliveryPolicy{
+		MaxRetries: DefaultManifestMaxRetries,
+		Backoff:    DefaultManifestBackoff,
+		Timeout:    DefaultManifestTimeout,
+	}
+	if md == nil {
+		return policy
+	}
+
+	if md.MaxRetries != nil {
+		policy.MaxRetries = *md.MaxRetries
+	}
+	if md.Backoff != nil {
+		policy.Backoff = md.Backoff.Duration
+	}
+	if md.Timeout != nil {
+		policy.Timeout = md.Timeout.Duration
+	}
+
+	return policy
+}
+
+// SendManifestWithRetry submits the manifest to the provider, retrying with
+// exponential backoff until it succeeds, the retries are exhausted or the
+// policy timeout elapses. The number of attempts made is always returned so
+// callers can report it, even when delivery ultimately fails.
+func (ak *AkashClient) SendManifestWithRetry(ctx context.Context, dseq string, provider string, manifestLocation string, policy ManifestDeliveryPolicy) (ManifestDeliveryResult, error) {
+	defer ak.begin(ctx, opUnbounded)()
+	deadline := time.Now().Add(policy.Timeout)
+	backoff := policy.Backoff
+	result := ManifestDeliveryResult{}
+
+	var err error
+	for {
+		result.Attempts++
+		result.Response, err = ak.SendManifest(ak.ctx, dseq, provider, manifestLocation)
+		if err == nil {
+			return result, nil
+		}
+
+		if result.Attempts > policy.MaxRetries || time.Now().Add(backoff).After(deadline) {
+			break
+		}
+
+		select {
+		case <-ak.ctx.Done():
+			return result, errors.Wrap(ak.ctx.Err(), "manifest delivery interrupted")
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	return result, errors.Wrapf(err, "cannot deliver manifest after %d attempts", result.Attempts)
+}
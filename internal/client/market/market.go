@@ -0,0 +1,115 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/overlock-network/provider-akash/internal/client/retry"
+	"github.com/overlock-network/provider-akash/internal/client/types"
+	"github.com/overlock-network/provider-akash/internal/tracing"
+)
+
+// bidsPath is the REST route of the QueryBidsRequest of the market module.
+const bidsPath = "/akash/market/v1beta4/bids/list"
+
+// DefaultPageLimit is the number of bids requested per page.
+const DefaultPageLimit = 100
+
+type pageResponse struct {
+	NextKey string `json:"next_key"`
+}
+
+type bidsResponse struct {
+	Bids       []types.BidWrapper `json:"bids"`
+	Pagination pageResponse       `json:"pagination"`
+}
+
+// BidFilters select the bids returned by a query. Empty fields match any bid.
+type BidFilters struct {
+	Owner    string
+	Dseq     string
+	Gseq     string
+	Oseq     string
+	Provider string
+	State    string
+}
+
+// QueryClient queries the market module of a node through its REST API, the
+// gRPC gateway serving the same requests as the gRPC query service.
+type QueryClient struct {
+	host      string
+	http      *http.Client
+	pageLimit int
+}
+
+// New creates a new QueryClient for the REST API of a node at host. Requests
+// are bound by the context they are made with.
+func New(host string) *QueryClient {
+	return &QueryClient{
+		host:      strings.TrimSuffix(host, "/"),
+		http:      &http.Client{Transport: tracing.NewTransport(nil)},
+		pageLimit: DefaultPageLimit,
+	}
+}
+
+// Bids returns every bid matching the filters, following the pagination of
+// the query until the last page.
+func (c *QueryClient) Bids(ctx context.Context, filters BidFilters) (types.Bids, error) {
+	q := url.Values{}
+	for k, v := range map[string]string{
+		"filters.owner":    filters.Owner,
+		"filters.dseq":     filters.Dseq,
+		"filters.gseq":     filters.Gseq,
+		"filters.oseq":     filters.Oseq,
+		"filters.provider": filters.Provider,
+		"filters.state":    filters.State,
+	} {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	q.Set("pagination.limit", strconv.Itoa(c.pageLimit))
+
+	bids := types.Bids{}
+	for {
+		page := bidsResponse{}
+		if err := c.get(ctx, bidsPath+"?"+q.Encode(), &page); err != nil {
+			return nil, err
+		}
+		for _, b := range page.Bids {
+			bids = append(bids, b.Bid)
+		}
+		if page.Pagination.NextKey == "" {
+			return bids, nil
+		}
+		q.Set("pagination.key", page.Pagination.NextKey)
+	}
+}
+
+// get decodes the response to a GET request of path into out, retrying it
+// according to the retry policy of ctx.
+func (c *QueryClient) get(ctx context.Context, path string, out interface{}) error {
+	return retry.Do(ctx, retry.Query, func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.host+path, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close() //nolint:errcheck // Nothing is lost when closing a body fails.
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("response status code %d", resp.StatusCode)
+		}
+
+		return json.NewDecoder(resp.Body).Decode(out)
+	})
+}
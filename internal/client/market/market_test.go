@@ -0,0 +1,48 @@
+package market
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+func TestBids(t *testing.T) {
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != bidsPath || q.Get("filters.owner") != "akash1owner" || q.Get("filters.dseq") != "42" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch q.Get("pagination.key") {
+		case "":
+			_, _ = w.Write([]byte(`{"bids":[{"bid":{"bid_id":{"provider":"akash1first"},"state":"open","price":{"denom":"uakt","amount":"1.5"}}}],
+				"pagination":{"next_key":"cGFnZTI="}}`))
+		case "cGFnZTI=":
+			_, _ = w.Write([]byte(`{"bids":[{"bid":{"bid_id":{"provider":"akash1second"},"state":"closed","price":{"denom":"uakt","amount":"2"}}}],
+				"pagination":{"next_key":null}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer node.Close()
+
+	want := types.Bids{
+		{Id: types.BidId{Provider: "akash1first"}, State: "open", Price: types.BidPrice{Denom: "uakt", Amount: 1.5}},
+		{Id: types.BidId{Provider: "akash1second"}, State: "closed", Price: types.BidPrice{Denom: "uakt", Amount: 2}},
+	}
+
+	c := New(node.URL + "/")
+	c.pageLimit = 1
+	got, err := c.Bids(context.Background(), BidFilters{Owner: "akash1owner", Dseq: "42"})
+	if err != nil {
+		t.Fatalf("Bids() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Bids() mismatch (-want +got):\n%s", diff)
+	}
+}
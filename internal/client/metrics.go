@@ -0,0 +1,55 @@
+package client
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// Results of a credential cache lookup.
+const (
+	cacheHit     = "hit"
+	cacheMiss    = "miss"
+	cacheExpired = "expired"
+)
+
+var (
+	credentialCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "akash_credential_cache_lookups_total",
+		Help: "Lookups of cached credentials by result: hit, miss or expired.",
+	}, []string{"result"})
+
+	clientPoolLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "akash_client_pool_lookups_total",
+		Help: "Lookups of the state shared by the clients of a ProviderConfig by result: hit, miss or expired, when the credentials had to be extracted again but did not change.",
+	}, []string{"result"})
+
+	clientPoolSize = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "akash_client_pool_size",
+		Help: "ProviderConfigs whose clients share pooled state.",
+	})
+
+	credentialInvalidations = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "akash_credential_invalidations_total",
+		Help: "ProviderConfigs whose pooled credentials were dropped because the Secret holding the credentials changed.",
+	})
+
+	credentialFetchDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "akash_credential_fetch_duration_seconds",
+		Help:    "Time taken to fetch credentials, e.g. from a Secret, by credentials source.",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 10),
+	}, []string{"source"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(credentialCacheLookups, clientPoolLookups, clientPoolSize, credentialInvalidations, credentialFetchDuration)
+}
+
+// observeCredentialFetch records how long fetching credentials from source
+// took since start.
+func observeCredentialFetch(source xpv1.CredentialsSource, start time.Time) {
+	credentialFetchDuration.WithLabelValues(string(source)).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/overlock-network/provider-akash/internal/client/keys"
+)
+
+const (
+	errMnemonicCredentials = "cannot parse mnemonic credentials"
+	errMnemonicWords       = "mnemonic has %d words, want 12, 15, 18, 21 or 24"
+	errRecoverKey          = "cannot recover key from mnemonic"
+)
+
+// MnemonicCredentials are ProviderConfig credentials holding the BIP39
+// mnemonic of the signing key, either as JSON or as the plain mnemonic.
+type MnemonicCredentials struct {
+	Mnemonic string `json:"mnemonic"`
+
+	// Account and Index select the key derived along the default HD path
+	// m/44'/118'/account'/0/index.
+	Account uint32 `json:"account,omitempty"`
+	Index   uint32 `json:"index,omitempty"`
+
+	// HDPath overrides the full HD path of the key.
+	HDPath string `json:"hdPath,omitempty"`
+}
+
+// key derives the private key of m in memory.
+func (m MnemonicCredentials) key() (*keys.PrivKey, error) {
+	hdPath := m.HDPath
+	if hdPath == "" {
+		hdPath = keys.HDPath(m.Account, m.Index)
+	}
+	key, err := keys.FromMnemonic(m.Mnemonic, hdPath)
+	return key, errors.Wrap(err, errRecoverKey)
+}
+
+// parseMnemonicCredentials returns the mnemonic held by creds. It reports
+// false when creds hold no mnemonic, e.g. when they hold the hex encoded
+// private key instead.
+func parseMnemonicCredentials(creds []byte) (MnemonicCredentials, bool, error) {
+	text := strings.TrimSpace(string(creds))
+	m := MnemonicCredentials{}
+	switch {
+	case strings.HasPrefix(text, "{"):
+		if err := json.Unmarshal([]byte(text), &m); err != nil {
+			return MnemonicCredentials{}, false, errors.Wrap(err, errMnemonicCredentials)
+		}
+		if m.Mnemonic == "" {
+			return MnemonicCredentials{}, false, nil
+		}
+	case isMnemonic(text):
+		m.Mnemonic = text
+	default:
+		return MnemonicCredentials{}, false, nil
+	}
+
+	words := strings.Fields(m.Mnemonic)
+	switch len(words) {
+	case 12, 15, 18, 21, 24:
+	default:
+		return MnemonicCredentials{}, false, errors.Errorf(errMnemonicWords, len(words))
+	}
+	m.Mnemonic = strings.Join(words, " ")
+	return m, true, nil
+}
+
+// isMnemonic reports whether text looks like a mnemonic: at least 12 words
+// of lowercase letters.
+func isMnemonic(text string) bool {
+	words := strings.Fields(text)
+	if len(words) < 12 {
+		return false
+	}
+	for _, w := range words {
+		for _, r := range w {
+			if r < 'a' || r > 'z' {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,402 @@
+// Package node talks to the Tendermint RPC of a node: it queries the gRPC
+// services of the chain through ABCI queries, simulates and broadcasts signed
+// transactions, without the Akash CLI.
+package node
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/overlock-network/provider-akash/internal/tracing"
+)
+
+// Paths of the ABCI queries.
+const (
+	pathAccount    = "/cosmos.auth.v1beta1.Query/Account"
+	pathSimulate   = "/cosmos.tx.v1beta1.Service/Simulate"
+	pathDeployment = "/akash.deployment.v1beta3.Query/Deployment"
+)
+
+// deploymentStates names the states of deployments as the chain does.
+var deploymentStates = map[uint64]string{0: "invalid", 1: "active", 2: "closed"}
+
+// ErrAccountNotFound is returned when the chain has no account of an
+// address, e.g. because it never received funds.
+var ErrAccountNotFound = errors.New("account not found")
+
+// ErrSimulationFailed is returned when the simulation of a transaction fails,
+// e.g. because the account cannot pay its deposit.
+var ErrSimulationFailed = errors.New("transaction simulation failed")
+
+// A Client calls the Tendermint RPC of a node, e.g.
+// https://rpc.akashnet.net:443.
+type Client struct {
+	endpoint string
+	http     *http.Client
+}
+
+// New returns the Client of the Tendermint RPC endpoint of a node.
+func New(endpoint string) *Client {
+	return &Client{endpoint: strings.TrimSuffix(endpoint, "/"), http: tracing.DefaultClient}
+}
+
+// An Account is the state of an account signing transactions.
+type Account struct {
+	Number   uint64
+	Sequence uint64
+}
+
+// A Result is the outcome of a transaction, once checked by the node or
+// included in a block.
+type Result struct {
+	// Hash of the transaction, upper case hex.
+	Hash string
+	// Height of the block including the transaction, zero until it is.
+	Height int64
+	// Code is zero when the transaction succeeded.
+	Code uint32
+	// Log explains why the transaction failed.
+	Log string
+	// Events are emitted by the transaction once it is included in a block.
+	Events []Event
+}
+
+// An Event is emitted by a transaction, e.g. when it creates a deployment.
+// Its attributes are reported as strings, as by CometBFT 0.37 and later.
+type Event struct {
+	Type       string      `json:"type"`
+	Attributes []Attribute `json:"attributes"`
+}
+
+// An Attribute of an Event.
+type Attribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Broadcast methods by broadcast mode: sync returns once the node checked
+// the transaction, async right away and block once it is in a block.
+var broadcastMethods = map[string]string{
+	"sync":  "broadcast_tx_sync",
+	"async": "broadcast_tx_async",
+	"block": "broadcast_tx_commit",
+}
+
+// ErrTxNotFound is returned by Tx while a transaction is not included in a
+// block.
+var ErrTxNotFound = errors.New("transaction not found")
+
+type txResult struct {
+	Code   uint32  `json:"code"`
+	Log    string  `json:"log"`
+	Events []Event `json:"events"`
+}
+
+type txResponse struct {
+	Hash   string `json:"hash"`
+	Height int64  `json:"height,string"`
+	txResult
+	CheckTx *txResult `json:"check_tx"`
+	// TxResult holds the result of a transaction in a block, reported as
+	// DeliverTx by older nodes.
+	TxResult  *txResult `json:"tx_result"`
+	DeliverTx *txResult `json:"deliver_tx"`
+}
+
+// result returns the outcome of the transaction, whichever way the node
+// reported it.
+func (r txResponse) result() Result {
+	res := Result{Hash: r.Hash, Height: r.Height, Code: r.Code, Log: r.Log}
+	for _, t := range []*txResult{r.CheckTx, r.DeliverTx, r.TxResult} {
+		if t != nil && (t.Code != 0 || res.Code == 0) {
+			res.Code, res.Log = t.Code, t.Log
+		}
+	}
+	// Checking a transaction emits no events worth reporting.
+	for _, t := range []*txResult{r.DeliverTx, r.TxResult} {
+		if t != nil {
+			res.Events = append(res.Events, t.Events...)
+		}
+	}
+	return res
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    string `json:"data"`
+	} `json:"error"`
+}
+
+// A queryError is a query the node answered with an error, as opposed to one
+// that never reached it.
+type queryError struct {
+	code uint32
+	log  string
+}
+
+func (e queryError) Error() string {
+	return e.log
+}
+
+type abciResponse struct {
+	Response struct {
+		Code  uint32 `json:"code"`
+		Log   string `json:"log"`
+		Value []byte `json:"value"`
+	} `json:"response"`
+}
+
+// call calls the JSON-RPC method with params and decodes its result into out.
+func (c *Client) call(ctx context.Context, method string, params map[string]any, out any) error {
+	body, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck // Nothing is lost when closing a body fails.
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("response status code %d", resp.StatusCode)
+	}
+	r := rpcResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return errors.Wrapf(err, "cannot decode response of %s", method)
+	}
+	if r.Error != nil {
+		return errors.Errorf("%s: %s", r.Error.Message, r.Error.Data)
+	}
+	return json.Unmarshal(r.Result, out)
+}
+
+// query runs the ABCI query of the gRPC method at path with the protobuf
+// encoded request data and returns the protobuf encoded response.
+func (c *Client) query(ctx context.Context, path string, data []byte) ([]byte, error) {
+	r := abciResponse{}
+	params := map[string]any{"path": path, "data": hex.EncodeToString(data), "height": "0", "prove": false}
+	if err := c.call(ctx, "abci_query", params, &r); err != nil {
+		return nil, err
+	}
+	if r.Response.Code != 0 {
+		return nil, queryError{code: r.Response.Code, log: r.Response.Log}
+	}
+	return r.Response.Value, nil
+}
+
+// LatestHeight returns the height of the latest block of the node.
+func (c *Client) LatestHeight(ctx context.Context) (int64, error) {
+	r := struct {
+		SyncInfo struct {
+			LatestBlockHeight int64 `json:"latest_block_height,string"`
+		} `json:"sync_info"`
+	}{}
+	if err := c.call(ctx, "status", map[string]any{}, &r); err != nil {
+		return 0, errors.Wrap(err, "cannot query node status")
+	}
+	return r.SyncInfo.LatestBlockHeight, nil
+}
+
+// Account returns the number and sequence of the account of address.
+func (c *Client) Account(ctx context.Context, address string) (Account, error) {
+	req := protowire.AppendString(protowire.AppendTag(nil, 1, protowire.BytesType), address)
+	resp, err := c.query(ctx, pathAccount, req)
+	if err != nil {
+		if qe := (queryError{}); errors.As(err, &qe) && strings.Contains(qe.log, "not found") {
+			return Account{}, errors.Wrap(ErrAccountNotFound, address)
+		}
+		return Account{}, errors.Wrap(err, "cannot query account")
+	}
+
+	packed, err := field(resp, 1)
+	if err != nil {
+		return Account{}, errors.Wrap(err, "cannot decode account")
+	}
+	return decodeAccount(packed)
+}
+
+// DeploymentState returns the state of the deployment dseq of owner, e.g.
+// active.
+func (c *Client) DeploymentState(ctx context.Context, owner string, dseq uint64) (string, error) {
+	id := protowire.AppendString(protowire.AppendTag(nil, 1, protowire.BytesType), owner)
+	id = protowire.AppendVarint(protowire.AppendTag(id, 2, protowire.VarintType), dseq)
+	req := protowire.AppendBytes(protowire.AppendTag(nil, 1, protowire.BytesType), id)
+	resp, err := c.query(ctx, pathDeployment, req)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot query deployment")
+	}
+
+	deployment, err := field(resp, 1)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot decode deployment")
+	}
+	state, err := varint(deployment, 2)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot decode deployment")
+	}
+	name, ok := deploymentStates[state]
+	if !ok {
+		return "", errors.Errorf("unknown deployment state %d", state)
+	}
+	return name, nil
+}
+
+// Simulate simulates the unsigned transaction tx and returns the gas it used.
+func (c *Client) Simulate(ctx context.Context, tx []byte) (uint64, error) {
+	req := protowire.AppendBytes(protowire.AppendTag(nil, 2, protowire.BytesType), tx)
+	resp, err := c.query(ctx, pathSimulate, req)
+	if qe := (queryError{}); errors.As(err, &qe) {
+		return 0, errors.Wrap(ErrSimulationFailed, qe.log)
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot simulate transaction")
+	}
+
+	gasInfo, err := field(resp, 1)
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot decode simulation")
+	}
+	return varint(gasInfo, 2)
+}
+
+// BroadcastTx broadcasts the signed transaction tx in the broadcast mode,
+// sync, async or block.
+func (c *Client) BroadcastTx(ctx context.Context, mode string, tx []byte) (Result, error) {
+	method, ok := broadcastMethods[mode]
+	if !ok {
+		return Result{}, errors.Errorf("unknown broadcast mode %q", mode)
+	}
+
+	r := txResponse{}
+	if err := c.call(ctx, method, map[string]any{"tx": base64.StdEncoding.EncodeToString(tx)}, &r); err != nil {
+		return Result{}, err
+	}
+	if mode != "block" {
+		r.Height = 0
+	}
+	return r.result(), nil
+}
+
+// Tx returns the outcome of the transaction with the given hex hash once it
+// was included in a block, and ErrTxNotFound until then.
+func (c *Client) Tx(ctx context.Context, hash string) (Result, error) {
+	b, err := hex.DecodeString(hash)
+	if err != nil {
+		return Result{}, errors.Wrapf(err, "invalid transaction hash %s", hash)
+	}
+
+	r := txResponse{}
+	if err := c.call(ctx, "tx", map[string]any{"hash": base64.StdEncoding.EncodeToString(b), "prove": false}, &r); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return Result{}, errors.Wrap(ErrTxNotFound, hash)
+		}
+		return Result{}, err
+	}
+	return r.result(), nil
+}
+
+// decodeAccount returns the number and sequence of the account packed into
+// an Any. Module and vesting accounts embed a base account.
+func decodeAccount(packed []byte) (Account, error) {
+	typeURL, err := field(packed, 1)
+	if err != nil {
+		return Account{}, err
+	}
+	base, err := field(packed, 2)
+	if err != nil {
+		return Account{}, err
+	}
+
+	depth := 0
+	switch t := string(typeURL); {
+	case t == "/cosmos.auth.v1beta1.BaseAccount":
+	case t == "/cosmos.auth.v1beta1.ModuleAccount":
+		depth = 1
+	case strings.HasPrefix(t, "/cosmos.vesting.v1beta1."):
+		depth = 2
+	default:
+		return Account{}, errors.Errorf("unsupported account type %s", t)
+	}
+	for ; depth > 0; depth-- {
+		if base, err = field(base, 1); err != nil {
+			return Account{}, err
+		}
+	}
+
+	number, err := varint(base, 3)
+	if err != nil {
+		return Account{}, err
+	}
+	sequence, err := varint(base, 4)
+	if err != nil {
+		return Account{}, err
+	}
+	return Account{Number: number, Sequence: sequence}, nil
+}
+
+// field returns the last value of the length-delimited field num of the
+// protobuf message b, empty when unset.
+func field(b []byte, num protowire.Number) ([]byte, error) {
+	var out []byte
+	err := rangeFields(b, func(n protowire.Number, typ protowire.Type, v []byte) int {
+		if n != num || typ != protowire.BytesType {
+			return protowire.ConsumeFieldValue(n, typ, v)
+		}
+		value, l := protowire.ConsumeBytes(v)
+		out = value
+		return l
+	})
+	return out, err
+}
+
+// varint returns the last value of the varint field num of the protobuf
+// message b, zero when unset.
+func varint(b []byte, num protowire.Number) (uint64, error) {
+	var out uint64
+	err := rangeFields(b, func(n protowire.Number, typ protowire.Type, v []byte) int {
+		if n != num || typ != protowire.VarintType {
+			return protowire.ConsumeFieldValue(n, typ, v)
+		}
+		value, l := protowire.ConsumeVarint(v)
+		out = value
+		return l
+	})
+	return out, err
+}
+
+// rangeFields calls fn with the number, type and remaining bytes of every
+// field of the protobuf message b. fn returns the length of the value it
+// consumed, or a negative error code.
+func rangeFields(b []byte, fn func(protowire.Number, protowire.Type, []byte) int) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if n = fn(num, typ, b); n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+package node
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// server serves the JSON-RPC methods of a node, answering ABCI queries by
+// path.
+func server(t *testing.T, queries map[string]func(data []byte) (uint32, string, []byte)) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := struct {
+			Method string         `json:"method"`
+			Params map[string]any `json:"params"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var result any
+		switch req.Method {
+		case "abci_query":
+			query, ok := queries[req.Params["path"].(string)]
+			if !ok {
+				t.Errorf("unexpected query %s", req.Params["path"].(string))
+				return
+			}
+			data, _ := hex.DecodeString(req.Params["data"].(string))
+			code, log, value := query(data)
+			result = map[string]any{"response": map[string]any{"code": code, "log": log, "value": value}}
+		case "broadcast_tx_sync":
+			tx, _ := base64.StdEncoding.DecodeString(req.Params["tx"].(string))
+			result = map[string]any{"code": 0, "log": "", "hash": hex.EncodeToString(tx)}
+		case "broadcast_tx_commit":
+			tx, _ := base64.StdEncoding.DecodeString(req.Params["tx"].(string))
+			result = map[string]any{
+				"hash":      hex.EncodeToString(tx),
+				"height":    "42",
+				"check_tx":  map[string]any{"code": 0},
+				"tx_result": map[string]any{"code": 5, "log": "insufficient funds"},
+			}
+		case "tx":
+			if req.Params["hash"] != base64.StdEncoding.EncodeToString([]byte{0xca, 0xfe}) {
+				_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": 1, "error": map[string]any{
+					"code": -32603, "message": "Internal error", "data": "tx (BEEF) not found",
+				}})
+				return
+			}
+			result = map[string]any{"hash": "CAFE", "height": "42", "tx_result": map[string]any{"code": 0, "events": []any{
+				map[string]any{"type": "akash.deployment.v1.EventDeploymentCreated", "attributes": []any{
+					map[string]any{"key": "id", "value": `{"owner":"akash1owner","dseq":"42"}`, "index": true},
+				}},
+			}}}
+		case "status":
+			result = map[string]any{"sync_info": map[string]any{"latest_block_height": "42"}}
+		default:
+			t.Errorf("unexpected method %s", req.Method)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": 1, "result": result})
+	}))
+}
+
+func message(fields ...[]byte) []byte {
+	var b []byte
+	for _, f := range fields {
+		b = append(b, f...)
+	}
+	return b
+}
+
+func bytesField(num protowire.Number, v []byte) []byte {
+	return protowire.AppendBytes(protowire.AppendTag(nil, num, protowire.BytesType), v)
+}
+
+func varintField(num protowire.Number, v uint64) []byte {
+	return protowire.AppendVarint(protowire.AppendTag(nil, num, protowire.VarintType), v)
+}
+
+func TestAccount(t *testing.T) {
+	base := message(bytesField(1, []byte("akash1owner")), varintField(3, 42), varintField(4, 7))
+
+	cases := map[string]struct {
+		typeURL string
+		account []byte
+		code    uint32
+		log     string
+		want    Account
+		wantErr error
+	}{
+		"BaseAccount": {
+			typeURL: "/cosmos.auth.v1beta1.BaseAccount",
+			account: base,
+			want:    Account{Number: 42, Sequence: 7},
+		},
+		"ModuleAccount": {
+			typeURL: "/cosmos.auth.v1beta1.ModuleAccount",
+			account: bytesField(1, base),
+			want:    Account{Number: 42, Sequence: 7},
+		},
+		"VestingAccount": {
+			typeURL: "/cosmos.vesting.v1beta1.DelayedVestingAccount",
+			account: bytesField(1, bytesField(1, base)),
+			want:    Account{Number: 42, Sequence: 7},
+		},
+		"NotFound": {
+			code:    22,
+			log:     "rpc error: code = NotFound desc = account akash1owner not found: key not found",
+			wantErr: ErrAccountNotFound,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := server(t, map[string]func([]byte) (uint32, string, []byte){
+				pathAccount: func(data []byte) (uint32, string, []byte) {
+					if address, _ := field(data, 1); string(address) != "akash1owner" {
+						t.Errorf("Account() queried %q, want akash1owner", address)
+					}
+					packed := message(bytesField(1, []byte(tc.typeURL)), bytesField(2, tc.account))
+					return tc.code, tc.log, bytesField(1, packed)
+				},
+			})
+			defer srv.Close()
+
+			got, err := New(srv.URL).Account(context.Background(), "akash1owner")
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Account() error = %v, want %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Account() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDeploymentState(t *testing.T) {
+	srv := server(t, map[string]func([]byte) (uint32, string, []byte){
+		pathDeployment: func(data []byte) (uint32, string, []byte) {
+			id, _ := field(data, 1)
+			if dseq, _ := varint(id, 2); dseq != 12 {
+				t.Errorf("DeploymentState() queried dseq %d, want 12", dseq)
+			}
+			return 0, "", bytesField(1, message(bytesField(1, id), varintField(2, 2)))
+		},
+	})
+	defer srv.Close()
+
+	got, err := New(srv.URL).DeploymentState(context.Background(), "akash1owner", 12)
+	if err != nil {
+		t.Fatalf("DeploymentState() unexpected error: %v", err)
+	}
+	if got != "closed" {
+		t.Errorf("DeploymentState() = %q, want closed", got)
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	cases := map[string]struct {
+		code    uint32
+		want    uint64
+		wantErr error
+	}{
+		"GasUsed": {want: 81234},
+		"Failed":  {code: 5, wantErr: ErrSimulationFailed},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := server(t, map[string]func([]byte) (uint32, string, []byte){
+				pathSimulate: func(data []byte) (uint32, string, []byte) {
+					if tx, _ := field(data, 2); string(tx) != "tx" {
+						t.Errorf("Simulate() simulated %q, want tx", tx)
+					}
+					return tc.code, "insufficient funds", bytesField(1, message(varintField(1, 100000), varintField(2, 81234)))
+				},
+			})
+			defer srv.Close()
+
+			got, err := New(srv.URL).Simulate(context.Background(), []byte("tx"))
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Simulate() error = %v, want %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("Simulate() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBroadcastTx(t *testing.T) {
+	cases := map[string]struct {
+		mode    string
+		want    Result
+		wantErr bool
+	}{
+		"Sync":    {mode: "sync", want: Result{Hash: "cafe"}},
+		"Block":   {mode: "block", want: Result{Hash: "cafe", Height: 42, Code: 5, Log: "insufficient funds"}},
+		"Unknown": {mode: "eventually", wantErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := server(t, nil)
+			defer srv.Close()
+
+			got, err := New(srv.URL).BroadcastTx(context.Background(), tc.mode, []byte{0xca, 0xfe})
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("BroadcastTx() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("BroadcastTx() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTx(t *testing.T) {
+	cases := map[string]struct {
+		hash    string
+		want    Result
+		wantErr error
+	}{
+		"Included": {hash: "CAFE", want: Result{Hash: "CAFE", Height: 42, Events: []Event{{
+			Type:       "akash.deployment.v1.EventDeploymentCreated",
+			Attributes: []Attribute{{Key: "id", Value: `{"owner":"akash1owner","dseq":"42"}`}},
+		}}}},
+		"Pending": {hash: "BEEF", wantErr: ErrTxNotFound},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := server(t, nil)
+			defer srv.Close()
+
+			got, err := New(srv.URL).Tx(context.Background(), tc.hash)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Tx() error = %v, want %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Tx() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLatestHeight(t *testing.T) {
+	srv := server(t, nil)
+	defer srv.Close()
+
+	got, err := New(srv.URL).LatestHeight(context.Background())
+	if err != nil {
+		t.Fatalf("LatestHeight() error = %v", err)
+	}
+	if got != 42 {
+		t.Errorf("LatestHeight() = %d, want 42", got)
+	}
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/overlock-network/provider-akash/internal/client/retry"
+)
+
+// Timeouts bound the operations of a client. Zero fields take their
+// defaults.
+type Timeouts struct {
+	// Query bounds a query, retries included.
+	Query time.Duration
+	// Transaction bounds a transaction from signing to its inclusion in a
+	// block.
+	Transaction time.Duration
+	// Manifest bounds sending a manifest to a provider once.
+	Manifest time.Duration
+}
+
+// Kinds of operations, each bounded by its own timeout.
+type operation int
+
+const (
+	// opUnbounded operations are only bounded by their context, e.g. those
+	// waiting for bids for a given time.
+	opUnbounded operation = iota
+	opQuery
+	opTransaction
+	opManifest
+)
+
+// timeout returns the timeout of operations of kind op, zero when they are
+// unbounded.
+func (t Timeouts) timeout(op operation) time.Duration {
+	pick := func(d, def time.Duration) time.Duration {
+		if d > 0 {
+			return d
+		}
+		return def
+	}
+	switch op {
+	case opQuery:
+		return pick(t.Query, DefaultQueryTimeout)
+	case opTransaction:
+		return pick(t.Transaction, DefaultTransactionTimeout)
+	case opManifest:
+		return pick(t.Manifest, DefaultManifestTimeout)
+	case opUnbounded:
+	}
+	return 0
+}
+
+// begin makes the calls of an operation of kind op use ctx, bounded by the
+// timeout of op, until the returned function is called, which restores the
+// previous context. Operations nest, so an operation calling another one
+// passes ak.ctx. Transactions are not cancelled with ctx once begun, as a
+// transaction cut short may still be included in a block, but they are
+// bounded by their timeout all the same. A client runs one operation at a
+// time.
+//
+//	defer ak.begin(ctx, opQuery)()
+func (ak *AkashClient) begin(ctx context.Context, op operation) func() {
+	parent := ak.ctx
+	if ctx == nil {
+		ctx = parent
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if op == opTransaction {
+		ctx = context.WithoutCancel(ctx)
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if timeout := ak.Config.Timeouts.timeout(op); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	ak.ctx = retry.WithPolicy(ctx, ak.Config.Retry)
+
+	return func() {
+		cancel()
+		ak.ctx = parent
+	}
+}
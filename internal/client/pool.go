@@ -0,0 +1,204 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultPoolIdleTimeout is how long pooled client state may go unused
+	// before it is evicted.
+	DefaultPoolIdleTimeout = 10 * time.Minute
+
+	// poolCredentialTTL is how long pooled credentials are used before they
+	// are extracted again to check whether they changed.
+	poolCredentialTTL = 5 * time.Minute
+)
+
+// pool is shared by every AkashClient of the process.
+var pool = newClientPool(DefaultPoolIdleTimeout)
+
+// SetPoolIdleTimeout sets how long the state shared by the clients of a
+// ProviderConfig is kept unused before it is evicted. Zero disables pooling,
+// so that every client extracts its credentials anew.
+func SetPoolIdleTimeout(d time.Duration) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	pool.idle = d
+}
+
+// A pooledClient is the state the clients of a ProviderConfig share as long as
+// its credentials and configuration stay the same: the configuration holding
+// the credentials, and what is known about the signing account.
+type pooledClient struct {
+	// config and credentials are hashes of the configuration and the
+	// credentials the state was prepared for, and secret the namespace/name
+	// of the Secret the credentials were extracted from, if any.
+	config      string
+	credentials string
+	secret      string
+
+	prepared AkashProviderConfiguration
+	cache    *credentialCache
+
+	// fetched is when the credentials were last extracted, lastUsed when a
+	// client was last created from the state.
+	fetched  time.Time
+	lastUsed time.Time
+
+	mu            sync.Mutex
+	address       string
+	accountExists bool
+}
+
+// A clientPool holds the state shared by the clients of every ProviderConfig,
+// keyed by ProviderConfig name. State is only reused while the hashes of the
+// configuration and the credentials match.
+type clientPool struct {
+	mu      sync.Mutex
+	idle    time.Duration
+	entries map[string]*pooledClient
+}
+
+func newClientPool(idle time.Duration) *clientPool {
+	return &clientPool{idle: idle, entries: map[string]*pooledClient{}}
+}
+
+// acquire returns the pooled state of the ProviderConfig name with
+// configuration config, whose credentials are held by the Secret secret, if
+// any. extract is called to extract the credentials when
+// there is no state or its credentials are due to be checked, and prepare to
+// prepare the state for new credentials.
+func (p *clientPool) acquire(name, secret string, config AkashProviderConfiguration, extract func() ([]byte, error), prepare func([]byte) (AkashProviderConfiguration, error)) (*pooledClient, error) {
+	now := time.Now()
+	configHash := hash(config)
+
+	p.mu.Lock()
+	p.evict(now)
+	idle := p.idle
+	e := p.entries[name]
+	if e != nil && e.config == configHash && now.Sub(e.fetched) < poolCredentialTTL {
+		e.lastUsed = now
+		p.mu.Unlock()
+		clientPoolLookups.WithLabelValues(cacheHit).Inc()
+		return e, nil
+	}
+	p.mu.Unlock()
+
+	creds, err := extract()
+	if err != nil {
+		return nil, err
+	}
+	credHash := hash(creds)
+
+	if idle > 0 && name != "" && e != nil && e.config == configHash && e.credentials == credHash {
+		p.mu.Lock()
+		e.fetched, e.lastUsed = now, now
+		p.mu.Unlock()
+		clientPoolLookups.WithLabelValues(cacheExpired).Inc()
+		return e, nil
+	}
+
+	prepared, err := prepare(creds)
+	if err != nil {
+		return nil, err
+	}
+	e = &pooledClient{
+		config:      configHash,
+		credentials: credHash,
+		secret:      secret,
+		prepared:    prepared,
+		cache:       &credentialCache{credentials: creds, lastUpdated: now, ttl: poolCredentialTTL},
+		fetched:     now,
+		lastUsed:    now,
+	}
+	if idle <= 0 || name == "" {
+		return e, nil
+	}
+
+	p.mu.Lock()
+	p.entries[name] = e
+	p.mu.Unlock()
+	clientPoolLookups.WithLabelValues(cacheMiss).Inc()
+	return e, nil
+}
+
+// InvalidateSecret drops the state pooled for the ProviderConfigs whose
+// credentials are held by the Secret namespace/name, e.g. because it changed.
+// Their next clients extract the credentials anew, and clients already
+// created reload the credentials on their next use. It returns how many
+// ProviderConfigs were affected.
+func InvalidateSecret(namespace, name string) int {
+	return pool.invalidate(namespace + "/" + name)
+}
+
+func (p *clientPool) invalidate(secret string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for name, e := range p.entries {
+		if e.secret != secret {
+			continue
+		}
+		delete(p.entries, name)
+		e.cache.mu.Lock()
+		e.cache.credentials = nil
+		e.cache.mu.Unlock()
+		n++
+	}
+	clientPoolSize.Set(float64(len(p.entries)))
+	credentialInvalidations.Add(float64(n))
+	return n
+}
+
+// evict removes the state unused for longer than the idle timeout. The
+// caller must hold the lock.
+func (p *clientPool) evict(now time.Time) {
+	for name, e := range p.entries {
+		if p.idle <= 0 || now.Sub(e.lastUsed) > p.idle {
+			delete(p.entries, name)
+		}
+	}
+	clientPoolSize.Set(float64(len(p.entries)))
+}
+
+// verifiedAccount returns the verified address of the signing account, if
+// any, and whether the account is known to exist on chain.
+func (e *pooledClient) verifiedAccount() (string, bool) {
+	if e == nil {
+		return "", false
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.address, e.accountExists
+}
+
+// verifyAddress records the address derived from the signing key.
+func (e *pooledClient) verifyAddress(address string) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.address = address
+}
+
+// verifyAccount records that the signing account exists on chain.
+func (e *pooledClient) verifyAccount() {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.accountExists = true
+}
+
+// hash returns a hash of the JSON encoding of v.
+func hash(v any) string {
+	b, _ := json.Marshal(v) //nolint:errchkjson // Configurations and credentials always encode.
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
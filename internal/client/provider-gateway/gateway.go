@@ -0,0 +1,368 @@
+package provider_gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"github.com/overlock-network/provider-akash/internal/client/types"
+	"github.com/overlock-network/provider-akash/internal/tracing"
+)
+
+// DefaultTimeout bounds a single request to a provider gateway.
+const DefaultTimeout = 30 * time.Second
+
+// An APIVersion is a version of the gateway API exposed by provider-services.
+// Providers of different releases serve their endpoints under different
+// paths.
+type APIVersion string
+
+// Gateway API versions, from the newest to the oldest.
+const (
+	APIVersionV2 APIVersion = "v2"
+	APIVersionV1 APIVersion = "v1"
+)
+
+// apiVersions are the supported gateway API versions in order of preference.
+var apiVersions = []APIVersion{APIVersionV2, APIVersionV1}
+
+// prefix returns the path prefix the endpoints of the version are served at.
+func (v APIVersion) prefix() string {
+	if v == APIVersionV1 {
+		return ""
+	}
+	return "/" + string(v)
+}
+
+// MinimumProviderVersion is the oldest provider-services release whose
+// gateway API is supported.
+const MinimumProviderVersion = "0.4.0"
+
+// ErrUnsupportedAPIVersion is returned when a provider gateway does not serve
+// any of the supported API versions.
+var ErrUnsupportedAPIVersion = errors.New("provider gateway does not serve a supported API version")
+
+// versionInfo is the response of the version endpoint of a provider gateway.
+type versionInfo struct {
+	Akash struct {
+		Version string `json:"version"`
+	} `json:"akash"`
+}
+
+// IsUnsupportedAPIVersion reports whether err was caused by a provider gateway
+// not serving any of the supported API versions.
+func IsUnsupportedAPIVersion(err error) bool {
+	return errors.Is(err, ErrUnsupportedAPIVersion)
+}
+
+// ErrManifestNotFound is returned when a provider does not store the manifest
+// of a deployment.
+var ErrManifestNotFound = errors.New("manifest not found")
+
+// IsManifestNotFound reports whether err was caused by a provider not storing
+// the manifest of a deployment.
+func IsManifestNotFound(err error) bool {
+	return errors.Is(err, ErrManifestNotFound)
+}
+
+// errNotFound is returned by get when the gateway answers 404, which usually
+// means the requested path belongs to another API version.
+var errNotFound = errors.New("response status code 404")
+
+type cachedResponse struct {
+	etag         string
+	lastModified string
+	body         []byte
+}
+
+// GatewayClient queries the HTTP gateway of Akash providers. Responses that
+// carry an ETag or Last-Modified header are cached and revalidated with
+// conditional requests, so frequent probing of an unchanged lease costs the
+// provider a 304 instead of a full response.
+type GatewayClient struct {
+	http *http.Client
+	tls  *tls.Config
+
+	mu       sync.Mutex
+	cache    map[string]cachedResponse
+	versions map[string]APIVersion
+}
+
+// New creates a new GatewayClient authenticating with the given TLS
+// configuration, which should carry the client certificate of the tenant.
+func New(tlsConfig *tls.Config) *GatewayClient {
+	return &GatewayClient{
+		http: &http.Client{
+			Timeout:   DefaultTimeout,
+			Transport: tracing.NewTransport(&http.Transport{TLSClientConfig: tlsConfig}),
+		},
+		tls:      tlsConfig,
+		cache:    map[string]cachedResponse{},
+		versions: map[string]APIVersion{},
+	}
+}
+
+// APIVersion returns the gateway API version served by the provider at
+// hostURI. The version is negotiated on first use by probing the version
+// endpoint of each supported API version, newest first, and remembered
+// afterwards. Gateways that predate the version endpoint are assumed to serve
+// the oldest API version.
+func (c *GatewayClient) APIVersion(ctx context.Context, hostURI string) (APIVersion, error) {
+	host := strings.TrimSuffix(hostURI, "/")
+
+	c.mu.Lock()
+	v, ok := c.versions[host]
+	c.mu.Unlock()
+	if ok {
+		return v, nil
+	}
+
+	v, err := c.negotiate(ctx, host)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	c.versions[host] = v
+	c.mu.Unlock()
+	return v, nil
+}
+
+func (c *GatewayClient) negotiate(ctx context.Context, host string) (APIVersion, error) {
+	for _, v := range apiVersions {
+		body, err := c.get(ctx, host+v.prefix()+"/version")
+		if errors.Is(err, errNotFound) {
+			continue
+		}
+		if err != nil {
+			return "", errors.Wrapf(err, "cannot probe gateway API version %s", v)
+		}
+		if err := checkProviderVersion(body); err != nil {
+			return "", errors.Wrap(err, host)
+		}
+		return v, nil
+	}
+
+	return apiVersions[len(apiVersions)-1], nil
+}
+
+// checkProviderVersion returns ErrUnsupportedAPIVersion if the version
+// endpoint reports a provider-services release older than
+// MinimumProviderVersion. Releases that cannot be parsed are let through.
+func checkProviderVersion(body []byte) error {
+	info := versionInfo{}
+	if err := json.Unmarshal(body, &info); err != nil || info.Akash.Version == "" {
+		return nil
+	}
+
+	release, err := version.ParseGeneric(info.Akash.Version)
+	if err != nil {
+		return nil
+	}
+	if !release.AtLeast(version.MustParseGeneric(MinimumProviderVersion)) {
+		return errors.Wrapf(ErrUnsupportedAPIVersion, "provider-services %s is older than %s", info.Akash.Version, MinimumProviderVersion)
+	}
+
+	return nil
+}
+
+// forgetAPIVersion drops the negotiated API version of a host, e.g. after the
+// provider was upgraded and the paths of the remembered version disappeared.
+func (c *GatewayClient) forgetAPIVersion(host string) {
+	c.mu.Lock()
+	delete(c.versions, host)
+	c.mu.Unlock()
+}
+
+// getVersioned returns the body at path under the negotiated API version of
+// the provider at hostURI. The version is renegotiated once if the path is not
+// found, since the provider may have been upgraded in the meantime.
+func (c *GatewayClient) getVersioned(ctx context.Context, hostURI string, path string) ([]byte, error) {
+	return c.versioned(ctx, hostURI, path, func(addr string) ([]byte, error) {
+		return c.get(ctx, addr)
+	})
+}
+
+// versioned calls do with the address of path under the negotiated API
+// version of the provider at hostURI, renegotiating the version once if the
+// path is not found.
+func (c *GatewayClient) versioned(ctx context.Context, hostURI string, path string, do func(addr string) ([]byte, error)) ([]byte, error) {
+	host := strings.TrimSuffix(hostURI, "/")
+
+	for attempt := 0; ; attempt++ {
+		v, err := c.APIVersion(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := do(host + v.prefix() + path)
+		if errors.Is(err, errNotFound) && attempt == 0 {
+			c.forgetAPIVersion(host)
+			continue
+		}
+		return body, err
+	}
+}
+
+// Version returns the provider-services release run by the provider at
+// hostURI, or an empty string for gateways that predate the version endpoint.
+// The version endpoint does not require a client certificate.
+func (c *GatewayClient) Version(ctx context.Context, hostURI string) (string, error) {
+	body, err := c.getVersioned(ctx, hostURI, "/version")
+	if errors.Is(err, errNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	info := versionInfo{}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return "", errors.Wrap(err, "cannot decode version")
+	}
+	return info.Akash.Version, nil
+}
+
+// SubmitManifest submits the JSON encoded manifest of a deployment to the
+// gateway of the provider at hostURI, which has to lease the deployment.
+func (c *GatewayClient) SubmitManifest(ctx context.Context, hostURI string, dseq string, manifest []byte) error {
+	_, err := c.versioned(ctx, hostURI, "/deployment/"+dseq+"/manifest", func(addr string) ([]byte, error) {
+		return nil, c.send(ctx, http.MethodPut, addr, manifest)
+	})
+	return err
+}
+
+// GetManifest returns the JSON encoded manifest of a deployment stored by the
+// gateway of the provider at hostURI, or ErrManifestNotFound if none was
+// submitted.
+func (c *GatewayClient) GetManifest(ctx context.Context, hostURI string, dseq string) ([]byte, error) {
+	body, err := c.getVersioned(ctx, hostURI, "/deployment/"+dseq+"/manifest")
+	if errors.Is(err, errNotFound) {
+		return nil, errors.Wrapf(ErrManifestNotFound, "deployment %s", dseq)
+	}
+	return body, err
+}
+
+// migrateHostnamesRequest is the request of the hostname migration endpoint.
+type migrateHostnamesRequest struct {
+	Hostnames       []string `json:"hostnames"`
+	DestinationDseq uint64   `json:"destination_dseq"`
+	DestinationGseq uint32   `json:"destination_gseq"`
+}
+
+// MigrateHostnames moves hostnames served by another deployment of the same
+// owner to the group gseq of the deployment dseq, both leased by the provider
+// at hostURI. The destination has to accept the hostnames in its SDL.
+func (c *GatewayClient) MigrateHostnames(ctx context.Context, hostURI string, hostnames []string, dseq uint64, gseq uint32) error {
+	body, err := json.Marshal(migrateHostnamesRequest{Hostnames: hostnames, DestinationDseq: dseq, DestinationGseq: gseq})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.versioned(ctx, hostURI, "/hostname/migrate", func(addr string) ([]byte, error) {
+		return nil, c.send(ctx, http.MethodPost, addr, body)
+	})
+	return err
+}
+
+// GetLeaseStatus gets the status of a lease from the gateway of the provider
+// at hostURI.
+func (c *GatewayClient) GetLeaseStatus(ctx context.Context, hostURI string, dseq string, gseq string, oseq string) (types.LeaseStatus, error) {
+	body, err := c.getVersioned(ctx, hostURI, "/lease/"+dseq+"/"+gseq+"/"+oseq+"/status")
+	if err != nil {
+		return types.LeaseStatus{}, err
+	}
+
+	status := types.LeaseStatus{}
+	if err := json.Unmarshal(body, &status); err != nil {
+		return types.LeaseStatus{}, err
+	}
+
+	return status, nil
+}
+
+// send sends body to addr as JSON with the given method.
+func (c *GatewayClient) send(ctx context.Context, method string, addr string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, method, addr, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck // Nothing is lost when closing a body fails.
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return errNotFound
+	case resp.StatusCode/100 != 2:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("response status code %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// get returns the body at addr, revalidating a cached response when the
+// gateway sent validators for it before.
+func (c *GatewayClient) get(ctx context.Context, addr string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, addr, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	cached, ok := c.cache[addr]
+	c.mu.Unlock()
+	if ok {
+		if cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck // Nothing is lost when closing a body fails.
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && ok:
+		return cached.body, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, errNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("response status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	c.mu.Lock()
+	if etag != "" || lastModified != "" {
+		c.cache[addr] = cachedResponse{etag: etag, lastModified: lastModified, body: body}
+	} else {
+		delete(c.cache, addr)
+	}
+	c.mu.Unlock()
+
+	return body, nil
+}
@@ -0,0 +1,343 @@
+package provider_gateway
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/net/websocket"
+
+	"github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+func TestGetLeaseStatusConditional(t *testing.T) {
+	want := types.LeaseStatus{Services: map[string]types.ServiceStatus{"web": {Name: "web", Available: 1}}}
+
+	full, revalidated := 0, 0
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/lease/1/1/1/status" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidated++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("ETag", `"v1"`)
+		_ = json.NewEncoder(w).Encode(want)
+	}))
+	defer gateway.Close()
+
+	c := New(nil)
+	for i := 0; i < 3; i++ {
+		got, err := c.GetLeaseStatus(context.Background(), gateway.URL, "1", "1", "1")
+		if err != nil {
+			t.Fatalf("GetLeaseStatus() unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("GetLeaseStatus() mismatch (-want +got):\n%s", diff)
+		}
+	}
+
+	if full != 1 || revalidated != 2 {
+		t.Errorf("GetLeaseStatus() made %d full and %d conditional requests, want 1 and 2", full, revalidated)
+	}
+}
+
+func TestAPIVersion(t *testing.T) {
+	cases := map[string]struct {
+		paths   map[string]string
+		want    APIVersion
+		wantErr bool
+	}{
+		"V2": {
+			paths: map[string]string{"/v2/version": `{"akash":{"version":"v0.8.0"}}`, "/version": `{}`},
+			want:  APIVersionV2,
+		},
+		"V1": {
+			paths: map[string]string{"/version": `{"akash":{"version":"v0.6.4"}}`},
+			want:  APIVersionV1,
+		},
+		"Legacy": {
+			paths: map[string]string{},
+			want:  APIVersionV1,
+		},
+		"Unsupported": {
+			paths:   map[string]string{"/version": `{"akash":{"version":"v0.2.1"}}`},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, ok := tc.paths[r.URL.Path]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_, _ = w.Write([]byte(body))
+			}))
+			defer gateway.Close()
+
+			got, err := New(nil).APIVersion(context.Background(), gateway.URL)
+			if tc.wantErr != IsUnsupportedAPIVersion(err) {
+				t.Fatalf("APIVersion(): want unsupported %t, got %v", tc.wantErr, err)
+			}
+			if got != tc.want {
+				t.Errorf("APIVersion(): want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestVersion(t *testing.T) {
+	cases := map[string]struct {
+		paths map[string]string
+		want  string
+	}{
+		"V2": {
+			paths: map[string]string{"/v2/version": `{"akash":{"version":"v0.8.0"}}`},
+			want:  "v0.8.0",
+		},
+		"V1": {
+			paths: map[string]string{"/version": `{"akash":{"version":"v0.6.4"}}`},
+			want:  "v0.6.4",
+		},
+		"Legacy": {
+			paths: map[string]string{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, ok := tc.paths[r.URL.Path]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_, _ = w.Write([]byte(body))
+			}))
+			defer gateway.Close()
+
+			got, err := New(nil).Version(context.Background(), gateway.URL)
+			if err != nil {
+				t.Fatalf("Version() unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Version(): want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestGetLeaseStatusRenegotiates(t *testing.T) {
+	upgraded := false
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/version":
+			_, _ = w.Write([]byte(`{"akash":{"version":"v0.6.4"}}`))
+		case upgraded && r.URL.Path == "/v2/version":
+			_, _ = w.Write([]byte(`{"akash":{"version":"v0.8.0"}}`))
+		case upgraded && r.URL.Path == "/v2/lease/1/1/1/status", !upgraded && r.URL.Path == "/lease/1/1/1/status":
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer gateway.Close()
+
+	c := New(nil)
+	if _, err := c.GetLeaseStatus(context.Background(), gateway.URL, "1", "1", "1"); err != nil {
+		t.Fatalf("GetLeaseStatus() unexpected error: %v", err)
+	}
+
+	upgraded = true
+	if _, err := c.GetLeaseStatus(context.Background(), gateway.URL, "1", "1", "1"); err != nil {
+		t.Fatalf("GetLeaseStatus() after upgrade unexpected error: %v", err)
+	}
+	if v, _ := c.APIVersion(context.Background(), gateway.URL); v != APIVersionV2 {
+		t.Errorf("APIVersion() after upgrade: want %q, got %q", APIVersionV2, v)
+	}
+}
+
+func TestSubmitManifest(t *testing.T) {
+	manifest := []byte(`[{"name":"dcloud","services":[]}]`)
+
+	var got []byte
+	gateway := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/version":
+			_, _ = w.Write([]byte(`{"akash":{"version":"v0.8.0"}}`))
+		case r.URL.Path == "/v2/deployment/42/manifest" && r.Method == http.MethodPut:
+			if len(r.TLS.PeerCertificates) == 0 {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			got, _ = io.ReadAll(r.Body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	gateway.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	gateway.StartTLS()
+	defer gateway.Close()
+
+	c := New(&tls.Config{Certificates: []tls.Certificate{clientCertificate(t)}, InsecureSkipVerify: true})
+	if err := c.SubmitManifest(context.Background(), gateway.URL, "42", manifest); err != nil {
+		t.Fatalf("SubmitManifest() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(string(manifest), string(got)); diff != "" {
+		t.Errorf("SubmitManifest() body mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetManifest(t *testing.T) {
+	manifest := `[{"name":"dcloud","services":[]}]`
+
+	cases := map[string]struct {
+		stored  bool
+		want    string
+		wantErr bool
+	}{
+		"Stored": {
+			stored: true,
+			want:   manifest,
+		},
+		"NotFound": {
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/v2/version":
+					_, _ = w.Write([]byte(`{"akash":{"version":"v0.8.0"}}`))
+				case tc.stored && r.URL.Path == "/v2/deployment/42/manifest" && r.Method == http.MethodGet:
+					_, _ = w.Write([]byte(manifest))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer gateway.Close()
+
+			got, err := New(nil).GetManifest(context.Background(), gateway.URL, "42")
+			if tc.wantErr {
+				if !IsManifestNotFound(err) {
+					t.Fatalf("GetManifest(): want manifest not found, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetManifest() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+				t.Errorf("GetManifest() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// clientCertificate returns a self-signed client certificate.
+func TestMigrateHostnames(t *testing.T) {
+	var got []byte
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/version":
+			_, _ = w.Write([]byte(`{"akash":{"version":"v0.8.0"}}`))
+		case r.URL.Path == "/v2/hostname/migrate" && r.Method == http.MethodPost:
+			got, _ = io.ReadAll(r.Body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer gateway.Close()
+
+	if err := New(nil).MigrateHostnames(context.Background(), gateway.URL, []string{"api.example.com"}, 42, 1); err != nil {
+		t.Fatalf("MigrateHostnames() unexpected error: %v", err)
+	}
+	want := `{"hostnames":["api.example.com"],"destination_dseq":42,"destination_gseq":1}`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("MigrateHostnames() body mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestStreamLogs(t *testing.T) {
+	sent := []LogLine{
+		{Name: "web-5d8f7c9b6-x2x7k", Message: "listening on :8080"},
+		{Name: "my-db-7c9b6d8f5-k2l9p", Message: "ready to accept connections"},
+	}
+
+	var query string
+	logs := websocket.Handler(func(ws *websocket.Conn) {
+		query = ws.Request().URL.RawQuery
+		for _, l := range sent {
+			_ = websocket.JSON.Send(ws, l)
+		}
+	})
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/lease/1/1/1/logs" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		logs.ServeHTTP(w, r)
+	}))
+	defer gateway.Close()
+
+	got := []LogLine{}
+	services := []string{}
+	err := New(nil).StreamLogs(context.Background(), gateway.URL, "1", "1", "1", LogOptions{Services: []string{"web", "my-db"}, Tail: 10}, func(l LogLine) error {
+		got = append(got, l)
+		services = append(services, l.Service())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamLogs() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(sent, got); diff != "" {
+		t.Errorf("StreamLogs() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"web", "my-db"}, services); diff != "" {
+		t.Errorf("Service() mismatch (-want +got):\n%s", diff)
+	}
+	if want := "follow=false&service=web%2Cmy-db&tail=10"; query != want {
+		t.Errorf("StreamLogs() query = %q, want %q", query, want)
+	}
+}
+
+func clientCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "akash1owner"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
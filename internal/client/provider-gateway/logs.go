@@ -0,0 +1,112 @@
+package provider_gateway
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/websocket"
+)
+
+const errStreamLogs = "cannot stream lease logs"
+
+// A LogLine is a line logged by a service of a lease.
+type LogLine struct {
+	// Name is the name of the pod that logged the line, e.g.
+	// web-5d8f7c9b6-x2x7k.
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+// Service returns the name of the SDL service that logged the line, which is
+// the name of its pod without the replica set and pod suffixes.
+func (l LogLine) Service() string {
+	parts := strings.Split(l.Name, "-")
+	if len(parts) < 3 {
+		return l.Name
+	}
+	return strings.Join(parts[:len(parts)-2], "-")
+}
+
+// LogOptions select the logs streamed by StreamLogs.
+type LogOptions struct {
+	// Services whose logs are streamed. All services are streamed when empty.
+	Services []string
+	// Tail is the number of past lines of each service streamed first.
+	Tail int
+	// Follow keeps the stream open for new lines.
+	Follow bool
+}
+
+// StreamLogs streams the logs of the services of a lease from the gateway of
+// the provider at hostURI, calling fn for every line, until ctx is done, the
+// gateway closes the stream or fn returns an error.
+func (c *GatewayClient) StreamLogs(ctx context.Context, hostURI string, dseq string, gseq string, oseq string, opts LogOptions, fn func(LogLine) error) error {
+	host := strings.TrimSuffix(hostURI, "/")
+	v, err := c.APIVersion(ctx, host)
+	if err != nil {
+		return err
+	}
+
+	q := url.Values{}
+	q.Set("follow", strconv.FormatBool(opts.Follow))
+	q.Set("tail", strconv.Itoa(opts.Tail))
+	if len(opts.Services) > 0 {
+		q.Set("service", strings.Join(opts.Services, ","))
+	}
+	addr := host + v.prefix() + "/lease/" + dseq + "/" + gseq + "/" + oseq + "/logs?" + q.Encode()
+
+	cfg, err := websocket.NewConfig(websocketURL(addr), host)
+	if err != nil {
+		return errors.Wrap(err, errStreamLogs)
+	}
+	cfg.TlsConfig = c.tls
+	cfg.Dialer = &net.Dialer{Timeout: DefaultTimeout}
+	ws, err := websocket.DialConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, errStreamLogs)
+	}
+
+	// Receiving blocks until the next line, so closing the connection is the
+	// only way to stop waiting once ctx is done.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		ws.Close() //nolint:errcheck // The stream is over either way.
+	}()
+
+	for {
+		line := LogLine{}
+		if err := websocket.JSON.Receive(ws, &line); err != nil {
+			switch {
+			case ctx.Err() != nil:
+				return ctx.Err()
+			case errors.Is(err, io.EOF):
+				return nil
+			}
+			return errors.Wrap(err, errStreamLogs)
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+}
+
+// websocketURL returns the WebSocket URL of the HTTP URL addr.
+func websocketURL(addr string) string {
+	switch {
+	case strings.HasPrefix(addr, "https://"):
+		return "wss://" + strings.TrimPrefix(addr, "https://")
+	case strings.HasPrefix(addr, "http://"):
+		return "ws://" + strings.TrimPrefix(addr, "http://")
+	}
+	return addr
+}
@@ -1,24 +1,267 @@
 package client
 
 import (
-	"fmt"
+	"context"
+	"crypto/tls"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/overlock-network/provider-akash/internal/client/cli"
+	"github.com/pkg/errors"
+
+	"github.com/overlock-network/provider-akash/internal/cert"
+	gateway "github.com/overlock-network/provider-akash/internal/client/provider-gateway"
+	providersapi "github.com/overlock-network/provider-akash/internal/client/providers-api"
+	"github.com/overlock-network/provider-akash/internal/client/types"
+	"github.com/overlock-network/provider-akash/internal/sdl"
 )
 
-func (ak *AkashClient) SendManifest(dseq string, provider string, manifestLocation string) (string, error) {
+const (
+	errReadManifest  = "cannot read SDL"
+	errBuildManifest = "cannot build manifest"
+	errProviderHost  = "cannot find the gateway of provider %s"
+	errGatewayHost   = "invalid gateway URI %q of provider %s"
+)
 
-	cmd := cli.AkashCli(ak).SendManifest(manifestLocation).
-		SetDseq(dseq).SetProvider(provider).SetHome(ak.Config.Home).
-		SetKeyringBackend(ak.Config.KeyringBackend).SetFrom(ak.Config.KeyName).
-		SetNode(ak.Config.Node).OutputJson()
+// ErrGatewayUnreachable is returned when the gateway of a provider did not
+// answer a query, as opposed to answering with an unsupported API version.
+var ErrGatewayUnreachable = errors.New("provider gateway is unreachable")
 
-	out, err := cmd.Raw()
+// SendManifest builds the manifest of the SDL at manifestLocation and submits
+// it to the gateway of the provider leasing the deployment, see
+// SubmitManifest. No response body is returned by the gateway, so the
+// returned response only names the provider.
+func (ak *AkashClient) SendManifest(ctx context.Context, dseq string, provider string, manifestLocation string) (string, error) {
+	defer ak.begin(ctx, opManifest)()
+	content, err := os.ReadFile(manifestLocation) //nolint:gosec // The SDL is written by the controller.
 	if err != nil {
+		return "", errors.Wrap(err, errReadManifest)
+	}
+	if err := ak.SubmitManifest(ak.ctx, dseq, provider, content); err != nil {
 		return "", err
 	}
 
-	fmt.Printf("Response content: %s\n", out)
+	return "manifest sent to " + provider, nil
+}
+
+// SubmitManifest builds the manifest of the SDL content and submits it to the
+// gateway of the provider leasing the deployment, authenticating with the
+// client certificate of the owner, which is generated and published first if
+// there is no valid one.
+func (ak *AkashClient) SubmitManifest(ctx context.Context, dseq string, provider string, content []byte) error {
+	defer ak.begin(ctx, opManifest)()
+	_, manifests, err := sdl.ParseSDL(content)
+	if err != nil {
+		return errors.Wrap(err, errBuildManifest)
+	}
+	manifest, err := sdl.MarshalManifest(manifests)
+	if err != nil {
+		return errors.Wrap(err, errBuildManifest)
+	}
+
+	hostURI, err := ak.providerHostURI(provider)
+	if err != nil {
+		return err
+	}
+
+	gw, err := ak.gatewayClient(provider, hostURI)
+	if err != nil {
+		return err
+	}
+	return gw.SubmitManifest(ak.ctx, hostURI, dseq, manifest)
+}
+
+// GetManifest returns the JSON encoded manifest of the deployment dseq stored
+// by the gateway of provider. The error satisfies
+// gateway.IsManifestNotFound if none was submitted.
+func (ak *AkashClient) GetManifest(ctx context.Context, dseq string, provider string) ([]byte, error) {
+	defer ak.begin(ctx, opQuery)()
+	hostURI, err := ak.providerHostURI(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	gw, err := ak.gatewayClient(provider, hostURI)
+	if err != nil {
+		return nil, err
+	}
+	return gw.GetManifest(ak.ctx, hostURI, dseq)
+}
+
+// StreamLeaseLogs streams the logs of the services of the lease of seqs from
+// the gateway of provider, calling fn for every line, until ctx is done or the
+// stream ends.
+func (ak *AkashClient) StreamLeaseLogs(ctx context.Context, seqs Seqs, provider string, opts gateway.LogOptions, fn func(gateway.LogLine) error) error {
+	hostURI, err := ak.providerHostURI(provider)
+	if err != nil {
+		return err
+	}
+
+	gw, err := ak.gatewayClient(provider, hostURI)
+	if err != nil {
+		return err
+	}
+	return gw.StreamLogs(ctx, hostURI, seqs.Dseq, seqs.Gseq, seqs.Oseq, opts, fn)
+}
+
+// GetLeaseStatus queries the gateway of the provider of the lease id for its
+// status, including the URIs, ports and IPs it assigned to the services of
+// the deployment.
+func (ak *AkashClient) GetLeaseStatus(ctx context.Context, id types.LeaseId) (types.LeaseStatus, error) {
+	defer ak.begin(ctx, opQuery)()
+	hostURI, err := ak.providerHostURI(id.Provider)
+	if err != nil {
+		return types.LeaseStatus{}, err
+	}
+
+	gw, err := ak.gatewayClient(id.Provider, hostURI)
+	if err != nil {
+		return types.LeaseStatus{}, err
+	}
+	status, err := gw.GetLeaseStatus(ak.ctx, hostURI, id.Dseq, strconv.Itoa(id.Gseq), strconv.Itoa(id.Oseq))
+	if err != nil && !gateway.IsUnsupportedAPIVersion(err) {
+		return types.LeaseStatus{}, errors.Wrap(ErrGatewayUnreachable, err.Error())
+	}
+	return status, err
+}
 
-	return string(out), nil
+// gatewayClient returns a client of the gateway of provider at hostURI
+// authenticating with the client certificate of the owner, which is
+// generated and published first if there is no valid one.
+func (ak *AkashClient) gatewayClient(provider string, hostURI string) (*gateway.GatewayClient, error) {
+	owner, err := ak.AccountAddress()
+	if err != nil {
+		return nil, err
+	}
+	// Make sure a client certificate is published before using it.
+	c, err := cert.Ensure(ak.ctx, ak, ak.Config.Home, owner, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := ak.gatewayTLS(provider, hostURI)
+	if err != nil {
+		return nil, err
+	}
+	config.Certificates = []tls.Certificate{c}
+	return gateway.New(config), nil
+}
+
+// gatewayTLS returns the TLS configuration of connections to the gateway of
+// provider at hostURI. Providers serve self-signed certificates, which are
+// not verifiable against the system roots, so the certificate of the gateway
+// must instead be the one provider published on chain.
+func (ak *AkashClient) gatewayTLS(provider string, hostURI string) (*tls.Config, error) {
+	u, err := url.Parse(hostURI)
+	if err != nil || u.Hostname() == "" {
+		return nil, errors.Errorf(errGatewayHost, hostURI, provider)
+	}
+	return &tls.Config{
+		// The certificate is verified by VerifyPeerCertificate instead.
+		InsecureSkipVerify:    true, //nolint:gosec // See above.
+		VerifyPeerCertificate: cert.VerifyProvider(ak.ctx, ak, provider, u.Hostname(), time.Now),
+	}, nil
+}
+
+// MigrateHostname moves hostname, served by another deployment of the owner
+// on the same provider, to the group of the lease id.
+func (ak *AkashClient) MigrateHostname(ctx context.Context, hostname string, id types.LeaseId) error {
+	defer ak.begin(ctx, opManifest)()
+	dseq, err := strconv.ParseUint(id.Dseq, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid dseq %q", id.Dseq)
+	}
+
+	hostURI, err := ak.providerHostURI(id.Provider)
+	if err != nil {
+		return err
+	}
+
+	gw, err := ak.gatewayClient(id.Provider, hostURI)
+	if err != nil {
+		return err
+	}
+	return gw.MigrateHostnames(ak.ctx, hostURI, []string{hostname}, dseq, uint32(id.Gseq)) //nolint:gosec // Group sequences are small.
+}
+
+// GetProviders returns the providers known to the providers API.
+func (ak *AkashClient) GetProviders(ctx context.Context) ([]types.Provider, error) {
+	defer ak.begin(ctx, opQuery)()
+	return providersapi.New(ak.Config.ProvidersApi).GetAllProviders(ak.ctx)
+}
+
+// GetProvider queries the on-chain record of the provider at address.
+func (ak *AkashClient) GetProvider(ctx context.Context, address string) (types.ProviderRecord, error) {
+	defer ak.begin(ctx, opQuery)()
+	q, err := ak.grpcQuery()
+	if err != nil {
+		return types.ProviderRecord{}, err
+	}
+	return q.Provider(ak.ctx, address)
+}
+
+// IsProviderNotFound reports whether err is the chain reporting that a
+// provider does not exist.
+func IsProviderNotFound(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "not found")
+}
+
+// ProviderVersion returns the provider-services release served by the gateway
+// of provider at hostURI, which proves the gateway reachable. No client
+// certificate is needed, so nothing is published on chain to probe a
+// provider.
+func (ak *AkashClient) ProviderVersion(ctx context.Context, provider string, hostURI string) (string, error) {
+	defer ak.begin(ctx, opQuery)()
+	config, err := ak.gatewayTLS(provider, hostURI)
+	if err != nil {
+		return "", err
+	}
+	return gateway.New(config).Version(ak.ctx, hostURI)
+}
+
+// GetProviderAudits returns the attributes of provider signed by auditors on
+// chain, by auditor.
+func (ak *AkashClient) GetProviderAudits(ctx context.Context, provider string) ([]types.AuditedAttributes, error) {
+	defer ak.begin(ctx, opQuery)()
+	q, err := ak.grpcQuery()
+	if err != nil {
+		return nil, err
+	}
+	audits, err := q.ProviderAudits(ak.ctx, provider)
+	// The chain reports providers nobody audited as not found.
+	if IsProviderNotFound(err) {
+		return nil, nil
+	}
+	return audits, err
+}
+
+// GetProviderAuditors returns the auditors that signed attributes of
+// provider on chain.
+func (ak *AkashClient) GetProviderAuditors(ctx context.Context, provider string) ([]string, error) {
+	defer ak.begin(ctx, opQuery)()
+	audits, err := ak.GetProviderAudits(ak.ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	auditors := make([]string, 0, len(audits))
+	for _, a := range audits {
+		auditors = append(auditors, a.Auditor)
+	}
+	return auditors, nil
+}
+
+// providerHostURI returns the gateway URI of provider.
+func (ak *AkashClient) providerHostURI(provider string) (string, error) {
+	providers, err := ak.GetProviders(ak.ctx)
+	if err != nil {
+		return "", errors.Wrapf(err, errProviderHost, provider)
+	}
+	p, ok := IndexProviders(providers)[provider]
+	if !ok || p.HostURI == "" {
+		return "", errors.Errorf(errProviderHost, provider)
+	}
+	return p.HostURI, nil
 }
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	apisv1alpha1 "github.com/overlock-network/provider-akash/apis/v1alpha1"
+)
+
+// LabelKeyClaimNamespace is set by Crossplane on the resources composed for a
+// claim to the namespace of the claim.
+const LabelKeyClaimNamespace = "crossplane.io/claim-namespace"
+
+const (
+	errNoProviderConfig    = "managed resource does not reference a ProviderConfig"
+	errGetProviderConfig   = "cannot get ProviderConfig"
+	errGetNamespacedConfig = "cannot get NamespacedProviderConfig"
+	errNotClaimed          = "only resources composed for a claim can reference a NamespacedProviderConfig"
+	errNamespacedSource    = "credentials of a NamespacedProviderConfig must be a Secret"
+	errNamespacedSecret    = "credentials of a NamespacedProviderConfig must be a Secret in its namespace %s"
+)
+
+// A namespacedProviderConfigReferencer may reference a
+// NamespacedProviderConfig.
+type namespacedProviderConfigReferencer interface {
+	GetNamespacedProviderConfigReference() *xpv1.Reference
+}
+
+// GetProviderConfig returns the configuration mg uses: the
+// NamespacedProviderConfig it references, in the namespace of the claim it was
+// composed for, if it references one, and the ProviderConfig it references
+// otherwise. Resources composed for claims may use either. A resource
+// referencing a NamespacedProviderConfig never falls back to its
+// ProviderConfig, so that tenants cannot sign with the credentials of the
+// provider by mistake.
+func GetProviderConfig(ctx context.Context, kube client.Reader, mg resource.Managed) (ProviderConfigInfo, error) {
+	if r, ok := mg.(namespacedProviderConfigReferencer); ok {
+		if ref := r.GetNamespacedProviderConfigReference(); ref != nil {
+			ns := mg.GetLabels()[LabelKeyClaimNamespace]
+			if ns == "" {
+				return ProviderConfigInfo{}, errors.New(errNotClaimed)
+			}
+			npc := &apisv1alpha1.NamespacedProviderConfig{}
+			if err := kube.Get(ctx, types.NamespacedName{Namespace: ns, Name: ref.Name}, npc); err != nil {
+				return ProviderConfigInfo{}, errors.Wrap(err, errGetNamespacedConfig)
+			}
+			return NewNamespacedProviderConfigInfo(npc)
+		}
+	}
+
+	ref := mg.GetProviderConfigReference()
+	if ref == nil {
+		return ProviderConfigInfo{}, errors.New(errNoProviderConfig)
+	}
+	pc := &apisv1alpha1.ProviderConfig{}
+	if err := kube.Get(ctx, types.NamespacedName{Name: ref.Name}, pc); err != nil {
+		return ProviderConfigInfo{}, err
+	}
+	return NewProviderConfigInfo(pc), nil
+}
+
+// NewNamespacedProviderConfigInfo extracts the credentials and configuration
+// of a NamespacedProviderConfig. Its credentials must be a Secret in its own
+// namespace, so that it cannot sign with the credentials of the provider or
+// of other namespaces.
+func NewNamespacedProviderConfigInfo(pc *apisv1alpha1.NamespacedProviderConfig) (ProviderConfigInfo, error) {
+	creds := pc.Spec.Credentials
+	if creds.Source != xpv1.CredentialsSourceSecret || creds.SecretRef == nil {
+		return ProviderConfigInfo{}, errors.New(errNamespacedSource)
+	}
+	selectors := *creds.CommonCredentialSelectors.DeepCopy()
+	switch selectors.SecretRef.Namespace {
+	case "":
+		selectors.SecretRef.Namespace = pc.GetNamespace()
+	case pc.GetNamespace():
+	default:
+		return ProviderConfigInfo{}, errors.Errorf(errNamespacedSecret, pc.GetNamespace())
+	}
+
+	return ProviderConfigInfo{
+		Name:                pc.GetName(),
+		Namespace:           pc.GetNamespace(),
+		Source:              creds.Source,
+		CredentialSelectors: selectors,
+		Configuration:       pc.Spec.Configuration,
+		Endpoints:           pc.Status.Endpoints,
+	}, nil
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	resourcev1alpha1 "github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	apisv1alpha1 "github.com/overlock-network/provider-akash/apis/v1alpha1"
+)
+
+func TestGetProviderConfig(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := apisv1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	secretRef := func(ns string) *xpv1.SecretKeySelector {
+		return &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Namespace: ns, Name: "akash"}, Key: "mnemonic"}
+	}
+	cluster := &apisv1alpha1.ProviderConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: apisv1alpha1.ProviderConfigSpec{Credentials: apisv1alpha1.ProviderCredentials{
+			Source:                    xpv1.CredentialsSourceSecret,
+			CommonCredentialSelectors: xpv1.CommonCredentialSelectors{SecretRef: secretRef("crossplane-system")},
+		}},
+	}
+	namespaced := func(ns string) *apisv1alpha1.NamespacedProviderConfig {
+		return &apisv1alpha1.NamespacedProviderConfig{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "default"},
+			Spec: apisv1alpha1.ProviderConfigSpec{Credentials: apisv1alpha1.ProviderCredentials{
+				Source:                    xpv1.CredentialsSourceSecret,
+				CommonCredentialSelectors: xpv1.CommonCredentialSelectors{SecretRef: secretRef(ns)},
+			}},
+		}
+	}
+
+	type want struct {
+		info ProviderConfigInfo
+		err  bool
+	}
+	cases := map[string]struct {
+		objects    []runtime.Object
+		labels     map[string]string
+		namespaced bool
+		want       want
+	}{
+		"Cluster": {
+			objects: []runtime.Object{cluster, namespaced("")},
+			want: want{info: ProviderConfigInfo{
+				Name:                "default",
+				Source:              xpv1.CredentialsSourceSecret,
+				CredentialSelectors: xpv1.CommonCredentialSelectors{SecretRef: secretRef("crossplane-system")},
+			}},
+		},
+		"ClaimedCluster": {
+			objects: []runtime.Object{cluster, namespaced("")},
+			labels:  map[string]string{LabelKeyClaimNamespace: "team-a"},
+			want: want{info: ProviderConfigInfo{
+				Name:                "default",
+				Source:              xpv1.CredentialsSourceSecret,
+				CredentialSelectors: xpv1.CommonCredentialSelectors{SecretRef: secretRef("crossplane-system")},
+			}},
+		},
+		"Namespaced": {
+			objects:    []runtime.Object{cluster, namespaced("")},
+			labels:     map[string]string{LabelKeyClaimNamespace: "team-a"},
+			namespaced: true,
+			want: want{info: ProviderConfigInfo{
+				Name:                "default",
+				Namespace:           "team-a",
+				Source:              xpv1.CredentialsSourceSecret,
+				CredentialSelectors: xpv1.CommonCredentialSelectors{SecretRef: secretRef("team-a")},
+			}},
+		},
+		"NotClaimed": {
+			objects:    []runtime.Object{cluster, namespaced("")},
+			namespaced: true,
+			want:       want{err: true},
+		},
+		"NoNamespacedConfig": {
+			objects:    []runtime.Object{cluster},
+			labels:     map[string]string{LabelKeyClaimNamespace: "team-a"},
+			namespaced: true,
+			want:       want{err: true},
+		},
+		"SecretInOtherNamespace": {
+			objects:    []runtime.Object{cluster, namespaced("crossplane-system")},
+			labels:     map[string]string{LabelKeyClaimNamespace: "team-a"},
+			namespaced: true,
+			want:       want{err: true},
+		},
+		"NotFound": {
+			want: want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(tc.objects...).Build()
+			cr := &resourcev1alpha1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: tc.labels}}
+			cr.SetProviderConfigReference(&xpv1.Reference{Name: "default"})
+			if tc.namespaced {
+				cr.Spec.NamespacedProviderConfigReference = &xpv1.Reference{Name: "default"}
+			}
+
+			info, err := GetProviderConfig(context.Background(), kube, cr)
+			if (err != nil) != tc.want.err {
+				t.Fatalf("GetProviderConfig(...): unexpected error %v", err)
+			}
+			if diff := cmp.Diff(tc.want.info, info); diff != "" {
+				t.Errorf("GetProviderConfig(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
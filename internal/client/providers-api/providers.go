@@ -8,11 +8,14 @@ import (
 	"os"
 
 	"github.com/overlock-network/provider-akash/internal/client/types"
+	"github.com/overlock-network/provider-akash/internal/tracing"
 )
 
 type provider struct {
 	Address    string            `json:"address"`
+	HostURI    string            `json:"hostUri"`
 	Active     bool              `json:"active"`
+	IsAudited  bool              `json:"isAudited"`
 	Uptime     uptime            `json:"uptime"`
 	Attributes map[string]string `json:"extraAttributes"`
 }
@@ -34,22 +37,18 @@ func New(host string) *ProvidersClient {
 }
 
 // GetAllProviders gets all the providers from the providers' API. Returns error in case something goes wrong.
-func (c *ProvidersClient) GetAllProviders() ([]types.Provider, error) {
+func (c *ProvidersClient) GetAllProviders(ctx context.Context) ([]types.Provider, error) {
 	addr := c.host + "/provider" + string(os.PathSeparator)
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, addr, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, addr, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := tracing.DefaultClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		if cerr := resp.Body.Close(); cerr != nil {
-			fmt.Printf("error closing response body: %v\n", cerr)
-		}
-	}()
+	defer resp.Body.Close() //nolint:errcheck // Nothing is lost when closing a body fails.
 
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("response status code %d", resp.StatusCode)
@@ -68,7 +67,9 @@ func (c *ProvidersClient) GetAllProviders() ([]types.Provider, error) {
 		// TODO: Fix bad design. Dependency on types of other API
 		providers = append(providers, types.Provider{
 			Address:    provider.Address,
+			HostURI:    provider.HostURI,
 			Active:     provider.Active,
+			Audited:    provider.IsAudited,
 			Uptime:     provider.Uptime.Percentage,
 			Attributes: provider.Attributes,
 		})
@@ -78,8 +79,8 @@ func (c *ProvidersClient) GetAllProviders() ([]types.Provider, error) {
 }
 
 // GetActiveProviders gets the active providers from the providers' API.
-func (c *ProvidersClient) GetActiveProviders() ([]types.Provider, error) {
-	providers, err := c.GetAllProviders()
+func (c *ProvidersClient) GetActiveProviders(ctx context.Context) ([]types.Provider, error) {
+	providers, err := c.GetAllProviders(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,16 @@
+package client
+
+import (
+	"github.com/pkg/errors"
+
+	grpcquery "github.com/overlock-network/provider-akash/internal/client/grpc-query"
+)
+
+// grpcQuery returns the client of the gRPC endpoint the chain is queried
+// through.
+func (ak *AkashClient) grpcQuery() (*grpcquery.QueryClient, error) {
+	if ak.Config.GRPCEndpoint == "" {
+		return nil, errors.New(errNoGRPCEndpoint)
+	}
+	return grpcquery.New(ak.Config.GRPCEndpoint)
+}
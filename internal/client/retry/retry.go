@@ -0,0 +1,172 @@
+// Package retry retries chain calls failing with transient errors, e.g.
+// timeouts, rate limits or a full mempool, with exponential backoff and
+// jitter. The policy travels with the context of the calls, so that every
+// transport retries alike.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Defaults of a Policy.
+const (
+	DefaultMaxAttempts = 4
+	DefaultBackoff     = time.Second
+	DefaultMaxBackoff  = 15 * time.Second
+)
+
+// A Kind of call decides which errors are retried.
+type Kind string
+
+const (
+	// Query calls only read state, so any transient error is retried.
+	Query Kind = "query"
+
+	// Broadcast calls submit transactions, so only errors that prove the
+	// transaction was not accepted are retried, never timeouts after which
+	// it may still be included.
+	Broadcast Kind = "broadcast"
+)
+
+// A Policy controls how often and how long apart failed calls are retried.
+// Zero fields take their defaults.
+type Policy struct {
+	// MaxAttempts bounds the attempts of a call, the first included. One
+	// disables retries.
+	MaxAttempts int
+	// Backoff is waited before the first retry and doubled before every
+	// further one, with jitter, up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// withDefaults returns p with defaults for its zero fields.
+func (p Policy) withDefaults() Policy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultMaxAttempts
+	}
+	if p.Backoff <= 0 {
+		p.Backoff = DefaultBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = DefaultMaxBackoff
+	}
+	return p
+}
+
+type policyKey struct{}
+
+// WithPolicy returns a copy of ctx whose calls are retried according to p.
+func WithPolicy(ctx context.Context, p Policy) context.Context {
+	return context.WithValue(ctx, policyKey{}, p)
+}
+
+// FromContext returns the policy of ctx, the default one if none was set.
+func FromContext(ctx context.Context) Policy {
+	p, _ := ctx.Value(policyKey{}).(Policy)
+	return p.withDefaults()
+}
+
+var retries = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "akash_chain_call_retries_total",
+	Help: "Chain calls retried after a transient error, by kind of call: query or broadcast.",
+}, []string{"kind"})
+
+func init() {
+	metrics.Registry.MustRegister(retries)
+}
+
+// Do calls fn until it succeeds, fails with an error that is not retryable
+// for kind, or the attempts of the policy of ctx are exhausted. It returns
+// the last error of fn, or the error of ctx if it is done while waiting.
+func Do(ctx context.Context, kind Kind, fn func() error) error {
+	if ctx == nil {
+		return fn()
+	}
+	p := FromContext(ctx)
+	backoff := p.Backoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.MaxAttempts || ctx.Err() != nil || !Retryable(err, kind) {
+			return err
+		}
+
+		retries.WithLabelValues(string(kind)).Inc()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(jitter(backoff)):
+		}
+		backoff = min(2*backoff, p.MaxBackoff)
+	}
+}
+
+// jitter returns a random duration between half of d and d, so that calls
+// failing together do not retry together.
+func jitter(d time.Duration) time.Duration {
+	half := int64(d / 2)
+	if half <= 0 {
+		return d
+	}
+	return time.Duration(half + rand.Int63n(half+1)) //nolint:gosec // Jitter needs no cryptographic randomness.
+}
+
+// rateLimited, mempoolFull, unavailable and timedOut match the messages of transient
+// errors, as reported by the node, gRPC and HTTP clients.
+var (
+	rateLimited = []string{"too many requests", "status code 429", "rate limit"}
+	mempoolFull = []string{"mempool is full"}
+	unavailable = []string{
+		"connection refused", "connection reset", "no such host", "broken pipe",
+		"unexpected eof", "service unavailable", "bad gateway",
+		"status code 502", "status code 503", "status code 504",
+		"error unmarshalling",
+	}
+	timedOut = []string{"timeout", "timed out", "deadline exceeded"}
+)
+
+// Retryable reports whether a call of kind failing with err may succeed when
+// retried.
+func Retryable(err error, kind Kind) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if s, ok := status.FromError(err); ok && s.Code() != codes.Unknown {
+		switch s.Code() { //nolint:exhaustive // Every other code is final.
+		case codes.Unavailable, codes.ResourceExhausted:
+			return true
+		case codes.DeadlineExceeded, codes.Aborted:
+			return kind == Query
+		}
+		return false
+	}
+
+	msg := strings.ToLower(err.Error())
+	if contains(msg, rateLimited) || contains(msg, mempoolFull) {
+		return true
+	}
+	if kind == Broadcast {
+		// Nothing reached the node when it could not be connected to.
+		return strings.Contains(msg, "connection refused") || strings.Contains(msg, "no such host")
+	}
+	return contains(msg, unavailable) || contains(msg, timedOut)
+}
+
+func contains(msg string, substrs []string) bool {
+	for _, s := range substrs {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
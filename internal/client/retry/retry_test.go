@@ -0,0 +1,133 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryable(t *testing.T) {
+	cases := map[string]struct {
+		err       error
+		query     bool
+		broadcast bool
+	}{
+		"Nil":               {},
+		"Canceled":          {err: fmt.Errorf("query: %w", context.Canceled)},
+		"NotFound":          {err: errors.New("deployment not found")},
+		"ConnectionRefused": {err: errors.New(`post failed: Post "https://rpc.akashnet.net:443": dial tcp 1.2.3.4:443: connect: connection refused`), query: true, broadcast: true},
+		"Timeout":           {err: errors.New("Post \"https://rpc.akashnet.net:443\": context deadline exceeded (Client.Timeout exceeded while awaiting headers)"), query: true},
+		"TooManyRequests":   {err: errors.New("429 Too Many Requests"), query: true, broadcast: true},
+		"StatusCode":        {err: errors.New("response status code 503"), query: true},
+		"MempoolFull":       {err: errors.New("transaction ABC failed with code 20: mempool is full"), query: true, broadcast: true},
+		"Unmarshalling":     {err: errors.New("error unmarshalling: invalid character '<' looking for beginning of value"), query: true},
+		"OutOfGas":          {err: errors.New("out of gas in location: WriteFlat; gasWanted: 200000, gasUsed: 210000")},
+		"NumberLookingLike": {err: errors.New("deployment 4290 not found")},
+		"GRPCUnavailable":   {err: status.Error(codes.Unavailable, "connection error"), query: true, broadcast: true},
+		"GRPCDeadline":      {err: status.Error(codes.DeadlineExceeded, "deadline exceeded"), query: true},
+		"GRPCNotFound":      {err: status.Error(codes.NotFound, "deployment not found: timeout")},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := Retryable(tc.err, Query); got != tc.query {
+				t.Errorf("Retryable(%v, Query): want %t, got %t", tc.err, tc.query, got)
+			}
+			if got := Retryable(tc.err, Broadcast); got != tc.broadcast {
+				t.Errorf("Retryable(%v, Broadcast): want %t, got %t", tc.err, tc.broadcast, got)
+			}
+		})
+	}
+}
+
+func TestDo(t *testing.T) {
+	transient := errors.New("connection refused")
+	final := errors.New("deployment not found")
+	fast := Policy{MaxAttempts: 3, Backoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	cases := map[string]struct {
+		ctx          context.Context
+		errs         []error
+		wantAttempts int
+		wantErr      error
+	}{
+		"Succeeds": {
+			ctx:          WithPolicy(context.Background(), fast),
+			errs:         []error{nil},
+			wantAttempts: 1,
+		},
+		"RecoversFromTransientErrors": {
+			ctx:          WithPolicy(context.Background(), fast),
+			errs:         []error{transient, transient, nil},
+			wantAttempts: 3,
+		},
+		"GivesUpAfterMaxAttempts": {
+			ctx:          WithPolicy(context.Background(), fast),
+			errs:         []error{transient, transient, transient, nil},
+			wantAttempts: 3,
+			wantErr:      transient,
+		},
+		"StopsAtFinalErrors": {
+			ctx:          WithPolicy(context.Background(), fast),
+			errs:         []error{final, nil},
+			wantAttempts: 1,
+			wantErr:      final,
+		},
+		"Disabled": {
+			ctx:          WithPolicy(context.Background(), Policy{MaxAttempts: 1}),
+			errs:         []error{transient, nil},
+			wantAttempts: 1,
+			wantErr:      transient,
+		},
+		"NoContext": {
+			errs:         []error{transient, nil},
+			wantAttempts: 1,
+			wantErr:      transient,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			attempts := 0
+			err := Do(tc.ctx, Query, func() error {
+				attempts++
+				return tc.errs[attempts-1]
+			})
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Do(...): want error %v, got %v", tc.wantErr, err)
+			}
+			if attempts != tc.wantAttempts {
+				t.Errorf("Do(...): want %d attempts, got %d", tc.wantAttempts, attempts)
+			}
+		})
+	}
+}
+
+func TestDoCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(WithPolicy(context.Background(), Policy{Backoff: time.Hour}))
+	attempts := 0
+	err := Do(ctx, Query, func() error {
+		attempts++
+		cancel()
+		return errors.New("connection refused")
+	})
+	if attempts != 1 {
+		t.Errorf("Do(...): want 1 attempt, got %d", attempts)
+	}
+	if err == nil {
+		t.Errorf("Do(...): want error, got nil")
+	}
+}
+
+func TestJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if d := jitter(time.Second); d < 500*time.Millisecond || d > time.Second {
+			t.Fatalf("jitter(1s): want between 500ms and 1s, got %s", d)
+		}
+	}
+}
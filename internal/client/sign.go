@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/overlock-network/provider-akash/internal/client/keys"
+	"github.com/overlock-network/provider-akash/internal/client/node"
+	"github.com/overlock-network/provider-akash/internal/client/retry"
+	"github.com/overlock-network/provider-akash/internal/client/tx"
+	"github.com/overlock-network/provider-akash/internal/client/types"
+	"github.com/overlock-network/provider-akash/internal/tracing"
+)
+
+const errInvalidCoins = "invalid coins %q"
+
+// coinPattern matches an amount of a denomination, e.g. 5000uakt or
+// 0.025uakt.
+var coinPattern = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)([a-zA-Z][a-zA-Z0-9/:._-]*)$`)
+
+// signingKey returns the private key held by the credentials of the client.
+// It is derived in memory from mnemonic credentials, or else decoded from
+// the hex encoding exported by akash keys export --unarmored-hex --unsafe.
+func (ak *AkashClient) signingKey() (*keys.PrivKey, error) {
+	creds, err := ak.GetCredentials()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get credentials")
+	}
+
+	m, ok, err := parseMnemonicCredentials(creds)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return m.key()
+	}
+
+	key, err := keys.FromHex(string(creds))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot load signing key from credentials")
+	}
+	return key, nil
+}
+
+// signer returns the signing key of the client and its address, which must
+// be the configured account address when one is configured.
+func (ak *AkashClient) signer() (*keys.PrivKey, string, error) {
+	key, err := ak.signingKey()
+	if err != nil {
+		return nil, "", err
+	}
+	address := key.Address()
+	if ak.Config.AccountAddress != "" && ak.Config.AccountAddress != address {
+		return nil, "", errors.Wrapf(ErrAccountMismatch, "configured %s, credentials hold %s", ak.Config.AccountAddress, address)
+	}
+	return key, address, nil
+}
+
+// signAndBroadcast signs msgs with key, whose account is address, and
+// broadcasts them in a single transaction through the node. Its gas is
+// estimated by simulating it first, see simulated, and nothing is broadcast
+// when the simulation fails. It returns the transaction once it was included
+// in a block, see broadcastSigned. Transactions rejected because their
+// account sequence does not match the one expected by the chain, as happens
+// when several controllers sign with the same key, are signed again with the
+// expected sequence and rebroadcast up to maxSequenceRetries times. Nothing
+// is broadcast unless this replica passes the broadcast gate.
+func (ak *AkashClient) signAndBroadcast(ctx context.Context, key *keys.PrivKey, address string, msgs ...tx.Msg) (transaction types.Transaction, err error) {
+	if err := requireBroadcast(); err != nil {
+		return types.Transaction{}, err
+	}
+
+	ctx, span := tracing.Start(ctx, "broadcast", tracing.AttrNode.String(ak.Config.Node))
+	defer func() {
+		span.SetAttributes(tracing.AttrTxHash.String(transaction.TxHash), tracing.AttrHeight.String(transaction.Height))
+		tracing.End(span, err)
+	}()
+	n := node.New(ak.Config.Node)
+
+	t, account, err := ak.simulated(ctx, n, key, address, msgs...)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+
+	transaction, err = ak.broadcastSigned(ctx, n, t.Sign(key, ak.Config.ChainId, account.Number, account.Sequence))
+	for attempt := 0; attempt < maxSequenceRetries && isSequenceMismatch(err); attempt++ {
+		sequence, serr := nodeSequence(ctx, n, address, err)
+		if serr != nil {
+			return transaction, err
+		}
+		ak.log().Info("Account sequence mismatch, rebroadcasting", "sequence", sequence)
+		transaction, err = ak.broadcastSigned(ctx, n, t.Sign(key, ak.Config.ChainId, account.Number, sequence))
+	}
+	return transaction, err
+}
+
+// signingAccount returns the number and sequence of the account of address
+// signing a transaction, and ErrAccountUninitialized if the chain has none.
+func (ak *AkashClient) signingAccount(ctx context.Context, n *node.Client, address string) (node.Account, error) {
+	var account node.Account
+	err := retry.Do(ctx, retry.Query, func() error {
+		var err error
+		account, err = n.Account(ctx, address)
+		return err
+	})
+	if errors.Is(err, node.ErrAccountNotFound) {
+		return node.Account{}, errors.Wrap(ErrAccountUninitialized, address)
+	}
+	return account, err
+}
+
+// feeCoins returns the fees of a transaction of gas: the fixed fees when
+// configured, otherwise the gas at the gas prices, rounded up.
+func (ak *AkashClient) feeCoins(gas uint64) ([]tx.Coin, error) {
+	if fees := ak.txFees(); fees != "" {
+		return parseCoins(fees)
+	}
+
+	prices, err := parseCoins(ak.txGasPrices())
+	if err != nil {
+		return nil, err
+	}
+	for i, p := range prices {
+		price, ok := new(big.Rat).SetString(p.Amount)
+		if !ok {
+			return nil, errors.Errorf(errInvalidCoins, ak.txGasPrices())
+		}
+		amount := price.Mul(price, new(big.Rat).SetInt(new(big.Int).SetUint64(gas)))
+		ceil := new(big.Int).Add(amount.Num(), new(big.Int).Sub(amount.Denom(), big.NewInt(1)))
+		prices[i].Amount = ceil.Quo(ceil, amount.Denom()).String()
+	}
+	return prices, nil
+}
+
+// parseCoins parses comma separated amounts of denominations, e.g.
+// 5000uakt or 0.025uakt.
+func parseCoins(s string) ([]tx.Coin, error) {
+	var coins []tx.Coin
+	for _, c := range strings.Split(s, ",") {
+		m := coinPattern.FindStringSubmatch(strings.TrimSpace(c))
+		if m == nil {
+			return nil, errors.Errorf(errInvalidCoins, s)
+		}
+		coins = append(coins, tx.Coin{Denom: m[2], Amount: m[1]})
+	}
+	return coins, nil
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"math"
+
+	"github.com/overlock-network/provider-akash/internal/client/keys"
+	"github.com/overlock-network/provider-akash/internal/client/node"
+	"github.com/overlock-network/provider-akash/internal/client/tx"
+)
+
+// ErrSimulationFailed is returned when a transaction fails its simulation, in
+// which case it is not broadcast and no fees are spent.
+var ErrSimulationFailed = node.ErrSimulationFailed
+
+// simulated returns the transaction of msgs signed by key, whose account is
+// address, with a gas limit of the gas its simulation used multiplied by the
+// gas adjustment and the fees of that gas, ready to be signed and broadcast,
+// along with the account. It returns ErrSimulationFailed if the simulation
+// fails, e.g. because the account cannot pay the deposit, so no fees are
+// spent on a transaction bound to fail. The configured fee granter pays its
+// fees, unless it is the signer itself: an account cannot pay its own fees
+// out of an allowance, e.g. when it is the treasury granting allowances.
+func (ak *AkashClient) simulated(ctx context.Context, n *node.Client, key *keys.PrivKey, address string, msgs ...tx.Msg) (tx.Tx, node.Account, error) {
+	account, err := ak.signingAccount(ctx, n, address)
+	if err != nil {
+		return tx.Tx{}, node.Account{}, err
+	}
+
+	t := tx.Tx{Msgs: msgs, Memo: ak.transactionNote}
+	if g := ak.Config.FeeGranter; g != address {
+		t.Fee.Granter = g
+	}
+	gasUsed, err := n.Simulate(ctx, t.Unsigned(key.PubKey(), account.Sequence))
+	if err != nil {
+		return tx.Tx{}, node.Account{}, err
+	}
+
+	t.Fee.GasLimit = gasLimit(gasUsed, ak.txGasAdjustment())
+	if t.Fee.Amount, err = ak.feeCoins(t.Fee.GasLimit); err != nil {
+		return tx.Tx{}, node.Account{}, err
+	}
+	return t, account, nil
+}
+
+// gasLimit returns the estimated gas multiplied by adjustment, rounded up.
+func gasLimit(estimate uint64, adjustment float32) uint64 {
+	return uint64(math.Ceil(float64(estimate) * float64(adjustment)))
+}
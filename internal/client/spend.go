@@ -0,0 +1,32 @@
+package client
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrMainnetSpendNotConfirmed is returned instead of broadcasting a
+// transaction that deposits funds on mainnet when the ProviderConfig does not
+// confirm spending on mainnet.
+var ErrMainnetSpendNotConfirmed = errors.New("spending on mainnet is not confirmed, set confirmMainnetSpend in the ProviderConfig")
+
+// IsMainnet reports whether chainId is the chain ID of the Akash mainnet.
+func IsMainnet(chainId string) bool {
+	return strings.HasPrefix(chainId, MainnetChainIdPrefix)
+}
+
+// checkMainnetSpend returns ErrMainnetSpendNotConfirmed when chainId is a
+// mainnet chain ID and spending on it is not confirmed.
+func checkMainnetSpend(chainId string, confirmed bool) error {
+	if confirmed || !IsMainnet(chainId) {
+		return nil
+	}
+	return errors.Wrap(ErrMainnetSpendNotConfirmed, chainId)
+}
+
+// requireSpendConfirmed returns an error unless transactions depositing funds
+// may be broadcast on the configured chain.
+func (ak *AkashClient) requireSpendConfirmed() error {
+	return checkMainnetSpend(ak.Config.ChainId, ak.Config.ConfirmMainnetSpend)
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/overlock-network/provider-akash/internal/client/types"
+	"github.com/overlock-network/provider-akash/internal/statestore"
+)
+
+// Key prefixes of the state shared by all clients.
+const (
+	stateKeySequence  = "sequence."
+	stateKeyBlacklist = "blacklist."
+	stateKeyPrice     = "price."
+)
+
+var (
+	stateMu sync.RWMutex
+	state   statestore.Store = statestore.NewMemory()
+)
+
+// SetStateStore sets the store used by all clients to persist operational
+// state. The default store keeps it in memory.
+func SetStateStore(s statestore.Store) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	state = s
+}
+
+// StateStore returns the store used by all clients to persist operational
+// state.
+func StateStore() statestore.Store {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	return state
+}
+
+// PriceObservation is the last price a provider was seen bidding.
+type PriceObservation struct {
+	Price    types.BidPrice `json:"price"`
+	Observed time.Time      `json:"observed"`
+}
+
+// SequenceHint returns the last known sequence of the signing account.
+func (ak *AkashClient) SequenceHint() (uint64, bool, error) {
+	owner, err := ak.AccountAddress()
+	if err != nil {
+		return 0, false, err
+	}
+	v, ok, err := StateStore().Get(ak.ctx, stateKeySequence+owner)
+	if err != nil || !ok {
+		return 0, false, err
+	}
+	seq, err := strconv.ParseUint(v, 10, 64)
+	return seq, err == nil, err
+}
+
+// SetSequenceHint records the last known sequence of the signing account.
+func (ak *AkashClient) SetSequenceHint(seq uint64) error {
+	owner, err := ak.AccountAddress()
+	if err != nil {
+		return err
+	}
+	return StateStore().Set(ak.ctx, stateKeySequence+owner, strconv.FormatUint(seq, 10))
+}
+
+// BlacklistProvider records that the provider should not be leased from
+// anymore, and why.
+func (ak *AkashClient) BlacklistProvider(provider string, reason string) error {
+	return StateStore().Set(ak.ctx, stateKeyBlacklist+provider, reason)
+}
+
+// BlacklistedProviders returns the blacklisted providers with the reason they
+// were blacklisted.
+func (ak *AkashClient) BlacklistedProviders() (map[string]string, error) {
+	entries, err := StateStore().List(ak.ctx, stateKeyBlacklist)
+	if err != nil {
+		return nil, err
+	}
+	blacklist := make(map[string]string, len(entries))
+	for k, v := range entries {
+		blacklist[k[len(stateKeyBlacklist):]] = v
+	}
+	return blacklist, nil
+}
+
+// RecordBidPrices records the price of each bid as the last one seen from
+// its provider.
+func (ak *AkashClient) RecordBidPrices(bids types.Bids, at time.Time) error {
+	for _, bid := range bids {
+		b, err := json.Marshal(PriceObservation{Price: bid.Price, Observed: at})
+		if err != nil {
+			return err
+		}
+		if err := StateStore().Set(ak.ctx, stateKeyPrice+bid.Id.Provider, string(b)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ObservedPrices returns the last price seen from each provider.
+func (ak *AkashClient) ObservedPrices() (map[string]PriceObservation, error) {
+	entries, err := StateStore().List(ak.ctx, stateKeyPrice)
+	if err != nil {
+		return nil, err
+	}
+	prices := make(map[string]PriceObservation, len(entries))
+	for k, v := range entries {
+		o := PriceObservation{}
+		if err := json.Unmarshal([]byte(v), &o); err != nil {
+			return nil, err
+		}
+		prices[k[len(stateKeyPrice):]] = o
+	}
+	return prices, nil
+}
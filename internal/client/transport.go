@@ -0,0 +1,24 @@
+package client
+
+import (
+	"github.com/pkg/errors"
+)
+
+// errNoGRPCEndpoint is returned when the chain is to be queried without a
+// gRPC endpoint, e.g. because a node is configured without one.
+const errNoGRPCEndpoint = "no gRPC endpoint is configured nor discovered to query the chain"
+
+// checkTransport returns an error unless the chain can be queried through the
+// transport and gRPC endpoint. The cli transport is a deprecated alias of the
+// grpc one.
+func checkTransport(transport string, grpcEndpoint string) error {
+	switch transport {
+	case TransportGRPC, TransportCLI, "":
+	default:
+		return errors.Errorf("unknown transport %q", transport)
+	}
+	if grpcEndpoint == "" {
+		return errors.New(errNoGRPCEndpoint)
+	}
+	return nil
+}
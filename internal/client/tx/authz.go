@@ -0,0 +1,60 @@
+package tx
+
+import "time"
+
+// MsgGrant authorizes Grantee to deposit up to SpendLimit into deployments
+// from the account of Granter until Expiration, replacing any previous
+// deposit authorization.
+type MsgGrant struct {
+	Granter    string
+	Grantee    string
+	SpendLimit Coin
+	// Expiration is when the authorization expires. Never when zero.
+	Expiration time.Time
+}
+
+// TypeURL implements Msg.
+func (MsgGrant) TypeURL() string {
+	return "/cosmos.authz.v1beta1.MsgGrant"
+}
+
+// Marshal implements Msg.
+func (m MsgGrant) Marshal() []byte {
+	authorization := encoder{}.message(1, coin(m.SpendLimit))
+	grant := encoder{}.message(1, packAny("/akash.deployment.v1beta3.DepositDeploymentAuthorization", authorization))
+	if !m.Expiration.IsZero() {
+		grant = grant.message(2, timestamp(m.Expiration))
+	}
+	return encoder{}.
+		string(1, m.Granter).
+		string(2, m.Grantee).
+		message(3, grant)
+}
+
+// MsgRevoke revokes the authorization of Grantee to send messages of type
+// MsgTypeURL on behalf of Granter.
+type MsgRevoke struct {
+	Granter    string
+	Grantee    string
+	MsgTypeURL string
+}
+
+// TypeURL implements Msg.
+func (MsgRevoke) TypeURL() string {
+	return "/cosmos.authz.v1beta1.MsgRevoke"
+}
+
+// Marshal implements Msg.
+func (m MsgRevoke) Marshal() []byte {
+	return encoder{}.
+		string(1, m.Granter).
+		string(2, m.Grantee).
+		string(3, m.MsgTypeURL)
+}
+
+// timestamp encodes t as a google.protobuf.Timestamp.
+func timestamp(t time.Time) []byte {
+	return encoder{}.
+		uint(1, uint64(t.Unix())).
+		uint(2, uint64(t.Nanosecond()))
+}
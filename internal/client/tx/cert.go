@@ -0,0 +1,22 @@
+package tx
+
+// MsgCreateCertificate publishes the certificate of Owner on chain, for
+// providers to authenticate it with. Cert and PubKey are PEM encoded.
+type MsgCreateCertificate struct {
+	Owner  string
+	Cert   []byte
+	PubKey []byte
+}
+
+// TypeURL implements Msg.
+func (MsgCreateCertificate) TypeURL() string {
+	return "/akash.cert.v1beta3.MsgCreateCertificate"
+}
+
+// Marshal implements Msg.
+func (m MsgCreateCertificate) Marshal() []byte {
+	return encoder{}.
+		string(1, m.Owner).
+		bytes(2, m.Cert).
+		bytes(3, m.PubKey)
+}
@@ -0,0 +1,189 @@
+package tx
+
+import (
+	"math/big"
+	"strconv"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/overlock-network/provider-akash/internal/sdl"
+)
+
+// TypeURLMsgDepositDeployment is the type URL of MsgDepositDeployment, which
+// deposit authorizations allow grantees to send.
+const TypeURLMsgDepositDeployment = "/akash.deployment.v1beta3.MsgDepositDeployment"
+
+// decPrecision is the number of decimals of the Dec amounts of the chain,
+// which are encoded as integers scaled by 10^decPrecision.
+const decPrecision = 18
+
+// endpointKinds numbers the endpoint kinds of resource units as the chain
+// does.
+var endpointKinds = map[string]uint64{
+	sdl.EndpointSharedHTTP: 0,
+	sdl.EndpointRandomPort: 1,
+	sdl.EndpointLeasedIP:   2,
+}
+
+// MsgCreateDeployment creates the deployment Dseq of Owner, placing its
+// groups on chain and funding its escrow with Deposit taken from Depositor.
+type MsgCreateDeployment struct {
+	Owner   string
+	Dseq    uint64
+	Groups  []sdl.GroupSpec
+	Version []byte
+	Deposit Coin
+	// Depositor is the account paying the deposit, the owner itself or an
+	// account that authorized it to.
+	Depositor string
+}
+
+// TypeURL implements Msg.
+func (MsgCreateDeployment) TypeURL() string {
+	return "/akash.deployment.v1beta3.MsgCreateDeployment"
+}
+
+// Marshal implements Msg.
+func (m MsgCreateDeployment) Marshal() []byte {
+	e := encoder{}.message(1, deploymentID(m.Owner, m.Dseq))
+	for _, g := range m.Groups {
+		e = e.message(2, groupSpec(g))
+	}
+	return e.bytes(3, m.Version).
+		message(4, coin(m.Deposit)).
+		string(5, m.Depositor)
+}
+
+// MsgCloseDeployment closes the deployment Dseq of Owner, refunding what is
+// left of its escrow.
+type MsgCloseDeployment struct {
+	Owner string
+	Dseq  uint64
+}
+
+// TypeURL implements Msg.
+func (MsgCloseDeployment) TypeURL() string {
+	return "/akash.deployment.v1beta3.MsgCloseDeployment"
+}
+
+// Marshal implements Msg.
+func (m MsgCloseDeployment) Marshal() []byte {
+	return encoder{}.message(1, deploymentID(m.Owner, m.Dseq))
+}
+
+// MsgDepositDeployment deposits Amount taken from Depositor into the escrow of
+// the deployment Dseq of Owner.
+type MsgDepositDeployment struct {
+	Owner     string
+	Dseq      uint64
+	Amount    Coin
+	Depositor string
+}
+
+// TypeURL implements Msg.
+func (MsgDepositDeployment) TypeURL() string {
+	return TypeURLMsgDepositDeployment
+}
+
+// Marshal implements Msg.
+func (m MsgDepositDeployment) Marshal() []byte {
+	return encoder{}.
+		message(1, deploymentID(m.Owner, m.Dseq)).
+		message(2, coin(m.Amount)).
+		string(3, m.Depositor)
+}
+
+// MsgUpdateDeployment records Version as the version of the manifest of the
+// deployment Dseq of Owner.
+type MsgUpdateDeployment struct {
+	Owner   string
+	Dseq    uint64
+	Version []byte
+}
+
+// TypeURL implements Msg.
+func (MsgUpdateDeployment) TypeURL() string {
+	return "/akash.deployment.v1beta3.MsgUpdateDeployment"
+}
+
+// Marshal implements Msg.
+func (m MsgUpdateDeployment) Marshal() []byte {
+	// Field 2 held the groups of older versions of the message.
+	return encoder{}.
+		message(1, deploymentID(m.Owner, m.Dseq)).
+		bytes(3, m.Version)
+}
+
+func deploymentID(owner string, dseq uint64) []byte {
+	return encoder{}.string(1, owner).uint(2, dseq)
+}
+
+func coin(c Coin) []byte {
+	return encoder{}.string(1, c.Denom).string(2, c.Amount)
+}
+
+func groupSpec(g sdl.GroupSpec) []byte {
+	signedBy := encoder{}
+	for _, s := range g.Requirements.SignedBy.AllOf {
+		signedBy = signedBy.message(1, []byte(s))
+	}
+	for _, s := range g.Requirements.SignedBy.AnyOf {
+		signedBy = signedBy.message(2, []byte(s))
+	}
+	requirements := attributes(encoder{}.message(1, signedBy), 2, g.Requirements.Attributes)
+
+	e := encoder{}.string(1, g.Name).message(2, requirements)
+	for _, r := range g.Resources {
+		price := encoder{}.string(1, r.Price.Denom).string(2, decAmount(r.Price.Amount))
+		unit := encoder{}.
+			message(1, resources(r.Resources)).
+			uint(2, uint64(r.Count)).
+			message(3, price)
+		e = e.message(3, unit)
+	}
+	return e
+}
+
+func resources(r sdl.Resources) []byte {
+	e := encoder{}.
+		uint(1, uint64(r.ID)).
+		message(2, encoder{}.message(1, resourceValue(r.CPU))).
+		message(3, encoder{}.message(1, resourceValue(r.Memory)))
+	for _, s := range r.Storage {
+		storage := encoder{}.string(1, s.Name).message(2, resourceValue(s.Size))
+		e = e.message(4, attributes(storage, 3, s.Attributes))
+	}
+	e = e.message(5, encoder{}.message(1, resourceValue(r.GPU)))
+	for _, ep := range r.Endpoints {
+		e = e.message(6, encoder{}.uint(1, endpointKinds[ep.Kind]).uint(2, uint64(ep.SequenceNumber)))
+	}
+	return e
+}
+
+// resourceValue encodes a quantity of a resource, whose value is the decimal
+// representation of an integer.
+func resourceValue(v uint64) []byte {
+	return encoder{}.string(1, strconv.FormatUint(v, 10))
+}
+
+// attributes appends the attributes to e as the repeated field num.
+func attributes(e encoder, num protowire.Number, attrs []sdl.Attribute) encoder {
+	for _, a := range attrs {
+		e = e.message(num, encoder{}.string(1, a.Key).string(2, a.Value))
+	}
+	return e
+}
+
+// decAmount returns the decimal amount as the chain encodes Dec amounts,
+// e.g. 0.5 as 500000000000000000. Digits beyond its precision are
+// truncated. ParseSDL only accepts valid amounts, anything else is encoded
+// as zero.
+func decAmount(amount string) string {
+	r, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return "0"
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(decPrecision), nil)
+	r.Mul(r, new(big.Rat).SetInt(scale))
+	return new(big.Int).Quo(r.Num(), r.Denom()).String()
+}
@@ -0,0 +1,65 @@
+package tx
+
+import "time"
+
+// MsgGrantAllowance grants Grantee an allowance to pay the fees of its
+// transactions from the account of Granter.
+type MsgGrantAllowance struct {
+	Granter string
+	Grantee string
+	// SpendLimit are the coins the grantee may spend. Unlimited when empty.
+	SpendLimit []Coin
+	// Expiration is when the allowance expires. Never when zero.
+	Expiration time.Time
+	// AllowedMessages restricts the allowance to the given message types,
+	// when any.
+	AllowedMessages []string
+}
+
+// TypeURL implements Msg.
+func (MsgGrantAllowance) TypeURL() string {
+	return "/cosmos.feegrant.v1beta1.MsgGrantAllowance"
+}
+
+// Marshal implements Msg.
+func (m MsgGrantAllowance) Marshal() []byte {
+	basic := encoder{}
+	for _, c := range m.SpendLimit {
+		basic = basic.message(1, coin(c))
+	}
+	if !m.Expiration.IsZero() {
+		basic = basic.message(2, timestamp(m.Expiration))
+	}
+	allowance := packAny("/cosmos.feegrant.v1beta1.BasicAllowance", basic)
+
+	if len(m.AllowedMessages) > 0 {
+		allowed := encoder{}.message(1, allowance)
+		for _, msg := range m.AllowedMessages {
+			allowed = allowed.message(2, []byte(msg))
+		}
+		allowance = packAny("/cosmos.feegrant.v1beta1.AllowedMsgAllowance", allowed)
+	}
+
+	return encoder{}.
+		string(1, m.Granter).
+		string(2, m.Grantee).
+		message(3, allowance)
+}
+
+// MsgRevokeAllowance revokes the allowance Granter granted Grantee.
+type MsgRevokeAllowance struct {
+	Granter string
+	Grantee string
+}
+
+// TypeURL implements Msg.
+func (MsgRevokeAllowance) TypeURL() string {
+	return "/cosmos.feegrant.v1beta1.MsgRevokeAllowance"
+}
+
+// Marshal implements Msg.
+func (m MsgRevokeAllowance) Marshal() []byte {
+	return encoder{}.
+		string(1, m.Granter).
+		string(2, m.Grantee)
+}
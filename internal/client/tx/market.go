@@ -0,0 +1,27 @@
+package tx
+
+// MsgCreateLease accepts the bid of Provider on the order of the deployment
+// Dseq of Owner identified by Gseq and Oseq.
+type MsgCreateLease struct {
+	Owner    string
+	Dseq     uint64
+	Gseq     uint32
+	Oseq     uint32
+	Provider string
+}
+
+// TypeURL implements Msg.
+func (MsgCreateLease) TypeURL() string {
+	return "/akash.market.v1beta4.MsgCreateLease"
+}
+
+// Marshal implements Msg.
+func (m MsgCreateLease) Marshal() []byte {
+	bid := encoder{}.
+		string(1, m.Owner).
+		uint(2, m.Dseq).
+		uint(3, uint64(m.Gseq)).
+		uint(4, uint64(m.Oseq)).
+		string(5, m.Provider)
+	return encoder{}.message(1, bid)
+}
@@ -0,0 +1,137 @@
+// Package tx encodes Cosmos SDK transactions and signs them in
+// SIGN_MODE_DIRECT, so that they are broadcast without the Akash CLI.
+package tx
+
+import (
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	typeURLPubKey  = "/cosmos.crypto.secp256k1.PubKey"
+	signModeDirect = 1
+)
+
+// A Msg is a message of a transaction.
+type Msg interface {
+	// TypeURL returns the type URL the message is packed into an Any with,
+	// e.g. /akash.market.v1beta4.MsgCreateLease.
+	TypeURL() string
+	// Marshal returns the protobuf encoding of the message.
+	Marshal() []byte
+}
+
+// A Coin is an amount of a denomination, e.g. 5000uakt.
+type Coin struct {
+	Denom  string
+	Amount string
+}
+
+// A Fee is paid for a transaction.
+type Fee struct {
+	Amount   []Coin
+	GasLimit uint64
+	// Granter pays the fee out of an allowance granted to the signer, if
+	// set.
+	Granter string
+}
+
+// A Signer signs transactions with the key of an account.
+type Signer interface {
+	// PubKey returns the compressed secp256k1 public key of the account.
+	PubKey() []byte
+	// Sign returns the signature of msg.
+	Sign(msg []byte) []byte
+}
+
+// A Tx is a transaction of a single signer.
+type Tx struct {
+	Msgs []Msg
+	Memo string
+	Fee  Fee
+}
+
+// Sign returns the transaction signed by key, the account number and
+// sequence of the signer, encoded as a TxRaw ready to be broadcast.
+func (t Tx) Sign(key Signer, chainID string, accountNumber uint64, sequence uint64) []byte {
+	body := t.body()
+	authInfo := t.authInfo(key.PubKey(), sequence)
+
+	doc := encoder{}.
+		bytes(1, body).
+		bytes(2, authInfo).
+		string(3, chainID).
+		uint(4, accountNumber)
+	return raw(body, authInfo, key.Sign(doc))
+}
+
+// Unsigned returns the transaction with an empty signature, as simulations
+// expect it.
+func (t Tx) Unsigned(pubKey []byte, sequence uint64) []byte {
+	return raw(t.body(), t.authInfo(pubKey, sequence), nil)
+}
+
+func (t Tx) body() []byte {
+	e := encoder{}
+	for _, m := range t.Msgs {
+		e = e.message(1, packAny(m.TypeURL(), m.Marshal()))
+	}
+	return e.string(2, t.Memo)
+}
+
+func (t Tx) authInfo(pubKey []byte, sequence uint64) []byte {
+	signer := encoder{}.
+		message(1, packAny(typeURLPubKey, encoder{}.bytes(1, pubKey))).
+		message(2, encoder{}.message(1, encoder{}.uint(1, signModeDirect))).
+		uint(3, sequence)
+
+	fee := encoder{}
+	for _, c := range t.Fee.Amount {
+		fee = fee.message(1, encoder{}.string(1, c.Denom).string(2, c.Amount))
+	}
+	fee = fee.uint(2, t.Fee.GasLimit).string(4, t.Fee.Granter)
+
+	return encoder{}.message(1, signer).message(2, fee)
+}
+
+// raw returns the TxRaw of body and authInfo with the given signature.
+func raw(body []byte, authInfo []byte, signature []byte) []byte {
+	return encoder{}.
+		bytes(1, body).
+		bytes(2, authInfo).
+		message(3, signature)
+}
+
+func packAny(typeURL string, value []byte) []byte {
+	return encoder{}.string(1, typeURL).bytes(2, value)
+}
+
+// An encoder appends the fields of a protobuf message, omitting the scalar
+// fields holding their zero value as proto3 does.
+type encoder []byte
+
+func (e encoder) string(num protowire.Number, s string) encoder {
+	if s == "" {
+		return e
+	}
+	return protowire.AppendString(protowire.AppendTag(e, num, protowire.BytesType), s)
+}
+
+func (e encoder) bytes(num protowire.Number, b []byte) encoder {
+	if len(b) == 0 {
+		return e
+	}
+	return e.message(num, b)
+}
+
+func (e encoder) uint(num protowire.Number, v uint64) encoder {
+	if v == 0 {
+		return e
+	}
+	return protowire.AppendVarint(protowire.AppendTag(e, num, protowire.VarintType), v)
+}
+
+// message appends the embedded message or element of a repeated field b,
+// even when empty.
+func (e encoder) message(num protowire.Number, b []byte) encoder {
+	return protowire.AppendBytes(protowire.AppendTag(e, num, protowire.BytesType), b)
+}
@@ -0,0 +1,211 @@
+package tx
+
+import (
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/overlock-network/provider-akash/internal/sdl"
+)
+
+type testSigner struct {
+	key *secp256k1.PrivateKey
+}
+
+func (s testSigner) PubKey() []byte {
+	return s.key.PubKey().SerializeCompressed()
+}
+
+func (s testSigner) Sign(msg []byte) []byte {
+	sum := sha256.Sum256(msg)
+	return ecdsa.SignCompact(s.key, sum[:], true)[1:]
+}
+
+// fields decodes the fields of a protobuf message, keyed by number. Varints
+// are returned as uint64 and every other field as bytes.
+func fields(t *testing.T, b []byte) map[protowire.Number][]any {
+	t.Helper()
+	out := map[protowire.Number][]any{}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			t.Fatalf("invalid tag: %v", protowire.ParseError(n))
+		}
+		b = b[n:]
+		switch typ {
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				t.Fatalf("invalid varint: %v", protowire.ParseError(n))
+			}
+			out[num] = append(out[num], v)
+			b = b[n:]
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				t.Fatalf("invalid bytes: %v", protowire.ParseError(n))
+			}
+			out[num] = append(out[num], v)
+			b = b[n:]
+		default:
+			t.Fatalf("unexpected wire type %d", typ)
+		}
+	}
+	return out
+}
+
+func field(t *testing.T, b []byte, path ...protowire.Number) any {
+	t.Helper()
+	var v any = b
+	for _, num := range path {
+		values := fields(t, v.([]byte))[num]
+		if len(values) != 1 {
+			t.Fatalf("field %v: want one value, got %d", path, len(values))
+		}
+		v = values[0]
+	}
+	return v
+}
+
+func TestSign(t *testing.T) {
+	key, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := testSigner{key: key}
+	tx := Tx{
+		Msgs: []Msg{MsgCreateLease{Owner: "akash1owner", Dseq: 42, Gseq: 1, Oseq: 1, Provider: "akash1provider"}},
+		Memo: "crossplane",
+		Fee:  Fee{Amount: []Coin{{Denom: "uakt", Amount: "5000"}}, GasLimit: 200000},
+	}
+
+	raw := tx.Sign(signer, "akashnet-2", 7, 3)
+
+	body := field(t, raw, 1).([]byte)
+	authInfo := field(t, raw, 2).([]byte)
+	if got := string(field(t, body, 1, 1).([]byte)); got != "/akash.market.v1beta4.MsgCreateLease" {
+		t.Errorf("message type URL: got %s", got)
+	}
+	bid := field(t, body, 1, 2, 1).([]byte)
+	want := map[protowire.Number][]any{
+		1: {[]byte("akash1owner")},
+		2: {uint64(42)},
+		3: {uint64(1)},
+		4: {uint64(1)},
+		5: {[]byte("akash1provider")},
+	}
+	if diff := cmp.Diff(want, fields(t, bid)); diff != "" {
+		t.Errorf("bid id: -want, +got:\n%s", diff)
+	}
+	if got := string(field(t, body, 2).([]byte)); got != "crossplane" {
+		t.Errorf("memo: got %s", got)
+	}
+	if got := field(t, authInfo, 1, 3).(uint64); got != 3 {
+		t.Errorf("sequence: want 3, got %d", got)
+	}
+	if got := field(t, authInfo, 1, 2, 1, 1).(uint64); got != signModeDirect {
+		t.Errorf("sign mode: want %d, got %d", signModeDirect, got)
+	}
+	if got := field(t, authInfo, 2, 2).(uint64); got != 200000 {
+		t.Errorf("gas limit: want 200000, got %d", got)
+	}
+
+	doc := encoder{}.bytes(1, body).bytes(2, authInfo).string(3, "akashnet-2").uint(4, 7)
+	sig := field(t, raw, 3).([]byte)
+	var r, s secp256k1.ModNScalar
+	r.SetByteSlice(sig[:32])
+	s.SetByteSlice(sig[32:])
+	sum := sha256.Sum256(doc)
+	if !ecdsa.NewSignature(&r, &s).Verify(sum[:], key.PubKey()) {
+		t.Errorf("signature does not verify the sign doc")
+	}
+}
+
+func TestUnsigned(t *testing.T) {
+	key, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := Tx{Msgs: []Msg{MsgCreateLease{Owner: "akash1owner", Dseq: 42, Gseq: 1, Oseq: 1, Provider: "akash1provider"}}}
+
+	raw := tx.Unsigned(key.PubKey().SerializeCompressed(), 3)
+
+	// Simulations expect one empty signature per signer.
+	if diff := cmp.Diff([]any{[]byte{}}, fields(t, raw)[3]); diff != "" {
+		t.Errorf("signatures: -want, +got:\n%s", diff)
+	}
+}
+
+func TestMsgCreateDeployment(t *testing.T) {
+	msg := MsgCreateDeployment{
+		Owner: "akash1owner",
+		Dseq:  42,
+		Groups: []sdl.GroupSpec{{
+			Name: "dcloud",
+			Resources: []sdl.ResourceUnit{{
+				Resources: sdl.Resources{ID: 1, CPU: 500, Memory: 512, Endpoints: []sdl.Endpoint{{Kind: sdl.EndpointRandomPort}}},
+				Count:     2,
+				Price:     sdl.DecCoin{Denom: "uakt", Amount: "0.5"},
+			}},
+		}},
+		Version:   []byte{0xca, 0xfe},
+		Deposit:   Coin{Denom: "uakt", Amount: "5000000"},
+		Depositor: "akash1depositor",
+	}
+
+	b := msg.Marshal()
+	unit := field(t, b, 2, 3).([]byte)
+	if got := string(field(t, unit, 3, 2).([]byte)); got != "500000000000000000" {
+		t.Errorf("price: want 500000000000000000, got %s", got)
+	}
+	if got := string(field(t, unit, 1, 2, 1, 1).([]byte)); got != "500" {
+		t.Errorf("cpu units: want 500, got %s", got)
+	}
+	if got := field(t, unit, 1, 6, 1).(uint64); got != 1 {
+		t.Errorf("endpoint kind: want 1, got %d", got)
+	}
+	if got := field(t, unit, 2).(uint64); got != 2 {
+		t.Errorf("count: want 2, got %d", got)
+	}
+	if got := string(field(t, b, 5).([]byte)); got != "akash1depositor" {
+		t.Errorf("depositor: got %s", got)
+	}
+}
+
+func TestMsgGrantAllowance(t *testing.T) {
+	expiration := time.Unix(1700000000, 0)
+	cases := map[string]struct {
+		msg         MsgGrantAllowance
+		wantTypeURL string
+	}{
+		"Basic": {
+			msg:         MsgGrantAllowance{Granter: "akash1treasury", Grantee: "akash1tenant", SpendLimit: []Coin{{Denom: "uakt", Amount: "5000000"}}, Expiration: expiration},
+			wantTypeURL: "/cosmos.feegrant.v1beta1.BasicAllowance",
+		},
+		"AllowedMessages": {
+			msg:         MsgGrantAllowance{Granter: "akash1treasury", Grantee: "akash1tenant", Expiration: expiration, AllowedMessages: []string{"/akash.market.v1beta4.MsgCreateLease"}},
+			wantTypeURL: "/cosmos.feegrant.v1beta1.AllowedMsgAllowance",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			allowance := field(t, tc.msg.Marshal(), 3).([]byte)
+			if got := string(field(t, allowance, 1).([]byte)); got != tc.wantTypeURL {
+				t.Errorf("allowance type URL: want %s, got %s", tc.wantTypeURL, got)
+			}
+			basic := field(t, allowance, 2).([]byte)
+			if len(tc.msg.AllowedMessages) > 0 {
+				basic = field(t, basic, 1, 2).([]byte)
+			}
+			if got := field(t, basic, 2, 1).(uint64); got != uint64(expiration.Unix()) {
+				t.Errorf("expiration: want %d, got %d", expiration.Unix(), got)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package types
+
+type Coin struct {
+	Denom  string `json:"denom"`
+	Amount string `json:"amount"`
+}
+
+type Balances struct {
+	Balances []Coin `json:"balances"`
+}
+
+// AmountOf returns the amount of the given denom, or an empty string.
+func (b Balances) AmountOf(denom string) string {
+	for _, c := range b.Balances {
+		if c.Denom == denom {
+			return c.Amount
+		}
+	}
+
+	return ""
+}
+
+// Delegation is stake delegated by an account to a validator.
+type Delegation struct {
+	DelegatorAddress string `json:"delegator_address"`
+	ValidatorAddress string `json:"validator_address"`
+	Shares           string `json:"shares"`
+}
+
+type DelegationResponse struct {
+	Delegation Delegation `json:"delegation"`
+	Balance    Coin       `json:"balance"`
+}
+
+type Certificate struct {
+	State  string `json:"state"`
+	Cert   string `json:"cert"`
+	Pubkey string `json:"pubkey"`
+}
+
+type CertificateResponse struct {
+	Certificate Certificate `json:"certificate"`
+	Serial      string      `json:"serial"`
+}
+
+type Certificates struct {
+	Certificates []CertificateResponse `json:"certificates"`
+}
+
+type NodeStatus struct {
+	Result struct {
+		NodeInfo struct {
+			Network string `json:"network"`
+			Version string `json:"version"`
+		} `json:"node_info"`
+		SyncInfo struct {
+			LatestBlockHeight string `json:"latest_block_height"`
+			CatchingUp        bool   `json:"catching_up"`
+		} `json:"sync_info"`
+	} `json:"result"`
+}
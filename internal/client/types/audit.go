@@ -0,0 +1,8 @@
+package types
+
+// AuditedAttributes are attributes of a provider signed by an auditor.
+type AuditedAttributes struct {
+	Owner      string      `json:"owner"`
+	Auditor    string      `json:"auditor"`
+	Attributes []Attribute `json:"attributes"`
+}
@@ -0,0 +1,23 @@
+package types
+
+import "strings"
+
+// AuthorizationTypeDepositDeployment is the suffix of the type of deposit
+// authorizations, whose package is versioned.
+const AuthorizationTypeDepositDeployment = ".DepositDeploymentAuthorization"
+
+// Authorization is an authorization granted with authz.
+type Authorization struct {
+	Type       string `json:"@type"`
+	SpendLimit Coin   `json:"spend_limit"`
+}
+
+// IsDepositDeployment reports whether a authorizes deposits into deployments.
+func (a Authorization) IsDepositDeployment() bool {
+	return strings.HasSuffix(a.Type, AuthorizationTypeDepositDeployment)
+}
+
+type AuthzGrant struct {
+	Authorization Authorization `json:"authorization"`
+	Expiration    string        `json:"expiration"`
+}
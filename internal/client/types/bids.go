@@ -1,8 +1,6 @@
 package types
 
-type BidsSliceWrapper struct {
-	BidWrappers []BidWrapper `json:"bids"`
-}
+import "sort"
 
 type BidWrapper struct {
 	Bid Bid `json:"bid"`
@@ -12,6 +10,7 @@ type Bids []Bid
 
 type Bid struct {
 	Id    BidId    `json:"bid_id"`
+	State string   `json:"state"`
 	Price BidPrice `json:"price"`
 }
 
@@ -20,6 +19,7 @@ type BidId struct {
 }
 
 type BidPrice struct {
+	Denom  string  `json:"denom"`
 	Amount float32 `json:"amount,string"`
 }
 
@@ -56,3 +56,25 @@ func (b Bids) FindAllByProviders(providers []string) Bids {
 
 	return bids
 }
+
+// SortByPrice returns a copy of the bids ordered from the cheapest to the most expensive.
+func (b Bids) SortByPrice() Bids {
+	sorted := make(Bids, len(b))
+	copy(sorted, b)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Price.Amount < sorted[j].Price.Amount
+	})
+
+	return sorted
+}
+
+// PreferProvider returns a copy of the bids ordered from the cheapest to the
+// most expensive, except that the bid of the given provider comes first.
+func (b Bids) PreferProvider(provider string) Bids {
+	sorted := b.SortByPrice()
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Id.Provider == provider && sorted[j].Id.Provider != provider
+	})
+
+	return sorted
+}
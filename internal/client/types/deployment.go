@@ -1,5 +1,11 @@
 package types
 
+// Deployment states as reported by the chain.
+const (
+	DeploymentStateActive = "active"
+	DeploymentStateClosed = "closed"
+)
+
 type DeploymentId struct {
 	Dseq  string `json:"dseq"`
 	Owner string `json:"owner"`
@@ -8,6 +14,9 @@ type DeploymentId struct {
 type DeploymentInfo struct {
 	State        string       `json:"state"`
 	DeploymentId DeploymentId `json:"deployment_id"`
+	// Version is the base64 encoded version of the manifest of the
+	// deployment.
+	Version string `json:"version"`
 }
 
 type EscrowAccountBalance struct {
@@ -16,16 +25,53 @@ type EscrowAccountBalance struct {
 }
 
 type EscrowAccount struct {
-	Owner   string               `json:"owner"`
-	State   string               `json:"state"`
-	Balance EscrowAccountBalance `json:"balance"`
+	Owner string `json:"owner"`
+	// Depositor is the account that funded the deposit, if other than the
+	// owner.
+	Depositor string               `json:"depositor"`
+	State     string               `json:"state"`
+	Balance   EscrowAccountBalance `json:"balance"`
+	SettledAt string               `json:"settled_at"`
+}
+
+// SignedByRequirement lists the auditors that must have signed the
+// attributes of a provider.
+type SignedByRequirement struct {
+	AllOf []string `json:"all_of"`
+	AnyOf []string `json:"any_of"`
+}
+
+// PlacementRequirements are the requirements of a group on its provider.
+type PlacementRequirements struct {
+	SignedBy   SignedByRequirement `json:"signed_by"`
+	Attributes []Attribute         `json:"attributes"`
+}
+
+type GroupSpec struct {
+	Name         string                `json:"name"`
+	Requirements PlacementRequirements `json:"requirements"`
+}
+
+type Group struct {
+	GroupSpec GroupSpec `json:"group_spec"`
+	State     string    `json:"state"`
 }
 
 type Deployment struct {
 	DeploymentInfo DeploymentInfo `json:"deployment"`
+	Groups         []Group        `json:"groups"`
 	EscrowAccount  EscrowAccount  `json:"escrow_account"`
 }
 
 type DeploymentResponse struct {
 	Deployments []Deployment `json:"deployments"`
+	Pagination  Pagination   `json:"pagination"`
+}
+
+// Pagination describes a page of the results of a list query.
+type Pagination struct {
+	// NextKey is the key of the next page, empty on the last one.
+	NextKey string `json:"next_key"`
+	// Total is the number of results of the query, if counted.
+	Total string `json:"total"`
 }
@@ -0,0 +1,36 @@
+package types
+
+// Fee allowance types as reported by the chain.
+const (
+	AllowanceTypeBasic      = "/cosmos.feegrant.v1beta1.BasicAllowance"
+	AllowanceTypeAllowedMsg = "/cosmos.feegrant.v1beta1.AllowedMsgAllowance"
+)
+
+// Allowance is a fee allowance. An allowance restricted to some messages
+// wraps the basic allowance limiting what it may spend.
+type Allowance struct {
+	Type            string     `json:"@type"`
+	SpendLimit      []Coin     `json:"spend_limit"`
+	Expiration      string     `json:"expiration"`
+	Allowance       *Allowance `json:"allowance"`
+	AllowedMessages []string   `json:"allowed_messages"`
+}
+
+// Basic returns the basic allowance limiting what a may spend.
+func (a Allowance) Basic() Allowance {
+	if a.Allowance != nil {
+		return a.Allowance.Basic()
+	}
+	return a
+}
+
+// FeeGrant is a fee allowance granted by granter to grantee.
+type FeeGrant struct {
+	Granter   string    `json:"granter"`
+	Grantee   string    `json:"grantee"`
+	Allowance Allowance `json:"allowance"`
+}
+
+type FeeGrantResponse struct {
+	Allowance FeeGrant `json:"allowance"`
+}
@@ -0,0 +1,36 @@
+package types
+
+import "time"
+
+type UpgradePlan struct {
+	Name   string `json:"name"`
+	Height int64  `json:"height,string"`
+}
+
+type ParamChange struct {
+	Subspace string `json:"subspace"`
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+}
+
+// ProposalContent is a legacy proposal content or a message of a proposal. Only
+// the fields needed to tell whether it affects deployments are decoded.
+type ProposalContent struct {
+	Type    string        `json:"@type"`
+	Title   string        `json:"title"`
+	Plan    *UpgradePlan  `json:"plan"`
+	Changes []ParamChange `json:"changes"`
+	// Content is the legacy content a MsgExecLegacyContent executes.
+	Content *ProposalContent `json:"content"`
+}
+
+// Proposal holds the fields of both v1beta1 and v1 governance proposals.
+type Proposal struct {
+	ProposalId    string            `json:"proposal_id"`
+	Id            string            `json:"id"`
+	Title         string            `json:"title"`
+	Status        string            `json:"status"`
+	Content       *ProposalContent  `json:"content"`
+	Messages      []ProposalContent `json:"messages"`
+	VotingEndTime time.Time         `json:"voting_end_time"`
+}
@@ -0,0 +1,147 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type LeaseStatus struct {
+	Services       map[string]ServiceStatus    `json:"services"`
+	ForwardedPorts map[string][]ForwardedPort  `json:"forwarded_ports"`
+	IPs            map[string][]LeasedIPStatus `json:"ips"`
+}
+
+type ServiceStatus struct {
+	Name      string   `json:"name"`
+	Available int32    `json:"available"`
+	Total     int32    `json:"total"`
+	URIs      []string `json:"uris"`
+}
+
+// ForwardedPort is a raw TCP/UDP service port exposed by the provider on one of
+// its nodes, NodePort-style.
+type ForwardedPort struct {
+	Host         string `json:"host"`
+	Port         int32  `json:"port"`
+	ExternalPort int32  `json:"externalPort"`
+	Proto        string `json:"proto"`
+	Name         string `json:"name"`
+}
+
+// LeasedIPStatus is a service port exposed on a leased IP endpoint. The
+// provider reports it without JSON tags, hence the capitalized keys.
+type LeasedIPStatus struct {
+	IP           string `json:"IP"`
+	Port         int32  `json:"Port"`
+	ExternalPort int32  `json:"ExternalPort"`
+	Protocol     string `json:"Protocol"`
+}
+
+// SortedServiceNames returns the names of the given services in a stable order.
+func SortedServiceNames[T any](services map[string]T) []string {
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// MergeLeaseStatuses merges the statuses of the leases of a deployment, whose
+// services are placed on different providers.
+func MergeLeaseStatuses(statuses ...LeaseStatus) LeaseStatus {
+	merged := LeaseStatus{
+		Services:       map[string]ServiceStatus{},
+		ForwardedPorts: map[string][]ForwardedPort{},
+		IPs:            map[string][]LeasedIPStatus{},
+	}
+	for _, s := range statuses {
+		for name, svc := range s.Services {
+			merged.Services[name] = svc
+		}
+		for name, p := range s.ForwardedPorts {
+			merged.ForwardedPorts[name] = p
+		}
+		for name, ip := range s.IPs {
+			merged.IPs[name] = ip
+		}
+	}
+
+	return merged
+}
+
+// ConnectionDetails returns how to reach the services: the first URI of every
+// service keyed by <service>.uri, all its URIs separated by commas keyed by
+// <service>.uris, and the externally reachable address of every forwarded
+// port and leased IP, keyed by <service>.<port>.<protocol> for forwarded
+// ports and <service>.<port>.<protocol>.ip for leased IPs.
+func (s LeaseStatus) ConnectionDetails() map[string][]byte {
+	details := map[string][]byte{}
+
+	for service, svc := range s.Services {
+		if len(svc.URIs) == 0 {
+			continue
+		}
+		details[service+".uri"] = []byte(svc.URIs[0])
+		details[service+".uris"] = []byte(strings.Join(svc.URIs, ","))
+	}
+
+	for service, ports := range s.ForwardedPorts {
+		for _, p := range ports {
+			key := fmt.Sprintf("%s.%d.%s", service, p.Port, strings.ToLower(p.Proto))
+			details[key] = []byte(fmt.Sprintf("%s:%d", p.Host, p.ExternalPort))
+		}
+	}
+
+	for service, ips := range s.IPs {
+		for _, ip := range ips {
+			key := fmt.Sprintf("%s.%d.%s.ip", service, ip.Port, strings.ToLower(ip.Protocol))
+			details[key] = []byte(fmt.Sprintf("%s:%d", ip.IP, ip.ExternalPort))
+		}
+	}
+
+	return details
+}
+
+// Lease states as reported by the chain.
+const (
+	LeaseStateActive = "active"
+	LeaseStateClosed = "closed"
+)
+
+type LeaseId struct {
+	Owner    string `json:"owner"`
+	Dseq     string `json:"dseq"`
+	Gseq     int    `json:"gseq"`
+	Oseq     int    `json:"oseq"`
+	Provider string `json:"provider"`
+}
+
+type LeaseInfo struct {
+	LeaseId   LeaseId  `json:"lease_id"`
+	State     string   `json:"state"`
+	Price     BidPrice `json:"price"`
+	CreatedAt string   `json:"created_at"`
+	ClosedOn  string   `json:"closed_on"`
+}
+
+// DecCoin is an amount of a denom kept as the exact decimal string reported
+// by the chain, for amounts that must not lose precision.
+type DecCoin struct {
+	Denom  string `json:"denom"`
+	Amount string `json:"amount"`
+}
+
+type EscrowPayment struct {
+	State     string   `json:"state"`
+	Rate      BidPrice `json:"rate"`
+	Balance   DecCoin  `json:"balance"`
+	Withdrawn DecCoin  `json:"withdrawn"`
+}
+
+type Lease struct {
+	Lease         LeaseInfo     `json:"lease"`
+	EscrowPayment EscrowPayment `json:"escrow_payment"`
+}
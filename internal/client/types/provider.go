@@ -1,8 +1,38 @@
 package types
 
+// ProviderAttributeRegion is the attribute providers use to advertise their region.
+const ProviderAttributeRegion = "region"
+
 type Provider struct {
 	Address    string            `json:"address"`
+	HostURI    string            `json:"hostUri"`
 	Active     bool              `json:"active"`
+	Audited    bool              `json:"audited"`
 	Uptime     float32           `json:"uptime"`
 	Attributes map[string]string `json:"attributes"`
 }
+
+// Region returns the region advertised by the provider, or an empty string.
+func (p Provider) Region() string {
+	return p.Attributes[ProviderAttributeRegion]
+}
+
+// Attribute is a key-value pair advertised on chain, e.g. by a provider.
+type Attribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// ProviderInfo is the contact information of a provider.
+type ProviderInfo struct {
+	Email   string `json:"email"`
+	Website string `json:"website"`
+}
+
+// ProviderRecord is a provider as registered on chain.
+type ProviderRecord struct {
+	Owner      string       `json:"owner"`
+	HostURI    string       `json:"host_uri"`
+	Attributes []Attribute  `json:"attributes"`
+	Info       ProviderInfo `json:"info"`
+}
@@ -4,8 +4,32 @@ import "errors"
 
 type Transaction struct {
 	Height string           `json:"height"`
+	TxHash string           `json:"txhash"`
+	Code   uint32           `json:"code"`
 	Logs   []TransactionLog `json:"logs"`
 	RawLog string           `json:"raw_log"`
+	// Events are reported at the top level of the response by newer chain
+	// versions, which leave Logs empty.
+	Events []TransactionEvent `json:"events"`
+}
+
+// FindEvents returns the events of the given type, whether they were
+// reported at the top level of the response or in its logs.
+func (t Transaction) FindEvents(typ string) []TransactionEvent {
+	var events []TransactionEvent
+	for _, e := range t.Events {
+		if e.Type == typ {
+			events = append(events, e)
+		}
+	}
+	for _, l := range t.Logs {
+		for _, e := range l.Events {
+			if e.Type == typ {
+				events = append(events, e)
+			}
+		}
+	}
+	return events
 }
 
 type TransactionLog struct {
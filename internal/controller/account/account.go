@@ -0,0 +1,258 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package account observes the balances of Akash accounts.
+package account
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	ctrl "sigs.k8s.io/controller-runtime"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	apisv1alpha1 "github.com/overlock-network/provider-akash/apis/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+	"github.com/overlock-network/provider-akash/internal/features"
+	"github.com/overlock-network/provider-akash/internal/tracing"
+)
+
+const (
+	errNotAccount     = "managed resource is not an Account custom resource"
+	errGetPC          = "cannot get ProviderConfig"
+	errNewClient      = "cannot create new client"
+	errAccountAddress = "cannot determine account address"
+	errGetBalances    = "cannot query spendable balances"
+	errGetDelegations = "cannot query delegations"
+	errGetDeployments = "cannot query active deployments"
+)
+
+var spendableBalance = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "akash_account_spendable_balance",
+	Help: "Spendable balance of an observed account, in the smallest unit of its denomination.",
+}, []string{"account", "denom"})
+
+func init() {
+	metrics.Registry.MustRegister(spendableBalance)
+}
+
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=accounts,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=accounts/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigusages,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Setup adds a controller that reconciles Account managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	name := managed.ControllerName(v1alpha1.AccountGroupKind)
+
+	opts := []managed.ReconcilerOption{
+		managed.WithExternalConnecter(tracing.NewConnecter(v1alpha1.AccountKind, &connector{
+			kube:      mgr.GetClient(),
+			usage:     resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClient: client.NewAPIFromManagedResource,
+		})),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+	}
+	if o.Features.Enabled(features.EnableAlphaManagementPolicies) {
+		opts = append(opts, managed.WithManagementPolicies())
+	}
+
+	r := managed.NewReconciler(mgr, resource.ManagedKind(v1alpha1.AccountGroupVersionKind), opts...)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Account{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// A connector produces an external client for the ProviderConfig of an
+// Account.
+type connector struct {
+	kube      kubeclient.Client
+	usage     resource.Tracker
+	newClient func(ctx context.Context, kube kubeclient.Client, usage resource.Tracker, mg resource.Managed, pcInfo client.ProviderConfigInfo) (client.AkashAPI, error)
+}
+
+// Connect produces an ExternalClient with a ready-to-use AkashClient.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.Account)
+	if !ok {
+		return nil, errors.New(errNotAccount)
+	}
+
+	pcInfo, err := client.GetProviderConfig(ctx, c.kube, cr)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+
+	ak, err := c.newClient(ctx, c.kube, c.usage, mg, pcInfo)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+
+	return &external{client: ak}, nil
+}
+
+// An external observes an account on chain. Accounts are never created,
+// updated or deleted.
+type external struct {
+	client client.AkashAPI
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Account)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotAccount)
+	}
+
+	// Nothing is deleted on chain, so the Account is gone once it is deleted.
+	if meta.WasDeleted(cr) {
+		spendableBalance.DeletePartialMatch(prometheus.Labels{"account": cr.GetName()})
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	address, err := e.address(cr)
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errAccountAddress)
+	}
+
+	balances, err := e.client.GetSpendableBalances(ctx, address)
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetBalances)
+	}
+	delegations, err := e.client.GetDelegations(ctx, address)
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetDelegations)
+	}
+	deployments, _, err := e.client.ListDeployments(ctx, client.ListOptions{Filters: client.DeploymentFilters{Owner: address, State: akashtypes.DeploymentStateActive}})
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetDeployments)
+	}
+
+	cr.Status.AtProvider = observe(address, balances, delegations, deployments)
+	for _, c := range cr.Status.AtProvider.Balances {
+		if amount, err := strconv.ParseFloat(c.Amount, 64); err == nil {
+			spendableBalance.WithLabelValues(cr.GetName(), c.Denom).Set(amount)
+		}
+	}
+
+	cr.SetConditions(xpv1.Available())
+	if t := cr.Spec.ForProvider.LowBalance; t != nil {
+		cr.SetConditions(lowBalance(t, balances))
+	}
+
+	return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, nil
+}
+
+// address returns the address of the account observed by cr.
+func (e *external) address(cr *v1alpha1.Account) (string, error) {
+	if cr.Spec.ForProvider.Address != nil {
+		return *cr.Spec.ForProvider.Address, nil
+	}
+	return e.client.AccountAddress()
+}
+
+func (e *external) Create(_ context.Context, _ resource.Managed) (managed.ExternalCreation, error) {
+	return managed.ExternalCreation{}, nil
+}
+
+func (e *external) Update(_ context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
+	return managed.ExternalUpdate{}, nil
+}
+
+func (e *external) Delete(_ context.Context, _ resource.Managed) error {
+	return nil
+}
+
+// observe returns the observation of the account at address given its
+// spendable balances, delegations and active deployments.
+func observe(address string, balances akashtypes.Balances, delegations []akashtypes.DelegationResponse, deployments []akashtypes.Deployment) v1alpha1.AccountObservation {
+	o := v1alpha1.AccountObservation{Address: address, ActiveDeployments: len(deployments)}
+	for _, b := range balances.Balances {
+		o.Balances = append(o.Balances, v1alpha1.Coin{Denom: b.Denom, Amount: b.Amount})
+	}
+	for _, d := range delegations {
+		o.Delegations = append(o.Delegations, v1alpha1.Delegation{
+			Validator: d.Delegation.ValidatorAddress,
+			Balance:   v1alpha1.Coin{Denom: d.Balance.Denom, Amount: d.Balance.Amount},
+		})
+	}
+
+	escrow := map[string]*big.Rat{}
+	for _, d := range deployments {
+		b := d.EscrowAccount.Balance
+		amount, ok := new(big.Rat).SetString(b.Amount)
+		if !ok {
+			continue
+		}
+		if escrow[b.Denom] == nil {
+			escrow[b.Denom] = new(big.Rat)
+		}
+		escrow[b.Denom].Add(escrow[b.Denom], amount)
+	}
+	denoms := make([]string, 0, len(escrow))
+	for denom := range escrow {
+		denoms = append(denoms, denom)
+	}
+	sort.Strings(denoms)
+	for _, denom := range denoms {
+		// Escrow balances are decimal, but only whole units can be spent.
+		amount := new(big.Int).Quo(escrow[denom].Num(), escrow[denom].Denom())
+		o.EscrowObligations = append(o.EscrowObligations, v1alpha1.Coin{Denom: denom, Amount: amount.String()})
+	}
+
+	return o
+}
+
+// lowBalance returns the LowBalance condition of an account holding balances
+// given threshold t.
+func lowBalance(t *v1alpha1.BalanceThreshold, balances akashtypes.Balances) xpv1.Condition {
+	denom := t.Denom
+	if denom == "" {
+		denom = client.DefaultBalanceDenom
+	}
+	balance := balances.AmountOf(denom)
+	have, ok := new(big.Int).SetString(balance, 10)
+	if !ok {
+		have, balance = new(big.Int), "0"
+	}
+	// The pattern of the field guarantees a valid number.
+	threshold, _ := new(big.Int).SetString(t.Amount, 10)
+	if have.Cmp(threshold) < 0 {
+		return v1alpha1.BalanceLow(balance+denom, t.Amount+denom)
+	}
+	return v1alpha1.BalanceSufficient()
+}
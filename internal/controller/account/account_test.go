@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package account
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+func TestObserve(t *testing.T) {
+	escrow := func(denom, amount string) akashtypes.Deployment {
+		return akashtypes.Deployment{EscrowAccount: akashtypes.EscrowAccount{
+			Balance: akashtypes.EscrowAccountBalance{Denom: denom, Amount: amount},
+		}}
+	}
+
+	cases := map[string]struct {
+		balances    akashtypes.Balances
+		delegations []akashtypes.DelegationResponse
+		deployments []akashtypes.Deployment
+		want        v1alpha1.AccountObservation
+	}{
+		"Empty": {
+			want: v1alpha1.AccountObservation{Address: "akash1owner"},
+		},
+		"BalancesAndDelegations": {
+			balances: akashtypes.Balances{Balances: []akashtypes.Coin{{Denom: "uakt", Amount: "1000"}}},
+			delegations: []akashtypes.DelegationResponse{{
+				Delegation: akashtypes.Delegation{ValidatorAddress: "akashvaloper1v"},
+				Balance:    akashtypes.Coin{Denom: "uakt", Amount: "500"},
+			}},
+			want: v1alpha1.AccountObservation{
+				Address:     "akash1owner",
+				Balances:    []v1alpha1.Coin{{Denom: "uakt", Amount: "1000"}},
+				Delegations: []v1alpha1.Delegation{{Validator: "akashvaloper1v", Balance: v1alpha1.Coin{Denom: "uakt", Amount: "500"}}},
+			},
+		},
+		"EscrowSummedByDenom": {
+			deployments: []akashtypes.Deployment{
+				escrow("uakt", "1000.500000000000000000"),
+				escrow("ibc/USDC", "20.000000000000000000"),
+				escrow("uakt", "999.600000000000000000"),
+			},
+			want: v1alpha1.AccountObservation{
+				Address:           "akash1owner",
+				ActiveDeployments: 3,
+				EscrowObligations: []v1alpha1.Coin{{Denom: "ibc/USDC", Amount: "20"}, {Denom: "uakt", Amount: "2000"}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := observe("akash1owner", tc.balances, tc.delegations, tc.deployments)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("observe(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLowBalance(t *testing.T) {
+	balances := akashtypes.Balances{Balances: []akashtypes.Coin{{Denom: "uakt", Amount: "1000"}}}
+
+	cases := map[string]struct {
+		threshold *v1alpha1.BalanceThreshold
+		want      xpv1.Condition
+	}{
+		"Sufficient": {
+			threshold: &v1alpha1.BalanceThreshold{Amount: "1000", Denom: "uakt"},
+			want:      v1alpha1.BalanceSufficient(),
+		},
+		"Low": {
+			threshold: &v1alpha1.BalanceThreshold{Amount: "1001", Denom: "uakt"},
+			want:      v1alpha1.BalanceLow("1000uakt", "1001uakt"),
+		},
+		"DefaultDenom": {
+			threshold: &v1alpha1.BalanceThreshold{Amount: "2000"},
+			want:      v1alpha1.BalanceLow("1000uakt", "2000uakt"),
+		},
+		"MissingDenom": {
+			threshold: &v1alpha1.BalanceThreshold{Amount: "1", Denom: "ibc/USDC"},
+			want:      v1alpha1.BalanceLow("0ibc/USDC", "1ibc/USDC"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := lowBalance(tc.threshold, balances)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("lowBalance(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
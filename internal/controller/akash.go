@@ -20,16 +20,49 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/controller"
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	"github.com/overlock-network/provider-akash/internal/controller/account"
+	"github.com/overlock-network/provider-akash/internal/controller/audit"
+	"github.com/overlock-network/provider-akash/internal/controller/authzgrant"
 	"github.com/overlock-network/provider-akash/internal/controller/config"
 	"github.com/overlock-network/provider-akash/internal/controller/deployment"
+	"github.com/overlock-network/provider-akash/internal/controller/feegrant"
+	"github.com/overlock-network/provider-akash/internal/controller/hostname"
+	"github.com/overlock-network/provider-akash/internal/controller/iplease"
+	"github.com/overlock-network/provider-akash/internal/controller/manifest"
+	"github.com/overlock-network/provider-akash/internal/controller/migration"
+	"github.com/overlock-network/provider-akash/internal/controller/provider"
 )
 
+// The manager elects a leader with a Lease and records events of its own.
+// +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
 // Setup creates all Akash controllers with the supplied logger and adds them to
 // the supplied manager.
 func Setup(mgr ctrl.Manager, o controller.Options) error {
 	for _, setup := range []func(ctrl.Manager, controller.Options) error{
 		config.Setup,
+		config.SetupEndpoints,
+		config.SetupGovernance,
+		config.SetupCredentials,
 		deployment.Setup,
+		deployment.SetupPromotion,
+		deployment.SetupDiagnostics,
+		deployment.SetupUnleased,
+		deployment.SetupExpiry,
+		deployment.SetupHealth,
+		deployment.SetupSpend,
+		deployment.SetupCertificates,
+		deployment.SetupLogForwarder,
+		account.Setup,
+		provider.Setup,
+		audit.Setup,
+		hostname.Setup,
+		iplease.Setup,
+		feegrant.Setup,
+		authzgrant.Setup,
+		manifest.Setup,
+		migration.Setup,
 	} {
 		if err := setup(mgr, o); err != nil {
 			return err
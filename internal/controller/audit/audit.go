@@ -0,0 +1,200 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package audit observes the attributes of providers signed by auditors.
+package audit
+
+import (
+	"context"
+	"maps"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	apisv1alpha1 "github.com/overlock-network/provider-akash/apis/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+	"github.com/overlock-network/provider-akash/internal/features"
+	"github.com/overlock-network/provider-akash/internal/tracing"
+)
+
+const (
+	errNotAudit    = "managed resource is not an Audit custom resource"
+	errGetPC       = "cannot get ProviderConfig"
+	errNewClient   = "cannot create new client"
+	errGetAudits   = "cannot query provider audits"
+	labelValueTrue = "true"
+)
+
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=audits,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=audits/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigusages,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Setup adds a controller that reconciles Audit managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	name := managed.ControllerName(v1alpha1.AuditGroupKind)
+
+	opts := []managed.ReconcilerOption{
+		managed.WithExternalConnecter(tracing.NewConnecter(v1alpha1.AuditKind, &connector{
+			kube:      mgr.GetClient(),
+			usage:     resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClient: client.NewAPIFromManagedResource,
+		})),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+	}
+	if o.Features.Enabled(features.EnableAlphaManagementPolicies) {
+		opts = append(opts, managed.WithManagementPolicies())
+	}
+
+	r := managed.NewReconciler(mgr, resource.ManagedKind(v1alpha1.AuditGroupVersionKind), opts...)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Audit{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// A connector produces an external client for the ProviderConfig of an
+// Audit.
+type connector struct {
+	kube      kubeclient.Client
+	usage     resource.Tracker
+	newClient func(ctx context.Context, kube kubeclient.Client, usage resource.Tracker, mg resource.Managed, pcInfo client.ProviderConfigInfo) (client.AkashAPI, error)
+}
+
+// Connect produces an ExternalClient with a ready-to-use AkashClient.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.Audit)
+	if !ok {
+		return nil, errors.New(errNotAudit)
+	}
+
+	pcInfo, err := client.GetProviderConfig(ctx, c.kube, cr)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+
+	ak, err := c.newClient(ctx, c.kube, c.usage, mg, pcInfo)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+
+	return &external{client: ak}, nil
+}
+
+// An external observes the audits of a provider on chain. Audits are never
+// created, updated or deleted.
+type external struct {
+	client client.AkashAPI
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Audit)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotAudit)
+	}
+
+	// Nothing is deleted on chain, so the Audit is gone once it is deleted.
+	if meta.WasDeleted(cr) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	audits, err := e.client.GetProviderAudits(ctx, cr.Spec.ForProvider.Provider)
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetAudits)
+	}
+
+	cr.Status.AtProvider = observe(audits, cr.Spec.ForProvider.Auditor)
+	cr.SetConditions(xpv1.Available())
+
+	// The labels are persisted like late initialized fields.
+	labeled := label(cr)
+
+	return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true, ResourceLateInitialized: labeled}, nil
+}
+
+func (e *external) Create(_ context.Context, _ resource.Managed) (managed.ExternalCreation, error) {
+	return managed.ExternalCreation{}, nil
+}
+
+func (e *external) Update(_ context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
+	return managed.ExternalUpdate{}, nil
+}
+
+func (e *external) Delete(_ context.Context, _ resource.Managed) error {
+	return nil
+}
+
+// observe returns the observation of the given audits of a provider, limited
+// to those of auditor unless it is nil.
+func observe(audits []akashtypes.AuditedAttributes, auditor *string) v1alpha1.AuditObservation {
+	o := v1alpha1.AuditObservation{}
+	for _, a := range audits {
+		if auditor != nil && a.Auditor != *auditor {
+			continue
+		}
+		r := v1alpha1.AuditRecord{Auditor: a.Auditor}
+		if len(a.Attributes) > 0 {
+			r.Attributes = make(map[string]string, len(a.Attributes))
+			for _, attr := range a.Attributes {
+				r.Attributes[attr.Key] = attr.Value
+			}
+		}
+		o.Records = append(o.Records, r)
+	}
+	sort.Slice(o.Records, func(i, j int) bool { return o.Records[i].Auditor < o.Records[j].Auditor })
+	return o
+}
+
+// label labels cr with its provider and the auditors of its records, removing
+// the labels of auditors it no longer has a record of, and reports whether
+// any label changed.
+func label(cr *v1alpha1.Audit) bool {
+	labels := map[string]string{}
+	for k, v := range cr.GetLabels() {
+		if !strings.HasPrefix(k, v1alpha1.LabelKeyPrefixAuditor) {
+			labels[k] = v
+		}
+	}
+	labels[v1alpha1.LabelKeyProvider] = cr.Spec.ForProvider.Provider
+	for _, r := range cr.Status.AtProvider.Records {
+		labels[v1alpha1.LabelKeyPrefixAuditor+r.Auditor] = labelValueTrue
+	}
+
+	if maps.Equal(labels, cr.GetLabels()) {
+		return false
+	}
+	cr.SetLabels(labels)
+	return true
+}
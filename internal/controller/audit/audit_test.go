@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+func TestObserve(t *testing.T) {
+	audits := []akashtypes.AuditedAttributes{
+		{Owner: "akash1provider", Auditor: "akash1auditorb", Attributes: []akashtypes.Attribute{{Key: "tier", Value: "community"}}},
+		{Owner: "akash1provider", Auditor: "akash1auditora"},
+	}
+	auditor := "akash1auditorb"
+
+	cases := map[string]struct {
+		auditor *string
+		want    v1alpha1.AuditObservation
+	}{
+		"AllAuditors": {
+			want: v1alpha1.AuditObservation{Records: []v1alpha1.AuditRecord{
+				{Auditor: "akash1auditora"},
+				{Auditor: "akash1auditorb", Attributes: map[string]string{"tier": "community"}},
+			}},
+		},
+		"OneAuditor": {
+			auditor: &auditor,
+			want: v1alpha1.AuditObservation{Records: []v1alpha1.AuditRecord{
+				{Auditor: "akash1auditorb", Attributes: map[string]string{"tier": "community"}},
+			}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := observe(audits, tc.auditor)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("observe(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLabel(t *testing.T) {
+	type want struct {
+		labels  map[string]string
+		changed bool
+	}
+
+	cases := map[string]struct {
+		labels  map[string]string
+		records []v1alpha1.AuditRecord
+		want    want
+	}{
+		"Unlabeled": {
+			labels:  map[string]string{"team": "platform"},
+			records: []v1alpha1.AuditRecord{{Auditor: "akash1auditora"}},
+			want: want{
+				labels: map[string]string{
+					"team":                                 "platform",
+					v1alpha1.LabelKeyProvider:              "akash1provider",
+					"auditor.akash.web7.md/akash1auditora": "true",
+				},
+				changed: true,
+			},
+		},
+		"UpToDate": {
+			labels: map[string]string{
+				v1alpha1.LabelKeyProvider:              "akash1provider",
+				"auditor.akash.web7.md/akash1auditora": "true",
+			},
+			records: []v1alpha1.AuditRecord{{Auditor: "akash1auditora"}},
+			want: want{
+				labels: map[string]string{
+					v1alpha1.LabelKeyProvider:              "akash1provider",
+					"auditor.akash.web7.md/akash1auditora": "true",
+				},
+			},
+		},
+		"AuditorGone": {
+			labels: map[string]string{
+				v1alpha1.LabelKeyProvider:              "akash1provider",
+				"auditor.akash.web7.md/akash1auditora": "true",
+			},
+			want: want{
+				labels:  map[string]string{v1alpha1.LabelKeyProvider: "akash1provider"},
+				changed: true,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &v1alpha1.Audit{
+				ObjectMeta: metav1.ObjectMeta{Labels: tc.labels},
+				Spec:       v1alpha1.AuditSpec{ForProvider: v1alpha1.AuditParameters{Provider: "akash1provider"}},
+				Status:     v1alpha1.AuditStatus{AtProvider: v1alpha1.AuditObservation{Records: tc.records}},
+			}
+			changed := label(cr)
+			got := want{labels: cr.GetLabels(), changed: changed}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("label(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package authzgrant manages deposit authorizations granted to other
+// accounts.
+package authzgrant
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	apisv1alpha1 "github.com/overlock-network/provider-akash/apis/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+	"github.com/overlock-network/provider-akash/internal/features"
+	"github.com/overlock-network/provider-akash/internal/tracing"
+)
+
+const (
+	errNotAuthzGrant = "managed resource is not an AuthzGrant custom resource"
+	errGetPC         = "cannot get ProviderConfig"
+	errNewClient     = "cannot create new client"
+	errGranter       = "cannot determine granter"
+	errGetGrant      = "cannot query deposit authorization"
+	errGrant         = "cannot grant deposit authorization"
+	errRevoke        = "cannot revoke deposit authorization"
+)
+
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=authzgrants,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=authzgrants/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigusages,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Setup adds a controller that reconciles AuthzGrant managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	name := managed.ControllerName(v1alpha1.AuthzGrantGroupKind)
+
+	opts := []managed.ReconcilerOption{
+		managed.WithExternalConnecter(tracing.NewConnecter(v1alpha1.AuthzGrantKind, &connector{
+			kube:      mgr.GetClient(),
+			usage:     resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClient: client.NewAPIFromManagedResource,
+		})),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+	}
+	if o.Features.Enabled(features.EnableAlphaManagementPolicies) {
+		opts = append(opts, managed.WithManagementPolicies())
+	}
+
+	r := managed.NewReconciler(mgr, resource.ManagedKind(v1alpha1.AuthzGrantGroupVersionKind), opts...)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.AuthzGrant{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// A connector produces an external client for the ProviderConfig of an
+// AuthzGrant.
+type connector struct {
+	kube      kubeclient.Client
+	usage     resource.Tracker
+	newClient func(ctx context.Context, kube kubeclient.Client, usage resource.Tracker, mg resource.Managed, pcInfo client.ProviderConfigInfo) (client.AkashAPI, error)
+}
+
+// Connect produces an ExternalClient with a ready-to-use AkashClient.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.AuthzGrant)
+	if !ok {
+		return nil, errors.New(errNotAuthzGrant)
+	}
+
+	pcInfo, err := client.GetProviderConfig(ctx, c.kube, cr)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+
+	ak, err := c.newClient(ctx, c.kube, c.usage, mg, pcInfo)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+
+	return &external{client: ak}, nil
+}
+
+// An external grants, renews and revokes deposit authorizations on chain.
+type external struct {
+	client client.AkashAPI
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.AuthzGrant)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotAuthzGrant)
+	}
+
+	granter, err := e.client.AccountAddress()
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGranter)
+	}
+	grant, err := e.client.GetDepositAuthorization(ctx, granter, cr.Spec.ForProvider.Grantee)
+	if client.IsDepositAuthorizationNotFound(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetGrant)
+	}
+
+	granted := cr.Status.AtProvider.GrantedSpendLimit
+	cr.Status.AtProvider = observe(grant)
+	cr.Status.AtProvider.GrantedSpendLimit = granted
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: upToDate(cr.Spec.ForProvider, cr.Status.AtProvider, time.Now())}, nil
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.AuthzGrant)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotAuthzGrant)
+	}
+
+	cr.SetConditions(xpv1.Creating())
+	return managed.ExternalCreation{}, e.grant(ctx, cr)
+}
+
+// Update renews the authorization. Granting replaces the authorization on
+// chain, restoring the full spend limit and extending the expiration.
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.AuthzGrant)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotAuthzGrant)
+	}
+
+	return managed.ExternalUpdate{}, e.grant(ctx, cr)
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.AuthzGrant)
+	if !ok {
+		return errors.New(errNotAuthzGrant)
+	}
+
+	cr.SetConditions(xpv1.Deleting())
+	err := e.client.RevokeDepositAuthorization(ctx, cr.Spec.ForProvider.Grantee)
+	return errors.Wrap(resource.Ignore(client.IsDepositAuthorizationNotFound, err), errRevoke)
+}
+
+// grant grants the authorization of cr for its validity and records the
+// spend limit it was granted with.
+func (e *external) grant(ctx context.Context, cr *v1alpha1.AuthzGrant) error {
+	p := cr.Spec.ForProvider
+	limit := spendLimit(p)
+	expiration := time.Now().Add(durationOr(p.Validity, client.DefaultAuthzValidity))
+	if err := e.client.GrantDepositAuthorization(ctx, p.Grantee, akashtypes.Coin{Denom: limit.Denom, Amount: limit.Amount}, expiration); err != nil {
+		return errors.Wrap(err, errGrant)
+	}
+	cr.Status.AtProvider.GrantedSpendLimit = &limit
+	return nil
+}
+
+// spendLimit returns the spend limit of p.
+func spendLimit(p v1alpha1.AuthzGrantParameters) v1alpha1.Coin {
+	c := v1alpha1.Coin{Denom: p.SpendLimit.Denom, Amount: p.SpendLimit.Amount}
+	if c.Denom == "" {
+		c.Denom = client.DefaultDepositDenom
+	}
+	return c
+}
+
+// durationOr returns d, or def when d is unset.
+func durationOr(d *metav1.Duration, def time.Duration) time.Duration {
+	if d == nil {
+		return def
+	}
+	return d.Duration
+}
+
+// observe returns the observation of grant.
+func observe(grant akashtypes.AuthzGrant) v1alpha1.AuthzGrantObservation {
+	limit := grant.Authorization.SpendLimit
+	o := v1alpha1.AuthzGrantObservation{SpendLimit: &v1alpha1.Coin{Denom: limit.Denom, Amount: limit.Amount}}
+	if t, err := time.Parse(time.RFC3339, grant.Expiration); err == nil {
+		o.Expiration = &metav1.Time{Time: t}
+	}
+	return o
+}
+
+// upToDate reports whether the authorization observed as o was granted with
+// the spend limit of p and does not have to be renewed yet at now.
+func upToDate(p v1alpha1.AuthzGrantParameters, o v1alpha1.AuthzGrantObservation, now time.Time) bool {
+	if o.GrantedSpendLimit == nil || *o.GrantedSpendLimit != spendLimit(p) {
+		return false
+	}
+	if o.Expiration == nil {
+		return true
+	}
+	return now.Add(durationOr(p.RenewBefore, client.DefaultAuthzRenewBefore)).Before(o.Expiration.Time)
+}
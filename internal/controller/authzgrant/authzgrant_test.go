@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authzgrant
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+func TestObserve(t *testing.T) {
+	expiration := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		grant akashtypes.AuthzGrant
+		want  v1alpha1.AuthzGrantObservation
+	}{
+		"Expiring": {
+			grant: akashtypes.AuthzGrant{
+				Authorization: akashtypes.Authorization{
+					Type:       "/akash.deployment.v1beta3.DepositDeploymentAuthorization",
+					SpendLimit: akashtypes.Coin{Denom: "uakt", Amount: "400"},
+				},
+				Expiration: "2025-01-01T00:00:00Z",
+			},
+			want: v1alpha1.AuthzGrantObservation{
+				SpendLimit: &v1alpha1.Coin{Denom: "uakt", Amount: "400"},
+				Expiration: &metav1.Time{Time: expiration},
+			},
+		},
+		"NeverExpiring": {
+			grant: akashtypes.AuthzGrant{
+				Authorization: akashtypes.Authorization{
+					Type:       "/akash.deployment.v1beta3.DepositDeploymentAuthorization",
+					SpendLimit: akashtypes.Coin{Denom: "uakt", Amount: "400"},
+				},
+			},
+			want: v1alpha1.AuthzGrantObservation{
+				SpendLimit: &v1alpha1.Coin{Denom: "uakt", Amount: "400"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := observe(tc.grant)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("observe(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUpToDate(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limit := v1alpha1.Deposit{Amount: "1000", Denom: "uakt"}
+	granted := &v1alpha1.Coin{Denom: "uakt", Amount: "1000"}
+	renewBefore := &metav1.Duration{Duration: 24 * time.Hour}
+	expiresAt := func(d time.Duration) *metav1.Time {
+		return &metav1.Time{Time: now.Add(d)}
+	}
+
+	cases := map[string]struct {
+		p    v1alpha1.AuthzGrantParameters
+		o    v1alpha1.AuthzGrantObservation
+		want bool
+	}{
+		"UpToDate": {
+			p:    v1alpha1.AuthzGrantParameters{SpendLimit: limit, RenewBefore: renewBefore},
+			o:    v1alpha1.AuthzGrantObservation{GrantedSpendLimit: granted, Expiration: expiresAt(48 * time.Hour)},
+			want: true,
+		},
+		"DefaultDenom": {
+			p:    v1alpha1.AuthzGrantParameters{SpendLimit: v1alpha1.Deposit{Amount: "1000"}},
+			o:    v1alpha1.AuthzGrantObservation{GrantedSpendLimit: granted},
+			want: true,
+		},
+		"NotGrantedByUs": {
+			p:    v1alpha1.AuthzGrantParameters{SpendLimit: limit},
+			o:    v1alpha1.AuthzGrantObservation{Expiration: expiresAt(48 * time.Hour)},
+			want: false,
+		},
+		"SpendLimitChanged": {
+			p:    v1alpha1.AuthzGrantParameters{SpendLimit: v1alpha1.Deposit{Amount: "2000", Denom: "uakt"}},
+			o:    v1alpha1.AuthzGrantObservation{GrantedSpendLimit: granted},
+			want: false,
+		},
+		"DueForRenewal": {
+			p:    v1alpha1.AuthzGrantParameters{SpendLimit: limit, RenewBefore: renewBefore},
+			o:    v1alpha1.AuthzGrantObservation{GrantedSpendLimit: granted, Expiration: expiresAt(12 * time.Hour)},
+			want: false,
+		},
+		"DueForRenewalByDefault": {
+			p:    v1alpha1.AuthzGrantParameters{SpendLimit: limit},
+			o:    v1alpha1.AuthzGrantObservation{GrantedSpendLimit: granted, Expiration: expiresAt(48 * time.Hour)},
+			want: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := upToDate(tc.p, tc.o, now); got != tc.want {
+				t.Errorf("upToDate(...): want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+)
+
+// managedObjects are the managed resource kinds reconciled by this provider,
+// and the records kept for them. They are the kinds whose informers grow with
+// the size of the fleet.
+var managedObjects = []client.Object{
+	&v1alpha1.Deployment{},
+	&v1alpha1.FailoverRecord{},
+	&v1alpha1.Account{},
+	&v1alpha1.Provider{},
+	&v1alpha1.Audit{},
+	&v1alpha1.Hostname{},
+	&v1alpha1.IPLease{},
+	&v1alpha1.FeeGrant{},
+	&v1alpha1.AuthzGrant{},
+	&v1alpha1.Manifest{},
+}
+
+// CacheOptions returns the options of the manager cache. When the selector is
+// not empty, only the managed resources it matches are cached and reconciled.
+// When the secret selector is not empty, only the Secrets it matches are
+// watched, e.g. for changes to the SDLs they hold. Secrets are still read
+// directly, as RBAC cannot restrict access to them by label. When
+// stripPayloads is true, managed fields are dropped from every cached object,
+// as no controller reads them.
+func CacheOptions(syncPeriod *time.Duration, selector, secretSelector labels.Selector, stripPayloads bool) cache.Options {
+	o := cache.Options{SyncPeriod: syncPeriod, ByObject: map[client.Object]cache.ByObject{}}
+
+	if stripPayloads {
+		o.DefaultTransform = StripObjectPayload
+	}
+
+	if selector != nil && !selector.Empty() {
+		for _, obj := range managedObjects {
+			o.ByObject[obj] = cache.ByObject{Label: selector}
+		}
+	}
+
+	if secretSelector != nil && !secretSelector.Empty() {
+		o.ByObject[&corev1.Secret{}] = cache.ByObject{Label: secretSelector, Transform: o.DefaultTransform}
+	}
+
+	return o
+}
+
+// ClientOptions returns the options of the manager client. Secrets are read
+// directly from the API server unless cacheSecrets is true, so the provider
+// does not keep an informer on every Secret of the cluster merely to read a
+// handful of credentials.
+func ClientOptions(cacheSecrets bool) client.Options {
+	if cacheSecrets {
+		return client.Options{}
+	}
+
+	return client.Options{
+		Cache: &client.CacheOptions{DisableFor: []client.Object{&corev1.Secret{}}},
+	}
+}
+
+// StripObjectPayload is a cache transform removing the managed fields of an
+// object, which are never read by the controllers but can dominate its size.
+// Cached objects are written back, e.g. to add a finalizer, and updates
+// without managed fields leave those of the API server untouched. Annotations
+// are kept: the last-applied configuration is just as large, but dropping it
+// from an update would remove it from the API server and break kubectl apply.
+func StripObjectPayload(obj interface{}) (interface{}, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		// Not an object, e.g. a tombstone. Leave it untouched.
+		return obj, nil
+	}
+
+	accessor.SetManagedFields(nil)
+	return obj, nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+)
+
+func TestStripObjectPayload(t *testing.T) {
+	lastApplied := map[string]string{"kubectl.kubernetes.io/last-applied-configuration": `{"kind":"Deployment"}`}
+	tombstone := cache.DeletedFinalStateUnknown{Key: "default/web"}
+
+	cases := map[string]struct {
+		reason string
+		obj    interface{}
+		want   interface{}
+	}{
+		"ManagedFields": {
+			reason: "Managed fields should be dropped.",
+			obj: &v1alpha1.Deployment{ObjectMeta: metav1.ObjectMeta{
+				Name:          "web",
+				ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationApply}},
+			}},
+			want: &v1alpha1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web"}},
+		},
+		"LastAppliedConfiguration": {
+			reason: "The last-applied configuration should be kept, as updates without it would remove it from the API server.",
+			obj: &v1alpha1.Deployment{ObjectMeta: metav1.ObjectMeta{
+				Name:          "web",
+				Annotations:   lastApplied,
+				ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+			}},
+			want: &v1alpha1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Annotations: lastApplied}},
+		},
+		"NotAnObject": {
+			reason: "Anything else than an object, e.g. a tombstone, should be left untouched.",
+			obj:    tombstone,
+			want:   tombstone,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := StripObjectPayload(tc.obj)
+			if err != nil {
+				t.Fatalf("\n%s\nStripObjectPayload(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nStripObjectPayload(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCacheOptions(t *testing.T) {
+	selector := labels.SelectorFromSet(labels.Set{"team": "web"})
+
+	cases := map[string]struct {
+		reason         string
+		selector       labels.Selector
+		secretSelector labels.Selector
+		strip          bool
+		wantByObject   int
+		wantTransform  bool
+	}{
+		"Defaults": {
+			reason:       "Without selectors nothing should be restricted, nor stripped.",
+			selector:     labels.Everything(),
+			wantByObject: 0,
+		},
+		"Selector": {
+			reason:       "A selector should restrict every managed resource kind.",
+			selector:     selector,
+			wantByObject: len(managedObjects),
+		},
+		"SecretSelector": {
+			reason:         "A secret selector should only restrict Secrets.",
+			secretSelector: selector,
+			wantByObject:   1,
+		},
+		"Strip": {
+			reason:        "Stripping payloads should transform every cached object.",
+			strip:         true,
+			wantTransform: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			period := time.Hour
+			o := CacheOptions(&period, tc.selector, tc.secretSelector, tc.strip)
+			if diff := cmp.Diff(tc.wantByObject, len(o.ByObject)); diff != "" {
+				t.Errorf("\n%s\nCacheOptions(...): -want objects, +got objects:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.wantTransform, o.DefaultTransform != nil); diff != "" {
+				t.Errorf("\n%s\nCacheOptions(...): -want transform, +got transform:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -27,6 +27,11 @@ import (
 	"github.com/overlock-network/provider-akash/apis/v1alpha1"
 )
 
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigs,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=namespacedproviderconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigusages,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
 // Setup adds a controller that reconciles ProviderConfigs by accounting for
 // their current usage.
 func Setup(mgr ctrl.Manager, o controller.Options) error {
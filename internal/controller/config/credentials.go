@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+
+	"github.com/overlock-network/provider-akash/apis/v1alpha1"
+	akashclient "github.com/overlock-network/provider-akash/internal/client"
+)
+
+const credentialsController = "credentials"
+
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// SetupCredentials adds a controller that drops the credentials pooled for
+// ProviderConfigs as soon as the Secret holding them changes, so that rotated
+// keys are used without waiting for the credentials to be checked again or
+// restarting the provider.
+func SetupCredentials(mgr ctrl.Manager, o controller.Options) error {
+	name := credentialsController + "/" + v1alpha1.ProviderConfigGroupKind
+
+	r := &credentialsReconciler{
+		log:        o.Logger.WithValues("controller", name),
+		invalidate: akashclient.InvalidateSecret,
+	}
+
+	// Only the metadata of Secrets is watched, so their contents are not kept
+	// in the cache. Resyncs do not change the resource version, so they do
+	// not drop credentials.
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&corev1.Secret{}, builder.OnlyMetadata, builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+type credentialsReconciler struct {
+	log        logging.Logger
+	invalidate func(namespace, name string) int
+}
+
+// Reconcile drops the credentials pooled from a Secret that was created,
+// updated or deleted.
+func (r *credentialsReconciler) Reconcile(_ context.Context, req reconcile.Request) (reconcile.Result, error) {
+	if n := r.invalidate(req.Namespace, req.Name); n > 0 {
+		r.log.Debug("Dropped credentials of changed Secret", "namespace", req.Namespace, "name", req.Name, "providerconfigs", n)
+	}
+	return reconcile.Result{}, nil
+}
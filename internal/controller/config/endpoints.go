@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/overlock-network/provider-akash/apis/v1alpha1"
+	akashclient "github.com/overlock-network/provider-akash/internal/client"
+	chainregistry "github.com/overlock-network/provider-akash/internal/client/chain-registry"
+)
+
+const (
+	errGetPC            = "cannot get ProviderConfig"
+	errDiscover         = "cannot discover endpoints from the chain registry"
+	errUpdateStatus     = "cannot update ProviderConfig status"
+	endpointsController = "endpoints"
+
+	defaultEndpointRefreshInterval = time.Hour
+)
+
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigs/status,verbs=get;update;patch
+
+// SetupEndpoints adds a controller that discovers healthy public endpoints for
+// ProviderConfigs that do not configure their own node.
+func SetupEndpoints(mgr ctrl.Manager, o controller.Options) error {
+	name := endpointsController + "/" + v1alpha1.ProviderConfigGroupKind
+
+	r := &endpointReconciler{
+		kube: mgr.GetClient(),
+		log:  o.Logger.WithValues("controller", name),
+		discover: func(ctx context.Context, registry, net string) (chainregistry.Endpoints, error) {
+			return chainregistry.New(registry).DiscoverEndpoints(ctx, net)
+		},
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.ProviderConfig{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+type endpointReconciler struct {
+	kube     client.Client
+	log      logging.Logger
+	discover func(ctx context.Context, registry, net string) (chainregistry.Endpoints, error)
+}
+
+// Reconcile discovers endpoints for the ProviderConfig and records them in its
+// status, requeueing itself to refresh them periodically.
+func (r *endpointReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	pc := &v1alpha1.ProviderConfig{}
+	if err := r.kube.Get(ctx, req.NamespacedName, pc); err != nil {
+		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetPC)
+	}
+
+	cfg := pc.Spec.Configuration
+	if cfg == nil {
+		cfg = &v1alpha1.AkashConfiguration{}
+	}
+
+	if cfg.Node != nil {
+		if pc.Status.Endpoints == nil {
+			return reconcile.Result{}, nil
+		}
+		pc.Status.Endpoints = nil
+		return reconcile.Result{}, errors.Wrap(r.kube.Status().Update(ctx, pc), errUpdateStatus)
+	}
+
+	interval := defaultEndpointRefreshInterval
+	if cfg.EndpointRefreshInterval != nil {
+		interval = cfg.EndpointRefreshInterval.Duration
+	}
+
+	if e := pc.Status.Endpoints; e != nil && e.LastRefreshTime != nil {
+		if wait := time.Until(e.LastRefreshTime.Add(interval)); wait > 0 {
+			return reconcile.Result{RequeueAfter: wait}, nil
+		}
+	}
+
+	net := akashclient.DefaultNet
+	if cfg.Net != nil {
+		net = *cfg.Net
+	}
+	registry := chainregistry.DefaultHost
+	if cfg.ChainRegistry != nil {
+		registry = *cfg.ChainRegistry
+	}
+
+	endpoints, err := r.discover(ctx, registry, net)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, errDiscover)
+	}
+
+	r.log.Debug("Discovered endpoints", "providerConfig", pc.GetName(), "rpc", endpoints.Rpc, "grpc", endpoints.Grpc)
+
+	now := metav1.Now()
+	pc.Status.Endpoints = &v1alpha1.DiscoveredEndpoints{
+		RPC:             endpoints.Rpc,
+		GRPC:            endpoints.Grpc,
+		LastRefreshTime: &now,
+	}
+	if err := r.kube.Status().Update(ctx, pc); err != nil {
+		return reconcile.Result{}, errors.Wrap(err, errUpdateStatus)
+	}
+
+	return reconcile.Result{RequeueAfter: interval}, nil
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/overlock-network/provider-akash/apis/v1alpha1"
+	akashclient "github.com/overlock-network/provider-akash/internal/client"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+const (
+	errNewClient     = "cannot create Akash client"
+	errListProposals = "cannot list governance proposals"
+
+	reasonPendingProposal event.Reason = "PendingGovernanceProposal"
+
+	governanceController = "governance"
+)
+
+// pendingProposals reports the governance proposals in voting period that
+// affect deployments, per ProviderConfig and kind.
+var pendingProposals = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "akash_governance_pending_proposals",
+	Help: "Governance proposals in voting period that affect deployments or the market.",
+}, []string{"providerconfig", "kind"})
+
+func init() {
+	metrics.Registry.MustRegister(pendingProposals)
+}
+
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigs/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// SetupGovernance adds a controller that watches governance proposals
+// affecting deployments for ProviderConfigs that enable it.
+func SetupGovernance(mgr ctrl.Manager, o controller.Options) error {
+	name := governanceController + "/" + v1alpha1.ProviderConfigGroupKind
+
+	r := &governanceReconciler{
+		kube:   mgr.GetClient(),
+		log:    o.Logger.WithValues("controller", name),
+		record: event.NewAPIRecorder(mgr.GetEventRecorderFor(name)),
+		listProposals: func(ctx context.Context, kube client.Client, pc *v1alpha1.ProviderConfig) ([]akashtypes.Proposal, error) {
+			ak, err := akashclient.NewFromManagedResource(ctx, kube, nil, nil, akashclient.NewProviderConfigInfo(pc))
+			if err != nil {
+				return nil, errors.Wrap(err, errNewClient)
+			}
+			return ak.GetProposalsInVotingPeriod(ctx)
+		},
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.ProviderConfig{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+type governanceReconciler struct {
+	kube          client.Client
+	log           logging.Logger
+	record        event.Recorder
+	listProposals func(ctx context.Context, kube client.Client, pc *v1alpha1.ProviderConfig) ([]akashtypes.Proposal, error)
+}
+
+// Reconcile polls the proposals in voting period, records those affecting
+// deployments in the ProviderConfig status and emits an event for each newly
+// seen one, so operators can prepare before they are executed.
+func (r *governanceReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	pc := &v1alpha1.ProviderConfig{}
+	if err := r.kube.Get(ctx, req.NamespacedName, pc); err != nil {
+		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetPC)
+	}
+
+	var interval *metav1.Duration
+	if pc.Spec.Configuration != nil {
+		interval = pc.Spec.Configuration.GovernanceWatchInterval
+	}
+
+	pendingProposals.DeletePartialMatch(prometheus.Labels{"providerconfig": pc.GetName()})
+
+	if interval == nil || pc.GetDeletionTimestamp() != nil {
+		if len(pc.Status.PendingProposals) == 0 {
+			return reconcile.Result{}, nil
+		}
+		pc.Status.PendingProposals = nil
+		return reconcile.Result{}, errors.Wrap(r.kube.Status().Update(ctx, pc), errUpdateStatus)
+	}
+
+	proposals, err := r.listProposals(ctx, r.kube, pc)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, errListProposals)
+	}
+	pending := akashclient.PendingProposals(proposals)
+
+	seen := make(map[string]bool, len(pc.Status.PendingProposals))
+	for _, p := range pc.Status.PendingProposals {
+		seen[p.ID] = true
+	}
+	for _, p := range pending {
+		pendingProposals.WithLabelValues(pc.GetName(), string(p.Kind)).Inc()
+		if seen[p.ID] {
+			continue
+		}
+		r.log.Debug("Found pending governance proposal", "providerConfig", pc.GetName(), "id", p.ID, "kind", p.Kind)
+		r.record.Event(pc, event.Warning(reasonPendingProposal, errors.New(describeProposal(p))))
+	}
+
+	if len(pending) == 0 {
+		pending = nil
+	}
+	if equality.Semantic.DeepEqual(pending, pc.Status.PendingProposals) {
+		return reconcile.Result{RequeueAfter: interval.Duration}, nil
+	}
+	pc.Status.PendingProposals = pending
+	return reconcile.Result{RequeueAfter: interval.Duration}, errors.Wrap(r.kube.Status().Update(ctx, pc), errUpdateStatus)
+}
+
+// describeProposal explains what a pending proposal may change.
+func describeProposal(p v1alpha1.PendingProposal) string {
+	msg := fmt.Sprintf("%s proposal %s %q is in voting period", p.Kind, p.ID, p.Title)
+	if p.VotingEndTime != nil {
+		msg += " until " + p.VotingEndTime.UTC().Format("2006-01-02 15:04 MST")
+	}
+	if p.UpgradeHeight > 0 {
+		msg += fmt.Sprintf(", upgrading the chain at height %d", p.UpgradeHeight)
+	}
+	return msg
+}
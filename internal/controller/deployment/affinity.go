@@ -0,0 +1,62 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+)
+
+const (
+	errGetColocated      = "cannot get Deployment %s to colocate with"
+	errColocatedUnplaced = "waiting for Deployment %s to colocate with to accept a bid"
+)
+
+// colocatedProvider resolves the provider the Deployment should be colocated
+// with, if any. It is the provider of the last bid accepted for the referenced
+// Deployment.
+func colocatedProvider(ctx context.Context, kube kubeclient.Reader, cr *v1alpha1.Deployment) (string, error) {
+	name := cr.Spec.ForProvider.ColocateWith
+	if name == nil {
+		return "", nil
+	}
+
+	target := &v1alpha1.Deployment{}
+	if err := kube.Get(ctx, types.NamespacedName{Name: *name}, target); err != nil {
+		return "", errors.Wrapf(err, errGetColocated, *name)
+	}
+
+	if p := acceptedProvider(target.Status.AtProvider.Bids); p != "" {
+		return p, nil
+	}
+	return "", errors.Errorf(errColocatedUnplaced, *name)
+}
+
+// acceptedProvider returns the provider of the last accepted bid.
+func acceptedProvider(bids []v1alpha1.BidRecord) string {
+	for i := len(bids) - 1; i >= 0; i-- {
+		if bids[i].Decision == v1alpha1.BidDecisionAccepted {
+			return bids[i].Provider
+		}
+	}
+	return ""
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/billing"
+	"github.com/overlock-network/provider-akash/internal/client"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+const (
+	errListDeployments = "cannot list Deployments"
+	errWriteRecords    = "cannot write billing records"
+
+	// stateKeyPayment prefixes the amount last exported for each lease.
+	stateKeyPayment = "payment."
+)
+
+// paymentBaseline is the amount withdrawn from a lease when it was last
+// exported.
+type paymentBaseline struct {
+	Withdrawn string    `json:"withdrawn"`
+	At        time.Time `json:"at"`
+}
+
+// A BillingExporter periodically exports the escrow payments made to
+// providers for each Deployment to a billing sink.
+type BillingExporter struct {
+	kube      kubeclient.Client
+	log       logging.Logger
+	sink      billing.Sink
+	interval  time.Duration
+	newClient func(ctx context.Context, kube kubeclient.Client, mg resource.Managed, pcInfo client.ProviderConfigInfo) (client.AkashAPI, error)
+}
+
+// SetupBillingExport adds a BillingExporter writing to sink every interval to
+// the manager. Like controllers, it only runs on the elected replica.
+func SetupBillingExport(mgr ctrl.Manager, log logging.Logger, sink billing.Sink, interval time.Duration) error {
+	return mgr.Add(&BillingExporter{
+		kube:      mgr.GetClient(),
+		log:       log.WithValues("exporter", "billing"),
+		sink:      sink,
+		interval:  interval,
+		newClient: newUntrackedClient,
+	})
+}
+
+// Start exports payments every interval until ctx is done.
+func (e *BillingExporter) Start(ctx context.Context) error {
+	t := time.NewTicker(e.interval)
+	defer t.Stop()
+
+	for {
+		if err := e.export(ctx, time.Now()); err != nil {
+			e.log.Info("Cannot export payments", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+	}
+}
+
+// export writes a record of the amount withdrawn by the provider of each lease
+// since the previous export.
+func (e *BillingExporter) export(ctx context.Context, now time.Time) error {
+	l := &v1alpha1.DeploymentList{}
+	if err := e.kube.List(ctx, l); err != nil {
+		return errors.Wrap(err, errListDeployments)
+	}
+
+	store := client.StateStore()
+	var records []billing.Record
+	baselines := map[string]paymentBaseline{}
+
+	for i := range l.Items {
+		cr := &l.Items[i]
+		dseq := externalDseq(cr)
+		if dseq == "" || dseq == cr.GetName() {
+			continue
+		}
+
+		leases, err := e.leases(ctx, cr, dseq)
+		if err != nil {
+			e.log.Debug("Cannot get leases", "deployment", cr.GetName(), "error", err)
+			continue
+		}
+
+		for _, lease := range leases {
+			id := lease.Lease.LeaseId
+			key := fmt.Sprintf("%s%s.%d.%d.%s", stateKeyPayment, dseq, id.Gseq, id.Oseq, id.Provider)
+
+			prev := paymentBaseline{}
+			if v, ok, err := store.Get(ctx, key); err != nil {
+				return err
+			} else if ok {
+				if err := json.Unmarshal([]byte(v), &prev); err != nil {
+					return err
+				}
+			}
+
+			withdrawn := lease.EscrowPayment.Withdrawn
+			amount, ok := paymentDelta(prev.Withdrawn, withdrawn.Amount)
+			if !ok {
+				continue
+			}
+
+			records = append(records, billing.Record{
+				Deployment:  cr.GetName(),
+				Dseq:        dseq,
+				Provider:    id.Provider,
+				PeriodStart: prev.At,
+				PeriodEnd:   now,
+				Amount:      amount,
+				Denom:       withdrawn.Denom,
+			})
+			baselines[key] = paymentBaseline{Withdrawn: withdrawn.Amount, At: now}
+		}
+	}
+
+	if len(records) == 0 {
+		return nil
+	}
+	if err := e.sink.Write(ctx, records); err != nil {
+		return errors.Wrap(err, errWriteRecords)
+	}
+
+	for key, b := range baselines {
+		v, err := json.Marshal(b)
+		if err != nil {
+			return err
+		}
+		if err := store.Set(ctx, key, string(v)); err != nil {
+			return err
+		}
+	}
+	e.log.Debug("Exported payments", "records", len(records))
+	return nil
+}
+
+func (e *BillingExporter) leases(ctx context.Context, cr *v1alpha1.Deployment, dseq string) ([]akashtypes.Lease, error) {
+	ak, err := connect(ctx, e.kube, cr, e.newClient)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := deploymentOwner(ak, cr)
+	if err != nil {
+		return nil, err
+	}
+	return ak.GetLeases(ctx, dseq, owner)
+}
+
+// paymentDelta returns the exact amount withdrawn since prev, both being
+// decimal amounts, and whether anything was withdrawn. An empty prev means
+// nothing was exported for the lease yet.
+func paymentDelta(prev, cur string) (string, bool) {
+	c, ok := new(big.Rat).SetString(cur)
+	if !ok {
+		return "", false
+	}
+	p := new(big.Rat)
+	if prev != "" {
+		if _, ok := p.SetString(prev); !ok {
+			return "", false
+		}
+	}
+
+	d := new(big.Rat).Sub(c, p)
+	if d.Sign() <= 0 {
+		return "", false
+	}
+
+	s := d.FloatString(18)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, "."), true
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+)
+
+const (
+	errGetConnectionSecret    = "cannot get connection secret"
+	errUpdateConnectionSecret = "cannot update connection secret"
+	errGetCertificates        = "cannot get valid certificates"
+
+	reasonCertificateRemoved event.Reason = "CertificateRemoved"
+
+	certificatesController = "certificates"
+
+	// certificateCheckInterval is how often connection secrets are checked
+	// for certificates revoked on chain.
+	certificateCheckInterval = time.Hour
+)
+
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=deployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;update
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// SetupCertificates adds a controller that removes expired and revoked client
+// certificates, together with their keys, from the connection secrets of
+// Deployments so consumers of the secrets cannot keep using them.
+func SetupCertificates(mgr ctrl.Manager, o controller.Options) error {
+	name := certificatesController + "/" + v1alpha1.DeploymentGroupKind
+
+	r := &certificatesReconciler{
+		kube:      mgr.GetClient(),
+		log:       o.Logger.WithValues("controller", name),
+		record:    event.NewAPIRecorder(mgr.GetEventRecorderFor(name)),
+		newClient: newUntrackedClient,
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Deployment{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+type certificatesReconciler struct {
+	kube      kubeclient.Client
+	log       logging.Logger
+	record    event.Recorder
+	newClient func(ctx context.Context, kube kubeclient.Client, mg resource.Managed, pcInfo client.ProviderConfigInfo) (client.AkashAPI, error)
+}
+
+// Reconcile removes the certificates of the connection secret of a Deployment
+// that expired or are no longer valid on chain.
+func (r *certificatesReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	cr := &v1alpha1.Deployment{}
+	if err := r.kube.Get(ctx, req.NamespacedName, cr); err != nil {
+		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetDeployment)
+	}
+
+	ref := cr.Spec.WriteConnectionSecretToReference
+	if ref == nil || cr.GetDeletionTimestamp() != nil {
+		return reconcile.Result{}, nil
+	}
+
+	s := &corev1.Secret{}
+	if err := r.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetConnectionSecret)
+	}
+
+	certs := parseCertificates(s.Data)
+	if len(certs) == 0 {
+		return reconcile.Result{}, nil
+	}
+
+	ak, err := connect(ctx, r.kube, cr, r.newClient)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	owner, err := ak.AccountAddress()
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	published, err := ak.GetCertificates(ctx, owner)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, errGetCertificates)
+	}
+	valid := make(map[string]bool, len(published.Certificates))
+	for _, c := range published.Certificates {
+		valid[c.Serial] = true
+	}
+
+	now := time.Now()
+	stale := staleCertificates(certs, valid, now)
+	if len(stale) == 0 {
+		return reconcile.Result{RequeueAfter: nextCertificateCheck(certs, now)}, nil
+	}
+
+	keys := make([]string, 0, len(stale))
+	for key := range stale {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		delete(s.Data, key)
+		delete(s.Data, privateKeyOf(key))
+	}
+	if err := r.kube.Update(ctx, s); err != nil {
+		return reconcile.Result{}, errors.Wrap(err, errUpdateConnectionSecret)
+	}
+
+	for _, key := range keys {
+		r.log.Debug("Removed certificate from connection secret", "deployment", cr.GetName(), "key", key, "reason", stale[key])
+		r.record.Event(cr, event.Warning(reasonCertificateRemoved, errors.Errorf("removed %s certificate %s from connection secret %s/%s", stale[key], key, s.GetNamespace(), s.GetName())))
+	}
+
+	return reconcile.Result{RequeueAfter: nextCertificateCheck(parseCertificates(s.Data), now)}, nil
+}
+
+// parseCertificates returns the X.509 certificates held by the connection
+// secret, keyed by the secret key holding them.
+func parseCertificates(data map[string][]byte) map[string]*x509.Certificate {
+	certs := map[string]*x509.Certificate{}
+	for key, value := range data {
+		block, _ := pem.Decode(value)
+		if block == nil || block.Type != "CERTIFICATE" {
+			continue
+		}
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			continue
+		}
+		certs[key] = c
+	}
+	return certs
+}
+
+// staleCertificates returns why each of the certificates that expired or whose
+// serial is no longer valid on chain is stale, keyed by its secret key.
+func staleCertificates(certs map[string]*x509.Certificate, valid map[string]bool, now time.Time) map[string]string {
+	stale := map[string]string{}
+	for key, c := range certs {
+		switch {
+		case now.After(c.NotAfter):
+			stale[key] = "expired"
+		case !valid[c.SerialNumber.String()]:
+			stale[key] = "revoked"
+		}
+	}
+	return stale
+}
+
+// nextCertificateCheck returns when the certificates should be checked next:
+// when the first of them expires, but at least every
+// certificateCheckInterval to catch revocations.
+func nextCertificateCheck(certs map[string]*x509.Certificate, now time.Time) time.Duration {
+	next := certificateCheckInterval
+	for _, c := range certs {
+		if d := c.NotAfter.Sub(now) + time.Second; d > 0 && d < next {
+			next = d
+		}
+	}
+	return next
+}
+
+// privateKeyOf returns the secret key conventionally holding the private key
+// of the certificate at key, e.g. tls.key for tls.crt.
+func privateKeyOf(key string) string {
+	for _, suffix := range []string{".crt", ".pem", ".cert"} {
+		if strings.HasSuffix(key, suffix) {
+			return strings.TrimSuffix(key, suffix) + ".key"
+		}
+	}
+	return key + ".key"
+}
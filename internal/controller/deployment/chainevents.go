@@ -0,0 +1,188 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlevent "sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	apisv1alpha1 "github.com/overlock-network/provider-akash/apis/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+	"github.com/overlock-network/provider-akash/internal/client/events"
+)
+
+const (
+	errIndexDseq = "cannot index Deployments by dseq"
+
+	// dseqIndex indexes Deployments by their dseq.
+	dseqIndex = "metadata.annotations.crossplane.io/external-name"
+
+	chainEventsResync     = time.Minute
+	chainEventsBackoff    = 5 * time.Second
+	chainEventsMaxBackoff = 5 * time.Minute
+)
+
+// chainEvents carries the Deployments chain events concern to the Deployment
+// controller, which reconciles them right away.
+var chainEvents = make(chan ctrlevent.GenericEvent)
+
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=deployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigs,verbs=get;list;watch
+
+// A ChainEventWatcher subscribes to the deployment, lease and bid events of
+// the node of every ProviderConfig and enqueues the Deployments they concern,
+// so that their state changes are observed within seconds instead of at the
+// next poll. Events are matched by dseq only, so a Deployment of another
+// owner with the same dseq is merely reconciled once more.
+type ChainEventWatcher struct {
+	kube      kubeclient.Client
+	log       logging.Logger
+	subscribe func(ctx context.Context, node string, handle func(events.Event)) error
+	enqueue   chan<- ctrlevent.GenericEvent
+	resync    time.Duration
+	backoff   time.Duration
+}
+
+// SetupChainEvents adds a ChainEventWatcher to the manager. Like
+// controllers, it only runs on the elected replica.
+func SetupChainEvents(mgr ctrl.Manager, log logging.Logger) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &v1alpha1.Deployment{}, dseqIndex, indexDseq); err != nil {
+		return errors.Wrap(err, errIndexDseq)
+	}
+	return mgr.Add(&ChainEventWatcher{
+		kube:      mgr.GetClient(),
+		log:       log.WithValues("watcher", "chain-events"),
+		subscribe: events.Subscribe,
+		enqueue:   chainEvents,
+		resync:    chainEventsResync,
+		backoff:   chainEventsBackoff,
+	})
+}
+
+// Start watches the nodes of the ProviderConfigs, picking up added and
+// removed nodes every resync, until ctx is done.
+func (w *ChainEventWatcher) Start(ctx context.Context) error {
+	watches := map[string]context.CancelFunc{}
+	defer func() {
+		for _, cancel := range watches {
+			cancel()
+		}
+	}()
+
+	t := time.NewTicker(w.resync)
+	defer t.Stop()
+
+	for {
+		nodes, err := w.nodes(ctx)
+		if err != nil {
+			w.log.Info("Cannot list ProviderConfigs", "error", err)
+		}
+		for node, cancel := range watches {
+			if err == nil && !nodes[node] {
+				cancel()
+				delete(watches, node)
+			}
+		}
+		for node := range nodes {
+			if _, ok := watches[node]; !ok {
+				wctx, cancel := context.WithCancel(ctx)
+				watches[node] = cancel
+				go w.watch(wctx, node)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+	}
+}
+
+// nodes returns the nodes of every ProviderConfig.
+func (w *ChainEventWatcher) nodes(ctx context.Context) (map[string]bool, error) {
+	l := &apisv1alpha1.ProviderConfigList{}
+	if err := w.kube.List(ctx, l); err != nil {
+		return nil, err
+	}
+	nodes := make(map[string]bool, len(l.Items))
+	for i := range l.Items {
+		nodes[client.Node(client.NewProviderConfigInfo(&l.Items[i]))] = true
+	}
+	return nodes, nil
+}
+
+// watch subscribes to the events of node until ctx is done, subscribing
+// again with exponential backoff whenever the subscription fails.
+func (w *ChainEventWatcher) watch(ctx context.Context, node string) {
+	log := w.log.WithValues("node", node)
+	backoff := w.backoff
+	for {
+		started := time.Now()
+		err := w.subscribe(ctx, node, func(e events.Event) { w.handle(ctx, log, e) })
+		if ctx.Err() != nil {
+			return
+		}
+		log.Info("Chain event subscription failed", "error", err, "retry-after", backoff)
+
+		// A subscription that lasted resets the backoff.
+		if time.Since(started) > chainEventsMaxBackoff {
+			backoff = w.backoff
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, chainEventsMaxBackoff)
+	}
+}
+
+// handle enqueues the Deployments an event concerns.
+func (w *ChainEventWatcher) handle(ctx context.Context, log logging.Logger, e events.Event) {
+	l := &v1alpha1.DeploymentList{}
+	if err := w.kube.List(ctx, l, kubeclient.MatchingFields{dseqIndex: e.Dseq}); err != nil {
+		log.Info("Cannot list Deployments", "dseq", e.Dseq, "error", err)
+		return
+	}
+	for i := range l.Items {
+		cr := &l.Items[i]
+		log.Debug("Reconciling Deployment on chain event", "deployment", cr.GetName(), "event", e.Type, "dseq", e.Dseq, "height", e.Height)
+		select {
+		case w.enqueue <- ctrlevent.GenericEvent{Object: cr}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// indexDseq returns the dseq of a Deployment that was created on chain.
+func indexDseq(o kubeclient.Object) []string {
+	dseq := externalDseq(o)
+	if dseq == "" || dseq == o.GetName() {
+		return nil
+	}
+	return []string{dseq}
+}
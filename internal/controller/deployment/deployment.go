@@ -18,16 +18,24 @@ package deployment
 
 import (
 	"context"
-	"fmt"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strconv"
 
 	"github.com/pkg/errors"
-	"k8s.io/apimachinery/pkg/types"
+	corev1 "k8s.io/api/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/connection"
 	"github.com/crossplane/crossplane-runtime/pkg/controller"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
@@ -36,6 +44,7 @@ import (
 	apisv1alpha1 "github.com/overlock-network/provider-akash/apis/v1alpha1"
 	client "github.com/overlock-network/provider-akash/internal/client"
 	"github.com/overlock-network/provider-akash/internal/features"
+	"github.com/overlock-network/provider-akash/internal/tracing"
 )
 
 const (
@@ -44,22 +53,41 @@ const (
 	errGetPC         = "cannot get ProviderConfig"
 	errGetCreds      = "cannot get credentials"
 
-	errNewClient = "cannot create new Service"
+	errNewClient          = "cannot create new Service"
+	errGetChainDeployment = "cannot query deployment"
+	errSDLChecksum        = "SDL checksum %s does not match the expected sdlChecksum %s"
+	errCreateDeployment   = "cannot create deployment"
+	errUpdateDeployment   = "cannot update deployment"
+
+	errExternalNameRestored = "external name annotation was missing and has been restored to dseq %s recorded in status"
+
+	reasonExternalNameRestored event.Reason = "ExternalNameRestored"
+	reasonDeploymentUpdated    event.Reason = "DeploymentUpdated"
 )
 
 type DeploymentService struct {
-	client *client.AkashClient
+	client client.AkashAPI
 }
 
 // newDeploymentService creates DeploymentService with AkashClient created from managed resource
 var newDeploymentService = func(ctx context.Context, kubeClient kubeclient.Client, usage resource.Tracker, mg resource.Managed, pcInfo client.ProviderConfigInfo) (*DeploymentService, error) {
-	c, err := client.NewFromManagedResource(ctx, kubeClient, usage, mg, pcInfo)
+	c, err := client.NewAPIFromManagedResource(ctx, kubeClient, usage, mg, pcInfo)
 	if err != nil {
 		return nil, err
 	}
 	return &DeploymentService{client: c}, nil
 }
 
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=deployments,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=deployments/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigusages,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=storeconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps;secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=create;update;patch;delete
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=failoverrecords,verbs=create
+// +kubebuilder:rbac:groups="",resources=events,verbs=list;create;patch
+
 // Setup adds a controller that reconciles Deployment managed resources.
 func Setup(mgr ctrl.Manager, o controller.Options) error {
 	name := managed.ControllerName(v1alpha1.DeploymentGroupKind)
@@ -69,22 +97,43 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
 	}
 
-	r := managed.NewReconciler(mgr,
-		resource.ManagedKind(v1alpha1.DeploymentGroupVersionKind),
-		managed.WithExternalConnecter(&connector{
+	log := o.Logger.WithValues("controller", name)
+	recorder := newTimeline(event.NewAPIRecorder(mgr.GetEventRecorderFor(name)))
+
+	opts := []managed.ReconcilerOption{
+		managed.WithExternalConnecter(tracing.NewConnecter(v1alpha1.DeploymentKind, &connector{
 			kubeClient:                mgr.GetClient(),
+			reader:                    mgr.GetAPIReader(),
 			usage:                     resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
-			createDeploymentServiceFn: newDeploymentService}),
-		managed.WithLogger(o.Logger.WithValues("controller", name)),
+			recorder:                  recorder,
+			managementPolicies:        o.Features.Enabled(features.EnableAlphaManagementPolicies),
+			createDeploymentServiceFn: newDeploymentService})),
+		managed.WithLogger(log),
 		managed.WithPollInterval(o.PollInterval),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
-		managed.WithConnectionPublishers(cps...))
+		managed.WithPollIntervalHook(pollIntervalHook),
+		managed.WithRecorder(recorder),
+		managed.WithConnectionPublishers(cps...),
+		managed.WithFinalizer(timelineFinalizer{resource.NewAPIFinalizer(mgr.GetClient(), managed.FinalizerName)}),
+	}
+	if o.Features.Enabled(features.EnableAlphaManagementPolicies) {
+		opts = append(opts, managed.WithManagementPolicies())
+	}
+
+	r := managed.NewReconciler(mgr, resource.ManagedKind(v1alpha1.DeploymentGroupVersionKind), opts...)
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &v1alpha1.Deployment{}, sdlRefIndex, indexSDLRef); err != nil {
+		return errors.Wrap(err, errIndexSDLRef)
+	}
 
+	// ConfigMaps and Secrets holding SDLs are only watched for changes, so
+	// their contents are not kept in the cache.
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		WithOptions(o.ForControllerRuntime()).
-		WithEventFilter(resource.DesiredStateChanged()).
-		For(&v1alpha1.Deployment{}).
+		For(&v1alpha1.Deployment{}, builder.WithPredicates(resource.DesiredStateChanged())).
+		Watches(&corev1.ConfigMap{}, enqueueSDLReferrers(mgr.GetClient(), log, v1alpha1.SDLSourceConfigMap), builder.OnlyMetadata).
+		Watches(&corev1.Secret{}, enqueueSDLReferrers(mgr.GetClient(), log, v1alpha1.SDLSourceSecret), builder.OnlyMetadata).
+		WatchesRawSource(&source.Channel{Source: chainEvents}, &handler.EnqueueRequestForObject{}).
 		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
 }
 
@@ -92,7 +141,10 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 // is called.
 type connector struct {
 	kubeClient                kubeclient.Client
+	reader                    kubeclient.Reader
 	usage                     resource.Tracker
+	recorder                  event.Recorder
+	managementPolicies        bool
 	createDeploymentServiceFn func(ctx context.Context, kubeClient kubeclient.Client, usage resource.Tracker, mg resource.Managed, pcInfo client.ProviderConfigInfo) (*DeploymentService, error)
 }
 
@@ -104,25 +156,69 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	}
 
 	// Get the ProviderConfig referenced by the managed resource
-	pc := &apisv1alpha1.ProviderConfig{}
-	if err := c.kubeClient.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+	pcInfo, err := client.GetProviderConfig(ctx, c.kubeClient, cr)
+	if err != nil {
 		return nil, errors.Wrap(err, errGetPC)
 	}
 
-	// Create ProviderConfig info struct directly using ProviderConfig types
-	pcInfo := client.ProviderConfigInfo{
-		Source:              pc.Spec.Credentials.Source,
-		CredentialSelectors: pc.Spec.Credentials.CommonCredentialSelectors,
-		Configuration:       pc.Spec.Configuration,
-	}
-
 	// Create service with AkashClient - this handles everything internally
 	svc, err := c.createDeploymentServiceFn(ctx, c.kubeClient, c.usage, mg, pcInfo)
 	if err != nil {
 		return nil, errors.Wrap(err, errNewClient)
 	}
+	if svc.client != nil {
+		svc.client.SetTransactionOptions(transactionOptions(cr))
+	}
+
+	recorder := c.recorder
+	if recorder == nil {
+		recorder = event.NewNopRecorder()
+	}
+
+	return &external{service: svc, kube: c.kubeClient, reader: c.reader, recorder: recorder, managementPolicies: c.managementPolicies}, nil
+}
+
+// newUntrackedClient creates a client for controllers that act on behalf of a
+// Deployment without tracking its usage of the ProviderConfig.
+func newUntrackedClient(ctx context.Context, kube kubeclient.Client, mg resource.Managed, pcInfo client.ProviderConfigInfo) (client.AkashAPI, error) {
+	return client.NewAPIFromManagedResource(ctx, kube, nil, mg, pcInfo)
+}
+
+// connect returns a client for the ProviderConfig referenced by cr.
+func connect(ctx context.Context, kube kubeclient.Client, cr *v1alpha1.Deployment, newClient func(ctx context.Context, kube kubeclient.Client, mg resource.Managed, pcInfo client.ProviderConfigInfo) (client.AkashAPI, error)) (client.AkashAPI, error) {
+	pcInfo, err := client.GetProviderConfig(ctx, kube, cr)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
 
-	return &external{service: svc}, nil
+	ak, err := newClient(ctx, kube, cr, pcInfo)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+	ak.SetTransactionOptions(transactionOptions(cr))
+	return ak, nil
+}
+
+// transactionOptions returns the transaction overrides of cr.
+func transactionOptions(cr *v1alpha1.Deployment) client.TransactionOptions {
+	t := cr.Spec.ForProvider.Transaction
+	if t == nil {
+		return client.TransactionOptions{}
+	}
+
+	o := client.TransactionOptions{}
+	if t.Memo != nil {
+		o.Memo = *t.Memo
+	}
+	if t.GasAdjustment != nil {
+		// The pattern of the field guarantees a valid number.
+		adjustment, _ := strconv.ParseFloat(*t.GasAdjustment, 32)
+		o.GasAdjustment = float32(adjustment)
+	}
+	if t.FeeCap != nil {
+		o.Fees = *t.FeeCap
+	}
+	return o
 }
 
 // An ExternalClient observes, then either creates, updates, or deletes an
@@ -131,6 +227,13 @@ type external struct {
 	// A 'client' used to connect to the external resource API. In practice this
 	// would be something like an AWS SDK client.
 	service *DeploymentService
+
+	kube     kubeclient.Client
+	reader   kubeclient.Reader
+	recorder event.Recorder
+
+	// managementPolicies is whether management policies are honored.
+	managementPolicies bool
 }
 
 func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
@@ -139,41 +242,228 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		return managed.ExternalObservation{}, errors.New(errNotDeployment)
 	}
 
-	// These fmt statements should be removed in the real implementation.
-	fmt.Printf("Observing: %+v", cr)
-	deployment, err := c.service.client.GetDeployment("test", "test")
-	fmt.Println(deployment)
+	restored := restoreExternalName(cr)
+	if restored {
+		c.recorder.Event(cr, event.Warning(reasonExternalNameRestored, errors.Errorf(errExternalNameRestored, cr.Status.AtProvider.Dseq)))
+	}
+
+	// Until it is created, the external name is the name of the resource.
+	dseq := externalDseq(cr)
+	if dseq == "" || dseq == cr.GetName() {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	ak := c.service.client
+	owner, account, err := deploymentAccount(ak, cr)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	deployment, err := ak.ReadDeployment(ctx, dseq, owner)
+	if client.IsDeploymentNotFound(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
 	if err != nil {
-		fmt.Println(err)
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetChainDeployment)
+	}
+	cr.Status.AtProvider.Dseq = dseq
+	cr.Status.AtProvider.Owner, _, _ = parseExternalName(meta.GetExternalName(cr))
+	cr.Status.AtProvider.State = v1alpha1.DeploymentStateFromChain(deployment.DeploymentInfo.State)
+	owned := owner == account
+	if !owned {
+		cr.SetConditions(v1alpha1.NotOwner(dseq, owner, account))
+	}
+
+	// Imported deployments converge to a fully specified spec.
+	lateInitialized := restored
+	if mayAct(c.managementPolicies, cr, xpv1.ManagementActionLateInitialize) {
+		lateInitialized = lateInitialize(cr, deployment) || lateInitialized
+	}
+
+	// State read from the indexer is never acted upon, nor are deployments
+	// whose management policies do not allow replacing them.
+	recreate := recreateOnFailure(cr) && !ak.ObservedViaFallback() && mayAct(c.managementPolicies, cr, xpv1.ManagementActionCreate, xpv1.ManagementActionDelete)
+
+	// Closed deployments cannot be updated anymore.
+	if cr.Status.AtProvider.State == v1alpha1.DeploymentStateClosed {
+		// Deleted once closed.
+		if meta.WasDeleted(cr) {
+			return managed.ExternalObservation{ResourceExists: false}, nil
+		}
+		if reason := closedOnChain(cr, dseq); recreate && reason != "" {
+			return managed.ExternalObservation{ResourceExists: false}, c.recreate(ctx, cr, dseq, owner, reason)
+		}
+		cr.Status.AtProvider.Phase = v1alpha1.DeploymentPhaseClosed
+		cr.SetConditions(xpv1.Unavailable())
+		return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true, ResourceLateInitialized: lateInitialized}, nil
 	}
+
+	leases, err := ak.ReadActiveLeases(ctx, dseq, owner)
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetLeases)
+	}
+	if len(leases) == 0 && recreate {
+		all, err := ak.GetLeases(ctx, dseq, owner)
+		if err != nil {
+			return managed.ExternalObservation{}, errors.Wrap(err, errGetLeases)
+		}
+		if reason := lostLease(all); reason != "" {
+			return managed.ExternalObservation{ResourceExists: false}, c.recreate(ctx, cr, dseq, owner, reason)
+		}
+	}
+	provider := ""
+	if len(leases) > 0 {
+		provider = leases[0].Lease.LeaseId.Provider
+		cr.Status.AtProvider.Phase = v1alpha1.DeploymentPhaseLeaseActive
+	} else {
+		bids, err := ak.QueryBids(ctx, orderSeqs(owner, dseq))
+		if err != nil {
+			return managed.ExternalObservation{}, errors.Wrap(err, errQueryBids)
+		}
+		cr.Status.AtProvider.Phase = bidsPhase(len(bids))
+	}
+
+	// The manifest of a deployment of another owner is theirs to send, and
+	// the gateway of its provider only serves the certificates of its owner.
+	drifted, delivered := false, provider != ""
+	if owned {
+		drifted, delivered, err = c.manifestState(ctx, cr, deployment.DeploymentInfo.Version, provider)
+		if err != nil {
+			return managed.ExternalObservation{}, err
+		}
+	}
+
+	details := managed.ConnectionDetails{}
+	if owned && provider != "" && delivered {
+		status, err := leaseStatus(ctx, ak, leases)
+		if errors.Is(err, client.ErrGatewayUnreachable) {
+			cr.Status.AtProvider.GatewayFailures++
+			cr.SetConditions(v1alpha1.GatewayUnreachable(cr.Status.AtProvider.GatewayFailures, err.Error()))
+			if failoverDue(cr) && mayAct(c.managementPolicies, cr, xpv1.ManagementActionCreate, xpv1.ManagementActionDelete) {
+				return managed.ExternalObservation{ResourceExists: false}, c.failover(ctx, cr, dseq, owner, provider, err.Error())
+			}
+		}
+		if err != nil {
+			return managed.ExternalObservation{}, err
+		}
+		if cr.Status.AtProvider.GatewayFailures > 0 {
+			cr.Status.AtProvider.GatewayFailures = 0
+			cr.SetConditions(v1alpha1.GatewayReachable())
+		}
+		details = publishServices(cr, status)
+	}
+
+	checks := cr.Spec.ForProvider.HealthChecks
+	switch {
+	case provider == "" || !delivered:
+		cr.SetConditions(xpv1.Creating())
+	case len(checks) > 0:
+		cr.SetConditions(healthCondition(checks, cr.Status.AtProvider.ServiceHealth))
+	default:
+		cr.SetConditions(xpv1.Available())
+	}
+
 	return managed.ExternalObservation{
-		// Return false when the external resource does not exist. This lets
-		// the managed resource reconciler know that it needs to call Create to
-		// (re)create the resource, or that it has successfully been deleted.
-		ResourceExists: false,
+		ResourceExists: true,
+
+		// The deployment is driven towards an active lease running the
+		// desired manifest by Update. State read from the indexer is never
+		// acted upon, nor are deployments of other owners.
+		ResourceUpToDate: ak.ObservedViaFallback() || !owned || (provider != "" && delivered && !drifted),
 
-		// Return false when the external resource exists, but it not up to date
-		// with the desired managed resource state. This lets the managed
-		// resource reconciler know that it needs to call Update.
-		ResourceUpToDate: false,
+		// Persist the external name when it was restored, and the fields of
+		// the spec initialized from the chain.
+		ResourceLateInitialized: lateInitialized,
 
 		// Return any details that may be required to connect to the external
 		// resource. These will be stored as the connection secret.
-		ConnectionDetails: managed.ConnectionDetails{},
+		ConnectionDetails: details,
 	}, nil
 }
 
+// manifestState reports whether the manifest of the deployment, identified by
+// its version on chain, drifted from the SDL of cr, and whether the desired
+// manifest was delivered to provider. Deployments that are only observed need
+// no SDL, their manifest is whatever they run.
+func (c *external) manifestState(ctx context.Context, cr *v1alpha1.Deployment, onChain, provider string) (bool, bool, error) {
+	if !hasSDL(cr) && !mayAct(c.managementPolicies, cr, xpv1.ManagementActionUpdate) {
+		return false, provider != "", nil
+	}
+	sdl, err := desiredSDL(ctx, c.kube, cr)
+	if err != nil {
+		return false, false, err
+	}
+	drifted, err := manifestDrifted(sdl, onChain)
+	if err != nil {
+		return false, false, err
+	}
+	version, err := manifestVersion(sdl)
+	if err != nil {
+		return false, false, err
+	}
+	return drifted, manifestDelivered(cr.Status.AtProvider.ManifestDelivery, provider, version), nil
+}
+
 func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
 	cr, ok := mg.(*v1alpha1.Deployment)
 	if !ok {
 		return managed.ExternalCreation{}, errors.New(errNotDeployment)
 	}
 
-	fmt.Printf("Creating: %+v", cr)
-	_, err := c.service.client.CreateDeployment("test")
+	source, err := resolveSDL(ctx, c.kube, cr)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	if err := verifySDLChecksum(source, cr.Spec.ForProvider.SDLChecksum); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	// Bid selection prefers the provider of the Deployment to colocate with,
+	// so there is no point in creating this one before that one is placed.
+	if _, err := colocatedProvider(ctx, c.kube, cr); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	sdl, err := renderSDL(cr, source)
 	if err != nil {
 		return managed.ExternalCreation{}, err
 	}
+	if err := checkResources(sdl); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	path, err := writeSDL(sdl)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	defer os.Remove(path) //nolint:errcheck // Best effort, the file is temporary.
+
+	depositor := ""
+	if cr.Spec.ForProvider.Depositor != nil {
+		depositor = *cr.Spec.ForProvider.Depositor
+	}
+	seqs, err := c.service.client.CreateDeployment(ctx, path, client.NewDeposit(cr.Spec.ForProvider.Deposit), depositor)
+	switch {
+	case errors.Is(err, client.ErrAccountMismatch):
+		cr.SetConditions(v1alpha1.AccountMismatch(err.Error()))
+	case client.IsAccountNotFound(err):
+		cr.SetConditions(v1alpha1.AccountUninitialized(c.service.client.Configuration().AccountAddress))
+	case errors.Is(err, client.ErrMainnetSpendNotConfirmed):
+		cr.SetConditions(v1alpha1.MainnetSpendNotConfirmed(c.service.client.Configuration().ChainId))
+	case errors.Is(err, client.ErrSimulationFailed):
+		cr.SetConditions(v1alpha1.SimulationFailed(err.Error()))
+	}
+	if err != nil {
+		c.recorder.Event(cr, event.Warning(reasonTransactionFailed, errors.Wrap(err, errCreateDeployment)))
+		return managed.ExternalCreation{}, err
+	}
+	cr.SetConditions(v1alpha1.AccountInitialized(), v1alpha1.SimulationSucceeded())
+	if client.IsMainnet(c.service.client.Configuration().ChainId) {
+		cr.SetConditions(v1alpha1.MainnetSpendConfirmed())
+	}
+	if seqs.Dseq != "" {
+		meta.SetExternalName(cr, seqs.Dseq)
+		cr.Status.AtProvider.Dseq = seqs.Dseq
+		cr.Status.AtProvider.Phase = v1alpha1.DeploymentPhasePending
+		c.recorder.Event(cr, event.Normal(reasonDeploymentCreated, "Created deployment "+seqs.Dseq))
+	}
 	return managed.ExternalCreation{
 		// Optionally return any details that may be required to connect to the
 		// external resource. These will be stored as the connection secret.
@@ -187,7 +477,68 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalUpdate{}, errors.New(errNotDeployment)
 	}
 
-	fmt.Printf("Updating: %+v", cr)
+	// Only the owner of a deployment may update it and lease its bids.
+	ak := c.service.client
+	dseq := externalDseq(cr)
+	owner, account, err := deploymentAccount(ak, cr)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	if owner != account {
+		cr.SetConditions(v1alpha1.NotOwner(dseq, owner, account))
+		return managed.ExternalUpdate{}, errors.Errorf(errUpdateUnowned, dseq, owner, account)
+	}
+
+	sdl, err := desiredSDL(ctx, c.kube, cr)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	if err := checkResources(sdl); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	version, err := manifestVersion(sdl)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	path, err := writeSDL(sdl)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	defer os.Remove(path) //nolint:errcheck // Best effort, the file is temporary.
+
+	deployment, err := ak.GetDeployment(ctx, dseq, owner)
+	if err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errGetChainDeployment)
+	}
+	drifted, err := manifestDrifted(sdl, deployment.DeploymentInfo.Version)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	if drifted {
+		if err := ak.UpdateDeployment(ctx, dseq, path); err != nil {
+			c.recorder.Event(cr, event.Warning(reasonTransactionFailed, errors.Wrap(err, errUpdateDeployment)))
+			return managed.ExternalUpdate{}, err
+		}
+		c.recorder.Event(cr, event.Normal(reasonDeploymentUpdated, "Updated deployment "+dseq+" to manifest version "+version))
+	}
+
+	// Bids are selected and leased once, then the manifest is sent to the
+	// leasing provider whenever it did not receive the desired version yet.
+	leases, err := ak.GetActiveLeases(ctx, dseq, owner)
+	if err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errGetLeases)
+	}
+	provider := ""
+	if len(leases) > 0 {
+		provider = leases[0].Lease.LeaseId.Provider
+	} else if provider, err = c.leaseBid(ctx, cr, orderSeqs(owner, dseq)); err != nil || provider == "" {
+		return managed.ExternalUpdate{}, err
+	}
+	if !manifestDelivered(cr.Status.AtProvider.ManifestDelivery, provider, version) {
+		if err := c.deliverManifest(ctx, cr, dseq, provider, path, version); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
 
 	return managed.ExternalUpdate{
 		// Optionally return any details that may be required to connect to the
@@ -202,7 +553,50 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 		return errors.New(errNotDeployment)
 	}
 
-	fmt.Printf("Deleting: %+v", cr)
+	// Deployments that were never created or are closed already are gone.
+	dseq := externalDseq(cr)
+	if dseq == "" || dseq == cr.GetName() || cr.Status.AtProvider.State == v1alpha1.DeploymentStateClosed {
+		return nil
+	}
 
+	ak := c.service.client
+	owner, err := deploymentOwner(ak, cr)
+	if err != nil {
+		return err
+	}
+	if err := closable(ak, cr, dseq, owner); err != nil {
+		return err
+	}
+	cr.SetConditions(xpv1.Deleting())
+	return errors.Wrap(ak.DeleteDeployment(ctx, dseq, owner), errCloseDeleted)
+}
+
+// verifySDLChecksum returns an error unless the SHA-256 digest of sdl matches
+// the expected checksum. A nil checksum skips the verification.
+func verifySDLChecksum(sdl string, checksum *string) error {
+	if checksum == nil {
+		return nil
+	}
+	sum := sha256.Sum256([]byte(sdl))
+	if got := hex.EncodeToString(sum[:]); got != *checksum {
+		return errors.Errorf(errSDLChecksum, got, *checksum)
+	}
 	return nil
 }
+
+// restoreExternalName restores the external name of cr from the dseq, and
+// owner of an imported deployment, recorded in its status when the annotation
+// was deleted, in which case it is either missing or was reset to the name of
+// the resource. It reports whether the external name was restored.
+func restoreExternalName(cr *v1alpha1.Deployment) bool {
+	dseq := cr.Status.AtProvider.Dseq
+	if owner := cr.Status.AtProvider.Owner; owner != "" && dseq != "" {
+		dseq = owner + "/" + dseq
+	}
+	name := meta.GetExternalName(cr)
+	if dseq == "" || name == dseq || (name != "" && name != cr.GetName()) {
+		return false
+	}
+	meta.SetExternalName(cr, dseq)
+	return true
+}
@@ -18,13 +18,50 @@ package deployment
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	xpfake "github.com/crossplane/crossplane-runtime/pkg/resource/fake"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	apisv1alpha1 "github.com/overlock-network/provider-akash/apis/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+	akashfake "github.com/overlock-network/provider-akash/internal/client/fake"
+	gateway "github.com/overlock-network/provider-akash/internal/client/provider-gateway"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+	akashsdl "github.com/overlock-network/provider-akash/internal/sdl"
 )
 
 // Unlike many Kubernetes projects Crossplane does not use third party testing
@@ -36,8 +73,26 @@ import (
 // https://github.com/crossplane/crossplane/blob/master/CONTRIBUTING.md#contributing-code
 
 func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	deployment := func(name string, policies ...xpv1.ManagementAction) *v1alpha1.Deployment {
+		cr := &v1alpha1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web"}}
+		meta.SetExternalName(cr, name)
+		if len(policies) > 0 {
+			cr.SetManagementPolicies(policies)
+		}
+		return cr
+	}
+	onChain := func(state string) func(context.Context, string, string) (akashtypes.Deployment, error) {
+		return func(context.Context, string, string) (akashtypes.Deployment, error) {
+			return akashtypes.Deployment{DeploymentInfo: akashtypes.DeploymentInfo{State: state}}, nil
+		}
+	}
+	lease := akashtypes.Lease{Lease: akashtypes.LeaseInfo{LeaseId: akashtypes.LeaseId{Dseq: "42", Provider: "akash1provider"}}}
+
 	type fields struct {
-		service interface{}
+		client             *akashfake.Client
+		managementPolicies bool
 	}
 
 	type args struct {
@@ -56,12 +111,139 @@ func TestObserve(t *testing.T) {
 		args   args
 		want   want
 	}{
-		// TODO: Add test cases.
+		"NotCreated": {
+			reason: "A deployment whose external name is its name was not created yet.",
+			fields: fields{client: &akashfake.Client{}},
+			args:   args{ctx: context.Background(), mg: deployment("web")},
+			want:   want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"NotFound": {
+			reason: "A deployment the chain does not know does not exist.",
+			fields: fields{client: &akashfake.Client{
+				MockAccountAddress: func() (string, error) { return "akash1owner", nil },
+				MockReadDeployment: func(context.Context, string, string) (akashtypes.Deployment, error) {
+					return akashtypes.Deployment{}, errors.New("rpc error: deployment not found")
+				},
+			}},
+			args: args{ctx: context.Background(), mg: deployment("42")},
+			want: want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"ReadDeploymentError": {
+			reason: "Errors reading the deployment are returned.",
+			fields: fields{client: &akashfake.Client{
+				MockAccountAddress: func() (string, error) { return "akash1owner", nil },
+				MockReadDeployment: func(context.Context, string, string) (akashtypes.Deployment, error) {
+					return akashtypes.Deployment{}, errBoom
+				},
+			}},
+			args: args{ctx: context.Background(), mg: deployment("42")},
+			want: want{err: errors.Wrap(errBoom, errGetChainDeployment)},
+		},
+		"Closed": {
+			reason: "A closed deployment exists and cannot be updated.",
+			fields: fields{client: &akashfake.Client{
+				MockAccountAddress: func() (string, error) { return "akash1owner", nil },
+				MockReadDeployment: onChain("closed"),
+			}},
+			args: args{ctx: context.Background(), mg: deployment("42")},
+			want: want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"ReadLeasesError": {
+			reason: "Errors reading the leases of the deployment are returned.",
+			fields: fields{client: &akashfake.Client{
+				MockAccountAddress: func() (string, error) { return "akash1owner", nil },
+				MockReadDeployment: onChain("active"),
+				MockReadActiveLeases: func(context.Context, string, string) ([]akashtypes.Lease, error) {
+					return nil, errBoom
+				},
+			}},
+			args: args{ctx: context.Background(), mg: deployment("42")},
+			want: want{err: errors.Wrap(errBoom, errGetLeases)},
+		},
+		"ObservedLease": {
+			reason: "An observed deployment with an active lease is up to date, and publishes its services.",
+			fields: fields{
+				client: &akashfake.Client{
+					MockAccountAddress: func() (string, error) { return "akash1owner", nil },
+					MockReadDeployment: onChain("active"),
+					MockReadActiveLeases: func(context.Context, string, string) ([]akashtypes.Lease, error) {
+						return []akashtypes.Lease{lease}, nil
+					},
+					MockGetLeaseStatus: func(context.Context, akashtypes.LeaseId) (akashtypes.LeaseStatus, error) {
+						return akashtypes.LeaseStatus{Services: map[string]akashtypes.ServiceStatus{
+							"web": {Name: "web", Available: 1, Total: 1, URIs: []string{"web.example.com"}},
+						}}, nil
+					},
+				},
+				managementPolicies: true,
+			},
+			args: args{ctx: context.Background(), mg: deployment("42", xpv1.ManagementActionObserve)},
+			want: want{o: managed.ExternalObservation{
+				ResourceExists:   true,
+				ResourceUpToDate: true,
+				ConnectionDetails: managed.ConnectionDetails{
+					"web.uri":  []byte("web.example.com"),
+					"web.uris": []byte("web.example.com"),
+				},
+			}},
+		},
+		"NotOwnedBids": {
+			reason: "The bids on a deployment of another owner are queried for that owner, and the deployment is only observed.",
+			fields: fields{client: &akashfake.Client{
+				MockAccountAddress: func() (string, error) { return "akash1owner", nil },
+				MockReadDeployment: onChain("active"),
+				MockQueryBids: func(_ context.Context, seqs client.Seqs) (akashtypes.Bids, error) {
+					if seqs.Owner != "akash1other" {
+						return nil, errors.Errorf("queried the bids of %s", seqs.Owner)
+					}
+					return akashtypes.Bids{}, nil
+				},
+			}},
+			args: args{ctx: context.Background(), mg: deployment("akash1other/42")},
+			want: want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true, ConnectionDetails: managed.ConnectionDetails{}}},
+		},
+		"NotOwnedLease": {
+			reason: "The gateway of the provider of a deployment of another owner is not queried with the certificate of the account.",
+			fields: fields{client: &akashfake.Client{
+				MockAccountAddress: func() (string, error) { return "akash1owner", nil },
+				MockReadDeployment: onChain("active"),
+				MockReadActiveLeases: func(context.Context, string, string) ([]akashtypes.Lease, error) {
+					return []akashtypes.Lease{lease}, nil
+				},
+				MockGetLeaseStatus: func(context.Context, akashtypes.LeaseId) (akashtypes.LeaseStatus, error) {
+					return akashtypes.LeaseStatus{}, errBoom
+				},
+			}},
+			args: args{ctx: context.Background(), mg: deployment("akash1other/42")},
+			want: want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true, ConnectionDetails: managed.ConnectionDetails{}}},
+		},
+		"GatewayError": {
+			reason: "Errors getting the status of a lease are returned.",
+			fields: fields{
+				client: &akashfake.Client{
+					MockAccountAddress: func() (string, error) { return "akash1owner", nil },
+					MockReadDeployment: onChain("active"),
+					MockReadActiveLeases: func(context.Context, string, string) ([]akashtypes.Lease, error) {
+						return []akashtypes.Lease{lease}, nil
+					},
+					MockGetLeaseStatus: func(context.Context, akashtypes.LeaseId) (akashtypes.LeaseStatus, error) {
+						return akashtypes.LeaseStatus{}, errBoom
+					},
+				},
+				managementPolicies: true,
+			},
+			args: args{ctx: context.Background(), mg: deployment("42", xpv1.ManagementActionObserve)},
+			want: want{err: errors.Wrapf(errBoom, errGetLeaseStatus, "akash1provider")},
+		},
 	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			e := external{service: tc.fields.service.(*DeploymentService)}
+			e := external{
+				service:            &DeploymentService{client: tc.fields.client},
+				recorder:           event.NewNopRecorder(),
+				managementPolicies: tc.fields.managementPolicies,
+			}
 			got, err := e.Observe(tc.args.ctx, tc.args.mg)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
@@ -72,3 +254,2404 @@ func TestObserve(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	deployment := func(name string) *v1alpha1.Deployment {
+		cr := &v1alpha1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web"}}
+		meta.SetExternalName(cr, name)
+		return cr
+	}
+
+	type want struct {
+		err       error
+		condition xpv1.Condition
+	}
+
+	cases := map[string]struct {
+		reason string
+		client *akashfake.Client
+		mg     *v1alpha1.Deployment
+		want   want
+	}{
+		"AccountError": {
+			reason: "Errors getting the account of the ProviderConfig are returned.",
+			client: &akashfake.Client{
+				MockAccountAddress: func() (string, error) { return "", errBoom },
+			},
+			mg:   deployment("akash1other/42"),
+			want: want{err: errBoom, condition: xpv1.Condition{Type: v1alpha1.TypeAccount, Status: corev1.ConditionUnknown}},
+		},
+		"NotOwner": {
+			reason: "Deployments of other owners are neither updated nor leased.",
+			client: &akashfake.Client{
+				MockAccountAddress: func() (string, error) { return "akash1owner", nil },
+				MockUpdateDeployment: func(context.Context, string, string) error {
+					return errors.New("updated the deployment of another owner")
+				},
+				MockLeaseFirstOpenBid: func(context.Context, client.Seqs, akashtypes.Bids) (akashtypes.Bid, error) {
+					return akashtypes.Bid{}, errors.New("leased a bid on the deployment of another owner")
+				},
+			},
+			mg: deployment("akash1other/42"),
+			want: want{
+				err:       errors.Errorf(errUpdateUnowned, "42", "akash1other", "akash1owner"),
+				condition: v1alpha1.NotOwner("42", "akash1other", "akash1owner"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{service: &DeploymentService{client: tc.client}, recorder: event.NewNopRecorder()}
+			_, err := e.Update(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			got := tc.mg.GetCondition(v1alpha1.TypeAccount)
+			if !got.Equal(tc.want.condition) {
+				t.Errorf("\n%s\ne.Update(...): want condition %v, got %v\n", tc.reason, tc.want.condition, got)
+			}
+		})
+	}
+}
+
+func TestPromotedTwin(t *testing.T) {
+	usdc := func(network, denom string) *apisv1alpha1.ProviderConfig {
+		return &apisv1alpha1.ProviderConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: network},
+			Spec: apisv1alpha1.ProviderConfigSpec{Configuration: &apisv1alpha1.AkashConfiguration{
+				DeploymentDefaults: &apisv1alpha1.DeploymentDefaults{Deposit: &v1alpha1.Deposit{Amount: "5000000", Denom: denom}},
+			}},
+		}
+	}
+	sandbox := &apisv1alpha1.ProviderConfig{ObjectMeta: metav1.ObjectMeta{Name: "sandbox"}}
+	mainnet := &apisv1alpha1.ProviderConfig{ObjectMeta: metav1.ObjectMeta{Name: "mainnet"}}
+
+	pricedSDL := func(denom string) string {
+		return "profiles:\n  placement:\n    dcloud:\n      pricing:\n        web:\n          denom: " + denom + "\n          amount: 1000\n"
+	}
+	deployment := func(denom string) *v1alpha1.Deployment {
+		return &v1alpha1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "app"},
+			Spec: v1alpha1.DeploymentSpec{
+				ResourceSpec: xpv1.ResourceSpec{
+					ProviderConfigReference:          &xpv1.Reference{Name: "sandbox"},
+					DeletionPolicy:                   xpv1.DeletionDelete,
+					WriteConnectionSecretToReference: &xpv1.SecretReference{Name: "app", Namespace: "default"},
+				},
+				ForProvider: v1alpha1.DeploymentParameters{
+					SDLRef:              &v1alpha1.SDLReference{Name: "app"},
+					SDLChecksum:         ptr("checksum"),
+					MetadataPassthrough: []v1alpha1.MetadataPassthrough{{Label: ptr("team"), Env: "TEAM"}},
+					Deposit:             &v1alpha1.Deposit{Amount: "5000000", Denom: denom},
+					MaxPrice:            &v1alpha1.MaxPrice{Amount: "1000", Denom: denom},
+				},
+			},
+		}
+	}
+	twin := func(denom, sdl string) *v1alpha1.Deployment {
+		return &v1alpha1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "app-mainnet",
+				Labels: map[string]string{v1alpha1.LabelKeyPromotedFrom: "app"},
+			},
+			Spec: v1alpha1.DeploymentSpec{
+				ResourceSpec: xpv1.ResourceSpec{
+					ProviderConfigReference:          &xpv1.Reference{Name: "mainnet"},
+					DeletionPolicy:                   xpv1.DeletionDelete,
+					WriteConnectionSecretToReference: &xpv1.SecretReference{Name: "app-mainnet", Namespace: "default"},
+				},
+				ForProvider: v1alpha1.DeploymentParameters{
+					SDL:      ptr(sdl),
+					Deposit:  &v1alpha1.Deposit{Amount: "5000000", Denom: denom},
+					MaxPrice: &v1alpha1.MaxPrice{Amount: "1000", Denom: denom},
+				},
+			},
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		cr     *v1alpha1.Deployment
+		sdl    string
+		source *apisv1alpha1.ProviderConfig
+		target *apisv1alpha1.ProviderConfig
+		want   *v1alpha1.Deployment
+	}{
+		"SameDenom": {
+			reason: "The twin runs the rendered SDL of the Deployment as is when both networks fund deployments in the same denomination.",
+			cr:     deployment("uakt"),
+			sdl:    pricedSDL("uakt"),
+			source: sandbox,
+			target: mainnet,
+			want:   twin("uakt", pricedSDL("uakt")),
+		},
+		"OtherDenom": {
+			reason: "Prices in the denomination of the source network are moved to the one of the target network.",
+			cr:     deployment("ibc/SANDBOX"),
+			sdl:    pricedSDL("ibc/SANDBOX"),
+			source: usdc("sandbox", "ibc/SANDBOX"),
+			target: usdc("mainnet", "ibc/MAINNET"),
+			want:   twin("ibc/MAINNET", pricedSDL("ibc/MAINNET")),
+		},
+		"OtherDenomUntouched": {
+			reason: "Prices in other denominations than the one of the source network are kept.",
+			cr:     deployment("uakt"),
+			sdl:    pricedSDL("uakt"),
+			source: usdc("sandbox", "ibc/SANDBOX"),
+			target: usdc("mainnet", "ibc/MAINNET"),
+			want:   twin("uakt", pricedSDL("uakt")),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := promotedTwin(tc.cr, tc.sdl, tc.source, tc.target)
+			if err != nil {
+				t.Fatalf("promotedTwin(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\npromotedTwin(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPromote(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := apisv1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	sandbox := &apisv1alpha1.ProviderConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "sandbox"},
+		Spec:       apisv1alpha1.ProviderConfigSpec{Configuration: &apisv1alpha1.AkashConfiguration{PromotionTargets: []string{"mainnet"}}},
+	}
+	mainnet := &apisv1alpha1.ProviderConfig{ObjectMeta: metav1.ObjectMeta{Name: "mainnet"}}
+	deployment := func() *v1alpha1.Deployment {
+		return &v1alpha1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Annotations: map[string]string{v1alpha1.AnnotationKeyPromoteTo: "mainnet"}},
+			Spec: v1alpha1.DeploymentSpec{
+				ResourceSpec: xpv1.ResourceSpec{ProviderConfigReference: &xpv1.Reference{Name: "sandbox"}},
+				ForProvider:  v1alpha1.DeploymentParameters{SDL: ptr("version: \"2.0\"\n")},
+			},
+		}
+	}
+	existing := func(labels map[string]string) *v1alpha1.Deployment {
+		return &v1alpha1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app-mainnet", Labels: labels}}
+	}
+
+	type want struct {
+		phase      v1alpha1.PromotionPhase
+		deployment string
+	}
+
+	cases := map[string]struct {
+		reason    string
+		existing  *v1alpha1.Deployment
+		preflight error
+		want      want
+	}{
+		"Created": {
+			reason: "The twin is created and linked once the preflight checks pass.",
+			want:   want{phase: v1alpha1.PromotionPhaseCompleted, deployment: "app-mainnet"},
+		},
+		"PreflightFailed": {
+			reason:    "No twin is created while the preflight checks fail.",
+			preflight: errors.New("account holds less than the deposit"),
+			want:      want{phase: v1alpha1.PromotionPhasePreflightFailed},
+		},
+		"AlreadyPromoted": {
+			reason:   "A twin created by an earlier promotion is linked.",
+			existing: existing(map[string]string{v1alpha1.LabelKeyPromotedFrom: "app"}),
+			want:     want{phase: v1alpha1.PromotionPhaseCompleted, deployment: "app-mainnet"},
+		},
+		"NameTaken": {
+			reason:   "A Deployment of the name of the twin that was not promoted from the Deployment is never taken over.",
+			existing: existing(map[string]string{v1alpha1.LabelKeyPromotedFrom: "other"}),
+			want:     want{phase: v1alpha1.PromotionPhaseRefused},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := deployment()
+			objs := []kubeclient.Object{cr, sandbox.DeepCopy(), mainnet.DeepCopy()}
+			if tc.existing != nil {
+				objs = append(objs, tc.existing)
+			}
+			kube := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).WithStatusSubresource(cr).Build()
+			r := &promotionReconciler{
+				kube:   kube,
+				log:    logging.NewNopLogger(),
+				record: event.NewNopRecorder(),
+				usage:  resource.TrackerFn(func(context.Context, resource.Managed) error { return nil }),
+				newClient: func(context.Context, kubeclient.Client, resource.Managed, client.ProviderConfigInfo) (client.AkashAPI, error) {
+					return &akashfake.Client{MockPreflight: func(context.Context, akashtypes.Coin) error { return tc.preflight }}, nil
+				},
+			}
+
+			if _, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "app"}}); err != nil {
+				t.Fatalf("Reconcile(...): %v", err)
+			}
+			got := &v1alpha1.Deployment{}
+			if err := kube.Get(context.Background(), types.NamespacedName{Name: "app"}, got); err != nil {
+				t.Fatal(err)
+			}
+			p := got.Status.AtProvider.Promotion
+			if p == nil {
+				t.Fatalf("\n%s\nReconcile(...): no promotion status", tc.reason)
+			}
+			if diff := cmp.Diff(tc.want, want{phase: p.Phase, deployment: p.Deployment}, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nReconcile(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+
+			twin := &v1alpha1.Deployment{}
+			err := kube.Get(context.Background(), types.NamespacedName{Name: "app-mainnet"}, twin)
+			switch {
+			case tc.existing != nil:
+				if twin.Spec.ProviderConfigReference != nil {
+					t.Errorf("\n%s\nReconcile(...): the existing Deployment was modified", tc.reason)
+				}
+			case tc.want.deployment != "" && err != nil:
+				t.Errorf("\n%s\nReconcile(...): want the twin created, got %v", tc.reason, err)
+			case tc.want.deployment == "" && !kerrors.IsNotFound(err):
+				t.Errorf("\n%s\nReconcile(...): want no twin, got %v", tc.reason, err)
+			}
+		})
+	}
+}
+
+func TestPromotionRefusal(t *testing.T) {
+	sandbox := &apisv1alpha1.ProviderConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "sandbox"},
+		Spec: apisv1alpha1.ProviderConfigSpec{
+			Configuration: &apisv1alpha1.AkashConfiguration{PromotionTargets: []string{"mainnet"}},
+		},
+	}
+	deployment := func(labels map[string]string, policies ...xpv1.ManagementAction) *v1alpha1.Deployment {
+		cr := &v1alpha1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app", Labels: labels}}
+		cr.Spec.ProviderConfigReference = &xpv1.Reference{Name: "sandbox"}
+		cr.Spec.ManagementPolicies = policies
+		return cr
+	}
+
+	cases := map[string]struct {
+		cr                 *v1alpha1.Deployment
+		target             string
+		managementPolicies bool
+		wantRefused        bool
+	}{
+		"Allowed": {
+			cr:     deployment(nil),
+			target: "mainnet",
+		},
+		"NotATarget": {
+			cr:          deployment(nil),
+			target:      "treasury",
+			wantRefused: true,
+		},
+		"Claimed": {
+			cr:     deployment(map[string]string{client.LabelKeyClaimNamespace: "tenant"}),
+			target: "mainnet",
+		},
+		"NamespacedProviderConfig": {
+			cr: func() *v1alpha1.Deployment {
+				cr := deployment(map[string]string{client.LabelKeyClaimNamespace: "tenant"})
+				cr.Spec.NamespacedProviderConfigReference = &xpv1.Reference{Name: "team"}
+				return cr
+			}(),
+			target:      "mainnet",
+			wantRefused: true,
+		},
+		"ObserveOnly": {
+			cr:                 deployment(nil, xpv1.ManagementActionObserve),
+			target:             "mainnet",
+			managementPolicies: true,
+			wantRefused:        true,
+		},
+		"PoliciesDisabled": {
+			cr:     deployment(nil, xpv1.ManagementActionObserve),
+			target: "mainnet",
+		},
+		"AllActions": {
+			cr:                 deployment(nil, xpv1.ManagementActionAll),
+			target:             "mainnet",
+			managementPolicies: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := &test.MockClient{MockGet: func(_ context.Context, key kubeclient.ObjectKey, obj kubeclient.Object) error {
+				if key.Name != sandbox.GetName() {
+					return kerrors.NewNotFound(schema.GroupResource{Resource: "providerconfigs"}, key.Name)
+				}
+				sandbox.DeepCopyInto(obj.(*apisv1alpha1.ProviderConfig))
+				return nil
+			}}
+			r := &promotionReconciler{kube: kube, managementPolicies: tc.managementPolicies}
+
+			refusal, err := r.refusal(context.Background(), tc.cr, tc.target)
+			if err != nil {
+				t.Fatalf("refusal(...): %v", err)
+			}
+			if refused := refusal != ""; refused != tc.wantRefused {
+				t.Errorf("refusal(...): want refused %t, got %q", tc.wantRefused, refusal)
+			}
+		})
+	}
+}
+
+func TestInspectAtHeight(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := apisv1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	pc := &apisv1alpha1.ProviderConfig{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	errBoom := errors.New("boom")
+
+	deployment := akashtypes.Deployment{
+		DeploymentInfo: akashtypes.DeploymentInfo{State: "active", DeploymentId: akashtypes.DeploymentId{Dseq: "42", Owner: "akash1owner"}},
+		EscrowAccount:  akashtypes.EscrowAccount{Balance: akashtypes.EscrowAccountBalance{Amount: "5000000", Denom: "uakt"}},
+	}
+	lease := akashtypes.Lease{Lease: akashtypes.LeaseInfo{
+		LeaseId: akashtypes.LeaseId{Owner: "akash1owner", Dseq: "42", Gseq: 1, Oseq: 1, Provider: "akash1provider"},
+		State:   "active",
+		Price:   akashtypes.BidPrice{Denom: "uakt", Amount: 1.5},
+	}}
+
+	type want struct {
+		msg    string
+		err    error
+		owner  string
+		height int64
+	}
+
+	cases := map[string]struct {
+		reason       string
+		externalName string
+		height       string
+		leases       []akashtypes.Lease
+		queryErr     error
+		want         want
+	}{
+		"InvalidHeight": {
+			reason:       "Heights that are not positive integers should be refused.",
+			externalName: "42",
+			height:       "latest",
+			want:         want{err: errors.Errorf("%s: %q", errInvalidHeight, "latest")},
+		},
+		"NotCreated": {
+			reason:       "Deployments that were not created yet have no state to inspect.",
+			externalName: "app",
+			height:       "100",
+			want:         want{err: errors.New(errNoExternalName)},
+		},
+		"Leased": {
+			reason:       "The deployment and its leases should be described as they were at the height.",
+			externalName: "42",
+			height:       "100",
+			leases:       []akashtypes.Lease{lease},
+			want: want{
+				msg:    "At height 100 deployment 42 was active with escrow balance 5000000uakt; leases: 1/1 with akash1provider active at 1.5uakt",
+				owner:  "akash1account",
+				height: 100,
+			},
+		},
+		"ImportedWithoutLeases": {
+			reason:       "Imported deployments should be queried for their owner.",
+			externalName: "akash1owner/42",
+			height:       "100",
+			want: want{
+				msg:    "At height 100 deployment 42 was active with escrow balance 5000000uakt and had no leases",
+				owner:  "akash1owner",
+				height: 100,
+			},
+		},
+		"Pruned": {
+			reason:       "Errors querying the chain, e.g. because the state was pruned, should be returned.",
+			externalName: "42",
+			height:       "100",
+			queryErr:     errBoom,
+			want:         want{err: errors.Wrap(errBoom, errQueryAtHeight), owner: "akash1account", height: 100},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &v1alpha1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Annotations: map[string]string{meta.AnnotationKeyExternalName: tc.externalName}},
+				Spec:       v1alpha1.DeploymentSpec{ResourceSpec: xpv1.ResourceSpec{ProviderConfigReference: &xpv1.Reference{Name: "default"}}},
+			}
+			got := want{}
+			r := &diagnosticsReconciler{
+				kube: fake.NewClientBuilder().WithScheme(scheme).WithObjects(pc.DeepCopy()).Build(),
+				newClient: func(context.Context, kubeclient.Client, resource.Managed, client.ProviderConfigInfo) (client.AkashAPI, error) {
+					return &akashfake.Client{
+						MockAccountAddress: func() (string, error) { return "akash1account", nil },
+						MockGetDeploymentAtHeight: func(_ context.Context, _ string, owner string, height int64) (akashtypes.Deployment, error) {
+							got.owner, got.height = owner, height
+							return deployment, tc.queryErr
+						},
+						MockGetLeasesAtHeight: func(context.Context, string, string, int64) ([]akashtypes.Lease, error) {
+							return tc.leases, nil
+						},
+					}, nil
+				},
+			}
+
+			msg, err := r.inspectAtHeight(context.Background(), cr, tc.height)
+			got.msg, got.err = msg, err
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{}), test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ninspectAtHeight(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUnleasedRemaining(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		now  time.Time
+		want time.Duration
+	}{
+		"WithinPeriod": {
+			now:  since.Add(45 * time.Minute),
+			want: 15 * time.Minute,
+		},
+		"PeriodElapsed": {
+			now:  since.Add(time.Hour),
+			want: 0,
+		},
+		"PeriodExceeded": {
+			now:  since.Add(2 * time.Hour),
+			want: 0,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, unleasedRemaining(since, time.Hour, tc.now)); diff != "" {
+				t.Errorf("unleasedRemaining(...): -want, +got:\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestExpiresAt(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	deployment := func(ttl time.Duration, closeAt *time.Time) *v1alpha1.Deployment {
+		cr := &v1alpha1.Deployment{}
+		meta.SetExternalCreateSucceeded(cr, created)
+		if ttl > 0 {
+			cr.Spec.ForProvider.TTL = &metav1.Duration{Duration: ttl}
+		}
+		if closeAt != nil {
+			cr.Spec.ForProvider.CloseAt = &metav1.Time{Time: *closeAt}
+		}
+		return cr
+	}
+	early := created.Add(time.Hour)
+	late := created.Add(48 * time.Hour)
+
+	cases := map[string]struct {
+		cr   *v1alpha1.Deployment
+		want *metav1.Time
+	}{
+		"Unset": {
+			cr: deployment(0, nil),
+		},
+		"TTL": {
+			cr:   deployment(24*time.Hour, nil),
+			want: &metav1.Time{Time: created.Add(24 * time.Hour)},
+		},
+		"CloseAt": {
+			cr:   deployment(0, &early),
+			want: &metav1.Time{Time: early},
+		},
+		"CloseAtFirst": {
+			cr:   deployment(24*time.Hour, &early),
+			want: &metav1.Time{Time: early},
+		},
+		"TTLFirst": {
+			cr:   deployment(24*time.Hour, &late),
+			want: &metav1.Time{Time: created.Add(24 * time.Hour)},
+		},
+		"NotCreatedByController": {
+			cr: func() *v1alpha1.Deployment {
+				cr := &v1alpha1.Deployment{}
+				cr.SetCreationTimestamp(metav1.Time{Time: created})
+				cr.Spec.ForProvider.TTL = &metav1.Duration{Duration: time.Hour}
+				return cr
+			}(),
+			want: &metav1.Time{Time: early},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, expiresAt(tc.cr)); diff != "" {
+				t.Errorf("expiresAt(...): -want, +got:\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestVerifySDLChecksum(t *testing.T) {
+	mismatch := "4c8ed4a4e5e3a4b59b5fcc8b0e5de0f56ec2b3b8a1cae0b2c1b3d4e5f6a7b8c9"
+
+	cases := map[string]struct {
+		sdl      string
+		checksum *string
+		wantErr  bool
+	}{
+		"NoChecksum": {
+			sdl: "version: \"2.0\"\n",
+		},
+		"Match": {
+			// SHA-256 of the empty string.
+			sdl:      "",
+			checksum: ptr("e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"),
+		},
+		"Mismatch": {
+			sdl:      "version: \"2.0\"\n",
+			checksum: &mismatch,
+			wantErr:  true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := verifySDLChecksum(tc.sdl, tc.checksum)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("verifySDLChecksum(...): want error %t, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func ptr(s string) *string { return &s }
+
+func TestRedactSDL(t *testing.T) {
+	sdl := `services:
+  web:
+    image: nginx
+    env:
+      - DB_USER=admin
+      - "DB_PASSWORD=s3cr=t"
+`
+	want := `services:
+  web:
+    image: nginx
+    env:
+      - DB_USER=<redacted>
+      - "DB_PASSWORD=<redacted>"
+`
+
+	if diff := cmp.Diff(want, redactSDL(sdl)); diff != "" {
+		t.Errorf("redactSDL(...): -want, +got:\n%s\n", diff)
+	}
+}
+
+func TestHealthCheckTarget(t *testing.T) {
+	status := akashtypes.LeaseStatus{
+		Services: map[string]akashtypes.ServiceStatus{
+			"web": {Name: "web", URIs: []string{"web.provider.example.com"}},
+		},
+		ForwardedPorts: map[string][]akashtypes.ForwardedPort{
+			"db": {{Host: "node1.provider.example.com", Port: 5432, ExternalPort: 31234}},
+		},
+	}
+
+	cases := map[string]struct {
+		check   v1alpha1.HealthCheck
+		want    string
+		wantErr bool
+	}{
+		"HTTP": {
+			check: v1alpha1.HealthCheck{Service: "web", Type: v1alpha1.HealthCheckHTTP, Path: "healthz"},
+			want:  "http://web.provider.example.com/healthz",
+		},
+		"TCP": {
+			check: v1alpha1.HealthCheck{Service: "db", Type: v1alpha1.HealthCheckTCP, Port: 5432},
+			want:  "node1.provider.example.com:31234",
+		},
+		"NoURI": {
+			check:   v1alpha1.HealthCheck{Service: "db", Type: v1alpha1.HealthCheckHTTP, Path: "/"},
+			wantErr: true,
+		},
+		"NoForwardedPort": {
+			check:   v1alpha1.HealthCheck{Service: "db", Type: v1alpha1.HealthCheckTCP, Port: 5433},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := healthCheckTarget(tc.check, status)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("healthCheckTarget(...): want error %t, got %v", tc.wantErr, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("healthCheckTarget(...): -want, +got:\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestAnalyzeSpend(t *testing.T) {
+	deployment := func(balance, settledAt string) akashtypes.Deployment {
+		return akashtypes.Deployment{EscrowAccount: akashtypes.EscrowAccount{
+			Balance:   akashtypes.EscrowAccountBalance{Denom: "uakt", Amount: balance},
+			SettledAt: settledAt,
+		}}
+	}
+	lease := func(price, rate float32) akashtypes.Lease {
+		return akashtypes.Lease{
+			Lease:         akashtypes.LeaseInfo{LeaseId: akashtypes.LeaseId{Provider: "akash1provider"}, Price: akashtypes.BidPrice{Denom: "uakt", Amount: price}},
+			EscrowPayment: akashtypes.EscrowPayment{Rate: akashtypes.BidPrice{Denom: "uakt", Amount: rate}},
+		}
+	}
+
+	cases := map[string]struct {
+		prev       *v1alpha1.SpendStatus
+		deployment akashtypes.Deployment
+		leases     []akashtypes.Lease
+		want       *v1alpha1.SpendStatus
+	}{
+		"FirstSample": {
+			deployment: deployment("5000000", "100"),
+			leases:     []akashtypes.Lease{lease(10, 10)},
+			want:       &v1alpha1.SpendStatus{Denom: "uakt", Balance: "5000000", SettledAt: 100, AgreedRate: "10"},
+		},
+		"DrainsAsAgreed": {
+			prev:       &v1alpha1.SpendStatus{Denom: "uakt", Balance: "5000000", SettledAt: 100, AgreedRate: "10"},
+			deployment: deployment("4999000", "200"),
+			leases:     []akashtypes.Lease{lease(10, 10)},
+			want:       &v1alpha1.SpendStatus{Denom: "uakt", Balance: "4999000", SettledAt: 200, AgreedRate: "10", ObservedRate: "10"},
+		},
+		"DrainsTooFast": {
+			prev:       &v1alpha1.SpendStatus{Denom: "uakt", Balance: "5000000", SettledAt: 100, AgreedRate: "10"},
+			deployment: deployment("4997000", "200"),
+			leases:     []akashtypes.Lease{lease(10, 10)},
+			want: &v1alpha1.SpendStatus{Denom: "uakt", Balance: "4997000", SettledAt: 200, AgreedRate: "10", ObservedRate: "30",
+				Anomaly: "escrow drained at 30uakt per block over the last 100 blocks, above the 10uakt agreed for its leases"},
+		},
+		"ToppedUp": {
+			prev:       &v1alpha1.SpendStatus{Denom: "uakt", Balance: "5000000", SettledAt: 100, AgreedRate: "10", ObservedRate: "10"},
+			deployment: deployment("9999000", "200"),
+			leases:     []akashtypes.Lease{lease(10, 10)},
+			want:       &v1alpha1.SpendStatus{Denom: "uakt", Balance: "9999000", SettledAt: 200, AgreedRate: "10", ObservedRate: "10"},
+		},
+		"Repriced": {
+			deployment: deployment("5000000", "100"),
+			leases:     []akashtypes.Lease{lease(10, 15)},
+			want: &v1alpha1.SpendStatus{Denom: "uakt", Balance: "5000000", SettledAt: 100, AgreedRate: "10",
+				Anomaly: "lease with akash1provider is charged 15uakt per block above its price of 10uakt"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, analyzeSpend(tc.prev, tc.deployment, tc.leases, 20)); diff != "" {
+				t.Errorf("analyzeSpend(...): -want, +got:\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestOrderHealthChecks(t *testing.T) {
+	web := v1alpha1.HealthCheck{Service: "web"}
+	api := v1alpha1.HealthCheck{Service: "api"}
+	db := v1alpha1.HealthCheck{Service: "db", Type: v1alpha1.HealthCheckTCP}
+
+	cases := map[string]struct {
+		dependencies map[string][]string
+		want         []v1alpha1.HealthCheck
+		wantErr      bool
+	}{
+		"NoDependencies": {
+			want: []v1alpha1.HealthCheck{web, api, db},
+		},
+		"DependenciesFirst": {
+			dependencies: map[string][]string{"web": {"api"}, "api": {"db"}},
+			want:         []v1alpha1.HealthCheck{db, api, web},
+		},
+		"Cycle": {
+			dependencies: map[string][]string{"web": {"api"}, "api": {"web"}},
+			wantErr:      true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := orderHealthChecks([]v1alpha1.HealthCheck{web, api, db}, tc.dependencies)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("orderHealthChecks(...): want error %t, got %v", tc.wantErr, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("orderHealthChecks(...): -want, +got:\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestPaymentDelta(t *testing.T) {
+	cases := map[string]struct {
+		prev, cur string
+		want      string
+		wantOK    bool
+	}{
+		"FirstExport":  {cur: "1200.500000000000000000", want: "1200.5", wantOK: true},
+		"Increase":     {prev: "1200.5", cur: "1300.000000000000000001", want: "99.500000000000000001", wantOK: true},
+		"NoWithdrawal": {prev: "1300", cur: "1300.000000000000000000"},
+		"Invalid":      {prev: "1300", cur: "n/a"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, ok := paymentDelta(tc.prev, tc.cur)
+			if diff := cmp.Diff(tc.want, got); diff != "" || ok != tc.wantOK {
+				t.Errorf("paymentDelta(%q, %q): want %q, %t, got %q, %t", tc.prev, tc.cur, tc.want, tc.wantOK, got, ok)
+			}
+		})
+	}
+}
+
+func TestRestoreExternalName(t *testing.T) {
+	cases := map[string]struct {
+		externalName string
+		owner        string
+		dseq         string
+		want         string
+		restored     bool
+	}{
+		"NoDseq": {
+			want: "",
+		},
+		"Missing": {
+			dseq:     "1234",
+			want:     "1234",
+			restored: true,
+		},
+		"ResetToName": {
+			externalName: "example",
+			dseq:         "1234",
+			want:         "1234",
+			restored:     true,
+		},
+		"Present": {
+			externalName: "1234",
+			dseq:         "1234",
+			want:         "1234",
+		},
+		"SetByUser": {
+			externalName: "5678",
+			dseq:         "1234",
+			want:         "5678",
+		},
+		"Imported": {
+			owner:    "akash1owner",
+			dseq:     "1234",
+			want:     "akash1owner/1234",
+			restored: true,
+		},
+		"ImportedPresent": {
+			externalName: "akash1owner/1234",
+			owner:        "akash1owner",
+			dseq:         "1234",
+			want:         "akash1owner/1234",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &v1alpha1.Deployment{}
+			cr.SetName("example")
+			if tc.externalName != "" {
+				meta.SetExternalName(cr, tc.externalName)
+			}
+			cr.Status.AtProvider.Dseq = tc.dseq
+			cr.Status.AtProvider.Owner = tc.owner
+
+			restored := restoreExternalName(cr)
+			if restored != tc.restored {
+				t.Errorf("restoreExternalName(...): want %t, got %t", tc.restored, restored)
+			}
+			if diff := cmp.Diff(tc.want, meta.GetExternalName(cr)); diff != "" {
+				t.Errorf("restoreExternalName(...): -want external name, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseExternalName(t *testing.T) {
+	cases := map[string]struct {
+		name  string
+		owner string
+		dseq  string
+		err   bool
+	}{
+		"Dseq":            {name: "1234", dseq: "1234"},
+		"NotCreated":      {name: "example", dseq: "example"},
+		"Imported":        {name: "akash1owner/1234", owner: "akash1owner", dseq: "1234"},
+		"InvalidOwner":    {name: "cosmos1owner/1234", dseq: "cosmos1owner/1234", err: true},
+		"InvalidDseq":     {name: "akash1owner/latest", dseq: "akash1owner/latest", err: true},
+		"TooManySegments": {name: "akash1owner/1234/1", dseq: "akash1owner/1234/1", err: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			owner, dseq, err := parseExternalName(tc.name)
+			if (err != nil) != tc.err {
+				t.Fatalf("parseExternalName(%q): unexpected error %v", tc.name, err)
+			}
+			if owner != tc.owner || dseq != tc.dseq {
+				t.Errorf("parseExternalName(%q): want %q %q, got %q %q", tc.name, tc.owner, tc.dseq, owner, dseq)
+			}
+		})
+	}
+}
+
+func TestMayClose(t *testing.T) {
+	allow, disallow := true, false
+	cases := map[string]struct {
+		owner string
+		allow *bool
+		err   bool
+	}{
+		"Owned":      {owner: "akash1account"},
+		"Unowned":    {owner: "akash1owner", err: true},
+		"Disallowed": {owner: "akash1owner", allow: &disallow, err: true},
+		"Allowed":    {owner: "akash1owner", allow: &allow},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &v1alpha1.Deployment{}
+			cr.Spec.ForProvider.AllowClosingUnowned = tc.allow
+			if err := mayClose(cr, "1234", tc.owner, "akash1account"); (err != nil) != tc.err {
+				t.Errorf("mayClose(...): want error %t, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestMayAct(t *testing.T) {
+	cases := map[string]struct {
+		enabled  bool
+		policies xpv1.ManagementPolicies
+		actions  []xpv1.ManagementAction
+		want     bool
+	}{
+		"Disabled": {
+			policies: xpv1.ManagementPolicies{xpv1.ManagementActionObserve},
+			actions:  []xpv1.ManagementAction{xpv1.ManagementActionDelete},
+			want:     true,
+		},
+		"All": {
+			enabled:  true,
+			policies: xpv1.ManagementPolicies{xpv1.ManagementActionAll},
+			actions:  []xpv1.ManagementAction{xpv1.ManagementActionCreate, xpv1.ManagementActionDelete},
+			want:     true,
+		},
+		"ObserveOnly": {
+			enabled:  true,
+			policies: xpv1.ManagementPolicies{xpv1.ManagementActionObserve},
+			actions:  []xpv1.ManagementAction{xpv1.ManagementActionUpdate},
+		},
+		"Paused": {
+			enabled: true,
+			actions: []xpv1.ManagementAction{xpv1.ManagementActionDelete},
+		},
+		"SomeActions": {
+			enabled:  true,
+			policies: xpv1.ManagementPolicies{xpv1.ManagementActionObserve, xpv1.ManagementActionCreate, xpv1.ManagementActionUpdate},
+			actions:  []xpv1.ManagementAction{xpv1.ManagementActionCreate, xpv1.ManagementActionDelete},
+		},
+		"EveryAction": {
+			enabled:  true,
+			policies: xpv1.ManagementPolicies{xpv1.ManagementActionObserve, xpv1.ManagementActionCreate, xpv1.ManagementActionDelete},
+			actions:  []xpv1.ManagementAction{xpv1.ManagementActionCreate, xpv1.ManagementActionDelete},
+			want:     true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &v1alpha1.Deployment{}
+			cr.SetManagementPolicies(tc.policies)
+			if got := mayAct(tc.enabled, cr, tc.actions...); got != tc.want {
+				t.Errorf("mayAct(...): want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRenderSDL(t *testing.T) {
+	sdl := `version: "2.0"
+services:
+  web:
+    image: nginx
+    env:
+      - TEAM=own
+  db:
+    image: postgres
+`
+	cases := map[string]struct {
+		passthrough []v1alpha1.MetadataPassthrough
+		want        string
+		wantErr     bool
+	}{
+		"NoPassthrough": {
+			want: sdl,
+		},
+		"LabelAndAnnotation": {
+			passthrough: []v1alpha1.MetadataPassthrough{
+				{Label: ptr("team"), Env: "TEAM"},
+				{Annotation: ptr("example.org/cost-center"), Env: "COST_CENTER"},
+				{Label: ptr("missing"), Env: "MISSING"},
+			},
+			want: `version: "2.0"
+services:
+  web:
+    image: nginx
+    env:
+      - TEAM=own
+      - COST_CENTER=cc-42
+  db:
+    image: postgres
+    env:
+      - TEAM=platform
+      - COST_CENTER=cc-42
+`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &v1alpha1.Deployment{}
+			cr.SetLabels(map[string]string{"team": "platform"})
+			cr.SetAnnotations(map[string]string{"example.org/cost-center": "cc-42"})
+			cr.Spec.ForProvider.MetadataPassthrough = tc.passthrough
+
+			got, err := renderSDL(cr, sdl)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("renderSDL(...): want error %t, got %v", tc.wantErr, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("renderSDL(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateResources(t *testing.T) {
+	sdl := func(cpu, memory, storage string, count int) string {
+		return `version: "2.0"
+services:
+  web:
+    image: nginx
+profiles:
+  compute:
+    web:
+      resources:
+        cpu:
+          units: ` + cpu + `
+        memory:
+          size: ` + memory + `
+        storage:
+` + storage + `
+  placement:
+    dcloud:
+      pricing:
+        web:
+          denom: uakt
+          amount: 1000
+deployment:
+  web:
+    dcloud:
+      profile: web
+      count: ` + strconv.Itoa(count) + `
+`
+	}
+
+	cases := map[string]struct {
+		sdl     string
+		want    []string
+		wantErr bool
+	}{
+		"WithinLimits": {
+			sdl: sdl("0.5", "512Mi", "          size: 1Gb", 2),
+		},
+		"StorageList": {
+			sdl: sdl("500m", "1Gi", "          - size: 1Gi\n          - name: data\n            size: 10Gi", 1),
+		},
+		"UnitLimits": {
+			sdl: sdl("0.001", "512Ki", "          size: 1Mi", 51),
+			want: []string{
+				"service web in placement dcloud requests count 51, above the maximum of 50",
+				"service web in placement dcloud requests cpu 1m, below the minimum of 10m",
+				"service web in placement dcloud requests memory 512Ki, below the minimum of 1Mi",
+				"service web in placement dcloud requests storage volume 0 1Mi, below the minimum of 5Mi",
+			},
+		},
+		"GroupLimits": {
+			sdl: sdl("300", "1Gi", "          size: 1Gi", 2),
+			want: []string{
+				"group dcloud requests total cpu 600, above the maximum of 512",
+			},
+		},
+		"InvalidQuantity": {
+			sdl:     sdl("lots", "1Gi", "          size: 1Gi", 1),
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, manifests, err := akashsdl.ParseSDL([]byte(tc.sdl))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseSDL(...): want error %t, got %v", tc.wantErr, err)
+			}
+			if diff := cmp.Diff(tc.want, validateResources(manifests, networkLimits)); diff != "" {
+				t.Errorf("validateResources(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStaleCertificates(t *testing.T) {
+	now := time.Now()
+	cert := func(serial int64, notAfter time.Time) *x509.Certificate {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpl := &x509.Certificate{SerialNumber: big.NewInt(serial), NotBefore: now.Add(-time.Hour), NotAfter: notAfter}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	certs := map[string]*x509.Certificate{
+		"tls.crt":     cert(1, now.Add(time.Hour)),
+		"old.crt":     cert(2, now.Add(-time.Minute)),
+		"revoked.pem": cert(3, now.Add(time.Hour)),
+	}
+	valid := map[string]bool{"1": true, "2": true}
+
+	want := map[string]string{"old.crt": "expired", "revoked.pem": "revoked"}
+	if diff := cmp.Diff(want, staleCertificates(certs, valid, now)); diff != "" {
+		t.Errorf("staleCertificates(...): -want, +got:\n%s", diff)
+	}
+
+	if got := privateKeyOf("revoked.pem"); got != "revoked.key" {
+		t.Errorf("privateKeyOf(...): want revoked.key, got %s", got)
+	}
+}
+
+func TestSummarizeFleet(t *testing.T) {
+	deployment := func(pc string, spend *v1alpha1.SpendStatus, conditions ...xpv1.Condition) v1alpha1.Deployment {
+		cr := v1alpha1.Deployment{}
+		if pc != "" {
+			cr.SetProviderConfigReference(&xpv1.Reference{Name: pc})
+		}
+		cr.SetConditions(conditions...)
+		cr.Status.AtProvider.Spend = spend
+		return cr
+	}
+	spend := &v1alpha1.SpendStatus{Denom: "uakt", Balance: "1000", AgreedRate: "1"}
+
+	got := summarizeFleet([]v1alpha1.Deployment{
+		deployment("", spend, xpv1.Available()),
+		deployment("", spend, xpv1.Available(), xpv1.ReconcileError(errors.New("boom"))),
+		deployment("", spend, xpv1.Available(), v1alpha1.ClosedUnleased(metav1.Duration{Duration: time.Hour})),
+		deployment("sandbox", nil, xpv1.Creating()),
+		deployment("sandbox", nil),
+	})
+
+	if s := got["default"]; s == nil {
+		t.Fatal("summarizeFleet(...): missing default ProviderConfig")
+	} else {
+		if diff := cmp.Diff(map[string]int{"Available": 2, "Closed": 1}, s.states); diff != "" {
+			t.Errorf("summarizeFleet(...): -want states, +got:\n%s", diff)
+		}
+		if s.failing != 1 {
+			t.Errorf("summarizeFleet(...): want 1 failing, got %d", s.failing)
+		}
+		if s.escrow["uakt"] != 2000 {
+			t.Errorf("summarizeFleet(...): want escrow 2000, got %v", s.escrow["uakt"])
+		}
+		if m := s.monthlySpend["uakt"]; m < 2*430000 || m > 2*432000 {
+			t.Errorf("summarizeFleet(...): want monthly spend of about 2*431000, got %v", m)
+		}
+	}
+	if diff := cmp.Diff(map[string]int{"Creating": 1, "Unknown": 1}, got["sandbox"].states); diff != "" {
+		t.Errorf("summarizeFleet(...): -want sandbox states, +got:\n%s", diff)
+	}
+}
+
+func TestColocatedProvider(t *testing.T) {
+	cases := map[string]struct {
+		target  *v1alpha1.Deployment
+		want    string
+		wantErr bool
+	}{
+		"Placed": {
+			target: &v1alpha1.Deployment{Status: v1alpha1.DeploymentStatus{AtProvider: v1alpha1.DeploymentObservation{
+				Bids: []v1alpha1.BidRecord{
+					{Provider: "akash1old", Decision: v1alpha1.BidDecisionAccepted},
+					{Provider: "akash1new", Decision: v1alpha1.BidDecisionAccepted},
+					{Provider: "akash1other", Decision: v1alpha1.BidDecisionRejected},
+				},
+			}}},
+			want: "akash1new",
+		},
+		"Unplaced": {
+			target:  &v1alpha1.Deployment{},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := &test.MockClient{MockGet: func(_ context.Context, _ kubeclient.ObjectKey, obj kubeclient.Object) error {
+				tc.target.DeepCopyInto(obj.(*v1alpha1.Deployment))
+				return nil
+			}}
+			cr := &v1alpha1.Deployment{}
+			cr.Spec.ForProvider.ColocateWith = ptr("db")
+
+			got, err := colocatedProvider(context.Background(), kube, cr)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("colocatedProvider(...): want error %t, got %v", tc.wantErr, err)
+			}
+			if got != tc.want {
+				t.Errorf("colocatedProvider(...): want %q, got %q", tc.want, got)
+			}
+
+			bids := akashtypes.Bids{
+				{Id: akashtypes.BidId{Provider: "akash1cheap"}, Price: akashtypes.BidPrice{Amount: 1}},
+				{Id: akashtypes.BidId{Provider: "akash1new"}, Price: akashtypes.BidPrice{Amount: 3}},
+				{Id: akashtypes.BidId{Provider: "akash1mid"}, Price: akashtypes.BidPrice{Amount: 2}},
+			}
+			if first := bids.PreferProvider(got)[0].Id.Provider; tc.want != "" && first != tc.want {
+				t.Errorf("PreferProvider(%q): want %q first, got %q", got, tc.want, first)
+			}
+		})
+	}
+}
+
+func TestResolveSDL(t *testing.T) {
+	cases := map[string]struct {
+		params  v1alpha1.DeploymentParameters
+		want    string
+		wantErr bool
+	}{
+		"ConfigMap": {
+			params: v1alpha1.DeploymentParameters{
+				SDL:    ptr("inline"),
+				SDLRef: &v1alpha1.SDLReference{Name: "web", Namespace: "apps"},
+			},
+			want: "from-configmap",
+		},
+		"SecretKey": {
+			params: v1alpha1.DeploymentParameters{
+				SDLRef: &v1alpha1.SDLReference{Kind: v1alpha1.SDLSourceSecret, Name: "web", Namespace: "apps", Key: "sdl"},
+			},
+			want: "from-secret",
+		},
+		"MissingKey": {
+			params: v1alpha1.DeploymentParameters{
+				SDLRef: &v1alpha1.SDLReference{Name: "web", Namespace: "apps", Key: "missing"},
+			},
+			wantErr: true,
+		},
+		"Inline": {
+			params: v1alpha1.DeploymentParameters{SDL: ptr("inline"), Deployment: "deprecated"},
+			want:   "inline",
+		},
+		"Deprecated": {
+			params: v1alpha1.DeploymentParameters{Deployment: "deprecated"},
+			want:   "deprecated",
+		},
+		"None": {
+			wantErr: true,
+		},
+	}
+
+	kube := &test.MockClient{MockGet: func(_ context.Context, _ kubeclient.ObjectKey, obj kubeclient.Object) error {
+		switch o := obj.(type) {
+		case *corev1.ConfigMap:
+			o.Data = map[string]string{"deploy.yaml": "from-configmap"}
+		case *corev1.Secret:
+			o.Data = map[string][]byte{"sdl": []byte("from-secret")}
+		}
+		return nil
+	}}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &v1alpha1.Deployment{}
+			cr.Spec.ForProvider = tc.params
+
+			got, err := resolveSDL(context.Background(), kube, cr)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("resolveSDL(...): want error %t, got %v", tc.wantErr, err)
+			}
+			if got != tc.want {
+				t.Errorf("resolveSDL(...): want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSDLReferrerRequests(t *testing.T) {
+	deployment := func(name, freeze string) v1alpha1.Deployment {
+		cr := v1alpha1.Deployment{}
+		cr.SetName(name)
+		if freeze != "" {
+			cr.SetAnnotations(map[string]string{v1alpha1.AnnotationKeySDLFreeze: freeze})
+		}
+		return cr
+	}
+	items := []v1alpha1.Deployment{
+		deployment("web", ""),
+		deployment("frozen", "true"),
+		deployment("thawed", "false"),
+	}
+
+	got := sdlReferrerRequests(items)
+	want := []reconcile.Request{
+		{NamespacedName: kubeclient.ObjectKey{Name: "web"}},
+		{NamespacedName: kubeclient.ObjectKey{Name: "thawed"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("sdlReferrerRequests(...): -want, +got:\n%s", diff)
+	}
+
+	cr := &v1alpha1.Deployment{}
+	cr.Spec.ForProvider.SDLRef = &v1alpha1.SDLReference{Name: "web", Namespace: "apps"}
+	if diff := cmp.Diff([]string{"ConfigMap/apps/web"}, indexSDLRef(cr)); diff != "" {
+		t.Errorf("indexSDLRef(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestTransactionOptions(t *testing.T) {
+	cases := map[string]struct {
+		overrides *v1alpha1.TransactionOverrides
+		want      client.TransactionOptions
+	}{
+		"None": {},
+		"All": {
+			overrides: &v1alpha1.TransactionOverrides{
+				Memo:          ptr("priority"),
+				GasAdjustment: ptr("1.75"),
+				FeeCap:        ptr("50000uakt"),
+			},
+			want: client.TransactionOptions{Memo: "priority", GasAdjustment: 1.75, Fees: "50000uakt"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &v1alpha1.Deployment{}
+			cr.Spec.ForProvider.Transaction = tc.overrides
+			if diff := cmp.Diff(tc.want, transactionOptions(cr)); diff != "" {
+				t.Errorf("transactionOptions(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+// eventLog is an event.Recorder remembering the recorded events.
+type eventLog struct {
+	events []event.Event
+}
+
+func (l *eventLog) Event(_ runtime.Object, e event.Event) { l.events = append(l.events, e) }
+
+func (l *eventLog) WithAnnotations(...string) event.Recorder { return l }
+
+func TestTimeline(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	type recorded struct {
+		after time.Duration
+		event event.Event
+	}
+
+	cases := map[string]struct {
+		reason string
+		events []recorded
+		want   []event.Event
+	}{
+		"Ordered": {
+			reason: "Phases recorded in order should all be recorded.",
+			events: []recorded{
+				{0, event.Normal(reasonDeploymentCreated, "Created deployment 1")},
+				{time.Second, event.Normal(reasonLeaseCreated, "Leased from akash1provider")},
+				{2 * time.Second, event.Normal(reasonServiceReady, "ready")},
+			},
+			want: []event.Event{
+				event.Normal(reasonDeploymentCreated, "Created deployment 1"),
+				event.Normal(reasonLeaseCreated, "Leased from akash1provider"),
+				event.Normal(reasonServiceReady, "ready"),
+			},
+		},
+		"OutOfOrder": {
+			reason: "A phase older than the latest recorded one should be dropped, until a new deployment is created.",
+			events: []recorded{
+				{0, event.Normal(reasonLeaseCreated, "Leased from akash1provider")},
+				{time.Second, event.Normal(reasonBidReceived, "Bid from akash1provider")},
+				{2 * time.Second, event.Normal(reasonDeploymentCreated, "Created deployment 2")},
+				{3 * time.Second, event.Normal(reasonBidReceived, "Bid from akash1provider")},
+			},
+			want: []event.Event{
+				event.Normal(reasonLeaseCreated, "Leased from akash1provider"),
+				event.Normal(reasonDeploymentCreated, "Created deployment 2"),
+				event.Normal(reasonBidReceived, "Bid from akash1provider"),
+			},
+		},
+		"Duplicates": {
+			reason: "Identical events within the window should be aggregated into the next one recorded after it.",
+			events: []recorded{
+				{0, event.Warning("CannotObserveExternalResource", errors.New("boom"))},
+				{time.Minute, event.Warning("CannotObserveExternalResource", errors.New("boom"))},
+				{2 * time.Minute, event.Warning("CannotObserveExternalResource", errors.New("boom"))},
+				{2 * time.Minute, event.Warning("CannotObserveExternalResource", errors.New("bang"))},
+				{defaultTimelineWindow + time.Minute, event.Warning("CannotObserveExternalResource", errors.New("boom"))},
+			},
+			want: []event.Event{
+				event.Warning("CannotObserveExternalResource", errors.New("boom")),
+				event.Warning("CannotObserveExternalResource", errors.New("bang")),
+				event.Warning("CannotObserveExternalResource", errors.New("boom (repeated 2 times since 2024-05-01T12:00:00Z)")),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			log := &eventLog{}
+			now := start
+			tl := &timeline{record: log, window: defaultTimelineWindow, now: func() time.Time { return now }, objects: &sync.Map{}}
+			cr := &v1alpha1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app", UID: "uid"}}
+			for _, r := range tc.events {
+				now = start.Add(r.after)
+				tl.Event(cr, r.event)
+			}
+			if diff := cmp.Diff(tc.want, log.events); diff != "" {
+				t.Errorf("\n%s\ntimeline.Event(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// fakeLeases serves leases and lease statuses by provider.
+type fakeLeases struct {
+	leases   []akashtypes.Lease
+	statuses map[string]akashtypes.LeaseStatus
+}
+
+func (f *fakeLeases) GetLeases(_ context.Context, _ string, _ string) ([]akashtypes.Lease, error) {
+	return f.leases, nil
+}
+
+func (f *fakeLeases) GetLeaseStatus(_ context.Context, id akashtypes.LeaseId) (akashtypes.LeaseStatus, error) {
+	s, ok := f.statuses[id.Provider]
+	if !ok {
+		return akashtypes.LeaseStatus{}, errors.New("gateway unreachable")
+	}
+	return s, nil
+}
+
+func TestFailoverEvidence(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	lease := func(provider, state string) akashtypes.Lease {
+		return akashtypes.Lease{Lease: akashtypes.LeaseInfo{
+			LeaseId: akashtypes.LeaseId{Provider: provider, Dseq: "42", Gseq: 1, Oseq: 1},
+			State:   state,
+			Price:   akashtypes.BidPrice{Denom: "uakt", Amount: 1.5},
+		}}
+	}
+	cr := &v1alpha1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app"}}
+	cr.Status.AtProvider.Bids = []v1alpha1.BidRecord{{Provider: "akash1healthy", Price: "1.5", Decision: v1alpha1.BidDecisionAccepted}}
+
+	leases := &fakeLeases{
+		leases: []akashtypes.Lease{lease("akash1closed", "closed"), lease("akash1healthy", "active"), lease("akash1failing", "active")},
+		statuses: map[string]akashtypes.LeaseStatus{
+			"akash1healthy": {Services: map[string]akashtypes.ServiceStatus{"web": {Name: "web", Available: 1, Total: 1}}},
+		},
+	}
+	reader := &test.MockClient{MockList: func(_ context.Context, list kubeclient.ObjectList, _ ...kubeclient.ListOption) error {
+		list.(*corev1.EventList).Items = []corev1.Event{{Type: "Warning", Reason: "CannotObserveExternalResource", Message: "boom", Count: 3, LastTimestamp: metav1.NewTime(now)}}
+		return nil
+	}}
+
+	want := &v1alpha1.FailoverRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "app-42-1failing",
+			Labels: map[string]string{v1alpha1.LabelKeyDeployment: "app"},
+		},
+		Spec: v1alpha1.FailoverRecordSpec{
+			Deployment: "app",
+			Dseq:       "42",
+			Provider:   "akash1failing",
+			Reason:     "lease status unavailable",
+			CapturedAt: metav1.NewTime(now),
+			LeaseStatuses: []v1alpha1.LeaseStatusEvidence{
+				{Provider: "akash1healthy", Gseq: 1, Oseq: 1, Response: `{"services":{"web":{"name":"web","available":1,"total":1,"uris":null}},"forwarded_ports":null,"ips":null}`},
+				{Provider: "akash1failing", Gseq: 1, Oseq: 1, GatewayError: "gateway unreachable"},
+			},
+			Leases: []v1alpha1.LeaseEvidence{
+				{Provider: "akash1closed", Gseq: 1, Oseq: 1, State: "closed", Price: "1.5", Denom: "uakt"},
+				{Provider: "akash1healthy", Gseq: 1, Oseq: 1, State: "active", Price: "1.5", Denom: "uakt"},
+				{Provider: "akash1failing", Gseq: 1, Oseq: 1, State: "active", Price: "1.5", Denom: "uakt"},
+			},
+			Bids:   cr.Status.AtProvider.Bids,
+			Events: []string{"2024-05-01T12:00:00Z Warning CannotObserveExternalResource (x3): boom"},
+		},
+	}
+
+	got := failoverEvidence(context.Background(), reader, leases, cr, "42", "akash1owner", "akash1failing", "lease status unavailable", now)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("failoverEvidence(...): -want, +got:\n%s", diff)
+	}
+}
+
+type sentLogs struct {
+	batches [][]forwardedLog
+	err     error
+}
+
+func (s *sentLogs) Send(_ context.Context, lines []forwardedLog) error {
+	s.batches = append(s.batches, lines)
+	return s.err
+}
+
+func TestBatchLogs(t *testing.T) {
+	line := func(msg string) forwardedLog {
+		return forwardedLog{Deployment: "app", Service: "web", Message: msg}
+	}
+
+	cases := map[string]struct {
+		lines   []forwardedLog
+		sinkErr error
+		want    [][]forwardedLog
+		failed  int
+	}{
+		"NoLines": {},
+		"FullAndPartialBatches": {
+			lines: []forwardedLog{line("a"), line("b"), line("c")},
+			want:  [][]forwardedLog{{line("a"), line("b")}, {line("c")}},
+		},
+		"FailingSinkDropsBatches": {
+			lines:   []forwardedLog{line("a"), line("b"), line("c")},
+			sinkErr: errors.New("boom"),
+			want:    [][]forwardedLog{{line("a"), line("b")}, {line("c")}},
+			failed:  2,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			lines := make(chan forwardedLog, len(tc.lines))
+			for _, l := range tc.lines {
+				lines <- l
+			}
+			close(lines)
+
+			sink := &sentLogs{err: tc.sinkErr}
+			failed := 0
+			batchLogs(context.Background(), lines, sink, 2, time.Hour, func(error) { failed++ })
+			if diff := cmp.Diff(tc.want, sink.batches); diff != "" {
+				t.Errorf("batchLogs(...): -want batches, +got batches:\n%s", diff)
+			}
+			if failed != tc.failed {
+				t.Errorf("batchLogs(...): want %d failed batches, got %d", tc.failed, failed)
+			}
+		})
+	}
+}
+
+func TestLokiPayload(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	lines := []forwardedLog{
+		{Time: at, Deployment: "app", Provider: "akash1p", Service: "web", Message: "started"},
+		{Time: at, Deployment: "app", Provider: "akash1p", Service: "db", Message: "ready"},
+		{Time: at.Add(time.Second), Deployment: "app", Provider: "akash1p", Service: "web", Message: "GET /"},
+	}
+
+	want := lokiPush{Streams: []lokiStream{
+		{
+			Stream: map[string]string{"cluster": "prod", "deployment": "app", "provider": "akash1p", "service": "web"},
+			Values: [][2]string{{"1700000000000000000", "started"}, {"1700000001000000000", "GET /"}},
+		},
+		{
+			Stream: map[string]string{"cluster": "prod", "deployment": "app", "provider": "akash1p", "service": "db"},
+			Values: [][2]string{{"1700000000000000000", "ready"}},
+		},
+	}}
+	if diff := cmp.Diff(want, lokiPayload(lines, map[string]string{"cluster": "prod"})); diff != "" {
+		t.Errorf("lokiPayload(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestNextPoll(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	created := func(ago time.Duration) *v1alpha1.Deployment {
+		cr := &v1alpha1.Deployment{}
+		meta.SetExternalCreateSucceeded(cr, now.Add(-ago))
+		return cr
+	}
+
+	cases := map[string]struct {
+		cr   *v1alpha1.Deployment
+		want time.Duration
+	}{
+		"NoHints": {
+			cr:   &v1alpha1.Deployment{},
+			want: time.Minute,
+		},
+		"BidWindowClosing": {
+			cr:   created(40 * time.Second),
+			want: 20*time.Second + blockTime,
+		},
+		"BidWindowClosed": {
+			cr:   created(2 * time.Minute),
+			want: time.Minute,
+		},
+		"BidAccepted": {
+			cr: func() *v1alpha1.Deployment {
+				cr := created(40 * time.Second)
+				cr.Status.AtProvider.Bids = []v1alpha1.BidRecord{{Provider: "akash1provider", Decision: v1alpha1.BidDecisionAccepted}}
+				return cr
+			}(),
+			want: time.Minute,
+		},
+		"ManifestDeliveryRetry": {
+			cr: func() *v1alpha1.Deployment {
+				cr := &v1alpha1.Deployment{}
+				cr.Status.AtProvider.ManifestDelivery = &v1alpha1.ManifestDeliveryStatus{
+					LastAttemptTime: &metav1.Time{Time: now.Add(-4 * time.Second)},
+					LastError:       "boom",
+				}
+				return cr
+			}(),
+			want: time.Second + blockTime,
+		},
+		"MaxPriceWindowClosing": {
+			cr: func() *v1alpha1.Deployment {
+				cr := created(2 * time.Minute)
+				cr.Spec.ForProvider.MaxPrice = &v1alpha1.MaxPrice{Amount: "100", Window: &metav1.Duration{Duration: 150 * time.Second}}
+				return cr
+			}(),
+			want: 30*time.Second + blockTime,
+		},
+		"HintAfterPollInterval": {
+			cr: func() *v1alpha1.Deployment {
+				cr := created(0)
+				cr.Spec.ForProvider.BidCollectionWindow = &metav1.Duration{Duration: time.Hour}
+				return cr
+			}(),
+			want: time.Minute,
+		},
+		"Expiring": {
+			cr: func() *v1alpha1.Deployment {
+				cr := &v1alpha1.Deployment{}
+				cr.Status.AtProvider.ExpiresAt = &metav1.Time{Time: now.Add(10 * time.Second)}
+				return cr
+			}(),
+			want: 10*time.Second + blockTime,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := nextPoll(tc.cr, time.Minute, now)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("nextPoll(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestManifestDrifted(t *testing.T) {
+	sdl := func(image string) string {
+		return `version: "2.0"
+services:
+  web:
+    image: ` + image + `
+profiles:
+  compute:
+    web:
+      resources:
+        cpu:
+          units: 0.5
+        memory:
+          size: 512Mi
+        storage:
+          size: 1Gi
+  placement:
+    dcloud:
+      pricing:
+        web:
+          denom: uakt
+          amount: 1000
+deployment:
+  web:
+    dcloud:
+      profile: web
+      count: 1
+`
+	}
+	_, manifests, err := akashsdl.ParseSDL([]byte(sdl("nginx:1.25")))
+	if err != nil {
+		t.Fatalf("ParseSDL(...): %v", err)
+	}
+	sum, err := akashsdl.ManifestVersion(manifests)
+	if err != nil {
+		t.Fatalf("ManifestVersion(...): %v", err)
+	}
+	version := base64.StdEncoding.EncodeToString(sum)
+
+	cases := map[string]struct {
+		sdl     string
+		version string
+		want    bool
+		wantErr bool
+	}{
+		"Unchanged": {
+			sdl:     sdl("nginx:1.25"),
+			version: version,
+		},
+		"ImageChanged": {
+			sdl:     sdl("nginx:1.26"),
+			version: version,
+			want:    true,
+		},
+		"NoRecordedVersion": {
+			sdl: sdl("nginx:1.26"),
+		},
+		"InvalidVersion": {
+			sdl:     sdl("nginx:1.25"),
+			version: "not base64!",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := manifestDrifted(tc.sdl, tc.version)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("manifestDrifted(...): want error %t, got %v", tc.wantErr, err)
+			}
+			if got != tc.want {
+				t.Errorf("manifestDrifted(...): want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRankBids(t *testing.T) {
+	bid := func(provider string, amount float32) akashtypes.Bid {
+		return akashtypes.Bid{Id: akashtypes.BidId{Provider: provider}, Price: akashtypes.BidPrice{Denom: "uakt", Amount: amount}}
+	}
+	bids := akashtypes.Bids{bid("a", 3), bid("b", 1), bid("c", 2)}
+
+	cases := map[string]struct {
+		colocated string
+		approved  string
+		want      []string
+	}{
+		"Cheapest": {
+			want: []string{"b", "c", "a"},
+		},
+		"Colocated": {
+			colocated: "a",
+			want:      []string{"a", "b", "c"},
+		},
+		"Approved": {
+			colocated: "a",
+			approved:  "c",
+			want:      []string{"c"},
+		},
+		"ApprovedDidNotBid": {
+			approved: "d",
+			want:     []string{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := rankBids(bids, nil, client.NewBidSelectionPolicy(nil), nil, tc.colocated, tc.approved).GetProviderAddresses()
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("rankBids(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWaitingForBids(t *testing.T) {
+	cases := map[string]struct {
+		received int
+		policy   client.BidCollectionPolicy
+		want     string
+	}{
+		"NoBids": {
+			policy: client.BidCollectionPolicy{MinBids: 1, Window: time.Minute},
+			want:   "Waiting for bids: received 0 of 1 within 1m0s",
+		},
+		"CollectingMore": {
+			received: 2,
+			policy:   client.BidCollectionPolicy{MinBids: 3, Window: 5 * time.Minute},
+			want:     "Waiting for bids: received 2 of 3 within 5m0s",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, waitingForBids(tc.received, tc.policy)); diff != "" {
+				t.Errorf("waitingForBids(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestManifestDelivered(t *testing.T) {
+	now := metav1.Now()
+	delivered := &v1alpha1.ManifestDeliveryStatus{Attempts: 1, LastAttemptTime: &now, Provider: "akash1provider", Version: "v1"}
+
+	cases := map[string]struct {
+		md       *v1alpha1.ManifestDeliveryStatus
+		provider string
+		version  string
+		want     bool
+	}{
+		"NeverSent": {
+			provider: "akash1provider",
+			version:  "v1",
+		},
+		"Delivered": {
+			md:       delivered,
+			provider: "akash1provider",
+			version:  "v1",
+			want:     true,
+		},
+		"Failed": {
+			md:       &v1alpha1.ManifestDeliveryStatus{Attempts: 4, LastAttemptTime: &now, LastError: "boom", Provider: "akash1provider", Version: "v1"},
+			provider: "akash1provider",
+			version:  "v1",
+		},
+		"OtherProvider": {
+			md:       delivered,
+			provider: "akash1other",
+			version:  "v1",
+		},
+		"OtherVersion": {
+			md:       delivered,
+			provider: "akash1provider",
+			version:  "v2",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := manifestDelivered(tc.md, tc.provider, tc.version); got != tc.want {
+				t.Errorf("manifestDelivered(...): want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestFilterBids(t *testing.T) {
+	bids := akashtypes.Bids{
+		{Id: akashtypes.BidId{Provider: "a"}},
+		{Id: akashtypes.BidId{Provider: "b"}},
+		{Id: akashtypes.BidId{Provider: "c"}},
+	}
+	providers := map[string]akashtypes.Provider{
+		"a": {Address: "a", Attributes: map[string]string{"region": "us-west", "tier": "community"}},
+		"b": {Address: "b", Attributes: map[string]string{"region": "eu-central", "tier": "premium"}},
+		"c": {Address: "c", Attributes: map[string]string{"region": "us-west", "tier": "premium"}},
+	}
+	auditors := map[string][]string{
+		"a": {"akash1auditor"},
+		"b": {"akash1auditor", "akash1other"},
+	}
+
+	type want struct {
+		eligible []string
+		rejected map[string]string
+	}
+
+	cases := map[string]struct {
+		filter    *v1alpha1.ProviderFilter
+		blacklist map[string]string
+		want      want
+	}{
+		"NoFilter": {
+			want: want{eligible: []string{"a", "b", "c"}, rejected: map[string]string{}},
+		},
+		"Blacklisted": {
+			blacklist: map[string]string{"b": "lease lost"},
+			want:      want{eligible: []string{"a", "c"}, rejected: map[string]string{"b": "blacklisted: lease lost"}},
+		},
+		"AllowAndDeny": {
+			filter: &v1alpha1.ProviderFilter{Allow: []string{"a", "b"}, Deny: []string{"a"}},
+			want: want{eligible: []string{"b"}, rejected: map[string]string{
+				"a": "provider is denied",
+				"c": "provider is not allowed",
+			}},
+		},
+		"Regions": {
+			filter: &v1alpha1.ProviderFilter{Regions: []string{"us-west"}},
+			want:   want{eligible: []string{"a", "c"}, rejected: map[string]string{"b": `region "eu-central" is not allowed`}},
+		},
+		"Attributes": {
+			filter: &v1alpha1.ProviderFilter{Attributes: map[string]string{"tier": "premium"}},
+			want:   want{eligible: []string{"b", "c"}, rejected: map[string]string{"a": "attribute tier=premium is missing"}},
+		},
+		"SignedByAllOf": {
+			filter: &v1alpha1.ProviderFilter{SignedBy: &v1alpha1.SignedBy{AllOf: []string{"akash1auditor", "akash1other"}}},
+			want: want{eligible: []string{"b"}, rejected: map[string]string{
+				"a": "not audited by akash1other",
+				"c": "not audited by akash1auditor",
+			}},
+		},
+		"SignedByAnyOf": {
+			filter: &v1alpha1.ProviderFilter{SignedBy: &v1alpha1.SignedBy{AnyOf: []string{"akash1other", "akash1auditor"}}},
+			want:   want{eligible: []string{"a", "b"}, rejected: map[string]string{"c": "not audited by any of akash1other, akash1auditor"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			eligible, rejected := filterBids(bids, providers, tc.filter, auditors, tc.blacklist)
+			got := want{eligible: eligible.GetProviderAddresses(), rejected: map[string]string{}}
+			for _, r := range rejected {
+				got.rejected[r.bid.Id.Provider] = r.reason
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("filterBids(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAffordableBids(t *testing.T) {
+	bids := akashtypes.Bids{
+		{Id: akashtypes.BidId{Provider: "a"}, Price: akashtypes.BidPrice{Denom: "uakt", Amount: 50}},
+		{Id: akashtypes.BidId{Provider: "b"}, Price: akashtypes.BidPrice{Denom: "uakt", Amount: 100}},
+		{Id: akashtypes.BidId{Provider: "c"}, Price: akashtypes.BidPrice{Denom: "uakt", Amount: 100.5}},
+		{Id: akashtypes.BidId{Provider: "d"}, Price: akashtypes.BidPrice{Denom: "uusdc", Amount: 1}},
+	}
+
+	type want struct {
+		affordable []string
+		rejected   map[string]string
+	}
+
+	cases := map[string]struct {
+		maxPrice *v1alpha1.MaxPrice
+		want     want
+	}{
+		"NoMaxPrice": {
+			want: want{affordable: []string{"a", "b", "c", "d"}, rejected: map[string]string{}},
+		},
+		"MaxPrice": {
+			maxPrice: &v1alpha1.MaxPrice{Amount: "100"},
+			want: want{affordable: []string{"a", "b"}, rejected: map[string]string{
+				"c": "price 100.5uakt exceeds maximum price 100uakt",
+				"d": "price 1uusdc exceeds maximum price 100uakt",
+			}},
+		},
+		"Denom": {
+			maxPrice: &v1alpha1.MaxPrice{Amount: "2", Denom: "uusdc"},
+			want: want{affordable: []string{"d"}, rejected: map[string]string{
+				"a": "price 50uakt exceeds maximum price 2uusdc",
+				"b": "price 100uakt exceeds maximum price 2uusdc",
+				"c": "price 100.5uakt exceeds maximum price 2uusdc",
+			}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			maxPrice, err := client.NewMaxPricePolicy(tc.maxPrice)
+			if err != nil {
+				t.Fatalf("NewMaxPricePolicy(...): %v", err)
+			}
+			affordable, rejected := affordableBids(bids, maxPrice)
+			got := want{affordable: affordable.GetProviderAddresses(), rejected: map[string]string{}}
+			for _, r := range rejected {
+				got.rejected[r.bid.Id.Provider] = r.reason
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("affordableBids(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPublishServices(t *testing.T) {
+	status := akashtypes.MergeLeaseStatuses(
+		akashtypes.LeaseStatus{
+			Services: map[string]akashtypes.ServiceStatus{
+				"web": {Name: "web", Available: 1, Total: 2, URIs: []string{"web.example.com", "www.example.com"}},
+			},
+		},
+		akashtypes.LeaseStatus{
+			Services: map[string]akashtypes.ServiceStatus{
+				"db": {Name: "db", Available: 1, Total: 1},
+			},
+			ForwardedPorts: map[string][]akashtypes.ForwardedPort{
+				"db": {{Host: "node.provider.com", Port: 5432, ExternalPort: 31432, Proto: "TCP"}},
+			},
+		},
+	)
+
+	type want struct {
+		details  managed.ConnectionDetails
+		services []v1alpha1.ServiceStatus
+		ports    []v1alpha1.ForwardedPort
+	}
+
+	cr := &v1alpha1.Deployment{}
+	details := publishServices(cr, status)
+	got := want{details: details, services: cr.Status.AtProvider.Services, ports: cr.Status.AtProvider.ForwardedPorts}
+	w := want{
+		details: managed.ConnectionDetails{
+			"web.uri":     []byte("web.example.com"),
+			"web.uris":    []byte("web.example.com,www.example.com"),
+			"db.5432.tcp": []byte("node.provider.com:31432"),
+		},
+		services: []v1alpha1.ServiceStatus{
+			{Name: "db", Available: 1, Total: 1},
+			{Name: "web", Available: 1, Total: 2, URIs: []string{"web.example.com", "www.example.com"}},
+		},
+		ports: []v1alpha1.ForwardedPort{
+			{Service: "db", Host: "node.provider.com", Port: 5432, ExternalPort: 31432, Protocol: "TCP"},
+		},
+	}
+	if diff := cmp.Diff(w, got, cmp.AllowUnexported(want{})); diff != "" {
+		t.Errorf("publishServices(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestLostLease(t *testing.T) {
+	lease := func(provider, state string) akashtypes.Lease {
+		return akashtypes.Lease{Lease: akashtypes.LeaseInfo{LeaseId: akashtypes.LeaseId{Provider: provider}, State: state}}
+	}
+
+	cases := map[string]struct {
+		leases []akashtypes.Lease
+		want   string
+	}{
+		"NeverLeased": {},
+		"Active": {
+			leases: []akashtypes.Lease{lease("akash1old", "closed"), lease("akash1new", "active")},
+		},
+		"ClosedByProvider": {
+			leases: []akashtypes.Lease{lease("akash1provider", "closed")},
+			want:   "lease with akash1provider was closed",
+		},
+		"InsufficientFunds": {
+			leases: []akashtypes.Lease{lease("akash1provider", "insufficient_funds")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, lostLease(tc.leases)); diff != "" {
+				t.Errorf("lostLease(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestClosedOnChain(t *testing.T) {
+	cases := map[string]struct {
+		cr   *v1alpha1.Deployment
+		want string
+	}{
+		"ClosedOnChain": {
+			cr:   &v1alpha1.Deployment{},
+			want: "deployment 42 was closed on chain",
+		},
+		"ClosedUnleased": {
+			cr: func() *v1alpha1.Deployment {
+				cr := &v1alpha1.Deployment{}
+				cr.SetConditions(v1alpha1.ClosedUnleased(metav1.Duration{Duration: time.Hour}))
+				return cr
+			}(),
+		},
+		"ClosedExpired": {
+			cr: func() *v1alpha1.Deployment {
+				cr := &v1alpha1.Deployment{}
+				cr.SetConditions(v1alpha1.ClosedExpired(metav1.Now()))
+				return cr
+			}(),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, closedOnChain(tc.cr, "42")); diff != "" {
+				t.Errorf("closedOnChain(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFailoverDue(t *testing.T) {
+	deployment := func(failover *v1alpha1.Failover, failures int) *v1alpha1.Deployment {
+		cr := &v1alpha1.Deployment{}
+		cr.Spec.ForProvider.Failover = failover
+		cr.Status.AtProvider.GatewayFailures = failures
+		return cr
+	}
+	probes := 5
+
+	cases := map[string]struct {
+		cr   *v1alpha1.Deployment
+		want bool
+	}{
+		"Disabled": {
+			cr: deployment(nil, 10),
+		},
+		"BelowDefault": {
+			cr: deployment(&v1alpha1.Failover{}, defaultUnreachableProbes-1),
+		},
+		"Default": {
+			cr:   deployment(&v1alpha1.Failover{}, defaultUnreachableProbes),
+			want: true,
+		},
+		"BelowConfigured": {
+			cr: deployment(&v1alpha1.Failover{UnreachableProbes: &probes}, 4),
+		},
+		"Configured": {
+			cr:   deployment(&v1alpha1.Failover{UnreachableProbes: &probes}, 5),
+			want: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := failoverDue(tc.cr); got != tc.want {
+				t.Errorf("failoverDue(...): want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTopUpDue(t *testing.T) {
+	maxTotal := "12000"
+	topUp := &v1alpha1.AutoTopUp{Threshold: "1000.5", Amount: "5000"}
+	capped := &v1alpha1.AutoTopUp{Threshold: "1000.5", Amount: "5000", MaxTotal: &maxTotal}
+
+	type want struct {
+		amount string
+		due    bool
+	}
+
+	cases := map[string]struct {
+		topUp     *v1alpha1.AutoTopUp
+		balance   string
+		deposited string
+		want      want
+	}{
+		"AboveThreshold": {
+			topUp:     topUp,
+			balance:   "1000.5",
+			deposited: "0",
+			want:      want{amount: "0"},
+		},
+		"BelowThreshold": {
+			topUp:     topUp,
+			balance:   "1000.25",
+			deposited: "100000",
+			want:      want{amount: "5000", due: true},
+		},
+		"WithinMaxTotal": {
+			topUp:     capped,
+			balance:   "10",
+			deposited: "5000",
+			want:      want{amount: "5000", due: true},
+		},
+		"CappedByMaxTotal": {
+			topUp:     capped,
+			balance:   "10",
+			deposited: "10000",
+			want:      want{amount: "2000", due: true},
+		},
+		"MaxTotalReached": {
+			topUp:     capped,
+			balance:   "10",
+			deposited: "12000",
+			want:      want{amount: "0", due: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			amount, due := topUpDue(tc.topUp, akashtypes.EscrowAccountBalance{Denom: "uakt", Amount: tc.balance}, tc.deposited)
+			got := want{amount: amount.String(), due: due}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("topUpDue(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLateInitialize(t *testing.T) {
+	owner := "akash1owner"
+	depositor := "akash1depositor"
+	usdc := "ibc/170C677610AC31DF0904FFE09CD3B5C657492170E7E52372E48756B71E56F2F1"
+	onChain := akashtypes.Deployment{
+		DeploymentInfo: akashtypes.DeploymentInfo{DeploymentId: akashtypes.DeploymentId{Dseq: "42", Owner: owner}},
+		Groups: []akashtypes.Group{
+			{GroupSpec: akashtypes.GroupSpec{Requirements: akashtypes.PlacementRequirements{
+				SignedBy:   akashtypes.SignedByRequirement{AnyOf: []string{"akash1auditor"}},
+				Attributes: []akashtypes.Attribute{{Key: "region", Value: "us-west"}},
+			}}},
+			{GroupSpec: akashtypes.GroupSpec{Requirements: akashtypes.PlacementRequirements{
+				Attributes: []akashtypes.Attribute{{Key: "region", Value: "eu-central"}, {Key: "tier", Value: "community"}},
+			}}},
+		},
+		EscrowAccount: akashtypes.EscrowAccount{Owner: owner, Depositor: depositor, Balance: akashtypes.EscrowAccountBalance{Denom: usdc, Amount: "100"}},
+	}
+	bare := akashtypes.Deployment{
+		DeploymentInfo: akashtypes.DeploymentInfo{DeploymentId: akashtypes.DeploymentId{Dseq: "42", Owner: owner}},
+		EscrowAccount:  akashtypes.EscrowAccount{Owner: owner, Depositor: owner, Balance: akashtypes.EscrowAccountBalance{Denom: "uakt"}},
+	}
+	other := "akash1other"
+
+	type want struct {
+		params  v1alpha1.DeploymentParameters
+		changed bool
+	}
+
+	cases := map[string]struct {
+		params     v1alpha1.DeploymentParameters
+		deployment akashtypes.Deployment
+		want       want
+	}{
+		"Unset": {
+			deployment: onChain,
+			want: want{
+				params: v1alpha1.DeploymentParameters{
+					Owner:     &owner,
+					Deposit:   &v1alpha1.Deposit{Amount: "5000000", Denom: usdc},
+					Depositor: &depositor,
+					ProviderFilter: &v1alpha1.ProviderFilter{
+						Attributes: map[string]string{"region": "us-west", "tier": "community"},
+						SignedBy:   &v1alpha1.SignedBy{AnyOf: []string{"akash1auditor"}},
+					},
+				},
+				changed: true,
+			},
+		},
+		"DepositDenomUnset": {
+			params:     v1alpha1.DeploymentParameters{Owner: &owner, Deposit: &v1alpha1.Deposit{Amount: "100"}},
+			deployment: bare,
+			want: want{
+				params:  v1alpha1.DeploymentParameters{Owner: &owner, Deposit: &v1alpha1.Deposit{Amount: "100", Denom: "uakt"}},
+				changed: true,
+			},
+		},
+		"AlreadySet": {
+			params: v1alpha1.DeploymentParameters{
+				Owner:     &other,
+				Deposit:   &v1alpha1.Deposit{Amount: "100", Denom: "uakt"},
+				Depositor: &other,
+				ProviderFilter: &v1alpha1.ProviderFilter{
+					Attributes: map[string]string{"region": "ap-south"},
+					SignedBy:   &v1alpha1.SignedBy{AllOf: []string{"akash1other"}},
+				},
+			},
+			deployment: onChain,
+			want: want{
+				params: v1alpha1.DeploymentParameters{
+					Owner:     &other,
+					Deposit:   &v1alpha1.Deposit{Amount: "100", Denom: "uakt"},
+					Depositor: &other,
+					ProviderFilter: &v1alpha1.ProviderFilter{
+						Attributes: map[string]string{"region": "ap-south"},
+						SignedBy:   &v1alpha1.SignedBy{AllOf: []string{"akash1other"}},
+					},
+				},
+			},
+		},
+		"FilterWithoutPlacement": {
+			params:     v1alpha1.DeploymentParameters{Owner: &owner, Deposit: &v1alpha1.Deposit{Amount: "100", Denom: "uakt"}},
+			deployment: bare,
+			want: want{
+				params: v1alpha1.DeploymentParameters{Owner: &owner, Deposit: &v1alpha1.Deposit{Amount: "100", Denom: "uakt"}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &v1alpha1.Deployment{Spec: v1alpha1.DeploymentSpec{ForProvider: tc.params}}
+			changed := lateInitialize(cr, tc.deployment)
+			got := want{params: cr.Spec.ForProvider, changed: changed}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("lateInitialize(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+// observedDeployment reports that the deployment exists without querying the
+// chain, leaving Delete to the external client.
+type observedDeployment struct {
+	*external
+}
+
+func (observedDeployment) Observe(context.Context, resource.Managed) (managed.ExternalObservation, error) {
+	return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, nil
+}
+
+func TestDeletionPolicy(t *testing.T) {
+	// The node counts the requests it fails.
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		policy xpv1.DeletionPolicy
+		// closes is whether the deployment is closed on chain, which the
+		// node fails to do.
+		closes bool
+	}{
+		"Delete": {policy: xpv1.DeletionDelete, closes: true},
+		"Orphan": {policy: xpv1.DeletionOrphan},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var requests atomic.Int32
+			node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer node.Close()
+
+			cr := &v1alpha1.Deployment{}
+			cr.SetName("example")
+			cr.SetUID(types.UID("uid-" + name))
+			cr.SetDeletionPolicy(tc.policy)
+			cr.SetFinalizers([]string{managed.FinalizerName})
+			cr.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+			meta.SetExternalName(cr, "1234")
+			timelines.Store(cr.GetUID(), &objectTimeline{seen: map[string]*seenEvent{}})
+
+			kube := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cr).WithStatusSubresource(cr).Build()
+			ak := client.New(context.Background(), client.AkashProviderConfiguration{Creds: []byte("c4a48e2fce1481cd3294b4490f6678090ea98d3d0e5cd984558ab0968741b104"), Node: node.URL})
+			r := managed.NewReconciler(&xpfake.Manager{Client: kube, Scheme: scheme},
+				resource.ManagedKind(v1alpha1.DeploymentGroupVersionKind),
+				managed.WithExternalConnecter(managed.ExternalConnectorFn(func(context.Context, resource.Managed) (managed.ExternalClient, error) {
+					return observedDeployment{&external{service: &DeploymentService{client: ak}, recorder: event.NewNopRecorder()}}, nil
+				})),
+				managed.WithFinalizer(timelineFinalizer{resource.NewAPIFinalizer(kube, managed.FinalizerName)}))
+
+			if _, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "example"}}); err != nil {
+				t.Fatalf("Reconcile(...): %v", err)
+			}
+
+			if got := requests.Load() > 0; got != tc.closes {
+				t.Errorf("Reconcile(...): want the node asked to close the deployment %t, got %d requests", tc.closes, requests.Load())
+			}
+			if tc.closes {
+				return
+			}
+			if err := kube.Get(context.Background(), types.NamespacedName{Name: "example"}, &v1alpha1.Deployment{}); !kerrors.IsNotFound(err) {
+				t.Errorf("Reconcile(...): want the finalizer of an orphaned deployment removed, got %v", err)
+			}
+			if _, ok := timelines.Load(cr.GetUID()); ok {
+				t.Error("Reconcile(...): want the timeline of an orphaned deployment forgotten")
+			}
+		})
+	}
+}
+
+func TestForwardLogs(t *testing.T) {
+	var mu sync.Mutex
+	var got []string
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var lines []httpLogLine
+		if err := json.NewDecoder(r.Body).Decode(&lines); err != nil {
+			t.Errorf("cannot decode posted lines: %v", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, l := range lines {
+			got = append(got, l.Provider+": "+l.Message)
+		}
+	}))
+	defer sink.Close()
+
+	kube := &test.MockClient{MockGet: func(_ context.Context, _ kubeclient.ObjectKey, obj kubeclient.Object) error {
+		(&apisv1alpha1.ProviderConfig{ObjectMeta: metav1.ObjectMeta{Name: "default"}}).DeepCopyInto(obj.(*apisv1alpha1.ProviderConfig))
+		return nil
+	}}
+	r := &logForwarderReconciler{
+		kube: kube,
+		log:  logging.NewNopLogger(),
+		newClient: func(context.Context, kubeclient.Client, resource.Managed, client.ProviderConfigInfo) (client.AkashAPI, error) {
+			// Each client counts the lines it streamed, which races when a
+			// client is shared by the streams of several leases.
+			streamed := 0
+			return &akashfake.Client{
+				MockStreamLeaseLogs: func(_ context.Context, _ client.Seqs, provider string, _ gateway.LogOptions, fn func(gateway.LogLine) error) error {
+					for i := 0; i < 10; i++ {
+						streamed++
+						if err := fn(gateway.LogLine{Name: "web-5d8f7c9b6-x2x7k", Message: strconv.Itoa(streamed)}); err != nil {
+							return err
+						}
+					}
+					return nil
+				},
+			}, nil
+		},
+	}
+
+	cr := &v1alpha1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web"}}
+	cr.Spec.ProviderConfigReference = &xpv1.Reference{Name: "default"}
+	cr.Spec.ForProvider.LogForwarding = &v1alpha1.LogForwarding{Sink: &v1alpha1.LogSink{Type: v1alpha1.LogSinkHTTP, URL: sink.URL}}
+	leases := []akashtypes.Lease{
+		{Lease: akashtypes.LeaseInfo{LeaseId: akashtypes.LeaseId{Gseq: 1, Oseq: 1, Provider: "akash1a"}}},
+		{Lease: akashtypes.LeaseInfo{LeaseId: akashtypes.LeaseId{Gseq: 2, Oseq: 1, Provider: "akash1b"}}},
+	}
+
+	done := make(chan struct{})
+	r.forward(context.Background(), cr, "42", leases, done)
+
+	var want []string
+	for _, provider := range []string{"akash1a", "akash1b"} {
+		for i := 1; i <= 10; i++ {
+			want = append(want, provider+": "+strconv.Itoa(i))
+		}
+	}
+	sort.Strings(got)
+	sort.Strings(want)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("forward(...): -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+const (
+	errInvalidHeight    = "invalid block height"
+	errNoExternalName   = "deployment has no external name"
+	errQueryAtHeight    = "cannot query deployment at height"
+	errRemoveAnnotation = "cannot remove diagnostic annotation"
+	errSupportBundle    = "cannot write support bundle"
+
+	reasonInspectAtHeight event.Reason = "InspectAtHeight"
+	reasonSupportBundle   event.Reason = "SupportBundle"
+
+	diagnosticsController = "diagnostics"
+)
+
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=deployments,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;create;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=list;create;patch
+
+// SetupDiagnostics adds a controller that runs the diagnostic queries
+// requested through annotations on Deployments.
+func SetupDiagnostics(mgr ctrl.Manager, o controller.Options) error {
+	name := diagnosticsController + "/" + v1alpha1.DeploymentGroupKind
+
+	r := &diagnosticsReconciler{
+		kube:      mgr.GetClient(),
+		reader:    mgr.GetAPIReader(),
+		log:       o.Logger.WithValues("controller", name),
+		record:    event.NewAPIRecorder(mgr.GetEventRecorderFor(name)),
+		newClient: newUntrackedClient,
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Deployment{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+type diagnosticsReconciler struct {
+	kube      kubeclient.Client
+	reader    kubeclient.Reader
+	log       logging.Logger
+	record    event.Recorder
+	newClient func(ctx context.Context, kube kubeclient.Client, mg resource.Managed, pcInfo client.ProviderConfigInfo) (client.AkashAPI, error)
+}
+
+// Reconcile runs the diagnostics requested through annotations on a
+// Deployment, records their results and removes the annotations so they can
+// be requested again.
+func (r *diagnosticsReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	cr := &v1alpha1.Deployment{}
+	if err := r.kube.Get(ctx, req.NamespacedName, cr); err != nil {
+		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetDeployment)
+	}
+
+	height, inspect := cr.GetAnnotations()[v1alpha1.AnnotationKeyInspectAtHeight]
+	namespace, bundle := cr.GetAnnotations()[v1alpha1.AnnotationKeySupportBundle]
+	if (!inspect && !bundle) || cr.GetDeletionTimestamp() != nil {
+		return reconcile.Result{}, nil
+	}
+
+	log := r.log.WithValues("deployment", cr.GetName())
+
+	if inspect {
+		if msg, err := r.inspectAtHeight(ctx, cr, height); err != nil {
+			log.Debug("Diagnostic query failed", "height", height, "error", err)
+			r.record.Event(cr, event.Warning(reasonInspectAtHeight, err))
+		} else {
+			r.record.Event(cr, event.Normal(reasonInspectAtHeight, msg))
+		}
+	}
+
+	if bundle {
+		if name, err := r.writeSupportBundle(ctx, cr, namespace); err != nil {
+			log.Debug("Cannot write support bundle", "namespace", namespace, "error", err)
+			r.record.Event(cr, event.Warning(reasonSupportBundle, err))
+		} else {
+			r.record.Event(cr, event.Normal(reasonSupportBundle, "Wrote support bundle to ConfigMap "+namespace+"/"+name))
+		}
+	}
+
+	meta.RemoveAnnotations(cr, v1alpha1.AnnotationKeyInspectAtHeight, v1alpha1.AnnotationKeySupportBundle)
+	return reconcile.Result{}, errors.Wrap(r.kube.Update(ctx, cr), errRemoveAnnotation)
+}
+
+// connect returns a client for the ProviderConfig referenced by cr.
+func (r *diagnosticsReconciler) connect(ctx context.Context, cr *v1alpha1.Deployment) (client.AkashAPI, error) {
+	return connect(ctx, r.kube, cr, r.newClient)
+}
+
+func (r *diagnosticsReconciler) inspectAtHeight(ctx context.Context, cr *v1alpha1.Deployment, value string) (string, error) {
+	height, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || height <= 0 {
+		return "", errors.Errorf("%s: %q", errInvalidHeight, value)
+	}
+
+	dseq := externalDseq(cr)
+	if dseq == "" || dseq == cr.GetName() {
+		return "", errors.New(errNoExternalName)
+	}
+
+	ak, err := r.connect(ctx, cr)
+	if err != nil {
+		return "", err
+	}
+
+	owner, err := deploymentOwner(ak, cr)
+	if err != nil {
+		return "", err
+	}
+	deployment, err := ak.GetDeploymentAtHeight(ctx, dseq, owner, height)
+	if err != nil {
+		return "", errors.Wrap(err, errQueryAtHeight)
+	}
+
+	leases, err := ak.GetLeasesAtHeight(ctx, dseq, owner, height)
+	if err != nil {
+		return "", errors.Wrap(err, errQueryAtHeight)
+	}
+
+	return describeAtHeight(height, deployment, leases), nil
+}
+
+// describeAtHeight summarises the state of a deployment and its leases at a
+// block height in a single line suitable for an event message.
+func describeAtHeight(height int64, deployment akashtypes.Deployment, leases []akashtypes.Lease) string {
+	escrow := deployment.EscrowAccount.Balance
+	msg := fmt.Sprintf("At height %d deployment %s was %s with escrow balance %s%s",
+		height, deployment.DeploymentInfo.DeploymentId.Dseq, deployment.DeploymentInfo.State, escrow.Amount, escrow.Denom)
+
+	if len(leases) == 0 {
+		return msg + " and had no leases"
+	}
+
+	parts := make([]string, 0, len(leases))
+	for _, l := range leases {
+		id := l.Lease.LeaseId
+		parts = append(parts, fmt.Sprintf("%d/%d with %s %s at %g%s", id.Gseq, id.Oseq, id.Provider, l.Lease.State, l.Lease.Price.Amount, l.Lease.Price.Denom))
+	}
+	return msg + "; leases: " + strings.Join(parts, ", ")
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/pkg/errors"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/sdl"
+)
+
+const (
+	errDecodeVersion = "cannot decode the manifest version of the deployment"
+	errSDLVersion    = "cannot compute the manifest version of the SDL"
+)
+
+// desiredSDL returns the SDL cr should be deployed with: its resolved SDL,
+// verified against its checksum, with the passthrough environment injected.
+func desiredSDL(ctx context.Context, kube kubeclient.Reader, cr *v1alpha1.Deployment) (string, error) {
+	source, err := resolveSDL(ctx, kube, cr)
+	if err != nil {
+		return "", err
+	}
+	if err := verifySDLChecksum(source, cr.Spec.ForProvider.SDLChecksum); err != nil {
+		return "", err
+	}
+	return renderSDL(cr, source)
+}
+
+// manifestDrifted reports whether the manifest of content differs from the
+// one of a deployment, identified by the base64 encoded version recorded on
+// chain. Deployments without a recorded version are never considered
+// drifted, as there is nothing to compare with.
+func manifestDrifted(content string, version string) (bool, error) {
+	if version == "" {
+		return false, nil
+	}
+	if _, err := base64.StdEncoding.DecodeString(version); err != nil {
+		return false, errors.Wrap(err, errDecodeVersion)
+	}
+
+	desired, err := manifestVersion(content)
+	if err != nil {
+		return false, err
+	}
+	return version != desired, nil
+}
+
+// manifestVersion returns the base64 encoded version of the manifest of
+// content, as recorded on chain.
+func manifestVersion(content string) (string, error) {
+	_, manifests, err := sdl.ParseSDL([]byte(content))
+	if err != nil {
+		return "", errors.Wrap(err, errSDLVersion)
+	}
+	version, err := sdl.ManifestVersion(manifests)
+	if err != nil {
+		return "", errors.Wrap(err, errSDLVersion)
+	}
+	return base64.StdEncoding.EncodeToString(version), nil
+}
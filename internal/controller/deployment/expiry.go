@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+	"github.com/overlock-network/provider-akash/internal/features"
+)
+
+const (
+	errCloseExpired  = "cannot close expired deployment"
+	errUpdateExpired = "cannot update expiry status"
+
+	reasonExpired event.Reason = "Expired"
+
+	expiryController = "expiry"
+)
+
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=deployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=deployments/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// SetupExpiry adds a controller that closes Deployments once their TTL
+// elapsed or their closeAt time passed.
+func SetupExpiry(mgr ctrl.Manager, o controller.Options) error {
+	name := expiryController + "/" + v1alpha1.DeploymentGroupKind
+
+	r := &expiryReconciler{
+		kube:      mgr.GetClient(),
+		log:       o.Logger.WithValues("controller", name),
+		record:    event.NewAPIRecorder(mgr.GetEventRecorderFor(name)),
+		newClient: newUntrackedClient,
+
+		managementPolicies: o.Features.Enabled(features.EnableAlphaManagementPolicies),
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Deployment{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+type expiryReconciler struct {
+	kube      kubeclient.Client
+	log       logging.Logger
+	record    event.Recorder
+	newClient func(ctx context.Context, kube kubeclient.Client, mg resource.Managed, pcInfo client.ProviderConfigInfo) (client.AkashAPI, error)
+
+	// managementPolicies is whether management policies are honored.
+	managementPolicies bool
+}
+
+// Reconcile records when a deployment expires, requeues it until then and
+// closes it once it is due.
+func (r *expiryReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	cr := &v1alpha1.Deployment{}
+	if err := r.kube.Get(ctx, req.NamespacedName, cr); err != nil {
+		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetDeployment)
+	}
+
+	dseq := externalDseq(cr)
+	if cr.GetDeletionTimestamp() != nil || dseq == "" || dseq == cr.GetName() {
+		return reconcile.Result{}, nil
+	}
+	if cr.GetCondition(v1alpha1.TypeClosed).Status == corev1.ConditionTrue {
+		return reconcile.Result{}, nil
+	}
+
+	at := expiresAt(cr)
+	if !at.Equal(cr.Status.AtProvider.ExpiresAt) {
+		cr.Status.AtProvider.ExpiresAt = at
+		if err := r.kube.Status().Update(ctx, cr); err != nil {
+			return reconcile.Result{}, errors.Wrap(err, errUpdateExpired)
+		}
+	}
+	if at == nil {
+		return reconcile.Result{}, nil
+	}
+	if wait := time.Until(at.Time); wait > 0 {
+		return reconcile.Result{RequeueAfter: wait}, nil
+	}
+
+	// Closing the deployment amounts to deleting it.
+	if !mayAct(r.managementPolicies, cr, xpv1.ManagementActionDelete) {
+		return reconcile.Result{}, nil
+	}
+
+	ak, err := connect(ctx, r.kube, cr, r.newClient)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	owner, err := deploymentOwner(ak, cr)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := closable(ak, cr, dseq, owner); err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := ak.DeleteDeployment(ctx, dseq, owner); err != nil {
+		return reconcile.Result{}, errors.Wrap(err, errCloseExpired)
+	}
+
+	r.log.Debug("Closed expired deployment", "deployment", cr.GetName(), "dseq", dseq)
+	r.record.Event(cr, event.Normal(reasonExpired, "Closed deployment "+dseq+" which expired at "+at.Format(time.RFC3339)))
+	cr.SetConditions(v1alpha1.ClosedExpired(*at))
+	return reconcile.Result{}, errors.Wrap(r.kube.Status().Update(ctx, cr), errUpdateExpired)
+}
+
+// expiresAt returns when cr is closed, the earlier of its closeAt time and the
+// end of its TTL, which starts when the deployment was created on chain, or
+// nil if neither is set.
+func expiresAt(cr *v1alpha1.Deployment) *metav1.Time {
+	p := cr.Spec.ForProvider
+	var at *metav1.Time
+	if p.CloseAt != nil {
+		at = p.CloseAt.DeepCopy()
+	}
+	if p.TTL != nil {
+		// Deployments observed rather than created by the controller are
+		// aged from the creation of the resource.
+		created := meta.GetExternalCreateSucceeded(cr)
+		if created.IsZero() {
+			created = cr.GetCreationTimestamp().Time
+		}
+		if end := created.Add(p.TTL.Duration); at == nil || end.Before(at.Time) {
+			at = &metav1.Time{Time: end}
+		}
+	}
+	return at
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	client "github.com/overlock-network/provider-akash/internal/client"
+)
+
+const (
+	errExternalName  = "external name %q is neither a dseq nor of the form owner/dseq"
+	errNotOwner      = "refusing to close deployment %s of %s, which is not the account %s of the ProviderConfig, unless allowClosingUnowned is set"
+	errUpdateUnowned = "refusing to update deployment %s of %s, which is not the account %s of the ProviderConfig"
+	errCloseDeleted  = "cannot close deleted deployment"
+)
+
+// parseExternalName returns the owner and dseq of the deployment an external
+// name refers to. The external name is the dseq of a deployment of the
+// account of the ProviderConfig, e.g. 12345, in which case the owner is
+// empty, or the owner and dseq of an imported deployment, e.g.
+// akash1.../12345. The dseq is the whole external name when it is invalid.
+func parseExternalName(name string) (string, string, error) {
+	owner, dseq, imported := strings.Cut(name, "/")
+	if !imported {
+		return "", name, nil
+	}
+	if !strings.HasPrefix(owner, "akash1") {
+		return "", name, errors.Errorf(errExternalName, name)
+	}
+	if _, err := strconv.ParseUint(dseq, 10, 64); err != nil {
+		return "", name, errors.Errorf(errExternalName, name)
+	}
+	return owner, dseq, nil
+}
+
+// externalDseq returns the dseq of the deployment of a Deployment, which is
+// the name of the Deployment until the deployment is created.
+func externalDseq(o metav1.Object) string {
+	_, dseq, _ := parseExternalName(meta.GetExternalName(o))
+	return dseq
+}
+
+// deploymentOwner returns the owner of the deployment of cr: the owner named
+// by its external name when it was imported, the account of the
+// ProviderConfig otherwise.
+func deploymentOwner(ak client.AkashAPI, cr *v1alpha1.Deployment) (string, error) {
+	owner, _, err := parseExternalName(meta.GetExternalName(cr))
+	if err != nil || owner != "" {
+		return owner, err
+	}
+	return ak.AccountAddress()
+}
+
+// deploymentAccount returns the owner of the deployment of cr, as
+// deploymentOwner does, and the account of the ProviderConfig, which only
+// acts on the deployments it owns.
+func deploymentAccount(ak client.AkashAPI, cr *v1alpha1.Deployment) (string, string, error) {
+	account, err := ak.AccountAddress()
+	if err != nil {
+		return "", "", err
+	}
+	owner, _, err := parseExternalName(meta.GetExternalName(cr))
+	if owner == "" {
+		owner = account
+	}
+	return owner, account, err
+}
+
+// closable returns an error unless the deployment dseq of owner may be closed
+// on behalf of cr by the account of ak.
+func closable(ak client.AkashAPI, cr *v1alpha1.Deployment, dseq, owner string) error {
+	account, err := ak.AccountAddress()
+	if err != nil {
+		return err
+	}
+	return mayClose(cr, dseq, owner, account)
+}
+
+// mayClose returns an error unless the deployment dseq of owner may be closed
+// by account. Imported deployments of other owners are only closed when cr
+// explicitly allows it.
+func mayClose(cr *v1alpha1.Deployment, dseq, owner, account string) error {
+	if owner == account {
+		return nil
+	}
+	if allow := cr.Spec.ForProvider.AllowClosingUnowned; allow != nil && *allow {
+		return nil
+	}
+	return errors.Errorf(errNotOwner, dseq, owner, account)
+}
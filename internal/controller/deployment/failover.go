@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+const (
+	errRecordFailover = "cannot record failover evidence"
+
+	// failoverProviderSuffix is the length of the suffix of a provider
+	// address naming its failover records.
+	failoverProviderSuffix = 8
+)
+
+// leaseReader reads the leases of a deployment and their status.
+type leaseReader interface {
+	GetLeases(ctx context.Context, dseq string, owner string) ([]akashtypes.Lease, error)
+	GetLeaseStatus(ctx context.Context, id akashtypes.LeaseId) (akashtypes.LeaseStatus, error)
+}
+
+// failoverRecordName returns the name of the FailoverRecord of cr leaving
+// provider. Evidence is captured once per deployment and provider.
+func failoverRecordName(cr *v1alpha1.Deployment, dseq string, provider string) string {
+	name := cr.GetName() + "-" + dseq
+	if provider == "" {
+		return name
+	}
+	if len(provider) > failoverProviderSuffix {
+		provider = provider[len(provider)-failoverProviderSuffix:]
+	}
+	return name + "-" + provider
+}
+
+// failoverEvidence captures the last lease statuses, chain leases, bid
+// history and recent events of cr before it leaves provider, or tears down
+// the deployment when provider is empty. Evidence that cannot be captured is
+// described in the record rather than failing it, as it matters most when
+// things go wrong.
+func failoverEvidence(ctx context.Context, reader kubeclient.Reader, leases leaseReader, cr *v1alpha1.Deployment, dseq, owner, provider, reason string, now time.Time) *v1alpha1.FailoverRecord {
+	rec := &v1alpha1.FailoverRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   failoverRecordName(cr, dseq, provider),
+			Labels: map[string]string{v1alpha1.LabelKeyDeployment: cr.GetName()},
+		},
+		Spec: v1alpha1.FailoverRecordSpec{
+			Deployment: cr.GetName(),
+			Dseq:       dseq,
+			Provider:   provider,
+			Reason:     reason,
+			CapturedAt: metav1.NewTime(now),
+			Bids:       cr.Status.AtProvider.Bids,
+		},
+	}
+
+	onChain, err := leases.GetLeases(ctx, dseq, owner)
+	if err != nil {
+		rec.Spec.Errors = append(rec.Spec.Errors, "leases: "+err.Error())
+	}
+	for _, l := range onChain {
+		id := l.Lease.LeaseId
+		rec.Spec.Leases = append(rec.Spec.Leases, v1alpha1.LeaseEvidence{
+			Provider:  id.Provider,
+			Gseq:      id.Gseq,
+			Oseq:      id.Oseq,
+			State:     l.Lease.State,
+			Price:     client.FormatBidPrice(l.Lease.Price),
+			Denom:     l.Lease.Price.Denom,
+			CreatedAt: l.Lease.CreatedAt,
+			ClosedOn:  l.Lease.ClosedOn,
+		})
+		if l.Lease.State != akashtypes.LeaseStateActive {
+			continue
+		}
+
+		e := v1alpha1.LeaseStatusEvidence{Provider: id.Provider, Gseq: id.Gseq, Oseq: id.Oseq}
+		status, err := leases.GetLeaseStatus(ctx, id)
+		if err != nil {
+			e.GatewayError = err.Error()
+		} else if b, err := json.Marshal(status); err == nil {
+			e.Response = string(b)
+		}
+		rec.Spec.LeaseStatuses = append(rec.Spec.LeaseStatuses, e)
+	}
+
+	events, err := recentEvents(ctx, reader, cr)
+	if err != nil {
+		rec.Spec.Errors = append(rec.Spec.Errors, "events: "+err.Error())
+	} else if events != "" {
+		rec.Spec.Events = strings.Split(strings.TrimSuffix(events, "\n"), "\n")
+	}
+
+	return rec
+}
+
+// recordFailover captures the evidence of cr leaving provider into a
+// FailoverRecord. Evidence already recorded for the provider is kept.
+func recordFailover(ctx context.Context, kube kubeclient.Client, reader kubeclient.Reader, leases leaseReader, cr *v1alpha1.Deployment, dseq, owner, provider, reason string) error {
+	rec := failoverEvidence(ctx, reader, leases, cr, dseq, owner, provider, reason, time.Now())
+	return errors.Wrap(resource.Ignore(kerrors.IsAlreadyExists, kube.Create(ctx, rec)), errRecordFailover)
+}
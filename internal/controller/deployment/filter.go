@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+const (
+	errGetAuditors    = "cannot get the auditors of provider %s"
+	errNoEligibleBids = "none of the bids passes the provider filter: %d rejected"
+
+	reasonNoEligibleBids event.Reason = "NoEligibleBids"
+)
+
+// A rejectedBid is a bid excluded from selection, and why.
+type rejectedBid struct {
+	bid    akashtypes.Bid
+	reason string
+}
+
+// eligibleBids splits bids into those of providers that pass filter f and
+// are not blacklisted, and the rejected others. The auditors of the bidding
+// providers are queried when f requires them.
+func eligibleBids(ctx context.Context, ak client.AkashAPI, bids akashtypes.Bids, providers map[string]akashtypes.Provider, f *v1alpha1.ProviderFilter) (akashtypes.Bids, []rejectedBid, error) {
+	blacklist, err := ak.BlacklistedProviders()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var auditors map[string][]string
+	if f != nil && f.SignedBy != nil {
+		auditors = make(map[string][]string, len(bids))
+		for _, b := range bids {
+			a, err := ak.GetProviderAuditors(ctx, b.Id.Provider)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, errGetAuditors, b.Id.Provider)
+			}
+			auditors[b.Id.Provider] = a
+		}
+	}
+
+	eligible, rejected := filterBids(bids, providers, f, auditors, blacklist)
+	return eligible, rejected, nil
+}
+
+// affordableBids splits bids into those within the maximum price and the
+// rejected others.
+func affordableBids(bids akashtypes.Bids, maxPrice *client.MaxPricePolicy) (akashtypes.Bids, []rejectedBid) {
+	affordable := make(akashtypes.Bids, 0, len(bids))
+	var rejected []rejectedBid
+	for _, b := range bids {
+		if maxPrice.Exceeds(b.Price) {
+			reason := fmt.Sprintf("price %s%s exceeds maximum price %s", client.FormatBidPrice(b.Price), b.Price.Denom, maxPrice)
+			rejected = append(rejected, rejectedBid{bid: b, reason: reason})
+			continue
+		}
+		affordable = append(affordable, b)
+	}
+	return affordable, rejected
+}
+
+// filterBids splits bids into those of providers that pass filter f and are
+// not blacklisted, and the rejected others, given the providers and their
+// auditors by address.
+func filterBids(bids akashtypes.Bids, providers map[string]akashtypes.Provider, f *v1alpha1.ProviderFilter, auditors map[string][]string, blacklist map[string]string) (akashtypes.Bids, []rejectedBid) {
+	eligible := make(akashtypes.Bids, 0, len(bids))
+	var rejected []rejectedBid
+	for _, b := range bids {
+		address := b.Id.Provider
+		reason := providerRejection(address, providers[address], f, auditors[address])
+		if why, ok := blacklist[address]; ok && reason == "" {
+			reason = "blacklisted: " + why
+		}
+		if reason != "" {
+			rejected = append(rejected, rejectedBid{bid: b, reason: reason})
+			continue
+		}
+		eligible = append(eligible, b)
+	}
+	return eligible, rejected
+}
+
+// providerRejection returns why the provider at address fails filter f given
+// its auditors, or an empty string if it passes.
+func providerRejection(address string, p akashtypes.Provider, f *v1alpha1.ProviderFilter, auditors []string) string {
+	if f == nil {
+		return ""
+	}
+	if slices.Contains(f.Deny, address) {
+		return "provider is denied"
+	}
+	if len(f.Allow) > 0 && !slices.Contains(f.Allow, address) {
+		return "provider is not allowed"
+	}
+	if len(f.Regions) > 0 && !slices.Contains(f.Regions, p.Region()) {
+		return fmt.Sprintf("region %q is not allowed", p.Region())
+	}
+	keys := make([]string, 0, len(f.Attributes))
+	for key := range f.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if v, ok := p.Attributes[key]; !ok || v != f.Attributes[key] {
+			return fmt.Sprintf("attribute %s=%s is missing", key, f.Attributes[key])
+		}
+	}
+	if s := f.SignedBy; s != nil {
+		for _, a := range s.AllOf {
+			if !slices.Contains(auditors, a) {
+				return "not audited by " + a
+			}
+		}
+		if len(s.AnyOf) > 0 && !slices.ContainsFunc(s.AnyOf, func(a string) bool { return slices.Contains(auditors, a) }) {
+			return "not audited by any of " + strings.Join(s.AnyOf, ", ")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+)
+
+const (
+	// fleetListTimeout bounds listing Deployments while metrics are scraped.
+	fleetListTimeout = 10 * time.Second
+
+	// averageBlockTime and averageMonth project spend per block to a month.
+	averageBlockTime = 6098 * time.Millisecond
+	averageMonth     = 30437 * 24 * time.Hour / 1000
+
+	fleetStateUnknown = "Unknown"
+	fleetStateClosed  = "Closed"
+)
+
+var (
+	fleetDeployments = prometheus.NewDesc("akash_fleet_deployments",
+		"Number of Deployments by ProviderConfig and state.",
+		[]string{"provider_config", "state"}, nil)
+	fleetFailing = prometheus.NewDesc("akash_fleet_failing_deployments",
+		"Number of Deployments whose last reconcile failed, by ProviderConfig.",
+		[]string{"provider_config"}, nil)
+	fleetEscrow = prometheus.NewDesc("akash_fleet_escrow_locked",
+		"Escrow balance locked in Deployments by ProviderConfig and denom.",
+		[]string{"provider_config", "denom"}, nil)
+	fleetMonthlySpend = prometheus.NewDesc("akash_fleet_projected_monthly_spend",
+		"Projected monthly spend of the active leases of Deployments by ProviderConfig and denom.",
+		[]string{"provider_config", "denom"}, nil)
+)
+
+// fleetSummary aggregates the Deployments of a ProviderConfig.
+type fleetSummary struct {
+	states       map[string]int
+	failing      int
+	escrow       map[string]float64
+	monthlySpend map[string]float64
+}
+
+// A FleetCollector exports metrics summarizing all Deployments per
+// ProviderConfig, for fleet dashboards. They are computed from the status of
+// the Deployments when scraped, so no chain queries are made.
+type FleetCollector struct {
+	kube kubeclient.Reader
+	log  logging.Logger
+}
+
+// NewFleetCollector returns a FleetCollector listing Deployments with kube.
+func NewFleetCollector(kube kubeclient.Reader, log logging.Logger) *FleetCollector {
+	return &FleetCollector{kube: kube, log: log.WithValues("collector", "fleet")}
+}
+
+// Describe implements prometheus.Collector.
+func (c *FleetCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- fleetDeployments
+	ch <- fleetFailing
+	ch <- fleetEscrow
+	ch <- fleetMonthlySpend
+}
+
+// Collect implements prometheus.Collector.
+func (c *FleetCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), fleetListTimeout)
+	defer cancel()
+
+	l := &v1alpha1.DeploymentList{}
+	if err := c.kube.List(ctx, l); err != nil {
+		c.log.Info("Cannot list Deployments", "error", err)
+		return
+	}
+
+	for pc, s := range summarizeFleet(l.Items) {
+		for state, n := range s.states {
+			ch <- prometheus.MustNewConstMetric(fleetDeployments, prometheus.GaugeValue, float64(n), pc, state)
+		}
+		ch <- prometheus.MustNewConstMetric(fleetFailing, prometheus.GaugeValue, float64(s.failing), pc)
+		for denom, v := range s.escrow {
+			ch <- prometheus.MustNewConstMetric(fleetEscrow, prometheus.GaugeValue, v, pc, denom)
+		}
+		for denom, v := range s.monthlySpend {
+			ch <- prometheus.MustNewConstMetric(fleetMonthlySpend, prometheus.GaugeValue, v, pc, denom)
+		}
+	}
+}
+
+// summarizeFleet aggregates Deployments by the name of their ProviderConfig.
+func summarizeFleet(items []v1alpha1.Deployment) map[string]*fleetSummary {
+	blocksPerMonth := float64(averageMonth) / float64(averageBlockTime)
+
+	summaries := map[string]*fleetSummary{}
+	for i := range items {
+		cr := &items[i]
+
+		pc := "default"
+		if ref := cr.GetProviderConfigReference(); ref != nil {
+			pc = ref.Name
+		}
+		s, ok := summaries[pc]
+		if !ok {
+			s = &fleetSummary{states: map[string]int{}, escrow: map[string]float64{}, monthlySpend: map[string]float64{}}
+			summaries[pc] = s
+		}
+
+		s.states[fleetState(cr)]++
+		if cr.GetCondition(xpv1.TypeSynced).Status == corev1.ConditionFalse {
+			s.failing++
+		}
+
+		spend := cr.Status.AtProvider.Spend
+		if spend == nil || cr.GetCondition(v1alpha1.TypeClosed).Status == corev1.ConditionTrue {
+			continue
+		}
+		if balance, err := strconv.ParseFloat(spend.Balance, 64); err == nil {
+			s.escrow[spend.Denom] += balance
+		}
+		if rate, err := strconv.ParseFloat(spend.AgreedRate, 64); err == nil {
+			s.monthlySpend[spend.Denom] += rate * blocksPerMonth
+		}
+	}
+	return summaries
+}
+
+// fleetState returns the state a Deployment is counted in: Closed once it was
+// closed on chain, or else the reason of its Ready condition.
+func fleetState(cr *v1alpha1.Deployment) string {
+	if cr.GetCondition(v1alpha1.TypeClosed).Status == corev1.ConditionTrue {
+		return fleetStateClosed
+	}
+	if r := cr.GetCondition(xpv1.TypeReady).Reason; r != "" {
+		return string(r)
+	}
+	return fleetStateUnknown
+}
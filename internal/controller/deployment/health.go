@@ -0,0 +1,381 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+const (
+	errUpdateHealth      = "cannot update service health"
+	errNoLease           = "deployment has no active lease"
+	errNoServiceURI      = "service %s publishes no URI"
+	errNoForwardPort     = "service %s has no forwarded port %d"
+	errDependencyCycle   = "service dependencies form a cycle through %s"
+	errWaitingDependency = "waiting for dependency %s to be healthy"
+
+	defaultHealthCheckInterval = 30 * time.Second
+	defaultHealthCheckTimeout  = 5 * time.Second
+
+	healthController = "health"
+)
+
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=deployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=deployments/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// SetupHealth adds a controller that probes the endpoints of the services of
+// Deployments declaring health checks.
+func SetupHealth(mgr ctrl.Manager, o controller.Options) error {
+	name := healthController + "/" + v1alpha1.DeploymentGroupKind
+
+	r := &healthReconciler{
+		kube:      mgr.GetClient(),
+		log:       o.Logger.WithValues("controller", name),
+		record:    newTimeline(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		newClient: newUntrackedClient,
+		probe:     probe,
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Deployment{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+type healthReconciler struct {
+	kube      kubeclient.Client
+	log       logging.Logger
+	record    event.Recorder
+	newClient func(ctx context.Context, kube kubeclient.Client, mg resource.Managed, pcInfo client.ProviderConfigInfo) (client.AkashAPI, error)
+	probe     func(ctx context.Context, check v1alpha1.HealthCheck, status akashtypes.LeaseStatus) error
+}
+
+// Reconcile probes the health checks that are due and records their results
+// in the Deployment status, requeueing itself until the next one is due.
+func (r *healthReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	cr := &v1alpha1.Deployment{}
+	if err := r.kube.Get(ctx, req.NamespacedName, cr); err != nil {
+		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetDeployment)
+	}
+
+	checks := cr.Spec.ForProvider.HealthChecks
+	if len(checks) == 0 || cr.GetDeletionTimestamp() != nil {
+		if len(cr.Status.AtProvider.ServiceHealth) == 0 {
+			return reconcile.Result{}, nil
+		}
+		cr.Status.AtProvider.ServiceHealth = nil
+		return reconcile.Result{}, errors.Wrap(r.kube.Status().Update(ctx, cr), errUpdateHealth)
+	}
+
+	now := time.Now()
+	previous := make(map[string]v1alpha1.ServiceHealth, len(cr.Status.AtProvider.ServiceHealth))
+	for _, h := range cr.Status.AtProvider.ServiceHealth {
+		previous[healthKey(h.Service, h.Type)] = h
+	}
+
+	due := false
+	for _, c := range checks {
+		if healthCheckWait(c, previous[healthKey(c.Service, c.Type)], now) == 0 {
+			due = true
+		}
+	}
+	if !due {
+		return reconcile.Result{RequeueAfter: nextHealthCheck(checks, previous, now)}, nil
+	}
+
+	status, leaseErr := r.leaseStatus(ctx, cr)
+
+	dependencies := make(map[string][]string, len(cr.Spec.ForProvider.ServiceDependencies))
+	for _, d := range cr.Spec.ForProvider.ServiceDependencies {
+		dependencies[d.Service] = append(dependencies[d.Service], d.DependsOn...)
+	}
+	ordered, orderErr := orderHealthChecks(checks, dependencies)
+	if orderErr != nil {
+		ordered = checks
+	}
+
+	healthy := map[string]bool{}
+	health := make([]v1alpha1.ServiceHealth, 0, len(ordered))
+	for _, c := range ordered {
+		h, ok := previous[healthKey(c.Service, c.Type)]
+		if !ok || healthCheckWait(c, h, now) == 0 {
+			h = v1alpha1.ServiceHealth{Service: c.Service, Type: c.Type, Healthy: true, LastProbeTime: metav1.NewTime(now)}
+			err := orderErr
+			if err == nil {
+				err = leaseErr
+			}
+			if err == nil {
+				err = unhealthyDependency(c.Service, dependencies, healthy)
+			}
+			if err == nil {
+				err = r.probe(ctx, c, status)
+			}
+			if err != nil {
+				h.Healthy = false
+				h.Message = err.Error()
+			}
+			previous[healthKey(c.Service, c.Type)] = h
+		}
+
+		if prev, seen := healthy[c.Service]; !seen || prev {
+			healthy[c.Service] = h.Healthy
+		}
+		health = append(health, h)
+	}
+
+	r.log.Debug("Probed services", "deployment", cr.GetName())
+	if !allHealthy(checks, cr.Status.AtProvider.ServiceHealth) && allHealthy(checks, health) {
+		r.record.Event(cr, event.Normal(reasonServiceReady, "All services passed their health checks"))
+	}
+	cr.Status.AtProvider.ServiceHealth = health
+	return reconcile.Result{RequeueAfter: nextHealthCheck(checks, previous, now)}, errors.Wrap(r.kube.Status().Update(ctx, cr), errUpdateHealth)
+}
+
+// orderHealthChecks orders health checks so that the checks of a service come
+// after those of the services it depends on, keeping the declared order
+// otherwise. It returns an error if the dependencies form a cycle.
+func orderHealthChecks(checks []v1alpha1.HealthCheck, dependencies map[string][]string) ([]v1alpha1.HealthCheck, error) {
+	ordered := make([]v1alpha1.HealthCheck, 0, len(checks))
+	done := map[string]bool{}
+	visiting := map[string]bool{}
+
+	var visit func(service string) error
+	visit = func(service string) error {
+		if done[service] {
+			return nil
+		}
+		if visiting[service] {
+			return errors.Errorf(errDependencyCycle, service)
+		}
+		visiting[service] = true
+		for _, d := range dependencies[service] {
+			if err := visit(d); err != nil {
+				return err
+			}
+		}
+		visiting[service] = false
+		done[service] = true
+		for _, c := range checks {
+			if c.Service == service {
+				ordered = append(ordered, c)
+			}
+		}
+		return nil
+	}
+
+	for _, c := range checks {
+		if err := visit(c.Service); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// unhealthyDependency returns an error naming the first dependency of service
+// known to be unhealthy. Dependencies without health checks are assumed to be
+// healthy.
+func unhealthyDependency(service string, dependencies map[string][]string, healthy map[string]bool) error {
+	for _, d := range dependencies[service] {
+		if ok, known := healthy[d]; known && !ok {
+			return errors.Errorf(errWaitingDependency, d)
+		}
+	}
+	return nil
+}
+
+// leaseStatus returns the status of the services of all active leases of cr.
+func (r *healthReconciler) leaseStatus(ctx context.Context, cr *v1alpha1.Deployment) (akashtypes.LeaseStatus, error) {
+	merged := akashtypes.MergeLeaseStatuses()
+
+	dseq := externalDseq(cr)
+	if dseq == "" || dseq == cr.GetName() {
+		return merged, errors.New(errNoExternalName)
+	}
+
+	ak, err := connect(ctx, r.kube, cr, r.newClient)
+	if err != nil {
+		return merged, err
+	}
+
+	owner, err := deploymentOwner(ak, cr)
+	if err != nil {
+		return merged, err
+	}
+	leases, err := ak.ReadActiveLeases(ctx, dseq, owner)
+	if err != nil {
+		return merged, err
+	}
+	switch {
+	case ak.ObservedViaFallback():
+		cr.SetConditions(v1alpha1.ObservedViaIndexer(ak.Configuration().IndexerApi))
+	case cr.GetCondition(v1alpha1.TypeObservedViaFallback).Status == corev1.ConditionTrue:
+		cr.SetConditions(v1alpha1.ObservedViaNode())
+	}
+	if len(leases) == 0 {
+		return merged, errors.New(errNoLease)
+	}
+
+	return leaseStatus(ctx, ak, leases)
+}
+
+// probe runs a health check against the endpoints published in status.
+func probe(ctx context.Context, check v1alpha1.HealthCheck, status akashtypes.LeaseStatus) error {
+	target, err := healthCheckTarget(check, status)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, durationOr(check.Timeout, defaultHealthCheckTimeout))
+	defer cancel()
+
+	if check.Type == v1alpha1.HealthCheckTCP {
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", target)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck // Nothing is read from the body.
+	if resp.StatusCode >= http.StatusBadRequest {
+		return errors.Errorf("%s returned status code %d", target, resp.StatusCode)
+	}
+	return nil
+}
+
+// healthCheckTarget returns the URL probed by an HTTP check or the address
+// probed by a TCP check.
+func healthCheckTarget(check v1alpha1.HealthCheck, status akashtypes.LeaseStatus) (string, error) {
+	if check.Type == v1alpha1.HealthCheckTCP {
+		for _, p := range status.ForwardedPorts[check.Service] {
+			if p.Port == check.Port {
+				return net.JoinHostPort(p.Host, strconv.Itoa(int(p.ExternalPort))), nil
+			}
+		}
+		return "", errors.Errorf(errNoForwardPort, check.Service, check.Port)
+	}
+
+	uris := status.Services[check.Service].URIs
+	if len(uris) == 0 {
+		return "", errors.Errorf(errNoServiceURI, check.Service)
+	}
+	uri := uris[0]
+	if !strings.Contains(uri, "://") {
+		uri = "http://" + uri
+	}
+	path := check.Path
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return strings.TrimSuffix(uri, "/") + path, nil
+}
+
+// healthCheckWait returns how long until a check last probed as recorded in
+// last is due again, or zero if it is due now.
+func healthCheckWait(check v1alpha1.HealthCheck, last v1alpha1.ServiceHealth, now time.Time) time.Duration {
+	if last.LastProbeTime.IsZero() {
+		return 0
+	}
+	if wait := last.LastProbeTime.Add(durationOr(check.Interval, defaultHealthCheckInterval)).Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// nextHealthCheck returns how long until the next check is due.
+func nextHealthCheck(checks []v1alpha1.HealthCheck, last map[string]v1alpha1.ServiceHealth, now time.Time) time.Duration {
+	next := time.Duration(0)
+	for _, c := range checks {
+		wait := healthCheckWait(c, last[healthKey(c.Service, c.Type)], now)
+		if wait == 0 {
+			wait = durationOr(c.Interval, defaultHealthCheckInterval)
+		}
+		if next == 0 || wait < next {
+			next = wait
+		}
+	}
+	return next
+}
+
+// healthCondition returns the Ready condition reflecting the results of the
+// health checks of a Deployment.
+func healthCondition(checks []v1alpha1.HealthCheck, health []v1alpha1.ServiceHealth) xpv1.Condition {
+	if len(health) < len(checks) {
+		return xpv1.Unavailable().WithMessage("services have not been probed yet")
+	}
+
+	var failing []string
+	for _, h := range health {
+		if !h.Healthy {
+			failing = append(failing, h.Service+": "+h.Message)
+		}
+	}
+	if len(failing) > 0 {
+		return xpv1.Unavailable().WithMessage("unhealthy services: " + strings.Join(failing, "; "))
+	}
+	return xpv1.Available()
+}
+
+// allHealthy reports whether every check was probed and passed.
+func allHealthy(checks []v1alpha1.HealthCheck, health []v1alpha1.ServiceHealth) bool {
+	return healthCondition(checks, health).Status == corev1.ConditionTrue
+}
+
+func healthKey(service string, t v1alpha1.HealthCheckType) string {
+	return service + "/" + string(t)
+}
+
+func durationOr(d *metav1.Duration, def time.Duration) time.Duration {
+	if d == nil || d.Duration <= 0 {
+		return def
+	}
+	return d.Duration
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"strconv"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+	"github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+// lateInitialize sets the unset fields of the spec of cr that the deployment
+// on chain determines: the denom of its deposit, its owner and depositor, and
+// the placement requirements of its groups. Imported deployments thereby
+// converge to a fully specified spec. It reports whether the spec changed.
+func lateInitialize(cr *v1alpha1.Deployment, d types.Deployment) bool {
+	p := &cr.Spec.ForProvider
+	changed := false
+
+	owner := d.DeploymentInfo.DeploymentId.Owner
+	if p.Owner == nil && owner != "" {
+		p.Owner = &owner
+		changed = true
+	}
+
+	if denom := d.EscrowAccount.Balance.Denom; denom != "" {
+		switch {
+		case p.Deposit == nil:
+			p.Deposit = &v1alpha1.Deposit{Amount: strconv.Itoa(client.DefaultDepositAmount), Denom: denom}
+			changed = true
+		case p.Deposit.Denom == "":
+			p.Deposit.Denom = denom
+			changed = true
+		}
+	}
+
+	if depositor := d.EscrowAccount.Depositor; p.Depositor == nil && depositor != "" && depositor != owner {
+		p.Depositor = &depositor
+		changed = true
+	}
+
+	attrs, signedBy := placement(d.Groups)
+	filter := p.ProviderFilter
+	if filter == nil {
+		filter = &v1alpha1.ProviderFilter{}
+	}
+	if filter.Attributes == nil && len(attrs) > 0 {
+		filter.Attributes = attrs
+		p.ProviderFilter = filter
+		changed = true
+	}
+	if filter.SignedBy == nil && signedBy != nil {
+		filter.SignedBy = signedBy
+		p.ProviderFilter = filter
+		changed = true
+	}
+	return changed
+}
+
+// placement returns the provider attributes and auditors the groups require.
+// Attributes are merged across groups, the first group requiring a key
+// winning.
+func placement(groups []types.Group) (map[string]string, *v1alpha1.SignedBy) {
+	var attrs map[string]string
+	var signedBy *v1alpha1.SignedBy
+	for _, g := range groups {
+		req := g.GroupSpec.Requirements
+		for _, a := range req.Attributes {
+			if attrs == nil {
+				attrs = map[string]string{}
+			}
+			if _, ok := attrs[a.Key]; !ok {
+				attrs[a.Key] = a.Value
+			}
+		}
+		if signedBy == nil && (len(req.SignedBy.AllOf) > 0 || len(req.SignedBy.AnyOf) > 0) {
+			signedBy = &v1alpha1.SignedBy{AllOf: req.SignedBy.AllOf, AnyOf: req.SignedBy.AnyOf}
+		}
+	}
+	return attrs, signedBy
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+const (
+	errQueryBids      = "cannot query bids"
+	errGetProviders   = "cannot get providers"
+	errCreateLease    = "cannot create lease"
+	errGetLeaseStatus = "cannot get status of lease with %s"
+
+	// The order of the first group of a deployment. Only that group is
+	// placed.
+	firstGseq = "1"
+	firstOseq = "1"
+
+	// defaultBidReportSize is the size of bid reports when the Deployment
+	// does not set one.
+	defaultBidReportSize = 5
+
+	reasonPriceExceeded     event.Reason = "PriceExceeded"
+	reasonWaitingForBids    event.Reason = "WaitingForBids"
+	reasonManifestFailed    event.Reason = "ManifestFailed"
+	reasonTransactionFailed event.Reason = "TransactionFailed"
+
+	reasonApproved  = "approved"
+	reasonColocated = "provider of colocated Deployment %s"
+)
+
+// selectionReasons explain why the bid selected by each strategy was
+// accepted.
+var selectionReasons = map[v1alpha1.BidSelectionStrategy]string{
+	v1alpha1.BidSelectionLowestPrice: "cheapest open bid",
+	v1alpha1.BidSelectionRandom:      "randomly selected open bid",
+	v1alpha1.BidSelectionWeighted:    "highest scored open bid",
+}
+
+// orderSeqs returns the sequences of the order placing the deployment dseq of
+// owner.
+func orderSeqs(owner, dseq string) client.Seqs {
+	return client.Seqs{Owner: owner, Dseq: dseq, Gseq: firstGseq, Oseq: firstOseq}
+}
+
+// bidsPhase returns the phase of a deployment without an active lease that
+// received the given number of bids.
+func bidsPhase(received int) v1alpha1.DeploymentPhase {
+	if received == 0 {
+		return v1alpha1.DeploymentPhasePending
+	}
+	return v1alpha1.DeploymentPhaseBidsOpen
+}
+
+// leaseBid selects one of the bids on the order of seqs according to the
+// policy of cr and leases it, returning the provider of the lease. No provider
+// is returned while bids are still collected or wait for approval.
+func (c *external) leaseBid(ctx context.Context, cr *v1alpha1.Deployment, seqs client.Seqs) (string, error) {
+	ak := c.service.client
+	p := cr.Spec.ForProvider
+
+	bids, err := ak.QueryBids(ctx, seqs)
+	if err != nil {
+		return "", errors.Wrap(err, errQueryBids)
+	}
+	cr.Status.AtProvider.Phase = bidsPhase(len(bids))
+	if len(bids) > 0 {
+		c.recorder.Event(cr, event.Normal(reasonBidReceived, fmt.Sprintf("Received %d bids", len(bids))))
+	}
+
+	policy := client.NewBidCollectionPolicy(p.MinBids, p.BidCollectionWindow)
+	if !policy.Collected(len(bids), time.Since(meta.GetExternalCreateSucceeded(cr))) {
+		c.recorder.Event(cr, event.Normal(reasonWaitingForBids, waitingForBids(len(bids), policy)))
+		return "", nil
+	}
+	if err := ak.RecordBidPrices(bids, time.Now()); err != nil {
+		return "", errors.Wrap(err, "cannot record bid prices")
+	}
+	providers, err := ak.GetProviders(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, errGetProviders)
+	}
+	maxPrice, err := client.NewMaxPricePolicy(p.MaxPrice)
+	if err != nil {
+		return "", err
+	}
+	affordable, overpriced := affordableBids(bids, maxPrice)
+	if len(affordable) == 0 {
+		if elapsed := time.Since(meta.GetExternalCreateSucceeded(cr)); elapsed >= maxPrice.Window {
+			cheapest := bids.SortByPrice()[0].Price
+			cond := v1alpha1.NoBidWithinMaxPrice(maxPrice.String(), client.FormatBidPrice(cheapest)+cheapest.Denom, metav1.Duration{Duration: maxPrice.Window})
+			cr.SetConditions(cond)
+			c.recorder.Event(cr, event.Warning(reasonPriceExceeded, errors.New(cond.Message)))
+		}
+		return "", nil
+	}
+	byAddress := client.IndexProviders(providers)
+	bids, rejected, err := eligibleBids(ctx, ak, affordable, byAddress, p.ProviderFilter)
+	if err != nil {
+		return "", err
+	}
+	rejected = append(overpriced, rejected...)
+	if len(bids) == 0 {
+		c.recorder.Event(cr, event.Warning(reasonNoEligibleBids, errors.Errorf(errNoEligibleBids, len(rejected))))
+		return "", nil
+	}
+	colocated, err := colocatedProvider(ctx, c.kube, cr)
+	if err != nil {
+		return "", err
+	}
+
+	approved := ""
+	if p.RequireApproval != nil && *p.RequireApproval {
+		size := defaultBidReportSize
+		if p.BidReportSize != nil {
+			size = *p.BidReportSize
+		}
+		cr.Status.AtProvider.BidReport = client.BuildBidReport(bids, providers, size)
+		if approved = cr.GetAnnotations()[v1alpha1.AnnotationKeyApprovedBid]; approved == "" {
+			return "", nil
+		}
+	}
+
+	selection := client.NewBidSelectionPolicy(p.BidSelection)
+	r := rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec // Spreading deployments needs no secure randomness.
+	bid, err := ak.LeaseFirstOpenBid(ctx, seqs, rankBids(bids, providers, selection, r, colocated, approved))
+	if err != nil {
+		c.recorder.Event(cr, event.Warning(reasonTransactionFailed, errors.Wrap(err, errCreateLease)))
+		return "", errors.Wrap(err, errCreateLease)
+	}
+
+	reason := selectionReasons[selection.Strategy]
+	switch {
+	case approved != "":
+		reason = reasonApproved
+	case colocated == bid.Id.Provider:
+		reason = fmt.Sprintf(reasonColocated, *p.ColocateWith)
+	}
+	now := metav1.Now()
+	cr.Status.AtProvider.Bids = append(cr.Status.AtProvider.Bids, client.NewBidRecord(bid, byAddress[bid.Id.Provider], v1alpha1.BidDecisionAccepted, reason, now))
+	for _, r := range rejected {
+		cr.Status.AtProvider.Bids = append(cr.Status.AtProvider.Bids, client.NewBidRecord(r.bid, byAddress[r.bid.Id.Provider], v1alpha1.BidDecisionRejected, r.reason, now))
+	}
+	cr.Status.AtProvider.Phase = v1alpha1.DeploymentPhaseLeaseActive
+	if maxPrice != nil {
+		cr.SetConditions(v1alpha1.BidWithinMaxPrice())
+	}
+	c.recorder.Event(cr, event.Normal(reasonBidAccepted, "Accepted bid of "+bid.Id.Provider+": "+reason))
+	c.recorder.Event(cr, event.Normal(reasonLeaseCreated, "Created lease with "+bid.Id.Provider))
+	return bid.Id.Provider, nil
+}
+
+// waitingForBids describes a deployment that received the given number of
+// bids, which policy does not consider collected yet.
+func waitingForBids(received int, policy client.BidCollectionPolicy) string {
+	return fmt.Sprintf("Waiting for bids: received %d of %d within %s", received, policy.MinBids, policy.Window)
+}
+
+// rankBids orders bids by preference: only the bid of the approved provider
+// is considered when one was approved, and the bid of the colocated provider
+// comes first, followed by the others in the order of the selection policy.
+func rankBids(bids akashtypes.Bids, providers []akashtypes.Provider, selection client.BidSelectionPolicy, r *rand.Rand, colocated, approved string) akashtypes.Bids {
+	if approved != "" {
+		return bids.FindAllByProviders([]string{approved})
+	}
+	ranked := client.RankBids(bids, providers, selection, r)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return colocated != "" && ranked[i].Id.Provider == colocated && ranked[j].Id.Provider != colocated
+	})
+	return ranked
+}
+
+// leaseStatus returns the merged status of the given leases, queried from the
+// gateways of their providers.
+func leaseStatus(ctx context.Context, ak client.AkashAPI, leases []akashtypes.Lease) (akashtypes.LeaseStatus, error) {
+	statuses := make([]akashtypes.LeaseStatus, 0, len(leases))
+	for _, l := range leases {
+		status, err := ak.GetLeaseStatus(ctx, l.Lease.LeaseId)
+		if err != nil {
+			return akashtypes.MergeLeaseStatuses(), errors.Wrapf(err, errGetLeaseStatus, l.Lease.LeaseId.Provider)
+		}
+		statuses = append(statuses, status)
+	}
+	return akashtypes.MergeLeaseStatuses(statuses...), nil
+}
+
+// publishServices records the services of the deployment and the endpoints
+// assigned to them in the status of cr, and returns how to connect to them.
+func publishServices(cr *v1alpha1.Deployment, status akashtypes.LeaseStatus) managed.ConnectionDetails {
+	cr.Status.AtProvider.Services = client.LeaseServices(status)
+	cr.Status.AtProvider.ForwardedPorts, cr.Status.AtProvider.IPs = client.LeaseEndpoints(status)
+	return status.ConnectionDetails()
+}
+
+// manifestDelivered reports whether the manifest of the given version was
+// delivered to provider.
+func manifestDelivered(md *v1alpha1.ManifestDeliveryStatus, provider, version string) bool {
+	return md != nil && md.LastError == "" && md.LastAttemptTime != nil && md.Provider == provider && md.Version == version
+}
+
+// deliverManifest submits the manifest of the SDL at path to provider,
+// retrying according to the policy of cr, and records the outcome.
+func (c *external) deliverManifest(ctx context.Context, cr *v1alpha1.Deployment, dseq, provider, path, version string) error {
+	policy := client.NewManifestDeliveryPolicy(cr.Spec.ForProvider.ManifestDelivery)
+	result, err := c.service.client.SendManifestWithRetry(ctx, dseq, provider, path, policy)
+
+	now := metav1.Now()
+	md := &v1alpha1.ManifestDeliveryStatus{Attempts: result.Attempts, LastAttemptTime: &now, Provider: provider, Version: version}
+	cr.Status.AtProvider.ManifestDelivery = md
+	if err != nil {
+		md.LastError = err.Error()
+		c.recorder.Event(cr, event.Warning(reasonManifestFailed, errors.Wrapf(err, "Cannot send manifest to %s", provider)))
+		return err
+	}
+	c.recorder.Event(cr, event.Normal(reasonManifestSent, "Sent manifest to "+provider))
+	return nil
+}
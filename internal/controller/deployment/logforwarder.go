@@ -0,0 +1,404 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+	gateway "github.com/overlock-network/provider-akash/internal/client/provider-gateway"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+const (
+	errLogSinkStatus = "log sink responded with status code %d"
+
+	// logForwarderResync is how often forwarding is checked, restarting the
+	// log streams that ended, e.g. because the provider restarted.
+	logForwarderResync = time.Minute
+
+	// Lines are sent to the sink in batches of up to logBatchSize lines, at
+	// least every logFlushInterval. Up to logBufferSize lines are buffered
+	// while the sink is busy.
+	logBatchSize     = 100
+	logFlushInterval = 2 * time.Second
+	logBufferSize    = 1000
+	logSinkTimeout   = 10 * time.Second
+
+	lokiPushPath = "/loki/api/v1/push"
+
+	logForwarderController = "logforwarder"
+)
+
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=deployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigs,verbs=get;list;watch
+
+// SetupLogForwarder adds a controller that forwards the logs of the services
+// of Deployments declaring log forwarding from the gateway of their provider.
+func SetupLogForwarder(mgr ctrl.Manager, o controller.Options) error {
+	name := logForwarderController + "/" + v1alpha1.DeploymentGroupKind
+
+	r := &logForwarderReconciler{
+		kube:      mgr.GetClient(),
+		log:       o.Logger.WithValues("controller", name),
+		newClient: newUntrackedClient,
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Deployment{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+type logForwarderReconciler struct {
+	kube      kubeclient.Client
+	log       logging.Logger
+	newClient func(ctx context.Context, kube kubeclient.Client, mg resource.Managed, pcInfo client.ProviderConfigInfo) (client.AkashAPI, error)
+}
+
+// A logForwarder forwards the logs of the active leases of a Deployment in
+// the background.
+type logForwarder struct {
+	// spec identifies the configuration and leases being forwarded, so the
+	// forwarder is restarted when either changes.
+	spec   string
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (f *logForwarder) running() bool {
+	select {
+	case <-f.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// logForwarders holds the running log forwarder of each Deployment by name.
+var logForwarders sync.Map
+
+// stopLogForwarder stops the log forwarder of the named Deployment, if any.
+func stopLogForwarder(name string) {
+	if f, ok := logForwarders.LoadAndDelete(name); ok {
+		f.(*logForwarder).cancel()
+	}
+}
+
+// Reconcile starts forwarding the logs of the active leases of a Deployment,
+// restarting it when its configuration or leases changed or its streams
+// ended, and stops it once forwarding is no longer wanted.
+func (r *logForwarderReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	cr := &v1alpha1.Deployment{}
+	if err := r.kube.Get(ctx, req.NamespacedName, cr); err != nil {
+		if resource.IgnoreNotFound(err) == nil {
+			stopLogForwarder(req.Name)
+		}
+		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetDeployment)
+	}
+
+	lf := cr.Spec.ForProvider.LogForwarding
+	dseq := externalDseq(cr)
+	if lf == nil || cr.GetDeletionTimestamp() != nil || dseq == "" || dseq == cr.GetName() {
+		stopLogForwarder(cr.GetName())
+		return reconcile.Result{}, nil
+	}
+
+	ak, err := connect(ctx, r.kube, cr, r.newClient)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	owner, err := deploymentOwner(ak, cr)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	leases, err := ak.ReadActiveLeases(ctx, dseq, owner)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, errGetLeases)
+	}
+
+	spec := logForwarderSpec(lf, leases)
+	if f, ok := logForwarders.Load(cr.GetName()); ok && f.(*logForwarder).spec == spec && f.(*logForwarder).running() {
+		return reconcile.Result{RequeueAfter: logForwarderResync}, nil
+	}
+	stopLogForwarder(cr.GetName())
+	if len(leases) == 0 {
+		return reconcile.Result{RequeueAfter: logForwarderResync}, nil
+	}
+
+	// The forwarder outlives this reconcile, so it must not use its context.
+	fctx, cancel := context.WithCancel(context.Background())
+	f := &logForwarder{spec: spec, cancel: cancel, done: make(chan struct{})}
+	logForwarders.Store(cr.GetName(), f)
+	go r.forward(fctx, cr.DeepCopy(), dseq, leases, f.done)
+
+	r.log.Debug("Started forwarding logs", "deployment", cr.GetName(), "leases", len(leases))
+	return reconcile.Result{RequeueAfter: logForwarderResync}, nil
+}
+
+// forward streams the logs of leases into the sink of cr until ctx is done or
+// every stream ended, and closes done when it returns. Every lease is streamed
+// with a client of its own, since clients are not safe for concurrent use.
+func (r *logForwarderReconciler) forward(ctx context.Context, cr *v1alpha1.Deployment, dseq string, leases []akashtypes.Lease, done chan struct{}) {
+	defer close(done)
+	log := r.log.WithValues("deployment", cr.GetName())
+
+	clients := make([]client.AkashAPI, len(leases))
+	for i := range leases {
+		ak, err := connect(ctx, r.kube, cr, r.newClient)
+		if err != nil {
+			log.Info("Cannot forward logs", "error", err)
+			return
+		}
+		clients[i] = ak
+	}
+
+	lf := cr.Spec.ForProvider.LogForwarding
+	opts := gateway.LogOptions{Services: lf.Services, Follow: true}
+	if lf.Tail != nil {
+		opts.Tail = *lf.Tail
+	}
+
+	lines := make(chan forwardedLog, logBufferSize)
+	wg := sync.WaitGroup{}
+	for i, l := range leases {
+		wg.Add(1)
+		go func(ak client.AkashAPI, id akashtypes.LeaseId) {
+			defer wg.Done()
+			seqs := client.Seqs{Dseq: dseq, Gseq: strconv.Itoa(id.Gseq), Oseq: strconv.Itoa(id.Oseq)}
+			err := ak.StreamLeaseLogs(ctx, seqs, id.Provider, opts, func(line gateway.LogLine) error {
+				select {
+				case lines <- forwardedLog{
+					Time:       time.Now(),
+					Deployment: cr.GetName(),
+					Provider:   id.Provider,
+					Service:    line.Service(),
+					Pod:        line.Name,
+					Message:    line.Message,
+				}:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+			if err != nil && ctx.Err() == nil {
+				log.Info("Log stream ended", "provider", id.Provider, "error", err)
+			}
+		}(clients[i], l.Lease.LeaseId)
+	}
+	go func() {
+		wg.Wait()
+		close(lines)
+	}()
+
+	batchLogs(ctx, lines, newLogSink(lf.Sink, r.log), logBatchSize, logFlushInterval, func(err error) {
+		log.Info("Cannot forward logs", "error", err)
+	})
+}
+
+// logForwarderSpec identifies the forwarding configuration lf of the given
+// leases.
+func logForwarderSpec(lf *v1alpha1.LogForwarding, leases []akashtypes.Lease) string {
+	ids := make([]akashtypes.LeaseId, 0, len(leases))
+	for _, l := range leases {
+		ids = append(ids, l.Lease.LeaseId)
+	}
+	b, _ := json.Marshal(struct {
+		Forwarding *v1alpha1.LogForwarding `json:"forwarding"`
+		Leases     []akashtypes.LeaseId    `json:"leases"`
+	}{lf, ids})
+	return string(b)
+}
+
+// batchLogs sends the lines received on lines to sink in batches of up to
+// size lines, at least every interval, until lines is closed. Batches the
+// sink failed to receive are reported to failed and dropped, so a broken sink
+// does not stall the log streams.
+func batchLogs(ctx context.Context, lines <-chan forwardedLog, sink logSink, size int, interval time.Duration, failed func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	batch := make([]forwardedLog, 0, size)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := sink.Send(ctx, batch); err != nil {
+			failed(err)
+		}
+		batch = make([]forwardedLog, 0, size)
+	}
+
+	for {
+		select {
+		case l, ok := <-lines:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, l)
+			if len(batch) >= size {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// A forwardedLog is a line logged by a service of a Deployment.
+type forwardedLog struct {
+	Time       time.Time `json:"time"`
+	Deployment string    `json:"deployment"`
+	Provider   string    `json:"provider"`
+	Service    string    `json:"service"`
+	Pod        string    `json:"pod"`
+	Message    string    `json:"message"`
+}
+
+// A logSink receives forwarded logs.
+type logSink interface {
+	Send(ctx context.Context, lines []forwardedLog) error
+}
+
+// newLogSink returns the sink configured by s, or a sink writing to log when
+// s is nil.
+func newLogSink(s *v1alpha1.LogSink, log logging.Logger) logSink {
+	if s == nil {
+		return controllerLogSink{log: log}
+	}
+	return httpLogSink{sink: *s, client: &http.Client{Timeout: logSinkTimeout}}
+}
+
+// A controllerLogSink writes logs as structured lines of the controller log.
+type controllerLogSink struct {
+	log logging.Logger
+}
+
+func (s controllerLogSink) Send(_ context.Context, lines []forwardedLog) error {
+	for _, l := range lines {
+		s.log.Info(l.Message, "deployment", l.Deployment, "service", l.Service, "pod", l.Pod, "provider", l.Provider)
+	}
+	return nil
+}
+
+// An httpLogSink posts logs to Loki or a generic HTTP endpoint.
+type httpLogSink struct {
+	sink   v1alpha1.LogSink
+	client *http.Client
+}
+
+func (s httpLogSink) Send(ctx context.Context, lines []forwardedLog) error {
+	url := s.sink.URL
+	var payload any = httpLogPayload(lines, s.sink.Labels)
+	if s.sink.Type == v1alpha1.LogSinkLoki {
+		url = strings.TrimSuffix(url, "/") + lokiPushPath
+		payload = lokiPayload(lines, s.sink.Labels)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck // Nothing is read from the body.
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf(errLogSinkStatus, resp.StatusCode)
+	}
+	return nil
+}
+
+// An httpLogLine is a line posted to an HTTP sink.
+type httpLogLine struct {
+	forwardedLog
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// httpLogPayload returns the lines posted to an HTTP sink.
+func httpLogPayload(lines []forwardedLog, labels map[string]string) []httpLogLine {
+	payload := make([]httpLogLine, 0, len(lines))
+	for _, l := range lines {
+		payload = append(payload, httpLogLine{forwardedLog: l, Labels: labels})
+	}
+	return payload
+}
+
+// lokiPush is the body of a request to the push API of Loki.
+type lokiPush struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+// A lokiStream is a stream of lines sharing the same labels. Every value is a
+// pair of a timestamp in nanoseconds and a line.
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// lokiPayload groups lines into a stream per service, labeled with the
+// Deployment, service and provider besides labels, in the order the services
+// first logged.
+func lokiPayload(lines []forwardedLog, labels map[string]string) lokiPush {
+	push := lokiPush{Streams: []lokiStream{}}
+	streams := map[string]int{}
+	for _, l := range lines {
+		key := l.Deployment + "/" + l.Provider + "/" + l.Service
+		i, ok := streams[key]
+		if !ok {
+			stream := map[string]string{}
+			for k, v := range labels {
+				stream[k] = v
+			}
+			stream["deployment"] = l.Deployment
+			stream["service"] = l.Service
+			stream["provider"] = l.Provider
+			i = len(push.Streams)
+			streams[key] = i
+			push.Streams = append(push.Streams, lokiStream{Stream: stream})
+		}
+		push.Streams[i].Values = append(push.Streams[i].Values, [2]string{strconv.FormatInt(l.Time.UnixNano(), 10), l.Message})
+	}
+	return push
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+)
+
+const (
+	errParseSDL    = "cannot parse SDL"
+	errRenderSDL   = "cannot render SDL"
+	errNoServices  = "SDL has no services"
+	errWriteSDL    = "cannot write SDL"
+	errServiceSpec = "service %s is not a mapping"
+)
+
+// passthroughEnv returns the environment variables, as NAME=value, that the
+// metadata passthrough of cr sets on every SDL service.
+func passthroughEnv(cr *v1alpha1.Deployment) []string {
+	env := []string{}
+	for _, p := range cr.Spec.ForProvider.MetadataPassthrough {
+		var value string
+		var ok bool
+		switch {
+		case p.Label != nil:
+			value, ok = cr.GetLabels()[*p.Label]
+		case p.Annotation != nil:
+			value, ok = cr.GetAnnotations()[*p.Annotation]
+		}
+		if ok {
+			env = append(env, p.Env+"="+value)
+		}
+	}
+	return env
+}
+
+// renderSDL returns the given SDL of cr with its metadata passthrough applied.
+func renderSDL(cr *v1alpha1.Deployment, sdl string) (string, error) {
+	env := passthroughEnv(cr)
+	if len(env) == 0 {
+		return sdl, nil
+	}
+	return injectEnv(sdl, env)
+}
+
+// injectEnv appends the given NAME=value environment variables to every
+// service of the SDL, unless the service already sets a variable of that name.
+func injectEnv(sdl string, env []string) (string, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal([]byte(sdl), doc); err != nil {
+		return "", errors.Wrap(err, errParseSDL)
+	}
+
+	services := mappingValue(doc, "services")
+	if services == nil || services.Kind != yaml.MappingNode {
+		return "", errors.New(errNoServices)
+	}
+
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		name, svc := services.Content[i].Value, services.Content[i+1]
+		if svc.Kind != yaml.MappingNode {
+			return "", errors.Errorf(errServiceSpec, name)
+		}
+
+		vars := mappingValue(svc, "env")
+		if vars == nil {
+			vars = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+			svc.Content = append(svc.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "env"}, vars)
+		}
+
+		set := map[string]bool{}
+		for _, v := range vars.Content {
+			set[strings.SplitN(v.Value, "=", 2)[0]] = true
+		}
+		for _, e := range env {
+			if set[strings.SplitN(e, "=", 2)[0]] {
+				continue
+			}
+			vars.Content = append(vars.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: e})
+		}
+	}
+
+	return encodeSDL(doc)
+}
+
+// encodeSDL returns the SDL of the YAML document doc.
+func encodeSDL(doc *yaml.Node) (string, error) {
+	out := &strings.Builder{}
+	enc := yaml.NewEncoder(out)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return "", errors.Wrap(err, errRenderSDL)
+	}
+	if err := enc.Close(); err != nil {
+		return "", errors.Wrap(err, errRenderSDL)
+	}
+	return out.String(), nil
+}
+
+// mappingValue returns the value of key in the mapping n, or in the mapping at
+// the root of the document n. It returns nil if there is no such key.
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// writeSDL writes the SDL to a temporary file, since the client reads it
+// from disk, and returns its path. The caller removes the file.
+func writeSDL(sdl string) (string, error) {
+	f, err := os.CreateTemp("", "sdl-*.yaml")
+	if err != nil {
+		return "", errors.Wrap(err, errWriteSDL)
+	}
+	_, err = f.WriteString(sdl)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return "", errors.Wrap(err, errWriteSDL)
+	}
+	return f.Name(), nil
+}
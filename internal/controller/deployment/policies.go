@@ -0,0 +1,38 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+)
+
+// mayAct reports whether the management policies of cr allow every action.
+// Transactions broadcast outside of Create, Update and Delete, e.g. to close
+// an expired deployment or to top up its escrow, are only broadcast when the
+// policies allow the actions they amount to. Any action is allowed unless
+// support for management policies is enabled.
+func mayAct(enabled bool, cr *v1alpha1.Deployment, actions ...xpv1.ManagementAction) bool {
+	if !enabled {
+		return true
+	}
+	p := sets.New[xpv1.ManagementAction](cr.GetManagementPolicies()...)
+	return p.Has(xpv1.ManagementActionAll) || p.HasAll(actions...)
+}
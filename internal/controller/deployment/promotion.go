@@ -0,0 +1,339 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	apisv1alpha1 "github.com/overlock-network/provider-akash/apis/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+	"github.com/overlock-network/provider-akash/internal/features"
+)
+
+const (
+	errGetDeployment   = "cannot get Deployment"
+	errCreateTwin      = "cannot create promoted Deployment"
+	errRenderTwin      = "cannot render the SDL of the promoted Deployment"
+	errUpdatePromotion = "cannot update promotion status"
+	errTrackTwin       = "cannot track ProviderConfig usage of promoted Deployment"
+	errNotPromotedTwin = "Deployment %s already exists and was not promoted from %s"
+
+	reasonPromotionRefused         event.Reason = "PromotionRefused"
+	reasonPromotionPreflightFailed event.Reason = "PromotionPreflightFailed"
+	reasonPromoted                 event.Reason = "Promoted"
+
+	promotionController = "promotion"
+
+	// promotionRetryInterval is how long to wait before retrying a promotion
+	// whose preflight checks failed, e.g. until the target account is funded.
+	promotionRetryInterval = 5 * time.Minute
+)
+
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=deployments,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=deployments/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigusages,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// SetupPromotion adds a controller that promotes Deployments annotated with
+// akash.web7.md/promote-to to the network of the named ProviderConfig, when
+// the ProviderConfig of the Deployment lists it as a promotion target.
+func SetupPromotion(mgr ctrl.Manager, o controller.Options) error {
+	name := promotionController + "/" + v1alpha1.DeploymentGroupKind
+
+	r := &promotionReconciler{
+		kube:      mgr.GetClient(),
+		log:       o.Logger.WithValues("controller", name),
+		record:    event.NewAPIRecorder(mgr.GetEventRecorderFor(name)),
+		usage:     resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+		newClient: newUntrackedClient,
+
+		managementPolicies: o.Features.Enabled(features.EnableAlphaManagementPolicies),
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Deployment{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+type promotionReconciler struct {
+	kube      kubeclient.Client
+	log       logging.Logger
+	record    event.Recorder
+	usage     resource.Tracker
+	newClient func(ctx context.Context, kube kubeclient.Client, mg resource.Managed, pcInfo client.ProviderConfigInfo) (client.AkashAPI, error)
+
+	// managementPolicies is whether management policies are honored.
+	managementPolicies bool
+}
+
+// Reconcile checks that a requested promotion is allowed, runs its preflight
+// checks against the target ProviderConfig and, when they pass, creates the
+// twin Deployment on the target network and links both Deployments.
+func (r *promotionReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	cr := &v1alpha1.Deployment{}
+	if err := r.kube.Get(ctx, req.NamespacedName, cr); err != nil {
+		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetDeployment)
+	}
+
+	target := cr.GetAnnotations()[v1alpha1.AnnotationKeyPromoteTo]
+	if target == "" || cr.GetDeletionTimestamp() != nil {
+		return reconcile.Result{}, nil
+	}
+	if p := cr.Status.AtProvider.Promotion; p != nil && p.ProviderConfig == target && p.Phase == v1alpha1.PromotionPhaseCompleted {
+		return reconcile.Result{}, nil
+	}
+
+	log := r.log.WithValues("deployment", cr.GetName(), "providerConfig", target)
+
+	refusal, err := r.refusal(ctx, cr, target)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if refusal != "" {
+		log.Debug("Promotion refused", "reason", refusal)
+		r.record.Event(cr, event.Warning(reasonPromotionRefused, errors.New(refusal)))
+		cr.Status.AtProvider.Promotion = &v1alpha1.PromotionStatus{
+			ProviderConfig: target,
+			Phase:          v1alpha1.PromotionPhaseRefused,
+			Message:        refusal,
+		}
+		return reconcile.Result{RequeueAfter: promotionRetryInterval}, errors.Wrap(r.kube.Status().Update(ctx, cr), errUpdatePromotion)
+	}
+
+	source := &apisv1alpha1.ProviderConfig{}
+	if err := r.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, source); err != nil {
+		return reconcile.Result{}, errors.Wrap(err, errGetPC)
+	}
+	pc := &apisv1alpha1.ProviderConfig{}
+	if err := r.kube.Get(ctx, types.NamespacedName{Name: target}, pc); err != nil {
+		return reconcile.Result{}, errors.Wrap(err, errGetPC)
+	}
+
+	// The twin runs the SDL the Deployment renders, priced in the
+	// denomination of the target network.
+	sdl, err := desiredSDL(ctx, r.kube, cr)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, errRenderTwin)
+	}
+	twin, err := promotedTwin(cr, sdl, source, pc)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, errRenderTwin)
+	}
+
+	ak, err := r.newClient(ctx, r.kube, cr, client.NewProviderConfigInfo(pc))
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, errNewClient)
+	}
+
+	deposit := client.NewDeposit(twin.Spec.ForProvider.Deposit)
+	if cr.Spec.ForProvider.Depositor != nil {
+		// The deposit is not taken from the account being checked.
+		deposit.Amount = "0"
+	}
+	if err := ak.Preflight(ctx, deposit); err != nil {
+		log.Debug("Promotion preflight failed", "error", err)
+		r.record.Event(cr, event.Warning(reasonPromotionPreflightFailed, err))
+		cr.Status.AtProvider.Promotion = &v1alpha1.PromotionStatus{
+			ProviderConfig: target,
+			Phase:          v1alpha1.PromotionPhasePreflightFailed,
+			Message:        err.Error(),
+		}
+		return reconcile.Result{RequeueAfter: promotionRetryInterval}, errors.Wrap(r.kube.Status().Update(ctx, cr), errUpdatePromotion)
+	}
+
+	if err := r.kube.Create(ctx, twin); kerrors.IsAlreadyExists(err) {
+		err = r.kube.Get(ctx, types.NamespacedName{Name: twin.GetName()}, twin)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrap(err, errCreateTwin)
+		}
+		// A Deployment of the same name that is not the twin of cr is never
+		// taken over.
+		if twin.GetLabels()[v1alpha1.LabelKeyPromotedFrom] != cr.GetName() {
+			refusal := fmt.Sprintf(errNotPromotedTwin, twin.GetName(), cr.GetName())
+			log.Debug("Promotion refused", "reason", refusal)
+			r.record.Event(cr, event.Warning(reasonPromotionRefused, errors.New(refusal)))
+			cr.Status.AtProvider.Promotion = &v1alpha1.PromotionStatus{
+				ProviderConfig: target,
+				Phase:          v1alpha1.PromotionPhaseRefused,
+				Message:        refusal,
+			}
+			return reconcile.Result{RequeueAfter: promotionRetryInterval}, errors.Wrap(r.kube.Status().Update(ctx, cr), errUpdatePromotion)
+		}
+	} else if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, errCreateTwin)
+	}
+	// The twin uses the target ProviderConfig from now on, so the
+	// ProviderConfig may not be deleted before the twin is.
+	if err := r.usage.Track(ctx, twin); err != nil {
+		return reconcile.Result{}, errors.Wrap(err, errTrackTwin)
+	}
+
+	log.Debug("Promoted deployment", "twin", twin.GetName())
+	r.record.Event(cr, event.Normal(reasonPromoted, "Created Deployment "+twin.GetName()+" using ProviderConfig "+target))
+	cr.Status.AtProvider.Promotion = &v1alpha1.PromotionStatus{
+		ProviderConfig: target,
+		Deployment:     twin.GetName(),
+		Phase:          v1alpha1.PromotionPhaseCompleted,
+	}
+	return reconcile.Result{}, errors.Wrap(r.kube.Status().Update(ctx, cr), errUpdatePromotion)
+}
+
+// refusal returns why cr may not be promoted to the ProviderConfig target, or
+// nothing when it may. Promotion creates and funds a Deployment from the
+// account of target, so it is only allowed to the targets the ProviderConfig
+// of cr lists, never for Deployments configured by a NamespacedProviderConfig,
+// which may only use the account of their tenant, and only when cr may create
+// resources.
+func (r *promotionReconciler) refusal(ctx context.Context, cr *v1alpha1.Deployment, target string) (string, error) {
+	if ref := cr.GetNamespacedProviderConfigReference(); ref != nil {
+		return fmt.Sprintf("Deployments configured by NamespacedProviderConfig %s cannot be promoted", ref.Name), nil
+	}
+	if !mayAct(r.managementPolicies, cr, xpv1.ManagementActionCreate) {
+		return "management policies of the Deployment do not allow creating the promoted Deployment", nil
+	}
+
+	ref := cr.GetProviderConfigReference()
+	if ref == nil {
+		return "the Deployment does not reference a ProviderConfig", nil
+	}
+	source := &apisv1alpha1.ProviderConfig{}
+	if err := r.kube.Get(ctx, types.NamespacedName{Name: ref.Name}, source); err != nil {
+		return "", errors.Wrap(err, errGetPC)
+	}
+	if source.Spec.Configuration == nil || !slices.Contains(source.Spec.Configuration.PromotionTargets, target) {
+		return fmt.Sprintf("ProviderConfig %s does not list %s in its promotionTargets", ref.Name, target), nil
+	}
+	return "", nil
+}
+
+// promotedTwin returns the Deployment to create on the network of the
+// ProviderConfig target when promoting cr, configured by the ProviderConfig
+// source, to it. The twin runs sdl, the SDL cr renders, with its metadata
+// passthrough applied since the twin does not carry the metadata of cr.
+// Prices in the deployment denomination of source are moved to the one of
+// target.
+func promotedTwin(cr *v1alpha1.Deployment, sdl string, source, target *apisv1alpha1.ProviderConfig) (*v1alpha1.Deployment, error) {
+	from, to := deploymentDenom(source), deploymentDenom(target)
+	sdl, err := redenominate(sdl, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	name := cr.GetName() + "-" + target.GetName()
+	twin := &v1alpha1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{v1alpha1.LabelKeyPromotedFrom: cr.GetName()},
+		},
+		Spec: v1alpha1.DeploymentSpec{
+			ResourceSpec: xpv1.ResourceSpec{
+				ProviderConfigReference: &xpv1.Reference{Name: target.GetName()},
+				DeletionPolicy:          cr.Spec.DeletionPolicy,
+				ManagementPolicies:      cr.Spec.ManagementPolicies,
+			},
+			ForProvider: *cr.Spec.ForProvider.DeepCopy(),
+		},
+	}
+
+	p := &twin.Spec.ForProvider
+	p.SDL = &sdl
+	p.Deployment = ""
+	p.SDLRef = nil
+	p.SDLChecksum = nil
+	p.MetadataPassthrough = nil
+	if p.Deposit != nil && p.Deposit.Denom == from {
+		p.Deposit.Denom = to
+	}
+	if p.MaxPrice != nil && p.MaxPrice.Denom == from {
+		p.MaxPrice.Denom = to
+	}
+
+	if ref := cr.Spec.WriteConnectionSecretToReference; ref != nil {
+		twin.Spec.WriteConnectionSecretToReference = &xpv1.SecretReference{
+			Name:      ref.Name + "-" + target.GetName(),
+			Namespace: ref.Namespace,
+		}
+	}
+
+	return twin, nil
+}
+
+// deploymentDenom returns the denomination deployments configured by pc are
+// funded in by default, which names the same currency on every network only
+// for the native token.
+func deploymentDenom(pc *apisv1alpha1.ProviderConfig) string {
+	if c := pc.Spec.Configuration; c != nil && c.DeploymentDefaults != nil && c.DeploymentDefaults.Deposit != nil && c.DeploymentDefaults.Deposit.Denom != "" {
+		return c.DeploymentDefaults.Deposit.Denom
+	}
+	return client.DefaultDepositDenom
+}
+
+// redenominate returns sdl with the prices of its placements in from moved to
+// to.
+func redenominate(sdl, from, to string) (string, error) {
+	if from == to {
+		return sdl, nil
+	}
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal([]byte(sdl), doc); err != nil {
+		return "", errors.Wrap(err, errParseSDL)
+	}
+
+	profiles := mappingValue(doc, "profiles")
+	if profiles == nil {
+		return sdl, nil
+	}
+	placements := mappingValue(profiles, "placement")
+	if placements == nil || placements.Kind != yaml.MappingNode {
+		return sdl, nil
+	}
+	for i := 1; i < len(placements.Content); i += 2 {
+		pricing := mappingValue(placements.Content[i], "pricing")
+		if pricing == nil || pricing.Kind != yaml.MappingNode {
+			continue
+		}
+		for j := 1; j < len(pricing.Content); j += 2 {
+			if denom := mappingValue(pricing.Content[j], "denom"); denom != nil && denom.Value == from {
+				denom.Value = to
+			}
+		}
+	}
+	return encodeSDL(doc)
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+const (
+	errCloseFailed    = "cannot close failed deployment"
+	errBlacklist      = "cannot blacklist provider %s"
+	errFailoverReason = "gateway of %s failed %d consecutive probes: %s"
+
+	reasonRecreated  event.Reason = "Recreated"
+	reasonFailedOver event.Reason = "FailedOver"
+
+	// defaultUnreachableProbes is how many consecutive probes the gateway
+	// of a provider fails before a Deployment fails over when it does not
+	// set it.
+	defaultUnreachableProbes = 3
+)
+
+// recreateOnFailure reports whether cr is recreated once it failed on chain.
+func recreateOnFailure(cr *v1alpha1.Deployment) bool {
+	return cr.Spec.ForProvider.RecreateOnFailure != nil && *cr.Spec.ForProvider.RecreateOnFailure
+}
+
+// closedOnChain returns why the deployment dseq of cr, which is closed on
+// chain, has to be recreated, or an empty string when it was closed by this
+// controller.
+func closedOnChain(cr *v1alpha1.Deployment, dseq string) string {
+	if cr.GetCondition(v1alpha1.TypeClosed).Status == corev1.ConditionTrue {
+		return ""
+	}
+	return fmt.Sprintf("deployment %s was closed on chain", dseq)
+}
+
+// lostLease returns why a deployment with the given leases has to be
+// recreated because its lease was closed, or an empty string while a lease is
+// active or none was closed. Leases closed for insufficient funds are not
+// failures of the provider, and recreating does not refund them.
+func lostLease(leases []akashtypes.Lease) string {
+	reason := ""
+	for _, l := range leases {
+		switch v1alpha1.LeaseStateFromChain(l.Lease.State) {
+		case v1alpha1.LeaseStateActive:
+			return ""
+		case v1alpha1.LeaseStateClosed:
+			reason = fmt.Sprintf("lease with %s was closed", l.Lease.LeaseId.Provider)
+		}
+	}
+	return reason
+}
+
+// recreate closes the failed deployment dseq of cr, if it is still open, and
+// resets cr so that the deployment is created again under a new dseq.
+func (c *external) recreate(ctx context.Context, cr *v1alpha1.Deployment, dseq, owner, reason string) error {
+	if err := closable(c.service.client, cr, dseq, owner); err != nil {
+		return err
+	}
+	if err := c.service.client.DeleteDeployment(ctx, dseq, owner); err != nil {
+		return errors.Wrap(err, errCloseFailed)
+	}
+
+	// A recreation whose Create failed is retried with the same dseq.
+	rec := cr.Status.AtProvider.Recreation
+	if rec == nil || rec.PreviousDseq != dseq {
+		count := 1
+		if rec != nil {
+			count = rec.Count + 1
+		}
+		rec = &v1alpha1.RecreationStatus{Count: count, PreviousDseq: dseq, Reason: reason, LastRecreateTime: metav1.Now()}
+		c.recorder.Event(cr, event.Warning(reasonRecreated, errors.Errorf("Recreating deployment %s: %s", dseq, reason)))
+	}
+
+	// Until it is created again, the external name is the name of the
+	// resource.
+	cr.Status.AtProvider = v1alpha1.DeploymentObservation{Recreation: rec}
+	meta.SetExternalName(cr, cr.GetName())
+	forgetTimeline(cr)
+	return nil
+}
+
+// failoverDue reports whether cr fails over because the gateway of its
+// provider failed enough consecutive probes.
+func failoverDue(cr *v1alpha1.Deployment) bool {
+	f := cr.Spec.ForProvider.Failover
+	if f == nil {
+		return false
+	}
+	threshold := defaultUnreachableProbes
+	if f.UnreachableProbes != nil {
+		threshold = *f.UnreachableProbes
+	}
+	return cr.Status.AtProvider.GatewayFailures >= threshold
+}
+
+// failover moves the deployment dseq of cr away from provider, whose gateway
+// failed with the given error: the provider is blacklisted so that its next
+// bid is rejected, the evidence of the failure is recorded, and the
+// deployment is recreated.
+func (c *external) failover(ctx context.Context, cr *v1alpha1.Deployment, dseq, owner, provider, gatewayErr string) error {
+	reason := fmt.Sprintf(errFailoverReason, provider, cr.Status.AtProvider.GatewayFailures, gatewayErr)
+	ak := c.service.client
+	if err := ak.BlacklistProvider(provider, reason); err != nil {
+		return errors.Wrapf(err, errBlacklist, provider)
+	}
+	if err := recordFailover(ctx, c.kube, c.reader, ak, cr, dseq, owner, provider, reason); err != nil {
+		return err
+	}
+	c.recorder.Event(cr, event.Warning(reasonFailedOver, errors.New("Leaving provider "+provider+": "+reason)))
+	return c.recreate(ctx, cr, dseq, owner, reason)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+)
+
+const (
+	// blockTime is roughly how long the chain takes to produce a block.
+	// Deployments are requeued a block after an expected event, so its
+	// outcome can be observed.
+	blockTime = 6 * time.Second
+
+	// minPollInterval bounds how soon a requeue hint may poll a Deployment
+	// again.
+	minPollInterval = 5 * time.Second
+)
+
+// pollIntervalHook polls a Deployment right after the next chain event its
+// status predicts when that is due before the poll interval, and at the poll
+// interval otherwise.
+func pollIntervalHook(mg resource.Managed, pollInterval time.Duration) time.Duration {
+	cr, ok := mg.(*v1alpha1.Deployment)
+	if !ok {
+		return pollInterval
+	}
+	return nextPoll(cr, pollInterval, time.Now())
+}
+
+// nextPoll returns how long to wait before polling cr again. Hints that are
+// already past are ignored, so a late event does not make it poll tightly.
+func nextPoll(cr *v1alpha1.Deployment, pollInterval time.Duration, now time.Time) time.Duration {
+	next := pollInterval
+	for _, at := range requeueHints(cr) {
+		if !at.After(now) {
+			continue
+		}
+		wait := at.Sub(now) + blockTime
+		if wait < minPollInterval {
+			wait = minPollInterval
+		}
+		if wait < next {
+			next = wait
+		}
+	}
+	return next
+}
+
+// requeueHints returns when the chain events expected for cr are due: the
+// close of the bid collection and maximum price windows of a deployment
+// waiting for bids, the next manifest delivery attempt after a failed one,
+// and the expiry of the deployment.
+func requeueHints(cr *v1alpha1.Deployment) []time.Time {
+	p := cr.Spec.ForProvider
+	var hints []time.Time
+	if created := meta.GetExternalCreateSucceeded(cr); !created.IsZero() && acceptedProvider(cr.Status.AtProvider.Bids) == "" {
+		hints = append(hints, created.Add(client.NewBidCollectionPolicy(p.MinBids, p.BidCollectionWindow).Window))
+		if maxPrice, err := client.NewMaxPricePolicy(p.MaxPrice); err == nil && maxPrice != nil {
+			hints = append(hints, created.Add(maxPrice.Window))
+		}
+	}
+	if md := cr.Status.AtProvider.ManifestDelivery; md != nil && md.LastError != "" && md.LastAttemptTime != nil {
+		hints = append(hints, md.LastAttemptTime.Add(client.NewManifestDeliveryPolicy(p.ManifestDelivery).Backoff))
+	}
+	if at := cr.Status.AtProvider.ExpiresAt; at != nil {
+		hints = append(hints, at.Time)
+	}
+	return hints
+}
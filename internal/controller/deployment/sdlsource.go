@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+)
+
+const (
+	errNoSDL          = "deployment has no SDL: set sdl, sdlRef or deployment"
+	errGetSDLSource   = "cannot get %s %s/%s holding the SDL"
+	errSDLKeyNotFound = "%s %s/%s has no key %q"
+	errIndexSDLRef    = "cannot index Deployments by SDL reference"
+
+	// defaultSDLKey is the key the SDL is read from when an SDL reference
+	// names none.
+	defaultSDLKey = "deploy.yaml"
+
+	// sdlRefIndex indexes Deployments by the ConfigMap or Secret their SDL
+	// is read from.
+	sdlRefIndex = "spec.forProvider.sdlRef"
+)
+
+// resolveSDL returns the SDL of cr. It is read from the referenced ConfigMap
+// or Secret when there is a reference, or else taken from the inline SDL or
+// the deprecated deployment field.
+func resolveSDL(ctx context.Context, kube kubeclient.Reader, cr *v1alpha1.Deployment) (string, error) {
+	p := cr.Spec.ForProvider
+	switch {
+	case p.SDLRef != nil:
+		return readSDLRef(ctx, kube, p.SDLRef)
+	case p.SDL != nil:
+		return *p.SDL, nil
+	case p.Deployment != "":
+		return p.Deployment, nil
+	}
+	return "", errors.New(errNoSDL)
+}
+
+// hasSDL reports whether cr specifies an SDL.
+func hasSDL(cr *v1alpha1.Deployment) bool {
+	p := cr.Spec.ForProvider
+	return p.SDLRef != nil || p.SDL != nil || p.Deployment != ""
+}
+
+// readSDLRef reads the SDL from the key of the referenced ConfigMap or Secret.
+func readSDLRef(ctx context.Context, kube kubeclient.Reader, ref *v1alpha1.SDLReference) (string, error) {
+	kind := ref.Kind
+	if kind == "" {
+		kind = v1alpha1.SDLSourceConfigMap
+	}
+	key := ref.Key
+	if key == "" {
+		key = defaultSDLKey
+	}
+	nn := types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}
+
+	var sdl string
+	var ok bool
+	switch kind {
+	case v1alpha1.SDLSourceSecret:
+		s := &corev1.Secret{}
+		if err := kube.Get(ctx, nn, s); err != nil {
+			return "", errors.Wrapf(err, errGetSDLSource, kind, ref.Namespace, ref.Name)
+		}
+		var b []byte
+		b, ok = s.Data[key]
+		sdl = string(b)
+	default:
+		cm := &corev1.ConfigMap{}
+		if err := kube.Get(ctx, nn, cm); err != nil {
+			return "", errors.Wrapf(err, errGetSDLSource, kind, ref.Namespace, ref.Name)
+		}
+		sdl, ok = cm.Data[key]
+	}
+	if !ok {
+		return "", errors.Errorf(errSDLKeyNotFound, kind, ref.Namespace, ref.Name, key)
+	}
+	return sdl, nil
+}
+
+// sdlRefKey identifies a ConfigMap or Secret in the SDL reference index.
+func sdlRefKey(kind v1alpha1.SDLSourceKind, namespace, name string) string {
+	if kind == "" {
+		kind = v1alpha1.SDLSourceConfigMap
+	}
+	return string(kind) + "/" + namespace + "/" + name
+}
+
+// indexSDLRef returns the key of the ConfigMap or Secret the SDL of a
+// Deployment is read from, if any.
+func indexSDLRef(o kubeclient.Object) []string {
+	cr, ok := o.(*v1alpha1.Deployment)
+	if !ok || cr.Spec.ForProvider.SDLRef == nil {
+		return nil
+	}
+	ref := cr.Spec.ForProvider.SDLRef
+	return []string{sdlRefKey(ref.Kind, ref.Namespace, ref.Name)}
+}
+
+// enqueueSDLReferrers returns a handler enqueuing the Deployments whose SDL is
+// read from a changed ConfigMap or Secret of the given kind, so edits roll out
+// without waiting for the next poll. Deployments annotated with
+// akash.web7.md/sdl-freeze=true are skipped.
+func enqueueSDLReferrers(kube kubeclient.Reader, log logging.Logger, kind v1alpha1.SDLSourceKind) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o kubeclient.Object) []reconcile.Request {
+		l := &v1alpha1.DeploymentList{}
+		if err := kube.List(ctx, l, kubeclient.MatchingFields{sdlRefIndex: sdlRefKey(kind, o.GetNamespace(), o.GetName())}); err != nil {
+			log.Info("Cannot list Deployments referencing SDL", "kind", kind, "namespace", o.GetNamespace(), "name", o.GetName(), "error", err)
+			return nil
+		}
+		return sdlReferrerRequests(l.Items)
+	})
+}
+
+// sdlReferrerRequests returns reconcile requests for the given Deployments
+// that are not in a change freeze.
+func sdlReferrerRequests(items []v1alpha1.Deployment) []reconcile.Request {
+	reqs := make([]reconcile.Request, 0, len(items))
+	for i := range items {
+		if frozen, _ := strconv.ParseBool(items[i].GetAnnotations()[v1alpha1.AnnotationKeySDLFreeze]); frozen {
+			continue
+		}
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: items[i].GetName()}})
+	}
+	return reqs
+}
@@ -0,0 +1,271 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/equality"
+	ctrl "sigs.k8s.io/controller-runtime"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+	"github.com/overlock-network/provider-akash/internal/denom"
+	"github.com/overlock-network/provider-akash/internal/features"
+)
+
+const (
+	errGetEscrow   = "cannot get deployment escrow"
+	errUpdateSpend = "cannot update spend status"
+
+	reasonSpendAnomaly event.Reason = "SpendAnomaly"
+
+	// spendCheckInterval is how often the escrow of a deployment is checked.
+	// Providers settle leases far less often than that.
+	spendCheckInterval = 10 * time.Minute
+
+	defaultSpendTolerancePercent = 20
+
+	spendController = "spend"
+)
+
+var (
+	spendRatio = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "akash_deployment_spend_ratio",
+		Help: "Rate the escrow of a deployment drains at relative to the prices of its leases.",
+	}, []string{"deployment"})
+
+	spendAnomalies = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "akash_deployment_spend_anomalies_total",
+		Help: "Spend anomalies detected for a deployment.",
+	}, []string{"deployment"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(spendRatio, spendAnomalies)
+}
+
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=deployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=deployments/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// SetupSpend adds a controller that tracks how fast the escrow of Deployments
+// drains, reports anomalies compared to the prices of their leases, and tops
+// up escrows running low.
+func SetupSpend(mgr ctrl.Manager, o controller.Options) error {
+	name := spendController + "/" + v1alpha1.DeploymentGroupKind
+
+	r := &spendReconciler{
+		kube:      mgr.GetClient(),
+		log:       o.Logger.WithValues("controller", name),
+		record:    event.NewAPIRecorder(mgr.GetEventRecorderFor(name)),
+		newClient: newUntrackedClient,
+
+		managementPolicies: o.Features.Enabled(features.EnableAlphaManagementPolicies),
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Deployment{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+type spendReconciler struct {
+	kube      kubeclient.Client
+	log       logging.Logger
+	record    event.Recorder
+	newClient func(ctx context.Context, kube kubeclient.Client, mg resource.Managed, pcInfo client.ProviderConfigInfo) (client.AkashAPI, error)
+
+	// managementPolicies is whether management policies are honored.
+	managementPolicies bool
+}
+
+// Reconcile samples the escrow of the deployment and the prices of its
+// leases, records the observed spend rate and emits a warning event when a
+// new anomaly is detected. The escrow is topped up when it runs low.
+func (r *spendReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	cr := &v1alpha1.Deployment{}
+	if err := r.kube.Get(ctx, req.NamespacedName, cr); err != nil {
+		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetDeployment)
+	}
+
+	dseq := externalDseq(cr)
+	if cr.GetDeletionTimestamp() != nil {
+		spendRatio.DeleteLabelValues(cr.GetName())
+		spendAnomalies.DeleteLabelValues(cr.GetName())
+		topUps.DeleteLabelValues(cr.GetName())
+		topUpAmount.DeletePartialMatch(prometheus.Labels{"deployment": cr.GetName()})
+		return reconcile.Result{}, nil
+	}
+	if dseq == "" || dseq == cr.GetName() {
+		return reconcile.Result{}, nil
+	}
+
+	ak, err := connect(ctx, r.kube, cr, r.newClient)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	owner, err := deploymentOwner(ak, cr)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	deployment, err := ak.GetDeployment(ctx, dseq, owner)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, errGetEscrow)
+	}
+	if state := v1alpha1.DeploymentStateFromChain(deployment.DeploymentInfo.State); state != cr.Status.AtProvider.State {
+		cr.Status.AtProvider.State = state
+		if err := r.kube.Status().Update(ctx, cr); err != nil {
+			return reconcile.Result{}, errors.Wrap(err, errUpdateSpend)
+		}
+	}
+	if cr.Status.AtProvider.State == v1alpha1.DeploymentStateClosed {
+		return reconcile.Result{}, nil
+	}
+	leases, err := ak.GetActiveLeases(ctx, dseq, owner)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, errGetLeases)
+	}
+
+	tolerance := defaultSpendTolerancePercent
+	if t := cr.Spec.ForProvider.SpendTolerancePercent; t != nil {
+		tolerance = *t
+	}
+
+	prev := cr.Status.AtProvider.Spend
+	spend := analyzeSpend(prev, deployment, leases, tolerance)
+	spend.EscrowState = v1alpha1.EscrowStateFromChain(deployment.EscrowAccount.State)
+	displaySpend(spend)
+
+	if ratio, ok := spendRateRatio(spend); ok {
+		spendRatio.WithLabelValues(cr.GetName()).Set(ratio)
+	}
+	if spend.Anomaly != "" && (prev == nil || prev.Anomaly != spend.Anomaly) {
+		r.log.Debug("Detected spend anomaly", "deployment", cr.GetName(), "anomaly", spend.Anomaly)
+		spendAnomalies.WithLabelValues(cr.GetName()).Inc()
+		r.record.Event(cr, event.Warning(reasonSpendAnomaly, errors.New(spend.Anomaly)))
+	}
+
+	toppedUp, err := r.topUp(ctx, ak, cr, dseq, owner, deployment.EscrowAccount)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if !toppedUp && equality.Semantic.DeepEqual(prev, spend) {
+		return reconcile.Result{RequeueAfter: spendCheckInterval}, nil
+	}
+	cr.Status.AtProvider.Spend = spend
+	return reconcile.Result{RequeueAfter: spendCheckInterval}, errors.Wrap(r.kube.Status().Update(ctx, cr), errUpdateSpend)
+}
+
+// analyzeSpend compares the escrow of a deployment with its state at the
+// previous sample and with the prices of its leases. An anomaly is reported
+// when a lease is charged above its price, or when the escrow drained faster
+// than the prices of the leases allow, beyond the given tolerance.
+func analyzeSpend(prev *v1alpha1.SpendStatus, deployment akashtypes.Deployment, leases []akashtypes.Lease, tolerancePercent int) *v1alpha1.SpendStatus {
+	escrow := deployment.EscrowAccount
+	limit := 1 + float64(tolerancePercent)/100
+
+	balance, _ := strconv.ParseFloat(escrow.Balance.Amount, 64)
+	settledAt, _ := strconv.ParseInt(escrow.SettledAt, 10, 64)
+
+	var anomalies []string
+	agreed := 0.0
+	for _, l := range leases {
+		price := float64(l.Lease.Price.Amount)
+		agreed += price
+		if rate := float64(l.EscrowPayment.Rate.Amount); rate > price*limit {
+			anomalies = append(anomalies, fmt.Sprintf("lease with %s is charged %s%s per block above its price of %s%s",
+				l.Lease.LeaseId.Provider, formatRate(rate), escrow.Balance.Denom, formatRate(price), escrow.Balance.Denom))
+		}
+	}
+
+	spend := &v1alpha1.SpendStatus{
+		Denom:      escrow.Balance.Denom,
+		Balance:    escrow.Balance.Amount,
+		SettledAt:  settledAt,
+		AgreedRate: formatRate(agreed),
+	}
+
+	if prev != nil && prev.Denom == spend.Denom {
+		spend.ObservedRate = prev.ObservedRate
+		prevBalance, _ := strconv.ParseFloat(prev.Balance, 64)
+		// A balance that grew was topped up, which leaves nothing to compare.
+		if blocks := settledAt - prev.SettledAt; blocks > 0 && prev.SettledAt > 0 && balance <= prevBalance {
+			observed := (prevBalance - balance) / float64(blocks)
+			spend.ObservedRate = formatRate(observed)
+			if agreed > 0 && observed > agreed*limit {
+				anomalies = append(anomalies, fmt.Sprintf("escrow drained at %s%s per block over the last %d blocks, above the %s%s agreed for its leases",
+					spend.ObservedRate, spend.Denom, blocks, spend.AgreedRate, spend.Denom))
+			}
+		}
+	}
+
+	spend.Anomaly = strings.Join(anomalies, "; ")
+	return spend
+}
+
+// displaySpend sets the display amounts of spend, unless its denomination is
+// unknown.
+func displaySpend(spend *v1alpha1.SpendStatus) {
+	unit, ok := denom.UnitOf(spend.Denom)
+	if !ok {
+		return
+	}
+	spend.DisplayDenom = unit.Display
+	spend.DisplayBalance, _, _ = denom.ToDisplay(spend.Balance, spend.Denom)
+	spend.DisplayAgreedRate, _, _ = denom.ToDisplay(spend.AgreedRate, spend.Denom)
+	if spend.ObservedRate != "" {
+		spend.DisplayObservedRate, _, _ = denom.ToDisplay(spend.ObservedRate, spend.Denom)
+	}
+}
+
+// spendRateRatio returns the observed rate relative to the agreed one.
+func spendRateRatio(spend *v1alpha1.SpendStatus) (float64, bool) {
+	observed, err := strconv.ParseFloat(spend.ObservedRate, 64)
+	if err != nil {
+		return 0, false
+	}
+	agreed, err := strconv.ParseFloat(spend.AgreedRate, 64)
+	if err != nil || agreed == 0 {
+		return 0, false
+	}
+	return observed / agreed, true
+}
+
+func formatRate(rate float64) string {
+	return strconv.FormatFloat(rate, 'f', -1, 64)
+}
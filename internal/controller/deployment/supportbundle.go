@@ -0,0 +1,188 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+const (
+	errBundleNamespace = "the support bundle annotation must name the namespace to write the bundle to"
+
+	// supportBundleEvents is the number of most recent events included in a
+	// support bundle.
+	supportBundleEvents = 50
+
+	redacted = "<redacted>"
+)
+
+// Keys of a support bundle ConfigMap.
+const (
+	bundleKeySDL    = "sdl.yaml"
+	bundleKeyStatus = "status.json"
+	bundleKeyEvents = "events.txt"
+	bundleKeyLeases = "leases.json"
+	bundleKeyErrors = "errors.txt"
+)
+
+// sdlEnvValue matches the value of an environment variable in an SDL, e.g.
+// "- DB_PASSWORD=secret", which commonly holds credentials.
+var sdlEnvValue = regexp.MustCompile(`(?m)^(\s*-\s*"?[A-Za-z_][A-Za-z0-9_]*=).*?("?)$`)
+
+// writeSupportBundle collects the SDL, status, recent events and lease status
+// of cr into a ConfigMap in the given namespace and returns its name. Parts
+// that cannot be collected are reported in the bundle instead of failing it.
+func (r *diagnosticsReconciler) writeSupportBundle(ctx context.Context, cr *v1alpha1.Deployment, namespace string) (string, error) {
+	if namespace == "" {
+		return "", errors.New(errBundleNamespace)
+	}
+
+	var problems []string
+	data := map[string]string{}
+
+	if sdl, err := resolveSDL(ctx, r.kube, cr); err != nil {
+		problems = append(problems, "sdl: "+err.Error())
+	} else {
+		data[bundleKeySDL] = redactSDL(sdl)
+	}
+
+	if status, err := json.MarshalIndent(cr.Status, "", "  "); err != nil {
+		problems = append(problems, "status: "+err.Error())
+	} else {
+		data[bundleKeyStatus] = string(status)
+	}
+
+	if events, err := recentEvents(ctx, r.reader, cr); err != nil {
+		problems = append(problems, "events: "+err.Error())
+	} else {
+		data[bundleKeyEvents] = events
+	}
+
+	if leases, err := r.leaseStatuses(ctx, cr); err != nil {
+		problems = append(problems, "leases: "+err.Error())
+	} else {
+		data[bundleKeyLeases] = leases
+	}
+
+	if len(problems) > 0 {
+		data[bundleKeyErrors] = strings.Join(problems, "\n")
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cr.GetName() + "-support-bundle",
+			Namespace: namespace,
+		},
+		Data: data,
+	}
+	if err := resource.NewAPIUpdatingApplicator(r.kube).Apply(ctx, cm); err != nil {
+		return "", errors.Wrap(err, errSupportBundle)
+	}
+	return cm.GetName(), nil
+}
+
+// recentEvents returns the most recent events recorded for cr, one per line.
+// Events cannot be listed by field from the cache, so reader must read from
+// the API server.
+func recentEvents(ctx context.Context, reader kubeclient.Reader, cr *v1alpha1.Deployment) (string, error) {
+	l := &corev1.EventList{}
+	if err := reader.List(ctx, l, kubeclient.MatchingFields{
+		"involvedObject.kind": v1alpha1.DeploymentKind,
+		"involvedObject.name": cr.GetName(),
+	}); err != nil {
+		return "", err
+	}
+
+	events := l.Items
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).Before(eventTime(events[j]))
+	})
+	if len(events) > supportBundleEvents {
+		events = events[len(events)-supportBundleEvents:]
+	}
+
+	b := &strings.Builder{}
+	for _, e := range events {
+		fmt.Fprintf(b, "%s %s %s (x%d): %s\n", eventTime(e).UTC().Format("2006-01-02T15:04:05Z"), e.Type, e.Reason, e.Count, e.Message)
+	}
+	return b.String(), nil
+}
+
+func eventTime(e corev1.Event) time.Time {
+	if !e.LastTimestamp.IsZero() {
+		return e.LastTimestamp.Time
+	}
+	return e.EventTime.Time
+}
+
+// leaseStatuses returns the status reported by the provider of each active
+// lease of cr, keyed by provider address.
+func (r *diagnosticsReconciler) leaseStatuses(ctx context.Context, cr *v1alpha1.Deployment) (string, error) {
+	dseq := externalDseq(cr)
+	if dseq == "" || dseq == cr.GetName() {
+		return "", errors.New(errNoExternalName)
+	}
+
+	ak, err := r.connect(ctx, cr)
+	if err != nil {
+		return "", err
+	}
+
+	owner, err := deploymentOwner(ak, cr)
+	if err != nil {
+		return "", err
+	}
+	leases, err := ak.GetActiveLeases(ctx, dseq, owner)
+	if err != nil {
+		return "", err
+	}
+
+	statuses := make(map[string]akashtypes.LeaseStatus, len(leases))
+	for _, l := range leases {
+		id := l.Lease.LeaseId
+		status, err := ak.GetLeaseStatus(ctx, id)
+		if err != nil {
+			return "", errors.Wrapf(err, "cannot get status of lease with %s", id.Provider)
+		}
+		statuses[id.Provider] = status
+	}
+
+	b, err := json.MarshalIndent(statuses, "", "  ")
+	return string(b), err
+}
+
+// redactSDL replaces the values of environment variables in an SDL, which
+// commonly hold credentials.
+func redactSDL(sdl string) string {
+	return sdlEnvValue.ReplaceAllString(sdl, "${1}"+redacted+"${2}")
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// Reasons of the events recording the provisioning phases of a Deployment,
+// which read as a timeline in kubectl describe.
+const (
+	reasonDeploymentCreated event.Reason = "DeploymentCreated"
+	reasonBidReceived       event.Reason = "BidReceived"
+	reasonBidAccepted       event.Reason = "BidAccepted"
+	reasonLeaseCreated      event.Reason = "LeaseCreated"
+	reasonManifestSent      event.Reason = "ManifestSent"
+	reasonServiceReady      event.Reason = "ServiceReady"
+)
+
+// timelinePhases are the provisioning phases in the order they happen.
+var timelinePhases = map[event.Reason]int{
+	reasonDeploymentCreated: 0,
+	reasonBidReceived:       1,
+	reasonBidAccepted:       2,
+	reasonLeaseCreated:      3,
+	reasonManifestSent:      4,
+	reasonServiceReady:      5,
+}
+
+// defaultTimelineWindow is how long an identical event is not recorded again.
+const defaultTimelineWindow = 10 * time.Minute
+
+// timelines holds the timeline of every Deployment by UID, shared by the
+// recorders of every controller of the process.
+var timelines sync.Map
+
+// objectTimeline is what was recorded for a Deployment.
+type objectTimeline struct {
+	mu sync.Mutex
+
+	// phase is the latest provisioning phase recorded.
+	phase int
+
+	// seen are the recorded events by key, with the time they were recorded
+	// at and how many identical ones were suppressed since.
+	seen map[string]*seenEvent
+}
+
+type seenEvent struct {
+	at         time.Time
+	suppressed int
+}
+
+// A timeline is an event.Recorder that keeps the events of a Deployment a
+// coherent timeline. It drops provisioning phases older than the latest one
+// recorded, e.g. a BidReceived observed after the lease was created, and
+// aggregates identical events recorded within its window, so short poll
+// intervals don't flood the events of a Deployment.
+type timeline struct {
+	record  event.Recorder
+	window  time.Duration
+	now     func() time.Time
+	objects *sync.Map
+}
+
+// newTimeline returns a timeline recording events with r.
+func newTimeline(r event.Recorder) *timeline {
+	return &timeline{record: r, window: defaultTimelineWindow, now: time.Now, objects: &timelines}
+}
+
+// Event records e unless it is out of order or a duplicate.
+func (t *timeline) Event(obj runtime.Object, e event.Event) {
+	o, ok := obj.(metav1.Object)
+	if !ok || o.GetUID() == "" {
+		t.record.Event(obj, e)
+		return
+	}
+
+	v, _ := t.objects.LoadOrStore(o.GetUID(), &objectTimeline{seen: map[string]*seenEvent{}})
+	ot := v.(*objectTimeline)
+	ot.mu.Lock()
+	defer ot.mu.Unlock()
+
+	now := t.now()
+	if phase, ok := timelinePhases[e.Reason]; ok && e.Type == event.TypeNormal {
+		switch {
+		case phase == 0:
+			// A new deployment starts a new timeline.
+			ot.phase = 0
+			ot.seen = map[string]*seenEvent{}
+		case phase < ot.phase:
+			return
+		default:
+			ot.phase = phase
+		}
+	}
+
+	key := string(e.Type) + "/" + string(e.Reason) + "/" + e.Message
+	if s, ok := ot.seen[key]; ok {
+		if now.Sub(s.at) < t.window {
+			s.suppressed++
+			return
+		}
+		if s.suppressed > 0 {
+			e.Message = fmt.Sprintf("%s (repeated %d times since %s)", e.Message, s.suppressed, s.at.Format(time.RFC3339))
+		}
+	}
+	for k, s := range ot.seen {
+		if now.Sub(s.at) >= t.window {
+			delete(ot.seen, k)
+		}
+	}
+	ot.seen[key] = &seenEvent{at: now}
+	t.record.Event(obj, e)
+}
+
+// WithAnnotations returns a timeline recording events with additional
+// annotations, sharing the timelines of t.
+func (t *timeline) WithAnnotations(keysAndValues ...string) event.Recorder {
+	return &timeline{record: t.record.WithAnnotations(keysAndValues...), window: t.window, now: t.now, objects: t.objects}
+}
+
+// A timelineFinalizer forgets the timeline of a Deployment once its finalizer
+// is removed, whether its deployment was closed or orphaned.
+type timelineFinalizer struct {
+	resource.Finalizer
+}
+
+// RemoveFinalizer removes the finalizer of obj and forgets its timeline.
+func (f timelineFinalizer) RemoveFinalizer(ctx context.Context, obj resource.Object) error {
+	if err := f.Finalizer.RemoveFinalizer(ctx, obj); err != nil {
+		return err
+	}
+	forgetTimeline(obj)
+	return nil
+}
+
+// forgetTimeline drops the timeline of a Deployment that was deleted or is
+// being recreated.
+func forgetTimeline(o metav1.Object) {
+	timelines.Delete(o.GetUID())
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+const (
+	errTopUp = "cannot top up deployment escrow"
+
+	reasonToppedUp           event.Reason = "ToppedUp"
+	reasonTopUpExhausted     event.Reason = "TopUpExhausted"
+	reasonTopUpDenomMismatch event.Reason = "TopUpDenomMismatch"
+)
+
+var (
+	topUps = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "akash_deployment_top_ups_total",
+		Help: "Deposits made into the escrow of a deployment by auto top-up.",
+	}, []string{"deployment"})
+
+	topUpAmount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "akash_deployment_top_up_amount_total",
+		Help: "Amount deposited into the escrow of a deployment by auto top-up.",
+	}, []string{"deployment", "denom"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(topUps, topUpAmount)
+}
+
+// topUpDue returns the amount to deposit into an escrow holding balance under
+// t, given the amount deposited by earlier top-ups, and whether a top-up is
+// due at all: Amount once the balance is below Threshold, capped so that the
+// deposits stay within MaxTotal. The amount is zero when a top-up is due but
+// MaxTotal was reached.
+func topUpDue(t *v1alpha1.AutoTopUp, balance akashtypes.EscrowAccountBalance, deposited string) (*big.Int, bool) {
+	zero := new(big.Int)
+	have, ok1 := new(big.Rat).SetString(balance.Amount)
+	threshold, ok2 := new(big.Rat).SetString(t.Threshold)
+	amount, ok3 := new(big.Int).SetString(t.Amount, 10)
+	if !ok1 || !ok2 || !ok3 || have.Cmp(threshold) >= 0 {
+		return zero, false
+	}
+	if t.MaxTotal == nil {
+		return amount, true
+	}
+
+	limit, ok := new(big.Int).SetString(*t.MaxTotal, 10)
+	if !ok {
+		return zero, true
+	}
+	total, ok := new(big.Int).SetString(deposited, 10)
+	if !ok {
+		total = zero
+	}
+	left := new(big.Int).Sub(limit, total)
+	if left.Sign() <= 0 {
+		return zero, true
+	}
+	if amount.Cmp(left) > 0 {
+		return left, true
+	}
+	return amount, true
+}
+
+// topUpDenom returns the denomination of the top-ups of t.
+func topUpDenom(t *v1alpha1.AutoTopUp) string {
+	if t.Denom == "" {
+		return client.DefaultDepositDenom
+	}
+	return t.Denom
+}
+
+// topUp deposits into the escrow of the deployment dseq of cr when its
+// AutoTopUp is due, and records the deposit in its status. It reports
+// whether the status changed.
+func (r *spendReconciler) topUp(ctx context.Context, ak client.AkashAPI, cr *v1alpha1.Deployment, dseq, owner string, escrow akashtypes.EscrowAccount) (bool, error) {
+	t := cr.Spec.ForProvider.AutoTopUp
+	if t == nil || !mayAct(r.managementPolicies, cr, xpv1.ManagementActionUpdate) || v1alpha1.EscrowStateFromChain(escrow.State) != v1alpha1.EscrowStateOpen {
+		return false, nil
+	}
+	denom := topUpDenom(t)
+	if escrow.Balance.Denom != denom {
+		r.record.Event(cr, event.Warning(reasonTopUpDenomMismatch, errors.Errorf("Escrow is funded in %s, not in the %s of auto top-up", escrow.Balance.Denom, denom)))
+		return false, nil
+	}
+
+	prev := cr.Status.AtProvider.TopUp
+	if prev == nil || prev.Denom != denom {
+		prev = &v1alpha1.TopUpStatus{Deposited: "0", Denom: denom}
+	}
+	amount, due := topUpDue(t, escrow.Balance, prev.Deposited)
+	if !due {
+		return false, nil
+	}
+	if amount.Sign() == 0 {
+		r.record.Event(cr, event.Warning(reasonTopUpExhausted, errors.Errorf("Escrow balance %s%s is below the top-up threshold, but %s%s were deposited already", escrow.Balance.Amount, denom, prev.Deposited, denom)))
+		return false, nil
+	}
+
+	coin := akashtypes.Coin{Denom: denom, Amount: amount.String()}
+	if err := ak.DepositDeployment(ctx, dseq, owner, coin); err != nil {
+		r.record.Event(cr, event.Warning(reasonTransactionFailed, errors.Wrap(err, errTopUp)))
+		return false, errors.Wrap(err, errTopUp)
+	}
+
+	deposited, _ := new(big.Int).SetString(prev.Deposited, 10)
+	if deposited == nil {
+		deposited = new(big.Int)
+	}
+	now := metav1.Now()
+	cr.Status.AtProvider.TopUp = &v1alpha1.TopUpStatus{
+		Deposited:     deposited.Add(deposited, amount).String(),
+		Denom:         denom,
+		Count:         prev.Count + 1,
+		LastTopUpTime: &now,
+	}
+	amountF, _ := new(big.Float).SetInt(amount).Float64()
+	topUps.WithLabelValues(cr.GetName()).Inc()
+	topUpAmount.WithLabelValues(cr.GetName(), denom).Add(amountF)
+	r.log.Debug("Topped up deployment escrow", "deployment", cr.GetName(), "dseq", dseq, "amount", coin.Amount+coin.Denom)
+	r.record.Event(cr, event.Normal(reasonToppedUp, "Deposited "+coin.Amount+coin.Denom+" into the escrow with a balance of "+escrow.Balance.Amount+denom))
+	return true, nil
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+	"github.com/overlock-network/provider-akash/internal/features"
+)
+
+const (
+	errGetLeases      = "cannot get active leases"
+	errCloseUnleased  = "cannot close unleased deployment"
+	errUpdateUnleased = "cannot update unleased status"
+
+	reasonClosedUnleased event.Reason = "ClosedUnleased"
+
+	unleasedController = "unleased"
+)
+
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=deployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=deployments/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=failoverrecords,verbs=create
+// +kubebuilder:rbac:groups="",resources=events,verbs=list;create;patch
+
+// SetupUnleased adds a controller that closes Deployments which had no active
+// lease for longer than their closeIfUnleasedFor period.
+func SetupUnleased(mgr ctrl.Manager, o controller.Options) error {
+	name := unleasedController + "/" + v1alpha1.DeploymentGroupKind
+
+	r := &unleasedReconciler{
+		kube:      mgr.GetClient(),
+		reader:    mgr.GetAPIReader(),
+		log:       o.Logger.WithValues("controller", name),
+		record:    event.NewAPIRecorder(mgr.GetEventRecorderFor(name)),
+		newClient: newUntrackedClient,
+
+		managementPolicies: o.Features.Enabled(features.EnableAlphaManagementPolicies),
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Deployment{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+type unleasedReconciler struct {
+	kube      kubeclient.Client
+	reader    kubeclient.Reader
+	log       logging.Logger
+	record    event.Recorder
+	newClient func(ctx context.Context, kube kubeclient.Client, mg resource.Managed, pcInfo client.ProviderConfigInfo) (client.AkashAPI, error)
+
+	// managementPolicies is whether management policies are honored.
+	managementPolicies bool
+}
+
+// Reconcile tracks since when a deployment has had no active lease and closes
+// it once that exceeds the configured period.
+func (r *unleasedReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	cr := &v1alpha1.Deployment{}
+	if err := r.kube.Get(ctx, req.NamespacedName, cr); err != nil {
+		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetDeployment)
+	}
+
+	period := cr.Spec.ForProvider.CloseIfUnleasedFor
+	dseq := externalDseq(cr)
+	if period == nil || cr.GetDeletionTimestamp() != nil || dseq == "" || dseq == cr.GetName() {
+		return reconcile.Result{}, nil
+	}
+	if cr.GetCondition(v1alpha1.TypeClosed).Status == corev1.ConditionTrue {
+		return reconcile.Result{}, nil
+	}
+
+	ak, err := connect(ctx, r.kube, cr, r.newClient)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	owner, err := deploymentOwner(ak, cr)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	leases, err := ak.GetActiveLeases(ctx, dseq, owner)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, errGetLeases)
+	}
+
+	if len(leases) > 0 {
+		if cr.Status.AtProvider.UnleasedSince == nil {
+			return reconcile.Result{RequeueAfter: period.Duration}, nil
+		}
+		cr.Status.AtProvider.UnleasedSince = nil
+		return reconcile.Result{RequeueAfter: period.Duration}, errors.Wrap(r.kube.Status().Update(ctx, cr), errUpdateUnleased)
+	}
+
+	now := time.Now()
+	if cr.Status.AtProvider.UnleasedSince == nil {
+		cr.Status.AtProvider.UnleasedSince = &metav1.Time{Time: now}
+		return reconcile.Result{RequeueAfter: period.Duration}, errors.Wrap(r.kube.Status().Update(ctx, cr), errUpdateUnleased)
+	}
+
+	if wait := unleasedRemaining(cr.Status.AtProvider.UnleasedSince.Time, period.Duration, now); wait > 0 {
+		return reconcile.Result{RequeueAfter: wait}, nil
+	}
+
+	// Closing the deployment amounts to deleting it.
+	if !mayAct(r.managementPolicies, cr, xpv1.ManagementActionDelete) {
+		return reconcile.Result{}, nil
+	}
+	if err := closable(ak, cr, dseq, owner); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// The provider may have closed the lease, so keep the evidence of what
+	// happened before the deployment is gone.
+	reason := "No active lease since " + cr.Status.AtProvider.UnleasedSince.Format(time.RFC3339)
+	if err := recordFailover(ctx, r.kube, r.reader, ak, cr, dseq, owner, "", reason); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err := ak.DeleteDeployment(ctx, dseq, owner); err != nil {
+		return reconcile.Result{}, errors.Wrap(err, errCloseUnleased)
+	}
+
+	r.log.Debug("Closed unleased deployment", "deployment", cr.GetName(), "dseq", dseq)
+	r.record.Event(cr, event.Normal(reasonClosedUnleased, "Closed deployment "+dseq+" without an active lease since "+cr.Status.AtProvider.UnleasedSince.Format(time.RFC3339)))
+	cr.SetConditions(v1alpha1.ClosedUnleased(*period))
+	return reconcile.Result{}, errors.Wrap(r.kube.Status().Update(ctx, cr), errUpdateUnleased)
+}
+
+// unleasedRemaining returns how much longer a deployment unleased since the
+// given time may stay open, or zero once it is due to be closed.
+func unleasedRemaining(since time.Time, period time.Duration, now time.Time) time.Duration {
+	if remaining := since.Add(period).Sub(now); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
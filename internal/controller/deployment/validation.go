@@ -0,0 +1,154 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/overlock-network/provider-akash/internal/sdl"
+)
+
+const (
+	errResourceLimits = "SDL exceeds the resource limits of the network:\n%s"
+)
+
+// resourceLimits are the limits the chain enforces on the resource units of
+// deployment groups. CPU is in millicores, memory and storage in bytes.
+type resourceLimits struct {
+	MinUnitCPU, MaxUnitCPU         int64
+	MinUnitMemory, MaxUnitMemory   int64
+	MinUnitStorage, MaxUnitStorage int64
+	MaxUnitGPU                     int64
+	MinUnitCount, MaxUnitCount     int64
+
+	MaxGroupCount   int
+	MaxGroupUnits   int
+	MaxGroupCPU     int64
+	MaxGroupGPU     int64
+	MaxGroupMemory  int64
+	MaxGroupStorage int64
+}
+
+// networkLimits mirror the validation config of the Akash deployment module.
+// They are compiled into the node rather than exposed as chain parameters.
+var networkLimits = resourceLimits{
+	MinUnitCPU:     10,
+	MaxUnitCPU:     384 * 1000,
+	MinUnitMemory:  1 << 20,
+	MaxUnitMemory:  2 << 40,
+	MinUnitStorage: 5 << 20,
+	MaxUnitStorage: 32 << 40,
+	MaxUnitGPU:     24,
+	MinUnitCount:   1,
+	MaxUnitCount:   50,
+
+	MaxGroupCount:   20,
+	MaxGroupUnits:   20,
+	MaxGroupCPU:     512 * 1000,
+	MaxGroupGPU:     24,
+	MaxGroupMemory:  4096 << 30,
+	MaxGroupStorage: 32 << 40,
+}
+
+// validateResources checks the resource units of the manifests against the
+// limits and returns a human readable report of every violation, or nil if
+// they are within them.
+func validateResources(manifests []sdl.Manifest, limits resourceLimits) []string {
+	report := []string{}
+
+	if len(manifests) > limits.MaxGroupCount {
+		report = append(report, fmt.Sprintf("deployment has %d groups, at most %d are allowed", len(manifests), limits.MaxGroupCount))
+	}
+
+	for _, m := range manifests {
+		var cpu, gpu, memory, storage int64
+		for _, svc := range m.Services {
+			unit := fmt.Sprintf("service %s in placement %s", svc.Name, m.Name)
+			res, count := svc.Resources, int64(svc.Count)
+
+			report = append(report, checkRange(unit, "count", count, limits.MinUnitCount, limits.MaxUnitCount, formatCount)...)
+			report = append(report, checkRange(unit, "cpu", int64(res.CPU), limits.MinUnitCPU, limits.MaxUnitCPU, formatCPU)...)
+			report = append(report, checkRange(unit, "memory", int64(res.Memory), limits.MinUnitMemory, limits.MaxUnitMemory, formatBytes)...)
+			report = append(report, checkRange(unit, "gpu", int64(res.GPU), 0, limits.MaxUnitGPU, formatCount)...)
+
+			var volumes int64
+			for i, v := range res.Storage {
+				report = append(report, checkRange(unit, fmt.Sprintf("storage volume %d", i), int64(v.Size), limits.MinUnitStorage, limits.MaxUnitStorage, formatBytes)...)
+				volumes += int64(v.Size)
+			}
+
+			cpu += int64(res.CPU) * count
+			gpu += int64(res.GPU) * count
+			memory += int64(res.Memory) * count
+			storage += volumes * count
+		}
+
+		group := "group " + m.Name
+		if len(m.Services) > limits.MaxGroupUnits {
+			report = append(report, fmt.Sprintf("%s has %d resource units, at most %d are allowed", group, len(m.Services), limits.MaxGroupUnits))
+		}
+		report = append(report, checkRange(group, "total cpu", cpu, 0, limits.MaxGroupCPU, formatCPU)...)
+		report = append(report, checkRange(group, "total gpu", gpu, 0, limits.MaxGroupGPU, formatCount)...)
+		report = append(report, checkRange(group, "total memory", memory, 0, limits.MaxGroupMemory, formatBytes)...)
+		report = append(report, checkRange(group, "total storage", storage, 0, limits.MaxGroupStorage, formatBytes)...)
+	}
+
+	if len(report) == 0 {
+		return nil
+	}
+	return report
+}
+
+// checkResources parses the SDL and returns an error listing every resource
+// limit of the network it violates.
+func checkResources(content string) error {
+	_, manifests, err := sdl.ParseSDL([]byte(content))
+	if err != nil {
+		return err
+	}
+	if report := validateResources(manifests, networkLimits); len(report) > 0 {
+		return errors.Errorf(errResourceLimits, "- "+strings.Join(report, "\n- "))
+	}
+	return nil
+}
+
+// checkRange reports value if it is outside of [lo, hi].
+func checkRange(subject, what string, value, lo, hi int64, format func(int64) string) []string {
+	switch {
+	case value < lo:
+		return []string{fmt.Sprintf("%s requests %s %s, below the minimum of %s", subject, what, format(value), format(lo))}
+	case value > hi:
+		return []string{fmt.Sprintf("%s requests %s %s, above the maximum of %s", subject, what, format(value), format(hi))}
+	}
+	return nil
+}
+
+func formatCount(v int64) string {
+	return fmt.Sprintf("%d", v)
+}
+
+func formatCPU(millis int64) string {
+	return kresource.NewMilliQuantity(millis, kresource.DecimalSI).String()
+}
+
+func formatBytes(b int64) string {
+	return kresource.NewQuantity(b, kresource.BinarySI).String()
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package feegrant manages fee allowances granted to other accounts.
+package feegrant
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	apisv1alpha1 "github.com/overlock-network/provider-akash/apis/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+	"github.com/overlock-network/provider-akash/internal/features"
+	"github.com/overlock-network/provider-akash/internal/tracing"
+)
+
+const (
+	errNotFeeGrant = "managed resource is not a FeeGrant custom resource"
+	errGetPC       = "cannot get ProviderConfig"
+	errNewClient   = "cannot create new client"
+	errGranter     = "cannot determine granter"
+	errGetGrant    = "cannot query fee grant"
+	errGrant       = "cannot grant fee allowance"
+	errRevoke      = "cannot revoke fee allowance"
+)
+
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=feegrants,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=feegrants/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigusages,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Setup adds a controller that reconciles FeeGrant managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	name := managed.ControllerName(v1alpha1.FeeGrantGroupKind)
+
+	opts := []managed.ReconcilerOption{
+		managed.WithExternalConnecter(tracing.NewConnecter(v1alpha1.FeeGrantKind, &connector{
+			kube:      mgr.GetClient(),
+			usage:     resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClient: client.NewAPIFromManagedResource,
+		})),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+	}
+	if o.Features.Enabled(features.EnableAlphaManagementPolicies) {
+		opts = append(opts, managed.WithManagementPolicies())
+	}
+
+	r := managed.NewReconciler(mgr, resource.ManagedKind(v1alpha1.FeeGrantGroupVersionKind), opts...)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.FeeGrant{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// A connector produces an external client for the ProviderConfig of a
+// FeeGrant.
+type connector struct {
+	kube      kubeclient.Client
+	usage     resource.Tracker
+	newClient func(ctx context.Context, kube kubeclient.Client, usage resource.Tracker, mg resource.Managed, pcInfo client.ProviderConfigInfo) (client.AkashAPI, error)
+}
+
+// Connect produces an ExternalClient with a ready-to-use AkashClient.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.FeeGrant)
+	if !ok {
+		return nil, errors.New(errNotFeeGrant)
+	}
+
+	pcInfo, err := client.GetProviderConfig(ctx, c.kube, cr)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+
+	ak, err := c.newClient(ctx, c.kube, c.usage, mg, pcInfo)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+
+	return &external{client: ak}, nil
+}
+
+// An external grants and revokes fee allowances on chain.
+type external struct {
+	client client.AkashAPI
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.FeeGrant)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotFeeGrant)
+	}
+
+	granter, err := e.client.AccountAddress()
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGranter)
+	}
+	grant, err := e.client.GetFeeGrant(ctx, granter, cr.Spec.ForProvider.Grantee)
+	if client.IsFeeGrantNotFound(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetGrant)
+	}
+
+	granted := cr.Status.AtProvider.GrantedSpendLimit
+	cr.Status.AtProvider = observe(grant)
+	cr.Status.AtProvider.GrantedSpendLimit = granted
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: upToDate(cr.Spec.ForProvider, cr.Status.AtProvider)}, nil
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.FeeGrant)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotFeeGrant)
+	}
+
+	cr.SetConditions(xpv1.Creating())
+	if err := e.grant(ctx, cr); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	return managed.ExternalCreation{}, nil
+}
+
+// Update revokes the allowance and grants it again, as allowances cannot be
+// changed on chain.
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.FeeGrant)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotFeeGrant)
+	}
+
+	if err := e.client.RevokeFeeAllowance(ctx, cr.Spec.ForProvider.Grantee); err != nil && !client.IsFeeGrantNotFound(err) {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errRevoke)
+	}
+	return managed.ExternalUpdate{}, e.grant(ctx, cr)
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.FeeGrant)
+	if !ok {
+		return errors.New(errNotFeeGrant)
+	}
+
+	cr.SetConditions(xpv1.Deleting())
+	err := e.client.RevokeFeeAllowance(ctx, cr.Spec.ForProvider.Grantee)
+	return errors.Wrap(resource.Ignore(client.IsFeeGrantNotFound, err), errRevoke)
+}
+
+// grant grants the allowance of cr and records the spend limit it was
+// granted with.
+func (e *external) grant(ctx context.Context, cr *v1alpha1.FeeGrant) error {
+	if err := e.client.GrantFeeAllowance(ctx, cr.Spec.ForProvider.Grantee, allowance(cr.Spec.ForProvider)); err != nil {
+		return errors.Wrap(err, errGrant)
+	}
+	cr.Status.AtProvider.GrantedSpendLimit = cr.Spec.ForProvider.SpendLimit
+	return nil
+}
+
+// allowance returns the fee allowance described by p.
+func allowance(p v1alpha1.FeeGrantParameters) client.FeeAllowance {
+	a := client.FeeAllowance{SpendLimit: formatCoins(p.SpendLimit), AllowedMessages: p.AllowedMessages}
+	if p.Expiration != nil {
+		a.Expiration = &p.Expiration.Time
+	}
+	return a
+}
+
+// observe returns the observation of grant.
+func observe(grant akashtypes.FeeGrant) v1alpha1.FeeGrantObservation {
+	basic := grant.Allowance.Basic()
+	o := v1alpha1.FeeGrantObservation{Granter: grant.Granter, AllowedMessages: grant.Allowance.AllowedMessages}
+	for _, c := range basic.SpendLimit {
+		o.SpendLimit = append(o.SpendLimit, v1alpha1.Coin{Denom: c.Denom, Amount: c.Amount})
+	}
+	if t, err := time.Parse(time.RFC3339, basic.Expiration); err == nil {
+		o.Expiration = &metav1.Time{Time: t}
+	}
+	return o
+}
+
+// upToDate reports whether the allowance observed as o was granted as p
+// describes it.
+func upToDate(p v1alpha1.FeeGrantParameters, o v1alpha1.FeeGrantObservation) bool {
+	if formatCoins(p.SpendLimit) != formatCoins(o.GrantedSpendLimit) {
+		return false
+	}
+	if (p.Expiration == nil) != (o.Expiration == nil) {
+		return false
+	}
+	// The chain keeps expirations to the second.
+	if p.Expiration != nil && !p.Expiration.Time.Truncate(time.Second).Equal(o.Expiration.Time) {
+		return false
+	}
+	want := slices.Clone(p.AllowedMessages)
+	got := slices.Clone(o.AllowedMessages)
+	slices.Sort(want)
+	slices.Sort(got)
+	return slices.Equal(want, got)
+}
+
+// formatCoins formats coins the way the client parses them, e.g.
+// 5000000uakt,1000ibc/USDC.
+func formatCoins(coins []v1alpha1.Coin) string {
+	s := make([]string, 0, len(coins))
+	for _, c := range coins {
+		s = append(s, c.Amount+c.Denom)
+	}
+	return strings.Join(s, ",")
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feegrant
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+func TestObserve(t *testing.T) {
+	expiration := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		grant akashtypes.FeeGrant
+		want  v1alpha1.FeeGrantObservation
+	}{
+		"Basic": {
+			grant: akashtypes.FeeGrant{Granter: "akash1treasury", Allowance: akashtypes.Allowance{
+				Type:       akashtypes.AllowanceTypeBasic,
+				SpendLimit: []akashtypes.Coin{{Denom: "uakt", Amount: "400"}},
+				Expiration: "2025-01-01T00:00:00Z",
+			}},
+			want: v1alpha1.FeeGrantObservation{
+				Granter:    "akash1treasury",
+				SpendLimit: []v1alpha1.Coin{{Denom: "uakt", Amount: "400"}},
+				Expiration: &metav1.Time{Time: expiration},
+			},
+		},
+		"AllowedMessages": {
+			grant: akashtypes.FeeGrant{Granter: "akash1treasury", Allowance: akashtypes.Allowance{
+				Type:            akashtypes.AllowanceTypeAllowedMsg,
+				Allowance:       &akashtypes.Allowance{Type: akashtypes.AllowanceTypeBasic},
+				AllowedMessages: []string{"/akash.deployment.v1beta3.MsgCreateDeployment"},
+			}},
+			want: v1alpha1.FeeGrantObservation{
+				Granter:         "akash1treasury",
+				AllowedMessages: []string{"/akash.deployment.v1beta3.MsgCreateDeployment"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := observe(tc.grant)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("observe(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUpToDate(t *testing.T) {
+	expiration := metav1.NewTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	limit := []v1alpha1.Coin{{Denom: "uakt", Amount: "1000"}}
+
+	cases := map[string]struct {
+		p    v1alpha1.FeeGrantParameters
+		o    v1alpha1.FeeGrantObservation
+		want bool
+	}{
+		"UpToDate": {
+			p: v1alpha1.FeeGrantParameters{SpendLimit: limit, Expiration: &expiration, AllowedMessages: []string{"b", "a"}},
+			o: v1alpha1.FeeGrantObservation{
+				SpendLimit:        []v1alpha1.Coin{{Denom: "uakt", Amount: "400"}},
+				GrantedSpendLimit: limit,
+				Expiration:        &expiration,
+				AllowedMessages:   []string{"a", "b"},
+			},
+			want: true,
+		},
+		"SpendLimitChanged": {
+			p:    v1alpha1.FeeGrantParameters{SpendLimit: []v1alpha1.Coin{{Denom: "uakt", Amount: "2000"}}},
+			o:    v1alpha1.FeeGrantObservation{GrantedSpendLimit: limit},
+			want: false,
+		},
+		"ExpirationRemoved": {
+			p:    v1alpha1.FeeGrantParameters{},
+			o:    v1alpha1.FeeGrantObservation{Expiration: &expiration},
+			want: false,
+		},
+		"AllowedMessagesChanged": {
+			p:    v1alpha1.FeeGrantParameters{AllowedMessages: []string{"a"}},
+			o:    v1alpha1.FeeGrantObservation{},
+			want: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := upToDate(tc.p, tc.o); got != tc.want {
+				t.Errorf("upToDate(...): want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
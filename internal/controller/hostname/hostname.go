@@ -0,0 +1,232 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hostname binds custom domains to the leases of deployments.
+package hostname
+
+import (
+	"context"
+	"slices"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	apisv1alpha1 "github.com/overlock-network/provider-akash/apis/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+	"github.com/overlock-network/provider-akash/internal/features"
+	"github.com/overlock-network/provider-akash/internal/tracing"
+)
+
+const (
+	errNotHostname     = "managed resource is not a Hostname custom resource"
+	errGetPC           = "cannot get ProviderConfig"
+	errNewClient       = "cannot create new client"
+	errGetDeployment   = "cannot get Deployment"
+	errNotDeployed     = "deployment %s was not created on chain yet"
+	errOwner           = "cannot determine owner"
+	errGetLeases       = "cannot query leases"
+	errNoLease         = "group %d of deployment %s has no active lease"
+	errGetLeaseStatus  = "cannot query lease status"
+	errMigrateHostname = "cannot migrate hostname"
+)
+
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=hostnames,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=hostnames/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=deployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigusages,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Setup adds a controller that reconciles Hostname managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	name := managed.ControllerName(v1alpha1.HostnameGroupKind)
+
+	opts := []managed.ReconcilerOption{
+		managed.WithExternalConnecter(tracing.NewConnecter(v1alpha1.HostnameKind, &connector{
+			kube:      mgr.GetClient(),
+			usage:     resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClient: client.NewAPIFromManagedResource,
+		})),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+	}
+	if o.Features.Enabled(features.EnableAlphaManagementPolicies) {
+		opts = append(opts, managed.WithManagementPolicies())
+	}
+
+	r := managed.NewReconciler(mgr, resource.ManagedKind(v1alpha1.HostnameGroupVersionKind), opts...)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Hostname{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// A connector produces an external client for the ProviderConfig of a
+// Hostname.
+type connector struct {
+	kube      kubeclient.Client
+	usage     resource.Tracker
+	newClient func(ctx context.Context, kube kubeclient.Client, usage resource.Tracker, mg resource.Managed, pcInfo client.ProviderConfigInfo) (client.AkashAPI, error)
+}
+
+// Connect produces an ExternalClient with a ready-to-use AkashClient.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.Hostname)
+	if !ok {
+		return nil, errors.New(errNotHostname)
+	}
+
+	pcInfo, err := client.GetProviderConfig(ctx, c.kube, cr)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+
+	ak, err := c.newClient(ctx, c.kube, c.usage, mg, pcInfo)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+
+	return &external{client: ak, kube: c.kube}, nil
+}
+
+// An external binds a hostname to a lease through the gateway of its
+// provider.
+type external struct {
+	client client.AkashAPI
+	kube   kubeclient.Client
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Hostname)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotHostname)
+	}
+
+	// Hostnames are released by the provider once no lease serves them, so
+	// the Hostname is gone once it is deleted.
+	if meta.WasDeleted(cr) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	id, err := e.lease(ctx, cr)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	status, err := e.client.GetLeaseStatus(ctx, id)
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetLeaseStatus)
+	}
+
+	service, ok := servedBy(status, cr.Spec.ForProvider.Hostname)
+	if !ok {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	cr.Status.AtProvider = v1alpha1.HostnameObservation{Dseq: id.Dseq, Provider: id.Provider, Service: service}
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, nil
+}
+
+// Create migrates the hostname to the lease of the deployment. The provider
+// refuses hostnames served by deployments of other owners.
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Hostname)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotHostname)
+	}
+
+	id, err := e.lease(ctx, cr)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	cr.SetConditions(xpv1.Creating())
+
+	return managed.ExternalCreation{}, errors.Wrap(e.client.MigrateHostname(ctx, cr.Spec.ForProvider.Hostname, id), errMigrateHostname)
+}
+
+// Update does nothing: a hostname no longer served by the lease, e.g. after
+// the deployment was recreated, is observed as missing and migrated again.
+func (e *external) Update(_ context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
+	return managed.ExternalUpdate{}, nil
+}
+
+// Delete leaves the hostname with its deployment: the gateway has no API to
+// release it.
+func (e *external) Delete(_ context.Context, _ resource.Managed) error {
+	return nil
+}
+
+// lease returns the active lease of the group of the deployment that should
+// serve the hostname of cr.
+func (e *external) lease(ctx context.Context, cr *v1alpha1.Hostname) (akashtypes.LeaseId, error) {
+	p := cr.Spec.ForProvider
+	d := &v1alpha1.Deployment{}
+	if err := e.kube.Get(ctx, types.NamespacedName{Name: p.Deployment}, d); err != nil {
+		return akashtypes.LeaseId{}, errors.Wrap(err, errGetDeployment)
+	}
+	dseq := d.Status.AtProvider.Dseq
+	if dseq == "" {
+		return akashtypes.LeaseId{}, errors.Errorf(errNotDeployed, p.Deployment)
+	}
+
+	owner, err := e.client.AccountAddress()
+	if err != nil {
+		return akashtypes.LeaseId{}, errors.Wrap(err, errOwner)
+	}
+	leases, err := e.client.GetActiveLeases(ctx, dseq, owner)
+	if err != nil {
+		return akashtypes.LeaseId{}, errors.Wrap(err, errGetLeases)
+	}
+
+	gseq := p.Gseq
+	if gseq == 0 {
+		gseq = 1
+	}
+	for _, l := range leases {
+		if l.Lease.LeaseId.Gseq == gseq {
+			return l.Lease.LeaseId, nil
+		}
+	}
+	return akashtypes.LeaseId{}, errors.Errorf(errNoLease, gseq, dseq)
+}
+
+// servedBy returns the service of a lease with the given status that serves
+// hostname, if any.
+func servedBy(status akashtypes.LeaseStatus, hostname string) (string, bool) {
+	for _, name := range akashtypes.SortedServiceNames(status.Services) {
+		if slices.Contains(status.Services[name].URIs, hostname) {
+			return name, true
+		}
+	}
+	return "", false
+}
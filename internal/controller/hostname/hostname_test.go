@@ -0,0 +1,61 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hostname
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+func TestServedBy(t *testing.T) {
+	status := akashtypes.LeaseStatus{Services: map[string]akashtypes.ServiceStatus{
+		"api": {Name: "api", URIs: []string{"abc.ingress.provider.com", "api.example.com"}},
+		"web": {Name: "web", URIs: []string{"www.example.com"}},
+	}}
+
+	type want struct {
+		service string
+		ok      bool
+	}
+
+	cases := map[string]struct {
+		hostname string
+		want     want
+	}{
+		"Served": {
+			hostname: "api.example.com",
+			want:     want{service: "api", ok: true},
+		},
+		"NotServed": {
+			hostname: "shop.example.com",
+			want:     want{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			service, ok := servedBy(status, tc.hostname)
+			got := want{service: service, ok: ok}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("servedBy(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
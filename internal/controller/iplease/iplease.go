@@ -0,0 +1,232 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package iplease observes the IP endpoints leased to deployments.
+package iplease
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	apisv1alpha1 "github.com/overlock-network/provider-akash/apis/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+	"github.com/overlock-network/provider-akash/internal/features"
+	"github.com/overlock-network/provider-akash/internal/tracing"
+)
+
+const (
+	errNotIPLease     = "managed resource is not an IPLease custom resource"
+	errGetPC          = "cannot get ProviderConfig"
+	errNewClient      = "cannot create new client"
+	errGetDeployment  = "cannot get Deployment"
+	errNotDeployed    = "deployment %s was not created on chain yet"
+	errOwner          = "cannot determine owner"
+	errGetLeases      = "cannot query leases"
+	errGetLeaseStatus = "cannot query lease status"
+)
+
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=ipleases,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=ipleases/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=deployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigusages,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=storeconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Setup adds a controller that reconciles IPLease managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	name := managed.ControllerName(v1alpha1.IPLeaseGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	opts := []managed.ReconcilerOption{
+		managed.WithExternalConnecter(tracing.NewConnecter(v1alpha1.IPLeaseKind, &connector{
+			kube:      mgr.GetClient(),
+			usage:     resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClient: client.NewAPIFromManagedResource,
+		})),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithConnectionPublishers(cps...),
+	}
+	if o.Features.Enabled(features.EnableAlphaManagementPolicies) {
+		opts = append(opts, managed.WithManagementPolicies())
+	}
+
+	r := managed.NewReconciler(mgr, resource.ManagedKind(v1alpha1.IPLeaseGroupVersionKind), opts...)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.IPLease{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// A connector produces an external client for the ProviderConfig of an
+// IPLease.
+type connector struct {
+	kube      kubeclient.Client
+	usage     resource.Tracker
+	newClient func(ctx context.Context, kube kubeclient.Client, usage resource.Tracker, mg resource.Managed, pcInfo client.ProviderConfigInfo) (client.AkashAPI, error)
+}
+
+// Connect produces an ExternalClient with a ready-to-use AkashClient.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.IPLease)
+	if !ok {
+		return nil, errors.New(errNotIPLease)
+	}
+
+	pcInfo, err := client.GetProviderConfig(ctx, c.kube, cr)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+
+	ak, err := c.newClient(ctx, c.kube, c.usage, mg, pcInfo)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+
+	return &external{client: ak, kube: c.kube}, nil
+}
+
+// An external observes the IP endpoints leased to a deployment through the
+// gateways of its providers. IP endpoints are leased and released together
+// with the deployment, so they are never created, updated or deleted.
+type external struct {
+	client client.AkashAPI
+	kube   kubeclient.Client
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.IPLease)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotIPLease)
+	}
+
+	if meta.WasDeleted(cr) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	d := &v1alpha1.Deployment{}
+	if err := e.kube.Get(ctx, types.NamespacedName{Name: cr.Spec.ForProvider.Deployment}, d); err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetDeployment)
+	}
+	dseq := d.Status.AtProvider.Dseq
+	if dseq == "" {
+		return managed.ExternalObservation{}, errors.Errorf(errNotDeployed, cr.Spec.ForProvider.Deployment)
+	}
+
+	owner, err := e.client.AccountAddress()
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errOwner)
+	}
+	leases, err := e.client.GetActiveLeases(ctx, dseq, owner)
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetLeases)
+	}
+	statuses := make([]akashtypes.LeaseStatus, 0, len(leases))
+	for _, l := range leases {
+		s, err := e.client.GetLeaseStatus(ctx, l.Lease.LeaseId)
+		if err != nil {
+			return managed.ExternalObservation{}, errors.Wrap(err, errGetLeaseStatus)
+		}
+		statuses = append(statuses, s)
+	}
+
+	_, ips := client.LeaseEndpoints(akashtypes.MergeLeaseStatuses(statuses...))
+	ips = serviceIPs(ips, cr.Spec.ForProvider.Service)
+	cr.Status.AtProvider = v1alpha1.IPLeaseObservation{Dseq: dseq, IPs: ips}
+
+	// The provider assigns the IPs once the manifest was deployed.
+	if len(ips) == 0 {
+		cr.SetConditions(xpv1.Unavailable())
+	} else {
+		cr.SetConditions(xpv1.Available())
+	}
+
+	return managed.ExternalObservation{
+		ResourceExists:    true,
+		ResourceUpToDate:  true,
+		ConnectionDetails: connectionDetails(ips),
+	}, nil
+}
+
+func (e *external) Create(_ context.Context, _ resource.Managed) (managed.ExternalCreation, error) {
+	return managed.ExternalCreation{}, nil
+}
+
+func (e *external) Update(_ context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
+	return managed.ExternalUpdate{}, nil
+}
+
+func (e *external) Delete(_ context.Context, _ resource.Managed) error {
+	return nil
+}
+
+// serviceIPs returns the ips of service, or all ips if service is nil.
+func serviceIPs(ips []v1alpha1.LeasedIP, service *string) []v1alpha1.LeasedIP {
+	if service == nil {
+		return ips
+	}
+	var filtered []v1alpha1.LeasedIP
+	for _, ip := range ips {
+		if ip.Service == *service {
+			filtered = append(filtered, ip)
+		}
+	}
+	return filtered
+}
+
+// connectionDetails returns the first IP keyed by ip, the first IP of every
+// service keyed by <service>.ip, and the address of every port keyed by
+// <service>.<port>.<protocol>.ip.
+func connectionDetails(ips []v1alpha1.LeasedIP) managed.ConnectionDetails {
+	details := managed.ConnectionDetails{}
+	for _, ip := range ips {
+		if _, ok := details["ip"]; !ok {
+			details["ip"] = []byte(ip.IP)
+		}
+		if _, ok := details[ip.Service+".ip"]; !ok {
+			details[ip.Service+".ip"] = []byte(ip.IP)
+		}
+		key := fmt.Sprintf("%s.%d.%s.ip", ip.Service, ip.Port, strings.ToLower(ip.Protocol))
+		details[key] = []byte(fmt.Sprintf("%s:%d", ip.IP, ip.ExternalPort))
+	}
+	return details
+}
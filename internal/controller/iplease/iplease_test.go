@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package iplease
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+)
+
+func TestConnectionDetails(t *testing.T) {
+	web := "web"
+	ips := []v1alpha1.LeasedIP{
+		{Service: "db", IP: "203.0.113.7", Port: 5432, ExternalPort: 5432, Protocol: "TCP"},
+		{Service: "web", IP: "203.0.113.9", Port: 80, ExternalPort: 80, Protocol: "TCP"},
+		{Service: "web", IP: "203.0.113.9", Port: 443, ExternalPort: 443, Protocol: "TCP"},
+	}
+
+	cases := map[string]struct {
+		service *string
+		want    managed.ConnectionDetails
+	}{
+		"AllServices": {
+			want: managed.ConnectionDetails{
+				"ip":             []byte("203.0.113.7"),
+				"db.ip":          []byte("203.0.113.7"),
+				"db.5432.tcp.ip": []byte("203.0.113.7:5432"),
+				"web.ip":         []byte("203.0.113.9"),
+				"web.80.tcp.ip":  []byte("203.0.113.9:80"),
+				"web.443.tcp.ip": []byte("203.0.113.9:443"),
+			},
+		},
+		"OneService": {
+			service: &web,
+			want: managed.ConnectionDetails{
+				"ip":             []byte("203.0.113.9"),
+				"web.ip":         []byte("203.0.113.9"),
+				"web.80.tcp.ip":  []byte("203.0.113.9:80"),
+				"web.443.tcp.ip": []byte("203.0.113.9:443"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := connectionDetails(serviceIPs(ips, tc.service))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("connectionDetails(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package manifest submits manifests of deployments to the providers leasing
+// them.
+package manifest
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	apisv1alpha1 "github.com/overlock-network/provider-akash/apis/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+	gateway "github.com/overlock-network/provider-akash/internal/client/provider-gateway"
+	"github.com/overlock-network/provider-akash/internal/features"
+	"github.com/overlock-network/provider-akash/internal/sdl"
+	"github.com/overlock-network/provider-akash/internal/tracing"
+)
+
+const (
+	errNotManifest   = "managed resource is not a Manifest custom resource"
+	errGetPC         = "cannot get ProviderConfig"
+	errNewClient     = "cannot create new client"
+	errGetManifest   = "cannot get manifest from provider"
+	errStoredVersion = "cannot determine version of the stored manifest"
+	errSDLVersion    = "cannot determine version of the SDL manifest"
+	errSubmit        = "cannot submit manifest"
+)
+
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=manifests,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=manifests/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigusages,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Setup adds a controller that reconciles Manifest managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	name := managed.ControllerName(v1alpha1.ManifestGroupKind)
+
+	opts := []managed.ReconcilerOption{
+		managed.WithExternalConnecter(tracing.NewConnecter(v1alpha1.ManifestKind, &connector{
+			kube:      mgr.GetClient(),
+			usage:     resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClient: client.NewAPIFromManagedResource,
+		})),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+	}
+	if o.Features.Enabled(features.EnableAlphaManagementPolicies) {
+		opts = append(opts, managed.WithManagementPolicies())
+	}
+
+	r := managed.NewReconciler(mgr, resource.ManagedKind(v1alpha1.ManifestGroupVersionKind), opts...)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Manifest{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// A connector produces an external client for the ProviderConfig of a
+// Manifest.
+type connector struct {
+	kube      kubeclient.Client
+	usage     resource.Tracker
+	newClient func(ctx context.Context, kube kubeclient.Client, usage resource.Tracker, mg resource.Managed, pcInfo client.ProviderConfigInfo) (client.AkashAPI, error)
+}
+
+// Connect produces an ExternalClient with a ready-to-use AkashClient.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.Manifest)
+	if !ok {
+		return nil, errors.New(errNotManifest)
+	}
+
+	pcInfo, err := client.GetProviderConfig(ctx, c.kube, cr)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+
+	ak, err := c.newClient(ctx, c.kube, c.usage, mg, pcInfo)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+
+	return &external{client: ak}, nil
+}
+
+// An external submits manifests to the gateways of providers. Manifests are
+// never deleted.
+type external struct {
+	client client.AkashAPI
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Manifest)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotManifest)
+	}
+
+	// Providers drop manifests with their leases, so the Manifest is gone
+	// once it is deleted.
+	if meta.WasDeleted(cr) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	p := cr.Spec.ForProvider
+	stored, err := e.client.GetManifest(ctx, p.Dseq, p.Provider)
+	if gateway.IsManifestNotFound(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetManifest)
+	}
+
+	observed, desired, err := versions(stored, p.SDL)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	cr.Status.AtProvider.Version = observed
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: observed == desired}, nil
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Manifest)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotManifest)
+	}
+
+	cr.SetConditions(xpv1.Creating())
+	return managed.ExternalCreation{}, e.submit(ctx, cr)
+}
+
+// Update submits the manifest again, replacing the manifest stored by the
+// provider.
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Manifest)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotManifest)
+	}
+
+	return managed.ExternalUpdate{}, e.submit(ctx, cr)
+}
+
+func (e *external) Delete(_ context.Context, _ resource.Managed) error {
+	return nil
+}
+
+// submit submits the manifest of the SDL of cr to its provider.
+func (e *external) submit(ctx context.Context, cr *v1alpha1.Manifest) error {
+	p := cr.Spec.ForProvider
+	return errors.Wrap(e.client.SubmitManifest(ctx, p.Dseq, p.Provider, []byte(p.SDL)), errSubmit)
+}
+
+// versions returns the base64 encoded versions of the manifest stored by a
+// provider and of the manifest of the SDL content.
+func versions(stored []byte, content string) (observed string, desired string, err error) {
+	v, err := sdl.RawManifestVersion(stored)
+	if err != nil {
+		return "", "", errors.Wrap(err, errStoredVersion)
+	}
+	_, manifests, err := sdl.ParseSDL([]byte(content))
+	if err != nil {
+		return "", "", errors.Wrap(err, errSDLVersion)
+	}
+	d, err := sdl.ManifestVersion(manifests)
+	if err != nil {
+		return "", "", errors.Wrap(err, errSDLVersion)
+	}
+	return base64.StdEncoding.EncodeToString(v), base64.StdEncoding.EncodeToString(d), nil
+}
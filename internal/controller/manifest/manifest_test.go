@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/overlock-network/provider-akash/internal/sdl"
+)
+
+const example = `---
+version: "2.0"
+services:
+  web:
+    image: nginx:1.25
+    expose:
+      - port: 80
+        to:
+          - global: true
+profiles:
+  compute:
+    web:
+      resources:
+        cpu:
+          units: 0.5
+        memory:
+          size: 512Mi
+        storage:
+          size: 1Gi
+  placement:
+    dcloud:
+      pricing:
+        web:
+          denom: uakt
+          amount: 1000
+deployment:
+  web:
+    dcloud:
+      profile: web
+      count: 1
+`
+
+func TestVersions(t *testing.T) {
+	_, manifests, err := sdl.ParseSDL([]byte(example))
+	if err != nil {
+		t.Fatalf("ParseSDL() unexpected error: %v", err)
+	}
+	stored, err := sdl.MarshalManifest(manifests)
+	if err != nil {
+		t.Fatalf("MarshalManifest() unexpected error: %v", err)
+	}
+
+	cases := map[string]struct {
+		stored       []byte
+		sdl          string
+		wantUpToDate bool
+		wantErr      bool
+	}{
+		"UpToDate": {
+			stored:       stored,
+			sdl:          example,
+			wantUpToDate: true,
+		},
+		"ImageChanged": {
+			stored: stored,
+			sdl:    strings.Replace(example, "nginx:1.25", "nginx:1.26", 1),
+		},
+		"InvalidStoredManifest": {
+			stored:  []byte(`{}`),
+			sdl:     example,
+			wantErr: true,
+		},
+		"InvalidSDL": {
+			stored:  stored,
+			sdl:     "services: {}",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			observed, desired, err := versions(tc.stored, tc.sdl)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("versions(...): want error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("versions(...): unexpected error: %v", err)
+			}
+			if got := observed == desired; got != tc.wantUpToDate {
+				t.Errorf("versions(...): want up to date %t, got %t", tc.wantUpToDate, got)
+			}
+		})
+	}
+}
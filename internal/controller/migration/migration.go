@@ -0,0 +1,235 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package migration moves objects of the API groups the provider used to
+// serve, akash.web7.md and resource.akash.web7.md, to the akash.overlock.network
+// groups that replaced them.
+package migration
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	apisv1alpha1 "github.com/overlock-network/provider-akash/apis/v1alpha1"
+)
+
+const (
+	// LegacyGroup and LegacyResourceGroup are the API groups of
+	// apisv1alpha1.Group and v1alpha1.Group before they were renamed.
+	LegacyGroup         = "akash.web7.md"
+	LegacyResourceGroup = "resource.akash.web7.md"
+
+	// AnnotationKeyMigratedFrom is set on migrated objects to the API
+	// version of the object they were migrated from.
+	AnnotationKeyMigratedFrom = "akash.web7.md/migrated-from"
+
+	errGetLegacy      = "cannot get legacy object"
+	errGetMigrated    = "cannot get migrated object"
+	errCreateMigrated = "cannot create migrated object"
+	errUpdateStatus   = "cannot update status of migrated object"
+	errReleaseLegacy  = "cannot release legacy object"
+	errDeleteLegacy   = "cannot delete legacy object"
+	errNotMigrated    = "%s %s already exists and was not migrated from %s"
+
+	migrationController = "migration"
+)
+
+// kinds are migrated to their group version kinds. ProviderConfigUsages are
+// not: the usages of migrated resources are tracked anew, and legacy usages
+// are garbage collected with the legacy resources owning them.
+var kinds = []schema.GroupVersionKind{
+	apisv1alpha1.ProviderConfigGroupVersionKind,
+	apisv1alpha1.StoreConfigGroupVersionKind,
+	v1alpha1.AccountGroupVersionKind,
+	v1alpha1.AuditGroupVersionKind,
+	v1alpha1.AuthzGrantGroupVersionKind,
+	v1alpha1.DeploymentGroupVersionKind,
+	v1alpha1.FailoverRecordGroupVersionKind,
+	v1alpha1.FeeGrantGroupVersionKind,
+	v1alpha1.HostnameGroupVersionKind,
+	v1alpha1.IPLeaseGroupVersionKind,
+	v1alpha1.ManifestGroupVersionKind,
+	v1alpha1.ProviderGroupVersionKind,
+}
+
+// +kubebuilder:rbac:groups=akash.web7.md,resources=*,verbs=get;list;watch;update;patch;delete
+// +kubebuilder:rbac:groups=resource.akash.web7.md,resources=*,verbs=get;list;watch;update;patch;delete
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=*,verbs=get;create
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=*/status,verbs=update
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=*,verbs=get;create
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=*/status,verbs=update
+
+// Setup adds a controller per kind that migrates the objects of its legacy
+// group to its group. Kinds whose legacy CRD is not installed are skipped.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	for _, gvk := range kinds {
+		legacy := legacyGroupVersionKind(gvk)
+		if _, err := mgr.GetRESTMapper().RESTMapping(legacy.GroupKind(), legacy.Version); err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return errors.Wrapf(err, "cannot map %s", legacy)
+		}
+
+		name := migrationController + "/" + legacy.GroupKind().String()
+		r := &reconciler{
+			kube:   mgr.GetClient(),
+			log:    o.Logger.WithValues("controller", name),
+			legacy: legacy,
+			gvk:    gvk,
+		}
+
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(legacy)
+		if err := ctrl.NewControllerManagedBy(mgr).
+			Named(name).
+			WithOptions(o.ForControllerRuntime()).
+			For(u).
+			Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// legacyGroupVersionKind returns the group version kind gvk was served as
+// before its group was renamed.
+func legacyGroupVersionKind(gvk schema.GroupVersionKind) schema.GroupVersionKind {
+	switch gvk.Group {
+	case apisv1alpha1.Group:
+		gvk.Group = LegacyGroup
+	case v1alpha1.Group:
+		gvk.Group = LegacyResourceGroup
+	}
+	return gvk
+}
+
+type reconciler struct {
+	kube   client.Client
+	log    logging.Logger
+	legacy schema.GroupVersionKind
+	gvk    schema.GroupVersionKind
+}
+
+// Reconcile creates the migrated twin of a legacy object, then deletes the
+// legacy object without deleting its external resource: its deletion policy
+// is set to Orphan and its finalizers, which no controller removes anymore,
+// are dropped.
+func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	legacy := &unstructured.Unstructured{}
+	legacy.SetGroupVersionKind(r.legacy)
+	if err := r.kube.Get(ctx, req.NamespacedName, legacy); err != nil {
+		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetLegacy)
+	}
+
+	log := r.log.WithValues("name", legacy.GetName(), "namespace", legacy.GetNamespace())
+
+	if err := r.migrate(ctx, legacy); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if _, managed, _ := unstructured.NestedFieldNoCopy(legacy.Object, "spec", "forProvider"); managed {
+		if err := unstructured.SetNestedField(legacy.Object, string(xpv1.DeletionOrphan), "spec", "deletionPolicy"); err != nil {
+			return reconcile.Result{}, errors.Wrap(err, errReleaseLegacy)
+		}
+	}
+	legacy.SetFinalizers(nil)
+	if err := r.kube.Update(ctx, legacy); err != nil {
+		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errReleaseLegacy)
+	}
+	if err := r.kube.Delete(ctx, legacy); resource.IgnoreNotFound(err) != nil {
+		return reconcile.Result{}, errors.Wrap(err, errDeleteLegacy)
+	}
+
+	log.Info("Migrated legacy object", "from", r.legacy.GroupVersion().String(), "to", r.gvk.GroupVersion().String())
+	return reconcile.Result{}, nil
+}
+
+// migrate creates the twin of legacy in the group of r, unless it was already
+// created. It fails when an object of the same name exists that was not
+// migrated from legacy, which is then left in place.
+func (r *reconciler) migrate(ctx context.Context, legacy *unstructured.Unstructured) error {
+	u := migrated(legacy, r.gvk)
+	err := r.kube.Create(ctx, u)
+	if kerrors.IsAlreadyExists(err) {
+		existing := &unstructured.Unstructured{}
+		existing.SetGroupVersionKind(r.gvk)
+		if err := r.kube.Get(ctx, client.ObjectKeyFromObject(u), existing); err != nil {
+			return errors.Wrap(err, errGetMigrated)
+		}
+		if existing.GetAnnotations()[AnnotationKeyMigratedFrom] != legacy.GetAPIVersion() {
+			return errors.Errorf(errNotMigrated, r.gvk.Kind, u.GetName(), legacy.GetAPIVersion())
+		}
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, errCreateMigrated)
+	}
+
+	// The status of kinds without a status subresource was created with the
+	// object.
+	status, ok := legacy.Object["status"]
+	if !ok {
+		return nil
+	}
+	u.Object["status"] = runtime.DeepCopyJSONValue(status)
+	return errors.Wrap(resource.IgnoreNotFound(r.kube.Status().Update(ctx, u)), errUpdateStatus)
+}
+
+// migrated returns the twin of legacy as an object of gvk: its name, labels,
+// annotations, including its external name, owners, spec and status.
+func migrated(legacy *unstructured.Unstructured, gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]any{}}
+	u.SetGroupVersionKind(gvk)
+	u.SetName(legacy.GetName())
+	u.SetNamespace(legacy.GetNamespace())
+	u.SetLabels(legacy.GetLabels())
+	u.SetOwnerReferences(legacy.GetOwnerReferences())
+
+	annotations := map[string]string{}
+	for k, v := range legacy.GetAnnotations() {
+		// The last applied configuration is of the legacy object.
+		if !strings.HasPrefix(k, "kubectl.kubernetes.io/") {
+			annotations[k] = v
+		}
+	}
+	annotations[AnnotationKeyMigratedFrom] = legacy.GetAPIVersion()
+	u.SetAnnotations(annotations)
+
+	for field, v := range legacy.Object {
+		if field != "apiVersion" && field != "kind" && field != "metadata" {
+			u.Object[field] = runtime.DeepCopyJSONValue(v)
+		}
+	}
+	return u
+}
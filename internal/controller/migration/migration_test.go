@@ -0,0 +1,237 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	apisv1alpha1 "github.com/overlock-network/provider-akash/apis/v1alpha1"
+)
+
+func object(gvk schema.GroupVersionKind, namespace, name string, fields map[string]any) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: runtime.DeepCopyJSON(fields)}
+	u.SetGroupVersionKind(gvk)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	return u
+}
+
+func legacyDeployment() *unstructured.Unstructured {
+	u := object(legacyGroupVersionKind(v1alpha1.DeploymentGroupVersionKind), "default", "web", map[string]any{
+		"spec": map[string]any{
+			"deletionPolicy": "Delete",
+			"forProvider":    map[string]any{"sdl": "version: \"2.0\""},
+		},
+		"status": map[string]any{
+			"atProvider": map[string]any{"state": "active"},
+		},
+	})
+	u.SetAnnotations(map[string]string{
+		"crossplane.io/external-name":                      "12345",
+		"kubectl.kubernetes.io/last-applied-configuration": "{}",
+	})
+	u.SetLabels(map[string]string{"app": "web"})
+	u.SetFinalizers([]string{"finalizer.managedresource.crossplane.io"})
+	return u
+}
+
+func TestReconcile(t *testing.T) {
+	deployment := v1alpha1.DeploymentGroupVersionKind
+	pc := apisv1alpha1.ProviderConfigGroupVersionKind
+
+	type want struct {
+		err            bool
+		migrated       *unstructured.Unstructured
+		legacyDeleted  bool
+		deletionPolicy string
+	}
+	cases := map[string]struct {
+		gvk     schema.GroupVersionKind
+		key     types.NamespacedName
+		objects []client.Object
+		want    want
+	}{
+		"MigratesManagedResource": {
+			gvk:     deployment,
+			key:     types.NamespacedName{Namespace: "default", Name: "web"},
+			objects: []client.Object{legacyDeployment()},
+			want: want{
+				migrated: func() *unstructured.Unstructured {
+					u := object(deployment, "default", "web", map[string]any{
+						"spec": map[string]any{
+							"deletionPolicy": "Delete",
+							"forProvider":    map[string]any{"sdl": "version: \"2.0\""},
+						},
+						"status": map[string]any{
+							"atProvider": map[string]any{"state": "active"},
+						},
+					})
+					u.SetAnnotations(map[string]string{
+						"crossplane.io/external-name": "12345",
+						AnnotationKeyMigratedFrom:     "resource.akash.web7.md/v1alpha1",
+					})
+					u.SetLabels(map[string]string{"app": "web"})
+					return u
+				}(),
+				legacyDeleted:  true,
+				deletionPolicy: "Orphan",
+			},
+		},
+		"MigratesProviderConfig": {
+			gvk: pc,
+			key: types.NamespacedName{Name: "default"},
+			objects: []client.Object{func() *unstructured.Unstructured {
+				u := object(legacyGroupVersionKind(pc), "", "default", map[string]any{
+					"spec": map[string]any{"net": "mainnet"},
+				})
+				u.SetFinalizers([]string{"in-use.crossplane.io"})
+				return u
+			}()},
+			want: want{
+				migrated: func() *unstructured.Unstructured {
+					u := object(pc, "", "default", map[string]any{
+						"spec": map[string]any{"net": "mainnet"},
+					})
+					u.SetAnnotations(map[string]string{AnnotationKeyMigratedFrom: "akash.web7.md/v1alpha1"})
+					return u
+				}(),
+				legacyDeleted: true,
+			},
+		},
+		"AlreadyMigrated": {
+			gvk: deployment,
+			key: types.NamespacedName{Namespace: "default", Name: "web"},
+			objects: []client.Object{
+				legacyDeployment(),
+				func() *unstructured.Unstructured {
+					u := object(deployment, "default", "web", map[string]any{"spec": map[string]any{}})
+					u.SetAnnotations(map[string]string{AnnotationKeyMigratedFrom: "resource.akash.web7.md/v1alpha1"})
+					return u
+				}(),
+			},
+			want: want{
+				migrated: func() *unstructured.Unstructured {
+					u := object(deployment, "default", "web", map[string]any{"spec": map[string]any{}})
+					u.SetAnnotations(map[string]string{AnnotationKeyMigratedFrom: "resource.akash.web7.md/v1alpha1"})
+					return u
+				}(),
+				legacyDeleted:  true,
+				deletionPolicy: "Orphan",
+			},
+		},
+		"NameTaken": {
+			gvk: deployment,
+			key: types.NamespacedName{Namespace: "default", Name: "web"},
+			objects: []client.Object{
+				legacyDeployment(),
+				object(deployment, "default", "web", map[string]any{"spec": map[string]any{}}),
+			},
+			want: want{
+				err:      true,
+				migrated: object(deployment, "default", "web", map[string]any{"spec": map[string]any{}}),
+			},
+		},
+		"LegacyGone": {
+			gvk:  deployment,
+			key:  types.NamespacedName{Namespace: "default", Name: "web"},
+			want: want{legacyDeleted: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			legacy := legacyGroupVersionKind(tc.gvk)
+			mapper := meta.NewDefaultRESTMapper(nil)
+			for _, gvk := range []schema.GroupVersionKind{tc.gvk, legacy} {
+				scope := meta.RESTScopeNamespace
+				if tc.key.Namespace == "" {
+					scope = meta.RESTScopeRoot
+				}
+				mapper.Add(gvk, scope)
+			}
+
+			var deletionPolicy string
+			kube := fake.NewClientBuilder().
+				WithScheme(runtime.NewScheme()).
+				WithRESTMapper(mapper).
+				WithObjects(tc.objects...).
+				WithStatusSubresource(object(tc.gvk, "", "", nil), object(legacy, "", "", nil)).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+						u := &unstructured.Unstructured{}
+						u.SetGroupVersionKind(legacy)
+						if err := c.Get(ctx, client.ObjectKeyFromObject(obj), u); err != nil {
+							return err
+						}
+						deletionPolicy, _, _ = unstructured.NestedString(u.Object, "spec", "deletionPolicy")
+						return c.Delete(ctx, obj, opts...)
+					},
+				}).
+				Build()
+
+			r := &reconciler{kube: kube, log: logging.NewNopLogger(), legacy: legacy, gvk: tc.gvk}
+			_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: tc.key})
+			if (err != nil) != tc.want.err {
+				t.Fatalf("Reconcile(...): want error %t, got %v", tc.want.err, err)
+			}
+
+			got := &unstructured.Unstructured{}
+			got.SetGroupVersionKind(tc.gvk)
+			err = kube.Get(context.Background(), tc.key, got)
+			if tc.want.migrated == nil {
+				if !kerrors.IsNotFound(err) {
+					t.Errorf("Get(migrated): want not found, got %v", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := tc.want.migrated.Object
+				delete(got.Object["metadata"].(map[string]any), "resourceVersion")
+				if diff := cmp.Diff(want, got.Object); diff != "" {
+					t.Errorf("migrated: -want, +got:\n%s", diff)
+				}
+			}
+
+			u := &unstructured.Unstructured{}
+			u.SetGroupVersionKind(legacy)
+			err = kube.Get(context.Background(), tc.key, u)
+			if deleted := kerrors.IsNotFound(err); deleted != tc.want.legacyDeleted {
+				t.Errorf("legacy deleted: want %t, got %t (%v)", tc.want.legacyDeleted, deleted, err)
+			}
+			if deletionPolicy != tc.want.deletionPolicy {
+				t.Errorf("deletion policy of the deleted legacy object: want %q, got %q", tc.want.deletionPolicy, deletionPolicy)
+			}
+		})
+	}
+}
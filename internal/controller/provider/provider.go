@@ -0,0 +1,211 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package provider observes providers of the on-chain provider directory.
+package provider
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	apisv1alpha1 "github.com/overlock-network/provider-akash/apis/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+	gateway "github.com/overlock-network/provider-akash/internal/client/provider-gateway"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+	"github.com/overlock-network/provider-akash/internal/features"
+	"github.com/overlock-network/provider-akash/internal/tracing"
+)
+
+const (
+	errNotProvider     = "managed resource is not a Provider custom resource"
+	errGetPC           = "cannot get ProviderConfig"
+	errNewClient       = "cannot create new client"
+	errGetProvider     = "cannot query provider"
+	errProviderMissing = "provider %s is not registered on chain"
+	errGetAuditors     = "cannot query auditors"
+	errGetProviders    = "cannot query providers API"
+)
+
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=providers,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=resource.akash.overlock.network,resources=providers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=akash.overlock.network,resources=providerconfigusages,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Setup adds a controller that reconciles Provider managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	name := managed.ControllerName(v1alpha1.ProviderGroupKind)
+
+	opts := []managed.ReconcilerOption{
+		managed.WithExternalConnecter(tracing.NewConnecter(v1alpha1.ProviderKind, &connector{
+			kube:      mgr.GetClient(),
+			usage:     resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClient: client.NewAPIFromManagedResource,
+		})),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+	}
+	if o.Features.Enabled(features.EnableAlphaManagementPolicies) {
+		opts = append(opts, managed.WithManagementPolicies())
+	}
+
+	r := managed.NewReconciler(mgr, resource.ManagedKind(v1alpha1.ProviderGroupVersionKind), opts...)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Provider{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// A connector produces an external client for the ProviderConfig of a
+// Provider.
+type connector struct {
+	kube      kubeclient.Client
+	usage     resource.Tracker
+	newClient func(ctx context.Context, kube kubeclient.Client, usage resource.Tracker, mg resource.Managed, pcInfo client.ProviderConfigInfo) (client.AkashAPI, error)
+}
+
+// Connect produces an ExternalClient with a ready-to-use AkashClient.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.Provider)
+	if !ok {
+		return nil, errors.New(errNotProvider)
+	}
+
+	pcInfo, err := client.GetProviderConfig(ctx, c.kube, cr)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+
+	ak, err := c.newClient(ctx, c.kube, c.usage, mg, pcInfo)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+
+	return &external{client: ak}, nil
+}
+
+// An external observes a provider on chain. Providers are never created,
+// updated or deleted.
+type external struct {
+	client client.AkashAPI
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Provider)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotProvider)
+	}
+
+	// Nothing is deleted on chain, so the Provider is gone once it is deleted.
+	if meta.WasDeleted(cr) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	address := cr.Spec.ForProvider.Address
+	record, err := e.client.GetProvider(ctx, address)
+	if client.IsProviderNotFound(err) {
+		cr.SetConditions(xpv1.Unavailable())
+		return managed.ExternalObservation{}, errors.Errorf(errProviderMissing, address)
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetProvider)
+	}
+	auditors, err := e.client.GetProviderAuditors(ctx, address)
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetAuditors)
+	}
+	providers, err := e.client.GetProviders(ctx)
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetProviders)
+	}
+
+	failures := cr.Status.AtProvider.GatewayFailures
+	cr.Status.AtProvider = observe(record, auditors, client.IndexProviders(providers)[address])
+	cr.SetConditions(xpv1.Available())
+
+	version, err := e.client.ProviderVersion(ctx, address, record.HostURI)
+	switch {
+	case gateway.IsUnsupportedAPIVersion(err):
+		cr.Status.AtProvider.Reachable = true
+		cr.SetConditions(v1alpha1.GatewayUnsupported(err.Error()))
+	case err != nil:
+		cr.Status.AtProvider.GatewayFailures = failures + 1
+		cr.SetConditions(v1alpha1.GatewayUnreachable(failures+1, err.Error()))
+	default:
+		cr.Status.AtProvider.Reachable = true
+		cr.Status.AtProvider.Version = version
+		cr.SetConditions(v1alpha1.GatewayReachable())
+	}
+
+	return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, nil
+}
+
+func (e *external) Create(_ context.Context, _ resource.Managed) (managed.ExternalCreation, error) {
+	return managed.ExternalCreation{}, nil
+}
+
+func (e *external) Update(_ context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
+	return managed.ExternalUpdate{}, nil
+}
+
+func (e *external) Delete(_ context.Context, _ resource.Managed) error {
+	return nil
+}
+
+// observe returns the observation of a provider given its on-chain record,
+// its auditors and its entry in the providers API, which is empty if the API
+// does not know the provider.
+func observe(record akashtypes.ProviderRecord, auditors []string, listed akashtypes.Provider) v1alpha1.ProviderObservation {
+	o := v1alpha1.ProviderObservation{
+		HostURI: record.HostURI,
+		Email:   record.Info.Email,
+		Website: record.Info.Website,
+		Audited: listed.Audited,
+		Active:  listed.Active,
+	}
+	if listed.Address != "" {
+		o.Uptime = strconv.FormatFloat(float64(listed.Uptime), 'f', -1, 32)
+	}
+	if len(record.Attributes) > 0 {
+		o.Attributes = make(map[string]string, len(record.Attributes))
+		for _, a := range record.Attributes {
+			o.Attributes[a.Key] = a.Value
+		}
+	}
+	if len(auditors) > 0 {
+		o.Auditors = append([]string(nil), auditors...)
+		sort.Strings(o.Auditors)
+	}
+	return o
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	akashtypes "github.com/overlock-network/provider-akash/internal/client/types"
+)
+
+func TestObserve(t *testing.T) {
+	record := akashtypes.ProviderRecord{
+		Owner:      "akash1provider",
+		HostURI:    "https://provider.example.com:8443",
+		Attributes: []akashtypes.Attribute{{Key: "region", Value: "eu-west"}, {Key: "tier", Value: "community"}},
+		Info:       akashtypes.ProviderInfo{Email: "ops@example.com", Website: "https://example.com"},
+	}
+
+	cases := map[string]struct {
+		auditors []string
+		listed   akashtypes.Provider
+		want     v1alpha1.ProviderObservation
+	}{
+		"Unlisted": {
+			want: v1alpha1.ProviderObservation{
+				HostURI:    "https://provider.example.com:8443",
+				Attributes: map[string]string{"region": "eu-west", "tier": "community"},
+				Email:      "ops@example.com",
+				Website:    "https://example.com",
+			},
+		},
+		"ListedAndAudited": {
+			auditors: []string{"akash1auditorb", "akash1auditora"},
+			listed:   akashtypes.Provider{Address: "akash1provider", Active: true, Audited: true, Uptime: 0.5},
+			want: v1alpha1.ProviderObservation{
+				HostURI:    "https://provider.example.com:8443",
+				Attributes: map[string]string{"region": "eu-west", "tier": "community"},
+				Email:      "ops@example.com",
+				Website:    "https://example.com",
+				Auditors:   []string{"akash1auditora", "akash1auditorb"},
+				Audited:    true,
+				Active:     true,
+				Uptime:     "0.5",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := observe(record, tc.auditors, tc.listed)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("observe(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+// Package denom converts amounts of base denoms, e.g. uakt, to their display
+// units, e.g. AKT.
+package denom
+
+import (
+	"math/big"
+	"strings"
+)
+
+// maxDecimals bounds the decimals of converted amounts, which is the precision
+// of decimal coins on chain.
+const maxDecimals = 18
+
+// A Unit is the display unit of a base denom.
+type Unit struct {
+	// Display is the name of the unit, e.g. AKT.
+	Display string
+	// Exponent is the power of ten the base denom is scaled down by.
+	Exponent int
+}
+
+// units are the display units of the denoms deployments are paid in.
+var units = map[string]Unit{
+	"uakt":  {Display: "AKT", Exponent: 6},
+	"uusdc": {Display: "USDC", Exponent: 6},
+	// USDC transferred to Akash over IBC.
+	"ibc/170C677610AC31DF0904FFE09CD3B5C657492170E7E52372E48756B71E56F2F1": {Display: "USDC", Exponent: 6},
+}
+
+// UnitOf returns the display unit of a base denom, if it is known.
+func UnitOf(denom string) (Unit, bool) {
+	u, ok := units[denom]
+	return u, ok
+}
+
+// ToDisplay converts a decimal amount of a base denom to its display unit,
+// e.g. 1500000 uakt to 1.5 AKT. It returns false when the denom is unknown or
+// the amount is not a decimal number.
+func ToDisplay(amount, denom string) (string, string, bool) {
+	u, ok := UnitOf(denom)
+	if !ok {
+		return "", "", false
+	}
+	r, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return "", "", false
+	}
+	r.Quo(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(u.Exponent)), nil)))
+
+	decimals := u.Exponent
+	if i := strings.IndexByte(amount, '.'); i >= 0 {
+		decimals += len(amount) - i - 1
+	}
+	if decimals > maxDecimals {
+		decimals = maxDecimals
+	}
+
+	s := r.FloatString(decimals)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s, u.Display, true
+}
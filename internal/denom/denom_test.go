@@ -0,0 +1,31 @@
+package denom
+
+import "testing"
+
+func TestToDisplay(t *testing.T) {
+	cases := map[string]struct {
+		amount      string
+		denom       string
+		wantAmount  string
+		wantDisplay string
+		wantOK      bool
+	}{
+		"AKT":          {amount: "1500000", denom: "uakt", wantAmount: "1.5", wantDisplay: "AKT", wantOK: true},
+		"Whole":        {amount: "2000000", denom: "uakt", wantAmount: "2", wantDisplay: "AKT", wantOK: true},
+		"PerBlock":     {amount: "0.25", denom: "uakt", wantAmount: "0.00000025", wantDisplay: "AKT", wantOK: true},
+		"Zero":         {amount: "0", denom: "uakt", wantAmount: "0", wantDisplay: "AKT", wantOK: true},
+		"IBCUSDC":      {amount: "12345678", denom: "ibc/170C677610AC31DF0904FFE09CD3B5C657492170E7E52372E48756B71E56F2F1", wantAmount: "12.345678", wantDisplay: "USDC", wantOK: true},
+		"UnknownDenom": {amount: "1", denom: "ufoo"},
+		"NotANumber":   {amount: "lots", denom: "uakt"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			amount, display, ok := ToDisplay(tc.amount, tc.denom)
+			if ok != tc.wantOK || amount != tc.wantAmount || display != tc.wantDisplay {
+				t.Errorf("ToDisplay(%q, %q): want %q %q %t, got %q %q %t",
+					tc.amount, tc.denom, tc.wantAmount, tc.wantDisplay, tc.wantOK, amount, display, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package sdl
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"strconv"
+)
+
+// endpointKinds are the values endpoint kinds are encoded as in a manifest.
+var endpointKinds = map[string]int{
+	EndpointSharedHTTP: 0,
+	EndpointRandomPort: 1,
+	EndpointLeasedIP:   2,
+}
+
+// The wire types below mirror the JSON encoding of the manifest accepted by
+// the gateway of provider-services.
+
+type manifestGroup struct {
+	Name     string            `json:"name"`
+	Services []manifestService `json:"services"`
+}
+
+type manifestService struct {
+	Name        string            `json:"name"`
+	Image       string            `json:"image"`
+	Command     []string          `json:"command"`
+	Args        []string          `json:"args"`
+	Env         []string          `json:"env"`
+	Resources   manifestResources `json:"resources"`
+	Count       uint32            `json:"count"`
+	Expose      []manifestExpose  `json:"expose"`
+	Params      interface{}       `json:"params"`
+	Credentials interface{}       `json:"credentials"`
+}
+
+type manifestValue struct {
+	Val string `json:"val"`
+}
+
+type manifestAttribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type manifestResources struct {
+	ID  uint32 `json:"id"`
+	CPU struct {
+		Units manifestValue `json:"units"`
+	} `json:"cpu"`
+	Memory struct {
+		Size manifestValue `json:"size"`
+	} `json:"memory"`
+	Storage []manifestStorage `json:"storage"`
+	GPU     struct {
+		Units manifestValue `json:"units"`
+	} `json:"gpu"`
+	Endpoints []manifestEndpoint `json:"endpoints"`
+}
+
+type manifestStorage struct {
+	Name       string              `json:"name"`
+	Size       manifestValue       `json:"size"`
+	Attributes []manifestAttribute `json:"attributes,omitempty"`
+}
+
+type manifestEndpoint struct {
+	Kind           int    `json:"kind,omitempty"`
+	SequenceNumber uint32 `json:"sequence_number"`
+}
+
+type manifestHTTPOptions struct {
+	MaxBodySize uint32   `json:"maxBodySize"`
+	ReadTimeout uint32   `json:"readTimeout"`
+	SendTimeout uint32   `json:"sendTimeout"`
+	NextTries   uint32   `json:"nextTries"`
+	NextTimeout uint32   `json:"nextTimeout"`
+	NextCases   []string `json:"nextCases"`
+}
+
+// defaultHTTPOptions are the HTTP options of exposed ports the SDL leaves
+// unset, as defaulted by provider-services.
+var defaultHTTPOptions = manifestHTTPOptions{
+	MaxBodySize: 1048576,
+	ReadTimeout: 60000,
+	SendTimeout: 60000,
+	NextTries:   3,
+	NextCases:   []string{"error", "timeout"},
+}
+
+type manifestExpose struct {
+	Port                   uint32              `json:"port"`
+	ExternalPort           uint32              `json:"externalPort"`
+	Proto                  string              `json:"proto"`
+	Service                string              `json:"service"`
+	Global                 bool                `json:"global"`
+	Hosts                  []string            `json:"hosts"`
+	HTTPOptions            manifestHTTPOptions `json:"httpOptions"`
+	IP                     string              `json:"ip"`
+	EndpointSequenceNumber uint32              `json:"endpointSequenceNumber"`
+}
+
+// MarshalManifest encodes manifests as the JSON document submitted to the
+// gateway of the provider leasing them.
+func MarshalManifest(manifests []Manifest) ([]byte, error) {
+	groups := make([]manifestGroup, 0, len(manifests))
+	for _, m := range manifests {
+		g := manifestGroup{Name: m.Name, Services: make([]manifestService, 0, len(m.Services))}
+		for _, svc := range m.Services {
+			g.Services = append(g.Services, manifestService{
+				Name:      svc.Name,
+				Image:     svc.Image,
+				Command:   svc.Command,
+				Args:      svc.Args,
+				Env:       svc.Env,
+				Resources: marshalResources(svc.Resources),
+				Count:     svc.Count,
+				Expose:    marshalExposes(svc.Expose),
+			})
+		}
+		groups = append(groups, g)
+	}
+	return json.Marshal(groups)
+}
+
+// ManifestVersion returns the version of manifests recorded on chain by the
+// deployments created from them: the SHA-256 digest of their JSON encoding
+// with object keys sorted, which does not depend on the order of the fields.
+func ManifestVersion(manifests []Manifest) ([]byte, error) {
+	raw, err := MarshalManifest(manifests)
+	if err != nil {
+		return nil, err
+	}
+	return digest(raw)
+}
+
+// RawManifestVersion returns the version of the JSON encoded manifest raw,
+// e.g. as stored by a provider, comparable to ManifestVersion. Fields the
+// manifest encoding does not know are ignored.
+func RawManifestVersion(raw []byte) ([]byte, error) {
+	groups := []manifestGroup{}
+	if err := json.Unmarshal(raw, &groups); err != nil {
+		return nil, err
+	}
+	normalized, err := json.Marshal(groups)
+	if err != nil {
+		return nil, err
+	}
+	return digest(normalized)
+}
+
+// digest returns the SHA-256 digest of the JSON document raw with object
+// keys sorted.
+func digest(raw []byte) ([]byte, error) {
+	// Maps are encoded with sorted keys, and numbers kept as they are.
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+	var doc interface{}
+	if err := d.Decode(&doc); err != nil {
+		return nil, err
+	}
+	sorted, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(sorted)
+	return sum[:], nil
+}
+
+func marshalResources(r Resources) manifestResources {
+	out := manifestResources{ID: r.ID, Storage: []manifestStorage{}, Endpoints: []manifestEndpoint{}}
+	out.CPU.Units.Val = strconv.FormatUint(r.CPU, 10)
+	out.Memory.Size.Val = strconv.FormatUint(r.Memory, 10)
+	out.GPU.Units.Val = strconv.FormatUint(r.GPU, 10)
+	for _, s := range r.Storage {
+		ms := manifestStorage{Name: s.Name, Size: manifestValue{Val: strconv.FormatUint(s.Size, 10)}}
+		for _, a := range s.Attributes {
+			ms.Attributes = append(ms.Attributes, manifestAttribute(a))
+		}
+		out.Storage = append(out.Storage, ms)
+	}
+	for _, e := range r.Endpoints {
+		out.Endpoints = append(out.Endpoints, manifestEndpoint{Kind: endpointKinds[e.Kind], SequenceNumber: e.SequenceNumber})
+	}
+	return out
+}
+
+func marshalExposes(exposes []ServiceExpose) []manifestExpose {
+	out := make([]manifestExpose, 0, len(exposes))
+	for _, e := range exposes {
+		out = append(out, manifestExpose{
+			Port:                   e.Port,
+			ExternalPort:           e.ExternalPort,
+			Proto:                  e.Proto,
+			Service:                e.Service,
+			Global:                 e.Global,
+			Hosts:                  e.Hosts,
+			HTTPOptions:            defaultHTTPOptions,
+			IP:                     e.IP,
+			EndpointSequenceNumber: e.EndpointSequenceNumber,
+		})
+	}
+	return out
+}
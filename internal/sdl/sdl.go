@@ -0,0 +1,460 @@
+// Package sdl parses the Stack Definition Language Akash deployments are
+// described in into the groups placed on chain and the manifests sent to the
+// providers leasing them.
+package sdl
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	errParse             = "cannot parse SDL"
+	errVersion           = "unsupported SDL version %q"
+	errNoDeployment      = "SDL deploys no services"
+	errUndefinedService  = "deployment of undefined service %s"
+	errUndefinedProfile  = "service %s in placement %s uses undefined compute profile %q"
+	errUndefinedPlace    = "service %s is deployed to undefined placement %s"
+	errNoPrice           = "placement %s has no pricing for compute profile %q"
+	errInvalidPrice      = "placement %s has invalid price %q for compute profile %q"
+	errInvalidQuantity   = "compute profile %s has invalid %s %q"
+	errUndefinedEndpoint = "service %s exposes port %d on undefined IP endpoint %s"
+)
+
+// Endpoint kinds of a resource unit.
+const (
+	EndpointSharedHTTP = "SHARED_HTTP"
+	EndpointRandomPort = "RANDOM_PORT"
+	EndpointLeasedIP   = "LEASED_IP"
+)
+
+// A GroupSpec is a deployment group as placed on chain: the resources a
+// provider has to satisfy to bid on it.
+type GroupSpec struct {
+	Name         string
+	Requirements PlacementRequirements
+	Resources    []ResourceUnit
+}
+
+// PlacementRequirements restrict the providers that may bid on a group.
+type PlacementRequirements struct {
+	SignedBy   SignedBy
+	Attributes []Attribute
+}
+
+// SignedBy lists the auditors that must, or any of which must, have signed
+// the attributes of a provider.
+type SignedBy struct {
+	AllOf []string
+	AnyOf []string
+}
+
+// An Attribute is a key value pair.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// A ResourceUnit is count instances of the given resources, offered at most at
+// Price per block each.
+type ResourceUnit struct {
+	Resources Resources
+	Count     uint32
+	Price     DecCoin
+}
+
+// Resources are the resources of a single instance. CPU is in millicores,
+// memory and storage in bytes.
+type Resources struct {
+	ID        uint32
+	CPU       uint64
+	Memory    uint64
+	Storage   []Storage
+	GPU       uint64
+	Endpoints []Endpoint
+}
+
+// Storage is a storage volume.
+type Storage struct {
+	Name       string
+	Size       uint64
+	Attributes []Attribute
+}
+
+// An Endpoint is a way a resource unit is exposed.
+type Endpoint struct {
+	Kind           string
+	SequenceNumber uint32
+}
+
+// DecCoin is an amount of a denom.
+type DecCoin struct {
+	Denom  string
+	Amount string
+}
+
+// A Manifest is what is sent to the provider leasing a group: the services it
+// has to run.
+type Manifest struct {
+	Name     string
+	Services []Service
+}
+
+// A Service of a manifest.
+type Service struct {
+	Name      string
+	Image     string
+	Command   []string
+	Args      []string
+	Env       []string
+	Resources Resources
+	Count     uint32
+	Expose    []ServiceExpose
+}
+
+// A ServiceExpose is a port exposed by a service.
+type ServiceExpose struct {
+	Port         uint32
+	ExternalPort uint32
+	Proto        string
+	Service      string
+	Global       bool
+	Hosts        []string
+	IP           string
+	// EndpointSequenceNumber is the sequence number of the leased IP
+	// endpoint the port is exposed on, if any.
+	EndpointSequenceNumber uint32
+}
+
+type sdl struct {
+	Version   string                       `yaml:"version"`
+	Services  map[string]service           `yaml:"services"`
+	Profiles  profiles                     `yaml:"profiles"`
+	Deploy    map[string]map[string]deploy `yaml:"deployment"`
+	Endpoints map[string]endpoint          `yaml:"endpoints"`
+}
+
+type endpoint struct {
+	Kind string `yaml:"kind"`
+}
+
+type service struct {
+	Image   string   `yaml:"image"`
+	Command []string `yaml:"command"`
+	Args    []string `yaml:"args"`
+	Env     []string `yaml:"env"`
+	Expose  []expose `yaml:"expose"`
+}
+
+type expose struct {
+	Port   uint32   `yaml:"port"`
+	As     uint32   `yaml:"as"`
+	Proto  string   `yaml:"proto"`
+	Accept []string `yaml:"accept"`
+	To     []struct {
+		Service string `yaml:"service"`
+		Global  bool   `yaml:"global"`
+		IP      string `yaml:"ip"`
+	} `yaml:"to"`
+}
+
+type profiles struct {
+	Compute map[string]struct {
+		Resources computeResources `yaml:"resources"`
+	} `yaml:"compute"`
+	Placement map[string]placement `yaml:"placement"`
+}
+
+type computeResources struct {
+	CPU struct {
+		Units string `yaml:"units"`
+	} `yaml:"cpu"`
+	Memory struct {
+		Size string `yaml:"size"`
+	} `yaml:"memory"`
+	GPU struct {
+		Units string `yaml:"units"`
+	} `yaml:"gpu"`
+	Storage storageVolumes `yaml:"storage"`
+}
+
+type storageVolume struct {
+	Name       string            `yaml:"name"`
+	Size       string            `yaml:"size"`
+	Attributes map[string]string `yaml:"attributes"`
+}
+
+// storageVolumes are the storage volumes of a compute profile, which may be
+// given as a single volume or as a list.
+type storageVolumes []storageVolume
+
+func (s *storageVolumes) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.MappingNode {
+		v := storageVolume{}
+		if err := n.Decode(&v); err != nil {
+			return err
+		}
+		*s = storageVolumes{v}
+		return nil
+	}
+	type plain storageVolumes
+	return n.Decode((*plain)(s))
+}
+
+type placement struct {
+	Attributes map[string]string `yaml:"attributes"`
+	SignedBy   struct {
+		AllOf []string `yaml:"allOf"`
+		AnyOf []string `yaml:"anyOf"`
+	} `yaml:"signedBy"`
+	Pricing map[string]struct {
+		Denom  string `yaml:"denom"`
+		Amount string `yaml:"amount"`
+	} `yaml:"pricing"`
+}
+
+type deploy struct {
+	Profile string `yaml:"profile"`
+	Count   uint32 `yaml:"count"`
+}
+
+// ParseSDL parses an SDL into the groups of the deployment, one per
+// placement, and the manifests of those groups. Groups and manifests are
+// ordered by name, as are the services within them.
+func ParseSDL(content []byte) ([]GroupSpec, []Manifest, error) {
+	s := sdl{}
+	if err := yaml.Unmarshal(content, &s); err != nil {
+		return nil, nil, errors.Wrap(err, errParse)
+	}
+	if s.Version != "" && !strings.HasPrefix(s.Version, "2.") {
+		return nil, nil, errors.Errorf(errVersion, s.Version)
+	}
+	if len(s.Deploy) == 0 {
+		return nil, nil, errors.New(errNoDeployment)
+	}
+
+	groups := map[string]*GroupSpec{}
+	manifests := map[string]*Manifest{}
+
+	for _, name := range sortedKeys(s.Deploy) {
+		svc, ok := s.Services[name]
+		if !ok {
+			return nil, nil, errors.Errorf(errUndefinedService, name)
+		}
+
+		for _, placementName := range sortedKeys(s.Deploy[name]) {
+			d := s.Deploy[name][placementName]
+			p, ok := s.Profiles.Placement[placementName]
+			if !ok {
+				return nil, nil, errors.Errorf(errUndefinedPlace, name, placementName)
+			}
+			profile, ok := s.Profiles.Compute[d.Profile]
+			if !ok {
+				return nil, nil, errors.Errorf(errUndefinedProfile, name, placementName, d.Profile)
+			}
+			price, ok := p.Pricing[d.Profile]
+			if !ok {
+				return nil, nil, errors.Errorf(errNoPrice, placementName, d.Profile)
+			}
+			if r, ok := new(big.Rat).SetString(price.Amount); !ok || r.Sign() < 0 {
+				return nil, nil, errors.Errorf(errInvalidPrice, placementName, price.Amount, d.Profile)
+			}
+
+			g, ok := groups[placementName]
+			if !ok {
+				g = &GroupSpec{
+					Name: placementName,
+					Requirements: PlacementRequirements{
+						SignedBy:   SignedBy{AllOf: p.SignedBy.AllOf, AnyOf: p.SignedBy.AnyOf},
+						Attributes: attributes(p.Attributes),
+					},
+				}
+				groups[placementName] = g
+				manifests[placementName] = &Manifest{Name: placementName}
+			}
+
+			res, err := resources(d.Profile, profile.Resources)
+			if err != nil {
+				return nil, nil, err
+			}
+			res.ID = uint32(len(g.Resources) + 1)
+
+			exposes, endpoints, err := serviceExposes(name, svc.Expose, s.Endpoints)
+			if err != nil {
+				return nil, nil, err
+			}
+			res.Endpoints = endpoints
+
+			count := d.Count
+			if count == 0 {
+				count = 1
+			}
+
+			g.Resources = append(g.Resources, ResourceUnit{
+				Resources: res,
+				Count:     count,
+				Price:     DecCoin{Denom: price.Denom, Amount: price.Amount},
+			})
+			m := manifests[placementName]
+			m.Services = append(m.Services, Service{
+				Name:      name,
+				Image:     svc.Image,
+				Command:   svc.Command,
+				Args:      svc.Args,
+				Env:       svc.Env,
+				Resources: res,
+				Count:     count,
+				Expose:    exposes,
+			})
+		}
+	}
+
+	gs := make([]GroupSpec, 0, len(groups))
+	ms := make([]Manifest, 0, len(manifests))
+	for _, name := range sortedKeys(groups) {
+		gs = append(gs, *groups[name])
+		ms = append(ms, *manifests[name])
+	}
+	return gs, ms, nil
+}
+
+// resources converts the resources of a compute profile.
+func resources(profile string, r computeResources) (Resources, error) {
+	res := Resources{}
+
+	cpu, err := ParseQuantity(r.CPU.Units)
+	if err != nil {
+		return Resources{}, errors.Errorf(errInvalidQuantity, profile, "cpu units", r.CPU.Units)
+	}
+	res.CPU = uint64(cpu.MilliValue())
+
+	memory, err := ParseQuantity(r.Memory.Size)
+	if err != nil {
+		return Resources{}, errors.Errorf(errInvalidQuantity, profile, "memory size", r.Memory.Size)
+	}
+	res.Memory = uint64(memory.Value())
+
+	for i, v := range r.Storage {
+		size, err := ParseQuantity(v.Size)
+		if err != nil {
+			return Resources{}, errors.Errorf(errInvalidQuantity, profile, "storage size", v.Size)
+		}
+		name := v.Name
+		if name == "" && i == 0 {
+			name = "default"
+		}
+		res.Storage = append(res.Storage, Storage{Name: name, Size: uint64(size.Value()), Attributes: attributes(v.Attributes)})
+	}
+
+	if r.GPU.Units != "" {
+		gpu, err := ParseQuantity(r.GPU.Units)
+		if err != nil {
+			return Resources{}, errors.Errorf(errInvalidQuantity, profile, "gpu units", r.GPU.Units)
+		}
+		res.GPU = uint64(gpu.Value())
+	}
+
+	return res, nil
+}
+
+// serviceExposes converts the exposed ports of a service and returns the
+// endpoints its resource unit needs. Globally exposed port 80 is served by the
+// shared HTTP ingress of the provider, other global ports get a random port.
+func serviceExposes(svc string, exposes []expose, ips map[string]endpoint) ([]ServiceExpose, []Endpoint, error) {
+	var out []ServiceExpose
+	var endpoints []Endpoint
+	ipSeq := map[string]uint32{}
+	for _, name := range sortedKeys(ips) {
+		ipSeq[name] = uint32(len(ipSeq) + 1)
+	}
+
+	for _, e := range exposes {
+		external := e.As
+		if external == 0 {
+			external = e.Port
+		}
+		proto := strings.ToUpper(e.Proto)
+		if proto == "" {
+			proto = "TCP"
+		}
+
+		for _, to := range e.To {
+			var seq uint32
+			if to.IP != "" {
+				var ok bool
+				if seq, ok = ipSeq[to.IP]; !ok {
+					return nil, nil, errors.Errorf(errUndefinedEndpoint, svc, e.Port, to.IP)
+				}
+			}
+
+			out = append(out, ServiceExpose{
+				Port:                   e.Port,
+				ExternalPort:           external,
+				Proto:                  proto,
+				Service:                to.Service,
+				Global:                 to.Global,
+				Hosts:                  e.Accept,
+				IP:                     to.IP,
+				EndpointSequenceNumber: seq,
+			})
+			if !to.Global {
+				continue
+			}
+
+			kind := EndpointRandomPort
+			if external == 80 && proto == "TCP" {
+				kind = EndpointSharedHTTP
+			}
+			endpoints = appendEndpoint(endpoints, Endpoint{Kind: kind})
+
+			if to.IP != "" {
+				endpoints = appendEndpoint(endpoints, Endpoint{Kind: EndpointLeasedIP, SequenceNumber: seq})
+			}
+		}
+	}
+	return out, endpoints, nil
+}
+
+func appendEndpoint(endpoints []Endpoint, e Endpoint) []Endpoint {
+	for _, existing := range endpoints {
+		if existing == e {
+			return endpoints
+		}
+	}
+	return append(endpoints, e)
+}
+
+// ParseQuantity parses a resource quantity of an SDL. Unlike Kubernetes, the
+// SDL also accepts sizes with a trailing byte unit, e.g. 512MB or 1Gb.
+func ParseQuantity(s string) (resource.Quantity, error) {
+	q, err := resource.ParseQuantity(s)
+	if err != nil && (strings.HasSuffix(s, "B") || strings.HasSuffix(s, "b")) {
+		q, err = resource.ParseQuantity(s[:len(s)-1])
+	}
+	return q, err
+}
+
+func attributes(m map[string]string) []Attribute {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make([]Attribute, 0, len(m))
+	for _, k := range sortedKeys(m) {
+		out = append(out, Attribute{Key: k, Value: m[k]})
+	}
+	return out
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
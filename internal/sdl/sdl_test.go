@@ -0,0 +1,272 @@
+package sdl
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const example = `---
+version: "2.0"
+services:
+  web:
+    image: nginx:1.25
+    env:
+      - MODE=production
+    expose:
+      - port: 8080
+        as: 80
+        accept:
+          - example.com
+        to:
+          - global: true
+      - port: 22
+        to:
+          - global: true
+            ip: public
+  db:
+    image: postgres:16
+    expose:
+      - port: 5432
+        to:
+          - service: web
+profiles:
+  compute:
+    web:
+      resources:
+        cpu:
+          units: 0.5
+        memory:
+          size: 512Mi
+        storage:
+          size: 1Gi
+    db:
+      resources:
+        cpu:
+          units: 2
+        memory:
+          size: 2Gi
+        storage:
+          - size: 1Gi
+          - name: data
+            size: 10Gi
+            attributes:
+              persistent: "true"
+        gpu:
+          units: 1
+  placement:
+    dcloud:
+      attributes:
+        region: us-west
+      signedBy:
+        anyOf:
+          - akash1auditor
+      pricing:
+        web:
+          denom: uakt
+          amount: 1000
+        db:
+          denom: uakt
+          amount: 5000
+deployment:
+  web:
+    dcloud:
+      profile: web
+      count: 2
+  db:
+    dcloud:
+      profile: db
+endpoints:
+  public:
+    kind: ip
+`
+
+func TestParseSDL(t *testing.T) {
+	dbResources := Resources{
+		ID:     1,
+		CPU:    2000,
+		Memory: 2 << 30,
+		Storage: []Storage{
+			{Name: "default", Size: 1 << 30},
+			{Name: "data", Size: 10 << 30, Attributes: []Attribute{{Key: "persistent", Value: "true"}}},
+		},
+		GPU: 1,
+	}
+	webResources := Resources{
+		ID:      2,
+		CPU:     500,
+		Memory:  512 << 20,
+		Storage: []Storage{{Name: "default", Size: 1 << 30}},
+		Endpoints: []Endpoint{
+			{Kind: EndpointSharedHTTP},
+			{Kind: EndpointRandomPort},
+			{Kind: EndpointLeasedIP, SequenceNumber: 1},
+		},
+	}
+
+	wantGroups := []GroupSpec{{
+		Name: "dcloud",
+		Requirements: PlacementRequirements{
+			SignedBy:   SignedBy{AnyOf: []string{"akash1auditor"}},
+			Attributes: []Attribute{{Key: "region", Value: "us-west"}},
+		},
+		Resources: []ResourceUnit{
+			{Resources: dbResources, Count: 1, Price: DecCoin{Denom: "uakt", Amount: "5000"}},
+			{Resources: webResources, Count: 2, Price: DecCoin{Denom: "uakt", Amount: "1000"}},
+		},
+	}}
+	wantManifests := []Manifest{{
+		Name: "dcloud",
+		Services: []Service{
+			{
+				Name:      "db",
+				Image:     "postgres:16",
+				Resources: dbResources,
+				Count:     1,
+				Expose:    []ServiceExpose{{Port: 5432, ExternalPort: 5432, Proto: "TCP", Service: "web"}},
+			},
+			{
+				Name:      "web",
+				Image:     "nginx:1.25",
+				Env:       []string{"MODE=production"},
+				Resources: webResources,
+				Count:     2,
+				Expose: []ServiceExpose{
+					{Port: 8080, ExternalPort: 80, Proto: "TCP", Global: true, Hosts: []string{"example.com"}},
+					{Port: 22, ExternalPort: 22, Proto: "TCP", Global: true, IP: "public", EndpointSequenceNumber: 1},
+				},
+			},
+		},
+	}}
+
+	groups, manifests, err := ParseSDL([]byte(example))
+	if err != nil {
+		t.Fatalf("ParseSDL() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(wantGroups, groups); diff != "" {
+		t.Errorf("ParseSDL() groups mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(wantManifests, manifests); diff != "" {
+		t.Errorf("ParseSDL() manifests mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseSDLErrors(t *testing.T) {
+	cases := map[string]string{
+		"Version":          "version: \"1.0\"\ndeployment: {web: {dcloud: {profile: web}}}\n",
+		"NoDeployment":     "version: \"2.0\"\n",
+		"UndefinedService": "version: \"2.0\"\ndeployment: {web: {dcloud: {profile: web}}}\n",
+		"UndefinedPlacement": "version: \"2.0\"\nservices: {web: {image: nginx}}\n" +
+			"deployment: {web: {dcloud: {profile: web}}}\n",
+		"InvalidPrice": "version: \"2.0\"\nservices: {web: {image: nginx}}\n" +
+			"profiles: {compute: {web: {resources: {cpu: {units: 1}, memory: {size: 512Mi}}}}, " +
+			"placement: {dcloud: {pricing: {web: {denom: uakt, amount: lots}}}}}\n" +
+			"deployment: {web: {dcloud: {profile: web}}}\n",
+	}
+
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := ParseSDL([]byte(content)); err == nil {
+				t.Errorf("ParseSDL(): want error, got none")
+			}
+		})
+	}
+}
+
+func TestMarshalManifest(t *testing.T) {
+	manifests := []Manifest{{
+		Name: "dcloud",
+		Services: []Service{{
+			Name:  "web",
+			Image: "nginx",
+			Env:   []string{"MODE=production"},
+			Resources: Resources{
+				ID:        1,
+				CPU:       500,
+				Memory:    512 << 20,
+				Storage:   []Storage{{Name: "data", Size: 1 << 30, Attributes: []Attribute{{Key: "persistent", Value: "true"}}}},
+				Endpoints: []Endpoint{{Kind: EndpointSharedHTTP}, {Kind: EndpointLeasedIP, SequenceNumber: 1}},
+			},
+			Count:  1,
+			Expose: []ServiceExpose{{Port: 8080, ExternalPort: 80, Proto: "TCP", Global: true, IP: "public", EndpointSequenceNumber: 1}},
+		}},
+	}}
+
+	want := `[{"name":"dcloud","services":[{"name":"web","image":"nginx","command":null,"args":null,"env":["MODE=production"],` +
+		`"resources":{"id":1,"cpu":{"units":{"val":"500"}},"memory":{"size":{"val":"536870912"}},` +
+		`"storage":[{"name":"data","size":{"val":"1073741824"},"attributes":[{"key":"persistent","value":"true"}]}],` +
+		`"gpu":{"units":{"val":"0"}},"endpoints":[{"sequence_number":0},{"kind":2,"sequence_number":1}]},"count":1,` +
+		`"expose":[{"port":8080,"externalPort":80,"proto":"TCP","service":"","global":true,"hosts":null,` +
+		`"httpOptions":{"maxBodySize":1048576,"readTimeout":60000,"sendTimeout":60000,"nextTries":3,"nextTimeout":0,"nextCases":["error","timeout"]},` +
+		`"ip":"public","endpointSequenceNumber":1}],"params":null,"credentials":null}]}]`
+
+	got, err := MarshalManifest(manifests)
+	if err != nil {
+		t.Fatalf("MarshalManifest() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("MarshalManifest() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestManifestVersion(t *testing.T) {
+	_, manifests, err := ParseSDL([]byte(example))
+	if err != nil {
+		t.Fatalf("ParseSDL() unexpected error: %v", err)
+	}
+	version, err := ManifestVersion(manifests)
+	if err != nil {
+		t.Fatalf("ManifestVersion() unexpected error: %v", err)
+	}
+
+	again, err := ManifestVersion(manifests)
+	if err != nil {
+		t.Fatalf("ManifestVersion() unexpected error: %v", err)
+	}
+	if !bytes.Equal(version, again) {
+		t.Errorf("ManifestVersion() is not deterministic: %x, then %x", version, again)
+	}
+
+	manifests[0].Services[0].Image = "nginx:1.26"
+	changed, err := ManifestVersion(manifests)
+	if err != nil {
+		t.Fatalf("ManifestVersion() unexpected error: %v", err)
+	}
+	if bytes.Equal(version, changed) {
+		t.Errorf("ManifestVersion() did not change with the image: %x", version)
+	}
+}
+
+func TestRawManifestVersion(t *testing.T) {
+	_, manifests, err := ParseSDL([]byte(example))
+	if err != nil {
+		t.Fatalf("ParseSDL() unexpected error: %v", err)
+	}
+	want, err := ManifestVersion(manifests)
+	if err != nil {
+		t.Fatalf("ManifestVersion() unexpected error: %v", err)
+	}
+
+	raw, err := MarshalManifest(manifests)
+	if err != nil {
+		t.Fatalf("MarshalManifest() unexpected error: %v", err)
+	}
+	got, err := RawManifestVersion(raw)
+	if err != nil {
+		t.Fatalf("RawManifestVersion() unexpected error: %v", err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Errorf("RawManifestVersion(MarshalManifest()) = %x, want %x", got, want)
+	}
+
+	// Providers may store fields of newer releases.
+	extended := bytes.Replace(raw, []byte(`"name":"dcloud",`), []byte(`"name":"dcloud","scheduling":null,`), 1)
+	got, err = RawManifestVersion(extended)
+	if err != nil {
+		t.Fatalf("RawManifestVersion() unexpected error: %v", err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Errorf("RawManifestVersion() with unknown fields = %x, want %x", got, want)
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statestore
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	errGetConfigMap    = "cannot get state ConfigMap"
+	errUpdateConfigMap = "cannot update state ConfigMap"
+)
+
+// ConfigMap is a Store that persists values in a ConfigMap, so they survive
+// restarts and are shared by all replicas of the provider.
+type ConfigMap struct {
+	kube   client.Client
+	reader client.Reader
+	name   types.NamespacedName
+}
+
+// NewConfigMap returns a Store persisting values in the named ConfigMap,
+// which is created on first write. Reads go through reader, which should not
+// be backed by a cache so that values written by other replicas are seen.
+func NewConfigMap(kube client.Client, reader client.Reader, namespace, name string) *ConfigMap {
+	return &ConfigMap{
+		kube:   kube,
+		reader: reader,
+		name:   types.NamespacedName{Namespace: namespace, Name: name},
+	}
+}
+
+// Get returns the value of key and whether it is set.
+func (c *ConfigMap) Get(ctx context.Context, key string) (string, bool, error) {
+	cm, err := c.get(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	v, ok := cm.Data[key]
+	return v, ok, nil
+}
+
+// Set sets the value of key.
+func (c *ConfigMap) Set(ctx context.Context, key, value string) error {
+	return c.update(ctx, func(data map[string]string) {
+		data[key] = value
+	})
+}
+
+// Delete unsets key.
+func (c *ConfigMap) Delete(ctx context.Context, key string) error {
+	return c.update(ctx, func(data map[string]string) {
+		delete(data, key)
+	})
+}
+
+// List returns all values whose key starts with prefix.
+func (c *ConfigMap) List(ctx context.Context, prefix string) (map[string]string, error) {
+	cm, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return filter(cm.Data, prefix), nil
+}
+
+// get returns the ConfigMap, or an empty one if it does not exist yet.
+func (c *ConfigMap) get(ctx context.Context) (*corev1.ConfigMap, error) {
+	cm := &corev1.ConfigMap{}
+	err := c.reader.Get(ctx, c.name, cm)
+	if kerrors.IsNotFound(err) {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: c.name.Namespace, Name: c.name.Name}}, nil
+	}
+	return cm, errors.Wrap(err, errGetConfigMap)
+}
+
+// update applies fn to the data of the ConfigMap, retrying when another
+// replica updated it concurrently.
+func (c *ConfigMap) update(ctx context.Context, fn func(data map[string]string)) error {
+	err := retry.OnError(retry.DefaultRetry, func(err error) bool {
+		return kerrors.IsConflict(err) || kerrors.IsAlreadyExists(err)
+	}, func() error {
+		cm, err := c.get(ctx)
+		if err != nil {
+			return err
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		fn(cm.Data)
+		if cm.GetResourceVersion() == "" {
+			return c.kube.Create(ctx, cm)
+		}
+		return c.kube.Update(ctx, cm)
+	})
+	return errors.Wrap(err, errUpdateConfigMap)
+}
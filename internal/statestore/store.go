@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package statestore persists operational state learnt by the provider, such
+// as account sequence hints, blacklisted providers and observed prices, so it
+// survives restarts and can be shared between replicas.
+package statestore
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// A Store holds string values by key. Keys may only contain alphanumeric
+// characters, '-', '_' and '.'.
+type Store interface {
+	// Get returns the value of key and whether it is set.
+	Get(ctx context.Context, key string) (string, bool, error)
+
+	// Set sets the value of key.
+	Set(ctx context.Context, key, value string) error
+
+	// Delete unsets key. Deleting an unset key is not an error.
+	Delete(ctx context.Context, key string) error
+
+	// List returns all values whose key starts with prefix.
+	List(ctx context.Context, prefix string) (map[string]string, error)
+}
+
+// Memory is a Store that keeps values in memory only. It is the default.
+type Memory struct {
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// NewMemory returns an empty in-memory Store.
+func NewMemory() *Memory {
+	return &Memory{values: map[string]string{}}
+}
+
+// Get returns the value of key and whether it is set.
+func (m *Memory) Get(_ context.Context, key string) (string, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	v, ok := m.values[key]
+	return v, ok, nil
+}
+
+// Set sets the value of key.
+func (m *Memory) Set(_ context.Context, key, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = value
+	return nil
+}
+
+// Delete unsets key.
+func (m *Memory) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.values, key)
+	return nil
+}
+
+// List returns all values whose key starts with prefix.
+func (m *Memory) List(_ context.Context, prefix string) (map[string]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return filter(m.values, prefix), nil
+}
+
+func filter(values map[string]string, prefix string) map[string]string {
+	out := map[string]string{}
+	for k, v := range values {
+		if strings.HasPrefix(k, prefix) {
+			out[k] = v
+		}
+	}
+	return out
+}
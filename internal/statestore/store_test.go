@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statestore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestStores(t *testing.T) {
+	kube := fake.NewClientBuilder().Build()
+
+	stores := map[string]Store{
+		"Memory":    NewMemory(),
+		"ConfigMap": NewConfigMap(kube, kube, "crossplane-system", "provider-akash-state"),
+	}
+
+	for name, s := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			if _, ok, err := s.Get(ctx, "sequence.akash1a"); ok || err != nil {
+				t.Fatalf("Get(...) of an unset key: want false, nil, got %t, %v", ok, err)
+			}
+
+			for k, v := range map[string]string{"blacklist.akash1a": "lost lease", "blacklist.akash1b": "slow", "sequence.akash1a": "42"} {
+				if err := s.Set(ctx, k, v); err != nil {
+					t.Fatalf("Set(%q): %v", k, err)
+				}
+			}
+			if err := s.Delete(ctx, "blacklist.akash1b"); err != nil {
+				t.Fatalf("Delete(...): %v", err)
+			}
+
+			if v, ok, err := s.Get(ctx, "sequence.akash1a"); v != "42" || !ok || err != nil {
+				t.Errorf("Get(...): want 42, true, nil, got %q, %t, %v", v, ok, err)
+			}
+
+			got, err := s.List(ctx, "blacklist.")
+			if err != nil {
+				t.Fatalf("List(...): %v", err)
+			}
+			if diff := cmp.Diff(map[string]string{"blacklist.akash1a": "lost lease"}, got); diff != "" {
+				t.Errorf("List(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
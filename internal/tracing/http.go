@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+// DefaultClient is like http.DefaultClient, but traces the requests it sends.
+var DefaultClient = &http.Client{Transport: NewTransport(nil)}
+
+// A transport traces the requests it sends.
+type transport struct {
+	wrapped http.RoundTripper
+}
+
+// NewTransport returns a RoundTripper tracing the requests sent with rt, or
+// with http.DefaultTransport when rt is nil, and propagating the trace to the
+// server.
+func NewTransport(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &transport{wrapped: rt}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	u := *req.URL
+	u.User, u.RawQuery = nil, ""
+	ctx, span := Start(req.Context(), "HTTP "+req.Method,
+		semconv.HTTPRequestMethodKey.String(req.Method),
+		semconv.URLFull(u.String()),
+		semconv.ServerAddress(req.URL.Hostname()),
+	)
+
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.wrapped.RoundTrip(req)
+	if err != nil {
+		End(span, err)
+		return nil, err
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		err = errors.Errorf("response status code %d", resp.StatusCode)
+	}
+	End(span, err)
+	return resp, nil
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// A connecter traces connecting to the external resources of managed
+// resources of one kind, and the operations of the external clients it
+// produces.
+type connecter struct {
+	kind    string
+	wrapped managed.ExternalConnecter
+}
+
+// NewConnecter returns an ExternalConnecter tracing c and the external
+// clients it produces with spans named after kind, e.g. Deployment.Observe.
+// Operations are children of the span of the connection, so that a reconcile
+// forms one trace, and chain calls made with the context the external client
+// was connected with are children of the operation in progress.
+func NewConnecter(kind string, c managed.ExternalConnecter) managed.ExternalConnecter {
+	return &connecter{kind: kind, wrapped: c}
+}
+
+func (c *connecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	op := &operation{}
+	_, span := Start(ctx, c.kind+".Connect", attributes(c.kind, mg)...)
+	op.set(span)
+	defer op.set(nil)
+
+	ext, err := c.wrapped.Connect(withOperation(ctx, op), mg)
+	End(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return &external{kind: c.kind, op: op, connection: span.SpanContext(), wrapped: ext}, nil
+}
+
+// An external traces the operations of an external client.
+type external struct {
+	kind       string
+	op         *operation
+	connection trace.SpanContext
+	wrapped    managed.ExternalClient
+}
+
+// start starts the span of the operation name on mg.
+func (e *external) start(ctx context.Context, name string, mg resource.Managed) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		ctx = trace.ContextWithSpanContext(ctx, e.connection)
+	}
+	ctx, span := Start(ctx, e.kind+"."+name, attributes(e.kind, mg)...)
+	e.op.set(span)
+	return ctx, span
+}
+
+// end ends the span of an operation, recording the external name mg has once
+// it completed, e.g. after its creation.
+func (e *external) end(span trace.Span, mg resource.Managed, err error) {
+	e.op.set(nil)
+	span.SetAttributes(AttrExternalName.String(meta.GetExternalName(mg)))
+	End(span, err)
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	ctx, span := e.start(ctx, "Observe", mg)
+	o, err := e.wrapped.Observe(ctx, mg)
+	span.SetAttributes(
+		attribute.Bool("akash.resource_exists", o.ResourceExists),
+		attribute.Bool("akash.resource_up_to_date", o.ResourceUpToDate),
+	)
+	e.end(span, mg, err)
+	return o, err
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	ctx, span := e.start(ctx, "Create", mg)
+	c, err := e.wrapped.Create(ctx, mg)
+	e.end(span, mg, err)
+	return c, err
+}
+
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	ctx, span := e.start(ctx, "Update", mg)
+	u, err := e.wrapped.Update(ctx, mg)
+	e.end(span, mg, err)
+	return u, err
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	ctx, span := e.start(ctx, "Delete", mg)
+	err := e.wrapped.Delete(ctx, mg)
+	e.end(span, mg, err)
+	return err
+}
+
+// attributes returns the attributes identifying mg.
+func attributes(kind string, mg resource.Managed) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		AttrKind.String(kind),
+		AttrName.String(mg.GetName()),
+		AttrExternalName.String(meta.GetExternalName(mg)),
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing traces reconciles and the chain calls they make with
+// OpenTelemetry, so that slow reconciles can be followed from the managed
+// resource down to the RPC node.
+package tracing
+
+import (
+	"context"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporters spans can be exported with.
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+// instrumentation names the tracer of the provider.
+const instrumentation = "github.com/overlock-network/provider-akash"
+
+// Attributes of spans.
+const (
+	AttrKind         = attribute.Key("akash.kind")
+	AttrName         = attribute.Key("akash.name")
+	AttrExternalName = attribute.Key("akash.external_name")
+	AttrDseq         = attribute.Key("akash.dseq")
+	AttrProvider     = attribute.Key("akash.provider")
+	AttrCommand      = attribute.Key("akash.command")
+	AttrNode         = attribute.Key("akash.rpc.endpoint")
+	AttrTxHash       = attribute.Key("akash.tx.hash")
+	AttrHeight       = attribute.Key("akash.tx.height")
+)
+
+// Options configure how spans are exported.
+type Options struct {
+	// Exporter is one of ExporterNone, ExporterOTLP or ExporterStdout.
+	Exporter string
+	// Endpoint is the host and port of the OTLP gRPC collector. The
+	// OTEL_EXPORTER_OTLP_ENDPOINT environment variable applies when unset.
+	Endpoint string
+	// Insecure disables TLS towards the OTLP collector.
+	Insecure bool
+	// SampleRatio is the fraction of traces sampled, between 0 and 1.
+	SampleRatio float64
+}
+
+// Setup installs a global tracer provider exporting spans as configured by o
+// and returns a function flushing and stopping it. Spans are not recorded
+// when o selects no exporter.
+func Setup(ctx context.Context, o Options) (func(context.Context) error, error) {
+	var exporter sdktrace.SpanExporter
+	switch o.Exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		opts := []otlptracegrpc.Option{}
+		if o.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(o.Endpoint))
+		}
+		if o.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		e, err := otlptracegrpc.New(ctx, opts...)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot create OTLP exporter")
+		}
+		exporter = e
+	case ExporterStdout:
+		e, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot create stdout exporter")
+		}
+		exporter = e
+	default:
+		return nil, errors.Errorf("unknown trace exporter %q", o.Exporter)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(o.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName("provider-akash"))),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return tp.Shutdown, nil
+}
+
+// Tracer returns the tracer of the provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentation)
+}
+
+// An operation tracks the span of the external client operation in progress
+// on behalf of a reconcile, so that calls made with the context the client
+// was connected with are traced as part of it.
+type operation struct {
+	mu   sync.Mutex
+	span trace.Span
+}
+
+type operationKey struct{}
+
+func (o *operation) set(span trace.Span) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.span = span
+}
+
+func (o *operation) get() trace.Span {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.span
+}
+
+// withOperation returns a copy of ctx tracking the operation o.
+func withOperation(ctx context.Context, o *operation) context.Context {
+	return context.WithValue(ctx, operationKey{}, o)
+}
+
+// Start starts a span named name with the given attributes. Spans started
+// with the context an external client was connected with are children of the
+// operation, e.g. Observe, the client is performing.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if o, ok := ctx.Value(operationKey{}).(*operation); ok && !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+		if span := o.get(); span != nil {
+			ctx = trace.ContextWithSpan(ctx, span)
+		}
+	}
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err, if any, on span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+)
+
+func TestConnecter(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	// The external client makes a chain call with the context it was
+	// connected with, as AkashClients do.
+	c := NewConnecter("Deployment", managed.ExternalConnectorFn(func(ctx context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+		return managed.ExternalClientFns{
+			ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+				_, span := Start(ctx, "cli query deployment get")
+				span.End()
+				return managed.ExternalObservation{ResourceExists: true}, nil
+			},
+		}, nil
+	}))
+
+	mg := &fake.Managed{}
+	meta.SetExternalName(mg, "42")
+	ext, err := c.Connect(context.Background(), mg)
+	if err != nil {
+		t.Fatalf("Connect(...): %v", err)
+	}
+	if _, err := ext.Observe(context.Background(), mg); err != nil {
+		t.Fatalf("Observe(...): %v", err)
+	}
+
+	// Every span maps to the name of its parent.
+	parents := map[string]string{}
+	names := map[string]string{}
+	for _, s := range recorder.Ended() {
+		names[s.SpanContext().SpanID().String()] = s.Name()
+	}
+	for _, s := range recorder.Ended() {
+		parents[s.Name()] = names[s.Parent().SpanID().String()]
+	}
+
+	want := map[string]string{
+		"Deployment.Connect":       "",
+		"Deployment.Observe":       "Deployment.Connect",
+		"cli query deployment get": "Deployment.Observe",
+	}
+	if diff := cmp.Diff(want, parents); diff != "" {
+		t.Errorf("Connect(...), Observe(...): -want parents, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	apisv1alpha1 "github.com/overlock-network/provider-akash/apis/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/client"
+)
+
+const (
+	errNotDeployment     = "managed resource is not a Deployment"
+	errGetProviderConfig = "cannot get ProviderConfig"
+)
+
+// SetupDeployment adds the defaulting webhook of Deployments to mgr, and the
+// webhook converting them between their versions.
+func SetupDeployment(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.Deployment{}).
+		WithDefaulter(&deploymentDefaulter{kube: mgr.GetAPIReader()}).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-resource-akash-overlock-network-v1alpha1-deployment,mutating=true,failurePolicy=fail,groups=resource.akash.overlock.network,resources=deployments,versions=v1alpha1,name=deployments.resource.akash.overlock.network,sideEffects=None,admissionReviewVersions=v1
+
+// A deploymentDefaulter fills the unset fields of Deployments with the
+// deployment defaults of their ProviderConfig or NamespacedProviderConfig.
+type deploymentDefaulter struct {
+	kube kubeclient.Reader
+}
+
+func (d *deploymentDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	cr, ok := obj.(*v1alpha1.Deployment)
+	if !ok {
+		return errors.New(errNotDeployment)
+	}
+
+	// Deployments may be applied before their ProviderConfig, in which case
+	// only the built-in defaults are filled in.
+	var defaults *apisv1alpha1.DeploymentDefaults
+	if cr.GetProviderConfigReference() != nil || cr.GetNamespacedProviderConfigReference() != nil {
+		pcInfo, err := client.GetProviderConfig(ctx, d.kube, cr)
+		if kubeclient.IgnoreNotFound(err) != nil {
+			return errors.Wrap(err, errGetProviderConfig)
+		}
+		if c := pcInfo.Configuration; c != nil {
+			defaults = c.DeploymentDefaults
+		}
+	}
+	defaultDeployment(cr, defaults)
+	return nil
+}
+
+// defaultDeployment fills the unset deposit, bid selection and maximum price
+// of cr with defaults, or with the built-in defaults of the controller. The
+// deposit of imported deployments is left unset, so that it is late
+// initialized from their escrow on chain instead.
+func defaultDeployment(cr *v1alpha1.Deployment, defaults *apisv1alpha1.DeploymentDefaults) {
+	if defaults == nil {
+		defaults = &apisv1alpha1.DeploymentDefaults{}
+	}
+	p := &cr.Spec.ForProvider
+
+	if p.Deposit == nil && !imported(cr) {
+		p.Deposit = defaults.Deposit.DeepCopy()
+		if p.Deposit == nil {
+			p.Deposit = &v1alpha1.Deposit{Amount: strconv.Itoa(client.DefaultDepositAmount), Denom: client.DefaultDepositDenom}
+		}
+	}
+	if p.BidSelection == nil {
+		p.BidSelection = defaults.BidSelection.DeepCopy()
+		if p.BidSelection == nil {
+			p.BidSelection = &v1alpha1.BidSelection{Strategy: v1alpha1.BidSelectionLowestPrice}
+		}
+	}
+	if p.MaxPrice == nil {
+		p.MaxPrice = defaults.MaxPrice.DeepCopy()
+	}
+}
+
+// imported reports whether cr refers to an existing deployment, whose external
+// name is set to something else than its name before it is created.
+func imported(cr *v1alpha1.Deployment) bool {
+	name := meta.GetExternalName(cr)
+	return name != "" && name != cr.GetName()
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+
+	"github.com/overlock-network/provider-akash/apis/resource/v1alpha1"
+	apisv1alpha1 "github.com/overlock-network/provider-akash/apis/v1alpha1"
+)
+
+func TestDefaultDeployment(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := apisv1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	usdc := &v1alpha1.Deposit{Amount: "10000000", Denom: "ibc/170C677610AC31DF0904FFE09CD3B5C657492170E7E52372E48756B71E56F2F1"}
+	weighted := &v1alpha1.BidSelection{Strategy: v1alpha1.BidSelectionWeighted}
+	maxPrice := &v1alpha1.MaxPrice{Amount: "1000", Denom: "uakt"}
+	pc := &apisv1alpha1.ProviderConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "team"},
+		Spec: apisv1alpha1.ProviderConfigSpec{Configuration: &apisv1alpha1.AkashConfiguration{
+			DeploymentDefaults: &apisv1alpha1.DeploymentDefaults{Deposit: usdc, BidSelection: weighted, MaxPrice: maxPrice},
+		}},
+	}
+	builtIn := v1alpha1.DeploymentParameters{
+		Deposit:      &v1alpha1.Deposit{Amount: "5000000", Denom: "uakt"},
+		BidSelection: &v1alpha1.BidSelection{Strategy: v1alpha1.BidSelectionLowestPrice},
+	}
+
+	cases := map[string]struct {
+		providerConfig string
+		externalName   string
+		params         v1alpha1.DeploymentParameters
+		want           v1alpha1.DeploymentParameters
+	}{
+		"MissingProviderConfig": {
+			providerConfig: "default",
+			want:           builtIn,
+		},
+		"NoDeploymentDefaults": {
+			want: builtIn,
+		},
+		"ProviderConfigDefaults": {
+			providerConfig: "team",
+			want:           v1alpha1.DeploymentParameters{Deposit: usdc, BidSelection: weighted, MaxPrice: maxPrice},
+		},
+		"SetFieldsKept": {
+			providerConfig: "team",
+			params: v1alpha1.DeploymentParameters{
+				Deposit:      &v1alpha1.Deposit{Amount: "100", Denom: "uakt"},
+				BidSelection: &v1alpha1.BidSelection{Strategy: v1alpha1.BidSelectionRandom},
+			},
+			want: v1alpha1.DeploymentParameters{
+				Deposit:      &v1alpha1.Deposit{Amount: "100", Denom: "uakt"},
+				BidSelection: &v1alpha1.BidSelection{Strategy: v1alpha1.BidSelectionRandom},
+				MaxPrice:     maxPrice,
+			},
+		},
+		"Imported": {
+			providerConfig: "team",
+			externalName:   "akash1owner/1234",
+			want:           v1alpha1.DeploymentParameters{BidSelection: weighted, MaxPrice: maxPrice},
+		},
+		"ImportedOwned": {
+			externalName: "1234",
+			want:         v1alpha1.DeploymentParameters{BidSelection: builtIn.BidSelection},
+		},
+		"NotCreated": {
+			externalName: "web",
+			want:         builtIn,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pc.DeepCopy()).Build()
+			cr := &v1alpha1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web"}, Spec: v1alpha1.DeploymentSpec{ForProvider: tc.params}}
+			if tc.externalName != "" {
+				meta.SetExternalName(cr, tc.externalName)
+			}
+			if tc.providerConfig != "" {
+				cr.SetProviderConfigReference(&xpv1.Reference{Name: tc.providerConfig})
+			}
+			if err := (&deploymentDefaulter{kube: kube}).Default(context.Background(), cr); err != nil {
+				t.Fatalf("Default(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want, cr.Spec.ForProvider); diff != "" {
+				t.Errorf("Default(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhook implements the admission webhooks of the Akash APIs.
+package webhook
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/overlock-network/provider-akash/apis/v1alpha1"
+	"github.com/overlock-network/provider-akash/internal/bech32"
+	"github.com/overlock-network/provider-akash/internal/client"
+)
+
+const errNotProviderConfig = "managed resource is not a ProviderConfig or NamespacedProviderConfig"
+
+// addressPrefix is the human-readable part of Akash account addresses.
+const addressPrefix = "akash"
+
+// chainIdPrefixes are the prefixes of the chain IDs of each network.
+var chainIdPrefixes = map[string]string{
+	client.NetworkMainnet: client.MainnetChainIdPrefix,
+	client.NetworkTestnet: "testnet-",
+	client.NetworkSandbox: "sandbox-",
+}
+
+// SetupProviderConfig adds the validating webhooks of ProviderConfigs and
+// NamespacedProviderConfigs to mgr.
+func SetupProviderConfig(mgr ctrl.Manager) error {
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.ProviderConfig{}).
+		WithValidator(&providerConfigValidator{}).
+		Complete(); err != nil {
+		return err
+	}
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.NamespacedProviderConfig{}).
+		WithValidator(&providerConfigValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-akash-overlock-network-v1alpha1-providerconfig,mutating=false,failurePolicy=fail,groups=akash.overlock.network,resources=providerconfigs,versions=v1alpha1,name=providerconfigs.akash.overlock.network,sideEffects=None,admissionReviewVersions=v1
+// +kubebuilder:webhook:verbs=create;update,path=/validate-akash-overlock-network-v1alpha1-namespacedproviderconfig,mutating=false,failurePolicy=fail,groups=akash.overlock.network,resources=namespacedproviderconfigs,versions=v1alpha1,name=namespacedproviderconfigs.akash.overlock.network,sideEffects=None,admissionReviewVersions=v1
+
+// A providerConfigValidator rejects ProviderConfigs that could never work,
+// so that misconfigurations surface when they are applied rather than when
+// a resource using them is reconciled.
+type providerConfigValidator struct{}
+
+func (v *providerConfigValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return validate(obj)
+}
+
+func (v *providerConfigValidator) ValidateUpdate(_ context.Context, _, obj runtime.Object) (admission.Warnings, error) {
+	return validate(obj)
+}
+
+func (v *providerConfigValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validate(obj runtime.Object) (admission.Warnings, error) {
+	var (
+		gvk      = v1alpha1.ProviderConfigGroupVersionKind
+		warnings admission.Warnings
+		errs     field.ErrorList
+	)
+	switch pc := obj.(type) {
+	case *v1alpha1.ProviderConfig:
+		warnings, errs = validateProviderConfig(pc.Spec)
+	case *v1alpha1.NamespacedProviderConfig:
+		gvk = v1alpha1.NamespacedProviderConfigGroupVersionKind
+		warnings, errs = validateProviderConfig(pc.Spec)
+		errs = append(errs, validateNamespacedCredentials(pc)...)
+	default:
+		return nil, errors.New(errNotProviderConfig)
+	}
+	if len(errs) > 0 {
+		return warnings, kerrors.NewInvalid(gvk.GroupKind(), obj.(metav1.Object).GetName(), errs)
+	}
+	return warnings, nil
+}
+
+// validateProviderConfig returns the warnings and errors of spec: malformed
+// account addresses and endpoints, a chain ID of another network than the
+// selected one, and deprecated keyring and CLI settings.
+func validateProviderConfig(spec v1alpha1.ProviderConfigSpec) (admission.Warnings, field.ErrorList) {
+	var warnings admission.Warnings
+	errs := validateCredentials(spec.Credentials, field.NewPath("spec", "credentials"))
+
+	c := spec.Configuration
+	if c == nil {
+		return warnings, errs
+	}
+	path := field.NewPath("spec", "configuration")
+
+	for name, address := range map[string]*string{"accountAddress": c.AccountAddress, "feeGranter": c.FeeGranter} {
+		if address == nil {
+			continue
+		}
+		if err := bech32.ValidateAddress(*address, addressPrefix); err != nil {
+			errs = append(errs, field.Invalid(path.Child(name), *address, "invalid account address: "+err.Error()))
+		}
+	}
+
+	network := value(c.Net, client.DefaultNet)
+	if prefix, ok := chainIdPrefixes[network]; ok && c.ChainId != nil && !strings.HasPrefix(*c.ChainId, prefix) {
+		errs = append(errs, field.Invalid(path.Child("chainId"), *c.ChainId, "chain IDs of "+network+" start with "+prefix))
+	}
+
+	for name, endpoint := range map[string]*string{
+		"node":          c.Node,
+		"providersApi":  c.ProvidersApi,
+		"indexerApi":    c.IndexerApi,
+		"restApi":       c.RestApi,
+		"chainRegistry": c.ChainRegistry,
+	} {
+		if endpoint == nil {
+			continue
+		}
+		if err := validateURL(*endpoint); err != nil {
+			errs = append(errs, field.Invalid(path.Child(name), *endpoint, err.Error()))
+		}
+	}
+	if c.GRPCEndpoint != nil {
+		if err := validateGRPCEndpoint(*c.GRPCEndpoint); err != nil {
+			errs = append(errs, field.Invalid(path.Child("grpcEndpoint"), *c.GRPCEndpoint, err.Error()))
+		}
+	}
+	if c.Node != nil && c.GRPCEndpoint == nil {
+		errs = append(errs, field.Required(path.Child("grpcEndpoint"), "the chain of a configured node is queried through the gRPC endpoint of a node"))
+	}
+	if value(c.Transport, client.DefaultTransport) == client.TransportCLI {
+		warnings = append(warnings, "the cli transport is deprecated, the chain is queried through gRPC either way")
+	}
+
+	if c.KeyName != nil {
+		warnings = append(warnings, "spec.configuration.keyName is deprecated and ignored, transactions are signed with the key held by the credentials")
+	}
+	if c.KeyringBackend != nil {
+		warnings = append(warnings, "spec.configuration.keyringBackend is deprecated and ignored, no keyring is used")
+	}
+	if c.Version != nil || c.Path != nil {
+		warnings = append(warnings, "spec.configuration.version and path are deprecated and ignored, the Akash CLI is no longer run")
+	}
+
+	return warnings, errs
+}
+
+// validateCredentials returns the errors of credentials lacking the
+// selector their source needs.
+func validateCredentials(c v1alpha1.ProviderCredentials, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	switch c.Source { //nolint:exhaustive // Other sources need no selector.
+	case xpv1.CredentialsSourceSecret:
+		if c.SecretRef == nil {
+			errs = append(errs, field.Required(path.Child("secretRef"), "credentials from a Secret need a secretRef"))
+		}
+	case xpv1.CredentialsSourceEnvironment:
+		if c.Env == nil {
+			errs = append(errs, field.Required(path.Child("env"), "credentials from the environment need an env"))
+		}
+	case xpv1.CredentialsSourceFilesystem:
+		if c.Fs == nil {
+			errs = append(errs, field.Required(path.Child("fs"), "credentials from the filesystem need an fs"))
+		}
+	}
+	return errs
+}
+
+// validateNamespacedCredentials returns the errors of the credentials of a
+// NamespacedProviderConfig that are not a Secret in its own namespace.
+func validateNamespacedCredentials(pc *v1alpha1.NamespacedProviderConfig) field.ErrorList {
+	path := field.NewPath("spec", "credentials")
+	c := pc.Spec.Credentials
+	if c.Source != xpv1.CredentialsSourceSecret {
+		return field.ErrorList{field.NotSupported(path.Child("source"), c.Source, []string{string(xpv1.CredentialsSourceSecret)})}
+	}
+	if c.SecretRef != nil && c.SecretRef.Namespace != "" && c.SecretRef.Namespace != pc.GetNamespace() {
+		return field.ErrorList{field.Invalid(path.Child("secretRef", "namespace"), c.SecretRef.Namespace, "credentials must be a Secret in the namespace of the NamespacedProviderConfig")}
+	}
+	return nil
+}
+
+// validateURL returns an error unless endpoint is an absolute URL with a
+// host.
+func validateURL(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return errors.Wrap(err, "invalid URL")
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return errors.New("URL needs a scheme and a host")
+	}
+	return nil
+}
+
+// validateGRPCEndpoint returns an error unless endpoint is a host,
+// optionally followed by a port and prefixed with http:// or https://.
+func validateGRPCEndpoint(endpoint string) error {
+	target := strings.TrimPrefix(strings.TrimPrefix(endpoint, "https://"), "http://")
+	host := target
+	if strings.Contains(target, ":") {
+		h, port, err := net.SplitHostPort(target)
+		if err != nil {
+			return errors.Wrap(err, "invalid endpoint")
+		}
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return errors.Errorf("invalid port %q", port)
+		}
+		host = h
+	}
+	if host == "" || strings.ContainsAny(host, "/?#") {
+		return errors.New("endpoint needs a host, optionally followed by a port")
+	}
+	return nil
+}
+
+func value(s *string, def string) string {
+	if s == nil || *s == "" {
+		return def
+	}
+	return *s
+}
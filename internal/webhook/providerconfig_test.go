@@ -0,0 +1,188 @@
+/*
+Copyright 2024 The Akash Provider Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/overlock-network/provider-akash/apis/v1alpha1"
+)
+
+func TestValidateProviderConfig(t *testing.T) {
+	str := func(s string) *string { return &s }
+	secret := v1alpha1.ProviderCredentials{
+		Source: xpv1.CredentialsSourceSecret,
+		CommonCredentialSelectors: xpv1.CommonCredentialSelectors{
+			SecretRef: &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: "akash", Namespace: "crossplane-system"}, Key: "mnemonic"},
+		},
+	}
+
+	type want struct {
+		warnings admission.Warnings
+		fields   []string
+	}
+
+	cases := map[string]struct {
+		credentials v1alpha1.ProviderCredentials
+		config      *v1alpha1.AkashConfiguration
+		want        want
+	}{
+		"NoConfiguration": {
+			credentials: secret,
+		},
+		"Valid": {
+			credentials: secret,
+			config: &v1alpha1.AkashConfiguration{
+				AccountAddress: str("akash1fsgzj6t7udv8zhf6zj32mkqhcjcpv52y9trpyw"),
+				FeeGranter:     str("akash1dff5xx85w2pl5ytdzkg8xa86ej3dgxhcfq4uq5"),
+				Net:            str("sandbox"),
+				ChainId:        str("sandbox-01"),
+				Node:           str("https://rpc.sandbox-01.aksh.pw:443"),
+				RestApi:        str("https://api.sandbox-01.aksh.pw"),
+				Transport:      str("grpc"),
+				GRPCEndpoint:   str("grpc.sandbox-01.aksh.pw:9090"),
+			},
+		},
+		"InvalidAddresses": {
+			credentials: secret,
+			config: &v1alpha1.AkashConfiguration{
+				AccountAddress: str("akash1fsgzj6t7udv8zhf6zj32mkqhcjcpv52y9trpyx"),
+				FeeGranter:     str("cosmos1fsgzj6t7udv8zhf6zj32mkqhcjcpv52ygswxa5"),
+			},
+			want: want{fields: []string{"spec.configuration.accountAddress", "spec.configuration.feeGranter"}},
+		},
+		"ChainIdOfOtherNet": {
+			credentials: secret,
+			config:      &v1alpha1.AkashConfiguration{Net: str("sandbox"), ChainId: str("akashnet-2")},
+			want:        want{fields: []string{"spec.configuration.chainId"}},
+		},
+		"ChainIdOfDefaultNet": {
+			credentials: secret,
+			config:      &v1alpha1.AkashConfiguration{ChainId: str("testnet-1")},
+			want:        want{fields: []string{"spec.configuration.chainId"}},
+		},
+		"InvalidEndpoints": {
+			credentials: secret,
+			config: &v1alpha1.AkashConfiguration{
+				Node:          str("rpc.akashnet.net:443"),
+				ProvidersApi:  str("://"),
+				ChainRegistry: str("https://raw.githubusercontent.com/cosmos/chain-registry/master"),
+				GRPCEndpoint:  str("https://grpc.akashnet.net:port"),
+			},
+			want: want{fields: []string{"spec.configuration.grpcEndpoint", "spec.configuration.node", "spec.configuration.providersApi"}},
+		},
+		"GRPCEndpointWithoutPort": {
+			credentials: secret,
+			config:      &v1alpha1.AkashConfiguration{GRPCEndpoint: str("https://grpc.akashnet.net")},
+		},
+		"NodeWithoutGRPCEndpoint": {
+			credentials: secret,
+			config:      &v1alpha1.AkashConfiguration{Node: str("https://rpc.sandbox-01.aksh.pw:443")},
+			want:        want{fields: []string{"spec.configuration.grpcEndpoint"}},
+		},
+		"CLITransport": {
+			credentials: secret,
+			config:      &v1alpha1.AkashConfiguration{Transport: str("cli")},
+			want: want{warnings: admission.Warnings{
+				"the cli transport is deprecated, the chain is queried through gRPC either way",
+			}},
+		},
+		"KeyringSettings": {
+			credentials: secret,
+			config:      &v1alpha1.AkashConfiguration{KeyName: str("main"), KeyringBackend: str("file")},
+			want: want{warnings: admission.Warnings{
+				"spec.configuration.keyName is deprecated and ignored, transactions are signed with the key held by the credentials",
+				"spec.configuration.keyringBackend is deprecated and ignored, no keyring is used",
+			}},
+		},
+		"CLISettings": {
+			credentials: secret,
+			config:      &v1alpha1.AkashConfiguration{Version: str("0.18.0"), Path: str("/usr/local/bin/akash")},
+			want: want{warnings: admission.Warnings{
+				"spec.configuration.version and path are deprecated and ignored, the Akash CLI is no longer run",
+			}},
+		},
+		"SecretWithoutRef": {
+			credentials: v1alpha1.ProviderCredentials{Source: xpv1.CredentialsSourceSecret},
+			want:        want{fields: []string{"spec.credentials.secretRef"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			spec := v1alpha1.ProviderConfigSpec{Credentials: tc.credentials, Configuration: tc.config}
+			warnings, errs := validateProviderConfig(spec)
+			got := want{warnings: warnings, fields: fields(errs)}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("validateProviderConfig(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateNamespacedCredentials(t *testing.T) {
+	secretRef := func(ns string) *xpv1.SecretKeySelector {
+		return &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Namespace: ns, Name: "akash"}, Key: "mnemonic"}
+	}
+	cases := map[string]struct {
+		credentials v1alpha1.ProviderCredentials
+		want        []string
+	}{
+		"SecretInNamespace": {
+			credentials: v1alpha1.ProviderCredentials{Source: xpv1.CredentialsSourceSecret, CommonCredentialSelectors: xpv1.CommonCredentialSelectors{SecretRef: secretRef("team-a")}},
+		},
+		"SecretWithoutNamespace": {
+			credentials: v1alpha1.ProviderCredentials{Source: xpv1.CredentialsSourceSecret, CommonCredentialSelectors: xpv1.CommonCredentialSelectors{SecretRef: secretRef("")}},
+		},
+		"SecretInOtherNamespace": {
+			credentials: v1alpha1.ProviderCredentials{Source: xpv1.CredentialsSourceSecret, CommonCredentialSelectors: xpv1.CommonCredentialSelectors{SecretRef: secretRef("crossplane-system")}},
+			want:        []string{"spec.credentials.secretRef.namespace"},
+		},
+		"Filesystem": {
+			credentials: v1alpha1.ProviderCredentials{Source: xpv1.CredentialsSourceFilesystem},
+			want:        []string{"spec.credentials.source"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pc := &v1alpha1.NamespacedProviderConfig{Spec: v1alpha1.ProviderConfigSpec{Credentials: tc.credentials}}
+			pc.SetNamespace("team-a")
+			got := fields(validateNamespacedCredentials(pc))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("validateNamespacedCredentials(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+// fields returns the sorted fields of errs.
+func fields(errs field.ErrorList) []string {
+	var out []string
+	for _, err := range errs {
+		out = append(out, err.Field)
+	}
+	sort.Strings(out)
+	return out
+}